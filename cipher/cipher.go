@@ -27,3 +27,42 @@ type Provider interface {
 type SecretsProvider interface {
 	SecretsCipher(ctx context.Context) [][32]byte
 }
+
+// Rotator is implemented by ciphers which store the ID of the key a message was
+// encrypted with alongside the ciphertext.
+type Rotator interface {
+	// NeedsRotation returns true if the hex-encoded ciphertext was not
+	// encrypted with the current key.
+	NeedsRotation(ctx context.Context, encrypted string) (bool, error)
+}
+
+// Reencrypt decrypts the hex-encoded ciphertext and encrypts it again, so that
+// it is encrypted with the current key. The second return value is false if the
+// ciphertext was left unchanged, because it is empty or because the cipher
+// reports that it is already encrypted with the current key.
+func Reencrypt(ctx context.Context, c Cipher, encrypted string) (string, bool, error) {
+	if len(encrypted) == 0 {
+		return encrypted, false, nil
+	}
+
+	if r, ok := c.(Rotator); ok {
+		needsRotation, err := r.NeedsRotation(ctx, encrypted)
+		if err != nil {
+			return "", false, err
+		}
+		if !needsRotation {
+			return encrypted, false, nil
+		}
+	}
+
+	plaintext, err := c.Decrypt(ctx, encrypted)
+	if err != nil {
+		return "", false, err
+	}
+
+	reencrypted, err := c.Encrypt(ctx, plaintext)
+	if err != nil {
+		return "", false, err
+	}
+	return reencrypted, true, nil
+}
@@ -5,9 +5,18 @@ package cipher_test
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -101,3 +110,193 @@ func testAllWork(ctx context.Context, t *testing.T, c cipher.Cipher) {
 	_, err = c.Decrypt(ctx, "")
 	require.NoError(t, err)
 }
+
+func writeKeyFile(t *testing.T, path, current string, keyIDs ...string) {
+	keys := map[string]string{}
+	for _, id := range keyIDs {
+		// The keys are derived from their IDs, so that rewriting the file
+		// keeps the existing keys.
+		keys[id] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%-32s", id)))
+	}
+	contents, err := json.Marshal(map[string]any{"current": current, "keys": keys})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, contents, 0o600))
+}
+
+func TestEnvelope(t *testing.T) {
+	ctx := context.Background()
+	_, reg := internal.NewFastRegistryWithMocks(t, configx.WithValue(config.ViperKeySecretsDefault, goodSecret))
+
+	keyFile := filepath.Join(t.TempDir(), "keys.json")
+	writeKeyFile(t, keyFile, "key-1", "key-1")
+	legacy := cipher.NewCryptChaCha20(reg.Config())
+	c := cipher.NewEnvelope(cipher.NewFileKMS(keyFile), legacy)
+
+	t.Run("case=all_work", func(t *testing.T) {
+		testAllWork(ctx, t, c)
+	})
+
+	t.Run("case=rotation", func(t *testing.T) {
+		encrypted, err := c.Encrypt(ctx, []byte("my secret message!"))
+		require.NoError(t, err)
+
+		needsRotation, err := c.NeedsRotation(ctx, encrypted)
+		require.NoError(t, err)
+		assert.False(t, needsRotation)
+
+		reencrypted, changed, err := cipher.Reencrypt(ctx, c, encrypted)
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, encrypted, reencrypted)
+
+		writeKeyFile(t, keyFile, "key-2", "key-1", "key-2")
+		t.Cleanup(func() { writeKeyFile(t, keyFile, "key-1", "key-1") })
+
+		needsRotation, err = c.NeedsRotation(ctx, encrypted)
+		require.NoError(t, err)
+		assert.True(t, needsRotation)
+
+		reencrypted, changed, err = cipher.Reencrypt(ctx, c, encrypted)
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		// The old key is no longer needed.
+		writeKeyFile(t, keyFile, "key-2", "key-2")
+
+		decrypted, err := c.Decrypt(ctx, reencrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "my secret message!", string(decrypted))
+
+		// Keys which were loaded once are never removed.
+		decrypted, err = c.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "my secret message!", string(decrypted))
+
+		_, err = cipher.NewEnvelope(cipher.NewFileKMS(keyFile), nil).Decrypt(ctx, encrypted)
+		require.Error(t, err)
+	})
+
+	t.Run("case=cached keys", func(t *testing.T) {
+		keyFile := filepath.Join(t.TempDir(), "keys.json")
+		writeKeyFile(t, keyFile, "key-1", "key-1", "key-2")
+		kms := cipher.NewFileKMS(keyFile)
+
+		current, err := kms.CurrentKeyID(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "key-1", current)
+
+		info, err := os.Stat(keyFile)
+		require.NoError(t, err)
+
+		// The file is not read again as long as it looks unchanged.
+		writeKeyFile(t, keyFile, "key-2", "key-1", "key-2")
+		require.NoError(t, os.Chtimes(keyFile, info.ModTime(), info.ModTime()))
+
+		current, err = kms.CurrentKeyID(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "key-1", current)
+
+		require.NoError(t, kms.Reload(ctx))
+		current, err = kms.CurrentKeyID(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "key-2", current)
+
+		writeKeyFile(t, keyFile, "key-1", "key-1", "key-2")
+		require.NoError(t, os.Chtimes(keyFile, info.ModTime().Add(time.Second), info.ModTime().Add(time.Second)))
+
+		current, err = kms.CurrentKeyID(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "key-1", current)
+	})
+
+	t.Run("case=legacy", func(t *testing.T) {
+		encrypted, err := legacy.Encrypt(ctx, []byte("my secret message!"))
+		require.NoError(t, err)
+
+		decrypted, err := c.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "my secret message!", string(decrypted))
+
+		needsRotation, err := c.NeedsRotation(ctx, encrypted)
+		require.NoError(t, err)
+		assert.True(t, needsRotation)
+
+		_, err = cipher.NewEnvelope(cipher.NewFileKMS(keyFile), nil).Decrypt(ctx, encrypted)
+		require.Error(t, err)
+	})
+
+	t.Run("case=tampered", func(t *testing.T) {
+		encrypted, err := c.Encrypt(ctx, []byte("my secret message!"))
+		require.NoError(t, err)
+
+		raw, err := hex.DecodeString(encrypted)
+		require.NoError(t, err)
+		raw[len(raw)-1] ^= 1
+
+		_, err = c.Decrypt(ctx, hex.EncodeToString(raw))
+		require.Error(t, err)
+	})
+
+	t.Run("case=misconfigured", func(t *testing.T) {
+		_, err := cipher.NewEnvelope(cipher.NewFileKMS(""), nil).Encrypt(ctx, []byte("not-empty"))
+		var hErr *herodot.DefaultError
+		require.ErrorAs(t, err, &hErr)
+		assert.Equal(t, "Unable to load the key encryption keys because no key file was configured.", hErr.Reason())
+	})
+}
+
+func TestVaultKMS(t *testing.T) {
+	ctx := context.Background()
+
+	// A minimal stand-in for the transit secrets engine, which "encrypts"
+	// by prefixing the plaintext with the key version.
+	var version atomic.Int32
+	version.Store(1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/v1/transit/keys/kratos":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"latest_version": version.Load()}})
+		case "/v1/transit/encrypt/kratos":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"ciphertext": fmt.Sprintf("vault:v%d:%s", version.Load(), body["plaintext"])}})
+		case "/v1/transit/decrypt/kratos":
+			parts := strings.SplitN(body["ciphertext"], ":", 3)
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"plaintext": parts[2]}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	c := cipher.NewEnvelope(cipher.NewVaultKMS(ts.Client(), ts.URL, "root", "transit", "kratos"), nil)
+	testAllWork(ctx, t, c)
+
+	encrypted, err := c.Encrypt(ctx, []byte("my secret message!"))
+	require.NoError(t, err)
+
+	needsRotation, err := c.NeedsRotation(ctx, encrypted)
+	require.NoError(t, err)
+	assert.False(t, needsRotation)
+
+	version.Store(2)
+
+	needsRotation, err = c.NeedsRotation(ctx, encrypted)
+	require.NoError(t, err)
+	assert.True(t, needsRotation)
+
+	decrypted, err := c.Decrypt(ctx, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "my secret message!", string(decrypted))
+
+	_, err = cipher.NewEnvelope(cipher.NewVaultKMS(ts.Client(), ts.URL, "invalid", "transit", "kratos"), nil).Encrypt(ctx, []byte("not-empty"))
+	var hErr *herodot.DefaultError
+	require.ErrorAs(t, err, &hErr)
+	assert.Equal(t, "Vault responded with status code 403.", hErr.Reason())
+}
@@ -0,0 +1,209 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cipher
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/ory/herodot"
+)
+
+// envelopeMagic prefixes every envelope ciphertext. The last byte is the
+// version of the format.
+var envelopeMagic = []byte{'k', 'e', 'n', 'v', 1}
+
+// Envelope encrypts every message with a fresh data key using
+// XChaCha20-Poly1305, and stores the data key wrapped by a KMS together with
+// the ID of the wrapping key alongside the ciphertext:
+//
+//	magic | len(key ID) (1 byte) | key ID | len(wrapped key) (2 bytes) | wrapped key | nonce | ciphertext
+//
+// Ciphertexts which were not produced by this cipher are decrypted with the
+// legacy cipher, if one is given, so that existing ciphertexts remain readable
+// until they were rotated.
+type Envelope struct {
+	kms    KMS
+	legacy Cipher
+}
+
+var (
+	_ Cipher   = (*Envelope)(nil)
+	_ Rotator  = (*Envelope)(nil)
+	_ Reloader = (*Envelope)(nil)
+)
+
+func NewEnvelope(kms KMS, legacy Cipher) *Envelope {
+	return &Envelope{kms: kms, legacy: legacy}
+}
+
+type envelope struct {
+	header  []byte
+	keyID   string
+	wrapped []byte
+	body    []byte
+}
+
+func parseEnvelope(raw []byte) (*envelope, bool) {
+	if !bytes.HasPrefix(raw, envelopeMagic) {
+		return nil, false
+	}
+
+	rest := raw[len(envelopeMagic):]
+	if len(rest) < 1 {
+		return nil, false
+	}
+	keyIDLen := int(rest[0])
+	rest = rest[1:]
+	if len(rest) < keyIDLen+2 {
+		return nil, false
+	}
+	keyID := string(rest[:keyIDLen])
+	rest = rest[keyIDLen:]
+
+	wrappedLen := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if len(rest) < wrappedLen {
+		return nil, false
+	}
+
+	return &envelope{
+		header:  raw[:len(raw)-len(rest)+wrappedLen],
+		keyID:   keyID,
+		wrapped: rest[:wrappedLen],
+		body:    rest[wrappedLen:],
+	}, true
+}
+
+// Encrypt returns an envelope encryption of the message.
+func (e *Envelope) Encrypt(ctx context.Context, message []byte) (string, error) {
+	if len(message) == 0 {
+		return "", nil
+	}
+
+	dataKey := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", errors.WithStack(herodot.ErrInternalServerError.WithWrap(err).WithReason("Unable to generate data key"))
+	}
+
+	keyID, wrapped, err := e.kms.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", err
+	}
+	if len(keyID) > math.MaxUint8 || len(wrapped) > math.MaxUint16 {
+		return "", errors.WithStack(herodot.ErrInternalServerError.WithReason("The key ID or wrapped data key returned by the KMS is too long."))
+	}
+
+	aead, err := chacha20poly1305.NewX(dataKey)
+	if err != nil {
+		return "", errors.WithStack(herodot.ErrInternalServerError.WithWrap(err).WithReason("Unable to generate key"))
+	}
+
+	// Make sure the size calculation does not overflow.
+	if len(message) > math.MaxInt-aead.NonceSize()-aead.Overhead()-len(envelopeMagic)-3-len(keyID)-len(wrapped) {
+		return "", errors.WithStack(herodot.ErrInternalServerError.WithReason("plaintext too large"))
+	}
+
+	header := make([]byte, 0, len(envelopeMagic)+3+len(keyID)+len(wrapped))
+	header = append(header, envelopeMagic...)
+	header = append(header, byte(len(keyID)))
+	header = append(header, keyID...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped))) //nolint:gosec // checked above
+	header = append(header, wrapped...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.WithStack(herodot.ErrInternalServerError.WithWrap(err).WithReason("Unable to generate nonce"))
+	}
+
+	encryptedMsg := make([]byte, 0, len(header)+len(nonce)+len(message)+aead.Overhead())
+	encryptedMsg = append(encryptedMsg, header...)
+	encryptedMsg = append(encryptedMsg, nonce...)
+
+	// The header is authenticated, so that the key ID can not be swapped.
+	encryptedMsg = aead.Seal(encryptedMsg, nonce, message, header)
+	return hex.EncodeToString(encryptedMsg), nil
+}
+
+// Decrypt decrypts an envelope ciphertext, or a ciphertext of the legacy
+// cipher.
+func (e *Envelope) Decrypt(ctx context.Context, ciphertext string) ([]byte, error) {
+	if len(ciphertext) == 0 {
+		return nil, nil
+	}
+
+	raw, err := hex.DecodeString(ciphertext)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithWrap(err).WithReason("Unable to decode hex encrypted string"))
+	}
+
+	env, ok := parseEnvelope(raw)
+	if !ok {
+		if e.legacy != nil {
+			return e.legacy.Decrypt(ctx, ciphertext)
+		}
+		return nil, errors.WithStack(herodot.ErrForbidden.WithReason("Unable to decrypt string"))
+	}
+
+	dataKey, err := e.kms.UnwrapKey(ctx, env.keyID, env.wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(dataKey)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithWrap(err).WithReason("Unable to instantiate chacha20"))
+	}
+
+	if len(env.body) < aead.NonceSize() {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("cipher text too short"))
+	}
+
+	nonce, body := env.body[:aead.NonceSize()], env.body[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, body, env.header)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrForbidden.WithWrap(err).WithReason("Unable to decrypt string"))
+	}
+	return plaintext, nil
+}
+
+// NeedsRotation returns true if the ciphertext is a ciphertext of the legacy
+// cipher, or if its data key was not wrapped with the current key of the KMS.
+func (e *Envelope) NeedsRotation(ctx context.Context, ciphertext string) (bool, error) {
+	if len(ciphertext) == 0 {
+		return false, nil
+	}
+
+	raw, err := hex.DecodeString(ciphertext)
+	if err != nil {
+		return false, errors.WithStack(herodot.ErrBadRequest.WithWrap(err).WithReason("Unable to decode hex encrypted string"))
+	}
+
+	env, ok := parseEnvelope(raw)
+	if !ok {
+		return true, nil
+	}
+
+	current, err := e.kms.CurrentKeyID(ctx)
+	if err != nil {
+		return false, err
+	}
+	return env.keyID != current, nil
+}
+
+// Reload loads the keys of the KMS again, if the KMS caches them.
+func (e *Envelope) Reload(ctx context.Context) error {
+	if r, ok := e.kms.(Reloader); ok {
+		return r.Reload(ctx)
+	}
+	return nil
+}
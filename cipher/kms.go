@@ -0,0 +1,29 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cipher
+
+import "context"
+
+// KMS wraps and unwraps the data keys of the envelope cipher with key
+// encryption keys which are kept outside of Ory Kratos.
+type KMS interface {
+	// CurrentKeyID returns the ID of the key which new data keys are wrapped
+	// with.
+	CurrentKeyID(ctx context.Context) (string, error)
+
+	// WrapKey encrypts the data key with the current key and returns the ID of
+	// that key alongside the wrapped data key.
+	WrapKey(ctx context.Context, key []byte) (keyID string, wrapped []byte, err error)
+
+	// UnwrapKey decrypts a data key which was wrapped with the key of the
+	// given ID.
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Reloader is implemented by KMSs and ciphers which cache their keys.
+type Reloader interface {
+	// Reload loads the keys again, for example before the stored ciphertexts
+	// are rotated.
+	Reload(ctx context.Context) error
+}
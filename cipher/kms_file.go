@@ -0,0 +1,198 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cipher
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/ory/herodot"
+)
+
+// FileKMS wraps data keys with XChaCha20-Poly1305 using key encryption keys
+// read from a JSON file:
+//
+//	{
+//	  "current": "2025-01",
+//	  "keys": {
+//	    "2024-01": "<base64-encoded 32 byte key>",
+//	    "2025-01": "<base64-encoded 32 byte key>"
+//	  }
+//	}
+//
+// The keys are cached and the file is read again when its modification time
+// changes, so keys can be rotated by adding a new key, pointing "current" to
+// it, and running `kratos ciphers rotate`. Keys which were loaded once remain
+// available until the process exits, even if they are removed from the file,
+// but old keys must be kept in the file until the rotation has finished.
+type FileKMS struct {
+	path string
+
+	mu      sync.RWMutex
+	modTime time.Time
+	size    int64
+	current string
+	keys    map[string]string
+}
+
+var (
+	_ KMS      = (*FileKMS)(nil)
+	_ Reloader = (*FileKMS)(nil)
+)
+
+type fileKMSKeys struct {
+	Current string            `json:"current"`
+	Keys    map[string]string `json:"keys"`
+}
+
+func NewFileKMS(path string) *FileKMS {
+	return &FileKMS{path: path}
+}
+
+// Reload reads the key file again, even if its modification time did not
+// change.
+func (f *FileKMS) Reload(_ context.Context) error {
+	return f.load(true)
+}
+
+// load reads the key file if it was not read yet, if it changed since it was
+// last read, or if force is true. Keys from the file are added to the cached
+// keys, and are never removed.
+func (f *FileKMS) load(force bool) error {
+	if f.path == "" {
+		return errors.WithStack(herodot.ErrMisconfiguration.WithReason("Unable to load the key encryption keys because no key file was configured."))
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return errors.WithStack(herodot.ErrMisconfiguration.WithWrap(err).WithReasonf("Unable to read the key encryption keys from %s.", f.path))
+	}
+
+	f.mu.RLock()
+	unchanged := f.keys != nil && f.modTime.Equal(info.ModTime()) && f.size == info.Size()
+	f.mu.RUnlock()
+	if unchanged && !force {
+		return nil
+	}
+
+	contents, err := os.ReadFile(f.path)
+	if err != nil {
+		return errors.WithStack(herodot.ErrMisconfiguration.WithWrap(err).WithReasonf("Unable to read the key encryption keys from %s.", f.path))
+	}
+
+	var keys fileKMSKeys
+	if err := json.Unmarshal(contents, &keys); err != nil {
+		return errors.WithStack(herodot.ErrMisconfiguration.WithWrap(err).WithReasonf("Unable to decode the key encryption keys from %s.", f.path))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.keys == nil {
+		f.keys = make(map[string]string, len(keys.Keys))
+	}
+	for keyID, key := range keys.Keys {
+		f.keys[keyID] = key
+	}
+	f.current = keys.Current
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return nil
+}
+
+func (f *FileKMS) key(keyID string) ([]byte, error) {
+	f.mu.RLock()
+	encoded, ok := f.keys[keyID]
+	f.mu.RUnlock()
+	if !ok {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("The key encryption key %q was not found in %s.", keyID, f.path))
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != chacha20poly1305.KeySize {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("The key encryption key %q must be a base64-encoded %d byte key.", keyID, chacha20poly1305.KeySize))
+	}
+	return key, nil
+}
+
+func (f *FileKMS) currentKeyID() (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.current == "" {
+		return "", errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("The key file %s does not declare a current key encryption key.", f.path))
+	}
+	return f.current, nil
+}
+
+func (f *FileKMS) CurrentKeyID(_ context.Context) (string, error) {
+	if err := f.load(false); err != nil {
+		return "", err
+	}
+	return f.currentKeyID()
+}
+
+func (f *FileKMS) WrapKey(_ context.Context, dataKey []byte) (string, []byte, error) {
+	if err := f.load(false); err != nil {
+		return "", nil, err
+	}
+
+	keyID, err := f.currentKeyID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	key, err := f.key(keyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", nil, errors.WithStack(herodot.ErrInternalServerError.WithWrap(err).WithReason("Unable to instantiate chacha20"))
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(dataKey)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, errors.WithStack(herodot.ErrInternalServerError.WithWrap(err).WithReason("Unable to generate nonce"))
+	}
+
+	return keyID, aead.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+func (f *FileKMS) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	if err := f.load(false); err != nil {
+		return nil, err
+	}
+
+	key, err := f.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithWrap(err).WithReason("Unable to instantiate chacha20"))
+	}
+
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("wrapped key too short"))
+	}
+
+	nonce, ciphertext := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrForbidden.WithWrap(err).WithReason("Unable to unwrap the data key"))
+	}
+	return dataKey, nil
+}
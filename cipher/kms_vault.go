@@ -0,0 +1,138 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cipher
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+)
+
+// VaultKMS wraps data keys using the transit secrets engine of HashiCorp Vault
+// (or OpenBao). The key IDs have the form "<key name>:v<key version>", so that
+// data keys can be unwrapped after the transit key was rotated or the
+// configured key name was changed.
+type VaultKMS struct {
+	client  *http.Client
+	address string
+	token   string
+	mount   string
+	key     string
+}
+
+var _ KMS = (*VaultKMS)(nil)
+
+// NewVaultKMS returns a KMS which uses the transit key named key of the
+// transit secrets engine mounted at mount of the Vault server at address.
+func NewVaultKMS(client *http.Client, address, token, mount, key string) *VaultKMS {
+	return &VaultKMS{
+		client:  client,
+		address: strings.TrimRight(address, "/"),
+		token:   token,
+		mount:   strings.Trim(mount, "/"),
+		key:     key,
+	}
+}
+
+func (v *VaultKMS) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, v.address+"/v1/"+v.mount+"/"+path, reqBody)
+	if err != nil {
+		return errors.WithStack(herodot.ErrMisconfiguration.WithWrap(err).WithReason("Unable to build the request to Vault."))
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return errors.WithStack(herodot.ErrUpstreamError.WithWrap(err).WithReason("Unable to reach Vault."))
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return errors.WithStack(herodot.ErrUpstreamError.
+			WithReasonf("Vault responded with status code %d.", res.StatusCode).
+			WithDebug(string(b)))
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return errors.WithStack(herodot.ErrUpstreamError.WithWrap(err).WithReason("Unable to decode the response from Vault."))
+	}
+	return nil
+}
+
+func (v *VaultKMS) CurrentKeyID(ctx context.Context) (string, error) {
+	var res struct {
+		Data struct {
+			LatestVersion int `json:"latest_version"`
+		} `json:"data"`
+	}
+	if err := v.do(ctx, http.MethodGet, "keys/"+url.PathEscape(v.key), nil, &res); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:v%d", v.key, res.Data.LatestVersion), nil
+}
+
+func (v *VaultKMS) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	var res struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := v.do(ctx, http.MethodPost, "encrypt/"+url.PathEscape(v.key), map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(dataKey),
+	}, &res); err != nil {
+		return "", nil, err
+	}
+
+	// Vault ciphertexts have the form "vault:v<key version>:<ciphertext>".
+	parts := strings.SplitN(res.Data.Ciphertext, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" {
+		return "", nil, errors.WithStack(herodot.ErrUpstreamError.WithReason("Vault returned a ciphertext in an unexpected format."))
+	}
+	return v.key + ":" + parts[1], []byte(res.Data.Ciphertext), nil
+}
+
+func (v *VaultKMS) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	i := strings.LastIndex(keyID, ":")
+	if i < 0 {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("The key ID %q is not a Vault transit key ID.", keyID))
+	}
+
+	var res struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := v.do(ctx, http.MethodPost, "decrypt/"+url.PathEscape(keyID[:i]), map[string]string{
+		"ciphertext": string(wrapped),
+	}, &res); err != nil {
+		return nil, err
+	}
+
+	dataKey, err := base64.StdEncoding.DecodeString(res.Data.Plaintext)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrUpstreamError.WithWrap(err).WithReason("Unable to decode the data key returned by Vault."))
+	}
+	return dataKey, nil
+}
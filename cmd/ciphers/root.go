@@ -0,0 +1,27 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ciphers
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ory/kratos/driver"
+	"github.com/ory/x/configx"
+)
+
+// NewCiphersCmd creates a new ciphers command
+func NewCiphersCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "ciphers",
+		Short: "Commands related to the encryption of stored secrets",
+	}
+	configx.RegisterFlags(c.PersistentFlags())
+	return c
+}
+
+func RegisterCommandRecursive(parent *cobra.Command, dOpts []driver.RegistryOption) {
+	c := NewCiphersCmd()
+	parent.AddCommand(c)
+	c.AddCommand(NewRotateCmd(dOpts))
+}
@@ -0,0 +1,183 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ciphers
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/cipher"
+	"github.com/ory/kratos/driver"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/x/configx"
+	"github.com/ory/x/sqlcon"
+)
+
+func NewRotateCmd(dOpts []driver.RegistryOption) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "rotate",
+		Short: "Re-encrypt stored OpenID Connect tokens and provider secrets with the current key",
+		Long: `Re-encrypts the OpenID Connect tokens stored in the credentials of identities,
+and the secrets of the OpenID Connect providers stored using the admin API, so
+that they are encrypted with the current cipher key.
+
+Run this command after changing the current key encryption key of the envelope
+cipher, or after enabling the envelope cipher with "ciphers.envelope.legacy_algorithm"
+set. Tokens which are already encrypted with the current key are skipped, so the
+command can be re-run safely if it was interrupted. Keep the old keys available
+until the command has finished.
+
+With the aes and xchacha20-poly1305 ciphers, all tokens are re-encrypted with the
+first secret of "secrets.cipher".`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := driver.New(cmd.Context(), cmd.ErrOrStderr(), append(dOpts, driver.WithConfigOptions(configx.WithFlags(cmd.Flags())))...)
+			if err != nil {
+				return err
+			}
+
+			batchSize, err := cmd.Flags().GetInt("batch-size")
+			if err != nil {
+				return err
+			}
+
+			rotated, err := Rotate(cmd.Context(), r, batchSize, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Re-encrypted the tokens of %d credentials.\n", rotated)
+
+			rotated, err = RotateOIDCProviders(cmd.Context(), r)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Re-encrypted the secrets of %d OpenID Connect providers.\n", rotated)
+			return nil
+		},
+	}
+	c.Flags().IntP("batch-size", "b", 100, "The number of credentials to load per batch")
+	return c
+}
+
+type rotateDependencies interface {
+	identity.PrivilegedPoolProvider
+	oidc.ProviderPersistenceProvider
+	cipher.Provider
+}
+
+// Rotate re-encrypts the tokens of all OpenID Connect credentials in batches
+// of batchSize credentials, and returns the number of credentials which were
+// updated. Progress is reported to out after every batch.
+func Rotate(ctx context.Context, r rotateDependencies, batchSize int, out io.Writer) (rotated int, err error) {
+	if batchSize < 1 {
+		return 0, fmt.Errorf("the batch size must be positive, got %d", batchSize)
+	}
+
+	// Pick up a key which was made current since the keys were cached.
+	if c, ok := r.Cipher(ctx).(cipher.Reloader); ok {
+		if err := c.Reload(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	var after uuid.UUID
+	for {
+		credentials, err := r.PrivilegedIdentityPool().ListCredentialsByType(ctx, identity.CredentialsTypeOIDC, after, batchSize)
+		if err != nil {
+			return rotated, err
+		}
+
+		for k := range credentials {
+			changed, err := rotateCredentials(ctx, r, &credentials[k])
+			if err != nil {
+				return rotated, err
+			}
+			if changed {
+				rotated++
+			}
+		}
+
+		if len(credentials) < batchSize {
+			return rotated, nil
+		}
+
+		after = credentials[len(credentials)-1].ID
+		_, _ = fmt.Fprintf(out, "Processed a batch of %d credentials, re-encrypted %d credentials so far.\n", len(credentials), rotated)
+	}
+}
+
+// maxRotateAttempts is how often the tokens of credentials are re-encrypted if
+// the credentials are updated concurrently, for example by a sign in.
+const maxRotateAttempts = 3
+
+// rotateCredentials re-encrypts the tokens of the credentials and reports
+// whether they were updated. If the credentials were updated concurrently, they
+// are loaded again and the tokens are re-encrypted again.
+func rotateCredentials(ctx context.Context, r rotateDependencies, c *identity.Credentials) (bool, error) {
+	for attempt := 1; ; attempt++ {
+		changed, err := c.ReencryptOIDCTokens(ctx, r.Cipher(ctx))
+		if err != nil {
+			return false, fmt.Errorf("unable to re-encrypt the tokens of credentials %s: %w", c.ID, err)
+		}
+		if !changed {
+			return false, nil
+		}
+
+		err = r.PrivilegedIdentityPool().UpdateCredentialsConfig(ctx, c)
+		if err == nil {
+			return true, nil
+		} else if !errors.Is(err, herodot.ErrConflict) || attempt == maxRotateAttempts {
+			return false, err
+		}
+
+		i, err := r.PrivilegedIdentityPool().GetIdentityConfidential(ctx, c.IdentityID)
+		if errors.Is(err, sqlcon.ErrNoRows) {
+			// The identity was deleted in the meantime.
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+
+		reloaded, ok := i.GetCredentials(identity.CredentialsTypeOIDC)
+		if !ok {
+			return false, nil
+		}
+		c = reloaded
+	}
+}
+
+// RotateOIDCProviders re-encrypts the secrets of the OpenID Connect providers
+// stored using the admin API, and returns the number of providers which were
+// updated.
+func RotateOIDCProviders(ctx context.Context, r rotateDependencies) (rotated int, err error) {
+	providers, err := r.OIDCProviderPersister().ListOIDCProviders(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for k := range providers {
+		p := &providers[k]
+		changed, err := p.Reencrypt(ctx, r.Cipher(ctx))
+		if err != nil {
+			return rotated, fmt.Errorf("unable to re-encrypt the secrets of OpenID Connect provider %s: %w", p.ProviderID, err)
+		}
+		if !changed {
+			continue
+		}
+
+		if err := r.OIDCProviderPersister().UpdateOIDCProvider(ctx, p); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+	return rotated, nil
+}
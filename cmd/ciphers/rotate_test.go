@@ -0,0 +1,183 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ciphers_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/cipher"
+	"github.com/ory/kratos/cmd/ciphers"
+	"github.com/ory/kratos/driver"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/x/configx"
+	"github.com/ory/x/uuidx"
+)
+
+type rotateDeps struct {
+	*driver.RegistryDefault
+	c    cipher.Cipher
+	pool identity.PrivilegedPool
+}
+
+func (d *rotateDeps) Cipher(context.Context) cipher.Cipher {
+	return d.c
+}
+
+func (d *rotateDeps) PrivilegedIdentityPool() identity.PrivilegedPool {
+	if d.pool != nil {
+		return d.pool
+	}
+	return d.RegistryDefault.PrivilegedIdentityPool()
+}
+
+// concurrentlyUpdatingPool updates the credentials once right before they are
+// updated by the rotation, as a concurrent sign in would.
+type concurrentlyUpdatingPool struct {
+	identity.PrivilegedPool
+	updated map[uuid.UUID]bool
+}
+
+func (p *concurrentlyUpdatingPool) UpdateCredentialsConfig(ctx context.Context, c *identity.Credentials) error {
+	if !p.updated[c.ID] {
+		p.updated[c.ID] = true
+
+		i, err := p.GetIdentityConfidential(ctx, c.IdentityID)
+		if err != nil {
+			return err
+		}
+		concurrent, _ := i.GetCredentials(identity.CredentialsTypeOIDC)
+		if err := p.PrivilegedPool.UpdateCredentialsConfig(ctx, concurrent); err != nil {
+			return err
+		}
+	}
+	return p.PrivilegedPool.UpdateCredentialsConfig(ctx, c)
+}
+
+func TestRotate(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t, configx.WithValue(config.ViperKeySecretsCipher, []string{"secret-thirty-two-character-long"}))
+	testhelpers.SetDefaultIdentitySchemaFromRaw(conf, []byte(`{"type": "object", "properties": {"traits": {"type": "object"}}}`))
+
+	keyFile := filepath.Join(t.TempDir(), "keys.json")
+	writeKeys := func(current string) {
+		require.NoError(t, os.WriteFile(keyFile, fmt.Appendf(nil, `{"current": %q, "keys": {"key-1": %q, "key-2": %q}}`,
+			current,
+			base64.StdEncoding.EncodeToString([]byte("key-1-thirty-two-character-long!")),
+			base64.StdEncoding.EncodeToString([]byte("key-2-thirty-two-character-long!")),
+		), 0o600))
+	}
+	writeKeys("key-1")
+
+	legacy := cipher.NewCryptChaCha20(reg.Config())
+	envelope := cipher.NewEnvelope(cipher.NewFileKMS(keyFile), legacy)
+	deps := &rotateDeps{RegistryDefault: reg, c: envelope}
+
+	var ids []identity.Identity
+	for k := range 5 {
+		token, err := legacy.Encrypt(ctx, fmt.Appendf(nil, "access-token-%d", k))
+		require.NoError(t, err)
+
+		creds, err := identity.NewCredentialsOIDC(&identity.CredentialsOIDCEncryptedTokens{AccessToken: token}, "google", fmt.Sprintf("subject-%d", k), "")
+		require.NoError(t, err)
+
+		i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+		i.SetCredentials(identity.CredentialsTypeOIDC, *creds)
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+		ids = append(ids, *i)
+	}
+
+	assertTokens := func(t *testing.T) {
+		for k, i := range ids {
+			actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+			require.NoError(t, err)
+
+			token := gjson.GetBytes(actual.Credentials[identity.CredentialsTypeOIDC].Config, "providers.0.initial_access_token").String()
+			needsRotation, err := envelope.NeedsRotation(ctx, token)
+			require.NoError(t, err)
+			assert.False(t, needsRotation)
+
+			plaintext, err := envelope.Decrypt(ctx, token)
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("access-token-%d", k), string(plaintext))
+		}
+	}
+
+	t.Run("case=re-encrypts legacy ciphertexts", func(t *testing.T) {
+		rotated, err := ciphers.Rotate(ctx, deps, 2, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, 5, rotated)
+		assertTokens(t)
+	})
+
+	t.Run("case=skips ciphertexts encrypted with the current key", func(t *testing.T) {
+		rotated, err := ciphers.Rotate(ctx, deps, 2, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, 0, rotated)
+	})
+
+	t.Run("case=re-encrypts ciphertexts of old keys", func(t *testing.T) {
+		writeKeys("key-2")
+
+		rotated, err := ciphers.Rotate(ctx, deps, 10, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, 5, rotated)
+		assertTokens(t)
+	})
+
+	t.Run("case=retries credentials which were updated concurrently", func(t *testing.T) {
+		writeKeys("key-1")
+		pool := &concurrentlyUpdatingPool{PrivilegedPool: reg.PrivilegedIdentityPool(), updated: map[uuid.UUID]bool{}}
+		deps := &rotateDeps{RegistryDefault: reg, c: envelope, pool: pool}
+
+		rotated, err := ciphers.Rotate(ctx, deps, 2, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, 5, rotated)
+		assert.Len(t, pool.updated, 5)
+		assertTokens(t)
+	})
+
+	t.Run("case=re-encrypts stored provider secrets", func(t *testing.T) {
+		secret, err := legacy.Encrypt(ctx, []byte("client-secret"))
+		require.NoError(t, err)
+		require.NoError(t, reg.OIDCProviderPersister().CreateOIDCProvider(ctx, &oidc.StoredProvider{
+			ID:         uuidx.NewV4(),
+			ProviderID: "acme",
+			Config:     fmt.Appendf(nil, `{"id": "acme", "provider": "generic", "client_secret": %q}`, secret),
+		}))
+
+		rotated, err := ciphers.RotateOIDCProviders(ctx, deps)
+		require.NoError(t, err)
+		assert.Equal(t, 1, rotated)
+
+		stored, err := reg.OIDCProviderPersister().GetOIDCProvider(ctx, "acme")
+		require.NoError(t, err)
+		c, err := stored.Configuration(ctx, envelope)
+		require.NoError(t, err)
+		assert.Equal(t, "client-secret", c.ClientSecret)
+
+		rotated, err = ciphers.RotateOIDCProviders(ctx, deps)
+		require.NoError(t, err)
+		assert.Equal(t, 0, rotated)
+	})
+
+	t.Run("case=rejects invalid batch sizes", func(t *testing.T) {
+		_, err := ciphers.Rotate(ctx, deps, 0, io.Discard)
+		require.Error(t, err)
+	})
+}
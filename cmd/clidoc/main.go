@@ -82,6 +82,7 @@ func init() {
 		"NewErrorValidationVerificationRetrySuccess":              text.NewErrorValidationVerificationRetrySuccess(),
 		"NewErrorValidationVerificationStateFailure":              text.NewErrorValidationVerificationStateFailure(),
 		"NewErrorValidationVerificationCodeInvalidOrAlreadyUsed":  text.NewErrorValidationVerificationCodeInvalidOrAlreadyUsed(),
+		"NewErrorValidationVerificationAddressSuppressed":         text.NewErrorValidationVerificationAddressSuppressed(),
 		"NewErrorSystemGeneric":                                   text.NewErrorSystemGeneric("{reason}"),
 		"NewValidationErrorGeneric":                               text.NewValidationErrorGeneric("{reason}"),
 		"NewValidationErrorRequired":                              text.NewValidationErrorRequired("{property}"),
@@ -105,6 +106,11 @@ func init() {
 		"NewErrorValidationPasswordMaxLength":                     text.NewErrorValidationPasswordMaxLength(72, 80),
 		"NewErrorValidationPasswordTooManyBreaches":               text.NewErrorValidationPasswordTooManyBreaches(101),
 		"NewErrorValidationPasswordNewSameAsOld":                  text.NewErrorValidationPasswordNewSameAsOld(),
+		"NewErrorValidationPasswordMissingCharacterClass":         text.NewErrorValidationPasswordMissingCharacterClass("{class}"),
+		"NewErrorValidationPasswordMinCharacterClasses":           text.NewErrorValidationPasswordMinCharacterClasses(3, 2),
+		"NewErrorValidationPasswordBannedWord":                    text.NewErrorValidationPasswordBannedWord(),
+		"NewErrorValidationPasswordTooWeak":                       text.NewErrorValidationPasswordTooWeak(3, 1),
+		"NewErrorValidationPasswordPreviouslyUsed":                text.NewErrorValidationPasswordPreviouslyUsed(),
 		"NewErrorValidationInvalidCredentials":                    text.NewErrorValidationInvalidCredentials(),
 		"NewErrorValidationDuplicateCredentials":                  text.NewErrorValidationDuplicateCredentials(),
 		"NewErrorValidationDuplicateCredentialsWithHints":         text.NewErrorValidationDuplicateCredentialsWithHints([]string{"{available_credential_types_list}"}, []string{"{available_oidc_providers_list}"}, "{credential_identifier_hint}"),
@@ -130,6 +136,9 @@ func init() {
 		"NewInfoLoginWith":                                        text.NewInfoLoginWith("{provider}", "{providerID}"),
 		"NewInfoLoginWithAndLink":                                 text.NewInfoLoginWithAndLink("{provider}"),
 		"NewErrorValidationLoginFlowExpired":                      text.NewErrorValidationLoginFlowExpired(aSecondAgo),
+		"NewErrorValidationLoginRateLimited":                      text.NewErrorValidationLoginRateLimited(inAMinute),
+		"NewErrorValidationLoginIdentityLocked":                   text.NewErrorValidationLoginIdentityLocked(inAMinute),
+		"NewErrorValidationLoginPasswordForbidden":                text.NewErrorValidationLoginPasswordForbidden("{domain}"),
 		"NewErrorValidationLoginNoStrategyFound":                  text.NewErrorValidationLoginNoStrategyFound(),
 		"NewErrorValidationRegistrationNoStrategyFound":           text.NewErrorValidationRegistrationNoStrategyFound(),
 		"NewErrorValidationSettingsNoStrategyFound":               text.NewErrorValidationSettingsNoStrategyFound(),
@@ -155,6 +164,7 @@ func init() {
 		"NewErrorValidationRecoveryCodeInvalidOrAlreadyUsed":      text.NewErrorValidationRecoveryCodeInvalidOrAlreadyUsed(),
 		"NewErrorValidationRecoveryRetrySuccess":                  text.NewErrorValidationRecoveryRetrySuccess(),
 		"NewErrorValidationRecoveryStateFailure":                  text.NewErrorValidationRecoveryStateFailure(),
+		"NewErrorValidationRecoveryAddressSuppressed":             text.NewErrorValidationRecoveryAddressSuppressed(),
 		"NewInfoNodeInputEmail":                                   text.NewInfoNodeInputEmail(),
 		"NewInfoNodeResendOTP":                                    text.NewInfoNodeResendOTP(),
 		"NewInfoNodeLoginAndLinkCredential":                       text.NewInfoNodeLoginAndLinkCredential(),
@@ -184,6 +194,7 @@ func init() {
 		"NewErrorValidationAddressUnknown":                        text.NewErrorValidationAddressUnknown(),
 		"NewInfoSelfServiceLoginCodeMFA":                          text.NewInfoSelfServiceLoginCodeMFA(),
 		"NewInfoLoginPassword":                                    text.NewInfoLoginPassword(),
+		"NewInfoLoginLDAP":                                        text.NewInfoLoginLDAP(),
 		"NewErrorValidationAccountNotFound":                       text.NewErrorValidationAccountNotFound(),
 		"NewInfoSelfServiceLoginAAL2CodeAddress":                  text.NewInfoSelfServiceLoginAAL2CodeAddress("{channel}", "{address}"),
 		"NewErrorCaptchaFailed":                                   text.NewErrorCaptchaFailed(),
@@ -302,6 +313,21 @@ func writeMessagesJson(path string, sortedMessages []*text.Message) error {
 	return nil
 }
 
+// returnsMessage returns true if the function returns a *Message, so that
+// other constructors of the text package, such as NewTranslations, are not
+// expected in the list of messages.
+func returnsMessage(decl *ast.FuncDecl) bool {
+	if decl.Type.Results == nil || len(decl.Type.Results.List) != 1 {
+		return false
+	}
+	star, ok := decl.Type.Results.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	return ok && ident.Name == "Message"
+}
+
 func validateAllMessages(path string) error {
 	type message struct {
 		ID, Name string
@@ -340,7 +366,7 @@ func validateAllMessages(path string) error {
 		for _, d := range f.Decls {
 			switch decl := d.(type) {
 			case *ast.FuncDecl:
-				if name := decl.Name.String(); decl.Name.IsExported() && strings.HasPrefix(name, "New") {
+				if name := decl.Name.String(); decl.Name.IsExported() && strings.HasPrefix(name, "New") && returnsMessage(decl) {
 					if _, ok := messages[name]; !ok {
 						return errors.Errorf("expected to find message %s in the list for the documentation generation but could not", name)
 					}
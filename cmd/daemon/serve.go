@@ -19,6 +19,7 @@ import (
 	"github.com/ory/analytics-go/v5"
 	"github.com/ory/graceful"
 	"github.com/ory/kratos/cmd/courier"
+	"github.com/ory/kratos/cmd/outbox"
 	"github.com/ory/kratos/driver"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
@@ -67,6 +68,7 @@ func servePublic(ctx context.Context, r *driver.RegistryDefault, cmd *cobra.Comm
 	n.UseFunc(semconv.Middleware)
 	n.Use(publicLogger)
 	n.Use(x.HTTPLoaderContextMiddleware(r))
+	n.Use(x.AcceptLanguageContextMiddleware())
 	n.Use(sqa(ctx, cmd, r))
 
 	router := x.NewRouterPublic(r)
@@ -156,6 +158,7 @@ func serveAdmin(ctx context.Context, r *driver.RegistryDefault, cmd *cobra.Comma
 	n.Use(adminLogger)
 	n.UseFunc(x.RedirectAdminMiddleware)
 	n.Use(x.HTTPLoaderContextMiddleware(r))
+	n.Use(x.AcceptLanguageContextMiddleware())
 	n.Use(sqa(ctx, cmd, r))
 
 	router := x.NewRouterAdmin(r)
@@ -313,6 +316,15 @@ func courierTask(ctx context.Context, d driver.Registry) func() error {
 	}
 }
 
+func outboxTask(ctx context.Context, d driver.Registry) func() error {
+	return func() error {
+		if d.Config().IsBackgroundOutboxEnabled(ctx) {
+			return outbox.Watch(ctx, d)
+		}
+		return nil
+	}
+}
+
 func ServeAll(d *driver.RegistryDefault) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, _ []string) error {
 		ctx := cmd.Context()
@@ -332,6 +344,7 @@ func ServeAll(d *driver.RegistryDefault) func(cmd *cobra.Command, args []string)
 			publicSrv,
 			adminSrv,
 			courierTask(ctx, d),
+			outboxTask(ctx, d),
 		}
 		for _, task := range tasks {
 			g.Go(task)
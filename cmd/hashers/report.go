@@ -0,0 +1,155 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hashers
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ory/kratos/driver"
+	"github.com/ory/kratos/hash"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/configx"
+)
+
+type (
+	// HashReportRow counts the identities whose password is hashed with an
+	// algorithm.
+	HashReportRow struct {
+		Algorithm string `json:"algorithm"`
+
+		// Identities is the number of identities with a password hashed by
+		// the algorithm.
+		Identities int `json:"identities"`
+
+		// Outdated is the number of these identities whose password hash is
+		// upgraded on their next login, because it is not generated by the
+		// configured hasher or with weaker parameters.
+		Outdated int `json:"outdated"`
+	}
+	HashReport []HashReportRow
+)
+
+func (HashReport) Header() []string {
+	return []string{"ALGORITHM", "IDENTITIES", "OUTDATED"}
+}
+
+func (r HashReport) Table() [][]string {
+	rows := make([][]string, len(r))
+	for k, row := range r {
+		rows[k] = []string{row.Algorithm, strconv.Itoa(row.Identities), strconv.Itoa(row.Outdated)}
+	}
+	return rows
+}
+
+func (r HashReport) Interface() interface{} {
+	return r
+}
+
+func (r HashReport) Len() int {
+	return len(r)
+}
+
+func NewReportCmd(dOpts []driver.RegistryOption) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "report",
+		Short: "Count identities per password hash algorithm",
+		Long: `Counts the identities per algorithm their password is hashed with, and how many of
+these hashes are upgraded to the configured hasher on the next login of the identity.
+
+Identities with a password that is verified by the password migration hook are
+listed with the algorithm "none".`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := driver.New(cmd.Context(), cmd.ErrOrStderr(), append(dOpts, driver.WithConfigOptions(configx.WithFlags(cmd.Flags())))...)
+			if err != nil {
+				return err
+			}
+
+			batchSize, err := cmd.Flags().GetInt("batch-size")
+			if err != nil {
+				return err
+			}
+
+			report, err := Report(cmd.Context(), r, batchSize)
+			if err != nil {
+				return err
+			}
+
+			cmdx.PrintTable(cmd, report)
+			return nil
+		},
+	}
+	configx.RegisterFlags(c.PersistentFlags())
+	cmdx.RegisterFormatFlags(c.Flags())
+	c.Flags().IntP("batch-size", "b", 1000, "The number of credentials to load per batch")
+	return c
+}
+
+type reportDependencies interface {
+	identity.PrivilegedPoolProvider
+	hash.HashProvider
+}
+
+// Report counts the identities per password hash algorithm, loading the
+// password credentials in batches of batchSize credentials.
+func Report(ctx context.Context, r reportDependencies, batchSize int) (HashReport, error) {
+	if batchSize < 1 {
+		return nil, fmt.Errorf("the batch size must be positive, got %d", batchSize)
+	}
+
+	rows := map[string]*HashReportRow{}
+	var after uuid.UUID
+	for {
+		credentials, err := r.PrivilegedIdentityPool().ListCredentialsByType(ctx, identity.CredentialsTypePassword, after, batchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range credentials {
+			var o identity.CredentialsPassword
+			if err := json.Unmarshal(c.Config, &o); err != nil {
+				return nil, errors.Wrapf(err, "unable to decode the password credentials %s", c.ID)
+			}
+
+			algorithm := "none"
+			if o.HashedPassword != "" {
+				algorithm = hash.AlgorithmName([]byte(o.HashedPassword))
+			}
+
+			row, ok := rows[algorithm]
+			if !ok {
+				row = &HashReportRow{Algorithm: algorithm}
+				rows[algorithm] = row
+			}
+			row.Identities++
+			if o.HashedPassword != "" && r.Hasher(ctx).NeedsRehash(ctx, []byte(o.HashedPassword)) {
+				row.Outdated++
+			}
+		}
+
+		if len(credentials) < batchSize {
+			break
+		}
+		after = credentials[len(credentials)-1].ID
+	}
+
+	report := make(HashReport, 0, len(rows))
+	for _, row := range rows {
+		report = append(report, *row)
+	}
+	slices.SortFunc(report, func(a, b HashReportRow) int {
+		return cmp.Or(b.Identities-a.Identities, strings.Compare(a.Algorithm, b.Algorithm))
+	})
+	return report, nil
+}
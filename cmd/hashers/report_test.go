@@ -0,0 +1,58 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package hashers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/cmd/hashers"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/hash"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/x/sqlxx"
+)
+
+func TestReport(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchemaFromRaw(conf, []byte(`{"type": "object", "properties": {"traits": {"type": "object"}}}`))
+
+	current, err := reg.Hasher(ctx).Generate(ctx, []byte("password"))
+	require.NoError(t, err)
+	weak, err := (&hash.Pbkdf2{Algorithm: "sha256", Iterations: 1000, SaltLength: 16, KeyLength: 32}).Generate(ctx, []byte("password"))
+	require.NoError(t, err)
+
+	for k, credentials := range []string{
+		`{"hashed_password": "` + string(current) + `"}`,
+		`{"hashed_password": "` + string(current) + `"}`,
+		`{"hashed_password": "` + string(weak) + `"}`,
+		`{"use_password_migration_hook": true}`,
+	} {
+		i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+		i.SetCredentials(identity.CredentialsTypePassword, identity.Credentials{
+			Type:        identity.CredentialsTypePassword,
+			Identifiers: []string{fmt.Sprintf("report-%d@ory.sh", k)},
+			Config:      sqlxx.JSONRawMessage(credentials),
+		})
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+	}
+
+	report, err := hashers.Report(ctx, reg, 3)
+	require.NoError(t, err)
+	assert.Equal(t, hashers.HashReport{
+		{Algorithm: "bcrypt", Identities: 2},
+		{Algorithm: "none", Identities: 1},
+		{Algorithm: "pbkdf2", Identities: 1, Outdated: 1},
+	}, report)
+
+	_, err = hashers.Report(ctx, reg, 0)
+	require.Error(t, err)
+}
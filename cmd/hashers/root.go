@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/ory/kratos/cmd/hashers/argon2"
+	"github.com/ory/kratos/driver"
 )
 
 func NewRootCmd() *cobra.Command {
@@ -17,9 +18,10 @@ func NewRootCmd() *cobra.Command {
 	return c
 }
 
-func RegisterCommandRecursive(parent *cobra.Command) {
+func RegisterCommandRecursive(parent *cobra.Command, dOpts []driver.RegistryOption) {
 	rootCmd := NewRootCmd()
 	parent.AddCommand(rootCmd)
 
 	argon2.RegisterCommandRecursive(rootCmd)
+	rootCmd.AddCommand(NewReportCmd(dOpts))
 }
@@ -0,0 +1,128 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package identities
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ory/kratos/cmd/cliclient"
+	kratos "github.com/ory/kratos/internal/httpclient"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/pagination/keysetpagination"
+	"github.com/ory/x/stringsx"
+	"github.com/ory/x/urlx"
+)
+
+func NewExportCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export resources",
+	}
+	cmd.AddCommand(NewExportIdentitiesCmd())
+	cliclient.RegisterClientFlags(cmd.PersistentFlags())
+	return cmd
+}
+
+func NewExportIdentitiesCmd() *cobra.Command {
+	var includeCreds []string
+
+	cmd := &cobra.Command{
+		Use:   "identities",
+		Short: "Export all identities as newline-delimited JSON",
+		Long: `Export all identities to STD_OUT, one identity per line.
+
+Every line can be imported again using "... import identities". Password hashes and the encrypted OpenID Connect tokens are only exported when requested with --include-credentials. Exported OpenID Connect tokens can only be used by a deployment sharing the same cipher secrets.
+
+Identities are exported page by page. If the export is interrupted, it can be resumed with the page token printed to STD_ERR.`,
+		Example: `{{ .CommandPath }} --include-credentials password --include-credentials oidc > identities.jsonl
+	{{ .Root.Name }} import identities identities.jsonl`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := cliclient.NewClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			for _, opt := range includeCreds {
+				e := stringsx.SwitchExact(opt)
+				if !e.AddCase("password", "oidc") {
+					cmd.PrintErrln(`You have to put a valid value of credentials type to be included, try --help for details.`)
+					return cmdx.FailSilently(cmd)
+				}
+			}
+
+			pageToken, pageSize, err := cmdx.ParseTokenPaginationArgs(cmd)
+			if err != nil {
+				return err
+			}
+
+			for {
+				next, err := exportIdentitiesPage(cmd, c.GetConfig(), pageToken, pageSize, includeCreds)
+				if err != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Could not export identities: %s\nResume the export with: --%s %q\n", err, cmdx.FlagPageToken, pageToken)
+					return cmdx.FailSilently(cmd)
+				}
+				if next == "" {
+					return nil
+				}
+				pageToken = next
+			}
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&includeCreds, FlagIncludeCreds, "i", []string{}, `Include credential secrets, either "password" or "oidc"`)
+	cmdx.RegisterTokenPaginationFlags(cmd)
+	return cmd
+}
+
+// exportIdentitiesPage writes a page of exported identities to STD_OUT and returns
+// the token of the next page, which is empty on the last page.
+func exportIdentitiesPage(cmd *cobra.Command, conf *kratos.Configuration, pageToken string, pageSize int, includeCreds []string) (string, error) {
+	endpoint, err := url.Parse(conf.Servers[0].URL)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	q := url.Values{"page_size": {strconv.Itoa(pageSize)}, "include_credential": includeCreds}
+	if pageToken != "" {
+		q.Set("page_token", pageToken)
+	}
+	u := urlx.AppendPaths(endpoint, "/admin/identities/export")
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(cmd.Context(), "GET", u.String(), nil)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	res, err := conf.HTTPClient.Do(req)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return "", errors.Errorf("the server responded with status code %d: %s", res.StatusCode, body)
+	}
+
+	// Only complete pages are written, so that the export can be resumed
+	// from the page token without duplicating identities.
+	page, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if _, err := cmd.OutOrStdout().Write(page); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return keysetpagination.ParseHeader(res).NextToken, nil
+}
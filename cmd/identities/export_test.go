@@ -0,0 +1,68 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package identities_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/cmd/cliclient"
+	"github.com/ory/kratos/cmd/identities"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/x/cmdx"
+)
+
+func TestExportCmd(t *testing.T) {
+	reg, cmd := setup(t, identities.NewExportIdentitiesCmd)
+
+	_, ids := makeIdentities(t, reg, 5)
+
+	t.Run("case=exports all identities page by page", func(t *testing.T) {
+		stdOut := cmd.ExecNoErr(t, "--page-size", "2")
+
+		lines := strings.Split(strings.TrimSpace(stdOut), "\n")
+		require.Len(t, lines, 5, stdOut)
+		for _, l := range lines {
+			assert.Equal(t, "bar", gjson.Get(l, "metadata_public.foo").String(), l)
+		}
+	})
+
+	t.Run("case=rejects unknown credentials", func(t *testing.T) {
+		_, _, err := cmd.Exec(nil, "--include-credentials", "totp")
+		require.Error(t, err)
+	})
+
+	t.Run("case=the export can be imported", func(t *testing.T) {
+		stdOut := cmd.ExecNoErr(t)
+
+		importCmd := &cmdx.CommandExecuter{
+			New: func() *cobra.Command {
+				cmd := identities.NewImportIdentitiesCmd()
+				cliclient.RegisterClientFlags(cmd.Flags())
+				cmdx.RegisterFormatFlags(cmd.Flags())
+				return cmd
+			},
+			PersistentArgs: cmd.PersistentArgs,
+		}
+		imported, stdErr, err := importCmd.Exec(bytes.NewBufferString(stdOut))
+		require.NoError(t, err, "%s %s", imported, stdErr)
+
+		importedIDs := gjson.Get(imported, "#.id").Array()
+		require.Len(t, importedIDs, len(ids), imported)
+		for _, id := range importedIDs {
+			assert.NotContains(t, ids, id.String())
+			i, err := reg.Persister().GetIdentity(context.Background(), uuid.FromStringOrNil(id.String()), identity.ExpandNothing)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"foo":"bar"}`, string(i.MetadataPublic))
+		}
+	})
+}
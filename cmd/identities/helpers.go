@@ -4,6 +4,9 @@
 package identities
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -16,13 +19,28 @@ import (
 
 func parseIdentities(raw []byte) (rawIdentities []string) {
 	res := gjson.ParseBytes(raw)
-	if !res.IsArray() {
+	if res.IsArray() {
+		res.ForEach(func(_, v gjson.Result) bool {
+			rawIdentities = append(rawIdentities, v.Raw)
+			return true
+		})
+		return
+	}
+
+	// The input can also contain one identity per line, as written by "export identities".
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	for {
+		var v json.RawMessage
+		if err := dec.Decode(&v); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return []string{res.Raw}
+		}
+		rawIdentities = append(rawIdentities, string(v))
+	}
+	if len(rawIdentities) == 0 {
 		return []string{res.Raw}
 	}
-	res.ForEach(func(_, v gjson.Result) bool {
-		rawIdentities = append(rawIdentities, v.Raw)
-		return true
-	})
 	return
 }
 
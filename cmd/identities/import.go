@@ -50,7 +50,7 @@ Alternatively:
 	cat file.json | {{ .CommandPath }}`,
 		Long: `Import identities from files or STD_IN.
 
-Files can contain only a single or an array of identities. The validity of files can be tested beforehand using "... identities validate".`,
+Files can contain a single identity, an array of identities, or one identity per line as written by "... export identities". The validity of files can be tested beforehand using "... identities validate".`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c, err := cliclient.NewClient(cmd)
 			if err != nil {
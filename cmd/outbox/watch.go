@@ -0,0 +1,50 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ory/graceful"
+	"github.com/ory/kratos/driver"
+	"github.com/ory/x/configx"
+)
+
+func NewWatchCmd(dOpts []driver.RegistryOption) *cobra.Command {
+	return &cobra.Command{
+		Use:   "watch",
+		Short: "Starts the Ory Kratos outbox dispatcher",
+		Long: `Starts the Ory Kratos outbox dispatcher, which delivers the recorded identity and session events to the configured sinks.
+
+Multiple dispatchers can run at the same time.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := driver.New(cmd.Context(), cmd.ErrOrStderr(), append(dOpts, driver.WithConfigOptions(configx.WithFlags(cmd.Flags())))...)
+			if err != nil {
+				return err
+			}
+
+			return Watch(cmd.Context(), r)
+		},
+	}
+}
+
+func Watch(ctx context.Context, r driver.Registry) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	r.Logger().Println("Outbox dispatcher started.")
+	if err := graceful.Graceful(func() error {
+		return r.OutboxDispatcher().Work(ctx)
+	}, func(_ context.Context) error {
+		cancel()
+		return nil
+	}); err != nil {
+		r.Logger().WithError(err).Error("Failed to run outbox dispatcher.")
+		return err
+	}
+
+	r.Logger().Println("Outbox dispatcher was shutdown gracefully.")
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package passwords
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ory/kratos/selfservice/strategy/password"
+)
+
+const (
+	FlagOutput            = "output"
+	FlagFalsePositiveRate = "false-positive-rate"
+	FlagExpectedItems     = "expected-items"
+	FlagMinCount          = "min-count"
+	FlagUpdate            = "update"
+)
+
+var rangeFileName = regexp.MustCompile(`^[0-9A-Fa-f]{5}\.txt$`)
+
+func NewBuildBreachFilterCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "build-breach-filter <dataset>",
+		Short: "Build a breach filter from the Pwned Passwords dataset",
+		Long: `Builds a breach filter from a local copy of the Pwned Passwords dataset, which can be
+configured as selfservice.methods.password.config.haveibeenpwned_dataset to check
+passwords against the dataset without network access.
+
+The dataset is either a directory with one file per hash prefix (e.g. 21BD1.txt),
+or a single file with one "<SHA-1 hash>:<count>" line per password, as created
+by the Pwned Passwords downloader.
+
+A breach filter is a bloom filter: it never misses a password which was added to
+it, but reports a password as breached with the configured false positive rate
+even though it was not. Only passwords breached at least --min-count times are
+added to the filter.
+
+Use --update to add the passwords of a newer dataset to an existing filter. The
+false positive rate rises if the filter holds more passwords than it was sized
+for with --expected-items.`,
+		Example: `kratos passwords build-breach-filter ./pwned-passwords --output /var/lib/kratos/breaches.bin`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString(FlagOutput)
+			rate, _ := cmd.Flags().GetFloat64(FlagFalsePositiveRate)
+			expected, _ := cmd.Flags().GetUint64(FlagExpectedItems)
+			minCount, _ := cmd.Flags().GetUint64(FlagMinCount)
+			update, _ := cmd.Flags().GetBool(FlagUpdate)
+
+			var f *password.BreachFilter
+			if update {
+				file, err := os.Open(output) // #nosec G304 -- the path is provided by the operator
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				f, err = password.ReadBreachFilter(bufio.NewReader(file))
+				_ = file.Close()
+				if err != nil {
+					return err
+				}
+				if cmd.Flags().Changed(FlagMinCount) && minCount != f.MinCount {
+					return errors.Errorf("the breach filter was built with a minimum count of %d, but --%s is %d", f.MinCount, FlagMinCount, minCount)
+				}
+			} else {
+				if expected == 0 {
+					var err error
+					if expected, err = CountBreaches(args[0]); err != nil {
+						return err
+					}
+				}
+
+				var err error
+				if f, err = password.NewBreachFilter(max(expected, 1), rate, minCount); err != nil {
+					return err
+				}
+			}
+
+			added, err := AddBreaches(f, args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := writeBreachFilter(f, output); err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Added %d passwords to the breach filter %s, which now holds %d passwords.\n", added, output, f.Items)
+			return nil
+		},
+	}
+
+	c.Flags().StringP(FlagOutput, "o", "breaches.bin", "The path of the breach filter.")
+	c.Flags().Float64(FlagFalsePositiveRate, 0.001, "The rate of passwords which are falsely reported as breached.")
+	c.Flags().Uint64(FlagExpectedItems, 0, "The number of passwords the filter is sized for. Defaults to the number of passwords in the dataset.")
+	c.Flags().Uint64(FlagMinCount, 1, "Only add passwords which were breached at least this many times.")
+	c.Flags().Bool(FlagUpdate, false, "Add the passwords to the existing breach filter at --output.")
+
+	return c
+}
+
+// walkDataset calls fn for every range of the dataset at path, with the hash
+// prefix of the range. The prefix is empty if the dataset is a single file.
+func walkDataset(path string, fn func(prefix string, r io.Reader) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if !info.IsDir() {
+		return readDatasetFile(path, "", fn)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !rangeFileName.MatchString(e.Name()) {
+			continue
+		}
+		if err := readDatasetFile(filepath.Join(path, e.Name()), strings.ToUpper(strings.TrimSuffix(e.Name(), ".txt")), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readDatasetFile(path, prefix string, fn func(prefix string, r io.Reader) error) error {
+	file, err := os.Open(path) // #nosec G304 -- the path is provided by the operator
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := fn(prefix, bufio.NewReader(file)); err != nil {
+		return errors.Wrapf(err, "unable to read %s", path)
+	}
+	return nil
+}
+
+// CountBreaches returns the number of passwords in the dataset at path.
+func CountBreaches(path string) (uint64, error) {
+	var count uint64
+	err := walkDataset(path, func(_ string, r io.Reader) error {
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			if len(bytes.TrimSpace(sc.Bytes())) > 0 {
+				count++
+			}
+		}
+		return errors.WithStack(sc.Err())
+	})
+	return count, err
+}
+
+// AddBreaches adds the passwords of the dataset at path to the breach filter,
+// and returns the number of added passwords.
+func AddBreaches(f *password.BreachFilter, path string) (int, error) {
+	var added int
+	err := walkDataset(path, func(prefix string, r io.Reader) error {
+		n, err := f.AddRange(prefix, r)
+		added += n
+		return err
+	})
+	return added, err
+}
+
+// writeBreachFilter replaces the breach filter at path atomically, so that
+// running servers never read a partially written filter.
+func writeBreachFilter(f *password.BreachFilter, path string) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	w := bufio.NewWriter(tmp)
+	if _, err := f.WriteTo(w); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return errors.WithStack(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmp.Name(), path))
+}
@@ -0,0 +1,110 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package passwords_test
+
+import (
+	"crypto/sha1" //#nosec G505 -- sha1 is used for k-anonymity
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/cmd/passwords"
+	"github.com/ory/kratos/selfservice/strategy/password"
+	"github.com/ory/x/cmdx"
+)
+
+func sha1Hex(pw string) string {
+	return fmt.Sprintf("%X", sha1.Sum([]byte(pw))) //#nosec G401 -- sha1 is used for k-anonymity
+}
+
+func sha1Bytes(pw string) []byte {
+	h := sha1.Sum([]byte(pw)) //#nosec G401 -- sha1 is used for k-anonymity
+	return h[:]
+}
+
+func readFilter(t *testing.T, path string) *password.BreachFilter {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	f, err := password.ReadBreachFilter(file)
+	require.NoError(t, err)
+	return f
+}
+
+func TestBuildBreachFilter(t *testing.T) {
+	breached := map[string]int{"password": 10, "123456": 1000, "hunter2": 2, "correct horse": 1}
+
+	// A dataset with one file per hash prefix.
+	rangeDir := t.TempDir()
+	for pw, count := range breached {
+		h := sha1Hex(pw)
+		file, err := os.OpenFile(filepath.Join(rangeDir, h[:5]+".txt"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		require.NoError(t, err)
+		_, err = fmt.Fprintf(file, "%s:%d\r\n", h[5:], count)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(rangeDir, "README.md"), []byte("not a range"), 0o600))
+
+	// A dataset in a single file.
+	single := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+	require.NoError(t, os.WriteFile(single, []byte(sha1Hex("letmein")+":5\n"+sha1Hex("trustno1")+":1\n"), 0o600))
+
+	t.Run("case=builds the filter from a range directory", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "breaches.bin")
+		stdout := cmdx.ExecNoErr(t, passwords.NewBuildBreachFilterCmd(), rangeDir, "--output", out)
+		assert.Contains(t, stdout, "Added 4 passwords")
+
+		f := readFilter(t, out)
+		assert.EqualValues(t, 4, f.Items)
+		for pw := range breached {
+			assert.True(t, f.Contains(sha1Bytes(pw)), pw)
+		}
+		assert.False(t, f.Contains(sha1Bytes("not breached at all")))
+	})
+
+	t.Run("case=only adds passwords breached at least min count times", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "breaches.bin")
+		cmdx.ExecNoErr(t, passwords.NewBuildBreachFilterCmd(), rangeDir, "--output", out, "--min-count", "10")
+
+		f := readFilter(t, out)
+		assert.EqualValues(t, 10, f.MinCount)
+		assert.EqualValues(t, 2, f.Items)
+		assert.True(t, f.Contains(sha1Bytes("password")))
+		assert.True(t, f.Contains(sha1Bytes("123456")))
+		assert.False(t, f.Contains(sha1Bytes("hunter2")))
+	})
+
+	t.Run("case=updates an existing filter", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "breaches.bin")
+		cmdx.ExecNoErr(t, passwords.NewBuildBreachFilterCmd(), rangeDir, "--output", out, "--expected-items", "100")
+		stdout := cmdx.ExecNoErr(t, passwords.NewBuildBreachFilterCmd(), single, "--output", out, "--update")
+		assert.Contains(t, stdout, "Added 2 passwords")
+
+		f := readFilter(t, out)
+		assert.EqualValues(t, 6, f.Items)
+		assert.True(t, f.Contains(sha1Bytes("password")))
+		assert.True(t, f.Contains(sha1Bytes("letmein")))
+		assert.True(t, f.Contains(sha1Bytes("trustno1")))
+	})
+
+	t.Run("case=rejects a different min count when updating", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "breaches.bin")
+		cmdx.ExecNoErr(t, passwords.NewBuildBreachFilterCmd(), single, "--output", out)
+		_, _, err := cmdx.Exec(t, passwords.NewBuildBreachFilterCmd(), nil, single, "--output", out, "--update", "--min-count", "3")
+		require.ErrorContains(t, err, "minimum count of 1")
+	})
+
+	t.Run("case=rejects invalid datasets", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "invalid.txt")
+		require.NoError(t, os.WriteFile(invalid, []byte("not-a-hash:1\n"), 0o600))
+		_, _, err := cmdx.Exec(t, passwords.NewBuildBreachFilterCmd(), nil, invalid, "--output", filepath.Join(t.TempDir(), "breaches.bin"))
+		require.ErrorContains(t, err, "expected a hex-encoded SHA-1 hash")
+	})
+}
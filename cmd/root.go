@@ -10,12 +10,15 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/ory/kratos/cmd/ciphers"
 	"github.com/ory/kratos/cmd/cleanup"
 	"github.com/ory/kratos/cmd/courier"
 	"github.com/ory/kratos/cmd/hashers"
 	"github.com/ory/kratos/cmd/identities"
 	"github.com/ory/kratos/cmd/jsonnet"
 	"github.com/ory/kratos/cmd/migrate"
+	"github.com/ory/kratos/cmd/outbox"
+	"github.com/ory/kratos/cmd/passwords"
 	"github.com/ory/kratos/cmd/remote"
 	"github.com/ory/kratos/cmd/serve"
 	"github.com/ory/kratos/driver"
@@ -31,15 +34,19 @@ func NewRootCmd(driverOpts ...driver.RegistryOption) (cmd *cobra.Command) {
 	}
 	cmdx.EnableUsageTemplating(cmd)
 
+	ciphers.RegisterCommandRecursive(cmd, driverOpts)
 	courier.RegisterCommandRecursive(cmd, driverOpts)
 	cmd.AddCommand(identities.NewGetCmd())
 	cmd.AddCommand(identities.NewDeleteCmd())
+	cmd.AddCommand(identities.NewExportCmd())
 	cmd.AddCommand(jsonnet.NewFormatCmd())
-	hashers.RegisterCommandRecursive(cmd)
+	hashers.RegisterCommandRecursive(cmd, driverOpts)
 	cmd.AddCommand(identities.NewImportCmd())
 	cmd.AddCommand(jsonnet.NewLintCmd())
 	cmd.AddCommand(identities.NewListCmd())
 	migrate.RegisterCommandRecursive(cmd)
+	outbox.RegisterCommandRecursive(cmd, driverOpts)
+	passwords.RegisterCommandRecursive(cmd)
 	serve.RegisterCommandRecursive(cmd, driverOpts)
 	cleanup.RegisterCommandRecursive(cmd)
 	remote.RegisterCommandRecursive(cmd)
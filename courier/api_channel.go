@@ -0,0 +1,186 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package courier
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gofrs/uuid"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/x/otelx"
+	"github.com/ory/x/urlx"
+)
+
+// The message ID is passed to the provider as custom data, so that delivery
+// status callbacks can be matched with the message.
+const apiMessageIDKey = "kratos_message_id"
+
+type (
+	// apiChannel delivers messages through the HTTP API of an email or SMS
+	// provider.
+	apiChannel struct {
+		id       string
+		typ      string
+		conf     *config.CourierAPIConfig
+		provider apiProvider
+		d        channelDependencies
+	}
+	apiProvider interface {
+		// messageType is the type of messages the provider delivers.
+		messageType() MessageType
+
+		// newRequest builds the request which sends out the message.
+		newRequest(ctx context.Context, c *apiChannel, msg Message) (*retryablehttp.Request, error)
+
+		// parseCallback returns the delivery events reported by a status
+		// callback of the provider.
+		parseCallback(ctx context.Context, c *apiChannel, r *http.Request) ([]DeliveryEvent, error)
+	}
+
+	// DeliveryEventType is the outcome of a delivery as reported by the provider.
+	DeliveryEventType string
+
+	// DeliveryEvent is a delivery status reported by the provider of a channel.
+	DeliveryEvent struct {
+		MessageID uuid.UUID
+		Type      DeliveryEventType
+		Reason    string
+	}
+)
+
+const (
+	DeliveryEventDelivered  DeliveryEventType = "delivered"
+	DeliveryEventBounced    DeliveryEventType = "bounced"
+	DeliveryEventComplained DeliveryEventType = "complained"
+
+	// DeliveryEventFailed is a delivery failure which does not mean that
+	// the recipient is invalid, so the recipient is not suppressed.
+	DeliveryEventFailed DeliveryEventType = "failed"
+)
+
+var _ Channel = new(apiChannel)
+
+func newAPIProvider(typ string) (apiProvider, bool) {
+	switch typ {
+	case "sendgrid":
+		return new(sendgridProvider), true
+	case "mailgun":
+		return new(mailgunProvider), true
+	case "ses":
+		return new(sesProvider), true
+	case "twilio":
+		return new(twilioProvider), true
+	}
+	return nil, false
+}
+
+func newAPIChannel(channel *config.CourierChannel, d channelDependencies) (*apiChannel, error) {
+	provider, ok := newAPIProvider(channel.Type)
+	if !ok {
+		return nil, errors.Errorf("unknown courier channel type: %s", channel.Type)
+	}
+	if channel.APIConfig == nil {
+		return nil, errors.Errorf("courier channel %s of type %s requires an api_config", channel.ID, channel.Type)
+	}
+
+	return &apiChannel{
+		id:       channel.ID,
+		typ:      channel.Type,
+		conf:     channel.APIConfig,
+		provider: provider,
+		d:        d,
+	}, nil
+}
+
+func (c *apiChannel) ID() string {
+	return c.id
+}
+
+// Status returns the message status the delivery event results in.
+func (e DeliveryEvent) Status() MessageStatus {
+	if e.Type == DeliveryEventDelivered {
+		return MessageStatusDelivered
+	}
+	return MessageStatusBounced
+}
+
+func (c *apiChannel) Dispatch(ctx context.Context, msg Message) (err error) {
+	ctx, span := c.d.Tracer(ctx).Tracer().Start(ctx, "courier.apiChannel.Dispatch")
+	defer otelx.End(span, &err)
+
+	if msg.Type != c.provider.messageType() {
+		return errors.WithStack(herodot.ErrInternalServerError.
+			WithReasonf("Courier channel %s of type %s can not deliver messages of type %s.", c.id, c.typ, msg.Type))
+	}
+
+	req, err := c.provider.newRequest(ctx, c, msg)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.d.HTTPClient(ctx).Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	logger := c.d.Logger().
+		WithField("channel", c.id).
+		WithField("channel_type", c.typ).
+		WithField("message_id", msg.ID).
+		WithField("message_nid", msg.NID).
+		WithField("message_type", msg.Type).
+		WithField("message_template_type", msg.TemplateType).
+		WithField("message_subject", msg.Subject)
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		logger.Debug("Courier sent out message.")
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	logger.
+		WithField("http_response_code", res.StatusCode).
+		WithField("http_response_body", string(body)).
+		Error("Sending message via provider API failed.")
+
+	return errors.WithStack(herodot.ErrInternalServerError.
+		WithReasonf("failed to send message via %s", c.typ).
+		WithDetail("status_code", res.StatusCode))
+}
+
+// htmlBody renders the HTML version of an email message. Messages are sent
+// as plain text only if it is not available.
+func (c *apiChannel) htmlBody(ctx context.Context, msg Message) string {
+	tmpl, err := NewEmailTemplateFromMessage(c.d, msg)
+	if err != nil {
+		c.d.Logger().WithError(err).Error("Unable to get email template from message.")
+		return ""
+	}
+	body, err := tmpl.EmailBody(ctx)
+	if err != nil {
+		c.d.Logger().WithError(err).Error("Unable to get email HTML body from template.")
+		return ""
+	}
+	return body
+}
+
+// endpoint returns the URL of the API resource. The configured URL replaces
+// the default URL of the provider.
+func (c *apiChannel) endpoint(defaultURL string, paths ...string) (string, error) {
+	u, err := url.Parse(cmp.Or(c.conf.URL, defaultURL))
+	if err != nil {
+		return "", errors.WithStack(herodot.ErrInternalServerError.WithWrap(err).
+			WithReasonf("The URL of courier channel %s is invalid: %s", c.id, err))
+	}
+	return urlx.AppendPaths(u, paths...).String(), nil
+}
@@ -0,0 +1,558 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package courier_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/courier"
+	"github.com/ory/kratos/courier/template/email"
+	"github.com/ory/kratos/courier/template/sms"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+)
+
+func TestAPIChannels(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	publicTS, _ := testhelpers.NewKratosServerWithCSRF(t, reg)
+	conf.MustSet(ctx, config.ViperKeyPublicBaseURL, publicTS.URL)
+
+	const callbackToken = "callback-token-0123456789"
+
+	var (
+		lock    sync.Mutex
+		request *http.Request
+		body    []byte
+	)
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		request = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(provider.Close)
+
+	apiConfig := map[string]any{
+		"url":            provider.URL,
+		"key":            "test-key",
+		"secret":         "test-secret",
+		"region":         "eu-central-1",
+		"domain":         "mg.example.org",
+		"from_address":   "no-reply@example.org",
+		"from_name":      "Example",
+		"callback_token": callbackToken,
+	}
+
+	type callback struct {
+		contentType string
+		query       url.Values
+		header      http.Header
+		body        string
+	}
+
+	callbackURL := func(channel string, query url.Values) string {
+		u := publicTS.URL + strings.Replace(courier.RouteCourierCallback, "{channel}", channel, 1)
+		if len(query) > 0 {
+			u += "?" + query.Encode()
+		}
+		return u
+	}
+
+	postCallback := func(t *testing.T, channel string, cb callback, token string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest("POST", callbackURL(channel, cb.query), strings.NewReader(cb.body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", cb.contentType)
+		for k, v := range cb.header {
+			req.Header[k] = v
+		}
+		if token != "" {
+			req.SetBasicAuth("kratos", token)
+		}
+		res, err := publicTS.Client().Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	sendCallback := func(t *testing.T, channel string, cb callback, expectCode int) {
+		t.Helper()
+		res := postCallback(t, channel, cb, callbackToken)
+		resBody, _ := io.ReadAll(res.Body)
+		require.Equalf(t, expectCode, res.StatusCode, "%s", resBody)
+	}
+
+	jsonCallback := func(v any) callback {
+		raw, _ := json.Marshal(v)
+		return callback{contentType: "application/json", body: string(raw)}
+	}
+
+	// Amazon SNS signs its messages with the key of a certificate which is
+	// served by SNS.
+	snsKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	snsCertDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{SerialNumber: big.NewInt(1)}, &x509.Certificate{SerialNumber: big.NewInt(1)}, &snsKey.PublicKey, snsKey)
+	require.NoError(t, err)
+	snsCert, err := x509.ParseCertificate(snsCertDER)
+	require.NoError(t, err)
+	const snsCertURL = "https://sns.eu-central-1.amazonaws.com/SimpleNotificationService-test.pem"
+	courier.SetSNSCertificateForTest(t, snsCertURL, snsCert)
+
+	signedSNSCallback := func(notification map[string]string) callback {
+		notification["MessageId"] = uuid.Must(uuid.NewV4()).String()
+		notification["TopicArn"] = "arn:aws:sns:eu-central-1:123456789012:ses"
+		notification["Timestamp"] = time.Now().UTC().Format(time.RFC3339)
+		notification["SignatureVersion"] = "2"
+		notification["SigningCertURL"] = snsCertURL
+
+		keys := []string{"Message", "MessageId", "Subject", "SubscribeURL", "Timestamp", "Token", "TopicArn", "Type"}
+		if notification["Type"] == "Notification" {
+			keys = slices.DeleteFunc(keys, func(k string) bool { return k == "SubscribeURL" || k == "Token" })
+		}
+		var signed string
+		for _, k := range keys {
+			if v, ok := notification[k]; ok {
+				signed += k + "\n" + v + "\n"
+			}
+		}
+		digest := sha256.Sum256([]byte(signed))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, snsKey, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		notification["Signature"] = base64.StdEncoding.EncodeToString(signature)
+
+		return jsonCallback(notification)
+	}
+
+	snsCallback := func(event string, id uuid.UUID, extra map[string]any) callback {
+		message := map[string]any{
+			"eventType": event,
+			"mail":      map[string]any{"tags": map[string][]string{"kratos_message_id": {id.String()}}},
+		}
+		for k, v := range extra {
+			message[k] = v
+		}
+		raw, _ := json.Marshal(message)
+		return signedSNSCallback(map[string]string{"Type": "Notification", "Message": string(raw)})
+	}
+
+	// Twilio signs the callback URL and the sorted form parameters.
+	twilioCallback := func(id uuid.UUID, form url.Values) callback {
+		query := url.Values{"kratos_message_id": {id.String()}}
+		signed := callbackURL("sms", query)
+		keys := slices.Sorted(maps.Keys(form))
+		for _, k := range keys {
+			signed += k + form.Get(k)
+		}
+		mac := hmac.New(sha1.New, []byte("test-secret"))
+		_, _ = mac.Write([]byte(signed))
+
+		return callback{
+			contentType: "application/x-www-form-urlencoded",
+			query:       query,
+			header:      http.Header{"X-Twilio-Signature": {base64.StdEncoding.EncodeToString(mac.Sum(nil))}},
+			body:        form.Encode(),
+		}
+	}
+
+	for _, tc := range []struct {
+		typ           string
+		channel       string
+		assertRequest func(t *testing.T, r *http.Request, body []byte, id uuid.UUID)
+		delivered     func(id uuid.UUID) callback
+		bounced       func(id uuid.UUID) callback
+	}{
+		{
+			typ:     "sendgrid",
+			channel: "email",
+			assertRequest: func(t *testing.T, r *http.Request, body []byte, id uuid.UUID) {
+				assert.Equal(t, "/v3/mail/send", r.URL.Path)
+				assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+				assert.Equal(t, "test-recipient@example.org", gjson.GetBytes(body, "personalizations.0.to.0.email").String())
+				assert.Equal(t, id.String(), gjson.GetBytes(body, "personalizations.0.custom_args.kratos_message_id").String())
+				assert.Contains(t, gjson.GetBytes(body, "subject").String(), "test-subject")
+				assert.Contains(t, gjson.GetBytes(body, "content.0.value").String(), "test-body")
+			},
+			delivered: func(id uuid.UUID) callback {
+				return jsonCallback([]map[string]any{
+					{"event": "processed", "kratos_message_id": id},
+					{"event": "delivered", "kratos_message_id": id},
+				})
+			},
+			bounced: func(id uuid.UUID) callback {
+				return jsonCallback([]map[string]any{
+					{"event": "bounce", "type": "blocked", "kratos_message_id": id},
+					{"event": "bounce", "type": "bounce", "reason": "550 mailbox unavailable", "kratos_message_id": id},
+				})
+			},
+		},
+		{
+			typ:     "mailgun",
+			channel: "email",
+			assertRequest: func(t *testing.T, r *http.Request, body []byte, id uuid.UUID) {
+				assert.Equal(t, "/v3/mg.example.org/messages", r.URL.Path)
+				user, password, _ := r.BasicAuth()
+				assert.Equal(t, "api", user)
+				assert.Equal(t, "test-key", password)
+
+				form, err := url.ParseQuery(string(body))
+				require.NoError(t, err)
+				assert.Equal(t, `"Example" <no-reply@example.org>`, form.Get("from"))
+				assert.Equal(t, "test-recipient@example.org", form.Get("to"))
+				assert.Equal(t, id.String(), form.Get("v:kratos_message_id"))
+			},
+			delivered: func(id uuid.UUID) callback {
+				return jsonCallback(map[string]any{"event-data": map[string]any{
+					"event":          "delivered",
+					"user-variables": map[string]any{"kratos_message_id": id},
+				}})
+			},
+			bounced: func(id uuid.UUID) callback {
+				return jsonCallback(map[string]any{"event-data": map[string]any{
+					"event":           "failed",
+					"severity":        "permanent",
+					"delivery-status": map[string]any{"message": "550 mailbox unavailable"},
+					"user-variables":  map[string]any{"kratos_message_id": id},
+				}})
+			},
+		},
+		{
+			typ:     "ses",
+			channel: "email",
+			assertRequest: func(t *testing.T, r *http.Request, body []byte, id uuid.UUID) {
+				assert.Equal(t, "/v2/email/outbound-emails", r.URL.Path)
+				assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=test-key/"), r.Header.Get("Authorization"))
+				assert.Contains(t, r.Header.Get("Authorization"), "/eu-central-1/ses/aws4_request")
+				assert.Equal(t, "test-recipient@example.org", gjson.GetBytes(body, "Destination.ToAddresses.0").String())
+				assert.Equal(t, id.String(), gjson.GetBytes(body, "EmailTags.0.Value").String())
+				assert.Contains(t, gjson.GetBytes(body, "Content.Simple.Body.Text.Data").String(), "test-body")
+			},
+			delivered: func(id uuid.UUID) callback {
+				return snsCallback("Delivery", id, nil)
+			},
+			bounced: func(id uuid.UUID) callback {
+				return snsCallback("Bounce", id, map[string]any{"bounce": map[string]any{"bounceType": "Permanent", "bounceSubType": "General"}})
+			},
+		},
+		{
+			typ:     "twilio",
+			channel: "sms",
+			assertRequest: func(t *testing.T, r *http.Request, body []byte, id uuid.UUID) {
+				assert.Equal(t, "/2010-04-01/Accounts/test-key/Messages.json", r.URL.Path)
+				user, password, _ := r.BasicAuth()
+				assert.Equal(t, "test-key", user)
+				assert.Equal(t, "test-secret", password)
+
+				form, err := url.ParseQuery(string(body))
+				require.NoError(t, err)
+				assert.Equal(t, "+12065550101", form.Get("To"))
+				assert.Contains(t, form.Get("Body"), "test-body")
+				statusCallback, err := url.Parse(form.Get("StatusCallback"))
+				require.NoError(t, err)
+				token, _ := statusCallback.User.Password()
+				assert.Equal(t, callbackToken, token, "the callback token must be sent with basic authentication")
+				statusCallback.User = nil
+				assert.Equal(t, callbackURL("sms", url.Values{"kratos_message_id": {id.String()}}), statusCallback.String())
+			},
+			delivered: func(id uuid.UUID) callback {
+				return twilioCallback(id, url.Values{"MessageStatus": {"delivered"}})
+			},
+			bounced: func(id uuid.UUID) callback {
+				return twilioCallback(id, url.Values{"MessageStatus": {"undelivered"}, "ErrorCode": {"30005"}})
+			},
+		},
+	} {
+		t.Run("type="+tc.typ, func(t *testing.T) {
+			if tc.channel == "email" {
+				conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, tc.typ)
+				conf.MustSet(ctx, config.ViperKeyCourierAPIConfig, apiConfig)
+			} else {
+				conf.MustSet(ctx, config.ViperKeyCourierChannels, []map[string]any{{
+					"id":         tc.channel,
+					"type":       tc.typ,
+					"api_config": apiConfig,
+				}})
+			}
+
+			c, err := reg.Courier(ctx)
+			require.NoError(t, err)
+
+			queue := func(t *testing.T) (id uuid.UUID) {
+				if tc.channel == "email" {
+					id, err = c.QueueEmail(ctx, email.NewTestStub(reg, &email.TestStubModel{
+						To:      "test-recipient@example.org",
+						Subject: "test-subject",
+						Body:    "test-body",
+					}))
+				} else {
+					id, err = c.QueueSMS(ctx, sms.NewTestStub(reg, &sms.TestStubModel{
+						To:   "+12065550101",
+						Body: "test-body",
+					}))
+				}
+				require.NoError(t, err)
+				return id
+			}
+
+			send := func(t *testing.T) uuid.UUID {
+				id := queue(t)
+				require.NoError(t, c.DispatchQueue(ctx))
+
+				lock.Lock()
+				defer lock.Unlock()
+				require.NotNil(t, request)
+				tc.assertRequest(t, request, body, id)
+				return id
+			}
+
+			status := func(t *testing.T, id uuid.UUID) *courier.Message {
+				message, err := reg.CourierPersister().FetchMessage(ctx, id)
+				require.NoError(t, err)
+				return message
+			}
+
+			t.Run("case=delivered", func(t *testing.T) {
+				id := send(t)
+				assert.Equal(t, courier.MessageStatusSent, status(t, id).Status)
+
+				sendCallback(t, tc.channel, tc.delivered(id), http.StatusNoContent)
+				assert.Equal(t, courier.MessageStatusDelivered, status(t, id).Status)
+			})
+
+			t.Run("case=bounced", func(t *testing.T) {
+				id := send(t)
+
+				sendCallback(t, tc.channel, tc.bounced(id), http.StatusNoContent)
+				message := status(t, id)
+				assert.Equal(t, courier.MessageStatusBounced, message.Status)
+				require.Len(t, message.Dispatches, 2)
+				assert.Equal(t, courier.CourierMessageDispatchStatusFailed, message.Dispatches[0].Status)
+				assert.Equal(t, "The message bounced.", gjson.GetBytes(message.Dispatches[0].Error, "reason").String())
+
+				// Bounced messages are not marked as delivered again.
+				sendCallback(t, tc.channel, tc.delivered(id), http.StatusNoContent)
+				assert.Equal(t, courier.MessageStatusBounced, status(t, id).Status)
+
+				// The recipient is suppressed, so further messages are abandoned.
+				suppressions, _, err := reg.CourierPersister().ListSuppressions(ctx, courier.ListCourierSuppressionsParameters{Recipient: message.Recipient}, nil)
+				require.NoError(t, err)
+				require.Len(t, suppressions, 1)
+				assert.Equal(t, courier.SuppressionReasonBounced, suppressions[0].Reason)
+				assert.Equal(t, id, suppressions[0].MessageID.UUID)
+				t.Cleanup(func() {
+					require.NoError(t, reg.CourierPersister().DeleteSuppression(ctx, suppressions[0].ID))
+				})
+
+				id = queue(t)
+				require.NoError(t, c.DispatchQueue(ctx))
+				assert.Equal(t, courier.MessageStatusAbandoned, status(t, id).Status)
+			})
+
+			t.Run("case=invalid token", func(t *testing.T) {
+				res := postCallback(t, tc.channel, jsonCallback(map[string]any{}), "wrong")
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+				assert.Equal(t, `Basic realm="courier"`, res.Header.Get("WWW-Authenticate"))
+			})
+
+			t.Run("case=token in the query", func(t *testing.T) {
+				cb := tc.delivered(send(t))
+				cb.query = url.Values{"token": {callbackToken}}
+				res := postCallback(t, tc.channel, cb, "")
+				assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+			})
+
+			t.Run("case=bearer token", func(t *testing.T) {
+				id := send(t)
+				cb := tc.delivered(id)
+				cb.header = cb.header.Clone()
+				if cb.header == nil {
+					cb.header = http.Header{}
+				}
+				cb.header.Set("Authorization", "Bearer "+callbackToken)
+				res := postCallback(t, tc.channel, cb, "")
+				assert.Equal(t, http.StatusNoContent, res.StatusCode)
+				assert.Equal(t, courier.MessageStatusDelivered, status(t, id).Status)
+			})
+		})
+	}
+
+	t.Run("case=unknown channel", func(t *testing.T) {
+		sendCallback(t, "unknown", jsonCallback(map[string]any{}), http.StatusNotFound)
+	})
+
+	t.Run("case=rejects SNS subscriptions of other hosts", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "ses")
+		conf.MustSet(ctx, config.ViperKeyCourierAPIConfig, apiConfig)
+
+		sendCallback(t, "email", signedSNSCallback(map[string]string{
+			"Type":         "SubscriptionConfirmation",
+			"Message":      "You have chosen to subscribe to the topic.",
+			"Token":        "token",
+			"SubscribeURL": provider.URL,
+		}), http.StatusBadRequest)
+	})
+
+	t.Run("case=rejects SNS messages without valid signature", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "ses")
+		conf.MustSet(ctx, config.ViperKeyCourierAPIConfig, apiConfig)
+		id := uuid.Must(uuid.NewV4())
+
+		t.Run("case=unsigned", func(t *testing.T) {
+			sendCallback(t, "email", jsonCallback(map[string]any{"Type": "Notification", "Message": "{}"}), http.StatusUnauthorized)
+		})
+
+		t.Run("case=tampered", func(t *testing.T) {
+			cb := snsCallback("Delivery", id, nil)
+			cb.body = strings.Replace(cb.body, "Delivery", "Bounce", 1)
+			sendCallback(t, "email", cb, http.StatusUnauthorized)
+		})
+
+		t.Run("case=certificate of other hosts", func(t *testing.T) {
+			var notification map[string]string
+			require.NoError(t, json.Unmarshal([]byte(snsCallback("Delivery", id, nil).body), &notification))
+			notification["SigningCertURL"] = "https://sns.eu-central-1.amazonaws.com.example.org/cert.pem"
+			sendCallback(t, "email", jsonCallback(notification), http.StatusUnauthorized)
+		})
+	})
+
+	t.Run("case=temporary Twilio failures do not suppress the recipient", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyCourierChannels, []map[string]any{{
+			"id":         "sms",
+			"type":       "twilio",
+			"api_config": apiConfig,
+		}})
+		c, err := reg.Courier(ctx)
+		require.NoError(t, err)
+
+		id, err := c.QueueSMS(ctx, sms.NewTestStub(reg, &sms.TestStubModel{
+			To:   "+12065550102",
+			Body: "test-body",
+		}))
+		require.NoError(t, err)
+		require.NoError(t, c.DispatchQueue(ctx))
+
+		sendCallback(t, "sms", twilioCallback(id, url.Values{"MessageStatus": {"undelivered"}, "ErrorCode": {"30003"}}), http.StatusNoContent)
+
+		message, err := reg.CourierPersister().FetchMessage(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, courier.MessageStatusBounced, message.Status)
+		require.NotEmpty(t, message.Dispatches)
+		assert.Equal(t, "The message could not be delivered.", gjson.GetBytes(message.Dispatches[0].Error, "reason").String())
+
+		suppressions, _, err := reg.CourierPersister().ListSuppressions(ctx, courier.ListCourierSuppressionsParameters{Recipient: "+12065550102"}, nil)
+		require.NoError(t, err)
+		assert.Empty(t, suppressions)
+	})
+
+	t.Run("case=rejects Twilio callbacks without valid signature", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyCourierChannels, []map[string]any{{
+			"id":         "sms",
+			"type":       "twilio",
+			"api_config": apiConfig,
+		}})
+
+		cb := twilioCallback(uuid.Must(uuid.NewV4()), url.Values{"MessageStatus": {"delivered"}})
+		cb.body = url.Values{"MessageStatus": {"undelivered"}}.Encode()
+		sendCallback(t, "sms", cb, http.StatusUnauthorized)
+	})
+
+	t.Run("case=verifies SendGrid signatures", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+
+		conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "sendgrid")
+		conf.MustSet(ctx, config.ViperKeyCourierAPIConfig, merge(apiConfig, map[string]any{
+			"callback_signing_key": base64.StdEncoding.EncodeToString(der),
+		}))
+
+		signed := func(t *testing.T, timestamp time.Time, body string) callback {
+			ts := strconv.FormatInt(timestamp.Unix(), 10)
+			digest := sha256.Sum256([]byte(ts + body))
+			signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+			require.NoError(t, err)
+			return callback{
+				contentType: "application/json",
+				header: http.Header{
+					"X-Twilio-Email-Event-Webhook-Signature": {base64.StdEncoding.EncodeToString(signature)},
+					"X-Twilio-Email-Event-Webhook-Timestamp": {ts},
+				},
+				body: body,
+			}
+		}
+
+		body := `[{"event":"processed","kratos_message_id":"` + uuid.Must(uuid.NewV4()).String() + `"}]`
+		sendCallback(t, "email", signed(t, time.Now(), body), http.StatusNoContent)
+		sendCallback(t, "email", jsonCallback([]any{}), http.StatusUnauthorized)
+		sendCallback(t, "email", signed(t, time.Now().Add(-time.Hour), body), http.StatusUnauthorized)
+
+		cb := signed(t, time.Now(), body)
+		cb.body = `[{"event":"bounce"}]`
+		sendCallback(t, "email", cb, http.StatusUnauthorized)
+	})
+
+	t.Run("case=verifies Mailgun signatures", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "mailgun")
+		conf.MustSet(ctx, config.ViperKeyCourierAPIConfig, merge(apiConfig, map[string]any{
+			"callback_signing_key": "mailgun-signing-key",
+		}))
+
+		signed := func(timestamp time.Time, key string) callback {
+			ts := strconv.FormatInt(timestamp.Unix(), 10)
+			mac := hmac.New(sha256.New, []byte(key))
+			_, _ = mac.Write([]byte(ts + "random-token"))
+			return jsonCallback(map[string]any{
+				"signature": map[string]any{
+					"timestamp": ts,
+					"token":     "random-token",
+					"signature": hex.EncodeToString(mac.Sum(nil)),
+				},
+				"event-data": map[string]any{"event": "accepted"},
+			})
+		}
+
+		sendCallback(t, "email", signed(time.Now(), "mailgun-signing-key"), http.StatusNoContent)
+		sendCallback(t, "email", jsonCallback(map[string]any{"event-data": map[string]any{}}), http.StatusUnauthorized)
+		sendCallback(t, "email", signed(time.Now(), "wrong-key"), http.StatusUnauthorized)
+		sendCallback(t, "email", signed(time.Now().Add(-time.Hour), "mailgun-signing-key"), http.StatusUnauthorized)
+	})
+}
+
+func merge(a, b map[string]any) map[string]any {
+	result := maps.Clone(a)
+	maps.Copy(result, b)
+	return result
+}
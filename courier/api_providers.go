@@ -0,0 +1,583 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package courier
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // Twilio signs callbacks with HMAC-SHA1.
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/urlx"
+)
+
+type (
+	sendgridProvider struct{}
+	mailgunProvider  struct{}
+	sesProvider      struct{}
+	twilioProvider   struct{}
+)
+
+func (c *apiChannel) fromAddress() string {
+	if c.conf.FromName == "" {
+		return c.conf.FromAddress
+	}
+	return (&mail.Address{Name: c.conf.FromName, Address: c.conf.FromAddress}).String()
+}
+
+func newJSONRequest(ctx context.Context, method, url string, body any) (*retryablehttp.Request, []byte, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	req, err := retryablehttp.NewRequestWithContext(ctx, method, url, raw)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, raw, nil
+}
+
+func newFormRequest(ctx context.Context, url string, form url.Values) (*retryablehttp.Request, error) {
+	req, err := retryablehttp.NewRequestWithContext(ctx, "POST", url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+func decodeCallback(raw []byte, v any) error {
+	if err := json.Unmarshal(raw, v); err != nil {
+		return errors.WithStack(herodot.ErrBadRequest.WithWrap(err).WithReasonf("Unable to decode the delivery status callback: %s", err))
+	}
+	return nil
+}
+
+func readCallback(r *http.Request) ([]byte, error) {
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithWrap(err).WithReasonf("Unable to read the delivery status callback: %s", err))
+	}
+	return raw, nil
+}
+
+// checkCallbackTimestamp rejects signed callbacks which are too old, so that
+// captured callbacks can not be replayed later on.
+func checkCallbackTimestamp(timestamp string) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.WithStack(herodot.ErrUnauthorized.WithReason("The callback signature timestamp is invalid."))
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > maxCallbackAge || age < -maxCallbackAge {
+		return errors.WithStack(herodot.ErrUnauthorized.WithReason("The callback signature has expired."))
+	}
+	return nil
+}
+
+// parseMessageID ignores malformed IDs, as providers also report the status of
+// messages which were not sent by Ory Kratos.
+func parseMessageID(id string) (uuid.UUID, bool) {
+	msgID, err := uuid.FromString(id)
+	return msgID, err == nil
+}
+
+// SendGrid, see https://www.twilio.com/docs/sendgrid/api-reference/mail-send/mail-send
+// and https://www.twilio.com/docs/sendgrid/for-developers/tracking-events/event
+
+func (*sendgridProvider) messageType() MessageType { return MessageTypeEmail }
+
+func (*sendgridProvider) newRequest(ctx context.Context, c *apiChannel, msg Message) (*retryablehttp.Request, error) {
+	content := []map[string]string{{"type": "text/plain", "value": msg.Body}}
+	if html := c.htmlBody(ctx, msg); html != "" {
+		content = append(content, map[string]string{"type": "text/html", "value": html})
+	}
+
+	endpoint, err := c.endpoint("https://api.sendgrid.com", "v3", "mail", "send")
+	if err != nil {
+		return nil, err
+	}
+
+	from := map[string]string{"email": c.conf.FromAddress}
+	if c.conf.FromName != "" {
+		from["name"] = c.conf.FromName
+	}
+
+	req, _, err := newJSONRequest(ctx, "POST", endpoint, map[string]any{
+		"personalizations": []map[string]any{{
+			"to":          []map[string]string{{"email": msg.Recipient}},
+			"custom_args": map[string]string{apiMessageIDKey: msg.ID.String()},
+		}},
+		"from":    from,
+		"subject": msg.Subject,
+		"content": content,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.conf.Key)
+	return req, nil
+}
+
+func (*sendgridProvider) parseCallback(_ context.Context, c *apiChannel, r *http.Request) ([]DeliveryEvent, error) {
+	raw, err := readCallback(r)
+	if err != nil {
+		return nil, err
+	}
+	if c.conf.CallbackSigningKey != "" {
+		if err := verifySendgridSignature(c.conf.CallbackSigningKey, r.Header, raw); err != nil {
+			return nil, err
+		}
+	}
+
+	var payload []struct {
+		Event     string `json:"event"`
+		Type      string `json:"type"`
+		Reason    string `json:"reason"`
+		MessageID string `json:"kratos_message_id"`
+	}
+	if err := decodeCallback(raw, &payload); err != nil {
+		return nil, err
+	}
+
+	events := make([]DeliveryEvent, 0, len(payload))
+	for _, e := range payload {
+		id, ok := parseMessageID(e.MessageID)
+		if !ok {
+			continue
+		}
+		switch e.Event {
+		case "delivered":
+			events = append(events, DeliveryEvent{MessageID: id, Type: DeliveryEventDelivered})
+		case "bounce":
+			// Blocks are temporary and retried by SendGrid.
+			if e.Type == "blocked" {
+				continue
+			}
+			events = append(events, DeliveryEvent{MessageID: id, Type: DeliveryEventBounced, Reason: e.Reason})
+		case "dropped":
+			events = append(events, DeliveryEvent{MessageID: id, Type: DeliveryEventBounced, Reason: e.Reason})
+		case "spamreport":
+			events = append(events, DeliveryEvent{MessageID: id, Type: DeliveryEventComplained})
+		}
+	}
+	return events, nil
+}
+
+// verifySendgridSignature verifies the signature of the signed event webhook,
+// see https://www.twilio.com/docs/sendgrid/for-developers/tracking-events/getting-started-event-webhook-security-features
+func verifySendgridSignature(verificationKey string, header http.Header, body []byte) error {
+	der, err := base64.StdEncoding.DecodeString(verificationKey)
+	if err != nil {
+		return errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("The SendGrid verification key is not base64 encoded: %s", err))
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("The SendGrid verification key is invalid: %s", err))
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.WithStack(herodot.ErrMisconfiguration.WithReason("The SendGrid verification key is not an ECDSA key."))
+	}
+
+	timestamp := header.Get("X-Twilio-Email-Event-Webhook-Timestamp")
+	if err := checkCallbackTimestamp(timestamp); err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(header.Get("X-Twilio-Email-Event-Webhook-Signature"))
+	if err != nil {
+		return errors.WithStack(herodot.ErrUnauthorized.WithReason("The callback signature is invalid."))
+	}
+
+	digest := sha256.Sum256(append([]byte(timestamp), body...))
+	if !ecdsa.VerifyASN1(ecdsaKey, digest[:], signature) {
+		return errors.WithStack(herodot.ErrUnauthorized.WithReason("The callback signature is invalid."))
+	}
+	return nil
+}
+
+// Mailgun, see https://documentation.mailgun.com/docs/mailgun/api-reference/openapi-final/tag/Messages/
+// and https://documentation.mailgun.com/docs/mailgun/user-manual/tracking-messages/#webhooks
+
+func (*mailgunProvider) messageType() MessageType { return MessageTypeEmail }
+
+func (*mailgunProvider) newRequest(ctx context.Context, c *apiChannel, msg Message) (*retryablehttp.Request, error) {
+	form := url.Values{
+		"from":                 {c.fromAddress()},
+		"to":                   {msg.Recipient},
+		"subject":              {msg.Subject},
+		"text":                 {msg.Body},
+		"v:" + apiMessageIDKey: {msg.ID.String()},
+	}
+	if html := c.htmlBody(ctx, msg); html != "" {
+		form.Set("html", html)
+	}
+
+	endpoint, err := c.endpoint("https://api.mailgun.net", "v3", c.conf.Domain, "messages")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := newFormRequest(ctx, endpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth("api", c.conf.Key)
+	return req, nil
+}
+
+func (*mailgunProvider) parseCallback(_ context.Context, c *apiChannel, r *http.Request) ([]DeliveryEvent, error) {
+	raw, err := readCallback(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload struct {
+		Signature struct {
+			Timestamp string `json:"timestamp"`
+			Token     string `json:"token"`
+			Signature string `json:"signature"`
+		} `json:"signature"`
+		EventData struct {
+			Event          string            `json:"event"`
+			Severity       string            `json:"severity"`
+			UserVariables  map[string]string `json:"user-variables"`
+			DeliveryStatus struct {
+				Message     string `json:"message"`
+				Description string `json:"description"`
+			} `json:"delivery-status"`
+		} `json:"event-data"`
+	}
+	if err := decodeCallback(raw, &payload); err != nil {
+		return nil, err
+	}
+
+	// See https://documentation.mailgun.com/docs/mailgun/user-manual/tracking-messages/#securing-webhooks
+	if c.conf.CallbackSigningKey != "" {
+		sig := payload.Signature
+		if err := checkCallbackTimestamp(sig.Timestamp); err != nil {
+			return nil, err
+		}
+		expected := hex.EncodeToString(hmacSHA256([]byte(c.conf.CallbackSigningKey), sig.Timestamp+sig.Token))
+		if !hmac.Equal([]byte(expected), []byte(sig.Signature)) {
+			return nil, errors.WithStack(herodot.ErrUnauthorized.WithReason("The callback signature is invalid."))
+		}
+	}
+
+	e := payload.EventData
+	id, ok := parseMessageID(e.UserVariables[apiMessageIDKey])
+	if !ok {
+		return nil, nil
+	}
+	switch e.Event {
+	case "delivered":
+		return []DeliveryEvent{{MessageID: id, Type: DeliveryEventDelivered}}, nil
+	case "failed":
+		// Temporary failures are retried by Mailgun.
+		if e.Severity != "permanent" {
+			return nil, nil
+		}
+		reason := e.DeliveryStatus.Message
+		if reason == "" {
+			reason = e.DeliveryStatus.Description
+		}
+		return []DeliveryEvent{{MessageID: id, Type: DeliveryEventBounced, Reason: reason}}, nil
+	case "complained":
+		return []DeliveryEvent{{MessageID: id, Type: DeliveryEventComplained}}, nil
+	}
+	return nil, nil
+}
+
+// Amazon SES, see https://docs.aws.amazon.com/ses/latest/APIReference-V2/API_SendEmail.html
+// and https://docs.aws.amazon.com/ses/latest/dg/event-publishing-retrieving-sns-contents.html
+
+func (*sesProvider) messageType() MessageType { return MessageTypeEmail }
+
+func (*sesProvider) newRequest(ctx context.Context, c *apiChannel, msg Message) (*retryablehttp.Request, error) {
+	body := map[string]any{"Text": map[string]string{"Data": msg.Body, "Charset": "UTF-8"}}
+	if html := c.htmlBody(ctx, msg); html != "" {
+		body["Html"] = map[string]string{"Data": html, "Charset": "UTF-8"}
+	}
+
+	endpoint, err := c.endpoint("https://email."+c.conf.Region+".amazonaws.com", "v2", "email", "outbound-emails")
+	if err != nil {
+		return nil, err
+	}
+
+	req, raw, err := newJSONRequest(ctx, "POST", endpoint, map[string]any{
+		"FromEmailAddress": c.fromAddress(),
+		"Destination":      map[string]any{"ToAddresses": []string{msg.Recipient}},
+		"Content": map[string]any{
+			"Simple": map[string]any{
+				"Subject": map[string]string{"Data": msg.Subject, "Charset": "UTF-8"},
+				"Body":    body,
+			},
+		},
+		"EmailTags": []map[string]string{{"Name": apiMessageIDKey, "Value": msg.ID.String()}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signAWSv4(req.Request, raw, c.conf.Key, c.conf.Secret, c.conf.Region, "ses", time.Now())
+	return req, nil
+}
+
+func (*sesProvider) parseCallback(ctx context.Context, c *apiChannel, r *http.Request) ([]DeliveryEvent, error) {
+	raw, err := readCallback(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// SES publishes events through Amazon SNS, which signs every message.
+	var notification snsNotification
+	if err := decodeCallback(raw, &notification); err != nil {
+		return nil, err
+	}
+	if err := notification.verify(ctx, c); err != nil {
+		return nil, err
+	}
+
+	switch notification.Type {
+	case "SubscriptionConfirmation":
+		return nil, c.confirmSNSSubscription(ctx, notification.SubscribeURL)
+	case "Notification":
+	default:
+		return nil, nil
+	}
+
+	var event struct {
+		EventType        string `json:"eventType"`
+		NotificationType string `json:"notificationType"`
+		Bounce           struct {
+			BounceType    string `json:"bounceType"`
+			BounceSubType string `json:"bounceSubType"`
+		} `json:"bounce"`
+		Mail struct {
+			Tags map[string][]string `json:"tags"`
+		} `json:"mail"`
+	}
+	if err := json.Unmarshal([]byte(notification.Message), &event); err != nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithWrap(err).WithReasonf("Unable to decode the delivery status callback: %s", err))
+	}
+
+	tags := event.Mail.Tags[apiMessageIDKey]
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	id, ok := parseMessageID(tags[0])
+	if !ok {
+		return nil, nil
+	}
+
+	// Event publishing and identity notifications name the type differently.
+	switch cmp.Or(event.EventType, event.NotificationType) {
+	case "Delivery":
+		return []DeliveryEvent{{MessageID: id, Type: DeliveryEventDelivered}}, nil
+	case "Bounce":
+		// Transient bounces are retried by SES.
+		if event.Bounce.BounceType != "Permanent" {
+			return nil, nil
+		}
+		return []DeliveryEvent{{MessageID: id, Type: DeliveryEventBounced, Reason: event.Bounce.BounceSubType}}, nil
+	case "Complaint":
+		return []DeliveryEvent{{MessageID: id, Type: DeliveryEventComplained}}, nil
+	}
+	return nil, nil
+}
+
+// confirmSNSSubscription confirms the subscription of the callback endpoint to
+// the SNS topic SES publishes to.
+func (c *apiChannel) confirmSNSSubscription(ctx context.Context, subscribeURL string) error {
+	u, err := url.Parse(subscribeURL)
+	if err != nil || u.Scheme != "https" || !strings.HasSuffix(u.Hostname(), ".amazonaws.com") {
+		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("The SNS subscription URL %q is not an Amazon SNS endpoint.", subscribeURL))
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	res, err := c.d.HTTPClient(ctx).Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.WithStack(herodot.ErrUpstreamError.WithReasonf("Unable to confirm the SNS subscription, received status code %d.", res.StatusCode))
+	}
+	return nil
+}
+
+// signAWSv4 signs the request with AWS Signature Version 4, see
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_sigv-create-signed-request.html
+func signAWSv4(req *http.Request, body []byte, key, secret, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders bytes.Buffer
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+secret), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+key+"/"+scope+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+hex.EncodeToString(hmacSHA256(signingKey, stringToSign)))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// Twilio, see https://www.twilio.com/docs/messaging/api/message-resource#create-a-message-resource
+// and https://www.twilio.com/docs/messaging/guides/track-outbound-message-status
+
+func (*twilioProvider) messageType() MessageType { return MessageTypeSMS }
+
+func (*twilioProvider) newRequest(ctx context.Context, c *apiChannel, msg Message) (*retryablehttp.Request, error) {
+	form := url.Values{
+		"To":   {msg.Recipient},
+		"From": {c.conf.FromAddress},
+		"Body": {msg.Body},
+	}
+
+	// Twilio reports the status to a callback URL given with every message.
+	// The callback token is sent as the password of HTTP basic authentication.
+	if c.conf.CallbackToken != "" {
+		callback := urlx.CopyWithQuery(c.callbackURL(ctx), url.Values{apiMessageIDKey: {msg.ID.String()}})
+		callback.User = url.UserPassword(callbackUsername, c.conf.CallbackToken)
+		form.Set("StatusCallback", callback.String())
+	}
+
+	endpoint, err := c.endpoint("https://api.twilio.com", "2010-04-01", "Accounts", c.conf.Key, "Messages.json")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := newFormRequest(ctx, endpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.conf.Key, c.conf.Secret)
+	return req, nil
+}
+
+func (*twilioProvider) parseCallback(ctx context.Context, c *apiChannel, r *http.Request) ([]DeliveryEvent, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithWrap(err).WithReasonf("Unable to decode the delivery status callback: %s", err))
+	}
+
+	// Twilio signs the callback URL and parameters with the auth token, see
+	// https://www.twilio.com/docs/usage/webhooks/webhooks-security
+	signed := urlx.CopyWithQuery(c.callbackURL(ctx), r.URL.Query()).String()
+	keys := make([]string, 0, len(r.PostForm))
+	for k := range r.PostForm {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		values := slices.Clone(r.PostForm[k])
+		sort.Strings(values)
+		for _, v := range values {
+			signed += k + v
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(c.conf.Secret))
+	_, _ = mac.Write([]byte(signed))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Twilio-Signature"))) {
+		return nil, errors.WithStack(herodot.ErrUnauthorized.WithReason("The callback signature is invalid."))
+	}
+
+	id, ok := parseMessageID(r.URL.Query().Get(apiMessageIDKey))
+	if !ok {
+		return nil, nil
+	}
+	switch r.PostForm.Get("MessageStatus") {
+	case "delivered":
+		return []DeliveryEvent{{MessageID: id, Type: DeliveryEventDelivered}}, nil
+	case "undelivered", "failed":
+		code := r.PostForm.Get("ErrorCode")
+		// Most errors, such as an unreachable handset, are temporary, so
+		// only permanent errors suppress the phone number.
+		if _, ok := twilioPermanentErrorCodes[code]; ok {
+			return []DeliveryEvent{{MessageID: id, Type: DeliveryEventBounced, Reason: "Twilio error code " + code}}, nil
+		}
+		return []DeliveryEvent{{MessageID: id, Type: DeliveryEventFailed, Reason: "Twilio error code " + code}}, nil
+	}
+	return nil, nil
+}
+
+// twilioPermanentErrorCodes are the error codes which mean that messages to
+// the phone number will never be delivered, see
+// https://www.twilio.com/docs/api/errors
+var twilioPermanentErrorCodes = map[string]struct{}{
+	"21211": {}, // Invalid 'To' phone number
+	"21610": {}, // Attempt to send to unsubscribed recipient
+	"21614": {}, // 'To' number is not a valid mobile number
+	"30005": {}, // Unknown destination handset
+	"30006": {}, // Landline or unreachable carrier
+}
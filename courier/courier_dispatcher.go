@@ -11,7 +11,9 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/x/events"
+	"github.com/ory/x/logrusx"
 	"github.com/ory/x/otelx"
 )
 
@@ -34,6 +36,8 @@ func (c *courier) channels(ctx context.Context, id string) (Channel, error) {
 			return courierChannel, nil
 		case "http":
 			return newHttpChannel(channel.ID, &channel.RequestConfig, c.deps), nil
+		case "ses", "sendgrid", "mailgun", "twilio":
+			return newAPIChannel(channel, c.deps)
 		default:
 			return nil, errors.Errorf("unknown courier channel type: %s", channel.Type)
 		}
@@ -42,6 +46,8 @@ func (c *courier) channels(ctx context.Context, id string) (Channel, error) {
 	return nil, errors.Errorf("no courier channels configured")
 }
 
+// DispatchMessage sends out the message using its channel. Every attempt is
+// recorded as a dispatch of the message.
 func (c *courier) DispatchMessage(ctx context.Context, msg Message) (err error) {
 	ctx, span := c.deps.Tracer(ctx).Tracer().Start(ctx, "courier.DispatchMessage", trace.WithAttributes(
 		attribute.Stringer("message.id", msg.ID),
@@ -67,6 +73,33 @@ func (c *courier) DispatchMessage(ctx context.Context, msg Message) (err error)
 		return err
 	}
 
+	defer func() {
+		status := CourierMessageDispatchStatusSuccess
+		if err != nil {
+			status = CourierMessageDispatchStatusFailed
+		}
+		if err := c.deps.CourierPersister().RecordDispatch(ctx, msg.ID, status, err); err != nil {
+			// The outcome of the attempt is returned regardless, as the
+			// dispatch log is informational only.
+			logger.
+				WithError(err).
+				Errorf(`Unable to record %s log entry.`, status)
+		}
+	}()
+
+	if suppressed, err := c.deps.CourierPersister().IsSuppressed(ctx, msg.Recipient); err != nil {
+		return err
+	} else if suppressed {
+		// Sending the message again would not change the outcome.
+		if err := c.deps.CourierPersister().SetMessageStatus(ctx, msg.ID, MessageStatusAbandoned); err != nil {
+			logger.
+				WithError(err).
+				Error(`Unable to set the message status to "abandoned".`)
+			return err
+		}
+		return errors.WithStack(ErrRecipientSuppressed)
+	}
+
 	channel, err := c.channels(ctx, msg.Channel.String())
 	if err != nil {
 		return err
@@ -94,10 +127,12 @@ func (c *courier) DispatchMessage(ctx context.Context, msg Message) (err error)
 	return nil
 }
 
+// DispatchQueue sends out the next queued messages. Messages which fail to
+// deliver are retried according to the retry policy of their channel, and
+// abandoned once they run out of attempts.
 func (c *courier) DispatchQueue(ctx context.Context) (err error) {
 	ctx, span := c.deps.Tracer(ctx).Tracer().Start(ctx, "courier.DispatchQueue")
 	defer otelx.End(span, &err)
-	maxRetries := c.deps.CourierConfig().CourierMessageRetries(ctx)
 	pullCount := c.deps.CourierConfig().CourierWorkerPullCount(ctx)
 
 	//nolint:gosec // disable G115
@@ -118,53 +153,83 @@ func (c *courier) DispatchQueue(ctx context.Context) (err error) {
 			WithField("message_template_type", msg.TemplateType).
 			WithField("message_subject", msg.Subject)
 
-		if msg.SendCount > maxRetries {
-			if err := c.deps.CourierPersister().SetMessageStatus(ctx, msg.ID, MessageStatusAbandoned); err != nil {
-				logger.
-					WithError(err).
-					Error(`Unable to set the retried message's status to "abandoned".`)
+		retry, err := c.deps.CourierConfig().CourierRetry(ctx, msg.Channel.String())
+		if err != nil {
+			return err
+		}
+
+		if msg.SendCount >= retry.MaxAttempts {
+			// The message may have run out of attempts before the retry
+			// policy was changed.
+			if err := c.abandon(ctx, logger, msg); err != nil {
 				return err
 			}
+			continue
+		}
 
-			span.AddEvent(events.NewCourierMessageAbandoned(ctx, msg.ID, msg.Channel.String(), string(msg.TemplateType)))
+		dispatchErr := c.DispatchMessage(ctx, msg)
+		if dispatchErr == nil {
+			continue
+		}
 
-			// Skip the message
-			logger.
-				Warnf(`Message was abandoned because it did not deliver after %d attempts`, msg.SendCount)
-		} else if err := c.DispatchMessage(ctx, msg); err != nil {
+		logger.
+			WithError(dispatchErr).
+			Warn(`Unable to dispatch message.`)
+
+		if attempts := msg.SendCount + 1; attempts >= retry.MaxAttempts {
+			msg.SendCount = attempts
+			if err := c.abandon(ctx, logger, msg); err != nil {
+				return err
+			}
+		} else if err := c.deps.CourierPersister().ScheduleMessageRetry(ctx, msg.ID, time.Now().Add(retryBackoff(retry, attempts))); err != nil {
 			logger.
 				WithError(err).
-				Warn(`Unable to dispatch message.`)
-			if err := c.deps.CourierPersister().RecordDispatch(ctx, msg.ID, CourierMessageDispatchStatusFailed, err); err != nil {
-				logger.
-					WithError(err).
-					Error(`Unable to record failure log entry.`)
-				if c.failOnDispatchError {
-					return err
-				}
+				Error(`Unable to reset the failed message's status to "queued".`)
+			if c.failOnDispatchError {
+				return err
 			}
+		}
 
-			for _, replace := range messages[k:] {
+		if c.failOnDispatchError {
+			for _, replace := range messages[k+1:] {
 				if err := c.deps.CourierPersister().SetMessageStatus(ctx, replace.ID, MessageStatusQueued); err != nil {
 					logger.
 						WithError(err).
-						Error(`Unable to reset the failed message's status to "queued".`)
-					if c.failOnDispatchError {
-						return err
-					}
+						Error(`Unable to reset the pending message's status to "queued".`)
+					return err
 				}
 			}
-
-			if c.failOnDispatchError {
-				return err
-			}
-		} else if err := c.deps.CourierPersister().RecordDispatch(ctx, msg.ID, CourierMessageDispatchStatusSuccess, nil); err != nil {
-			logger.
-				WithError(err).
-				Error(`Unable to record success log entry.`)
-			// continue with execution, as the message was successfully dispatched
+			return dispatchErr
 		}
 	}
 
 	return nil
 }
+
+func (c *courier) abandon(ctx context.Context, logger *logrusx.Logger, msg Message) error {
+	if err := c.deps.CourierPersister().SetMessageStatus(ctx, msg.ID, MessageStatusAbandoned); err != nil {
+		logger.
+			WithError(err).
+			Error(`Unable to set the retried message's status to "abandoned".`)
+		return err
+	}
+
+	trace.SpanFromContext(ctx).AddEvent(events.NewCourierMessageAbandoned(ctx, msg.ID, msg.Channel.String(), string(msg.TemplateType)))
+
+	logger.
+		Warnf(`Message was abandoned because it did not deliver after %d attempts`, msg.SendCount)
+	return nil
+}
+
+// retryBackoff returns the wait before the next attempt after the given number of
+// failed attempts.
+func retryBackoff(c *config.CourierRetry, attempts int) time.Duration {
+	wait := c.InitialInterval
+	for range attempts - 1 {
+		wait *= 2
+		if wait >= c.MaxInterval {
+			return c.MaxInterval
+		}
+	}
+	return min(wait, c.MaxInterval)
+}
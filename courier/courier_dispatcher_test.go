@@ -6,8 +6,10 @@ package courier_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
 
@@ -83,6 +85,11 @@ func TestDispatchMessage(t *testing.T) {
 		}
 		require.NoError(t, reg.CourierPersister().AddMessage(ctx, &message))
 		require.Error(t, c.DispatchMessage(ctx, message))
+
+		actual, err := reg.CourierPersister().FetchMessage(ctx, message.ID)
+		require.NoError(t, err)
+		require.Len(t, actual.Dispatches, 1, "every attempt is recorded")
+		assert.Equal(t, courier.CourierMessageDispatchStatusFailed, actual.Dispatches[0].Status)
 	})
 }
 
@@ -127,3 +134,46 @@ func TestDispatchQueue(t *testing.T) {
 	require.Contains(t, gjson.GetBytes(message.Dispatches[0].Error, "reason").String(), "failed to send email via smtp")
 	require.Contains(t, gjson.GetBytes(message.Dispatches[1].Error, "reason").String(), "failed to send email via smtp")
 }
+
+func TestDispatchQueueWithBackoff(t *testing.T) {
+	ctx := context.Background()
+
+	conf, reg := internal.NewRegistryDefaultWithDSN(t, "")
+	conf.MustSet(ctx, config.ViperKeyCourierRetry+".max_attempts", 2)
+	conf.MustSet(ctx, config.ViperKeyCourierRetry+".initial_interval", "1h")
+
+	c, err := reg.Courier(ctx)
+	require.NoError(t, err)
+	c.FailOnDispatchError()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	id := queueNewMessage(t, ctx, c, reg)
+
+	// Fails to deliver the first time
+	require.Error(t, c.DispatchQueue(ctx))
+
+	message, err := reg.CourierPersister().FetchMessage(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, courier.MessageStatusQueued, message.Status)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), time.Time(message.NextAttemptAt), time.Minute)
+
+	// The retry is not due yet
+	require.NoError(t, c.DispatchQueue(ctx))
+
+	message, err = reg.CourierPersister().FetchMessage(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 1, message.SendCount)
+	require.Len(t, message.Dispatches, 1)
+
+	// The last attempt abandons the message
+	require.NoError(t, reg.CourierPersister().SetMessageStatus(ctx, id, courier.MessageStatusProcessing))
+	require.NoError(t, reg.CourierPersister().ScheduleMessageRetry(ctx, id, time.Now().Add(-time.Second)))
+	require.Error(t, c.DispatchQueue(ctx))
+
+	message, err = reg.CourierPersister().FetchMessage(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, courier.MessageStatusAbandoned, message.Status)
+	assert.Len(t, message.Dispatches, 2)
+}
@@ -0,0 +1,20 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package courier
+
+import (
+	"crypto/x509"
+	"testing"
+)
+
+// SetSNSCertificateForTest caches the SNS signing certificate, so that tests
+// do not need to fetch it from Amazon SNS.
+func SetSNSCertificateForTest(t *testing.T, certURL string, cert *x509.Certificate) {
+	snsCertificates.set(certURL, cert)
+	t.Cleanup(func() {
+		snsCertificates.mu.Lock()
+		defer snsCertificates.mu.Unlock()
+		delete(snsCertificates.certs, certURL)
+	})
+}
@@ -24,6 +24,10 @@ const (
 	AdminRouteCourier      = "/courier"
 	AdminRouteListMessages = AdminRouteCourier + "/messages"
 	AdminRouteGetMessage   = AdminRouteCourier + "/messages/{msgID}"
+
+	AdminRouteCancelMessage  = AdminRouteGetMessage + "/cancel"
+	AdminRouteRequeueMessage = AdminRouteGetMessage + "/requeue"
+	AdminRouteResendMessage  = AdminRouteGetMessage + "/resend"
 )
 
 type (
@@ -33,6 +37,7 @@ type (
 		nosurfx.CSRFProvider
 		PersistenceProvider
 		config.Provider
+		channelDependencies
 	}
 	Handler struct {
 		r handlerDependencies
@@ -47,14 +52,33 @@ func NewHandler(r handlerDependencies) *Handler {
 }
 
 func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
-	h.r.CSRFHandler().IgnoreGlobs(x.AdminPrefix+AdminRouteListMessages, AdminRouteListMessages)
+	h.r.CSRFHandler().IgnoreGlobs(
+		x.AdminPrefix+AdminRouteListMessages, AdminRouteListMessages,
+		x.AdminPrefix+AdminRouteListMessages+"/*/*", AdminRouteListMessages+"/*/*",
+		x.AdminPrefix+AdminRouteListSuppressions, AdminRouteListSuppressions,
+		x.AdminPrefix+AdminRouteListSuppressions+"/*", AdminRouteListSuppressions+"/*",
+		"/courier/callbacks/*",
+	)
+	public.POST(RouteCourierCallback, h.receiveCourierCallback)
 	public.GET(x.AdminPrefix+AdminRouteListMessages, redir.RedirectToAdminRoute(h.r))
 	public.GET(x.AdminPrefix+AdminRouteGetMessage, redir.RedirectToAdminRoute(h.r))
+	public.POST(x.AdminPrefix+AdminRouteCancelMessage, redir.RedirectToAdminRoute(h.r))
+	public.POST(x.AdminPrefix+AdminRouteRequeueMessage, redir.RedirectToAdminRoute(h.r))
+	public.POST(x.AdminPrefix+AdminRouteResendMessage, redir.RedirectToAdminRoute(h.r))
+	public.GET(x.AdminPrefix+AdminRouteListSuppressions, redir.RedirectToAdminRoute(h.r))
+	public.POST(x.AdminPrefix+AdminRouteListSuppressions, redir.RedirectToAdminRoute(h.r))
+	public.DELETE(x.AdminPrefix+AdminRouteSuppression, redir.RedirectToAdminRoute(h.r))
 }
 
 func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
 	admin.GET(AdminRouteListMessages, h.listCourierMessages)
 	admin.GET(AdminRouteGetMessage, h.getCourierMessage)
+	admin.POST(AdminRouteCancelMessage, h.cancelCourierMessage)
+	admin.POST(AdminRouteRequeueMessage, h.requeueCourierMessage)
+	admin.POST(AdminRouteResendMessage, h.resendCourierMessage)
+	admin.GET(AdminRouteListSuppressions, h.listCourierSuppressions)
+	admin.POST(AdminRouteListSuppressions, h.createCourierSuppression)
+	admin.DELETE(AdminRouteSuppression, h.deleteCourierSuppression)
 }
 
 // Paginated Courier Message List Response
@@ -210,3 +234,123 @@ func (h *Handler) getCourierMessage(w http.ResponseWriter, r *http.Request) {
 
 	h.r.Writer().Write(w, r, message)
 }
+
+// Change Courier Message Parameters
+//
+// swagger:parameters cancelCourierMessage requeueCourierMessage resendCourierMessage
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type changeCourierMessage struct {
+	// MessageID is the ID of the message.
+	//
+	// required: true
+	// in: path
+	MessageID string `json:"id"`
+}
+
+// swagger:route POST /admin/courier/messages/{id}/cancel courier cancelCourierMessage
+//
+// # Cancel a Message
+//
+// Cancels a queued message, so that it is not sent out. Messages which are
+// being processed or were already sent can not be cancelled.
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//		oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//		200: message
+//		400: errorGeneric
+//		404: errorGeneric
+//		409: errorGeneric
+//		default: errorGeneric
+func (h *Handler) cancelCourierMessage(w http.ResponseWriter, r *http.Request) {
+	h.changeMessage(w, r, "cancelled", func(id uuid.UUID) (*Message, error) {
+		return h.r.CourierPersister().CancelMessage(r.Context(), id)
+	})
+}
+
+// swagger:route POST /admin/courier/messages/{id}/requeue courier requeueCourierMessage
+//
+// # Requeue a Message
+//
+// Queues an abandoned or cancelled message again. The message is retried with
+// all attempts of its channel's retry policy.
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//		oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//		200: message
+//		400: errorGeneric
+//		404: errorGeneric
+//		409: errorGeneric
+//		default: errorGeneric
+func (h *Handler) requeueCourierMessage(w http.ResponseWriter, r *http.Request) {
+	h.changeMessage(w, r, "requeued", func(id uuid.UUID) (*Message, error) {
+		return h.r.CourierPersister().RequeueMessage(r.Context(), id, MessageStatusAbandoned, MessageStatusCancelled)
+	})
+}
+
+// swagger:route POST /admin/courier/messages/{id}/resend courier resendCourierMessage
+//
+// # Resend a Message
+//
+// Queues a message which was already sent out again, for example if the
+// recipient did not receive it. Previous dispatches of the message are kept.
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//		oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//		200: message
+//		400: errorGeneric
+//		404: errorGeneric
+//		409: errorGeneric
+//		default: errorGeneric
+func (h *Handler) resendCourierMessage(w http.ResponseWriter, r *http.Request) {
+	h.changeMessage(w, r, "resent", func(id uuid.UUID) (*Message, error) {
+		return h.r.CourierPersister().RequeueMessage(r.Context(), id, MessageStatusSent, MessageStatusDelivered, MessageStatusBounced)
+	})
+}
+
+func (h *Handler) changeMessage(w http.ResponseWriter, r *http.Request, action string, change func(id uuid.UUID) (*Message, error)) {
+	msgID, err := uuid.FromString(r.PathValue("msgID"))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, herodot.ErrBadRequest.WithError(err.Error()).WithDebugf("could not parse parameter {id} as UUID, got %s", r.PathValue("msgID")))
+		return
+	}
+
+	message, err := change(msgID)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.Logger().
+		WithField("message_id", message.ID).
+		WithField("message_status", message.Status).
+		Infof("An administrator %s a courier message.", action)
+
+	if !h.r.Config().IsInsecureDevMode(r.Context()) {
+		message.Body = "<redacted-unless-dev-mode>"
+	}
+
+	h.r.Writer().Write(w, r, message)
+}
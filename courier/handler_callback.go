@@ -0,0 +1,199 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package courier
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/urlx"
+)
+
+const RouteCourierCallback = "/courier/callbacks/{channel}"
+
+// maxCallbackSize limits the size of delivery status callbacks. Providers
+// batch at most a few hundred events per callback.
+const maxCallbackSize = 1 << 20
+
+// maxCallbackAge is how old the timestamp of a signed callback may be.
+const maxCallbackAge = 15 * time.Minute
+
+// callbackUsername is the user name of the HTTP basic authentication of
+// callbacks. Only the password, which is the callback token, is checked.
+const callbackUsername = "kratos"
+
+var deliveryFailureReasons = map[DeliveryEventType]string{
+	DeliveryEventBounced:    "The message bounced.",
+	DeliveryEventComplained: "The recipient reported the message as spam.",
+	DeliveryEventFailed:     "The message could not be delivered.",
+}
+
+var deliveryFailureSuppressionReasons = map[DeliveryEventType]SuppressionReason{
+	DeliveryEventBounced:    SuppressionReasonBounced,
+	DeliveryEventComplained: SuppressionReasonComplained,
+}
+
+// Delivery Status Callback Parameters
+//
+// swagger:parameters receiveCourierCallback
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type receiveCourierCallback struct {
+	// The ID of the courier channel.
+	//
+	// required: true
+	// in: path
+	Channel string `json:"channel"`
+
+	// The callback token of the courier channel, either as the password of
+	// HTTP basic authentication or as a bearer token.
+	//
+	// required: true
+	// in: header
+	Authorization string `json:"Authorization"`
+}
+
+// swagger:route POST /courier/callbacks/{channel} courier receiveCourierCallback
+//
+// # Receive a Delivery Status Callback
+//
+// Receives the delivery, bounce and complaint notifications of the email or SMS
+// provider of a courier channel, and updates the status of the messages
+// accordingly. Recipients of bounced messages and recipients who complained
+// are suppressed. Configure this URL as the webhook URL at your provider, with
+// the callback token as the password of HTTP basic authentication, for example
+// `https://kratos:<callback_token>@<public URL>/courier/callbacks/<channel id>`.
+// The signatures of Amazon SNS and Twilio are verified, as well as those of
+// SendGrid and Mailgun if a callback signing key is configured.
+//
+//	Consumes:
+//	- application/json
+//	- application/x-www-form-urlencoded
+//
+//	Schemes: http, https
+//
+//	Responses:
+//		204: emptyResponse
+//		400: errorGeneric
+//		401: errorGeneric
+//		404: errorGeneric
+//		default: errorGeneric
+func (h *Handler) receiveCourierCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	channel, err := h.callbackChannel(r)
+	if err != nil {
+		// Some providers, such as Amazon SNS, only send the credentials
+		// after an authentication challenge.
+		if e := new(herodot.DefaultError); errors.As(err, &e) && e.StatusCode() == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", `Basic realm="courier"`)
+		}
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCallbackSize)
+	events, err := channel.provider.parseCallback(ctx, channel, r)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	for _, event := range events {
+		logger := h.r.Logger().
+			WithField("channel", channel.id).
+			WithField("message_id", event.MessageID).
+			WithField("delivery_event", event.Type)
+
+		if err := h.r.CourierPersister().SetMessageDeliveryStatus(ctx, event.MessageID, event.Status()); errors.Is(err, sqlcon.ErrNoRows) {
+			// The message may have been sent by another network, or the
+			// provider reports events out of order.
+			logger.Debug("Ignoring delivery status of unknown or unsent message.")
+			continue
+		} else if err != nil {
+			h.r.Writer().WriteError(w, r, err)
+			return
+		}
+
+		if reason, ok := deliveryFailureReasons[event.Type]; ok {
+			if err := h.r.CourierPersister().RecordDispatch(ctx, event.MessageID, CourierMessageDispatchStatusFailed,
+				errors.WithStack(herodot.ErrInternalServerError.
+					WithReason(reason).
+					WithDetail("provider_reason", event.Reason))); err != nil {
+				logger.WithError(err).Error("Unable to record failure log entry.")
+			}
+		}
+
+		if reason, ok := deliveryFailureSuppressionReasons[event.Type]; ok {
+			message, err := h.r.CourierPersister().FetchMessage(ctx, event.MessageID)
+			if err != nil {
+				h.r.Writer().WriteError(w, r, err)
+				return
+			}
+			if err := suppressRecipient(ctx, h.r.CourierPersister(), message, reason); err != nil {
+				h.r.Writer().WriteError(w, r, err)
+				return
+			}
+		}
+
+		logger.Info("Received the delivery status of a courier message.")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// callbackChannel returns the channel the callback is addressed to. Only
+// channels with a callback token accept callbacks.
+func (h *Handler) callbackChannel(r *http.Request) (*apiChannel, error) {
+	ccs, err := h.r.CourierConfig().CourierChannels(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	id := r.PathValue("channel")
+	for _, cc := range ccs {
+		if cc.ID != id || cc.APIConfig == nil || cc.APIConfig.CallbackToken == "" {
+			continue
+		}
+
+		channel, err := newAPIChannel(cc, h.r)
+		if err != nil {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(callbackToken(r)), []byte(cc.APIConfig.CallbackToken)) != 1 {
+			return nil, errors.WithStack(herodot.ErrUnauthorized.WithReason("The callback token is invalid."))
+		}
+		return channel, nil
+	}
+
+	return nil, errors.WithStack(herodot.ErrNotFound.WithReasonf("Courier channel %q does not accept delivery status callbacks.", id))
+}
+
+// callbackToken returns the callback token sent with the request. The token
+// is not accepted as a query parameter, as URLs end up in access logs.
+func callbackToken(r *http.Request) string {
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return ""
+}
+
+// callbackURL returns the URL the provider sends the delivery status callbacks
+// of the channel to.
+func (c *apiChannel) callbackURL(ctx context.Context) *url.URL {
+	return urlx.AppendPaths(c.d.CourierConfig().SelfPublicURL(ctx), strings.Replace(RouteCourierCallback, "{channel}", c.id, 1))
+}
@@ -0,0 +1,214 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package courier
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	keysetpagination "github.com/ory/x/pagination/keysetpagination_v2"
+	"github.com/ory/x/uuidx"
+)
+
+const (
+	AdminRouteListSuppressions = AdminRouteCourier + "/suppressions"
+	AdminRouteSuppression      = AdminRouteListSuppressions + "/{suppressionID}"
+)
+
+// Paginated Courier Suppression List Response
+//
+// swagger:response listCourierSuppressions
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type listCourierSuppressionsResponse struct {
+	keysetpagination.ResponseHeaders
+
+	// List of suppressions
+	//
+	// in:body
+	Body []Suppression
+}
+
+// Paginated List Courier Suppression Parameters
+//
+// swagger:parameters listCourierSuppressions
+type ListCourierSuppressionsParameters struct {
+	keysetpagination.RequestParameters
+
+	// Recipient filters out suppressions based on recipient.
+	// If no value is provided, it doesn't take effect on filter.
+	//
+	// required: false
+	// in: query
+	Recipient string `json:"recipient"`
+}
+
+// swagger:route GET /admin/courier/suppressions courier listCourierSuppressions
+//
+// # List Suppressed Recipients
+//
+// Lists the recipients to which the courier does not send messages, because
+// earlier messages could not be delivered to them.
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: listCourierSuppressions
+//	  400: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) listCourierSuppressions(w http.ResponseWriter, r *http.Request) {
+	keys := h.r.Config().SecretsPagination(r.Context())
+	opts, err := keysetpagination.ParseQueryParams(keys, r.URL.Query())
+	if err != nil {
+		h.r.Writer().WriteErrorCode(w, r, http.StatusBadRequest, errors.WithStack(err))
+		return
+	}
+
+	l, nextPage, err := h.r.CourierPersister().ListSuppressions(r.Context(), ListCourierSuppressionsParameters{
+		Recipient: r.URL.Query().Get("recipient"),
+	}, opts)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	u := *r.URL
+	keysetpagination.SetLinkHeader(w, keys, &u, nextPage)
+	h.r.Writer().Write(w, r, l)
+}
+
+// Create Courier Suppression Body
+//
+// swagger:model createCourierSuppressionBody
+type createCourierSuppressionBody struct {
+	// The email address or phone number to suppress.
+	//
+	// required: true
+	Recipient string `json:"recipient"`
+}
+
+// Create Courier Suppression Parameters
+//
+// swagger:parameters createCourierSuppression
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type createCourierSuppression struct {
+	// in: body
+	Body createCourierSuppressionBody
+}
+
+// swagger:route POST /admin/courier/suppressions courier createCourierSuppression
+//
+// # Suppress a Recipient
+//
+// Suppresses a recipient, so that the courier does not send messages to it.
+// Verifiable addresses of the recipient are marked as suppressed. Suppressing a
+// recipient which is already suppressed returns the existing suppression.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  201: courierSuppression
+//	  400: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) createCourierSuppression(w http.ResponseWriter, r *http.Request) {
+	var body createCourierSuppressionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithError(err.Error()).WithReason("The request body is not valid JSON.")))
+		return
+	}
+
+	if NormalizeRecipient(body.Recipient) == "" {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReason("The recipient must not be empty.")))
+		return
+	}
+
+	suppression := Suppression{
+		ID:        uuidx.NewV4(),
+		Recipient: body.Recipient,
+		Reason:    SuppressionReasonManual,
+	}
+	if err := h.r.CourierPersister().AddSuppression(r.Context(), &suppression); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.Logger().
+		WithField("suppression_id", suppression.ID).
+		Info("An administrator suppressed a courier recipient.")
+
+	h.r.Writer().WriteCode(w, r, http.StatusCreated, &suppression)
+}
+
+// Delete Courier Suppression Parameters
+//
+// swagger:parameters deleteCourierSuppression
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type deleteCourierSuppression struct {
+	// ID is the ID of the suppression.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:route DELETE /admin/courier/suppressions/{id} courier deleteCourierSuppression
+//
+// # Remove a Suppressed Recipient
+//
+// Removes a suppression, so that the courier sends messages to the recipient
+// again. Suppressed verifiable addresses of the recipient are marked as
+// completed if they are verified, and as pending otherwise.
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  204: emptyResponse
+//	  400: errorGeneric
+//	  404: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) deleteCourierSuppression(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.FromString(r.PathValue("suppressionID"))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, herodot.ErrBadRequest.WithError(err.Error()).WithDebugf("could not parse parameter {id} as UUID, got %s", r.PathValue("suppressionID")))
+		return
+	}
+
+	if err := h.r.CourierPersister().DeleteSuppression(r.Context(), id); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.Logger().
+		WithField("suppression_id", id).
+		Info("An administrator removed a courier suppression.")
+
+	w.WriteHeader(http.StatusNoContent)
+}
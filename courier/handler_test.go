@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-faker/faker/v4"
@@ -279,4 +280,108 @@ func TestHandler(t *testing.T) {
 			}
 		})
 	})
+	t.Run("handler=changeCourierMessage", func(t *testing.T) {
+		conf.MustSet(ctx, "dev", true)
+
+		post := func(t *testing.T, s *httptest.Server, href string, expectCode int) gjson.Result {
+			t.Helper()
+			res, err := s.Client().Post(s.URL+href, "application/json", nil)
+			require.NoError(t, err)
+			body := ioutilx.MustReadAll(res.Body)
+			require.NoError(t, res.Body.Close())
+			assert.EqualValuesf(t, expectCode, res.StatusCode, "%s", body)
+			return gjson.ParseBytes(body)
+		}
+
+		newMessage := func(t *testing.T) courier.Message {
+			message := courier.Message{}
+			require.NoError(t, faker.FakeData(&message))
+			message.Type = courier.MessageTypeEmail
+			require.NoError(t, reg.CourierPersister().AddMessage(ctx, &message))
+			return message
+		}
+
+		for _, tc := range tss {
+			t.Run("endpoint="+tc.name, func(t *testing.T) {
+				href := func(id uuid.UUID, action string) string {
+					return x.AdminPrefix + "/courier/messages/" + id.String() + "/" + action
+				}
+
+				t.Run("case=cancel and requeue", func(t *testing.T) {
+					message := newMessage(t)
+
+					body := post(t, tc.s, href(message.ID, "cancel"), http.StatusOK)
+					assert.Equal(t, "cancelled", body.Get("status").String(), body.Raw)
+					post(t, tc.s, href(message.ID, "cancel"), http.StatusConflict)
+
+					body = post(t, tc.s, href(message.ID, "requeue"), http.StatusOK)
+					assert.Equal(t, "queued", body.Get("status").String(), body.Raw)
+					post(t, tc.s, href(message.ID, "requeue"), http.StatusConflict)
+				})
+
+				t.Run("case=requeue abandoned", func(t *testing.T) {
+					message := newMessage(t)
+					require.NoError(t, reg.CourierPersister().IncrementMessageSendCount(ctx, message.ID))
+					require.NoError(t, reg.CourierPersister().SetMessageStatus(ctx, message.ID, courier.MessageStatusAbandoned))
+
+					post(t, tc.s, href(message.ID, "resend"), http.StatusConflict)
+					body := post(t, tc.s, href(message.ID, "requeue"), http.StatusOK)
+					assert.Equal(t, "queued", body.Get("status").String(), body.Raw)
+					assert.EqualValues(t, 0, body.Get("send_count").Int(), body.Raw)
+				})
+
+				t.Run("case=resend", func(t *testing.T) {
+					message := newMessage(t)
+					post(t, tc.s, href(message.ID, "resend"), http.StatusConflict)
+
+					require.NoError(t, reg.CourierPersister().SetMessageStatus(ctx, message.ID, courier.MessageStatusSent))
+					body := post(t, tc.s, href(message.ID, "resend"), http.StatusOK)
+					assert.Equal(t, "queued", body.Get("status").String(), body.Raw)
+				})
+
+				t.Run("case=not found", func(t *testing.T) {
+					post(t, tc.s, href(uuidx.NewV4(), "cancel"), http.StatusNotFound)
+				})
+			})
+		}
+	})
+	t.Run("handler=suppressions", func(t *testing.T) {
+		do := func(t *testing.T, s *httptest.Server, method, href, body string, expectCode int) gjson.Result {
+			t.Helper()
+			req, err := http.NewRequest(method, s.URL+href, strings.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			res, err := s.Client().Do(req)
+			require.NoError(t, err)
+			resBody := ioutilx.MustReadAll(res.Body)
+			require.NoError(t, res.Body.Close())
+			assert.EqualValuesf(t, expectCode, res.StatusCode, "%s", resBody)
+			return gjson.ParseBytes(resBody)
+		}
+
+		for _, tc := range tss {
+			t.Run("endpoint="+tc.name, func(t *testing.T) {
+				href := x.AdminPrefix + courier.AdminRouteListSuppressions
+				recipient := tc.name + "-suppressed@ory.sh"
+
+				created := do(t, tc.s, "POST", href, fmt.Sprintf(`{"recipient":%q}`, strings.ToUpper(recipient)), http.StatusCreated)
+				assert.Equal(t, recipient, created.Get("recipient").String(), created.Raw)
+				assert.Equal(t, "manual", created.Get("reason").String(), created.Raw)
+
+				again := do(t, tc.s, "POST", href, fmt.Sprintf(`{"recipient":%q}`, recipient), http.StatusCreated)
+				assert.Equal(t, created.Get("id").String(), again.Get("id").String())
+
+				listed := do(t, tc.s, "GET", href+"?recipient="+recipient, "", http.StatusOK)
+				require.Len(t, listed.Array(), 1, listed.Raw)
+				assert.Equal(t, created.Get("id").String(), listed.Get("0.id").String())
+
+				do(t, tc.s, "DELETE", href+"/"+created.Get("id").String(), "", http.StatusNoContent)
+				do(t, tc.s, "DELETE", href+"/"+created.Get("id").String(), "", http.StatusNotFound)
+				assert.Empty(t, do(t, tc.s, "GET", href+"?recipient="+recipient, "", http.StatusOK).Array())
+
+				do(t, tc.s, "POST", href, `{"recipient":" "}`, http.StatusBadRequest)
+				do(t, tc.s, "DELETE", href+"/not-a-uuid", "", http.StatusBadRequest)
+			})
+		}
+	})
 }
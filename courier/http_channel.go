@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/pkg/errors"
 
@@ -29,11 +30,22 @@ type (
 		x.HTTPClientProvider
 		jsonnetsecure.VMProvider
 		ConfigProvider
+		PersistenceProvider
 	}
 )
 
 var _ Channel = new(httpChannel)
 
+// httpRecipientRejectedCodes are the status codes with which the HTTP server
+// rejects the recipient permanently. Other 4xx codes, such as 408 and 429, as
+// well as 5xx codes are retried.
+var httpRecipientRejectedCodes = map[int]struct{}{
+	http.StatusBadRequest:          {},
+	http.StatusNotFound:            {},
+	http.StatusGone:                {},
+	http.StatusUnprocessableEntity: {},
+}
+
 func newHttpChannel(id string, requestConfig *request.Config, d channelDependencies) *httpChannel {
 	return &httpChannel{
 		id:            id,
@@ -119,6 +131,23 @@ func (c *httpChannel) Dispatch(ctx context.Context, msg Message) (err error) {
 		WithField("http_response_body", string(body)).
 		Error("sending mail via HTTP failed.")
 
+	// The server rejected the recipient itself, so sending the message again
+	// or sending further messages to it would be rejected as well.
+	if _, ok := httpRecipientRejectedCodes[res.StatusCode]; ok {
+		if err := c.d.CourierPersister().SetMessageStatus(ctx, msg.ID, MessageStatusAbandoned); err != nil {
+			logger.
+				WithError(err).
+				Error(`Unable to reset the retried message's status to "abandoned".`)
+			return errors.WithStack(err)
+		}
+
+		if err := suppressRecipient(ctx, c.d.CourierPersister(), &msg, SuppressionReasonRejected); err != nil {
+			logger.
+				WithError(err).
+				Error("Unable to suppress the rejected recipient.")
+		}
+	}
+
 	return errors.WithStack(err)
 }
 
@@ -5,11 +5,15 @@ package courier_test
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,9 +23,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ory/kratos/courier"
 	"github.com/ory/kratos/courier/template/email"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/request"
 	"github.com/ory/kratos/x"
 	"github.com/ory/x/resilience"
 )
@@ -128,3 +134,115 @@ func TestQueueHTTPEmail(t *testing.T) {
 		assert.Equal(t, expectedEmail[i].Subject, message.Subject)
 	}
 }
+
+func TestHTTPChannelSigning(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		header http.Header
+		body   []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	t.Cleanup(srv.Close)
+
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "http")
+	conf.MustSet(ctx, config.ViperKeyCourierHTTPRequestConfig, fmt.Sprintf(`{
+		"url": "%s",
+		"method": "POST",
+		"body": "file://./stub/request.config.mailer.jsonnet",
+		"signing": {"secrets": ["whsec_%s"]}
+	}`, srv.URL, base64.StdEncoding.EncodeToString([]byte("courier-secret"))))
+
+	c, err := reg.Courier(ctx)
+	require.NoError(t, err)
+
+	_, err = c.QueueEmail(ctx, email.NewTestStub(reg, &email.TestStubModel{
+		To:      "test-signing@test.com",
+		Subject: "test-subject",
+		Body:    "test-body",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, c.DispatchQueue(ctx))
+	require.NotNil(t, header)
+
+	id := header.Get(request.HeaderWebhookID)
+	timestamp := header.Get(request.HeaderWebhookTimestamp)
+	require.NotEmpty(t, id)
+	require.NotEmpty(t, timestamp)
+
+	mac := hmac.New(sha256.New, []byte("courier-secret"))
+	_, _ = mac.Write([]byte(id + "." + timestamp + "."))
+	_, _ = mac.Write(body)
+	assert.Equal(t, "v1,"+base64.StdEncoding.EncodeToString(mac.Sum(nil)), header.Get(request.HeaderWebhookSignature))
+	assert.Contains(t, string(body), "test-signing@test.com")
+}
+
+func TestHTTPChannelSuppression(t *testing.T) {
+	ctx := context.Background()
+
+	var status atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(srv.Close)
+
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeyCourierDeliveryStrategy, "http")
+	conf.MustSet(ctx, config.ViperKeyCourierHTTPRequestConfig, fmt.Sprintf(`{
+		"url": "%s",
+		"method": "POST",
+		"body": "file://./stub/request.config.mailer.jsonnet"
+	}`, srv.URL))
+
+	c, err := reg.Courier(ctx)
+	require.NoError(t, err)
+
+	send := func(t *testing.T, code int, recipient string) *courier.Message {
+		status.Store(int32(code))
+		id, err := c.QueueEmail(ctx, email.NewTestStub(reg, &email.TestStubModel{
+			To:      recipient,
+			Subject: "test-subject",
+			Body:    "test-body",
+		}))
+		require.NoError(t, err)
+		require.NoError(t, c.DispatchQueue(ctx))
+
+		message, err := reg.CourierPersister().FetchMessage(ctx, id)
+		require.NoError(t, err)
+		return message
+	}
+
+	suppressions := func(t *testing.T, recipient string) []courier.Suppression {
+		suppressions, _, err := reg.CourierPersister().ListSuppressions(ctx, courier.ListCourierSuppressionsParameters{Recipient: recipient}, nil)
+		require.NoError(t, err)
+		return suppressions
+	}
+
+	for _, code := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusGone, http.StatusUnprocessableEntity} {
+		t.Run(fmt.Sprintf("status=%d suppresses the recipient", code), func(t *testing.T) {
+			recipient := fmt.Sprintf("rejected-%d@test.com", code)
+
+			message := send(t, code, recipient)
+			assert.Equal(t, courier.MessageStatusAbandoned, message.Status)
+
+			s := suppressions(t, recipient)
+			require.Len(t, s, 1)
+			assert.Equal(t, courier.SuppressionReasonRejected, s[0].Reason)
+			assert.Equal(t, message.ID, s[0].MessageID.UUID)
+		})
+	}
+
+	for _, code := range []int{http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError} {
+		t.Run(fmt.Sprintf("status=%d is retried", code), func(t *testing.T) {
+			recipient := fmt.Sprintf("retried-%d@test.com", code)
+
+			message := send(t, code, recipient)
+			assert.Equal(t, courier.MessageStatusQueued, message.Status)
+			assert.Empty(t, suppressions(t, recipient))
+		})
+	}
+}
@@ -27,6 +27,9 @@ const (
 	MessageStatusSent
 	MessageStatusProcessing
 	MessageStatusAbandoned
+	MessageStatusCancelled
+	MessageStatusDelivered
+	MessageStatusBounced
 )
 
 const (
@@ -34,6 +37,9 @@ const (
 	messageStatusSentText       = "sent"
 	messageStatusProcessingText = "processing"
 	messageStatusAbandonedText  = "abandoned"
+	messageStatusCancelledText  = "cancelled"
+	messageStatusDeliveredText  = "delivered"
+	messageStatusBouncedText    = "bounced"
 )
 
 func ToMessageStatus(str string) (MessageStatus, error) {
@@ -46,6 +52,12 @@ func ToMessageStatus(str string) (MessageStatus, error) {
 		return MessageStatusProcessing, nil
 	case s.AddCase(MessageStatusAbandoned.String()):
 		return MessageStatusAbandoned, nil
+	case s.AddCase(MessageStatusCancelled.String()):
+		return MessageStatusCancelled, nil
+	case s.AddCase(MessageStatusDelivered.String()):
+		return MessageStatusDelivered, nil
+	case s.AddCase(MessageStatusBounced.String()):
+		return MessageStatusBounced, nil
 	default:
 		return 0, errors.WithStack(herodot.ErrBadRequest.WithWrap(s.ToUnknownCaseErr()).WithReason("Message status is not valid"))
 	}
@@ -61,6 +73,12 @@ func (ms MessageStatus) String() string {
 		return messageStatusProcessingText
 	case MessageStatusAbandoned:
 		return messageStatusAbandonedText
+	case MessageStatusCancelled:
+		return messageStatusCancelledText
+	case MessageStatusDelivered:
+		return messageStatusDeliveredText
+	case MessageStatusBounced:
+		return messageStatusBouncedText
 	default:
 		return ""
 	}
@@ -68,7 +86,7 @@ func (ms MessageStatus) String() string {
 
 func (ms MessageStatus) IsValid() error {
 	switch ms {
-	case MessageStatusQueued, MessageStatusSent, MessageStatusProcessing, MessageStatusAbandoned:
+	case MessageStatusQueued, MessageStatusSent, MessageStatusProcessing, MessageStatusAbandoned, MessageStatusCancelled, MessageStatusDelivered, MessageStatusBounced:
 		return nil
 	default:
 		return errors.WithStack(herodot.ErrBadRequest.WithReason("Message status is not valid"))
@@ -192,6 +210,9 @@ type Message struct {
 	// required: true
 	SendCount int `json:"send_count" db:"send_count"`
 
+	// NextAttemptAt is the earliest time at which a failed message is retried.
+	NextAttemptAt sqlxx.NullTime `json:"next_attempt_at" faker:"-" db:"next_attempt_at"`
+
 	// Dispatches store information about the attempts of delivering a message
 	// May contain an error if any happened, or just the `success` state.
 	Dispatches []MessageDispatch `json:"dispatches,omitempty" has_many:"courier_message_dispatches" order_by:"created_at desc" faker:"-"`
@@ -24,6 +24,9 @@ func TestToMessageStatus(t *testing.T) {
 			"sent":       courier.MessageStatusSent,
 			"processing": courier.MessageStatusProcessing,
 			"abandoned":  courier.MessageStatusAbandoned,
+			"cancelled":  courier.MessageStatusCancelled,
+			"delivered":  courier.MessageStatusDelivered,
+			"bounced":    courier.MessageStatusBounced,
 		} {
 			result, err := courier.ToMessageStatus(str)
 			require.NoError(t, err)
@@ -5,6 +5,7 @@ package courier
 
 import (
 	"context"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
@@ -36,6 +37,40 @@ type (
 		// Records an attempt of sending out a courier message
 		// Returns an error if it fails
 		RecordDispatch(ctx context.Context, msgID uuid.UUID, status CourierMessageDispatchStatus, err error) error
+
+		// ScheduleMessageRetry queues a message which is being processed again,
+		// to be retried no earlier than nextAttemptAt.
+		ScheduleMessageRetry(ctx context.Context, msgID uuid.UUID, nextAttemptAt time.Time) error
+
+		// CancelMessage cancels a queued message. Returns herodot.ErrConflict if
+		// the message is not queued.
+		CancelMessage(ctx context.Context, msgID uuid.UUID) (*Message, error)
+
+		// RequeueMessage queues a message in one of the given states again and
+		// resets its send count. Returns herodot.ErrConflict if the message is
+		// in any other state.
+		RequeueMessage(ctx context.Context, msgID uuid.UUID, from ...MessageStatus) (*Message, error)
+
+		// SetMessageDeliveryStatus sets the status of a sent message to the
+		// delivery status reported by its channel. Returns sqlcon.ErrNoRows if
+		// the message does not exist or was not sent.
+		SetMessageDeliveryStatus(ctx context.Context, msgID uuid.UUID, status MessageStatus) error
+
+		// AddSuppression suppresses the recipient and marks the verifiable
+		// addresses of the recipient as suppressed. If the recipient is
+		// already suppressed, the existing suppression is loaded instead.
+		AddSuppression(ctx context.Context, s *Suppression) error
+
+		// IsSuppressed returns true if the recipient is suppressed.
+		IsSuppressed(ctx context.Context, recipient string) (bool, error)
+
+		// ListSuppressions lists the suppressed recipients.
+		ListSuppressions(context.Context, ListCourierSuppressionsParameters, []keysetpagination.Option) ([]Suppression, *keysetpagination.Paginator, error)
+
+		// DeleteSuppression removes a suppression and restores the status of
+		// the verifiable addresses of the recipient. Returns sqlcon.ErrNoRows
+		// if the suppression does not exist.
+		DeleteSuppression(ctx context.Context, id uuid.UUID) error
 	}
 	PersistenceProvider interface {
 		CourierPersister() Persister
@@ -32,6 +32,14 @@ type (
 
 var _ Channel = new(SMTPChannel)
 
+// smtpRecipientRejectedCodes are the SMTP reply codes which reject the mailbox
+// of the recipient permanently.
+var smtpRecipientRejectedCodes = map[int]struct{}{
+	550: {}, // Mailbox unavailable
+	551: {}, // User not local
+	553: {}, // Mailbox name not allowed
+}
+
 func NewSMTPChannel(deps Dependencies, cfg *config.SMTPConfig) (*SMTPChannel, error) {
 	return NewSMTPChannelWithCustomTemplates(deps, cfg, NewEmailTemplateFromMessage)
 }
@@ -159,6 +167,16 @@ func (c *SMTPChannel) Dispatch(ctx context.Context, msg Message) (err error) {
 					Error(`Unable to reset the retried message's status to "abandoned".`)
 				return errors.WithStack(err)
 			}
+
+			// The server rejected the mailbox itself, so any further message
+			// to it would be rejected as well.
+			if _, ok := smtpRecipientRejectedCodes[protoErr.Code]; ok {
+				if err := suppressRecipient(ctx, c.d.CourierPersister(), &msg, SuppressionReasonRejected); err != nil {
+					logger.
+						WithError(err).
+						Error("Unable to suppress the rejected recipient.")
+				}
+			}
 		}
 
 		return errors.WithStack(herodot.ErrInternalServerError.
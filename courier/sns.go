@@ -0,0 +1,190 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package courier
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha1" //nolint:gosec // SNS signature version 1 uses SHA1.
+	_ "crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+)
+
+// snsNotification is a message Amazon SNS delivers to an HTTPS endpoint, see
+// https://docs.aws.amazon.com/sns/latest/dg/http-notification-json.html
+type snsNotification struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	SubscribeURL     string `json:"SubscribeURL"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+}
+
+// snsCertificateHost matches the hosts Amazon SNS serves its signing
+// certificates from.
+var snsCertificateHost = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// snsCertificateTTL is how long fetched signing certificates are cached.
+const snsCertificateTTL = 24 * time.Hour
+
+// maxSNSCertificates bounds the number of cached signing certificates.
+const maxSNSCertificates = 32
+
+type (
+	snsCertificateCache struct {
+		mu    sync.Mutex
+		certs map[string]*snsCertificate
+	}
+	snsCertificate struct {
+		cert      *x509.Certificate
+		expiresAt time.Time
+	}
+)
+
+// snsCertificates caches the signing certificates of Amazon SNS, which are
+// the same for all channels.
+var snsCertificates = new(snsCertificateCache)
+
+func (c *snsCertificateCache) get(certURL string) (*x509.Certificate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cert, ok := c.certs[certURL]; ok && time.Now().Before(cert.expiresAt) {
+		return cert.cert, true
+	}
+	return nil, false
+}
+
+func (c *snsCertificateCache) set(certURL string, cert *x509.Certificate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.certs == nil || len(c.certs) >= maxSNSCertificates {
+		c.certs = make(map[string]*snsCertificate)
+	}
+	c.certs[certURL] = &snsCertificate{cert: cert, expiresAt: time.Now().Add(snsCertificateTTL)}
+}
+
+// verify checks the signature of the notification, see
+// https://docs.aws.amazon.com/sns/latest/dg/sns-verify-signature-of-message.html
+func (n *snsNotification) verify(ctx context.Context, c *apiChannel) error {
+	var hash crypto.Hash
+	switch n.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return errors.WithStack(herodot.ErrUnauthorized.WithReasonf("The SNS signature version %q is not supported.", n.SignatureVersion))
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(n.Signature)
+	if err != nil {
+		return errors.WithStack(herodot.ErrUnauthorized.WithReason("The SNS signature is invalid."))
+	}
+
+	cert, err := c.snsCertificate(ctx, n.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.WithStack(herodot.ErrUnauthorized.WithReason("The SNS signing certificate does not contain an RSA key."))
+	}
+
+	digest := hash.New()
+	_, _ = digest.Write([]byte(n.stringToSign()))
+	if err := rsa.VerifyPKCS1v15(key, hash, digest.Sum(nil), signature); err != nil {
+		return errors.WithStack(herodot.ErrUnauthorized.WithReason("The SNS signature is invalid."))
+	}
+	return nil
+}
+
+// stringToSign returns the fields of the notification which are signed, in
+// the order defined by Amazon SNS.
+func (n *snsNotification) stringToSign() string {
+	fields := [][2]string{{"Message", n.Message}, {"MessageId", n.MessageID}}
+	if n.Type == "Notification" {
+		if n.Subject != "" {
+			fields = append(fields, [2]string{"Subject", n.Subject})
+		}
+	} else {
+		fields = append(fields, [2]string{"SubscribeURL", n.SubscribeURL})
+	}
+	fields = append(fields, [2]string{"Timestamp", n.Timestamp})
+	if n.Type != "Notification" {
+		fields = append(fields, [2]string{"Token", n.Token})
+	}
+	fields = append(fields, [2]string{"TopicArn", n.TopicArn}, [2]string{"Type", n.Type})
+
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(f[0] + "\n" + f[1] + "\n")
+	}
+	return b.String()
+}
+
+// snsCertificate returns the signing certificate, which must be served by
+// Amazon SNS.
+func (c *apiChannel) snsCertificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	u, err := url.Parse(certURL)
+	if err != nil || u.Scheme != "https" || !snsCertificateHost.MatchString(u.Hostname()) {
+		return nil, errors.WithStack(herodot.ErrUnauthorized.WithReasonf("The SNS signing certificate URL %q is not an Amazon SNS endpoint.", certURL))
+	}
+
+	if cert, ok := snsCertificates.get(u.String()); ok {
+		return cert, nil
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res, err := c.d.HTTPClient(ctx).Do(req)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrUpstreamError.WithWrap(err).WithReason("Unable to fetch the SNS signing certificate."))
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.WithStack(herodot.ErrUpstreamError.WithReasonf("Unable to fetch the SNS signing certificate, received status code %d.", res.StatusCode))
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrUpstreamError.WithWrap(err).WithReason("Unable to fetch the SNS signing certificate."))
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.WithStack(herodot.ErrUpstreamError.WithReason("The SNS signing certificate is not PEM encoded."))
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrUpstreamError.WithWrap(err).WithReason("The SNS signing certificate is invalid."))
+	}
+
+	snsCertificates.set(u.String(), cert)
+	return cert, nil
+}
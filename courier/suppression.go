@@ -0,0 +1,109 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package courier
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/ory/herodot"
+	keysetpagination "github.com/ory/x/pagination/keysetpagination_v2"
+	"github.com/ory/x/uuidx"
+)
+
+// A Suppression's Reason
+//
+// swagger:enum SuppressionReason
+type SuppressionReason string
+
+const (
+	// SuppressionReasonBounced is used if the provider reported a permanent
+	// bounce of a message to the recipient.
+	SuppressionReasonBounced SuppressionReason = "bounced"
+
+	// SuppressionReasonComplained is used if the recipient reported a message
+	// as spam.
+	SuppressionReasonComplained SuppressionReason = "complained"
+
+	// SuppressionReasonRejected is used if the server of the channel
+	// permanently rejected the recipient.
+	SuppressionReasonRejected SuppressionReason = "rejected"
+
+	// SuppressionReasonManual is used for suppressions added using the admin
+	// API.
+	SuppressionReasonManual SuppressionReason = "manual"
+)
+
+// ErrRecipientSuppressed is returned when dispatching a message to a
+// suppressed recipient.
+var ErrRecipientSuppressed = herodot.ErrBadRequest.
+	WithReason("The recipient is suppressed because earlier messages could not be delivered.")
+
+// A suppressed recipient. No messages are sent to suppressed recipients.
+//
+// swagger:model courierSuppression
+type Suppression struct {
+	// required: true
+	ID uuid.UUID `json:"id" faker:"-" db:"id"`
+
+	NID uuid.UUID `json:"-" faker:"-" db:"nid"`
+
+	// The email address or phone number of the recipient.
+	//
+	// required: true
+	Recipient string `json:"recipient" db:"recipient"`
+
+	// required: true
+	Reason SuppressionReason `json:"reason" db:"reason"`
+
+	// The message which could not be delivered to the recipient, if any.
+	MessageID uuid.NullUUID `json:"message_id" faker:"-" db:"message_id"`
+
+	// CreatedAt is a helper struct field for gobuffalo.pop.
+	// required: true
+	CreatedAt time.Time `json:"created_at" faker:"-" db:"created_at"`
+	// UpdatedAt is a helper struct field for gobuffalo.pop.
+	// required: true
+	UpdatedAt time.Time `json:"updated_at" faker:"-" db:"updated_at"`
+}
+
+// NormalizeRecipient normalizes the recipient of a suppression the same way
+// identity addresses are normalized.
+func NormalizeRecipient(recipient string) string {
+	return strings.ToLower(strings.TrimSpace(recipient))
+}
+
+// suppressRecipient suppresses the recipient of a message which could not be
+// delivered.
+func suppressRecipient(ctx context.Context, p Persister, msg *Message, reason SuppressionReason) error {
+	return p.AddSuppression(ctx, &Suppression{
+		ID:        uuidx.NewV4(),
+		Recipient: msg.Recipient,
+		Reason:    reason,
+		MessageID: uuid.NullUUID{UUID: msg.ID, Valid: true},
+	})
+}
+
+func (s Suppression) PageToken() keysetpagination.PageToken {
+	return keysetpagination.NewPageToken(
+		keysetpagination.Column{
+			Name:  "created_at",
+			Order: keysetpagination.OrderDescending,
+			Value: s.CreatedAt,
+		}, keysetpagination.Column{
+			Name:  "id",
+			Value: s.ID,
+		},
+	)
+}
+
+func (s Suppression) DefaultPageToken() keysetpagination.PageToken {
+	return Suppression{ID: uuid.Nil, CreatedAt: time.Date(2200, 12, 31, 23, 59, 59, 0, time.UTC)}.PageToken()
+}
+
+func (s Suppression) TableName() string { return "courier_suppressions" }
+func (s *Suppression) GetID() uuid.UUID { return s.ID }
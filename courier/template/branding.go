@@ -0,0 +1,29 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/ory/kratos/driver/config"
+)
+
+// Branding holds the branding variables of a message, e.g. the product name
+// and logo URL. They are available as `.Branding` in every template and are
+// part of the payload of HTTP channels.
+type Branding = config.CourierBranding
+
+// ResolveBranding returns the branding variables for a message to an identity
+// of the given identity schema and organization in the given locale. The
+// schema ID and organization are empty if the recipient is not a known
+// identity.
+func ResolveBranding(ctx context.Context, d Dependencies, schemaID string, organizationID uuid.NullUUID, locale string) Branding {
+	var org string
+	if organizationID.Valid {
+		org = organizationID.UUID.String()
+	}
+	return d.CourierConfig().CourierBranding(ctx, schemaID, org, locale)
+}
@@ -0,0 +1,33 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package template_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ory/kratos/courier/template"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/x/contextx"
+)
+
+func TestResolveBranding(t *testing.T) {
+	_, reg := internal.NewFastRegistryWithMocks(t)
+	org := uuid.Must(uuid.NewV4())
+	ctx := contextx.WithConfigValue(context.Background(), config.ViperKeyCourierBranding, map[string]any{
+		"product_name": "Ory",
+		"organizations": map[string]any{
+			org.String(): map[string]any{"product_name": "Acme"},
+		},
+	})
+
+	assert.Equal(t, "Ory", template.ResolveBranding(ctx, reg, "", uuid.NullUUID{}, "").ProductName)
+	assert.Equal(t, "Ory", template.ResolveBranding(ctx, reg, "", uuid.NullUUID{UUID: org}, "").ProductName, "ignores invalid organization IDs")
+	assert.Equal(t, "Acme", template.ResolveBranding(ctx, reg, "", uuid.NullUUID{UUID: org, Valid: true}, "").ProductName)
+	assert.Zero(t, template.ResolveBranding(context.Background(), reg, "", uuid.NullUUID{UUID: org, Valid: true}, ""))
+}
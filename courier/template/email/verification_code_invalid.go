@@ -21,6 +21,8 @@ type (
 		To               string                 `json:"to"`
 		RequestURL       string                 `json:"request_url"`
 		TransientPayload map[string]interface{} `json:"transient_payload"`
+		Locale           string                 `json:"locale,omitempty"`
+		Branding         template.Branding      `json:"branding,omitzero"`
 	}
 )
 
@@ -39,8 +41,9 @@ func (t *VerificationCodeInvalid) EmailSubject(ctx context.Context) (string, err
 		os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)),
 		"verification_code/invalid/email.subject.gotmpl",
 		"verification_code/invalid/email.subject*",
+		t.m.Locale,
 		t.m,
-		t.d.CourierConfig().CourierTemplatesVerificationCodeInvalid(ctx).Subject,
+		t.d.CourierConfig().CourierTemplatesVerificationCodeInvalid(ctx).ForLocale(t.m.Locale).Subject,
 	)
 
 	return strings.TrimSpace(subject), err
@@ -53,8 +56,9 @@ func (t *VerificationCodeInvalid) EmailBody(ctx context.Context) (string, error)
 		os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)),
 		"verification_code/invalid/email.body.gotmpl",
 		"verification_code/invalid/email.body*",
+		t.m.Locale,
 		t.m,
-		t.d.CourierConfig().CourierTemplatesVerificationCodeInvalid(ctx).Body.HTML,
+		t.d.CourierConfig().CourierTemplatesVerificationCodeInvalid(ctx).ForLocale(t.m.Locale).Body.HTML,
 	)
 }
 
@@ -65,8 +69,9 @@ func (t *VerificationCodeInvalid) EmailBodyPlaintext(ctx context.Context) (strin
 		os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)),
 		"verification_code/invalid/email.body.plaintext.gotmpl",
 		"verification_code/invalid/email.body.plaintext*",
+		t.m.Locale,
 		t.m,
-		t.d.CourierConfig().CourierTemplatesVerificationCodeInvalid(ctx).Body.PlainText,
+		t.d.CourierConfig().CourierTemplatesVerificationCodeInvalid(ctx).ForLocale(t.m.Locale).Body.PlainText,
 	)
 }
 
@@ -25,6 +25,8 @@ type (
 		RequestURL       string                 `json:"request_url"`
 		TransientPayload map[string]interface{} `json:"transient_payload"`
 		ExpiresInMinutes int                    `json:"expires_in_minutes"`
+		Locale           string                 `json:"locale,omitempty"`
+		Branding         template.Branding      `json:"branding,omitzero"`
 	}
 )
 
@@ -43,8 +45,9 @@ func (t *VerificationCodeValid) EmailSubject(ctx context.Context) (string, error
 		os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)),
 		"verification_code/valid/email.subject.gotmpl",
 		"verification_code/valid/email.subject*",
+		t.m.Locale,
 		t.m,
-		t.d.CourierConfig().CourierTemplatesVerificationCodeValid(ctx).Subject,
+		t.d.CourierConfig().CourierTemplatesVerificationCodeValid(ctx).ForLocale(t.m.Locale).Subject,
 	)
 
 	return strings.TrimSpace(subject), err
@@ -56,8 +59,9 @@ func (t *VerificationCodeValid) EmailBody(ctx context.Context) (string, error) {
 		os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)),
 		"verification_code/valid/email.body.gotmpl",
 		"verification_code/valid/email.body*",
+		t.m.Locale,
 		t.m,
-		t.d.CourierConfig().CourierTemplatesVerificationCodeValid(ctx).Body.HTML,
+		t.d.CourierConfig().CourierTemplatesVerificationCodeValid(ctx).ForLocale(t.m.Locale).Body.HTML,
 	)
 }
 
@@ -67,8 +71,9 @@ func (t *VerificationCodeValid) EmailBodyPlaintext(ctx context.Context) (string,
 		os.DirFS(t.d.CourierConfig().CourierTemplatesRoot(ctx)),
 		"verification_code/valid/email.body.plaintext.gotmpl",
 		"verification_code/valid/email.body.plaintext*",
+		t.m.Locale,
 		t.m,
-		t.d.CourierConfig().CourierTemplatesVerificationCodeValid(ctx).Body.PlainText,
+		t.d.CourierConfig().CourierTemplatesVerificationCodeValid(ctx).ForLocale(t.m.Locale).Body.PlainText,
 	)
 }
 
@@ -5,12 +5,18 @@ package email_test
 
 import (
 	"context"
+	"encoding/base64"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/ory/kratos/courier/template"
 	"github.com/ory/kratos/courier/template/email"
 	"github.com/ory/kratos/courier/template/testhelpers"
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/internal"
+	"github.com/ory/x/configx"
 )
 
 func TestVerifyCodeValid(t *testing.T) {
@@ -27,4 +33,25 @@ func TestVerifyCodeValid(t *testing.T) {
 	t.Run("test=with remote resources", func(t *testing.T) {
 		testhelpers.TestRemoteTemplates(t, "../courier/builtin/templates/verification_code/valid", template.TypeVerificationCodeValid)
 	})
+
+	t.Run("test=with localized remote resources", func(t *testing.T) {
+		_, reg := internal.NewFastRegistryWithMocks(t, configx.WithValues(map[string]interface{}{
+			config.ViperKeyCourierTemplatesVerificationCodeValidEmail + ".locales.de.subject": "base64://" + base64.StdEncoding.EncodeToString([]byte("Bitte bestätigen Sie {{ .To }}")),
+		}))
+
+		tpl := email.NewVerificationCodeValid(reg, &email.VerificationCodeValidModel{To: "foo@ory.sh", Locale: "de-DE"})
+		subject, err := tpl.EmailSubject(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Bitte bestätigen Sie foo@ory.sh", subject)
+
+		// Templates which are not localized fall back to the default ones.
+		body, err := tpl.EmailBodyPlaintext(ctx)
+		require.NoError(t, err)
+		assert.NotEmpty(t, body)
+
+		tpl = email.NewVerificationCodeValid(reg, &email.VerificationCodeValidModel{To: "foo@ory.sh", VerificationCode: "123456", Locale: "fr"})
+		subject, err = tpl.EmailSubject(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "Use code 123456 to verify your account", subject)
+	})
 }
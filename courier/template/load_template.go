@@ -10,6 +10,7 @@ import (
 	htemplate "html/template"
 	"io"
 	"io/fs"
+	"path"
 	"path/filepath"
 	"text/template"
 
@@ -145,7 +146,29 @@ func loadTemplate(filesystem fs.FS, name, pattern string, html bool) (Template,
 	return tpl, nil
 }
 
-func LoadText(ctx context.Context, d templateDependencies, filesystem fs.FS, name, pattern string, model interface{}, remoteURL string) (string, error) {
+// loadLocalizedTemplate loads the template from the subdirectory of the locale, e.g.
+// `verification_code/valid/de/email.body.gotmpl`, and falls back to the default
+// template if the locale has no template.
+func loadLocalizedTemplate(filesystem fs.FS, name, pattern, locale string, html bool) (Template, error) {
+	for _, l := range localeCandidates(locale) {
+		localizedName := path.Join(path.Dir(name), l, path.Base(name))
+		if _, found := Cache.Get(localizedName); !found {
+			if matches, _ := fs.Glob(filesystem, localizedName); matches == nil {
+				continue
+			}
+		}
+
+		var localizedPattern string
+		if pattern != "" {
+			localizedPattern = path.Join(path.Dir(pattern), l, path.Base(pattern))
+		}
+		return loadTemplate(filesystem, localizedName, localizedPattern, html)
+	}
+
+	return loadTemplate(filesystem, name, pattern, html)
+}
+
+func LoadText(ctx context.Context, d templateDependencies, filesystem fs.FS, name, pattern, locale string, model interface{}, remoteURL string) (string, error) {
 	var t Template
 	var err error
 	if remoteURL != "" {
@@ -154,7 +177,7 @@ func LoadText(ctx context.Context, d templateDependencies, filesystem fs.FS, nam
 			return "", err
 		}
 	} else {
-		t, err = loadTemplate(filesystem, name, pattern, false)
+		t, err = loadLocalizedTemplate(filesystem, name, pattern, locale, false)
 		if err != nil {
 			return "", err
 		}
@@ -167,7 +190,7 @@ func LoadText(ctx context.Context, d templateDependencies, filesystem fs.FS, nam
 	return b.String(), nil
 }
 
-func LoadHTML(ctx context.Context, d templateDependencies, filesystem fs.FS, name, pattern string, model interface{}, remoteURL string) (string, error) {
+func LoadHTML(ctx context.Context, d templateDependencies, filesystem fs.FS, name, pattern, locale string, model interface{}, remoteURL string) (string, error) {
 	var t Template
 	var err error
 	if remoteURL != "" {
@@ -176,7 +199,7 @@ func LoadHTML(ctx context.Context, d templateDependencies, filesystem fs.FS, nam
 			return "", err
 		}
 	} else {
-		t, err = loadTemplate(filesystem, name, pattern, true)
+		t, err = loadLocalizedTemplate(filesystem, name, pattern, locale, true)
 		if err != nil {
 			return "", err
 		}
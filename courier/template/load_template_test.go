@@ -30,7 +30,7 @@ func TestLoadTextTemplate(t *testing.T) {
 	executeTextTemplate := func(t *testing.T, dir, name, pattern string, model map[string]interface{}) string {
 		ctx := context.Background()
 		_, reg := internal.NewFastRegistryWithMocks(t)
-		tp, err := template.LoadText(ctx, reg, os.DirFS(dir), name, pattern, model, "")
+		tp, err := template.LoadText(ctx, reg, os.DirFS(dir), name, pattern, "", model, "")
 		require.NoError(t, err)
 		return tp
 	}
@@ -38,7 +38,7 @@ func TestLoadTextTemplate(t *testing.T) {
 	executeHTMLTemplate := func(t *testing.T, dir, name, pattern string, model map[string]interface{}) string {
 		ctx := context.Background()
 		_, reg := internal.NewFastRegistryWithMocks(t)
-		tp, err := template.LoadHTML(ctx, reg, os.DirFS(dir), name, pattern, model, "")
+		tp, err := template.LoadHTML(ctx, reg, os.DirFS(dir), name, pattern, "", model, "")
 		require.NoError(t, err)
 		return tp
 	}
@@ -70,7 +70,7 @@ func TestLoadTextTemplate(t *testing.T) {
 
 		for _, tc := range nonhermetic {
 			t.Run("case=should not support function: "+tc, func(t *testing.T) {
-				_, err := template.LoadText(ctx, reg, x.NewStubFS(tc, []byte(fmt.Sprintf("{{ %s }}", tc))), tc, "", map[string]interface{}{}, "")
+				_, err := template.LoadText(ctx, reg, x.NewStubFS(tc, []byte(fmt.Sprintf("{{ %s }}", tc))), tc, "", "", map[string]interface{}{}, "")
 				require.Error(t, err)
 				require.Contains(t, err.Error(), fmt.Sprintf("function \"%s\" not defined", tc))
 			})
@@ -96,6 +96,37 @@ func TestLoadTextTemplate(t *testing.T) {
 		assert.Contains(t, executeTextTemplate(t, dir, name, "", nil), "cached stub body")
 	})
 
+	t.Run("method=localized", func(t *testing.T) {
+		dir := t.TempDir()
+		name := x.NewUUID().String()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, name, "de"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, "email.body.gotmpl"), []byte("Hello {{ .name }}"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, "de", "email.body.gotmpl"), []byte("Hallo {{ .name }}"), 0o600))
+
+		ctx := context.Background()
+		_, reg := internal.NewFastRegistryWithMocks(t)
+		for _, tc := range []struct {
+			locale, expected string
+		}{
+			{locale: "de", expected: "Hallo Ory"},
+			{locale: "de-AT", expected: "Hallo Ory"},
+			{locale: "fr", expected: "Hello Ory"},
+			{locale: "../..", expected: "Hello Ory"},
+			{locale: "", expected: "Hello Ory"},
+		} {
+			t.Run("locale="+tc.locale, func(t *testing.T) {
+				m := map[string]interface{}{"name": "Ory"}
+				actual, err := template.LoadText(ctx, reg, os.DirFS(dir), name+"/email.body.gotmpl", name+"/email.body*", tc.locale, m, "")
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, actual)
+
+				actual, err = template.LoadHTML(ctx, reg, os.DirFS(dir), name+"/email.body.gotmpl", name+"/email.body*", tc.locale, m, "")
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, actual)
+			})
+		}
+	})
+
 	t.Run("method=remote resource", func(t *testing.T) {
 		_, reg := internal.NewFastRegistryWithMocks(t)
 
@@ -108,7 +139,7 @@ func TestLoadTextTemplate(t *testing.T) {
 				f, err := os.ReadFile("courier/builtin/templates/test_stub/email.body.html.en_US.gotmpl")
 				require.NoError(t, err)
 				b64 := base64.StdEncoding.EncodeToString(f)
-				tp, err := template.LoadHTML(ctx, reg, nil, "", "", m, "base64://"+b64)
+				tp, err := template.LoadHTML(ctx, reg, nil, "", "", "", m, "base64://"+b64)
 				require.NoError(t, err)
 				assert.Contains(t, tp, "lang=en_US")
 			})
@@ -120,7 +151,7 @@ func TestLoadTextTemplate(t *testing.T) {
 
 				b64 := base64.StdEncoding.EncodeToString(f)
 
-				tp, err := template.LoadText(ctx, reg, nil, "", "", m, "base64://"+b64)
+				tp, err := template.LoadText(ctx, reg, nil, "", "", "", m, "base64://"+b64)
 				require.NoError(t, err)
 				assert.Contains(t, tp, "stub email body something")
 			})
@@ -129,14 +160,14 @@ func TestLoadTextTemplate(t *testing.T) {
 		t.Run("case=file resource", func(t *testing.T) {
 			t.Run("case=html template", func(t *testing.T) {
 				m := map[string]interface{}{"lang": "en_US"}
-				tp, err := template.LoadHTML(ctx, reg, nil, "", "", m, "file://courier/builtin/templates/test_stub/email.body.html.en_US.gotmpl")
+				tp, err := template.LoadHTML(ctx, reg, nil, "", "", "", m, "file://courier/builtin/templates/test_stub/email.body.html.en_US.gotmpl")
 				require.NoError(t, err)
 				assert.Contains(t, tp, "lang=en_US")
 			})
 
 			t.Run("case=plaintext", func(t *testing.T) {
 				m := map[string]interface{}{"Body": "something"}
-				tp, err := template.LoadText(ctx, reg, nil, "", "", m, "file://courier/builtin/templates/test_stub/email.body.plaintext.gotmpl")
+				tp, err := template.LoadText(ctx, reg, nil, "", "", "", m, "file://courier/builtin/templates/test_stub/email.body.plaintext.gotmpl")
 				require.NoError(t, err)
 				assert.Contains(t, tp, "stub email body something")
 			})
@@ -155,26 +186,26 @@ func TestLoadTextTemplate(t *testing.T) {
 
 			t.Run("case=html template", func(t *testing.T) {
 				m := map[string]interface{}{"lang": "en_US"}
-				tp, err := template.LoadHTML(ctx, reg, nil, "", "", m, ts.URL+"/html")
+				tp, err := template.LoadHTML(ctx, reg, nil, "", "", "", m, ts.URL+"/html")
 				require.NoError(t, err)
 				assert.Contains(t, tp, "lang=en_US")
 			})
 
 			t.Run("case=plaintext", func(t *testing.T) {
 				m := map[string]interface{}{"Body": "something"}
-				tp, err := template.LoadText(ctx, reg, nil, "", "", m, ts.URL+"/plaintext")
+				tp, err := template.LoadText(ctx, reg, nil, "", "", "", m, ts.URL+"/plaintext")
 				require.NoError(t, err)
 				assert.Contains(t, tp, "stub email body something")
 			})
 		})
 
 		t.Run("case=unsupported resource", func(t *testing.T) {
-			tp, err := template.LoadHTML(ctx, reg, nil, "", "", map[string]interface{}{}, "grpc://unsupported-url")
+			tp, err := template.LoadHTML(ctx, reg, nil, "", "", "", map[string]interface{}{}, "grpc://unsupported-url")
 
 			require.ErrorIs(t, err, fetcher.ErrUnknownScheme)
 			require.Empty(t, tp)
 
-			tp, err = template.LoadText(ctx, reg, nil, "", "", map[string]interface{}{}, "grpc://unsupported-url")
+			tp, err = template.LoadText(ctx, reg, nil, "", "", "", map[string]interface{}{}, "grpc://unsupported-url")
 			require.ErrorIs(t, err, fetcher.ErrUnknownScheme)
 			require.Empty(t, tp)
 		})
@@ -184,21 +215,21 @@ func TestLoadTextTemplate(t *testing.T) {
 			reg.HTTPClient(ctx).RetryMax = 1
 			reg.HTTPClient(ctx).RetryWaitMax = time.Millisecond
 
-			_, err := template.LoadHTML(ctx, reg, nil, "", "", map[string]interface{}{}, "http://localhost:8080/1234")
+			_, err := template.LoadHTML(ctx, reg, nil, "", "", "", map[string]interface{}{}, "http://localhost:8080/1234")
 
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), "is not a permitted destination")
 
-			_, err = template.LoadText(ctx, reg, nil, "", "", map[string]interface{}{}, "http://localhost:8080/1234")
+			_, err = template.LoadText(ctx, reg, nil, "", "", "", map[string]interface{}{}, "http://localhost:8080/1234")
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), "is not a permitted destination")
 		})
 
 		t.Run("method=cache works", func(t *testing.T) {
-			tp1, err := template.LoadText(ctx, reg, nil, "", "", map[string]interface{}{}, "base64://e3sgJGwgOj0gY2F0ICJsYW5nPSIgLmxhbmcgfX0Ke3sgbm9zcGFjZSAkbCB9fQ==")
+			tp1, err := template.LoadText(ctx, reg, nil, "", "", "", map[string]interface{}{}, "base64://e3sgJGwgOj0gY2F0ICJsYW5nPSIgLmxhbmcgfX0Ke3sgbm9zcGFjZSAkbCB9fQ==")
 			assert.NoError(t, err)
 
-			tp2, err := template.LoadText(ctx, reg, nil, "", "", map[string]interface{}{}, "base64://c3R1YiBlbWFpbCBib2R5IHt7IC5Cb2R5IH19")
+			tp2, err := template.LoadText(ctx, reg, nil, "", "", "", map[string]interface{}{}, "base64://c3R1YiBlbWFpbCBib2R5IHt7IC5Cb2R5IH19")
 			assert.NoError(t, err)
 
 			require.NotEqualf(t, tp1, tp2, "Expected remote template 1 and remote template 2 to not be equal")
@@ -0,0 +1,56 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package template
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tidwall/gjson"
+	"golang.org/x/text/language"
+
+	"github.com/ory/kratos/x"
+)
+
+var multipleLanguages = language.Make("mul")
+
+// Locale returns the locale a message to the identity with the given traits is
+// sent in. The locale is read from the configured locale trait, or from the
+// Accept-Language header of the current request. An empty locale selects the
+// default templates.
+func Locale(ctx context.Context, d Dependencies, traits json.RawMessage) string {
+	if path := d.CourierConfig().CourierLocaleTrait(ctx); path != "" && len(traits) > 0 {
+		// Only valid language tags are used, as the locale is part of the template path.
+		if tag, err := language.Parse(gjson.GetBytes(traits, path).String()); err == nil {
+			return tag.String()
+		}
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(x.AcceptLanguageFromContext(ctx))
+	if err != nil {
+		return ""
+	}
+	for _, tag := range tags {
+		// The wildcard `*` is parsed as the "multiple languages" tag.
+		if tag != language.Und && tag != multipleLanguages {
+			return tag.String()
+		}
+	}
+	return ""
+}
+
+// localeCandidates returns the locales to look templates up for, the exact
+// locale first and its base language second.
+func localeCandidates(locale string) []string {
+	tag, err := language.Parse(locale)
+	if err != nil || tag == language.Und {
+		return nil
+	}
+
+	candidates := []string{tag.String()}
+	if base, conf := tag.Base(); conf != language.No && base.String() != tag.String() {
+		candidates = append(candidates, base.String())
+	}
+	return candidates
+}
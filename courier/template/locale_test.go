@@ -0,0 +1,47 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package template_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ory/kratos/courier/template"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/contextx"
+)
+
+func TestLocale(t *testing.T) {
+	_, reg := internal.NewFastRegistryWithMocks(t)
+	ctx := contextx.WithConfigValue(context.Background(), config.ViperKeyCourierLocaleTrait, "preferences.language")
+
+	for _, tc := range []struct {
+		name           string
+		ctx            context.Context
+		traits         json.RawMessage
+		acceptLanguage string
+		expected       string
+	}{
+		{name: "uses the trait", ctx: ctx, traits: json.RawMessage(`{"preferences":{"language":"pt-br"}}`), acceptLanguage: "de", expected: "pt-BR"},
+		{name: "uses the accept language without trait", ctx: ctx, traits: json.RawMessage(`{}`), acceptLanguage: "fr-CH, fr;q=0.9, en;q=0.8", expected: "fr-CH"},
+		{name: "ignores invalid traits", ctx: ctx, traits: json.RawMessage(`{"preferences":{"language":"../../etc"}}`), acceptLanguage: "de", expected: "de"},
+		{name: "uses the accept language without identity", ctx: ctx, acceptLanguage: "de", expected: "de"},
+		{name: "ignores the trait if not configured", ctx: context.Background(), traits: json.RawMessage(`{"preferences":{"language":"pt-BR"}}`), acceptLanguage: "de", expected: "de"},
+		{name: "uses the default locale", ctx: ctx, traits: json.RawMessage(`{}`), expected: ""},
+		{name: "ignores wildcards", ctx: ctx, acceptLanguage: "*", expected: ""},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			ctx := tc.ctx
+			if tc.acceptLanguage != "" {
+				ctx = x.WithAcceptLanguage(ctx, tc.acceptLanguage)
+			}
+			assert.Equal(t, tc.expected, template.Locale(ctx, reg, tc.traits))
+		})
+	}
+}
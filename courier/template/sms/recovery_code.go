@@ -25,6 +25,8 @@ type (
 		RequestURLDomain string                 `json:"request_url_domain"`
 		TransientPayload map[string]interface{} `json:"transient_payload"`
 		ExpiresInMinutes int                    `json:"expires_in_minutes"`
+		Locale           string                 `json:"locale,omitempty"`
+		Branding         template.Branding      `json:"branding,omitzero"`
 	}
 )
 
@@ -39,8 +41,9 @@ func (t *RecoveryCodeValid) SMSBody(ctx context.Context) (string, error) {
 		os.DirFS(t.deps.CourierConfig().CourierTemplatesRoot(ctx)),
 		"recovery_code/valid/sms.body.gotmpl",
 		"recovery_code/valid/sms.body*",
+		t.model.Locale,
 		t.model,
-		t.deps.CourierConfig().CourierSMSTemplatesRecoveryCodeValid(ctx).Body.PlainText,
+		t.deps.CourierConfig().CourierSMSTemplatesRecoveryCodeValid(ctx).ForLocale(t.model.Locale).Body.PlainText,
 	)
 }
 
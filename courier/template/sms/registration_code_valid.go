@@ -23,6 +23,8 @@ type (
 		RequestURL       string                 `json:"request_url"`
 		TransientPayload map[string]interface{} `json:"transient_payload"`
 		ExpiresInMinutes int                    `json:"expires_in_minutes"`
+		Locale           string                 `json:"locale,omitempty"`
+		Branding         template.Branding      `json:"branding,omitzero"`
 	}
 )
 
@@ -41,8 +43,9 @@ func (t *RegistrationCodeValid) SMSBody(ctx context.Context) (string, error) {
 		os.DirFS(t.deps.CourierConfig().CourierTemplatesRoot(ctx)),
 		"registration_code/valid/sms.body.gotmpl",
 		"registration_code/valid/sms.body*",
+		t.model.Locale,
 		t.model,
-		t.deps.CourierConfig().CourierSMSTemplatesRegistrationCodeValid(ctx).Body.PlainText,
+		t.deps.CourierConfig().CourierSMSTemplatesRegistrationCodeValid(ctx).ForLocale(t.model.Locale).Body.PlainText,
 	)
 }
 
@@ -24,6 +24,8 @@ type (
 		RequestURL       string                 `json:"request_url"`
 		TransientPayload map[string]interface{} `json:"transient_payload"`
 		ExpiresInMinutes int                    `json:"expires_in_minutes"`
+		Locale           string                 `json:"locale,omitempty"`
+		Branding         template.Branding      `json:"branding,omitzero"`
 	}
 )
 
@@ -42,8 +44,9 @@ func (t *VerificationCodeValid) SMSBody(ctx context.Context) (string, error) {
 		os.DirFS(t.deps.CourierConfig().CourierTemplatesRoot(ctx)),
 		"verification_code/valid/sms.body.gotmpl",
 		"verification_code/valid/sms.body*",
+		t.model.Locale,
 		t.model,
-		t.deps.CourierConfig().CourierSMSTemplatesVerificationCodeValid(ctx).Body.PlainText,
+		t.deps.CourierConfig().CourierSMSTemplatesVerificationCodeValid(ctx).ForLocale(t.model.Locale).Body.PlainText,
 	)
 }
 
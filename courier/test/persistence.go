@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
 
+	"github.com/ory/herodot"
 	"github.com/ory/kratos/courier"
 	"github.com/ory/kratos/x"
 	"github.com/ory/pop/v6"
@@ -349,5 +351,168 @@ func TestPersister(ctx context.Context, newNetworkUnlessExisting NetworkWrapper,
 				require.ErrorIs(t, err, sqlcon.ErrNoRows)
 			})
 		})
+
+		newMessage := func(t *testing.T) courier.Message {
+			var m courier.Message
+			require.NoError(t, faker.FakeData(&m))
+			require.NoError(t, p.AddMessage(ctx, &m))
+			return m
+		}
+
+		pulled := func(t *testing.T, id uuid.UUID) bool {
+			ms, err := p.NextMessages(ctx, 255)
+			if errors.Is(err, courier.ErrQueueEmpty) {
+				return false
+			}
+			require.NoError(t, err)
+			return slices.ContainsFunc(ms, func(m courier.Message) bool { return m.ID == id })
+		}
+
+		t.Run("case=ScheduleMessageRetry", func(t *testing.T) {
+			m := newMessage(t)
+			require.NoError(t, p.SetMessageStatus(ctx, m.ID, courier.MessageStatusProcessing))
+
+			require.NoError(t, p.ScheduleMessageRetry(ctx, m.ID, time.Now().Add(time.Hour)))
+			actual, err := p.FetchMessage(ctx, m.ID)
+			require.NoError(t, err)
+			assert.Equal(t, courier.MessageStatusQueued, actual.Status)
+			assert.False(t, time.Time(actual.NextAttemptAt).IsZero())
+			assert.False(t, pulled(t, m.ID), "messages are not pulled before their next attempt")
+
+			require.NoError(t, p.SetMessageStatus(ctx, m.ID, courier.MessageStatusProcessing))
+			require.NoError(t, p.ScheduleMessageRetry(ctx, m.ID, time.Now().Add(-time.Minute)))
+			assert.True(t, pulled(t, m.ID))
+
+			t.Run("does not requeue abandoned messages", func(t *testing.T) {
+				require.NoError(t, p.SetMessageStatus(ctx, m.ID, courier.MessageStatusAbandoned))
+				require.NoError(t, p.ScheduleMessageRetry(ctx, m.ID, time.Now()))
+
+				actual, err := p.FetchMessage(ctx, m.ID)
+				require.NoError(t, err)
+				assert.Equal(t, courier.MessageStatusAbandoned, actual.Status)
+			})
+		})
+
+		t.Run("case=CancelMessage", func(t *testing.T) {
+			m := newMessage(t)
+
+			actual, err := p.CancelMessage(ctx, m.ID)
+			require.NoError(t, err)
+			assert.Equal(t, courier.MessageStatusCancelled, actual.Status)
+			assert.False(t, pulled(t, m.ID), "cancelled messages are not pulled")
+
+			_, err = p.CancelMessage(ctx, m.ID)
+			require.ErrorIs(t, err, herodot.ErrConflict)
+
+			t.Run("can not cancel on another network", func(t *testing.T) {
+				_, p := newNetwork(t, ctx)
+
+				_, err := p.CancelMessage(ctx, newMessage(t).ID)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+		})
+
+		t.Run("case=RequeueMessage", func(t *testing.T) {
+			m := newMessage(t)
+			require.NoError(t, p.IncrementMessageSendCount(ctx, m.ID))
+			require.NoError(t, p.RecordDispatch(ctx, m.ID, courier.CourierMessageDispatchStatusFailed, errors.New("testerror")))
+			require.NoError(t, p.SetMessageStatus(ctx, m.ID, courier.MessageStatusAbandoned))
+
+			_, err := p.RequeueMessage(ctx, m.ID, courier.MessageStatusSent)
+			require.ErrorIs(t, err, herodot.ErrConflict)
+
+			actual, err := p.RequeueMessage(ctx, m.ID, courier.MessageStatusAbandoned, courier.MessageStatusCancelled)
+			require.NoError(t, err)
+			assert.Equal(t, courier.MessageStatusQueued, actual.Status)
+			assert.Zero(t, actual.SendCount)
+
+			actual, err = p.FetchMessage(ctx, m.ID)
+			require.NoError(t, err)
+			assert.Equal(t, courier.MessageStatusQueued, actual.Status)
+			assert.Zero(t, actual.SendCount)
+			assert.Len(t, actual.Dispatches, 1, "dispatches are kept")
+
+			t.Run("can not requeue on another network", func(t *testing.T) {
+				_, p := newNetwork(t, ctx)
+
+				_, err := p.RequeueMessage(ctx, m.ID, courier.MessageStatusQueued)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+		})
+
+		t.Run("case=SetMessageDeliveryStatus", func(t *testing.T) {
+			m := newMessage(t)
+
+			require.ErrorIs(t, p.SetMessageDeliveryStatus(ctx, m.ID, courier.MessageStatusDelivered), sqlcon.ErrNoRows, "queued messages were not sent")
+
+			require.NoError(t, p.SetMessageStatus(ctx, m.ID, courier.MessageStatusSent))
+			require.NoError(t, p.SetMessageDeliveryStatus(ctx, m.ID, courier.MessageStatusDelivered))
+			require.NoError(t, p.SetMessageDeliveryStatus(ctx, m.ID, courier.MessageStatusBounced))
+			require.ErrorIs(t, p.SetMessageDeliveryStatus(ctx, m.ID, courier.MessageStatusDelivered), sqlcon.ErrNoRows, "bounced messages stay bounced")
+
+			actual, err := p.FetchMessage(ctx, m.ID)
+			require.NoError(t, err)
+			assert.Equal(t, courier.MessageStatusBounced, actual.Status)
+
+			t.Run("can not set on another network", func(t *testing.T) {
+				_, p := newNetwork(t, ctx)
+
+				require.ErrorIs(t, p.SetMessageDeliveryStatus(ctx, m.ID, courier.MessageStatusBounced), sqlcon.ErrNoRows)
+			})
+		})
+
+		t.Run("case=suppressions", func(t *testing.T) {
+			recipient := strings.ToLower(x.NewUUID().String()) + "@ory.sh"
+
+			suppressed, err := p.IsSuppressed(ctx, recipient)
+			require.NoError(t, err)
+			assert.False(t, suppressed)
+
+			first := courier.Suppression{ID: x.NewUUID(), Recipient: " " + strings.ToUpper(recipient), Reason: courier.SuppressionReasonBounced}
+			require.NoError(t, p.AddSuppression(ctx, &first))
+			assert.Equal(t, recipient, first.Recipient)
+			assert.Equal(t, nid, first.NID)
+
+			suppressed, err = p.IsSuppressed(ctx, strings.ToUpper(recipient))
+			require.NoError(t, err)
+			assert.True(t, suppressed)
+
+			t.Run("keeps the existing suppression", func(t *testing.T) {
+				second := courier.Suppression{ID: x.NewUUID(), Recipient: recipient, Reason: courier.SuppressionReasonManual}
+				require.NoError(t, p.AddSuppression(ctx, &second))
+				assert.Equal(t, first.ID, second.ID)
+				assert.Equal(t, courier.SuppressionReasonBounced, second.Reason)
+			})
+
+			t.Run("lists suppressions", func(t *testing.T) {
+				other := courier.Suppression{ID: x.NewUUID(), Recipient: "+12065550102", Reason: courier.SuppressionReasonManual}
+				require.NoError(t, p.AddSuppression(ctx, &other))
+
+				all, _, err := p.ListSuppressions(ctx, courier.ListCourierSuppressionsParameters{}, []keysetpagination.Option{keysetpagination.WithSize(100)})
+				require.NoError(t, err)
+				assert.GreaterOrEqual(t, len(all), 2)
+
+				filtered, _, err := p.ListSuppressions(ctx, courier.ListCourierSuppressionsParameters{Recipient: recipient}, nil)
+				require.NoError(t, err)
+				require.Len(t, filtered, 1)
+				assert.Equal(t, first.ID, filtered[0].ID)
+			})
+
+			t.Run("is scoped to the network", func(t *testing.T) {
+				_, p := newNetwork(t, ctx)
+
+				suppressed, err := p.IsSuppressed(ctx, recipient)
+				require.NoError(t, err)
+				assert.False(t, suppressed)
+
+				require.ErrorIs(t, p.DeleteSuppression(ctx, first.ID), sqlcon.ErrNoRows)
+			})
+
+			require.NoError(t, p.DeleteSuppression(ctx, first.ID))
+			suppressed, err = p.IsSuppressed(ctx, recipient)
+			require.NoError(t, err)
+			assert.False(t, suppressed)
+			require.ErrorIs(t, p.DeleteSuppression(ctx, first.ID), sqlcon.ErrNoRows)
+		})
 	}
 }
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
@@ -27,6 +28,7 @@ import (
 	"github.com/rs/cors"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/publicsuffix"
+	"golang.org/x/text/language"
 
 	"github.com/ory/kratos/x"
 
@@ -59,6 +61,8 @@ const (
 	ViperKeyCourierSMTPClientCertPath                        = "courier.smtp.client_cert_path"
 	ViperKeyCourierSMTPClientKeyPath                         = "courier.smtp.client_key_path"
 	ViperKeyCourierTemplatesPath                             = "courier.template_override_path"
+	ViperKeyCourierLocaleTrait                               = "courier.locale.trait"
+	ViperKeyCourierBranding                                  = "courier.branding"
 	ViperKeyCourierTemplatesRecoveryInvalidEmail             = "courier.templates.recovery.invalid.email"
 	ViperKeyCourierTemplatesRecoveryValidEmail               = "courier.templates.recovery.valid.email"
 	ViperKeyCourierTemplatesRecoveryCodeInvalidEmail         = "courier.templates.recovery_code.invalid.email"
@@ -73,6 +77,7 @@ const (
 	ViperKeyCourierTemplatesRegistrationCodeValidSMS         = "courier.templates.registration_code.valid.sms"
 	ViperKeyCourierDeliveryStrategy                          = "courier.delivery_strategy"
 	ViperKeyCourierHTTPRequestConfig                         = "courier.http.request_config"
+	ViperKeyCourierAPIConfig                                 = "courier.api"
 	ViperKeyCourierTemplatesLoginCodeValidEmail              = "courier.templates.login_code.valid.email"
 	ViperKeyCourierTemplatesRegistrationCodeValidEmail       = "courier.templates.registration_code.valid.email"
 	ViperKeyCourierSMTP                                      = "courier.smtp"
@@ -84,6 +89,11 @@ const (
 	ViperKeyCourierWorkerPullCount                           = "courier.worker.pull_count"
 	ViperKeyCourierWorkerPullWait                            = "courier.worker.pull_wait"
 	ViperKeyCourierChannels                                  = "courier.channels"
+	ViperKeyCourierRetry                                     = "courier.retry"
+	ViperKeyOutboxSinks                                      = "outbox.sinks"
+	ViperKeyOutboxRetry                                      = "outbox.retry"
+	ViperKeyOutboxWorkerPullCount                            = "outbox.worker.pull_count"
+	ViperKeyOutboxWorkerPullWait                             = "outbox.worker.pull_wait"
 	ViperKeySecretsDefault                                   = "secrets.default"
 	ViperKeySecretsCookie                                    = "secrets.cookie"
 	ViperKeySecretsCipher                                    = "secrets.cipher"
@@ -91,6 +101,7 @@ const (
 	ViperKeyPublicBaseURL                                    = "serve.public.base_url"
 	ViperKeyAdminBaseURL                                     = "serve.admin.base_url"
 	ViperKeySessionLifespan                                  = "session.lifespan"
+	ViperKeySessionImpersonationLifespan                     = "session.impersonation.lifespan"
 	ViperKeySessionSameSite                                  = "session.cookie.same_site"
 	ViperKeySessionSecure                                    = "session.cookie.secure"
 	ViperKeySessionDomain                                    = "session.cookie.domain"
@@ -115,6 +126,7 @@ const (
 	ViperKeySelfServiceStrategyConfig                        = "selfservice.methods"
 	ViperKeySelfServiceBrowserDefaultReturnTo                = "selfservice." + DefaultBrowserReturnURL
 	ViperKeyURLsAllowedReturnToDomains                       = "selfservice.allowed_return_urls"
+	ViperKeySelfServiceTranslations                          = "selfservice.translations"
 	ViperKeySelfServiceRegistrationEnabled                   = "selfservice.flows.registration.enabled"
 	ViperKeySelfServiceRegistrationLoginHints                = "selfservice.flows.registration.login_hints"
 	ViperKeySelfServiceRegistrationEnableLegacyOneStep       = "selfservice.flows.registration.enable_legacy_one_step"
@@ -125,7 +137,11 @@ const (
 	ViperKeySelfServiceRegistrationBeforeHooks               = "selfservice.flows.registration.before.hooks"
 	ViperKeySelfServiceLoginUI                               = "selfservice.flows.login.ui_url"
 	ViperKeySelfServiceLoginFlowStyle                        = "selfservice.flows.login.style"
+	ViperKeySelfServiceLoginHomeRealms                       = "selfservice.flows.login.home_realm_discovery.realms"
 	ViperKeySecurityAccountEnumerationMitigate               = "security.account_enumeration.mitigate"
+	ViperKeySecurityRateLimit                                = "security.rate_limit"
+	ViperKeySecurityAccountLockout                           = "security.account_lockout"
+	ViperKeySecurityCaptcha                                  = "security.captcha"
 	ViperKeySelfServiceLoginRequestLifespan                  = "selfservice.flows.login.lifespan"
 	ViperKeySelfServiceLoginAfter                            = "selfservice.flows.login.after"
 	ViperKeySelfServiceLoginBeforeHooks                      = "selfservice.flows.login.before.hooks"
@@ -155,6 +171,7 @@ const (
 	ViperKeySelfServiceVerificationNotifyUnknownRecipients   = "selfservice.flows.verification.notify_unknown_recipients"
 	ViperKeyDefaultIdentitySchemaID                          = "identity.default_schema_id"
 	ViperKeyIdentitySchemas                                  = "identity.schemas"
+	ViperKeyIdentitySCIM                                     = "identity.scim"
 	ViperKeyHasherAlgorithm                                  = "hashers.algorithm"
 	ViperKeyHasherArgon2ConfigMemory                         = "hashers.argon2.memory"
 	ViperKeyHasherArgon2ConfigIterations                     = "hashers.argon2.iterations"
@@ -166,6 +183,13 @@ const (
 	ViperKeyHasherArgon2ConfigDedicatedMemory                = "hashers.argon2.dedicated_memory"
 	ViperKeyHasherBcryptCost                                 = "hashers.bcrypt.cost"
 	ViperKeyCipherAlgorithm                                  = "ciphers.algorithm"
+	ViperKeyCipherEnvelopeKMS                                = "ciphers.envelope.kms"
+	ViperKeyCipherEnvelopeLegacyAlgorithm                    = "ciphers.envelope.legacy_algorithm"
+	ViperKeyCipherEnvelopeFilePath                           = "ciphers.envelope.file.path"
+	ViperKeyCipherEnvelopeVaultAddress                       = "ciphers.envelope.vault.address"
+	ViperKeyCipherEnvelopeVaultToken                         = "ciphers.envelope.vault.token"
+	ViperKeyCipherEnvelopeVaultMount                         = "ciphers.envelope.vault.mount"
+	ViperKeyCipherEnvelopeVaultKey                           = "ciphers.envelope.vault.key"
 	ViperKeyDatabaseCleanupSleepTables                       = "database.cleanup.sleep.tables"
 	ViperKeyDatabaseCleanupBatchSize                         = "database.cleanup.batch_size"
 	ViperKeyLinkLifespan                                     = "selfservice.methods.link.config.lifespan"
@@ -174,10 +198,17 @@ const (
 	ViperKeyCodeConfigMissingCredentialFallbackEnabled       = "selfservice.methods.code.config.missing_credential_fallback_enabled"
 	ViperKeyPasswordHaveIBeenPwnedHost                       = "selfservice.methods.password.config.haveibeenpwned_host"
 	ViperKeyPasswordHaveIBeenPwnedEnabled                    = "selfservice.methods.password.config.haveibeenpwned_enabled"
+	ViperKeyPasswordHaveIBeenPwnedDataset                    = "selfservice.methods.password.config.haveibeenpwned_dataset"
 	ViperKeyPasswordMaxBreaches                              = "selfservice.methods.password.config.max_breaches"
 	ViperKeyPasswordMinLength                                = "selfservice.methods.password.config.min_password_length"
 	ViperKeyPasswordIdentifierSimilarityCheckEnabled         = "selfservice.methods.password.config.identifier_similarity_check_enabled"
 	ViperKeyIgnoreNetworkErrors                              = "selfservice.methods.password.config.ignore_network_errors"
+	ViperKeyPasswordMaxLength                                = "selfservice.methods.password.config.max_password_length"
+	ViperKeyPasswordRequiredCharacterClasses                 = "selfservice.methods.password.config.required_character_classes"
+	ViperKeyPasswordMinCharacterClasses                      = "selfservice.methods.password.config.min_character_classes"
+	ViperKeyPasswordBannedWordsURLs                          = "selfservice.methods.password.config.banned_words"
+	ViperKeyPasswordMinStrengthScore                         = "selfservice.methods.password.config.min_strength_score"
+	ViperKeyPasswordHistorySize                              = "selfservice.methods.password.config.history_size"
 	ViperKeyPasswordRegistrationProfileGroup                 = "selfservice.methods.password.config.password_profile_registration_node_group"
 	ViperKeyTOTPIssuer                                       = "selfservice.methods.totp.config.issuer"
 	ViperKeyOIDCBaseRedirectURL                              = "selfservice.methods.oidc.config.base_redirect_uri"
@@ -244,18 +275,111 @@ type (
 		PasswordlessEnabled bool `json:"passwordless_enabled"`
 		MFAEnabled          bool `json:"mfa_enabled"`
 	}
+	RateLimit struct {
+		Enabled bool          `json:"enabled"`
+		Store   string        `json:"store"`
+		Window  time.Duration `json:"window"`
+
+		// MaxAttemptsPerIdentifier, MaxAttemptsPerIP and MaxAttemptsPerIdentity
+		// are the number of failed attempts allowed within the window. Zero
+		// disables the respective limit.
+		MaxAttemptsPerIdentifier int `json:"max_attempts_per_identifier"`
+		MaxAttemptsPerIP         int `json:"max_attempts_per_ip"`
+		MaxAttemptsPerIdentity   int `json:"max_attempts_per_identity"`
+
+		// TrustedProxies are the IP addresses and CIDR ranges of the reverse
+		// proxies whose ClientIPHeader is used to determine the client IP
+		// address. Without trusted proxies, the remote address of the
+		// connection is used.
+		TrustedProxies []string `json:"trusted_proxies"`
+		ClientIPHeader string   `json:"client_ip_header"`
+	}
+	AccountLockout struct {
+		Enabled bool `json:"enabled"`
+
+		// MaxFailedAttempts is the number of consecutive failed
+		// authentications after which the identity is locked.
+		MaxFailedAttempts int `json:"max_failed_attempts"`
+
+		// Duration is the duration of the first lockout. Every further lockout
+		// doubles the duration, up to MaxDuration.
+		Duration    time.Duration `json:"duration"`
+		MaxDuration time.Duration `json:"max_duration"`
+	}
+	Captcha struct {
+		Enabled bool `json:"enabled"`
+
+		// Provider is one of `turnstile`, `hcaptcha`, or `recaptcha`.
+		Provider  string `json:"provider"`
+		SiteKey   string `json:"site_key"`
+		SecretKey string `json:"secret_key"`
+
+		// VerifyURL and ScriptURL override the provider's defaults if set.
+		VerifyURL string `json:"verify_url"`
+		ScriptURL string `json:"script_url"`
+
+		// MinScore is the minimum score for providers which return one.
+		// Zero ignores the score.
+		MinScore float64 `json:"min_score"`
+	}
+	CaptchaFlow struct {
+		Enabled bool `json:"enabled"`
+
+		// AfterFailedAttempts is the number of failed attempts of the client
+		// IP address after which a captcha is required. Zero always requires
+		// a captcha.
+		AfterFailedAttempts int `json:"after_failed_attempts"`
+	}
+	SCIM struct {
+		Enabled bool `json:"enabled"`
+
+		// MapperURL points to the Jsonnet code which maps a SCIM user
+		// resource to identity traits and metadata.
+		MapperURL string `json:"mapper_url"`
+
+		// SchemaID is the identity schema of provisioned identities.
+		SchemaID string `json:"schema_id"`
+	}
+	TranslationCatalog struct {
+		// Language is the BCP 47 language tag of the catalog.
+		Language string `json:"language"`
+
+		// URL is the location of the catalog.
+		URL string `json:"url"`
+
+		// Format is either "json" or "po". If empty, it is derived from the
+		// file extension of the URL.
+		Format string `json:"format"`
+	}
+	OutboxRetry struct {
+		// MaxAttempts is the number of delivery attempts after which an
+		// event is abandoned.
+		MaxAttempts int `json:"max_attempts"`
+
+		// InitialInterval is the wait before the first retry. Every further
+		// retry doubles the wait, up to MaxInterval.
+		InitialInterval time.Duration `json:"initial_interval"`
+		MaxInterval     time.Duration `json:"max_interval"`
+	}
 	Schema struct {
 		ID                    string `json:"id" koanf:"id"`
 		URL                   string `json:"url" koanf:"url"`
 		SelfserviceSelectable bool   `json:"selfservice_selectable" koanf:"selfservice_selectable"`
 	}
 	PasswordPolicy struct {
-		HaveIBeenPwnedHost               string `json:"haveibeenpwned_host"`
-		HaveIBeenPwnedEnabled            bool   `json:"haveibeenpwned_enabled"`
-		MaxBreaches                      uint   `json:"max_breaches"`
-		IgnoreNetworkErrors              bool   `json:"ignore_network_errors"`
-		MinPasswordLength                uint   `json:"min_password_length"`
-		IdentifierSimilarityCheckEnabled bool   `json:"identifier_similarity_check_enabled"`
+		HaveIBeenPwnedHost               string   `json:"haveibeenpwned_host"`
+		HaveIBeenPwnedEnabled            bool     `json:"haveibeenpwned_enabled"`
+		HaveIBeenPwnedDataset            string   `json:"haveibeenpwned_dataset"`
+		MaxBreaches                      uint     `json:"max_breaches"`
+		IgnoreNetworkErrors              bool     `json:"ignore_network_errors"`
+		MinPasswordLength                uint     `json:"min_password_length"`
+		IdentifierSimilarityCheckEnabled bool     `json:"identifier_similarity_check_enabled"`
+		MaxPasswordLength                uint     `json:"max_password_length"`
+		RequiredCharacterClasses         []string `json:"required_character_classes"`
+		MinCharacterClasses              uint     `json:"min_character_classes"`
+		BannedWordsURLs                  []string `json:"banned_words"`
+		MinStrengthScore                 uint     `json:"min_strength_score"`
+		HistorySize                      uint     `json:"history_size"`
 	}
 	Schemas                  []Schema
 	CourierEmailBodyTemplate struct {
@@ -263,20 +387,90 @@ type (
 		HTML      string `json:"html"`
 	}
 	CourierEmailTemplate struct {
-		Body    *CourierEmailBodyTemplate `json:"body"`
-		Subject string                    `json:"subject"`
+		Body    *CourierEmailBodyTemplate        `json:"body"`
+		Subject string                           `json:"subject"`
+		Locales map[string]*CourierEmailTemplate `json:"locales"`
+	}
+	CourierBranding struct {
+		ProductName  string                      `json:"product_name,omitempty"`
+		LogoURL      string                      `json:"logo_url,omitempty"`
+		SupportEmail string                      `json:"support_email,omitempty"`
+		Colors       map[string]string           `json:"colors,omitempty"`
+		Locales      map[string]*CourierBranding `json:"locales,omitempty"`
+	}
+	courierBrandings struct {
+		CourierBranding
+		Schemas       map[string]*CourierBranding `json:"schemas"`
+		Organizations map[string]*CourierBranding `json:"organizations"`
 	}
 	CourierSMSTemplate struct {
-		Body *CourierSMSTemplateBody `json:"body"`
+		Body    *CourierSMSTemplateBody        `json:"body"`
+		Locales map[string]*CourierSMSTemplate `json:"locales"`
 	}
 	CourierSMSTemplateBody struct {
 		PlainText string `json:"plaintext"`
 	}
 	CourierChannel struct {
-		ID            string         `json:"id" koanf:"id"`
-		Type          string         `json:"type" koanf:"type"`
-		SMTPConfig    *SMTPConfig    `json:"smtp_config" koanf:"smtp_config"`
-		RequestConfig request.Config `json:"request_config" koanf:"request_config"`
+		ID            string            `json:"id" koanf:"id"`
+		Type          string            `json:"type" koanf:"type"`
+		SMTPConfig    *SMTPConfig       `json:"smtp_config" koanf:"smtp_config"`
+		RequestConfig request.Config    `json:"request_config" koanf:"request_config"`
+		APIConfig     *CourierAPIConfig `json:"api_config" koanf:"api_config"`
+		Retry         *CourierRetry     `json:"retry" koanf:"retry"`
+	}
+	// CourierAPIConfig configures the built-in channel types which deliver
+	// messages through the HTTP API of an email or SMS provider.
+	CourierAPIConfig struct {
+		// URL overrides the API endpoint of the provider, e.g. for compatible
+		// services or regional endpoints.
+		URL string `json:"url" koanf:"url"`
+
+		// Key is the API key, the access key ID (SES) or the account SID
+		// (Twilio).
+		Key string `json:"key" koanf:"key"`
+
+		// Secret is the secret access key (SES) or the auth token (Twilio).
+		Secret string `json:"secret" koanf:"secret"`
+
+		Region      string `json:"region" koanf:"region"`
+		Domain      string `json:"domain" koanf:"domain"`
+		FromAddress string `json:"from_address" koanf:"from_address"`
+		FromName    string `json:"from_name" koanf:"from_name"`
+
+		// CallbackToken authenticates the delivery status callbacks of the
+		// provider. Callbacks are rejected if it is not set.
+		CallbackToken string `json:"callback_token" koanf:"callback_token"`
+
+		// CallbackSigningKey is the verification key of the signed event
+		// webhook (SendGrid) or the HTTP webhook signing key (Mailgun).
+		CallbackSigningKey string `json:"callback_signing_key" koanf:"callback_signing_key"`
+	}
+	CourierRetry struct {
+		// MaxAttempts is the number of delivery attempts after which a
+		// message is abandoned.
+		MaxAttempts int `json:"max_attempts" koanf:"max_attempts"`
+
+		// InitialInterval is the wait before the first retry. Every further
+		// retry doubles the wait, up to MaxInterval.
+		InitialInterval time.Duration `json:"initial_interval" koanf:"initial_interval"`
+		MaxInterval     time.Duration `json:"max_interval" koanf:"max_interval"`
+	}
+	CipherEnvelope struct {
+		// KMS is the key management service which wraps the data keys. One of
+		// "file" and "vault".
+		KMS string
+
+		// LegacyAlgorithm is the algorithm of the ciphertexts which were
+		// stored before the envelope cipher was enabled. They are decrypted
+		// with secrets.cipher until they were rotated.
+		LegacyAlgorithm string
+
+		FilePath string
+
+		VaultAddress string
+		VaultToken   string
+		VaultMount   string
+		VaultKey     string
 	}
 	SMTPConfig struct {
 		ConnectionURI  string            `json:"connection_uri" koanf:"connection_uri"`
@@ -291,6 +485,18 @@ type (
 		Enabled bool           `json:"enabled" koanf:"enabled"`
 		Config  request.Config `json:"config" koanf:"config"`
 	}
+	// HomeRealm routes users whose identifier is an email address of one of
+	// the domains to an OpenID Connect provider, or to the providers of an
+	// organization, during identifier-first login.
+	HomeRealm struct {
+		Domains      []string `json:"domains" koanf:"domains"`
+		Provider     string   `json:"provider" koanf:"provider"`
+		Organization string   `json:"organization" koanf:"organization"`
+
+		// ForbidPasswordLogin rejects password logins of identifiers of the
+		// domains.
+		ForbidPasswordLogin bool `json:"forbid_password_login" koanf:"forbid_password_login"`
+	}
 	Config struct {
 		l                  *logrusx.Logger
 		p                  *configx.Provider
@@ -303,6 +509,8 @@ type (
 	}
 	CourierConfigs interface {
 		CourierTemplatesRoot(ctx context.Context) string
+		CourierLocaleTrait(ctx context.Context) string
+		CourierBranding(ctx context.Context, schemaID, organizationID, locale string) CourierBranding
 		CourierTemplatesVerificationInvalid(ctx context.Context) *CourierEmailTemplate
 		CourierTemplatesVerificationValid(ctx context.Context) *CourierEmailTemplate
 		CourierTemplatesRecoveryInvalid(ctx context.Context) *CourierEmailTemplate
@@ -321,6 +529,8 @@ type (
 		CourierWorkerPullCount(ctx context.Context) int
 		CourierWorkerPullWait(ctx context.Context) time.Duration
 		CourierChannels(context.Context) ([]*CourierChannel, error)
+		CourierRetry(ctx context.Context, channelID string) (*CourierRetry, error)
+		SelfPublicURL(ctx context.Context) *url.URL
 	}
 )
 
@@ -577,6 +787,15 @@ func (p *Config) DefaultIdentityTraitsSchemaURL(ctx context.Context) (*url.URL,
 	return p.ParseURI(found.URL)
 }
 
+func (p *Config) IdentitySCIM(ctx context.Context) *SCIM {
+	pp := p.GetProvider(ctx)
+	return &SCIM{
+		Enabled:   pp.BoolF(ViperKeyIdentitySCIM+".enabled", false),
+		MapperURL: pp.String(ViperKeyIdentitySCIM + ".mapper_url"),
+		SchemaID:  pp.StringF(ViperKeyIdentitySCIM+".schema_id", pp.String(ViperKeyDefaultIdentitySchemaID)),
+	}
+}
+
 func (p *Config) DefaultIdentityTraitsSchemaID(ctx context.Context) string {
 	return p.GetProvider(ctx).String(ViperKeyDefaultIdentitySchemaID)
 }
@@ -1001,6 +1220,10 @@ func (p *Config) SessionLifespan(ctx context.Context) time.Duration {
 	return p.GetProvider(ctx).DurationF(ViperKeySessionLifespan, time.Hour*24)
 }
 
+func (p *Config) SessionImpersonationLifespan(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeySessionImpersonationLifespan, 15*time.Minute)
+}
+
 func (p *Config) SessionPersistentCookie(ctx context.Context) bool {
 	return p.GetProvider(ctx).Bool(ViperKeySessionPersistentCookie)
 }
@@ -1074,6 +1297,120 @@ func (p *Config) CourierTemplatesRoot(ctx context.Context) string {
 	return p.GetProvider(ctx).StringF(ViperKeyCourierTemplatesPath, "courier/builtin/templates")
 }
 
+// CourierLocaleTrait returns the path of the identity trait holding the preferred
+// language of the identity, if any.
+func (p *Config) CourierLocaleTrait(ctx context.Context) string {
+	return p.GetProvider(ctx).String(ViperKeyCourierLocaleTrait)
+}
+
+// ForLocale returns the template URLs configured for the locale. The base language
+// is used if the exact locale is not configured, and every URL not configured for
+// the locale falls back to the default one.
+func (t *CourierEmailTemplate) ForLocale(locale string) *CourierEmailTemplate {
+	l, ok := lookupLocale(t.Locales, locale)
+	if !ok {
+		return t
+	}
+
+	localized := &CourierEmailTemplate{Body: &CourierEmailBodyTemplate{}, Subject: stringsx.Coalesce(l.Subject, t.Subject)}
+	if t.Body != nil {
+		*localized.Body = *t.Body
+	}
+	if l.Body != nil {
+		localized.Body.HTML = stringsx.Coalesce(l.Body.HTML, localized.Body.HTML)
+		localized.Body.PlainText = stringsx.Coalesce(l.Body.PlainText, localized.Body.PlainText)
+	}
+	return localized
+}
+
+// ForLocale returns the template URLs configured for the locale. The base language
+// is used if the exact locale is not configured, and every URL not configured for
+// the locale falls back to the default one.
+func (t *CourierSMSTemplate) ForLocale(locale string) *CourierSMSTemplate {
+	l, ok := lookupLocale(t.Locales, locale)
+	if !ok {
+		return t
+	}
+
+	localized := &CourierSMSTemplate{Body: &CourierSMSTemplateBody{}}
+	if t.Body != nil {
+		*localized.Body = *t.Body
+	}
+	if l.Body != nil {
+		localized.Body.PlainText = stringsx.Coalesce(l.Body.PlainText, localized.Body.PlainText)
+	}
+	return localized
+}
+
+// CourierBranding returns the branding variables for messages to identities of the identity schema and organization
+// preferring the locale. The default branding is overridden by the branding of the identity schema, which is
+// overridden by the branding of the organization. Each of them is localized before.
+func (p *Config) CourierBranding(ctx context.Context, schemaID, organizationID, locale string) CourierBranding {
+	if !p.GetProvider(ctx).Exists(ViperKeyCourierBranding) {
+		return CourierBranding{}
+	}
+
+	config, err := json.Marshal(p.GetProvider(ctx).Get(ViperKeyCourierBranding))
+	if err != nil {
+		p.l.WithError(err).Errorf("Unable to decode values from %s.", ViperKeyCourierBranding)
+		return CourierBranding{}
+	}
+	var brandings courierBrandings
+	if err := json.Unmarshal(config, &brandings); err != nil {
+		p.l.WithError(err).Errorf("Unable to encode values from %s.", ViperKeyCourierBranding)
+		return CourierBranding{}
+	}
+
+	branding := brandings.CourierBranding.ForLocale(locale)
+	if b, ok := brandings.Schemas[schemaID]; ok && b != nil && schemaID != "" {
+		branding = branding.merge(b.ForLocale(locale))
+	}
+	if b, ok := brandings.Organizations[organizationID]; ok && b != nil && organizationID != "" {
+		branding = branding.merge(b.ForLocale(locale))
+	}
+	return branding
+}
+
+// ForLocale returns the branding variables configured for the locale. The base language is used if the exact locale
+// is not configured, and every variable not configured for the locale falls back to the default one.
+func (b *CourierBranding) ForLocale(locale string) CourierBranding {
+	branding := CourierBranding{}.merge(*b)
+	if l, ok := lookupLocale(b.Locales, locale); ok {
+		branding = branding.merge(*l)
+	}
+	return branding
+}
+
+func (b CourierBranding) merge(o CourierBranding) CourierBranding {
+	merged := CourierBranding{
+		ProductName:  stringsx.Coalesce(o.ProductName, b.ProductName),
+		LogoURL:      stringsx.Coalesce(o.LogoURL, b.LogoURL),
+		SupportEmail: stringsx.Coalesce(o.SupportEmail, b.SupportEmail),
+	}
+	if len(b.Colors)+len(o.Colors) > 0 {
+		merged.Colors = make(map[string]string, len(b.Colors)+len(o.Colors))
+		maps.Copy(merged.Colors, b.Colors)
+		maps.Copy(merged.Colors, o.Colors)
+	}
+	return merged
+}
+
+func lookupLocale[T any](locales map[string]*T, locale string) (*T, bool) {
+	if locale == "" || len(locales) == 0 {
+		return nil, false
+	}
+	if l, ok := locales[locale]; ok && l != nil {
+		return l, true
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return nil, false
+	}
+	base, _ := tag.Base()
+	l, ok := locales[base.String()]
+	return l, ok && l != nil
+}
+
 func (p *Config) CourierEmailTemplatesHelper(ctx context.Context, key string) *CourierEmailTemplate {
 	courierTemplate := &CourierEmailTemplate{
 		Body: &CourierEmailBodyTemplate{
@@ -1206,19 +1543,91 @@ func (p *Config) CourierChannels(ctx context.Context) (ccs []*CourierChannel, _
 		ID:   "email",
 		Type: p.CourierEmailStrategy(ctx),
 	}
-	if channel.Type == "smtp" {
+	switch channel.Type {
+	case "smtp":
 		if err := p.GetProvider(ctx).Unmarshal(ViperKeyCourierSMTP, &channel.SMTPConfig); err != nil {
 			return nil, errors.WithStack(err)
 		}
-	} else {
+	case "http":
 		if err := p.GetProvider(ctx).Unmarshal(ViperKeyCourierHTTPRequestConfig, &channel.RequestConfig); err != nil {
 			return nil, errors.WithStack(err)
 		}
+	default:
+		if err := p.GetProvider(ctx).Unmarshal(ViperKeyCourierAPIConfig, &channel.APIConfig); err != nil {
+			return nil, errors.WithStack(err)
+		}
 	}
 	ccs = append(ccs, &channel)
 	return ccs, nil
 }
 
+// CourierRetry returns the retry policy of the channel. Fields not set by the
+// channel fall back to `courier.retry`, and the number of attempts to the
+// deprecated `courier.message_retries`.
+func (p *Config) CourierRetry(ctx context.Context, channelID string) (*CourierRetry, error) {
+	pp := p.GetProvider(ctx)
+	retry := &CourierRetry{
+		MaxAttempts:     pp.IntF(ViperKeyCourierRetry+".max_attempts", p.CourierMessageRetries(ctx)+1),
+		InitialInterval: pp.DurationF(ViperKeyCourierRetry+".initial_interval", 0),
+		MaxInterval:     pp.DurationF(ViperKeyCourierRetry+".max_interval", time.Hour),
+	}
+
+	ccs, err := p.CourierChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, channel := range ccs {
+		if channel.ID != channelID || channel.Retry == nil {
+			continue
+		}
+		if channel.Retry.MaxAttempts > 0 {
+			retry.MaxAttempts = channel.Retry.MaxAttempts
+		}
+		if channel.Retry.InitialInterval > 0 {
+			retry.InitialInterval = channel.Retry.InitialInterval
+		}
+		if channel.Retry.MaxInterval > 0 {
+			retry.MaxInterval = channel.Retry.MaxInterval
+		}
+		break
+	}
+
+	return retry, nil
+}
+
+// SelfServiceTranslations returns the message catalogs UI texts are translated with.
+func (p *Config) SelfServiceTranslations(ctx context.Context) (catalogs []TranslationCatalog, _ error) {
+	if err := p.GetProvider(ctx).Unmarshal(ViperKeySelfServiceTranslations, &catalogs); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return catalogs, nil
+}
+
+// OutboxSinks returns the HTTP sinks outbox events are delivered to.
+func (p *Config) OutboxSinks(ctx context.Context) (sinks []request.Config, _ error) {
+	if err := p.GetProvider(ctx).Unmarshal(ViperKeyOutboxSinks, &sinks); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return sinks, nil
+}
+
+func (p *Config) OutboxRetry(ctx context.Context) *OutboxRetry {
+	pp := p.GetProvider(ctx)
+	return &OutboxRetry{
+		MaxAttempts:     pp.IntF(ViperKeyOutboxRetry+".max_attempts", 10),
+		InitialInterval: pp.DurationF(ViperKeyOutboxRetry+".initial_interval", time.Second),
+		MaxInterval:     pp.DurationF(ViperKeyOutboxRetry+".max_interval", time.Hour),
+	}
+}
+
+func (p *Config) OutboxWorkerPullCount(ctx context.Context) int {
+	return p.GetProvider(ctx).IntF(ViperKeyOutboxWorkerPullCount, 10)
+}
+
+func (p *Config) OutboxWorkerPullWait(ctx context.Context) time.Duration {
+	return p.GetProvider(ctx).DurationF(ViperKeyOutboxWorkerPullWait, time.Second)
+}
+
 func splitUrlAndFragment(s string) (string, string) {
 	i := strings.IndexByte(s, '#')
 	if i < 0 {
@@ -1282,6 +1691,10 @@ func (p *Config) IsBackgroundCourierEnabled(ctx context.Context) bool {
 	return p.GetProvider(ctx).Bool("watch-courier")
 }
 
+func (p *Config) IsBackgroundOutboxEnabled(ctx context.Context) bool {
+	return p.GetProvider(ctx).Bool("watch-outbox")
+}
+
 func (p *Config) CourierExposeMetricsPort(ctx context.Context) int {
 	return p.GetProvider(ctx).Int("expose-metrics-port")
 }
@@ -1480,10 +1893,17 @@ func (p *Config) PasswordPolicyConfig(ctx context.Context) *PasswordPolicy {
 	return &PasswordPolicy{
 		HaveIBeenPwnedHost:               p.GetProvider(ctx).StringF(ViperKeyPasswordHaveIBeenPwnedHost, "api.pwnedpasswords.com"),
 		HaveIBeenPwnedEnabled:            p.GetProvider(ctx).BoolF(ViperKeyPasswordHaveIBeenPwnedEnabled, true),
+		HaveIBeenPwnedDataset:            p.GetProvider(ctx).String(ViperKeyPasswordHaveIBeenPwnedDataset),
 		MaxBreaches:                      uint(p.GetProvider(ctx).Int(ViperKeyPasswordMaxBreaches)), // #nosec G115 -- negative values are prevented by the schema validation
 		IgnoreNetworkErrors:              p.GetProvider(ctx).BoolF(ViperKeyIgnoreNetworkErrors, true),
 		MinPasswordLength:                uint(p.GetProvider(ctx).IntF(ViperKeyPasswordMinLength, 8)), // #nosec G115 -- negative values are prevented by the schema validation
 		IdentifierSimilarityCheckEnabled: p.GetProvider(ctx).BoolF(ViperKeyPasswordIdentifierSimilarityCheckEnabled, true),
+		MaxPasswordLength:                uint(p.GetProvider(ctx).Int(ViperKeyPasswordMaxLength)), // #nosec G115 -- negative values are prevented by the schema validation
+		RequiredCharacterClasses:         p.GetProvider(ctx).Strings(ViperKeyPasswordRequiredCharacterClasses),
+		MinCharacterClasses:              uint(p.GetProvider(ctx).Int(ViperKeyPasswordMinCharacterClasses)), // #nosec G115 -- negative values are prevented by the schema validation
+		BannedWordsURLs:                  p.GetProvider(ctx).Strings(ViperKeyPasswordBannedWordsURLs),
+		MinStrengthScore:                 uint(p.GetProvider(ctx).Int(ViperKeyPasswordMinStrengthScore)), // #nosec G115 -- negative values are prevented by the schema validation
+		HistorySize:                      uint(p.GetProvider(ctx).Int(ViperKeyPasswordHistorySize)),      // #nosec G115 -- negative values are prevented by the schema validation
 	}
 }
 
@@ -1537,6 +1957,19 @@ func (p *Config) HasherPasswordHashingAlgorithm(ctx context.Context) string {
 	}
 }
 
+func (p *Config) CipherEnvelope(ctx context.Context) *CipherEnvelope {
+	pp := p.GetProvider(ctx)
+	return &CipherEnvelope{
+		KMS:             pp.StringF(ViperKeyCipherEnvelopeKMS, "file"),
+		LegacyAlgorithm: pp.String(ViperKeyCipherEnvelopeLegacyAlgorithm),
+		FilePath:        pp.String(ViperKeyCipherEnvelopeFilePath),
+		VaultAddress:    pp.String(ViperKeyCipherEnvelopeVaultAddress),
+		VaultToken:      pp.String(ViperKeyCipherEnvelopeVaultToken),
+		VaultMount:      pp.StringF(ViperKeyCipherEnvelopeVaultMount, "transit"),
+		VaultKey:        pp.String(ViperKeyCipherEnvelopeVaultKey),
+	}
+}
+
 func (p *Config) CipherAlgorithm(ctx context.Context) string {
 	configValue := p.GetProvider(ctx).StringF(ViperKeyCipherAlgorithm, DefaultCipherAlgorithm)
 	switch configValue {
@@ -1602,6 +2035,87 @@ func (p *Config) SelfServiceLoginFlowIdentifierFirstEnabled(ctx context.Context)
 	}
 }
 
+// SelfServiceLoginHomeRealm returns the home realm which claims the domain of
+// the identifier, or nil if the identifier is not an email address of a
+// claimed domain.
+func (p *Config) SelfServiceLoginHomeRealm(ctx context.Context, identifier string) *HomeRealm {
+	domain := EmailDomain(identifier)
+	if domain == "" {
+		return nil
+	}
+
+	var realms []HomeRealm
+	if err := p.GetProvider(ctx).Unmarshal(ViperKeySelfServiceLoginHomeRealms, &realms); err != nil {
+		p.l.WithError(err).Errorf("Unable to decode values from %s.", ViperKeySelfServiceLoginHomeRealms)
+		return nil
+	}
+
+	for k := range realms {
+		for _, d := range realms[k].Domains {
+			if strings.EqualFold(d, domain) {
+				return &realms[k]
+			}
+		}
+	}
+	return nil
+}
+
+// EmailDomain returns the lower-cased domain of the identifier, or an empty
+// string if the identifier is not an email address.
+func EmailDomain(identifier string) string {
+	_, domain, ok := strings.Cut(strings.TrimSpace(identifier), "@")
+	if !ok || strings.Contains(domain, "@") {
+		return ""
+	}
+	return strings.ToLower(domain)
+}
+
 func (p *Config) SecurityAccountEnumerationMitigate(ctx context.Context) bool {
 	return p.GetProvider(ctx).Bool(ViperKeySecurityAccountEnumerationMitigate)
 }
+
+func (p *Config) SecurityRateLimit(ctx context.Context) *RateLimit {
+	pp := p.GetProvider(ctx)
+	return &RateLimit{
+		Enabled:                  pp.BoolF(ViperKeySecurityRateLimit+".enabled", false),
+		Store:                    pp.StringF(ViperKeySecurityRateLimit+".store", "sql"),
+		Window:                   pp.DurationF(ViperKeySecurityRateLimit+".window", 15*time.Minute),
+		MaxAttemptsPerIdentifier: pp.IntF(ViperKeySecurityRateLimit+".max_attempts_per_identifier", 10),
+		MaxAttemptsPerIP:         pp.IntF(ViperKeySecurityRateLimit+".max_attempts_per_ip", 100),
+		MaxAttemptsPerIdentity:   pp.IntF(ViperKeySecurityRateLimit+".max_attempts_per_identity", 20),
+		TrustedProxies:           pp.StringsF(ViperKeySecurityRateLimit+".trusted_proxies", []string{}),
+		ClientIPHeader:           pp.StringF(ViperKeySecurityRateLimit+".client_ip_header", "X-Forwarded-For"),
+	}
+}
+
+func (p *Config) SecurityCaptcha(ctx context.Context) *Captcha {
+	pp := p.GetProvider(ctx)
+	return &Captcha{
+		Enabled:   pp.BoolF(ViperKeySecurityCaptcha+".enabled", false),
+		Provider:  pp.StringF(ViperKeySecurityCaptcha+".provider", "turnstile"),
+		SiteKey:   pp.String(ViperKeySecurityCaptcha + ".site_key"),
+		SecretKey: pp.String(ViperKeySecurityCaptcha + ".secret_key"),
+		VerifyURL: pp.String(ViperKeySecurityCaptcha + ".verify_url"),
+		ScriptURL: pp.String(ViperKeySecurityCaptcha + ".script_url"),
+		MinScore:  pp.Float64F(ViperKeySecurityCaptcha+".min_score", 0),
+	}
+}
+
+func (p *Config) SecurityCaptchaFlow(ctx context.Context, flow string) *CaptchaFlow {
+	pp := p.GetProvider(ctx)
+	key := ViperKeySecurityCaptcha + ".flows." + flow
+	return &CaptchaFlow{
+		Enabled:             pp.BoolF(key+".enabled", false),
+		AfterFailedAttempts: pp.IntF(key+".after_failed_attempts", 0),
+	}
+}
+
+func (p *Config) SecurityAccountLockout(ctx context.Context) *AccountLockout {
+	pp := p.GetProvider(ctx)
+	return &AccountLockout{
+		Enabled:           pp.BoolF(ViperKeySecurityAccountLockout+".enabled", false),
+		MaxFailedAttempts: pp.IntF(ViperKeySecurityAccountLockout+".max_failed_attempts", 5),
+		Duration:          pp.DurationF(ViperKeySecurityAccountLockout+".duration", 5*time.Minute),
+		MaxDuration:       pp.DurationF(ViperKeySecurityAccountLockout+".max_duration", 24*time.Hour),
+	}
+}
@@ -1129,6 +1129,63 @@ func TestPasswordlessCode(t *testing.T) {
 	assert.True(t, conf.SelfServiceCodeStrategy(ctx).PasswordlessEnabled)
 }
 
+func TestHomeRealmDiscovery(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	conf, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+		configx.SkipValidation(),
+		configx.WithValue(config.ViperKeySelfServiceLoginHomeRealms, []map[string]any{
+			{"domains": []string{"acme.com", "Acme.io"}, "provider": "acme", "forbid_password_login": true},
+			{"domains": []string{"globex.com"}, "organization": "9ebab1d6-4d5b-4b3b-a14f-4ebd59a1b2f4"},
+		}))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		identifier, provider string
+	}{
+		{identifier: "alice@acme.com", provider: "acme"},
+		{identifier: " ALICE@acme.IO ", provider: "acme"},
+		{identifier: "bob@globex.com"},
+	} {
+		realm := conf.SelfServiceLoginHomeRealm(ctx, tc.identifier)
+		require.NotNil(t, realm, tc.identifier)
+		assert.Equal(t, tc.provider, realm.Provider, tc.identifier)
+	}
+	assert.True(t, conf.SelfServiceLoginHomeRealm(ctx, "alice@acme.com").ForbidPasswordLogin)
+	assert.Equal(t, "9ebab1d6-4d5b-4b3b-a14f-4ebd59a1b2f4", conf.SelfServiceLoginHomeRealm(ctx, "bob@globex.com").Organization)
+
+	for _, identifier := range []string{"acme.com", "alice", "alice@sub.acme.com", "alice@acme.com@evil.com", ""} {
+		assert.Nil(t, conf.SelfServiceLoginHomeRealm(ctx, identifier), identifier)
+	}
+}
+
+func TestCaptchaAfterFailedAttempts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	newConfig := func(rateLimit map[string]any) error {
+		_, err := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithValues(map[string]any{
+				config.ViperKeyDSN: "memory",
+				config.ViperKeySelfServiceBrowserDefaultReturnTo: "https://www.ory.sh/",
+				config.ViperKeyIdentitySchemas:                   config.Schemas{{ID: "default", URL: "file://./stub/.identity.test.json"}},
+				config.ViperKeySecurityCaptcha: map[string]any{
+					"enabled":    true,
+					"site_key":   "site-key",
+					"secret_key": "secret-key",
+					"flows":      map[string]any{"login": map[string]any{"enabled": true, "after_failed_attempts": 3}},
+				},
+				config.ViperKeySecurityRateLimit: rateLimit,
+			}))
+		return err
+	}
+
+	assert.NoError(t, newConfig(map[string]any{"enabled": true}))
+	assert.Error(t, newConfig(map[string]any{"enabled": false}), "failed attempts are not counted without rate limiting")
+	assert.Error(t, newConfig(map[string]any{"enabled": true, "max_attempts_per_ip": 0}), "failed attempts per IP address are not counted")
+}
+
 func TestChangeMinPasswordLength(t *testing.T) {
 	t.Parallel()
 	t.Run("case=must fail on minimum password length below enforced minimum", func(t *testing.T) {
@@ -1232,6 +1289,50 @@ func TestCourierMessageTTL(t *testing.T) {
 	})
 }
 
+func TestCourierRetry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("case=defaults", func(t *testing.T) {
+		conf, _ := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{}, configx.SkipValidation())
+		retry, err := conf.CourierRetry(ctx, "email")
+		require.NoError(t, err)
+		assert.Equal(t, &config.CourierRetry{MaxAttempts: 6, MaxInterval: time.Hour}, retry)
+	})
+
+	t.Run("case=legacy message retries", func(t *testing.T) {
+		conf, _ := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithValue(config.ViperKeyCourierMessageRetries, 2), configx.SkipValidation())
+		retry, err := conf.CourierRetry(ctx, "email")
+		require.NoError(t, err)
+		assert.Equal(t, 3, retry.MaxAttempts)
+	})
+
+	t.Run("case=channel overrides", func(t *testing.T) {
+		conf, _ := config.New(ctx, logrusx.New("", ""), os.Stderr, &contextx.Default{},
+			configx.WithValues(map[string]any{
+				config.ViperKeyCourierRetry: map[string]any{
+					"max_attempts":     4,
+					"initial_interval": "10s",
+				},
+				config.ViperKeyCourierChannels: []map[string]any{{
+					"id":             "sms",
+					"type":           "http",
+					"request_config": map[string]any{"url": "https://example.org", "method": "POST"},
+					"retry":          map[string]any{"max_attempts": 10, "max_interval": "5m"},
+				}},
+			}), configx.SkipValidation())
+
+		retry, err := conf.CourierRetry(ctx, "sms")
+		require.NoError(t, err)
+		assert.Equal(t, &config.CourierRetry{MaxAttempts: 10, InitialInterval: 10 * time.Second, MaxInterval: 5 * time.Minute}, retry)
+
+		retry, err = conf.CourierRetry(ctx, "email")
+		require.NoError(t, err)
+		assert.Equal(t, &config.CourierRetry{MaxAttempts: 4, InitialInterval: 10 * time.Second, MaxInterval: time.Hour}, retry)
+	})
+}
+
 func TestTwoStep(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -1408,6 +1509,94 @@ func TestCourierTemplatesConfig(t *testing.T) {
 		}
 		assert.Equal(t, courierTemplateConfig, c.CourierEmailTemplatesHelper(ctx, config.ViperKeyCourierTemplatesRecoveryValidEmail))
 	})
+
+	t.Run("case=localized templates", func(t *testing.T) {
+		c := config.MustNew(t, logrusx.New("", ""), &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValues(map[string]interface{}{
+				config.ViperKeyCourierLocaleTrait: "locale",
+				config.ViperKeyCourierTemplatesVerificationCodeValidEmail: map[string]interface{}{
+					"subject": "base64://c3ViamVjdA==",
+					"body":    map[string]interface{}{"html": "base64://aHRtbA==", "plaintext": "base64://cGxhaW50ZXh0"},
+					"locales": map[string]interface{}{
+						"de":    map[string]interface{}{"subject": "base64://QmV0cmVmZg==", "body": map[string]interface{}{"html": "base64://SFRNTA=="}},
+						"pt-BR": map[string]interface{}{"body": map[string]interface{}{"plaintext": "base64://dGV4dG8="}},
+					},
+				},
+				config.ViperKeyCourierTemplatesVerificationCodeValidSMS: map[string]interface{}{
+					"body":    map[string]interface{}{"plaintext": "base64://c21z"},
+					"locales": map[string]interface{}{"de": map[string]interface{}{"body": map[string]interface{}{"plaintext": "base64://U01T"}}},
+				},
+			}))
+
+		assert.Equal(t, "locale", c.CourierLocaleTrait(ctx))
+
+		tpl := c.CourierTemplatesVerificationCodeValid(ctx)
+		assert.Equal(t, &config.CourierEmailTemplate{
+			Subject: "base64://QmV0cmVmZg==",
+			Body:    &config.CourierEmailBodyTemplate{HTML: "base64://SFRNTA==", PlainText: "base64://cGxhaW50ZXh0"},
+		}, tpl.ForLocale("de-AT"))
+		assert.Equal(t, &config.CourierEmailTemplate{
+			Subject: "base64://c3ViamVjdA==",
+			Body:    &config.CourierEmailBodyTemplate{HTML: "base64://aHRtbA==", PlainText: "base64://dGV4dG8="},
+		}, tpl.ForLocale("pt-BR"))
+		assert.Same(t, tpl, tpl.ForLocale("fr"))
+		assert.Same(t, tpl, tpl.ForLocale(""))
+
+		sms := c.CourierSMSTemplatesVerificationCodeValid(ctx)
+		assert.Equal(t, "base64://U01T", sms.ForLocale("de").Body.PlainText)
+		assert.Equal(t, "base64://c21z", sms.ForLocale("fr").Body.PlainText)
+	})
+
+	t.Run("case=branding", func(t *testing.T) {
+		c := config.MustNew(t, logrusx.New("", ""), &contextx.Default{},
+			configx.WithConfigFiles("stub/.kratos.yaml"),
+			configx.WithValues(map[string]interface{}{
+				config.ViperKeyCourierBranding: map[string]interface{}{
+					"product_name":  "Ory",
+					"logo_url":      "https://www.ory.sh/logo.png",
+					"support_email": "support@ory.sh",
+					"colors":        map[string]interface{}{"primary": "#000000", "background": "#ffffff"},
+					"locales": map[string]interface{}{
+						"de": map[string]interface{}{"product_name": "Ory DE"},
+					},
+					"schemas": map[string]interface{}{
+						"customer": map[string]interface{}{
+							"product_name": "Ory Shop",
+							"locales":      map[string]interface{}{"de": map[string]interface{}{"product_name": "Ory Laden"}},
+						},
+					},
+					"organizations": map[string]interface{}{
+						"7e0bb1b2-7a73-4a56-a2d9-6d8e3e1d1a53": map[string]interface{}{
+							"logo_url": "https://acme.com/logo.png",
+							"colors":   map[string]interface{}{"primary": "#ff0000"},
+						},
+					},
+				},
+			}))
+
+		assert.Equal(t, config.CourierBranding{
+			ProductName:  "Ory",
+			LogoURL:      "https://www.ory.sh/logo.png",
+			SupportEmail: "support@ory.sh",
+			Colors:       map[string]string{"primary": "#000000", "background": "#ffffff"},
+		}, c.CourierBranding(ctx, "", "", ""))
+		assert.Equal(t, "Ory DE", c.CourierBranding(ctx, "", "", "de-AT").ProductName)
+		assert.Equal(t, "Ory", c.CourierBranding(ctx, "", "", "fr").ProductName)
+		assert.Equal(t, "Ory Shop", c.CourierBranding(ctx, "customer", "", "").ProductName)
+		assert.Equal(t, "Ory Laden", c.CourierBranding(ctx, "customer", "", "de").ProductName)
+		assert.Equal(t, "Ory", c.CourierBranding(ctx, "employee", "", "").ProductName)
+
+		assert.Equal(t, config.CourierBranding{
+			ProductName:  "Ory Laden",
+			LogoURL:      "https://acme.com/logo.png",
+			SupportEmail: "support@ory.sh",
+			Colors:       map[string]string{"primary": "#ff0000", "background": "#ffffff"},
+		}, c.CourierBranding(ctx, "customer", "7e0bb1b2-7a73-4a56-a2d9-6d8e3e1d1a53", "de"))
+
+		empty := config.MustNew(t, logrusx.New("", ""), &contextx.Default{}, configx.WithConfigFiles("stub/.kratos.yaml"))
+		assert.Zero(t, empty.CourierBranding(ctx, "customer", "", "de"))
+	})
 }
 
 func TestCleanup(t *testing.T) {
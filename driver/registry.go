@@ -19,8 +19,11 @@ import (
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/hash"
 	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/outbox"
 	"github.com/ory/kratos/persistence"
 	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/scim"
+	"github.com/ory/kratos/selfservice/captcha"
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow/login"
 	"github.com/ory/kratos/selfservice/flow/logout"
@@ -28,9 +31,11 @@ import (
 	"github.com/ory/kratos/selfservice/flow/registration"
 	"github.com/ory/kratos/selfservice/flow/settings"
 	"github.com/ory/kratos/selfservice/flow/verification"
+	"github.com/ory/kratos/selfservice/ratelimit"
 	"github.com/ory/kratos/selfservice/sessiontokenexchange"
 	"github.com/ory/kratos/selfservice/strategy/code"
 	"github.com/ory/kratos/selfservice/strategy/link"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
 	password2 "github.com/ory/kratos/selfservice/strategy/password"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/x"
@@ -96,15 +101,24 @@ type Registry interface {
 	hash.HashProvider
 
 	identity.HandlerProvider
+	scim.HandlerProvider
 	identity.ValidationProvider
 	identity.PoolProvider
 	identity.PrivilegedPoolProvider
 	identity.ManagementProvider
 	identity.ActiveCredentialsCounterStrategyProvider
+	identity.LockoutPersistenceProvider
 
 	courier.HandlerProvider
 	courier.PersistenceProvider
 
+	outbox.HandlerProvider
+	outbox.PersistenceProvider
+	outbox.DispatcherProvider
+
+	oidc.HandlerProvider
+	oidc.ProviderPersistenceProvider
+
 	schema.HandlerProvider
 	schema.IdentitySchemaProvider
 
@@ -143,6 +157,11 @@ type Registry interface {
 
 	sessiontokenexchange.PersistenceProvider
 
+	ratelimit.PersistenceProvider
+	ratelimit.LimiterProvider
+
+	captcha.Provider
+
 	link.SenderProvider
 	link.VerificationTokenPersistenceProvider
 	link.RecoveryTokenPersistenceProvider
@@ -18,6 +18,7 @@ import (
 	"github.com/lestrrat-go/jwx/jwk"
 	"github.com/pkg/errors"
 	"github.com/urfave/negroni"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/ory/herodot"
 	"github.com/ory/kratos/cipher"
@@ -27,9 +28,12 @@ import (
 	"github.com/ory/kratos/hash"
 	"github.com/ory/kratos/hydra"
 	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/outbox"
 	"github.com/ory/kratos/persistence"
 	"github.com/ory/kratos/persistence/sql"
 	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/scim"
+	"github.com/ory/kratos/selfservice/captcha"
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow/login"
 	"github.com/ory/kratos/selfservice/flow/logout"
@@ -38,17 +42,21 @@ import (
 	"github.com/ory/kratos/selfservice/flow/settings"
 	"github.com/ory/kratos/selfservice/flow/verification"
 	"github.com/ory/kratos/selfservice/hook"
+	"github.com/ory/kratos/selfservice/ratelimit"
 	"github.com/ory/kratos/selfservice/strategy/code"
 	"github.com/ory/kratos/selfservice/strategy/idfirst"
+	"github.com/ory/kratos/selfservice/strategy/ldap"
 	"github.com/ory/kratos/selfservice/strategy/link"
 	"github.com/ory/kratos/selfservice/strategy/lookup"
 	"github.com/ory/kratos/selfservice/strategy/oidc"
 	"github.com/ory/kratos/selfservice/strategy/passkey"
 	"github.com/ory/kratos/selfservice/strategy/password"
 	"github.com/ory/kratos/selfservice/strategy/profile"
+	"github.com/ory/kratos/selfservice/strategy/saml"
 	"github.com/ory/kratos/selfservice/strategy/totp"
 	"github.com/ory/kratos/selfservice/strategy/webauthn"
 	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/ui/container"
 	"github.com/ory/kratos/x"
 	"github.com/ory/kratos/x/nosurfx"
 	"github.com/ory/nosurf"
@@ -102,6 +110,8 @@ type RegistryDefault struct {
 
 	courierHandler *courier.Handler
 
+	scimHandler *scim.Handler
+
 	continuityManager continuity.Manager
 
 	schemaHandler *schema.Handler
@@ -145,6 +155,20 @@ type RegistryDefault struct {
 
 	selfserviceLogoutHandler *logout.Handler
 
+	rateLimiter *ratelimit.Limiter
+
+	captchaVerifier *captcha.Verifier
+
+	outboxHandler    *outbox.Handler
+	outboxDispatcher *outbox.Dispatcher
+
+	oidcProviderHandler *oidc.Handler
+	oidcProviderCache   oidc.ProviderCache
+
+	translationsMu    sync.Mutex
+	translations      map[string]*translationsEntry
+	translationsLoads singleflight.Group
+
 	selfserviceStrategies            []any
 	replacementSelfserviceStrategies []NewStrategy
 
@@ -182,6 +206,8 @@ func (m *RegistryDefault) RegisterPublicRoutes(ctx context.Context, router *x.Ro
 	m.SettingsHandler().RegisterPublicRoutes(router)
 	m.IdentityHandler().RegisterPublicRoutes(router)
 	m.CourierHandler().RegisterPublicRoutes(router)
+	m.OutboxHandler().RegisterPublicRoutes(router)
+	m.OIDCProviderHandler().RegisterPublicRoutes(router)
 	m.AllLoginStrategies().RegisterPublicRoutes(router)
 	m.AllSettingsStrategies().RegisterPublicRoutes(router)
 	m.AllRegistrationStrategies().RegisterPublicRoutes(router)
@@ -208,7 +234,10 @@ func (m *RegistryDefault) RegisterAdminRoutes(ctx context.Context, router *x.Rou
 	m.SchemaHandler().RegisterAdminRoutes(router)
 	m.SettingsHandler().RegisterAdminRoutes(router)
 	m.IdentityHandler().RegisterAdminRoutes(router)
+	m.SCIMHandler().RegisterAdminRoutes(router)
 	m.CourierHandler().RegisterAdminRoutes(router)
+	m.OutboxHandler().RegisterAdminRoutes(router)
+	m.OIDCProviderHandler().RegisterAdminRoutes(router)
 	m.SelfServiceErrorHandler().RegisterAdminRoutes(router)
 
 	m.RecoveryHandler().RegisterAdminRoutes(router)
@@ -327,6 +356,8 @@ func (m *RegistryDefault) selfServiceStrategies() []any {
 			m.selfserviceStrategies = []any{
 				profile.NewStrategy(m), // <- should remain first
 				password.NewStrategy(m),
+				ldap.NewStrategy(m), // <- must be after password as it shares the password field
+				saml.NewStrategy(m), // <- must be before oidc as it shares the link/unlink fields
 				oidc.NewStrategy(m),
 				code.NewStrategy(m),
 				link.NewStrategy(m),
@@ -442,7 +473,7 @@ func (m *RegistryDefault) WithSelfserviceStrategies(t testing.TB, strategies []a
 func (m *RegistryDefault) Writer() herodot.Writer {
 	if m.writer == nil {
 		h := herodot.NewJSONWriter(m.Logger())
-		m.writer = h
+		m.writer = container.NewTranslatingWriter(h, m.Translations)
 	}
 	return m.writer
 }
@@ -454,6 +485,13 @@ func (m *RegistryDefault) Logger() *logrusx.Logger {
 	return m.l
 }
 
+func (m *RegistryDefault) SCIMHandler() *scim.Handler {
+	if m.scimHandler == nil {
+		m.scimHandler = scim.NewHandler(m)
+	}
+	return m.scimHandler
+}
+
 func (m *RegistryDefault) IdentityHandler() *identity.Handler {
 	if m.identityHandler == nil {
 		m.identityHandler = identity.NewHandler(m)
@@ -489,6 +527,8 @@ func (m *RegistryDefault) Cipher(ctx context.Context) cipher.Cipher {
 			m.crypter = cipher.NewCryptChaCha20(m.Config())
 		case "aes":
 			m.crypter = cipher.NewCryptAES(m.Config())
+		case "envelope":
+			m.crypter = m.envelopeCipher(ctx)
 		default:
 			m.crypter = cipher.NewNoop()
 			m.l.Logger.Warning("No encryption configuration found. The default algorithm (noop) will be used, resulting in sensitive data being stored in plaintext")
@@ -497,6 +537,35 @@ func (m *RegistryDefault) Cipher(ctx context.Context) cipher.Cipher {
 	return m.crypter
 }
 
+func (m *RegistryDefault) envelopeCipher(ctx context.Context) cipher.Cipher {
+	c := m.c.CipherEnvelope(ctx)
+
+	var legacy cipher.Cipher
+	switch c.LegacyAlgorithm {
+	case "xchacha20-poly1305":
+		legacy = cipher.NewCryptChaCha20(m.Config())
+	case "aes":
+		legacy = cipher.NewCryptAES(m.Config())
+	}
+
+	var kms cipher.KMS
+	switch c.KMS {
+	case "vault":
+		// Vault usually runs in a private network, so the client must not be
+		// restricted to public IP ranges.
+		client := httpx.NewResilientClient(
+			httpx.ResilientClientWithLogger(m.Logger()),
+			httpx.ResilientClientWithMaxRetry(2),
+			httpx.ResilientClientWithConnectionTimeout(30*time.Second),
+		)
+		kms = cipher.NewVaultKMS(client.StandardClient(), c.VaultAddress, c.VaultToken, c.VaultMount, c.VaultKey)
+	default:
+		kms = cipher.NewFileKMS(c.FilePath)
+	}
+
+	return cipher.NewEnvelope(kms, legacy)
+}
+
 func (m *RegistryDefault) Hasher(ctx context.Context) hash.Hasher {
 	if m.passwordHasher == nil {
 		if m.c.HasherPasswordHashingAlgorithm(ctx) == "bcrypt" {
@@ -743,6 +812,10 @@ func (m *RegistryDefault) PrivilegedIdentityPool() identity.PrivilegedPool {
 	return m.persister
 }
 
+func (m *RegistryDefault) IdentityLockoutPersister() identity.LockoutPersister {
+	return m.persister
+}
+
 func (m *RegistryDefault) RegistrationFlowPersister() registration.FlowPersister {
 	return m.persister
 }
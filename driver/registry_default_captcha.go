@@ -0,0 +1,13 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import "github.com/ory/kratos/selfservice/captcha"
+
+func (m *RegistryDefault) CaptchaVerifier() *captcha.Verifier {
+	if m.captchaVerifier == nil {
+		m.captchaVerifier = captcha.NewVerifier(m)
+	}
+	return m.captchaVerifier
+}
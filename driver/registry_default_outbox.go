@@ -0,0 +1,24 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import "github.com/ory/kratos/outbox"
+
+func (m *RegistryDefault) OutboxPersister() outbox.EventPersister {
+	return m.Persister()
+}
+
+func (m *RegistryDefault) OutboxHandler() *outbox.Handler {
+	if m.outboxHandler == nil {
+		m.outboxHandler = outbox.NewHandler(m)
+	}
+	return m.outboxHandler
+}
+
+func (m *RegistryDefault) OutboxDispatcher() *outbox.Dispatcher {
+	if m.outboxDispatcher == nil {
+		m.outboxDispatcher = outbox.NewDispatcher(m)
+	}
+	return m.outboxDispatcher
+}
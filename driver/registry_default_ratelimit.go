@@ -0,0 +1,17 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import "github.com/ory/kratos/selfservice/ratelimit"
+
+func (m *RegistryDefault) RateLimitPersister() ratelimit.Persister {
+	return m.Persister()
+}
+
+func (m *RegistryDefault) RateLimiter() *ratelimit.Limiter {
+	if m.rateLimiter == nil {
+		m.rateLimiter = ratelimit.NewLimiter(m)
+	}
+	return m.rateLimiter
+}
@@ -0,0 +1,140 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/text"
+	"github.com/ory/x/fetcher"
+)
+
+const (
+	translationsRetryInterval    = 5 * time.Second
+	translationsMaxRetryInterval = 10 * time.Minute
+)
+
+// translationsEntry caches the translations loaded for a configuration. If a
+// catalog could not be loaded, the catalogs are loaded again after retryAt.
+type translationsEntry struct {
+	translations *text.Translations
+	failures     int
+	retryAt      time.Time
+}
+
+// Translations returns the UI text translations of the configured message catalogs.
+// Catalogs are loaded once per configuration. If a catalog can not be loaded, the
+// error is logged and the texts of its language are not translated until the
+// catalogs are loaded again, with an exponential backoff.
+func (m *RegistryDefault) Translations(ctx context.Context) *text.Translations {
+	catalogs, err := m.Config().SelfServiceTranslations(ctx)
+	if err != nil {
+		m.Logger().WithError(err).Error("Unable to decode the UI text translation catalogs.")
+		return text.NewTranslations(nil)
+	}
+	if len(catalogs) == 0 {
+		return text.NewTranslations(nil)
+	}
+
+	raw, err := json.Marshal(catalogs)
+	if err != nil {
+		return text.NewTranslations(nil)
+	}
+	key := string(raw)
+
+	m.translationsMu.Lock()
+	cached, ok := m.translations[key]
+	m.translationsMu.Unlock()
+	if ok && (cached.failures == 0 || time.Now().Before(cached.retryAt)) {
+		return cached.translations
+	}
+
+	// The catalogs are fetched without holding the lock, and only once for
+	// concurrent requests. The fetch must not be canceled by the request which
+	// happens to trigger it.
+	loaded, _, _ := m.translationsLoads.Do(key, func() (any, error) {
+		return m.loadTranslations(context.WithoutCancel(ctx), key, catalogs), nil
+	})
+	return loaded.(*text.Translations)
+}
+
+func (m *RegistryDefault) loadTranslations(ctx context.Context, key string, catalogs []config.TranslationCatalog) *text.Translations {
+	var failed bool
+	loaded := make(map[language.Tag]text.Catalog, len(catalogs))
+	for _, c := range catalogs {
+		tag, catalog, err := m.loadTranslationCatalog(ctx, c)
+		if err != nil {
+			m.Logger().WithError(err).WithField("language", c.Language).WithField("url", c.URL).
+				Error("Unable to load the UI text translation catalog.")
+			failed = true
+			continue
+		}
+		// Later catalogs of the same language override earlier ones.
+		if loaded[tag] == nil {
+			loaded[tag] = make(text.Catalog, len(catalog))
+		}
+		for id, t := range catalog {
+			loaded[tag][id] = t
+		}
+	}
+
+	m.translationsMu.Lock()
+	defer m.translationsMu.Unlock()
+
+	entry := &translationsEntry{translations: text.NewTranslations(loaded)}
+	if failed {
+		entry.failures = 1
+		if previous, ok := m.translations[key]; ok {
+			entry.failures = previous.failures + 1
+		}
+		entry.retryAt = time.Now().Add(translationsRetryBackoff(entry.failures))
+	}
+
+	if m.translations == nil {
+		m.translations = make(map[string]*translationsEntry)
+	}
+	m.translations[key] = entry
+	return entry.translations
+}
+
+// translationsRetryBackoff returns the time to wait before loading the catalogs
+// again after the given number of consecutive failed loads.
+func translationsRetryBackoff(failures int) time.Duration {
+	return min(translationsRetryInterval<<min(failures-1, 16), translationsMaxRetryInterval)
+}
+
+func (m *RegistryDefault) loadTranslationCatalog(ctx context.Context, c config.TranslationCatalog) (language.Tag, text.Catalog, error) {
+	tag, err := language.Parse(c.Language)
+	if err != nil {
+		return language.Und, nil, errors.WithStack(err)
+	}
+
+	raw, err := fetcher.NewFetcher(fetcher.WithClient(m.HTTPClient(ctx))).FetchBytes(ctx, c.URL)
+	if err != nil {
+		return language.Und, nil, err
+	}
+
+	format := c.Format
+	if format == "" {
+		if u, err := url.Parse(c.URL); err == nil && path.Ext(u.Path) == ".po" {
+			format = "po"
+		}
+	}
+
+	var catalog text.Catalog
+	if format == "po" {
+		catalog, err = text.ParsePOCatalog(raw)
+	} else {
+		catalog, err = text.ParseJSONCatalog(raw)
+	}
+	return tag, catalog, err
+}
@@ -0,0 +1,110 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package driver_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/x/configx"
+	"github.com/ory/x/contextx"
+)
+
+func TestDriverDefault_Translations(t *testing.T) {
+	ctx := context.Background()
+	b64 := func(s string) string { return "base64://" + base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	conf, reg := internal.NewFastRegistryWithMocks(t, configx.WithValues(map[string]any{
+		config.ViperKeySelfServiceTranslations: []map[string]any{
+			{"language": "de", "url": b64(`{"1010001": "Anmelden", "1070004": "Kennung"}`)},
+			{"language": "de", "url": b64(`{"1070004": "Benutzername"}`)},
+			{"language": "pt-BR", "url": b64("msgctxt \"1010001\"\nmsgid \"Sign in\"\nmsgstr \"Entrar\"\n"), "format": "po"},
+			{"language": "fr", "url": b64(`not json`)},
+		},
+	}))
+
+	t.Run("case=loads the catalogs", func(t *testing.T) {
+		tr := reg.Translations(ctx)
+		assert.Equal(t, text.Catalog{text.InfoSelfServiceLogin: "Anmelden", text.InfoNodeLabelID: "Benutzername"}, tr.Negotiate("de", ""))
+		assert.Equal(t, text.Catalog{text.InfoSelfServiceLogin: "Entrar"}, tr.Negotiate("pt-BR", ""))
+		assert.Empty(t, tr.Negotiate("fr", ""), "catalogs which can not be loaded are skipped")
+		assert.Same(t, tr, reg.Translations(ctx), "catalogs are loaded once")
+	})
+
+	t.Run("case=reloads the catalogs when the configuration changes", func(t *testing.T) {
+		ctx := contextx.WithConfigValue(ctx, config.ViperKeySelfServiceTranslations, []map[string]any{
+			{"language": "de", "url": b64(`{"1010001": "Einloggen"}`)},
+		})
+		assert.Equal(t, text.Catalog{text.InfoSelfServiceLogin: "Einloggen"}, reg.Translations(ctx).Negotiate("de", ""))
+	})
+
+	t.Run("case=retries catalogs which can not be loaded", func(t *testing.T) {
+		var requests atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"1010001": "Inloggen"}`))
+		}))
+		t.Cleanup(ts.Close)
+		ctx := contextx.WithConfigValue(ctx, config.ViperKeySelfServiceTranslations, []map[string]any{
+			{"language": "nl", "url": ts.URL + "/nl.json"},
+		})
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Empty(t, reg.Translations(ctx).Negotiate("nl", ""))
+			}()
+		}
+		wg.Wait()
+		assert.EqualValues(t, 1, requests.Load(), "concurrent requests load the catalogs once")
+
+		assert.Empty(t, reg.Translations(ctx).Negotiate("nl", ""), "the failed load is not retried immediately")
+		assert.EqualValues(t, 1, requests.Load())
+
+		assert.Eventually(t, func() bool {
+			return reg.Translations(ctx).Negotiate("nl", "")[text.InfoSelfServiceLogin] == "Inloggen"
+		}, 10*time.Second, 100*time.Millisecond)
+		assert.EqualValues(t, 2, requests.Load())
+	})
+
+	t.Run("case=translates flows", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/self-service/login/api", nil)
+		r.Header.Set("Accept-Language", "de-DE,de;q=0.9,en;q=0.8")
+		f, err := login.NewFlow(conf, time.Minute, "", r, flow.TypeAPI)
+		require.NoError(t, err)
+		f.UI.Nodes.Append(node.NewInputField("identifier", "", node.DefaultGroup, node.InputAttributeTypeText).
+			WithMetaLabel(text.NewInfoNodeLabelID()))
+
+		rec := httptest.NewRecorder()
+		reg.Writer().Write(rec, r, f)
+		require.Equal(t, http.StatusOK, rec.Code, "%s", rec.Body)
+
+		identifier := gjson.Get(rec.Body.String(), `ui.nodes.#(attributes.name=="identifier").meta.label`)
+		assert.Equal(t, "Benutzername", identifier.Get("text").String(), "%s", rec.Body)
+		assert.EqualValues(t, text.InfoNodeLabelID, identifier.Get("id").Int(), "%s", rec.Body)
+
+		assert.Equal(t, text.NewInfoNodeLabelID().Text, f.UI.Nodes[0].Meta.Label.Text, "the flow is not modified")
+	})
+}
@@ -420,6 +420,18 @@ func IsFirebaseScryptHash(hash []byte) bool { return isFirebaseScryptHash.Match(
 func IsMD5Hash(hash []byte) bool            { return isMD5Hash.Match(hash) }
 func IsHMACHash(hash []byte) bool           { return isHMACHash.Match(hash) }
 
+// AlgorithmName returns the name of the algorithm which generated the hash, or
+// "unknown" if the hash is not supported.
+func AlgorithmName(hash []byte) string {
+	for _, h := range supportedHashers {
+		if h.Is(hash) {
+			return h.Name
+		}
+	}
+
+	return "unknown"
+}
+
 func IsValidHashFormat(hash []byte) bool {
 	for _, h := range supportedHashers {
 		if h.Is(hash) {
@@ -14,6 +14,10 @@ type Hasher interface {
 
 	// Understands returns whether the given hash can be understood by this hasher.
 	Understands(hash []byte) bool
+
+	// NeedsRehash returns whether the given hash should be replaced by a hash generated by this hasher, because
+	// it was generated by a different algorithm or with weaker parameters than the ones currently configured.
+	NeedsRehash(ctx context.Context, hash []byte) bool
 }
 
 type HashProvider interface {
@@ -88,3 +88,22 @@ func (h *Argon2) Generate(ctx context.Context, password []byte) ([]byte, error)
 func (h *Argon2) Understands(hash []byte) bool {
 	return IsArgon2idHash(hash)
 }
+
+func (h *Argon2) NeedsRehash(ctx context.Context, hash []byte) bool {
+	if !h.Understands(hash) {
+		return true
+	}
+
+	p, _, _, err := decodeArgon2idHash(string(hash))
+	if err != nil {
+		return true
+	}
+
+	conf := h.c.Config().HasherArgon2(ctx)
+	//nolint:gosec // disable G115
+	return uint32(p.Memory) < toKB(conf.Memory) ||
+		p.Iterations < conf.Iterations ||
+		p.Parallelism < conf.Parallelism ||
+		p.SaltLength < conf.SaltLength ||
+		p.KeyLength < conf.KeyLength
+}
@@ -12,8 +12,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/hash"
 	"github.com/ory/kratos/internal"
+	"github.com/ory/x/contextx"
 )
 
 func mkpw(t *testing.T, length int) []byte {
@@ -544,3 +546,71 @@ func TestCompare(t *testing.T) {
 
 	})
 }
+
+func TestNeedsRehash(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	conf, reg := internal.NewVeryFastRegistryWithoutDB(t)
+
+	t.Run("hasher=bcrypt", func(t *testing.T) {
+		t.Parallel()
+		h := hash.NewHasherBcrypt(reg)
+
+		hs, err := h.Generate(ctx, []byte("password"))
+		require.NoError(t, err)
+		assert.False(t, h.NeedsRehash(ctx, hs))
+
+		ctx := contextx.WithConfigValue(ctx, config.ViperKeyHasherBcryptCost, conf.HasherBcrypt(ctx).Cost+1)
+		assert.True(t, h.NeedsRehash(ctx, hs))
+
+		argon, err := hash.NewHasherArgon2(reg).Generate(ctx, []byte("password"))
+		require.NoError(t, err)
+		assert.True(t, h.NeedsRehash(ctx, argon))
+	})
+
+	t.Run("hasher=argon2", func(t *testing.T) {
+		t.Parallel()
+		h := hash.NewHasherArgon2(reg)
+
+		hs, err := h.Generate(ctx, []byte("password"))
+		require.NoError(t, err)
+		assert.False(t, h.NeedsRehash(ctx, hs))
+
+		for key, value := range map[string]any{
+			config.ViperKeyHasherArgon2ConfigIterations:  conf.HasherArgon2(ctx).Iterations + 1,
+			config.ViperKeyHasherArgon2ConfigMemory:      (conf.HasherArgon2(ctx).Memory * 2).String(),
+			config.ViperKeyHasherArgon2ConfigParallelism: conf.HasherArgon2(ctx).Parallelism + 1,
+			config.ViperKeyHasherArgon2ConfigKeyLength:   conf.HasherArgon2(ctx).KeyLength + 1,
+		} {
+			assert.True(t, h.NeedsRehash(contextx.WithConfigValue(ctx, key, value), hs), key)
+		}
+
+		bcrypt, err := hash.NewHasherBcrypt(reg).Generate(ctx, []byte("password"))
+		require.NoError(t, err)
+		assert.True(t, h.NeedsRehash(ctx, bcrypt))
+	})
+
+	t.Run("hasher=pbkdf2", func(t *testing.T) {
+		t.Parallel()
+		h := &hash.Pbkdf2{Algorithm: "sha256", Iterations: 1000, SaltLength: 16, KeyLength: 32}
+
+		hs, err := h.Generate(ctx, []byte("password"))
+		require.NoError(t, err)
+		assert.False(t, h.NeedsRehash(ctx, hs))
+		assert.True(t, (&hash.Pbkdf2{Algorithm: "sha256", Iterations: 2000, SaltLength: 16, KeyLength: 32}).NeedsRehash(ctx, hs))
+		assert.True(t, (&hash.Pbkdf2{Algorithm: "sha512", Iterations: 1000, SaltLength: 16, KeyLength: 32}).NeedsRehash(ctx, hs))
+	})
+}
+
+func TestAlgorithmName(t *testing.T) {
+	t.Parallel()
+
+	for hs, expected := range map[string]string{
+		"$2a$12$o6hx.Wog/wvFSkT/Bp/6DOxCtLRTDj7lm9on9suF/WaCGNVHbkfL6":              "bcrypt",
+		"$argon2id$v=19$m=16,t=2,p=1$bmJuczZjMGhjSTRmMUdBYg$cVPFJiGG9eR3dvl9VpSvMg": "argon2id",
+		"$md5$Q29udHJvbDEyMw==": "md5",
+		"not-a-hash":            "unknown",
+	} {
+		assert.Equal(t, expected, hash.AlgorithmName([]byte(hs)), hs)
+	}
+}
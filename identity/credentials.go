@@ -94,6 +94,7 @@ const (
 	CredentialsTypePasskey  CredentialsType = "passkey"
 	CredentialsTypeProfile  CredentialsType = "profile"
 	CredentialsTypeSAML     CredentialsType = "saml"
+	CredentialsTypeLDAP     CredentialsType = "ldap"
 )
 
 func (c CredentialsType) String() string {
@@ -116,6 +117,8 @@ func (c CredentialsType) ToUiNodeGroup() node.UiNodeGroup {
 		return node.CodeGroup
 	case CredentialsTypePasskey:
 		return node.PasskeyGroup
+	case CredentialsTypeLDAP:
+		return node.LDAPGroup
 	default:
 		return node.DefaultGroup
 	}
@@ -130,6 +133,7 @@ var AllCredentialTypes = []CredentialsType{
 	CredentialsTypeWebAuthn,
 	CredentialsTypeCodeAuth,
 	CredentialsTypePasskey,
+	CredentialsTypeLDAP,
 }
 
 const (
@@ -137,6 +141,10 @@ const (
 	// It is not used within the credentials object itself.
 	CredentialsTypeRecoveryLink CredentialsType = "link_recovery"
 	CredentialsTypeRecoveryCode CredentialsType = "code_recovery"
+
+	// CredentialsTypeImpersonation is a special credential type used as the authentication method of sessions
+	// which an administrator issued to act as the identity. It is not used within the credentials object itself.
+	CredentialsTypeImpersonation CredentialsType = "impersonation"
 )
 
 // ParseCredentialsType parses a string into a CredentialsType or returns false as the second argument.
@@ -151,7 +159,9 @@ func ParseCredentialsType(in string) (CredentialsType, bool) {
 		CredentialsTypeCodeAuth,
 		CredentialsTypeRecoveryLink,
 		CredentialsTypeRecoveryCode,
-		CredentialsTypePasskey:
+		CredentialsTypeImpersonation,
+		CredentialsTypePasskey,
+		CredentialsTypeLDAP:
 		return t, true
 	}
 	return "", false
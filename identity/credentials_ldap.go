@@ -0,0 +1,16 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package identity
+
+// CredentialsLDAP contains the configuration for credentials of the type ldap.
+//
+// swagger:model identityCredentialsLDAP
+type CredentialsLDAP struct {
+	// DN is the distinguished name of the directory entry the identity authenticated as.
+	DN string `json:"dn"`
+
+	// Username is the identifier the identity used to sign in. It is used to
+	// pre-fill the login form when the session is refreshed.
+	Username string `json:"username"`
+}
@@ -5,11 +5,16 @@ package identity
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 
+	"github.com/ory/kratos/cipher"
 	"github.com/ory/kratos/x"
 )
 
@@ -31,6 +36,10 @@ type CredentialsOIDCProvider struct {
 	InitialRefreshToken string `json:"initial_refresh_token"`
 	Organization        string `json:"organization,omitempty"`
 	UseAutoLink         bool   `json:"use_auto_link,omitzero"`
+
+	// AccessTokenExpiresAt is the expiry of the access token. It is zero if the
+	// provider did not return one.
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at,omitzero"`
 }
 
 // swagger:ignore
@@ -38,6 +47,9 @@ type CredentialsOIDCEncryptedTokens struct {
 	RefreshToken string `json:"refresh_token,omitempty"`
 	IDToken      string `json:"id_token,omitempty"`
 	AccessToken  string `json:"access_token,omitempty"`
+
+	// AccessTokenExpiresAt is not encrypted.
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at,omitzero"`
 }
 
 func (c *CredentialsOIDCEncryptedTokens) GetRefreshToken() string {
@@ -54,6 +66,13 @@ func (c *CredentialsOIDCEncryptedTokens) GetAccessToken() string {
 	return c.AccessToken
 }
 
+func (c *CredentialsOIDCEncryptedTokens) GetAccessTokenExpiresAt() time.Time {
+	if c == nil {
+		return time.Time{}
+	}
+	return c.AccessTokenExpiresAt
+}
+
 func (c *CredentialsOIDCEncryptedTokens) GetIDToken() string {
 	if c == nil {
 		return ""
@@ -80,12 +99,13 @@ func NewOIDCLikeCredentials(tokens *CredentialsOIDCEncryptedTokens, t Credential
 	if err := json.NewEncoder(&b).Encode(CredentialsOIDC{
 		Providers: []CredentialsOIDCProvider{
 			{
-				Subject:             subject,
-				Provider:            provider,
-				InitialIDToken:      tokens.GetIDToken(),
-				InitialAccessToken:  tokens.GetAccessToken(),
-				InitialRefreshToken: tokens.GetRefreshToken(),
-				Organization:        organization,
+				Subject:              subject,
+				Provider:             provider,
+				InitialIDToken:       tokens.GetIDToken(),
+				InitialAccessToken:   tokens.GetAccessToken(),
+				InitialRefreshToken:  tokens.GetRefreshToken(),
+				Organization:         organization,
+				AccessTokenExpiresAt: tokens.GetAccessTokenExpiresAt(),
 			},
 		},
 	}); err != nil {
@@ -105,6 +125,8 @@ func (c *CredentialsOIDCProvider) GetTokens() *CredentialsOIDCEncryptedTokens {
 		RefreshToken: c.InitialRefreshToken,
 		IDToken:      c.InitialIDToken,
 		AccessToken:  c.InitialAccessToken,
+
+		AccessTokenExpiresAt: c.AccessTokenExpiresAt,
 	}
 }
 
@@ -121,3 +143,35 @@ func (c *CredentialsOIDC) Organization() string {
 
 	return ""
 }
+
+// ReencryptOIDCTokens re-encrypts the tokens stored in the config of OIDC
+// credentials, so that they are encrypted with the current key of the cipher.
+// It returns true if any token was re-encrypted.
+func (c *Credentials) ReencryptOIDCTokens(ctx context.Context, ci cipher.Cipher) (changed bool, err error) {
+	config := c.Config
+	gjson.GetBytes(c.Config, "providers").ForEach(func(k, v gjson.Result) bool {
+		for _, token := range []string{"initial_id_token", "initial_access_token", "initial_refresh_token"} {
+			reencrypted, ok, rerr := cipher.Reencrypt(ctx, ci, v.Get(token).String())
+			if rerr != nil {
+				err = errors.WithStack(rerr)
+				return false
+			}
+			if !ok {
+				continue
+			}
+
+			config, err = sjson.SetBytes(config, fmt.Sprintf("providers.%d.%s", k.Int(), token), reencrypted)
+			if err != nil {
+				return false
+			}
+			changed = true
+		}
+		return true
+	})
+	if err != nil {
+		return false, err
+	}
+
+	c.Config = config
+	return changed, nil
+}
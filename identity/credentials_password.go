@@ -14,6 +14,11 @@ type CredentialsPassword struct {
 	// using the password migration hook. If set, and the HashedPassword is empty, a
 	// webhook will be called during login to migrate the password.
 	UsePasswordMigrationHook bool `json:"use_password_migration_hook,omitempty"`
+
+	// PreviousHashedPasswords are the hashes of the previous passwords, with
+	// the most recent first. They are only remembered if a password history
+	// is configured, and can not be reused when the password is changed.
+	PreviousHashedPasswords []string `json:"previous_hashed_passwords,omitempty"`
 }
 
 func (cp *CredentialsPassword) ShouldUsePasswordMigrationHook() bool {
@@ -42,6 +42,7 @@ func TestParseCredentialsType(t *testing.T) {
 		{"lookup_secret", CredentialsTypeLookup},
 		{"link_recovery", CredentialsTypeRecoveryLink},
 		{"code_recovery", CredentialsTypeRecoveryCode},
+		{"impersonation", CredentialsTypeImpersonation},
 	} {
 		t.Run("case="+tc.input, func(t *testing.T) {
 			actual, ok := ParseCredentialsType(tc.input)
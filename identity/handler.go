@@ -45,6 +45,7 @@ const (
 	RouteCollection     = "/identities"
 	RouteItem           = RouteCollection + "/{id}"
 	RouteCredentialItem = RouteItem + "/credentials/{type}"
+	RouteLockoutItem    = RouteItem + "/lockout"
 
 	BatchPatchIdentitiesLimit             = 1000
 	BatchPatchIdentitiesWithPasswordLimit = 200
@@ -85,11 +86,14 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	h.r.CSRFHandler().IgnoreGlobs(
 		RouteCollection, RouteCollection+"/*",
 		RouteCollection+"/*/credentials/*",
+		RouteCollection+"/*/lockout",
 		x.AdminPrefix+RouteCollection, x.AdminPrefix+RouteCollection+"/*",
 		x.AdminPrefix+RouteCollection+"/*/credentials/*",
+		x.AdminPrefix+RouteCollection+"/*/lockout",
 	)
 
 	public.GET(RouteCollection, redir.RedirectToAdminRoute(h.r))
+	public.GET(RouteExport, redir.RedirectToAdminRoute(h.r))
 	public.GET(RouteCollection+"/by/external/{externalID}", redir.RedirectToAdminRoute(h.r))
 	public.GET(RouteItem, redir.RedirectToAdminRoute(h.r))
 	public.DELETE(RouteItem, redir.RedirectToAdminRoute(h.r))
@@ -97,8 +101,11 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	public.PUT(RouteItem, redir.RedirectToAdminRoute(h.r))
 	public.PATCH(RouteItem, redir.RedirectToAdminRoute(h.r))
 	public.DELETE(RouteCredentialItem, redir.RedirectToAdminRoute(h.r))
+	public.GET(RouteLockoutItem, redir.RedirectToAdminRoute(h.r))
+	public.DELETE(RouteLockoutItem, redir.RedirectToAdminRoute(h.r))
 
 	public.GET(x.AdminPrefix+RouteCollection, redir.RedirectToAdminRoute(h.r))
+	public.GET(x.AdminPrefix+RouteExport, redir.RedirectToAdminRoute(h.r))
 	public.GET(x.AdminPrefix+RouteCollection+"/by/external/{externalID}", redir.RedirectToAdminRoute(h.r))
 	public.GET(x.AdminPrefix+RouteItem, redir.RedirectToAdminRoute(h.r))
 	public.DELETE(x.AdminPrefix+RouteItem, redir.RedirectToAdminRoute(h.r))
@@ -106,10 +113,13 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	public.PUT(x.AdminPrefix+RouteItem, redir.RedirectToAdminRoute(h.r))
 	public.PATCH(x.AdminPrefix+RouteItem, redir.RedirectToAdminRoute(h.r))
 	public.DELETE(x.AdminPrefix+RouteCredentialItem, redir.RedirectToAdminRoute(h.r))
+	public.GET(x.AdminPrefix+RouteLockoutItem, redir.RedirectToAdminRoute(h.r))
+	public.DELETE(x.AdminPrefix+RouteLockoutItem, redir.RedirectToAdminRoute(h.r))
 }
 
 func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
 	admin.GET(RouteCollection, h.list)
+	admin.GET(RouteExport, h.export)
 	admin.GET(RouteItem, h.get)
 	admin.GET(RouteCollection+"/by/external/{externalID}", h.getByExternalID)
 	admin.DELETE(RouteItem, h.delete)
@@ -120,6 +130,9 @@ func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
 	admin.PUT(RouteItem, h.update)
 
 	admin.DELETE(RouteCredentialItem, h.deleteIdentityCredentials)
+
+	admin.GET(RouteLockoutItem, h.getIdentityLockout)
+	admin.DELETE(RouteLockoutItem, h.deleteIdentityLockout)
 }
 
 // Paginated Identity List Response
@@ -603,6 +616,24 @@ type AdminCreateIdentityImportCredentialsOIDCProvider struct {
 
 	// The organization to assign for the provider.
 	Organization uuid.NullUUID `json:"organization,omitempty"`
+
+	// The encrypted initial OpenID Connect ID Token, as exported by `GET /admin/identities/export`.
+	// It can only be imported into a deployment sharing the same cipher secrets.
+	//
+	// required: false
+	InitialIDToken string `json:"initial_id_token,omitempty"`
+
+	// The encrypted initial OAuth 2.0 Access Token, as exported by `GET /admin/identities/export`.
+	// It can only be imported into a deployment sharing the same cipher secrets.
+	//
+	// required: false
+	InitialAccessToken string `json:"initial_access_token,omitempty"`
+
+	// The encrypted initial OAuth 2.0 Refresh Token, as exported by `GET /admin/identities/export`.
+	// It can only be imported into a deployment sharing the same cipher secrets.
+	//
+	// required: false
+	InitialRefreshToken string `json:"initial_refresh_token,omitempty"`
 }
 
 // Payload to import SAML credentials
@@ -1206,7 +1237,7 @@ func (h *Handler) deleteIdentityCredentials(w http.ResponseWriter, r *http.Reque
 			h.r.Writer().WriteError(w, r, err)
 			return
 		}
-	case CredentialsTypePassword, CredentialsTypeOIDC, CredentialsTypeSAML:
+	case CredentialsTypePassword, CredentialsTypeOIDC, CredentialsTypeSAML, CredentialsTypeLDAP:
 		firstFactor, err := h.r.IdentityManager().CountActiveFirstFactorCredentials(ctx, identity)
 		if err != nil {
 			h.r.Writer().WriteError(w, r, err)
@@ -1227,6 +1258,8 @@ func (h *Handler) deleteIdentityCredentials(w http.ResponseWriter, r *http.Reque
 				h.r.Writer().WriteError(w, r, err)
 				return
 			}
+		case CredentialsTypeLDAP:
+			identity.DeleteCredentialsType(cred.Type)
 		}
 	default:
 		// A bunch of credential type deletions are not yet implemented, e.g. passkeys, etc.
@@ -1245,3 +1278,83 @@ func (h *Handler) deleteIdentityCredentials(w http.ResponseWriter, r *http.Reque
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Identity Lockout Parameters
+//
+// swagger:parameters getIdentityLockout deleteIdentityLockout
+type _ struct {
+	// ID is the identity's ID.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:route GET /admin/identities/{id}/lockout identity getIdentityLockout
+//
+// # Get the lockout state of an identity
+//
+// Returns the number of consecutive failed authentications of an
+// [identity](https://www.ory.sh/docs/kratos/concepts/identity-user-model) and whether it is
+// temporarily locked because of too many failed password, TOTP, or lookup secret authentications.
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: identityLockout
+//	  404: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) getIdentityLockout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	i, err := h.r.PrivilegedIdentityPool().GetIdentity(ctx, x.ParseUUID(r.PathValue("id")), ExpandNothing)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	l, err := h.r.IdentityManager().GetLockout(ctx, i.ID)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.Writer().Write(w, r, l)
+}
+
+// swagger:route DELETE /admin/identities/{id}/lockout identity deleteIdentityLockout
+//
+// # Unlock an identity
+//
+// Clears the lockout state of an [identity](https://www.ory.sh/docs/kratos/concepts/identity-user-model),
+// which unlocks the identity and resets its number of consecutive failed authentications.
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  204: emptyResponse
+//	  404: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) deleteIdentityLockout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	i, err := h.r.PrivilegedIdentityPool().GetIdentity(ctx, x.ParseUUID(r.PathValue("id")), ExpandNothing)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if err := h.r.IdentityManager().Unlock(ctx, i.ID); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
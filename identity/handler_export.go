@@ -0,0 +1,229 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package identity
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/crdbx"
+	"github.com/ory/x/pagination/keysetpagination"
+)
+
+const RouteExport = RouteCollection + "/export"
+
+// Export Identities Parameters
+//
+// swagger:parameters exportIdentities
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type exportIdentities struct {
+	keysetpagination.RequestParameters
+
+	// Include Credentials in Export
+	//
+	// Set to `password` to export hashed passwords, and to `oidc` to export the encrypted initial OAuth 2.0 Access
+	// Token, OAuth 2.0 Refresh Token and OpenID Connect ID Token. Social sign in and SAML connections are always
+	// exported.
+	//
+	// required: false
+	// in: query
+	IncludeCredentials []CredentialsType `json:"include_credential"`
+
+	crdbx.ConsistencyRequestParameters
+}
+
+// Exported Identities
+//
+// swagger:response exportIdentities
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type exportIdentitiesResponse struct {
+	keysetpagination.ResponseHeaders
+
+	// The identities, one JSON object per line.
+	//
+	// in:body
+	Body []CreateIdentityBody
+}
+
+// swagger:route GET /admin/identities/export identity exportIdentities
+//
+// # Export Identities
+//
+// Exports a page of identities as newline-delimited JSON. Every line is a request body of the
+// `createIdentity` endpoint, so that the export can be imported again using `kratos import identities`.
+//
+// Identities are exported in a stable order. Follow the `Link` header to export the next page, or use
+// its page token to resume an interrupted export.
+//
+// Only password, social sign in and SAML credentials can be exported. Exported OpenID Connect tokens
+// remain encrypted and can only be used by a deployment sharing the same cipher secrets.
+//
+//	Produces:
+//	- application/x-ndjson
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  200: exportIdentities
+//	  400: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) export(w http.ResponseWriter, r *http.Request) {
+	params := ListIdentityParameters{
+		Expand:           ExpandEverything,
+		ConsistencyLevel: crdbx.ConsistencyLevelFromRequest(r),
+	}
+
+	var include []CredentialsType
+	for _, v := range r.URL.Query()["include_credential"] {
+		tc, ok := ParseCredentialsType(v)
+		if !ok || (tc != CredentialsTypePassword && tc != CredentialsTypeOIDC) {
+			h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Invalid value `%s` for parameter `include_credential`. Only `password` and `oidc` can be exported.", v)))
+			return
+		}
+		include = append(include, tc)
+	}
+
+	var err error
+	params.KeySetPagination, err = keysetpagination.Parse(r.URL.Query(), keysetpagination.NewStringPageToken)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReason(err.Error())))
+		return
+	}
+
+	is, nextPage, err := h.r.IdentityPool().ListIdentities(r.Context(), params)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	bodies := make([]*CreateIdentityBody, len(is))
+	for k := range is {
+		bodies[k], err = NewCreateIdentityBody(&is[k], include)
+		if err != nil {
+			h.r.Writer().WriteError(w, r, err)
+			return
+		}
+	}
+
+	if nextPage != nil {
+		u := *r.URL
+		keysetpagination.Header(w, &u, nextPage)
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for _, body := range bodies {
+		// The status code has already been sent. Clients detect an incomplete page by the missing lines
+		// and resume the export from the page token.
+		if err := enc.Encode(body); err != nil {
+			return
+		}
+	}
+}
+
+// NewCreateIdentityBody returns the body to import the identity with the createIdentity endpoint. Password
+// hashes and encrypted OpenID Connect tokens are only exported if their credentials type is included.
+func NewCreateIdentityBody(i *Identity, includeCredentials []CredentialsType) (*CreateIdentityBody, error) {
+	body := &CreateIdentityBody{
+		SchemaID:            i.SchemaID,
+		Traits:              json.RawMessage(i.Traits),
+		VerifiableAddresses: make([]VerifiableAddress, len(i.VerifiableAddresses)),
+		RecoveryAddresses:   make([]RecoveryAddress, len(i.RecoveryAddresses)),
+		MetadataPublic:      json.RawMessage(i.MetadataPublic),
+		MetadataAdmin:       json.RawMessage(i.MetadataAdmin),
+		State:               i.State,
+		OrganizationID:      i.OrganizationID,
+		ExternalID:          string(i.ExternalID),
+	}
+
+	// Addresses get new IDs when they are imported.
+	for k, a := range i.VerifiableAddresses {
+		a.ID, a.IdentityID, a.NID = uuid.Nil, uuid.Nil, uuid.Nil
+		body.VerifiableAddresses[k] = a
+	}
+	for k, a := range i.RecoveryAddresses {
+		a.ID, a.IdentityID, a.NID = uuid.Nil, uuid.Nil, uuid.Nil
+		body.RecoveryAddresses[k] = a
+	}
+
+	var creds IdentityWithCredentials
+	if c, ok := i.GetCredentials(CredentialsTypePassword); ok && slices.Contains(includeCredentials, CredentialsTypePassword) {
+		var conf CredentialsPassword
+		if err := json.Unmarshal(c.Config, &conf); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if conf.HashedPassword != "" || conf.UsePasswordMigrationHook {
+			creds.Password = &AdminIdentityImportCredentialsPassword{Config: AdminIdentityImportCredentialsPasswordConfig{
+				HashedPassword:           conf.HashedPassword,
+				UsePasswordMigrationHook: conf.ShouldUsePasswordMigrationHook(),
+			}}
+		}
+	}
+
+	if c, ok := i.GetCredentials(CredentialsTypeOIDC); ok {
+		var conf CredentialsOIDC
+		if err := json.Unmarshal(c.Config, &conf); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		includeTokens := slices.Contains(includeCredentials, CredentialsTypeOIDC)
+		creds.OIDC = &AdminIdentityImportCredentialsOIDC{}
+		for _, p := range conf.Providers {
+			provider := AdminCreateIdentityImportCredentialsOIDCProvider{
+				Subject:      p.Subject,
+				Provider:     p.Provider,
+				UseAutoLink:  p.UseAutoLink,
+				Organization: nullUUID(p.Organization),
+			}
+			if includeTokens {
+				provider.InitialIDToken = p.InitialIDToken
+				provider.InitialAccessToken = p.InitialAccessToken
+				provider.InitialRefreshToken = p.InitialRefreshToken
+			}
+			creds.OIDC.Config.Providers = append(creds.OIDC.Config.Providers, provider)
+		}
+	}
+
+	if c, ok := i.GetCredentials(CredentialsTypeSAML); ok {
+		var conf CredentialsOIDC
+		if err := json.Unmarshal(c.Config, &conf); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		creds.SAML = &AdminIdentityImportCredentialsSAML{}
+		for _, p := range conf.Providers {
+			creds.SAML.Config.Providers = append(creds.SAML.Config.Providers, AdminCreateIdentityImportCredentialsSAMLProvider{
+				Subject:      p.Subject,
+				Provider:     p.Provider,
+				Organization: nullUUID(p.Organization),
+			})
+		}
+	}
+
+	if creds.Password != nil || creds.OIDC != nil || creds.SAML != nil {
+		body.Credentials = &creds
+	}
+
+	return body, nil
+}
+
+func nullUUID(s string) uuid.NullUUID {
+	id, err := uuid.FromString(s)
+	if err != nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: id, Valid: true}
+}
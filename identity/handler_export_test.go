@@ -0,0 +1,152 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package identity_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/x/pagination/keysetpagination"
+	"github.com/ory/x/sqlxx"
+)
+
+func TestHandlerExport(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	_, adminTS := testhelpers.NewKratosServerWithCSRF(t, reg)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/identity.schema.json")
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	hashedPassword := string(hashed)
+	idToken, err := reg.Cipher(ctx).Encrypt(ctx, []byte("id-token"))
+	require.NoError(t, err)
+
+	var ids []uuid.UUID
+	for k := range 3 {
+		i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+		i.Traits = identity.Traits(fmt.Sprintf(`{"email":"export-%d@ory.sh"}`, k))
+		i.MetadataAdmin = sqlxx.NullJSONRawMessage(`{"admin":true}`)
+		i.ExternalID = sqlxx.NullString(fmt.Sprintf("external-%d", k))
+		require.NoError(t, i.SetCredentialsWithConfig(identity.CredentialsTypePassword,
+			identity.Credentials{Identifiers: []string{fmt.Sprintf("export-%d@ory.sh", k)}},
+			identity.CredentialsPassword{HashedPassword: hashedPassword}))
+		require.NoError(t, i.SetCredentialsWithConfig(identity.CredentialsTypeOIDC,
+			identity.Credentials{Identifiers: []string{identity.OIDCUniqueID("google", fmt.Sprintf("subject-%d", k))}},
+			identity.CredentialsOIDC{Providers: []identity.CredentialsOIDCProvider{{
+				Provider:       "google",
+				Subject:        fmt.Sprintf("subject-%d", k),
+				InitialIDToken: idToken,
+			}}}))
+		require.NoError(t, reg.IdentityManager().Create(ctx, i))
+		ids = append(ids, i.ID)
+	}
+
+	export := func(t *testing.T, query string) ([]gjson.Result, *http.Response) {
+		res, err := adminTS.Client().Get(adminTS.URL + "/admin/identities/export?" + query)
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+
+		var lines []gjson.Result
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			require.True(t, gjson.ValidBytes(scanner.Bytes()), "%s", scanner.Bytes())
+			lines = append(lines, gjson.ParseBytes(scanner.Bytes()))
+		}
+		require.NoError(t, scanner.Err())
+		return lines, res
+	}
+
+	t.Run("case=exports identities without secrets by default", func(t *testing.T) {
+		lines, _ := export(t, "")
+		require.Len(t, lines, 3)
+
+		for _, l := range lines {
+			assert.Equal(t, config.DefaultIdentityTraitsSchemaID, l.Get("schema_id").String(), l.Raw)
+			assert.True(t, l.Get("metadata_admin.admin").Bool(), l.Raw)
+			assert.False(t, l.Get("id").Exists(), l.Raw)
+			assert.Nil(t, l.Get("credentials.password").Value(), l.Raw)
+			assert.Equal(t, "google", l.Get("credentials.oidc.config.providers.0.provider").String(), l.Raw)
+			assert.False(t, l.Get("credentials.oidc.config.providers.0.initial_id_token").Exists(), l.Raw)
+		}
+	})
+
+	t.Run("case=exports pages", func(t *testing.T) {
+		first, res := export(t, "page_size=2")
+		require.Len(t, first, 2)
+
+		next := keysetpagination.ParseHeader(res).NextToken
+		require.NotEmpty(t, next)
+
+		second, res := export(t, "page_size=2&page_token="+next)
+		require.Len(t, second, 1)
+		assert.Empty(t, keysetpagination.ParseHeader(res).NextToken)
+
+		var externalIDs []string
+		for _, l := range append(first, second...) {
+			externalIDs = append(externalIDs, l.Get("external_id").String())
+		}
+		assert.ElementsMatch(t, []string{"external-0", "external-1", "external-2"}, externalIDs)
+	})
+
+	t.Run("case=rejects credentials which can not be exported", func(t *testing.T) {
+		res, err := adminTS.Client().Get(adminTS.URL + "/admin/identities/export?include_credential=webauthn")
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("case=exported identities can be imported", func(t *testing.T) {
+		lines, _ := export(t, "include_credential=password&include_credential=oidc")
+		require.Len(t, lines, 3)
+
+		for _, id := range ids {
+			require.NoError(t, reg.PrivilegedIdentityPool().DeleteIdentity(ctx, id))
+		}
+
+		for _, l := range lines {
+			assert.Equal(t, hashedPassword, l.Get("credentials.password.config.hashed_password").String(), l.Raw)
+			assert.Equal(t, idToken, l.Get("credentials.oidc.config.providers.0.initial_id_token").String(), l.Raw)
+
+			res, err := adminTS.Client().Post(adminTS.URL+"/admin/identities", "application/json", bytes.NewBufferString(l.Raw))
+			require.NoError(t, err)
+			var created identity.Identity
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&created))
+			require.NoError(t, res.Body.Close())
+			require.Equal(t, http.StatusCreated, res.StatusCode)
+
+			imported, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, created.ID)
+			require.NoError(t, err)
+			assert.Equal(t, l.Get("external_id").String(), string(imported.ExternalID))
+			assert.JSONEq(t, `{"admin":true}`, string(imported.MetadataAdmin))
+
+			password, ok := imported.GetCredentials(identity.CredentialsTypePassword)
+			require.True(t, ok)
+			assert.Equal(t, hashedPassword, gjson.GetBytes(password.Config, "hashed_password").String())
+
+			oidc, ok := imported.GetCredentials(identity.CredentialsTypeOIDC)
+			require.True(t, ok)
+			plaintext, err := reg.Cipher(ctx).Decrypt(ctx, gjson.GetBytes(oidc.Config, "providers.0.initial_id_token").String())
+			require.NoError(t, err)
+			assert.Equal(t, "id-token", string(plaintext))
+		}
+	})
+}
@@ -82,9 +82,12 @@ func (h *Handler) importOIDCCredentials(_ context.Context, i *Identity, creds *A
 		for _, p := range creds.Config.Providers {
 			ids = append(ids, OIDCUniqueID(p.Provider, p.Subject))
 			provider := CredentialsOIDCProvider{
-				Subject:     p.Subject,
-				Provider:    p.Provider,
-				UseAutoLink: p.UseAutoLink,
+				Subject:             p.Subject,
+				Provider:            p.Provider,
+				UseAutoLink:         p.UseAutoLink,
+				InitialIDToken:      p.InitialIDToken,
+				InitialAccessToken:  p.InitialAccessToken,
+				InitialRefreshToken: p.InitialRefreshToken,
 			}
 			if p.Organization.Valid {
 				provider.Organization = p.Organization.UUID.String()
@@ -106,9 +109,12 @@ func (h *Handler) importOIDCCredentials(_ context.Context, i *Identity, creds *A
 	for _, p := range creds.Config.Providers {
 		c.Identifiers = append(c.Identifiers, OIDCUniqueID(p.Provider, p.Subject))
 		provider := CredentialsOIDCProvider{
-			Subject:     p.Subject,
-			Provider:    p.Provider,
-			UseAutoLink: p.UseAutoLink,
+			Subject:             p.Subject,
+			Provider:            p.Provider,
+			UseAutoLink:         p.UseAutoLink,
+			InitialIDToken:      p.InitialIDToken,
+			InitialAccessToken:  p.InitialAccessToken,
+			InitialRefreshToken: p.InitialRefreshToken,
 		}
 		if p.Organization.Valid {
 			provider.Organization = p.Organization.UUID.String()
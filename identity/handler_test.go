@@ -511,6 +511,39 @@ func TestHandler(t *testing.T) {
 		assert.Contains(t, res.Get("error.reason").String(), "Invalid UUID value `not-a-uuid` for parameter `ids`.", "%s", res.Raw)
 	})
 
+	t.Run("case=should get and clear the lockout of an identity", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySecurityAccountLockout, map[string]any{"enabled": true, "max_failed_attempts": 2})
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeySecurityAccountLockout+".enabled", false) })
+
+		i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+
+		res := get(t, adminTS, "/identities/"+i.ID.String()+"/lockout", http.StatusOK)
+		assert.Equal(t, i.ID.String(), res.Get("identity_id").String(), "%s", res.Raw)
+		assert.False(t, res.Get("locked").Bool(), "%s", res.Raw)
+		assert.EqualValues(t, 0, res.Get("failed_attempts").Int(), "%s", res.Raw)
+
+		for range 2 {
+			require.NoError(t, reg.IdentityManager().RecordFailedAuthentication(ctx, i.ID))
+		}
+
+		res = get(t, adminTS, "/identities/"+i.ID.String()+"/lockout", http.StatusOK)
+		assert.True(t, res.Get("locked").Bool(), "%s", res.Raw)
+		assert.EqualValues(t, 1, res.Get("lockouts").Int(), "%s", res.Raw)
+		assert.True(t, res.Get("locked_until").Exists(), "%s", res.Raw)
+
+		remove(t, adminTS, "/identities/"+i.ID.String()+"/lockout", http.StatusNoContent)
+
+		res = get(t, adminTS, "/identities/"+i.ID.String()+"/lockout", http.StatusOK)
+		assert.False(t, res.Get("locked").Bool(), "%s", res.Raw)
+		assert.EqualValues(t, 0, res.Get("lockouts").Int(), "%s", res.Raw)
+
+		t.Run("case=unknown identity", func(t *testing.T) {
+			get(t, adminTS, "/identities/"+x.NewUUID().String()+"/lockout", http.StatusNotFound)
+			remove(t, adminTS, "/identities/"+x.NewUUID().String()+"/lockout", http.StatusNotFound)
+		})
+	})
+
 	t.Run("suite=create and update", func(t *testing.T) {
 		var i identity.Identity
 		createOIDCorSAMLIdentity := func(t *testing.T, ct identity.CredentialsType, identifier, accessToken, refreshToken, idToken string, encrypt bool) string {
@@ -506,6 +506,13 @@ func (i *Identity) WithDeclassifiedCredentials(ctx context.Context, c cipher.Pro
 							return false
 						}
 					}
+
+					if expiresAt := v.Get("access_token_expires_at"); expiresAt.Exists() {
+						toPublish.Config, err = sjson.SetBytes(toPublish.Config, fmt.Sprintf("providers.%d.access_token_expires_at", i), expiresAt.String())
+						if err != nil {
+							return false
+						}
+					}
 				}
 
 				toPublish.Config, err = sjson.SetBytes(toPublish.Config, fmt.Sprintf("providers.%d.subject", i), v.Get("subject").String())
@@ -19,6 +19,11 @@ const (
 	VerifiableAddressStatusPending   VerifiableAddressStatus = "pending"
 	VerifiableAddressStatusSent      VerifiableAddressStatus = "sent"
 	VerifiableAddressStatusCompleted VerifiableAddressStatus = "completed"
+
+	// VerifiableAddressStatusSuppressed is used for addresses to which the
+	// courier does not send messages, because earlier messages could not be
+	// delivered.
+	VerifiableAddressStatusSuppressed VerifiableAddressStatus = "suppressed"
 )
 
 // VerifiableAddressType must not exceed 16 characters as that is the limitation in the SQL Schema
@@ -60,7 +65,7 @@ type VerifiableAddress struct {
 
 	// The verified address status
 	//
-	// enum: pending,sent,completed
+	// enum: pending,sent,completed,suppressed
 	// example: sent
 	// required: true
 	Status VerifiableAddressStatus `json:"status" db:"status"`
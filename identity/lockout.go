@@ -0,0 +1,217 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package identity
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/x/events"
+	"github.com/ory/x/otelx"
+	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
+)
+
+// Lockout tracks the consecutive failed authentications of an identity.
+//
+// swagger:model identityLockout
+type Lockout struct {
+	ID  uuid.UUID `json:"-" faker:"-" db:"id"`
+	NID uuid.UUID `json:"-" faker:"-" db:"nid"`
+
+	// IdentityID is the ID of the identity.
+	//
+	// required: true
+	IdentityID uuid.UUID `json:"identity_id" faker:"-" db:"identity_id"`
+
+	// Locked is true if the identity is currently locked.
+	//
+	// required: true
+	Locked bool `json:"locked" db:"-"`
+
+	// FailedAttempts is the number of consecutive failed authentications
+	// since the last successful authentication or lockout.
+	//
+	// required: true
+	FailedAttempts int `json:"failed_attempts" db:"failed_attempts"`
+
+	// Lockouts is the number of times the identity was locked since the last
+	// successful authentication. It determines the duration of the next
+	// lockout.
+	//
+	// required: true
+	Lockouts int `json:"lockouts" db:"lockouts"`
+
+	// LockedUntil is the time until which the identity is locked.
+	LockedUntil sqlxx.NullTime `json:"locked_until,omitempty" faker:"-" db:"locked_until"`
+
+	// CreatedAt is a helper struct field for gobuffalo.pop.
+	CreatedAt time.Time `json:"-" faker:"-" db:"created_at"`
+
+	// UpdatedAt is a helper struct field for gobuffalo.pop.
+	UpdatedAt time.Time `json:"-" faker:"-" db:"updated_at"`
+}
+
+func (Lockout) TableName() string { return "identity_lockouts" }
+
+// IsLocked returns true if the identity is locked at the given time.
+func (l *Lockout) IsLocked(at time.Time) bool {
+	return l != nil && time.Time(l.LockedUntil).After(at)
+}
+
+type (
+	LockoutPersister interface {
+		// IncrementIdentityFailedAuthentications increments the number of
+		// consecutive failed authentications of the identity.
+		IncrementIdentityFailedAuthentications(ctx context.Context, identityID uuid.UUID) (*Lockout, error)
+
+		// LockIdentity locks the identity until the given time if it has at
+		// least maxFailedAttempts consecutive failed authentications, and
+		// resets the counter. It returns false if the identity was not locked,
+		// for example because a concurrent request has locked it already.
+		LockIdentity(ctx context.Context, identityID uuid.UUID, maxFailedAttempts int, until time.Time) (bool, error)
+
+		// GetIdentityLockout returns the lockout state of the identity or
+		// sqlcon.ErrNoRows if the identity has no failed authentications.
+		GetIdentityLockout(ctx context.Context, identityID uuid.UUID) (*Lockout, error)
+		DeleteIdentityLockout(ctx context.Context, identityID uuid.UUID) error
+	}
+
+	LockoutPersistenceProvider interface {
+		IdentityLockoutPersister() LockoutPersister
+	}
+)
+
+// lockoutDuration returns the duration of the n-th lockout, doubling the base
+// duration for every previous lockout.
+func lockoutDuration(base, maxDuration time.Duration, n int) time.Duration {
+	d := base
+	for i := 1; i < n && d < maxDuration; i++ {
+		d *= 2
+	}
+	return min(d, maxDuration)
+}
+
+// ActiveLockout returns the lockout of the identity if it is currently
+// locked, and nil otherwise.
+func (m *Manager) ActiveLockout(ctx context.Context, id uuid.UUID) (_ *Lockout, err error) {
+	ctx, span := m.r.Tracer(ctx).Tracer().Start(ctx, "identity.Manager.ActiveLockout")
+	defer otelx.End(span, &err)
+
+	if !m.r.Config().SecurityAccountLockout(ctx).Enabled {
+		return nil, nil
+	}
+
+	l, err := m.r.IdentityLockoutPersister().GetIdentityLockout(ctx, id)
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !l.IsLocked(time.Now()) {
+		return nil, nil
+	}
+
+	l.Locked = true
+	return l, nil
+}
+
+// CheckLockout returns a validation error if the identity is currently
+// locked. Strategies call it before verifying a credential. If account
+// enumeration mitigation is enabled, the error is the same as for unknown
+// identifiers and wrong credentials, so that it does not reveal that the
+// account exists.
+func (m *Manager) CheckLockout(ctx context.Context, id uuid.UUID) error {
+	l, err := m.ActiveLockout(ctx, id)
+	if err != nil {
+		return err
+	} else if l == nil {
+		return nil
+	} else if m.r.Config().SecurityAccountEnumerationMitigate(ctx) {
+		return errors.WithStack(schema.NewInvalidCredentialsError())
+	}
+	return errors.WithStack(schema.NewIdentityLockedError(time.Time(l.LockedUntil)))
+}
+
+// RecordFailedAuthentication counts a failed authentication of the identity
+// and locks the identity once the configured number of consecutive failed
+// authentications is reached.
+func (m *Manager) RecordFailedAuthentication(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := m.r.Tracer(ctx).Tracer().Start(ctx, "identity.Manager.RecordFailedAuthentication")
+	defer otelx.End(span, &err)
+
+	c := m.r.Config().SecurityAccountLockout(ctx)
+	if !c.Enabled || id == uuid.Nil {
+		return nil
+	}
+
+	l, err := m.r.IdentityLockoutPersister().IncrementIdentityFailedAuthentications(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if l.FailedAttempts < c.MaxFailedAttempts {
+		return nil
+	}
+
+	until := time.Now().UTC().Add(lockoutDuration(c.Duration, c.MaxDuration, l.Lockouts+1))
+	locked, err := m.r.IdentityLockoutPersister().LockIdentity(ctx, id, c.MaxFailedAttempts, until)
+	if err != nil {
+		return err
+	} else if !locked {
+		return nil
+	}
+
+	m.r.Logger().
+		WithField("identity_id", id).
+		WithField("locked_until", until).
+		Info("Locked identity because of too many consecutive failed authentications.")
+	trace.SpanFromContext(ctx).AddEvent(events.NewIdentityLocked(ctx, id, until))
+
+	return nil
+}
+
+// ResetFailedAuthentications forgets the failed authentications of the
+// identity. It is called after a successful authentication.
+func (m *Manager) ResetFailedAuthentications(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := m.r.Tracer(ctx).Tracer().Start(ctx, "identity.Manager.ResetFailedAuthentications")
+	defer otelx.End(span, &err)
+
+	if !m.r.Config().SecurityAccountLockout(ctx).Enabled {
+		return nil
+	}
+
+	return m.r.IdentityLockoutPersister().DeleteIdentityLockout(ctx, id)
+}
+
+// GetLockout returns the lockout state of the identity, regardless of
+// whether account lockout is enabled.
+func (m *Manager) GetLockout(ctx context.Context, id uuid.UUID) (_ *Lockout, err error) {
+	ctx, span := m.r.Tracer(ctx).Tracer().Start(ctx, "identity.Manager.GetLockout")
+	defer otelx.End(span, &err)
+
+	l, err := m.r.IdentityLockoutPersister().GetIdentityLockout(ctx, id)
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		return &Lockout{IdentityID: id}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	l.Locked = l.IsLocked(time.Now())
+	return l, nil
+}
+
+// Unlock clears the lockout state of the identity.
+func (m *Manager) Unlock(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := m.r.Tracer(ctx).Tracer().Start(ctx, "identity.Manager.Unlock")
+	defer otelx.End(span, &err)
+
+	return m.r.IdentityLockoutPersister().DeleteIdentityLockout(ctx, id)
+}
@@ -0,0 +1,34 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package identity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ory/x/sqlxx"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	for n, expected := range map[int]time.Duration{
+		1:  5 * time.Minute,
+		2:  10 * time.Minute,
+		3:  20 * time.Minute,
+		5:  80 * time.Minute,
+		6:  2 * time.Hour,
+		50: 2 * time.Hour,
+	} {
+		assert.Equal(t, expected, lockoutDuration(5*time.Minute, 2*time.Hour, n), "lockout %d", n)
+	}
+}
+
+func TestLockoutIsLocked(t *testing.T) {
+	now := time.Now()
+	assert.False(t, (*Lockout)(nil).IsLocked(now))
+	assert.False(t, new(Lockout).IsLocked(now))
+	assert.False(t, (&Lockout{LockedUntil: sqlxx.NullTime(now.Add(-time.Minute))}).IsLocked(now))
+	assert.True(t, (&Lockout{LockedUntil: sqlxx.NullTime(now.Add(time.Minute))}).IsLocked(now))
+}
@@ -38,6 +38,7 @@ type (
 		courier.Provider
 		ValidationProvider
 		ActiveCredentialsCounterStrategyProvider
+		LockoutPersistenceProvider
 		x.LoggingProvider
 	}
 	ManagementProvider interface {
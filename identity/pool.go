@@ -150,6 +150,20 @@ type (
 
 		// FindIdentityByCredentialsIdentifier returns an identity by its external ID.
 		FindIdentityByExternalID(ctx context.Context, externalID string, expand sqlxx.Expandables) (*Identity, error)
+
+		// ListCredentialsByType lists up to limit credentials of the given type, ordered by their ID and
+		// starting after the credentials with the ID after. The identifiers of the credentials are not loaded.
+		ListCredentialsByType(ctx context.Context, ct CredentialsType, after uuid.UUID, limit int) ([]Credentials, error)
+
+		// ListIdentitiesWithAdminMetadataKey lists the identities whose admin metadata contains an object at the key,
+		// ordered by their ID. It skips the first offset identities, returns at most limit identities, and also
+		// returns the total number of matching identities. If the organization ID is not nil, only identities
+		// of the organization are listed.
+		ListIdentitiesWithAdminMetadataKey(ctx context.Context, key string, organizationID uuid.UUID, offset, limit int) ([]Identity, int64, error)
+
+		// UpdateCredentialsConfig updates the config of the credentials without touching the rest of the identity.
+		// It returns herodot.ErrConflict if the credentials were updated since they were loaded.
+		UpdateCredentialsConfig(ctx context.Context, c *Credentials) error
 	}
 )
 
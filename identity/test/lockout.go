@@ -0,0 +1,124 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/persistence"
+	"github.com/ory/x/sqlcon"
+)
+
+func TestLockoutPersister(ctx context.Context, p persistence.Persister) func(t *testing.T) {
+	return func(t *testing.T) {
+		nid, p := testhelpers.NewNetworkUnlessExisting(t, ctx, p)
+
+		createIdentity := func(t *testing.T) *identity.Identity {
+			var i identity.Identity
+			require.NoError(t, faker.FakeData(&i))
+			require.NoError(t, p.CreateIdentity(ctx, &i))
+			return &i
+		}
+
+		t.Run("case=returns no rows for identities without failed authentications", func(t *testing.T) {
+			i := createIdentity(t)
+			_, err := p.GetIdentityLockout(ctx, i.ID)
+			require.ErrorIs(t, err, sqlcon.ErrNoRows)
+		})
+
+		t.Run("case=counts and locks", func(t *testing.T) {
+			i := createIdentity(t)
+
+			for n := 1; n <= 3; n++ {
+				l, err := p.IncrementIdentityFailedAuthentications(ctx, i.ID)
+				require.NoError(t, err)
+				assert.Equal(t, n, l.FailedAttempts)
+				assert.Equal(t, nid, l.NID)
+				assert.Equal(t, i.ID, l.IdentityID)
+			}
+
+			locked, err := p.LockIdentity(ctx, i.ID, 4, time.Now().Add(time.Hour))
+			require.NoError(t, err)
+			assert.False(t, locked, "the threshold is not reached yet")
+
+			until := time.Now().Add(time.Hour)
+			locked, err = p.LockIdentity(ctx, i.ID, 3, until)
+			require.NoError(t, err)
+			assert.True(t, locked)
+
+			locked, err = p.LockIdentity(ctx, i.ID, 3, until)
+			require.NoError(t, err)
+			assert.False(t, locked, "the counter is reset by the lockout")
+
+			l, err := p.GetIdentityLockout(ctx, i.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 0, l.FailedAttempts)
+			assert.Equal(t, 1, l.Lockouts)
+			assert.WithinDuration(t, until, time.Time(l.LockedUntil), time.Second)
+			assert.True(t, l.IsLocked(time.Now()))
+
+			t.Run("case=other networks are not affected", func(t *testing.T) {
+				_, other := testhelpers.NewNetwork(t, ctx, p)
+				_, err := other.GetIdentityLockout(ctx, i.ID)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+
+			t.Run("case=deletes the lockout", func(t *testing.T) {
+				require.NoError(t, p.DeleteIdentityLockout(ctx, i.ID))
+				_, err := p.GetIdentityLockout(ctx, i.ID)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+		})
+
+		t.Run("case=only one concurrent request locks the identity", func(t *testing.T) {
+			i := createIdentity(t)
+			for range 5 {
+				_, err := p.IncrementIdentityFailedAuthentications(ctx, i.ID)
+				require.NoError(t, err)
+			}
+
+			var (
+				wg     sync.WaitGroup
+				mu     sync.Mutex
+				locked int
+			)
+			for range 5 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					ok, err := p.LockIdentity(ctx, i.ID, 5, time.Now().Add(time.Hour))
+					if !assert.NoError(t, err) {
+						return
+					}
+					if ok {
+						mu.Lock()
+						locked++
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+			assert.Equal(t, 1, locked)
+		})
+
+		t.Run("case=deleting the identity deletes the lockout", func(t *testing.T) {
+			i := createIdentity(t)
+			_, err := p.IncrementIdentityFailedAuthentications(ctx, i.ID)
+			require.NoError(t, err)
+
+			require.NoError(t, p.DeleteIdentity(ctx, i.ID))
+			_, err = p.GetIdentityLockout(ctx, i.ID)
+			require.ErrorIs(t, err, sqlcon.ErrNoRows)
+		})
+	}
+}
@@ -654,6 +654,109 @@ func TestPool(ctx context.Context, p persistence.Persister, m *identity.Manager,
 			require.Contains(t, err.Error(), "malformed")
 		})
 
+		t.Run("case=update credentials config", func(t *testing.T) {
+			initial := oidcIdentity("", x.NewUUID().String())
+			require.NoError(t, p.CreateIdentity(ctx, initial))
+			createdIDs = append(createdIDs, initial.ID)
+
+			loaded, err := p.GetIdentityConfidential(ctx, initial.ID)
+			require.NoError(t, err)
+			first, ok := loaded.GetCredentials(identity.CredentialsTypeOIDC)
+			require.True(t, ok)
+			second := *first
+
+			first.Config = sqlxx.JSONRawMessage(`{"providers":[],"first":true}`)
+			require.NoError(t, p.UpdateCredentialsConfig(ctx, first))
+
+			actual, err := p.GetIdentityConfidential(ctx, initial.ID)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"providers":[],"first":true}`, string(actual.Credentials[identity.CredentialsTypeOIDC].Config))
+
+			t.Run("fails if the credentials were updated concurrently", func(t *testing.T) {
+				second.Config = sqlxx.JSONRawMessage(`{"providers":[],"second":true}`)
+				require.ErrorIs(t, p.UpdateCredentialsConfig(ctx, &second), herodot.ErrConflict)
+
+				actual, err := p.GetIdentityConfidential(ctx, initial.ID)
+				require.NoError(t, err)
+				assert.JSONEq(t, `{"providers":[],"first":true}`, string(actual.Credentials[identity.CredentialsTypeOIDC].Config))
+			})
+
+			t.Run("succeeds again with the returned credentials", func(t *testing.T) {
+				first.Config = sqlxx.JSONRawMessage(`{"providers":[],"third":true}`)
+				require.NoError(t, p.UpdateCredentialsConfig(ctx, first))
+			})
+
+			t.Run("fails on different network", func(t *testing.T) {
+				_, p := testhelpers.NewNetwork(t, ctx, p)
+				require.Error(t, p.UpdateCredentialsConfig(ctx, first))
+			})
+		})
+
+		t.Run("case=list identities with admin metadata key", func(t *testing.T) {
+			key := "key-" + randx.MustString(8, randx.AlphaLowerNum)
+			org := uuid.NullUUID{UUID: x.NewUUID(), Valid: true}
+
+			var expected []uuid.UUID
+			for k, metadata := range []string{
+				`{"` + key + `":{"a":1}}`,
+				`{"` + key + `":{}}`,
+				`{"` + key + `":{"b":2},"other":true}`,
+				`{"` + key + `":"not-an-object"}`,
+				`{"other":{}}`,
+				``,
+			} {
+				i := oidcIdentity("", x.NewUUID().String())
+				if metadata != "" {
+					i.MetadataAdmin = sqlxx.NullJSONRawMessage(metadata)
+				}
+				if k == 2 {
+					i.OrganizationID = org
+				}
+				require.NoError(t, p.CreateIdentity(ctx, i))
+				createdIDs = append(createdIDs, i.ID)
+				if k < 3 {
+					expected = append(expected, i.ID)
+				}
+			}
+			slices.SortFunc(expected, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+
+			ids := func(is []identity.Identity) (ids []uuid.UUID) {
+				for _, i := range is {
+					ids = append(ids, i.ID)
+				}
+				return ids
+			}
+
+			is, total, err := p.ListIdentitiesWithAdminMetadataKey(ctx, key, uuid.Nil, 0, 10)
+			require.NoError(t, err)
+			assert.EqualValues(t, 3, total)
+			assert.Equal(t, expected, ids(is))
+
+			is, total, err = p.ListIdentitiesWithAdminMetadataKey(ctx, key, uuid.Nil, 1, 1)
+			require.NoError(t, err)
+			assert.EqualValues(t, 3, total)
+			assert.Equal(t, expected[1:2], ids(is))
+
+			is, total, err = p.ListIdentitiesWithAdminMetadataKey(ctx, key, uuid.Nil, 3, 10)
+			require.NoError(t, err)
+			assert.EqualValues(t, 3, total)
+			assert.Empty(t, is)
+
+			is, total, err = p.ListIdentitiesWithAdminMetadataKey(ctx, key, org.UUID, 0, 10)
+			require.NoError(t, err)
+			assert.EqualValues(t, 1, total)
+			require.Len(t, is, 1)
+			assert.Equal(t, org, is[0].OrganizationID)
+
+			t.Run("does not list identities of other networks", func(t *testing.T) {
+				_, p := testhelpers.NewNetwork(t, ctx, p)
+				is, total, err := p.ListIdentitiesWithAdminMetadataKey(ctx, key, uuid.Nil, 0, 10)
+				require.NoError(t, err)
+				assert.Zero(t, total)
+				assert.Empty(t, is)
+			})
+		})
+
 		t.Run("case=update an identity column", func(t *testing.T) {
 			initial := oidcIdentity("", x.NewUUID().String())
 			initial.InternalAvailableAAL = identity.NewNullableAuthenticatorAssuranceLevel(identity.NoAuthenticatorAssuranceLevel)
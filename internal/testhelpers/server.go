@@ -37,6 +37,7 @@ func NewKratosServerWithCSRFAndRouters(t *testing.T, reg driver.Registry) (publi
 
 	rpn := negroni.New()
 	rpn.UseFunc(x.HTTPLoaderContextMiddleware(reg))
+	rpn.UseFunc(x.AcceptLanguageContextMiddleware())
 	rpn.UseHandler(rp)
 
 	public = httptest.NewServer(nosurfx.NewTestCSRFHandler(rpn, reg))
@@ -5,6 +5,7 @@ package httpx
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
 	"log"
 	"net/http"
@@ -25,6 +26,7 @@ type resilientOptions struct {
 	retryMax             int
 	noInternalIPs        bool
 	internalIPExceptions []string
+	clientCertificates   []tls.Certificate
 }
 
 func newResilientOptions() *resilientOptions {
@@ -91,6 +93,14 @@ func ResilientClientAllowInternalIPRequestsTo(urlGlobs ...string) ResilientOptio
 	}
 }
 
+// ResilientClientWithClientCertificates presents the certificates to servers
+// which request TLS client authentication.
+func ResilientClientWithClientCertificates(certs ...tls.Certificate) ResilientOptions {
+	return func(o *resilientOptions) {
+		o.clientCertificates = certs
+	}
+}
+
 // NewResilientClient creates a new ResilientClient.
 func NewResilientClient(opts ...ResilientOptions) *retryablehttp.Client {
 	o := newResilientOptions()
@@ -98,14 +108,20 @@ func NewResilientClient(opts ...ResilientOptions) *retryablehttp.Client {
 		f(o)
 	}
 
+	allowInternal, prohibitInternal := allowInternalAllowIPv6, prohibitInternalAllowIPv6
+	if len(o.clientCertificates) > 0 {
+		allowInternal = newClientCertificateTransport(true, o.clientCertificates)
+		prohibitInternal = newClientCertificateTransport(false, o.clientCertificates)
+	}
+
 	if o.noInternalIPs {
 		o.c.Transport = &noInternalIPRoundTripper{
-			onWhitelist:          allowInternalAllowIPv6,
-			notOnWhitelist:       prohibitInternalAllowIPv6,
+			onWhitelist:          allowInternal,
+			notOnWhitelist:       prohibitInternal,
 			internalIPExceptions: o.internalIPExceptions,
 		}
 	} else {
-		o.c.Transport = allowInternalAllowIPv6
+		o.c.Transport = allowInternal
 	}
 
 	cl := retryablehttp.NewClient()
@@ -5,10 +5,13 @@ package httpx
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
 	"net"
 	"net/http"
 	"net/http/httptrace"
 	"net/netip"
+	"sync"
 	"time"
 
 	"code.dny.dev/ssrf"
@@ -81,7 +84,14 @@ func init() {
 
 func init() {
 	t, d := newDefaultTransport()
-	d.Control = ssrf.New(
+	d.Control = allowInternalIPv6Guardian().Safe
+	allowInternalAllowIPv6 = OTELTraceTransport(t)
+}
+
+// allowInternalIPv6Guardian allows private and loopback networks, but no
+// other special-purpose addresses.
+func allowInternalIPv6Guardian() *ssrf.Guardian {
+	return ssrf.New(
 		ssrf.WithAnyPort(),
 		ssrf.WithNetworks("tcp4", "tcp6"),
 		ssrf.WithAllowedV4Prefixes(
@@ -95,8 +105,83 @@ func init() {
 			netip.MustParsePrefix("::1/128"),  // Loopback (RFC 4193)
 			netip.MustParsePrefix("fc00::/7"), // Unique Local (RFC 4193)
 		),
-	).Safe
-	allowInternalAllowIPv6 = OTELTraceTransport(t)
+	)
+}
+
+// maxClientCertificateTransports bounds the number of cached client
+// certificate transports, so that rotated certificates do not leak transports.
+const maxClientCertificateTransports = 128
+
+type clientCertificateTransportKey struct {
+	allowInternal bool
+	fingerprint   [sha256.Size]byte
+}
+
+var (
+	clientCertificateTransportsMu sync.Mutex
+	clientCertificateTransports   = map[clientCertificateTransportKey]*clientCertificateTransport{}
+)
+
+type clientCertificateTransport struct {
+	t  *http.Transport
+	rt http.RoundTripper
+}
+
+// clientCertificateFingerprint identifies the certificate chains, so that
+// clients presenting the same certificates share a transport.
+func clientCertificateFingerprint(certs []tls.Certificate) [sha256.Size]byte {
+	h := sha256.New()
+	for _, c := range certs {
+		for _, der := range c.Certificate {
+			_, _ = h.Write(der)
+		}
+		_, _ = h.Write([]byte{0})
+	}
+
+	var fingerprint [sha256.Size]byte
+	h.Sum(fingerprint[:0])
+	return fingerprint
+}
+
+// newClientCertificateTransport returns a transport which presents the
+// certificates for TLS client authentication. Unlike the shared transports,
+// it is specific to the certificates, and is reused by all clients which
+// present the same certificates so that connections are pooled.
+func newClientCertificateTransport(allowInternal bool, certs []tls.Certificate) http.RoundTripper {
+	key := clientCertificateTransportKey{allowInternal: allowInternal, fingerprint: clientCertificateFingerprint(certs)}
+
+	clientCertificateTransportsMu.Lock()
+	defer clientCertificateTransportsMu.Unlock()
+
+	if cached, ok := clientCertificateTransports[key]; ok {
+		return cached.rt
+	}
+
+	if len(clientCertificateTransports) >= maxClientCertificateTransports {
+		for k, evicted := range clientCertificateTransports {
+			evicted.t.CloseIdleConnections()
+			delete(clientCertificateTransports, k)
+			break
+		}
+	}
+
+	t, d := newDefaultTransport()
+	t.TLSClientConfig = &tls.Config{
+		Certificates: certs,
+		MinVersion:   tls.VersionTLS12,
+	}
+	if allowInternal {
+		d.Control = allowInternalIPv6Guardian().Safe
+	} else {
+		d.Control = ssrf.New(
+			ssrf.WithAnyPort(),
+			ssrf.WithNetworks("tcp4", "tcp6"),
+		).Safe
+	}
+
+	cached := &clientCertificateTransport{t: t, rt: OTELTraceTransport(t)}
+	clientCertificateTransports[key] = cached
+	return cached.rt
 }
 
 func init() {
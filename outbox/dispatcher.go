@@ -0,0 +1,203 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/request"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/jsonnetsecure"
+	"github.com/ory/x/otelx"
+	"github.com/ory/x/sqlxx"
+)
+
+// leaseDuration is how long an event is reserved for the worker delivering
+// it. If the worker crashes, the event is delivered again afterwards.
+const leaseDuration = time.Minute
+
+type (
+	dispatcherDependencies interface {
+		config.Provider
+		PersistenceProvider
+		x.LoggingProvider
+		x.TracingProvider
+		x.HTTPClientProvider
+		jsonnetsecure.VMProvider
+	}
+
+	DispatcherProvider interface {
+		OutboxDispatcher() *Dispatcher
+	}
+
+	// Dispatcher delivers the events recorded in the outbox to the configured
+	// sinks.
+	Dispatcher struct {
+		d dispatcherDependencies
+	}
+
+	// delivery is the request body sent to the sinks, and the input of the
+	// sink's Jsonnet template.
+	delivery struct {
+		ID         uuid.UUID       `json:"id"`
+		Type       EventType       `json:"type"`
+		IdentityID uuid.UUID       `json:"identity_id"`
+		OccurredAt time.Time       `json:"occurred_at"`
+		Data       json.RawMessage `json:"data"`
+	}
+)
+
+func NewDispatcher(d dispatcherDependencies) *Dispatcher {
+	return &Dispatcher{d: d}
+}
+
+// Work delivers events until the context is canceled.
+func (d *Dispatcher) Work(ctx context.Context) error {
+	for {
+		if err := d.DispatchOutbox(ctx); err != nil {
+			d.d.Logger().WithError(err).Error("Unable to dispatch the outbox.")
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil
+			}
+			return ctx.Err()
+		case <-time.After(d.d.Config().OutboxWorkerPullWait(ctx)):
+		}
+	}
+}
+
+// DispatchOutbox delivers the next batch of due events. Failed deliveries
+// are retried with exponential backoff.
+func (d *Dispatcher) DispatchOutbox(ctx context.Context) (err error) {
+	ctx, span := d.d.Tracer(ctx).Tracer().Start(ctx, "outbox.Dispatcher.DispatchOutbox")
+	defer otelx.End(span, &err)
+
+	sinks, err := d.d.Config().OutboxSinks(ctx)
+	if err != nil {
+		return err
+	}
+
+	events, err := d.d.OutboxPersister().NextOutboxEvents(ctx, d.d.Config().OutboxWorkerPullCount(ctx), time.Now().Add(leaseDuration))
+	if err != nil {
+		return err
+	}
+	span.SetAttributes(attribute.Int("events_count", len(events)))
+
+	retry := d.d.Config().OutboxRetry(ctx)
+	for i := range events {
+		e := &events[i]
+		logger := d.d.Logger().
+			WithField("outbox_event_id", e.ID).
+			WithField("outbox_event_type", e.Type).
+			WithField("outbox_sink_id", e.SinkID).
+			WithField("identity_id", e.IdentityID)
+
+		e.SendCount++
+		if err := d.deliver(ctx, sinks, e); err != nil {
+			e.LastError = sqlxx.NullString(err.Error())
+			if e.SendCount >= retry.MaxAttempts {
+				e.Status = EventStatusAbandoned
+				logger.WithError(err).Error("Unable to deliver the outbox event, abandoning it.")
+			} else {
+				e.NextAttemptAt = time.Now().Add(backoff(retry, e.SendCount))
+				logger.WithError(err).Warn("Unable to deliver the outbox event, retrying later.")
+			}
+		} else {
+			e.Status = EventStatusDelivered
+			e.DeliveredAt = sqlxx.NullTime(time.Now().UTC())
+			logger.Debug("Delivered the outbox event.")
+		}
+
+		if err := d.d.OutboxPersister().UpdateOutboxEventDelivery(ctx, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, sinks []request.Config, e *Event) (err error) {
+	ctx, span := d.d.Tracer(ctx).Tracer().Start(ctx, "outbox.Dispatcher.deliver", trace.WithAttributes(
+		attribute.Stringer("outbox.event.id", e.ID),
+		attribute.String("outbox.event.type", string(e.Type)),
+		attribute.String("outbox.sink.id", e.SinkID),
+		attribute.Int("outbox.event.send_count", e.SendCount),
+	))
+	defer otelx.End(span, &err)
+
+	var sink *request.Config
+	for i := range sinks {
+		if sinks[i].ID == e.SinkID {
+			sink = &sinks[i]
+			break
+		}
+	}
+	if sink == nil {
+		return errors.Errorf("the sink %q is not configured", e.SinkID)
+	}
+	if sink.Method == "" {
+		sink.Method = "POST"
+	}
+
+	builder, err := request.NewBuilder(ctx, sink, d.d)
+	if err != nil {
+		return err
+	}
+
+	body := delivery{
+		ID:         e.ID,
+		Type:       e.Type,
+		IdentityID: e.IdentityID,
+		OccurredAt: e.CreatedAt,
+		Data:       json.RawMessage(e.Payload),
+	}
+	var req *retryablehttp.Request
+	if sink.TemplateURI != "" {
+		req, err = builder.BuildRequest(ctx, body)
+	} else {
+		req, err = builder.BuildRawRequest(body)
+	}
+	if err != nil {
+		return err
+	}
+
+	res, err := d.d.HTTPClient(ctx).Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		responseBody, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return errors.Errorf("the sink responded with status code %d: %s", res.StatusCode, responseBody)
+	}
+
+	return nil
+}
+
+// backoff returns the wait before the next attempt after the given number of
+// failed attempts.
+func backoff(c *config.OutboxRetry, attempts int) time.Duration {
+	wait := c.InitialInterval
+	for range attempts - 1 {
+		wait *= 2
+		if wait >= c.MaxInterval {
+			return c.MaxInterval
+		}
+	}
+	return min(wait, c.MaxInterval)
+}
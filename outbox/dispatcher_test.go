@@ -0,0 +1,92 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/outbox"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/configx"
+)
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+
+	var received []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "invalid event", http.StatusBadRequest)
+			return
+		}
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received = append(received, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	_, reg := internal.NewFastRegistryWithMocks(t, configx.WithValues(map[string]any{
+		config.ViperKeyOutboxSinks: []map[string]any{
+			{"id": "ok", "url": srv.URL + "/ok"},
+			{"id": "fail", "url": srv.URL + "/fail"},
+		},
+		config.ViperKeyOutboxRetry + ".max_attempts":     2,
+		config.ViperKeyOutboxRetry + ".initial_interval": "1m",
+	}))
+
+	identityID := x.NewUUID()
+	e, err := outbox.NewIdentityDeletedEvent(identityID)
+	require.NoError(t, err)
+	require.NoError(t, reg.OutboxPersister().AddOutboxEvents(ctx, e))
+
+	getEvent := func(t *testing.T, sinkID string) *outbox.Event {
+		events, _, err := reg.OutboxPersister().ListOutboxEvents(ctx, outbox.ListEventsParameters{SinkID: sinkID}, nil)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		return &events[0]
+	}
+
+	require.NoError(t, reg.OutboxDispatcher().DispatchOutbox(ctx))
+
+	t.Run("case=delivers the event", func(t *testing.T) {
+		require.Len(t, received, 1)
+		assert.Equal(t, string(outbox.EventTypeIdentityDeleted), received[0]["type"])
+		assert.Equal(t, identityID.String(), received[0]["identity_id"])
+		assert.Equal(t, map[string]any{"id": identityID.String()}, received[0]["data"])
+
+		delivered := getEvent(t, "ok")
+		assert.Equal(t, outbox.EventStatusDelivered, delivered.Status)
+		assert.Equal(t, 1, delivered.SendCount)
+		assert.NotZero(t, delivered.DeliveredAt)
+		assert.Equal(t, delivered.ID.String(), received[0]["id"])
+	})
+
+	t.Run("case=retries failed deliveries with backoff", func(t *testing.T) {
+		failed := getEvent(t, "fail")
+		assert.Equal(t, outbox.EventStatusPending, failed.Status)
+		assert.Equal(t, 1, failed.SendCount)
+		assert.Contains(t, failed.LastError, "400")
+		assert.Contains(t, failed.LastError, "invalid event")
+		assert.WithinDuration(t, time.Now().Add(time.Minute), failed.NextAttemptAt, 10*time.Second)
+
+		failed.NextAttemptAt = time.Now().Add(-time.Second)
+		require.NoError(t, reg.OutboxPersister().UpdateOutboxEventDelivery(ctx, failed))
+		require.NoError(t, reg.OutboxDispatcher().DispatchOutbox(ctx))
+
+		abandoned := getEvent(t, "fail")
+		assert.Equal(t, outbox.EventStatusAbandoned, abandoned.Status)
+		assert.Equal(t, 2, abandoned.SendCount)
+		assert.Len(t, received, 1, "the delivered event is not sent again")
+	})
+}
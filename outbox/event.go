@@ -0,0 +1,186 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/session"
+	keysetpagination "github.com/ory/x/pagination/keysetpagination_v2"
+	"github.com/ory/x/sqlxx"
+)
+
+// Outbox Event Type
+//
+// swagger:model outboxEventType
+type EventType string
+
+const (
+	EventTypeIdentityCreated EventType = "identity.created"
+	EventTypeIdentityUpdated EventType = "identity.updated"
+	EventTypeIdentityDeleted EventType = "identity.deleted"
+	EventTypeSessionIssued   EventType = "session.issued"
+	EventTypeSessionRevoked  EventType = "session.revoked"
+)
+
+// Outbox Event Status
+//
+// swagger:model outboxEventStatus
+type EventStatus string
+
+const (
+	// EventStatusPending events are waiting to be delivered or retried.
+	EventStatusPending EventStatus = "pending"
+	// EventStatusDelivered events were accepted by the sink.
+	EventStatusDelivered EventStatus = "delivered"
+	// EventStatusAbandoned events could not be delivered within the maximum
+	// number of attempts. They can be replayed using the admin API.
+	EventStatusAbandoned EventStatus = "abandoned"
+)
+
+func ToEventStatus(s string) (EventStatus, error) {
+	switch st := EventStatus(s); st {
+	case EventStatusPending, EventStatusDelivered, EventStatusAbandoned:
+		return st, nil
+	default:
+		return "", errors.WithStack(herodot.ErrBadRequest.WithReasonf("Outbox event status %q is not valid.", s))
+	}
+}
+
+// Outbox Event
+//
+// An outbox event records a change of an identity or session. It is written
+// in the same database transaction as the change and delivered to a sink by
+// the outbox dispatcher. Every sink receives its own copy of the event.
+//
+// swagger:model outboxEvent
+type Event struct {
+	// The event's ID. Sinks can use it to deduplicate deliveries.
+	//
+	// required: true
+	ID uuid.UUID `json:"id" faker:"-" db:"id"`
+
+	NID uuid.UUID `json:"-" faker:"-" db:"nid"`
+
+	// required: true
+	Type EventType `json:"type" db:"event_type"`
+
+	// The ID of the identity the event belongs to. Events of the same
+	// identity are delivered to a sink in the order they occurred.
+	//
+	// required: true
+	IdentityID uuid.UUID `json:"identity_id" faker:"-" db:"identity_id"`
+
+	// The ID of the sink the event is delivered to.
+	//
+	// required: true
+	SinkID string `json:"sink_id" db:"sink_id"`
+
+	// required: true
+	Payload sqlxx.JSONRawMessage `json:"payload" faker:"-" db:"payload"`
+
+	// required: true
+	Status EventStatus `json:"status" db:"status"`
+
+	// required: true
+	SendCount int `json:"send_count" db:"send_count"`
+
+	// The error of the last failed delivery attempt.
+	LastError sqlxx.NullString `json:"last_error" db:"last_error"`
+
+	// The time of the next delivery attempt of a pending event.
+	//
+	// required: true
+	NextAttemptAt time.Time `json:"next_attempt_at" faker:"-" db:"next_attempt_at"`
+
+	DeliveredAt sqlxx.NullTime `json:"delivered_at" faker:"-" db:"delivered_at"`
+
+	// CreatedAt is the time the event occurred.
+	//
+	// required: true
+	CreatedAt time.Time `json:"created_at" faker:"-" db:"created_at"`
+
+	// UpdatedAt is a helper struct field for gobuffalo.pop.
+	//
+	// required: true
+	UpdatedAt time.Time `json:"updated_at" faker:"-" db:"updated_at"`
+}
+
+func (Event) TableName() string { return "outbox_events" }
+
+func (e Event) PageToken() keysetpagination.PageToken {
+	return keysetpagination.NewPageToken(
+		keysetpagination.Column{
+			Name:  "created_at",
+			Order: keysetpagination.OrderDescending,
+			Value: e.CreatedAt,
+		}, keysetpagination.Column{
+			Name:  "id",
+			Value: e.ID,
+		},
+	)
+}
+
+func (e Event) DefaultPageToken() keysetpagination.PageToken {
+	return Event{ID: uuid.Nil, CreatedAt: time.Date(2200, 12, 31, 23, 59, 59, 0, time.UTC)}.PageToken()
+}
+
+type sessionPayload struct {
+	SessionID                   uuid.UUID                            `json:"session_id"`
+	IdentityID                  uuid.UUID                            `json:"identity_id"`
+	AuthenticatorAssuranceLevel identity.AuthenticatorAssuranceLevel `json:"authenticator_assurance_level,omitempty"`
+	AuthenticationMethods       session.AuthenticationMethods        `json:"authentication_methods,omitempty"`
+	ExpiresAt                   *time.Time                           `json:"expires_at,omitempty"`
+}
+
+// NewIdentityEvent returns an event containing the identity without its
+// credentials and admin metadata.
+func NewIdentityEvent(t EventType, i *identity.Identity) (*Event, error) {
+	payload, err := json.Marshal(i)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Event{Type: t, IdentityID: i.ID, Payload: payload}, nil
+}
+
+// NewIdentityDeletedEvent returns an event recording the deletion of the
+// identity.
+func NewIdentityDeletedEvent(id uuid.UUID) (*Event, error) {
+	payload, err := json.Marshal(map[string]uuid.UUID{"id": id})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Event{Type: EventTypeIdentityDeleted, IdentityID: id, Payload: payload}, nil
+}
+
+// NewSessionIssuedEvent returns an event recording the issuance of the session.
+func NewSessionIssuedEvent(s *session.Session) (*Event, error) {
+	payload, err := json.Marshal(sessionPayload{
+		SessionID:                   s.ID,
+		IdentityID:                  s.IdentityID,
+		AuthenticatorAssuranceLevel: s.AuthenticatorAssuranceLevel,
+		AuthenticationMethods:       s.AMR,
+		ExpiresAt:                   &s.ExpiresAt,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Event{Type: EventTypeSessionIssued, IdentityID: s.IdentityID, Payload: payload}, nil
+}
+
+// NewSessionRevokedEvent returns an event recording the revocation of the
+// session.
+func NewSessionRevokedEvent(sessionID, identityID uuid.UUID) (*Event, error) {
+	payload, err := json.Marshal(sessionPayload{SessionID: sessionID, IdentityID: identityID})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Event{Type: EventTypeSessionRevoked, IdentityID: identityID, Payload: payload}, nil
+}
@@ -0,0 +1,258 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	keysetpagination "github.com/ory/x/pagination/keysetpagination_v2"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/x"
+	"github.com/ory/kratos/x/nosurfx"
+	"github.com/ory/kratos/x/redir"
+)
+
+const (
+	AdminRouteOutbox      = "/outbox"
+	AdminRouteListEvents  = AdminRouteOutbox + "/events"
+	AdminRouteGetEvent    = AdminRouteListEvents + "/{id}"
+	AdminRouteReplayEvent = AdminRouteGetEvent + "/replay"
+)
+
+type (
+	handlerDependencies interface {
+		x.WriterProvider
+		x.LoggingProvider
+		nosurfx.CSRFProvider
+		PersistenceProvider
+		config.Provider
+	}
+	Handler struct {
+		r handlerDependencies
+	}
+	HandlerProvider interface {
+		OutboxHandler() *Handler
+	}
+)
+
+func NewHandler(r handlerDependencies) *Handler {
+	return &Handler{r: r}
+}
+
+func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
+	h.r.CSRFHandler().IgnoreGlobs(x.AdminPrefix+AdminRouteListEvents, AdminRouteListEvents)
+	public.GET(x.AdminPrefix+AdminRouteListEvents, redir.RedirectToAdminRoute(h.r))
+	public.GET(x.AdminPrefix+AdminRouteGetEvent, redir.RedirectToAdminRoute(h.r))
+	public.POST(x.AdminPrefix+AdminRouteReplayEvent, redir.RedirectToAdminRoute(h.r))
+}
+
+func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
+	admin.GET(AdminRouteListEvents, h.listOutboxEvents)
+	admin.GET(AdminRouteGetEvent, h.getOutboxEvent)
+	admin.POST(AdminRouteReplayEvent, h.replayOutboxEvent)
+}
+
+// Paginated Outbox Event List Response
+//
+// swagger:response listOutboxEvents
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type listOutboxEventsResponse struct {
+	keysetpagination.ResponseHeaders
+
+	// List of outbox events
+	//
+	// in:body
+	Body []Event
+}
+
+// Paginated List Outbox Event Parameters
+//
+// swagger:parameters listOutboxEvents
+type ListEventsParameters struct {
+	keysetpagination.RequestParameters
+
+	// Status filters the events by their delivery status.
+	//
+	// required: false
+	// in: query
+	Status *EventStatus `json:"status"`
+
+	// IdentityID filters the events by the identity they belong to.
+	//
+	// required: false
+	// in: query
+	IdentityID *uuid.UUID `json:"identity_id"`
+
+	// SinkID filters the events by the sink they are delivered to.
+	//
+	// required: false
+	// in: query
+	SinkID string `json:"sink_id"`
+}
+
+// swagger:route GET /admin/outbox/events outbox listOutboxEvents
+//
+// # List Outbox Events
+//
+// Lists the identity and session lifecycle events recorded in the outbox,
+// newest first.
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: listOutboxEvents
+//	  400: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) listOutboxEvents(w http.ResponseWriter, r *http.Request) {
+	keys := h.r.Config().SecretsPagination(r.Context())
+	filter, paginator, err := parseEventsFilter(r, keys)
+	if err != nil {
+		h.r.Writer().WriteErrorCode(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	l, nextPage, err := h.r.OutboxPersister().ListOutboxEvents(r.Context(), filter, paginator)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	u := *r.URL
+	keysetpagination.SetLinkHeader(w, keys, &u, nextPage)
+	h.r.Writer().Write(w, r, l)
+}
+
+func parseEventsFilter(r *http.Request, keys [][32]byte) (ListEventsParameters, []keysetpagination.Option, error) {
+	var filter ListEventsParameters
+	query := r.URL.Query()
+
+	if query.Has("status") {
+		status, err := ToEventStatus(query.Get("status"))
+		if err != nil {
+			return filter, nil, err
+		}
+		filter.Status = &status
+	}
+
+	if query.Has("identity_id") {
+		id, err := uuid.FromString(query.Get("identity_id"))
+		if err != nil {
+			return filter, nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Parameter identity_id must be a UUID."))
+		}
+		filter.IdentityID = &id
+	}
+
+	filter.SinkID = query.Get("sink_id")
+
+	opts, err := keysetpagination.ParseQueryParams(keys, query)
+	if err != nil {
+		return filter, nil, errors.WithStack(err)
+	}
+
+	return filter, opts, nil
+}
+
+// Outbox Event Parameters
+//
+// swagger:parameters getOutboxEvent replayOutboxEvent
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type outboxEventParameters struct {
+	// ID is the ID of the outbox event.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:route GET /admin/outbox/events/{id} outbox getOutboxEvent
+//
+// # Get an Outbox Event
+//
+// Gets the outbox event with the given ID, including its delivery status.
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: outboxEvent
+//	  400: errorGeneric
+//	  404: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) getOutboxEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.FromString(r.PathValue("id"))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, herodot.ErrBadRequest.WithError(err.Error()).WithDebugf("could not parse parameter {id} as UUID, got %s", r.PathValue("id")))
+		return
+	}
+
+	e, err := h.r.OutboxPersister().GetOutboxEvent(r.Context(), id)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.Writer().Write(w, r, e)
+}
+
+// swagger:route POST /admin/outbox/events/{id}/replay outbox replayOutboxEvent
+//
+// # Replay an Outbox Event
+//
+// Delivers the outbox event again. This is useful to re-send abandoned events
+// after the sink has been fixed, or to re-synchronize a sink. Later events of
+// the same identity are held back until the replayed event is delivered.
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: outboxEvent
+//	  400: errorGeneric
+//	  404: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) replayOutboxEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.FromString(r.PathValue("id"))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, herodot.ErrBadRequest.WithError(err.Error()).WithDebugf("could not parse parameter {id} as UUID, got %s", r.PathValue("id")))
+		return
+	}
+
+	e, err := h.r.OutboxPersister().ReplayOutboxEvent(r.Context(), id)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.Logger().
+		WithField("outbox_event_id", e.ID).
+		WithField("outbox_sink_id", e.SinkID).
+		Info("An administrator replayed an outbox event.")
+
+	h.r.Writer().Write(w, r, e)
+}
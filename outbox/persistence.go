@@ -0,0 +1,45 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	keysetpagination "github.com/ory/x/pagination/keysetpagination_v2"
+)
+
+type (
+	EventPersister interface {
+		// AddOutboxEvents records the events for every configured sink. It
+		// uses the transaction of the context, if any, so that the events are
+		// only recorded if the change they describe is committed.
+		AddOutboxEvents(ctx context.Context, events ...*Event) error
+
+		// NextOutboxEvents returns up to limit pending events which are due.
+		// An event is only returned if no earlier event of the same identity
+		// is pending for the same sink. The returned events are leased until
+		// the given time, so that other workers do not deliver them as well.
+		// Events which another worker leased concurrently are skipped.
+		NextOutboxEvents(ctx context.Context, limit int, leaseUntil time.Time) ([]Event, error)
+
+		// UpdateOutboxEventDelivery stores the status, send count, last
+		// error, next attempt, and delivery time of the event.
+		UpdateOutboxEventDelivery(ctx context.Context, e *Event) error
+
+		ListOutboxEvents(ctx context.Context, filter ListEventsParameters, opts []keysetpagination.Option) ([]Event, *keysetpagination.Paginator, error)
+		GetOutboxEvent(ctx context.Context, id uuid.UUID) (*Event, error)
+
+		// ReplayOutboxEvent marks the event as pending, so that it is
+		// delivered again.
+		ReplayOutboxEvent(ctx context.Context, id uuid.UUID) (*Event, error)
+
+		DeleteDeliveredOutboxEvents(ctx context.Context, deliveredBefore time.Time, limit int) error
+	}
+	PersistenceProvider interface {
+		OutboxPersister() EventPersister
+	}
+)
@@ -0,0 +1,283 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/outbox"
+	"github.com/ory/kratos/persistence"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/x"
+	"github.com/ory/pop/v6"
+	"github.com/ory/x/contextx"
+	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
+)
+
+func TestPersister(ctx context.Context, p persistence.Persister) func(t *testing.T) {
+	return func(t *testing.T) {
+		sinks := []map[string]any{
+			{"id": "sink-a", "url": "https://a.example.com/events"},
+			{"id": "sink-b", "url": "https://b.example.com/events"},
+		}
+		withSinks := contextx.WithConfigValue(ctx, config.ViperKeyOutboxSinks, sinks)
+
+		pending := outbox.EventStatusPending
+		listEvents := func(t *testing.T, ctx context.Context, p persistence.Persister, identityID uuid.UUID) []outbox.Event {
+			events, _, err := p.ListOutboxEvents(ctx, outbox.ListEventsParameters{IdentityID: &identityID}, nil)
+			require.NoError(t, err)
+			return events
+		}
+		newIdentity := func(t *testing.T, ctx context.Context, p persistence.Persister) *identity.Identity {
+			i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+			require.NoError(t, p.CreateIdentity(ctx, i))
+			return i
+		}
+
+		t.Run("case=records nothing without sinks", func(t *testing.T) {
+			_, p := testhelpers.NewNetwork(t, ctx, p)
+
+			i := newIdentity(t, ctx, p)
+			assert.Empty(t, listEvents(t, ctx, p, i.ID))
+
+			events, err := p.NextOutboxEvents(ctx, 10, time.Now().Add(time.Minute))
+			require.NoError(t, err)
+			assert.Empty(t, events)
+		})
+
+		t.Run("case=records an event per sink", func(t *testing.T) {
+			nid, p := testhelpers.NewNetwork(t, withSinks, p)
+
+			i := newIdentity(t, withSinks, p)
+			events := listEvents(t, withSinks, p, i.ID)
+			require.Len(t, events, 2)
+
+			var sinkIDs []string
+			for _, e := range events {
+				sinkIDs = append(sinkIDs, e.SinkID)
+				assert.Equal(t, nid, e.NID)
+				assert.Equal(t, outbox.EventTypeIdentityCreated, e.Type)
+				assert.Equal(t, pending, e.Status)
+				assert.Equal(t, i.ID.String(), gjson.GetBytes(e.Payload, "id").String())
+				assert.False(t, gjson.GetBytes(e.Payload, "credentials").Exists(), "%s", e.Payload)
+			}
+			assert.ElementsMatch(t, []string{"sink-a", "sink-b"}, sinkIDs)
+
+			t.Run("case=other networks are not affected", func(t *testing.T) {
+				_, other := testhelpers.NewNetwork(t, ctx, p)
+				assert.Empty(t, listEvents(t, ctx, other, i.ID))
+				_, err := other.GetOutboxEvent(ctx, events[0].ID)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+		})
+
+		t.Run("case=records identity and session lifecycle events", func(t *testing.T) {
+			_, p := testhelpers.NewNetwork(t, withSinks, p)
+			ctx := contextx.WithConfigValue(ctx, config.ViperKeyOutboxSinks, sinks[:1])
+
+			i := newIdentity(t, ctx, p)
+			i.Traits = identity.Traits(`{"email":"foo@ory.sh"}`)
+			require.NoError(t, p.UpdateIdentity(ctx, i))
+
+			s := session.NewInactiveSession()
+			s.Identity = i
+			s.Active = true
+			s.ExpiresAt = time.Now().Add(time.Hour)
+			require.NoError(t, p.UpsertSession(ctx, s))
+			require.NoError(t, p.UpsertSession(ctx, s), "updating a session does not issue it again")
+			require.NoError(t, p.RevokeSession(ctx, i.ID, s.ID))
+			require.NoError(t, p.DeleteIdentity(ctx, i.ID))
+
+			events := listEvents(t, ctx, p, i.ID)
+			var types []outbox.EventType
+			for k := range events {
+				// The list is ordered newest first.
+				types = append(types, events[len(events)-1-k].Type)
+			}
+			assert.Equal(t, []outbox.EventType{
+				outbox.EventTypeIdentityCreated,
+				outbox.EventTypeIdentityUpdated,
+				outbox.EventTypeSessionIssued,
+				outbox.EventTypeSessionRevoked,
+				outbox.EventTypeIdentityDeleted,
+			}, types)
+			assert.Equal(t, s.ID.String(), gjson.GetBytes(events[1].Payload, "session_id").String())
+		})
+
+		t.Run("case=discards events of rolled back transactions", func(t *testing.T) {
+			_, p := testhelpers.NewNetwork(t, withSinks, p)
+
+			i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+			errRollback := errors.New("rollback")
+			require.ErrorIs(t, p.Transaction(withSinks, func(ctx context.Context, _ *pop.Connection) error {
+				require.NoError(t, p.CreateIdentity(ctx, i))
+				return errRollback
+			}), errRollback)
+
+			assert.Empty(t, listEvents(t, withSinks, p, i.ID))
+		})
+
+		t.Run("case=delivers events of an identity in order", func(t *testing.T) {
+			_, p := testhelpers.NewNetwork(t, withSinks, p)
+			ctx := contextx.WithConfigValue(ctx, config.ViperKeyOutboxSinks, sinks[:1])
+
+			first, second := x.NewUUID(), x.NewUUID()
+			for _, id := range []uuid.UUID{first, second} {
+				deleted, err := outbox.NewIdentityDeletedEvent(id)
+				require.NoError(t, err)
+				revoked, err := outbox.NewSessionRevokedEvent(x.NewUUID(), id)
+				require.NoError(t, err)
+				require.NoError(t, p.AddOutboxEvents(ctx, deleted, revoked))
+			}
+
+			next := func(t *testing.T) []outbox.Event {
+				events, err := p.NextOutboxEvents(ctx, 10, time.Now().Add(-time.Second))
+				require.NoError(t, err)
+				return events
+			}
+
+			events := next(t)
+			require.Len(t, events, 2, "only the first event of every identity is due")
+			assert.Equal(t, first, events[0].IdentityID)
+			assert.Equal(t, outbox.EventTypeIdentityDeleted, events[0].Type)
+			assert.Equal(t, second, events[1].IdentityID)
+			assert.Equal(t, outbox.EventTypeIdentityDeleted, events[1].Type)
+
+			events[0].Status = outbox.EventStatusDelivered
+			events[0].SendCount = 1
+			events[0].DeliveredAt = sqlxx.NullTime(time.Now().UTC())
+			require.NoError(t, p.UpdateOutboxEventDelivery(ctx, &events[0]))
+
+			events[1].SendCount = 1
+			events[1].LastError = "connection refused"
+			events[1].NextAttemptAt = time.Now().Add(time.Hour)
+			require.NoError(t, p.UpdateOutboxEventDelivery(ctx, &events[1]))
+
+			failed, err := p.GetOutboxEvent(ctx, events[1].ID)
+			require.NoError(t, err)
+			assert.Equal(t, pending, failed.Status)
+			assert.Equal(t, 1, failed.SendCount)
+			assert.EqualValues(t, "connection refused", failed.LastError)
+
+			events = next(t)
+			require.Len(t, events, 1, "the events of the failed identity are held back")
+			assert.Equal(t, first, events[0].IdentityID)
+			assert.Equal(t, outbox.EventTypeSessionRevoked, events[0].Type)
+
+			t.Run("case=leases the returned events", func(t *testing.T) {
+				_, err := p.NextOutboxEvents(ctx, 10, time.Now().Add(time.Hour))
+				require.NoError(t, err)
+				assert.Empty(t, next(t))
+			})
+
+			t.Run("case=replays an event", func(t *testing.T) {
+				failed.Status = outbox.EventStatusAbandoned
+				require.NoError(t, p.UpdateOutboxEventDelivery(ctx, failed))
+
+				replayed, err := p.ReplayOutboxEvent(ctx, failed.ID)
+				require.NoError(t, err)
+				assert.Equal(t, pending, replayed.Status)
+				assert.Zero(t, replayed.SendCount)
+
+				events := next(t)
+				require.Len(t, events, 1)
+				assert.Equal(t, failed.ID, events[0].ID)
+
+				_, err = p.ReplayOutboxEvent(ctx, x.NewUUID())
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+		})
+
+		t.Run("case=leases an event to one worker only", func(t *testing.T) {
+			_, p := testhelpers.NewNetwork(t, withSinks, p)
+			ctx := contextx.WithConfigValue(ctx, config.ViperKeyOutboxSinks, sinks[:1])
+
+			for range 10 {
+				deleted, err := outbox.NewIdentityDeletedEvent(x.NewUUID())
+				require.NoError(t, err)
+				require.NoError(t, p.AddOutboxEvents(ctx, deleted))
+			}
+
+			var (
+				wg     sync.WaitGroup
+				mu     sync.Mutex
+				leased = make(map[uuid.UUID]int)
+			)
+			for range 4 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					events, err := p.NextOutboxEvents(ctx, 10, time.Now().Add(time.Hour))
+					assert.NoError(t, err)
+
+					mu.Lock()
+					defer mu.Unlock()
+					for _, e := range events {
+						leased[e.ID]++
+					}
+				}()
+			}
+			wg.Wait()
+
+			for id, count := range leased {
+				assert.Equalf(t, 1, count, "event %s was leased more than once", id)
+			}
+		})
+
+		t.Run("case=lists events by status and sink", func(t *testing.T) {
+			_, p := testhelpers.NewNetwork(t, withSinks, p)
+
+			e, err := outbox.NewIdentityDeletedEvent(x.NewUUID())
+			require.NoError(t, err)
+			require.NoError(t, p.AddOutboxEvents(withSinks, e))
+
+			events, _, err := p.ListOutboxEvents(ctx, outbox.ListEventsParameters{SinkID: "sink-b"}, nil)
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			assert.Equal(t, "sink-b", events[0].SinkID)
+
+			delivered := outbox.EventStatusDelivered
+			events, _, err = p.ListOutboxEvents(ctx, outbox.ListEventsParameters{Status: &delivered}, nil)
+			require.NoError(t, err)
+			assert.Empty(t, events)
+
+			events, _, err = p.ListOutboxEvents(ctx, outbox.ListEventsParameters{Status: &pending}, nil)
+			require.NoError(t, err)
+			assert.Len(t, events, 2)
+		})
+
+		t.Run("case=deletes delivered events", func(t *testing.T) {
+			_, p := testhelpers.NewNetwork(t, withSinks, p)
+
+			e, err := outbox.NewIdentityDeletedEvent(x.NewUUID())
+			require.NoError(t, err)
+			require.NoError(t, p.AddOutboxEvents(withSinks, e))
+
+			events := listEvents(t, ctx, p, e.IdentityID)
+			require.Len(t, events, 2)
+			events[0].Status = outbox.EventStatusDelivered
+			events[0].DeliveredAt = sqlxx.NullTime(time.Now().Add(-time.Hour).UTC())
+			require.NoError(t, p.UpdateOutboxEventDelivery(ctx, &events[0]))
+
+			require.NoError(t, p.DeleteDeliveredOutboxEvents(ctx, time.Now(), 10))
+
+			remaining := listEvents(t, ctx, p, e.IdentityID)
+			require.Len(t, remaining, 1)
+			assert.Equal(t, events[1].ID, remaining[0].ID)
+		})
+	}
+}
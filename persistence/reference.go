@@ -21,14 +21,17 @@ import (
 	"github.com/ory/kratos/continuity"
 	"github.com/ory/kratos/courier"
 	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/outbox"
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow/login"
 	"github.com/ory/kratos/selfservice/flow/recovery"
 	"github.com/ory/kratos/selfservice/flow/registration"
 	"github.com/ory/kratos/selfservice/flow/settings"
 	"github.com/ory/kratos/selfservice/flow/verification"
+	"github.com/ory/kratos/selfservice/ratelimit"
 	"github.com/ory/kratos/selfservice/strategy/code"
 	"github.com/ory/kratos/selfservice/strategy/link"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
 	"github.com/ory/kratos/session"
 )
 
@@ -40,12 +43,15 @@ type Provider interface {
 type Persister interface {
 	continuity.Persister
 	identity.PrivilegedPool
+	identity.LockoutPersister
 	registration.FlowPersister
 	login.FlowPersister
 	settings.FlowPersister
 	courier.Persister
+	outbox.EventPersister
 	session.Persister
 	sessiontokenexchange.Persister
+	ratelimit.Persister
 	errorx.Persister
 	verification.FlowPersister
 	recovery.FlowPersister
@@ -55,6 +61,7 @@ type Persister interface {
 	code.VerificationCodePersister
 	code.RegistrationCodePersister
 	code.LoginCodePersister
+	oidc.ProviderPersister
 
 	CleanupDatabase(context.Context, time.Duration, time.Duration, int) error
 	Close(context.Context) error
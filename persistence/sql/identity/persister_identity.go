@@ -25,7 +25,9 @@ import (
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/otp"
+	"github.com/ory/kratos/outbox"
 	"github.com/ory/kratos/persistence/sql/batch"
+	outboxpersistence "github.com/ory/kratos/persistence/sql/outbox"
 	"github.com/ory/kratos/persistence/sql/update"
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/x"
@@ -57,15 +59,17 @@ type dependencies interface {
 }
 
 type IdentityPersister struct {
-	r   dependencies
-	c   *pop.Connection
-	nid uuid.UUID
+	r      dependencies
+	c      *pop.Connection
+	nid    uuid.UUID
+	outbox outbox.EventPersister
 }
 
 func NewPersister(r dependencies, c *pop.Connection) *IdentityPersister {
 	return &IdentityPersister{
-		c: c,
-		r: r,
+		c:      c,
+		r:      r,
+		outbox: outboxpersistence.NewPersister(r, c),
 	}
 }
 
@@ -75,9 +79,28 @@ func (p *IdentityPersister) NetworkID(ctx context.Context) uuid.UUID {
 
 func (p IdentityPersister) WithNetworkID(nid uuid.UUID) identity.PrivilegedPool {
 	p.nid = nid
+	if op, ok := p.outbox.(interface {
+		WithNetworkID(uuid.UUID) outbox.EventPersister
+	}); ok {
+		p.outbox = op.WithNetworkID(nid)
+	}
 	return &p
 }
 
+// addIdentityEvents records the identity lifecycle events in the outbox. It
+// must be called within the transaction changing the identities.
+func (p *IdentityPersister) addIdentityEvents(ctx context.Context, t outbox.EventType, identities ...*identity.Identity) error {
+	events := make([]*outbox.Event, 0, len(identities))
+	for _, i := range identities {
+		e, err := outbox.NewIdentityEvent(t, i)
+		if err != nil {
+			return err
+		}
+		events = append(events, e)
+	}
+	return p.outbox.AddOutboxEvents(ctx, events...)
+}
+
 func WithTransaction(ctx context.Context, tx *pop.Connection) context.Context {
 	return popx.WithTransaction(ctx, tx)
 }
@@ -118,6 +141,108 @@ func (p *IdentityPersister) ListRecoveryAddresses(ctx context.Context, page, ite
 	return a, nil
 }
 
+func (p *IdentityPersister) ListCredentialsByType(ctx context.Context, ct identity.CredentialsType, after uuid.UUID, limit int) (c []identity.Credentials, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ListCredentialsByType",
+		trace.WithAttributes(
+			attribute.String("credentials.type", string(ct)),
+			attribute.Int("limit", limit),
+			attribute.Stringer("network.id", p.NetworkID(ctx))))
+	defer otelx.End(span, &err)
+
+	typeID, err := FindIdentityCredentialsTypeByName(p.GetConnection(ctx), ct)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.GetConnection(ctx).
+		Where("nid = ? AND identity_credential_type_id = ? AND id > ?", p.NetworkID(ctx), typeID, after).
+		Order("id ASC").
+		Limit(limit).
+		All(&c); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	for k := range c {
+		c[k].Type = ct
+	}
+
+	return c, nil
+}
+
+func (p *IdentityPersister) ListIdentitiesWithAdminMetadataKey(ctx context.Context, key string, organizationID uuid.UUID, offset, limit int) (is []identity.Identity, total int64, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ListIdentitiesWithAdminMetadataKey",
+		trace.WithAttributes(
+			attribute.String("metadata_admin.key", key),
+			attribute.Int("offset", offset),
+			attribute.Int("limit", limit),
+			attribute.Stringer("network.id", p.NetworkID(ctx))))
+	defer otelx.End(span, &err)
+
+	con := p.GetConnection(ctx)
+	wheres := "nid = ?"
+	args := []any{p.NetworkID(ctx)}
+	switch con.Dialect.Name() {
+	case "postgres", "cockroach":
+		wheres += " AND jsonb_typeof(metadata_admin -> CAST(? AS TEXT)) = 'object'"
+		args = append(args, key)
+	case "mysql":
+		wheres += " AND JSON_TYPE(JSON_EXTRACT(metadata_admin, ?)) = 'OBJECT'"
+		args = append(args, fmt.Sprintf("$.%q", key))
+	default:
+		wheres += " AND json_type(metadata_admin, ?) = 'object'"
+		args = append(args, fmt.Sprintf("$.%q", key))
+	}
+	if !organizationID.IsNil() {
+		wheres += " AND organization_id = ?"
+		args = append(args, organizationID)
+	}
+
+	count, err := con.Where(wheres, args...).Count(new(identity.Identity))
+	if err != nil {
+		return nil, 0, sqlcon.HandleError(err)
+	}
+	total = int64(count)
+
+	is = make([]identity.Identity, 0)
+	if limit <= 0 || int64(offset) >= total {
+		return is, total, nil
+	}
+
+	//#nosec G201 -- the columns and conditions are static
+	query := fmt.Sprintf("SELECT %s FROM identities WHERE %s ORDER BY id ASC LIMIT %d OFFSET %d",
+		popx.DBColumns[identity.Identity](con.Dialect), wheres, limit, offset)
+	if err := con.RawQuery(query, args...).All(&is); err != nil {
+		return nil, 0, sqlcon.HandleError(err)
+	}
+
+	return is, total, nil
+}
+
+func (p *IdentityPersister) UpdateCredentialsConfig(ctx context.Context, c *identity.Credentials) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.UpdateCredentialsConfig",
+		trace.WithAttributes(
+			attribute.Stringer("credentials.id", c.ID),
+			attribute.Stringer("network.id", p.NetworkID(ctx))))
+	defer otelx.End(span, &err)
+
+	// The config is only updated if the credentials were not updated since
+	// they were loaded, so that concurrent updates do not overwrite each other.
+	updatedAt := time.Now().UTC().Truncate(time.Microsecond)
+	//#nosec G201 -- TableName is static
+	count, err := p.GetConnection(ctx).RawQuery(
+		fmt.Sprintf("UPDATE %s SET config = ?, updated_at = ? WHERE id = ? AND nid = ? AND updated_at = ?", c.TableName(ctx)),
+		c.Config, updatedAt, c.ID, p.NetworkID(ctx), c.UpdatedAt,
+	).ExecWithCount()
+	if err != nil {
+		return sqlcon.HandleError(err)
+	} else if count == 0 {
+		return errors.WithStack(herodot.ErrConflict.WithReason("The credentials were updated concurrently. Please reload them and try again."))
+	}
+
+	c.UpdatedAt = updatedAt
+	return nil
+}
+
 func stringToLowerTrim(match string) string {
 	return strings.ToLower(strings.TrimSpace(match))
 }
@@ -135,6 +260,9 @@ func NormalizeIdentifier(ct identity.CredentialsType, match string) string {
 		return match
 	case identity.CredentialsTypePassword, identity.CredentialsTypeCodeAuth, identity.CredentialsTypeWebAuthn:
 		return stringToLowerTrim(match)
+	case identity.CredentialsTypeLDAP:
+		// distinguished names are case-insensitive
+		return stringToLowerTrim(match)
 	default:
 		return match
 	}
@@ -758,7 +886,13 @@ func (p *IdentityPersister) CreateIdentities(ctx context.Context, identities ...
 				return sqlcon.HandleError(err)
 			}
 
-			return nil
+			succeeded := make([]*identity.Identity, 0, len(succeededIDs))
+			for _, ident := range identities {
+				if _, failed := failedIdentityIDs[ident.ID]; !failed {
+					succeeded = append(succeeded, ident)
+				}
+			}
+			return p.addIdentityEvents(ctx, outbox.EventTypeIdentityCreated, succeeded...)
 		} else {
 			// No failures: report all identities as created.
 			for _, ident := range identities {
@@ -766,7 +900,7 @@ func (p *IdentityPersister) CreateIdentities(ctx context.Context, identities ...
 			}
 		}
 
-		return nil
+		return p.addIdentityEvents(ctx, outbox.EventTypeIdentityCreated, identities...)
 	}); err != nil {
 		return err
 	}
@@ -1177,8 +1311,10 @@ func (p *IdentityPersister) UpdateIdentityColumns(ctx context.Context, i *identi
 	defer otelx.End(span, &err)
 
 	if err := p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
-		_, err := tx.Where("id = ? AND nid = ?", i.ID, p.NetworkID(ctx)).UpdateQuery(i, columns...)
-		return sqlcon.HandleError(err)
+		if _, err := tx.Where("id = ? AND nid = ?", i.ID, p.NetworkID(ctx)).UpdateQuery(i, columns...); err != nil {
+			return sqlcon.HandleError(err)
+		}
+		return p.addIdentityEvents(ctx, outbox.EventTypeIdentityUpdated, i)
 	}); err != nil {
 		return err
 	}
@@ -1257,7 +1393,11 @@ func (p *IdentityPersister) UpdateIdentity(ctx context.Context, i *identity.Iden
 		}
 
 		i.Credentials, err = updateCredentialsAssociation(ctx, p, tx, i.ID, oldCredentials, newCredentials)
-		return err
+		if err != nil {
+			return err
+		}
+
+		return p.addIdentityEvents(ctx, outbox.EventTypeIdentityUpdated, i)
 	})); err != nil {
 		return err
 	}
@@ -1278,15 +1418,25 @@ func (p *IdentityPersister) DeleteIdentity(ctx context.Context, id uuid.UUID) (e
 		tableName += "@primary"
 	}
 	nid := p.NetworkID(ctx)
-	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf("DELETE FROM %s WHERE id = ? AND nid = ?", tableName),
-		id,
-		nid,
-	).ExecWithCount()
-	if err != nil {
-		return sqlcon.HandleError(err)
-	}
-	if count == 0 {
-		return errors.WithStack(sqlcon.ErrNoRows)
+	if err := p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+		count, err := tx.RawQuery(fmt.Sprintf("DELETE FROM %s WHERE id = ? AND nid = ?", tableName),
+			id,
+			nid,
+		).ExecWithCount()
+		if err != nil {
+			return sqlcon.HandleError(err)
+		}
+		if count == 0 {
+			return errors.WithStack(sqlcon.ErrNoRows)
+		}
+
+		e, err := outbox.NewIdentityDeletedEvent(id)
+		if err != nil {
+			return err
+		}
+		return p.outbox.AddOutboxEvents(ctx, e)
+	}); err != nil {
+		return err
 	}
 	span.AddEvent(events.NewIdentityDeleted(ctx, id))
 	return nil
@@ -0,0 +1 @@
+DROP TABLE selfservice_rate_limit_buckets;
@@ -0,0 +1,18 @@
+CREATE TABLE selfservice_rate_limit_buckets (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "nid" UUID NOT NULL,
+    "bucket_key" VARCHAR(64) NOT NULL,
+    "hits" INTEGER NOT NULL,
+    "expires_at" timestamp NOT NULL,
+
+    "created_at" timestamp NOT NULL,
+    "updated_at" timestamp NOT NULL
+);
+
+-- Relevant query:
+--   SELECT * FROM selfservice_rate_limit_buckets WHERE nid = ? AND bucket_key = ? AND expires_at > ?
+CREATE UNIQUE INDEX selfservice_rate_limit_buckets_nid_bucket_key_uq_idx ON selfservice_rate_limit_buckets (nid, bucket_key);
+
+-- Relevant query:
+--   DELETE FROM selfservice_rate_limit_buckets WHERE expires_at <= ? AND nid = ?
+CREATE INDEX selfservice_rate_limit_buckets_expires_at_nid_idx ON selfservice_rate_limit_buckets (expires_at, nid);
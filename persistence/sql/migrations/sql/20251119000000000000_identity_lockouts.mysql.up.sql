@@ -0,0 +1,17 @@
+CREATE TABLE identity_lockouts (
+    id CHAR(36) NOT NULL PRIMARY KEY,
+    nid CHAR(36) NOT NULL,
+    identity_id CHAR(36) NOT NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    lockouts INTEGER NOT NULL DEFAULT 0,
+    locked_until timestamp NULL,
+
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT identity_lockouts_identity_id_fk FOREIGN KEY (identity_id) REFERENCES identities (id) ON DELETE CASCADE,
+    CONSTRAINT identity_lockouts_nid_fk FOREIGN KEY (nid) REFERENCES networks (id) ON DELETE CASCADE
+);
+
+-- Relevant query:
+--   SELECT * FROM identity_lockouts WHERE nid = ? AND identity_id = ?
+CREATE UNIQUE INDEX identity_lockouts_nid_identity_id_uq_idx ON identity_lockouts (nid, identity_id);
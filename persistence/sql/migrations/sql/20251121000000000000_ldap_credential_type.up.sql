@@ -0,0 +1,3 @@
+INSERT INTO identity_credential_types (id, name)
+SELECT 'c3a5e1f4-8a0b-4b6e-9d0f-2e7c4b1d5a63', 'ldap'
+    WHERE NOT EXISTS ( SELECT * FROM identity_credential_types WHERE name = 'ldap');
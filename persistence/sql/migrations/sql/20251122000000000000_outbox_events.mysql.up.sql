@@ -0,0 +1,35 @@
+CREATE TABLE outbox_events (
+    id CHAR(36) NOT NULL PRIMARY KEY,
+    nid CHAR(36) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    identity_id CHAR(36) NOT NULL,
+    sink_id VARCHAR(255) NOT NULL,
+    payload JSON NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    send_count INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    next_attempt_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at timestamp NULL,
+
+    -- Events of an identity are ordered by created_at, which requires
+    -- microsecond precision.
+    created_at timestamp(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT outbox_events_nid_fk FOREIGN KEY (nid) REFERENCES networks (id) ON DELETE CASCADE
+);
+
+-- Relevant query:
+--   SELECT * FROM outbox_events e WHERE e.nid = ? AND e.status = ? AND e.next_attempt_at <= ? AND NOT EXISTS (...)
+CREATE INDEX outbox_events_nid_status_next_attempt_at_idx ON outbox_events (nid, status, next_attempt_at);
+
+-- Relevant query:
+--   SELECT 1 FROM outbox_events o WHERE o.nid = e.nid AND o.sink_id = e.sink_id AND o.identity_id = e.identity_id AND o.status = ? AND o.created_at < e.created_at
+CREATE INDEX outbox_events_nid_sink_id_identity_id_status_created_at_idx ON outbox_events (nid, sink_id, identity_id, status, created_at);
+
+-- Relevant query:
+--   SELECT * FROM outbox_events WHERE nid = ? ORDER BY created_at DESC, id DESC
+CREATE INDEX outbox_events_nid_created_at_id_idx ON outbox_events (nid, created_at DESC, id DESC);
+
+-- Relevant query:
+--   DELETE FROM outbox_events WHERE status = ? AND delivered_at <= ? AND nid = ?
+CREATE INDEX outbox_events_nid_status_delivered_at_idx ON outbox_events (nid, status, delivered_at);
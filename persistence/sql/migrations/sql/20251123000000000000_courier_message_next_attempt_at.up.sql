@@ -0,0 +1,4 @@
+ALTER TABLE
+  courier_messages
+ADD
+  column next_attempt_at timestamp NULL;
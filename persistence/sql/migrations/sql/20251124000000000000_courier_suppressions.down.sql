@@ -0,0 +1 @@
+DROP TABLE courier_suppressions;
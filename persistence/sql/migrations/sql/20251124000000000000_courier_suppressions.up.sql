@@ -0,0 +1,18 @@
+CREATE TABLE courier_suppressions (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "nid" UUID NOT NULL,
+    "recipient" VARCHAR(255) NOT NULL,
+    "reason" VARCHAR(32) NOT NULL,
+    "message_id" UUID NULL,
+    "created_at" timestamp NOT NULL,
+    "updated_at" timestamp NOT NULL,
+    CONSTRAINT "courier_suppressions_nid_fk" FOREIGN KEY ("nid") REFERENCES "networks" ("id") ON DELETE cascade
+);
+
+-- Relevant query:
+--   SELECT * FROM courier_suppressions WHERE nid = ? AND recipient = ?
+CREATE UNIQUE INDEX courier_suppressions_nid_recipient_uq_idx ON courier_suppressions (nid, recipient);
+
+-- Relevant query:
+--   SELECT * FROM courier_suppressions WHERE nid = ? ORDER BY created_at DESC, id DESC
+CREATE INDEX courier_suppressions_nid_created_at_id_idx ON courier_suppressions (nid, created_at DESC, id DESC);
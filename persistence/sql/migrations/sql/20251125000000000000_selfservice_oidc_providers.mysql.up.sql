@@ -0,0 +1,18 @@
+CREATE TABLE selfservice_oidc_providers (
+    id CHAR(36) NOT NULL PRIMARY KEY,
+    nid CHAR(36) NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    organization_id CHAR(36) NULL,
+    config JSON NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT selfservice_oidc_providers_nid_fk FOREIGN KEY (nid) REFERENCES networks (id) ON DELETE CASCADE
+);
+
+-- Relevant query:
+--   SELECT * FROM selfservice_oidc_providers WHERE nid = ? AND provider_id = ?
+CREATE UNIQUE INDEX selfservice_oidc_providers_nid_provider_id_uq_idx ON selfservice_oidc_providers (nid, provider_id);
+
+-- Relevant query:
+--   SELECT * FROM selfservice_oidc_providers WHERE nid = ? ORDER BY created_at ASC, id ASC
+CREATE INDEX selfservice_oidc_providers_nid_created_at_id_idx ON selfservice_oidc_providers (nid, created_at, id);
@@ -0,0 +1,18 @@
+CREATE TABLE selfservice_oidc_providers (
+    "id" UUID NOT NULL PRIMARY KEY,
+    "nid" UUID NOT NULL,
+    "provider_id" VARCHAR(255) NOT NULL,
+    "organization_id" UUID NULL,
+    "config" jsonb NOT NULL,
+    "created_at" timestamp NOT NULL,
+    "updated_at" timestamp NOT NULL,
+    CONSTRAINT "selfservice_oidc_providers_nid_fk" FOREIGN KEY ("nid") REFERENCES "networks" ("id") ON DELETE cascade
+);
+
+-- Relevant query:
+--   SELECT * FROM selfservice_oidc_providers WHERE nid = ? AND provider_id = ?
+CREATE UNIQUE INDEX selfservice_oidc_providers_nid_provider_id_uq_idx ON selfservice_oidc_providers (nid, provider_id);
+
+-- Relevant query:
+--   SELECT * FROM selfservice_oidc_providers WHERE nid = ? ORDER BY created_at ASC, id ASC
+CREATE INDEX selfservice_oidc_providers_nid_created_at_id_idx ON selfservice_oidc_providers (nid, created_at, id);
@@ -0,0 +1 @@
+ALTER TABLE sessions DROP COLUMN IF EXISTS oidc_provider_session_id;
@@ -0,0 +1 @@
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS oidc_provider_session_id VARCHAR(255) NULL;
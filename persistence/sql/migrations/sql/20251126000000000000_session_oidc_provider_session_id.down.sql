@@ -0,0 +1 @@
+ALTER TABLE sessions DROP COLUMN oidc_provider_session_id;
@@ -0,0 +1 @@
+DROP INDEX sessions_nid_oidc_provider_session_id_idx;
@@ -0,0 +1,3 @@
+-- Relevant query:
+--   SELECT * FROM sessions WHERE nid = ? AND oidc_provider_session_id = ? AND active = ? AND expires_at >= ?
+CREATE INDEX sessions_nid_oidc_provider_session_id_idx ON sessions (nid, oidc_provider_session_id);
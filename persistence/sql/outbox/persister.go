@@ -0,0 +1,258 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/outbox"
+	"github.com/ory/kratos/x"
+	"github.com/ory/pop/v6"
+	"github.com/ory/x/contextx"
+	"github.com/ory/x/otelx"
+	keysetpagination "github.com/ory/x/pagination/keysetpagination_v2"
+	"github.com/ory/x/popx"
+	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
+)
+
+var _ outbox.EventPersister = (*OutboxPersister)(nil)
+
+type (
+	dependencies interface {
+		config.Provider
+		contextx.Provider
+		x.TracingProvider
+	}
+	OutboxPersister struct {
+		r   dependencies
+		c   *pop.Connection
+		nid uuid.UUID
+	}
+)
+
+func NewPersister(r dependencies, c *pop.Connection) *OutboxPersister {
+	return &OutboxPersister{
+		r: r,
+		c: c,
+	}
+}
+
+func (p *OutboxPersister) NetworkID(ctx context.Context) uuid.UUID {
+	return p.r.Contextualizer().Network(ctx, p.nid)
+}
+
+func (p OutboxPersister) WithNetworkID(nid uuid.UUID) outbox.EventPersister {
+	p.nid = nid
+	return &p
+}
+
+func (p *OutboxPersister) getConnection(ctx context.Context) *pop.Connection {
+	return popx.GetConnection(ctx, p.c.WithContext(ctx))
+}
+
+func (p *OutboxPersister) AddOutboxEvents(ctx context.Context, events ...*outbox.Event) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.AddOutboxEvents")
+	defer otelx.End(span, &err)
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	sinks, err := p.r.Config().OutboxSinks(ctx)
+	if err != nil {
+		return err
+	}
+
+	nid := p.NetworkID(ctx)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	conn := p.getConnection(ctx)
+	for _, sink := range sinks {
+		for k, e := range events {
+			row := *e
+			row.ID = uuid.Nil
+			row.NID = nid
+			row.SinkID = sink.ID
+			row.Status = outbox.EventStatusPending
+			row.NextAttemptAt = now
+			// Events are delivered in the order of their creation time, so
+			// every event gets a distinct one.
+			row.CreatedAt = now.Add(time.Duration(k) * time.Microsecond)
+			if err := conn.Create(&row); err != nil {
+				return sqlcon.HandleError(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *OutboxPersister) NextOutboxEvents(ctx context.Context, limit int, leaseUntil time.Time) (events []outbox.Event, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.NextOutboxEvents")
+	defer otelx.End(span, &err)
+
+	table := outbox.Event{}.TableName()
+	now := time.Now().UTC()
+	if err := popx.Transaction(ctx, p.c.WithContext(ctx), func(ctx context.Context, tx *pop.Connection) error {
+		var due []outbox.Event
+		//#nosec G201 -- TableName is static
+		if err := tx.RawQuery(fmt.Sprintf(`SELECT * FROM %[1]s e
+WHERE e.nid = ? AND e.status = ? AND e.next_attempt_at <= ?
+AND NOT EXISTS (
+	SELECT 1 FROM %[1]s o
+	WHERE o.nid = e.nid AND o.sink_id = e.sink_id AND o.identity_id = e.identity_id AND o.status = ?
+	AND (o.created_at < e.created_at OR (o.created_at = e.created_at AND o.id < e.id))
+)
+ORDER BY e.created_at ASC, e.id ASC
+LIMIT ?`, table),
+			p.NetworkID(ctx),
+			outbox.EventStatusPending,
+			now,
+			outbox.EventStatusPending,
+			limit,
+		).All(&due); err != nil {
+			return sqlcon.HandleError(err)
+		}
+
+		for _, e := range due {
+			// The lease is only taken if the event is still due, so that an
+			// event which another worker leased concurrently is skipped.
+			//#nosec G201 -- TableName is static
+			count, err := tx.RawQuery(fmt.Sprintf("UPDATE %s SET next_attempt_at = ? WHERE id = ? AND nid = ? AND status = ? AND next_attempt_at <= ?", table),
+				leaseUntil.UTC(), e.ID, e.NID, outbox.EventStatusPending, now,
+			).ExecWithCount()
+			if err != nil {
+				return sqlcon.HandleError(err)
+			} else if count == 0 {
+				continue
+			}
+
+			e.NextAttemptAt = leaseUntil.UTC()
+			events = append(events, e)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (p *OutboxPersister) UpdateOutboxEventDelivery(ctx context.Context, e *outbox.Event) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.UpdateOutboxEventDelivery")
+	defer otelx.End(span, &err)
+
+	e.UpdatedAt = time.Now().UTC()
+	//#nosec G201 -- TableName is static
+	count, err := p.getConnection(ctx).RawQuery(fmt.Sprintf(
+		"UPDATE %s SET status = ?, send_count = ?, last_error = ?, next_attempt_at = ?, delivered_at = ?, updated_at = ? WHERE id = ? AND nid = ?",
+		e.TableName(),
+	),
+		e.Status,
+		e.SendCount,
+		e.LastError,
+		e.NextAttemptAt.UTC(),
+		e.DeliveredAt,
+		e.UpdatedAt,
+		e.ID,
+		p.NetworkID(ctx),
+	).ExecWithCount()
+	if err != nil {
+		return sqlcon.HandleError(err)
+	}
+	if count == 0 {
+		return errors.WithStack(sqlcon.ErrNoRows)
+	}
+	return nil
+}
+
+func (p *OutboxPersister) ListOutboxEvents(ctx context.Context, filter outbox.ListEventsParameters, opts []keysetpagination.Option) (_ []outbox.Event, _ *keysetpagination.Paginator, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ListOutboxEvents")
+	defer otelx.End(span, &err)
+
+	q := p.getConnection(ctx).Where("nid = ?", p.NetworkID(ctx))
+
+	if filter.Status != nil {
+		q = q.Where("status = ?", *filter.Status)
+	}
+
+	if filter.IdentityID != nil {
+		q = q.Where("identity_id = ?", *filter.IdentityID)
+	}
+
+	if filter.SinkID != "" {
+		q = q.Where("sink_id = ?", filter.SinkID)
+	}
+
+	opts = append(opts, keysetpagination.WithDefaultToken(outbox.Event{}.DefaultPageToken()))
+	opts = append(opts, keysetpagination.WithDefaultSize(10))
+	paginator := keysetpagination.NewPaginator(opts...)
+
+	events := make([]outbox.Event, paginator.Size())
+	if err := q.Scope(keysetpagination.Paginate[outbox.Event](paginator)).
+		All(&events); err != nil {
+		return nil, nil, sqlcon.HandleError(err)
+	}
+
+	events, nextPage := keysetpagination.Result(events, paginator)
+	return events, nextPage, nil
+}
+
+func (p *OutboxPersister) GetOutboxEvent(ctx context.Context, id uuid.UUID) (_ *outbox.Event, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetOutboxEvent")
+	defer otelx.End(span, &err)
+
+	var e outbox.Event
+	if err := p.getConnection(ctx).Where("id = ? AND nid = ?", id, p.NetworkID(ctx)).First(&e); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+	return &e, nil
+}
+
+func (p *OutboxPersister) ReplayOutboxEvent(ctx context.Context, id uuid.UUID) (_ *outbox.Event, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ReplayOutboxEvent")
+	defer otelx.End(span, &err)
+
+	var e *outbox.Event
+	if err := popx.Transaction(ctx, p.c.WithContext(ctx), func(ctx context.Context, _ *pop.Connection) error {
+		e, err = p.GetOutboxEvent(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		e.Status = outbox.EventStatusPending
+		e.SendCount = 0
+		e.NextAttemptAt = time.Now().UTC()
+		e.DeliveredAt = sqlxx.NullTime{}
+		return p.UpdateOutboxEventDelivery(ctx, e)
+	}); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (p *OutboxPersister) DeleteDeliveredOutboxEvents(ctx context.Context, deliveredBefore time.Time, limit int) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteDeliveredOutboxEvents")
+	defer otelx.End(span, &err)
+
+	//#nosec G201 -- TableName is static
+	err = p.getConnection(ctx).RawQuery(fmt.Sprintf(
+		"DELETE FROM %[1]s WHERE id in (SELECT id FROM (SELECT id FROM %[1]s c WHERE status = ? AND delivered_at <= ? and nid = ? ORDER BY delivered_at ASC LIMIT ?) AS s)",
+		outbox.Event{}.TableName(),
+	),
+		outbox.EventStatusDelivered,
+		deliveredBefore.UTC(),
+		p.NetworkID(ctx),
+		limit,
+	).Exec()
+
+	return sqlcon.HandleError(err)
+}
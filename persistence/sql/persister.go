@@ -15,9 +15,11 @@ import (
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/outbox"
 	"github.com/ory/kratos/persistence"
 	"github.com/ory/kratos/persistence/sql/devices"
 	idpersistence "github.com/ory/kratos/persistence/sql/identity"
+	outboxpersistence "github.com/ory/kratos/persistence/sql/outbox"
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/x"
@@ -53,6 +55,7 @@ type (
 
 		identity.PrivilegedPool
 		session.DevicePersister
+		outbox.EventPersister
 	}
 )
 
@@ -106,6 +109,7 @@ func NewPersister(r persisterDependencies, c *pop.Connection, opts ...Option) (*
 		r:               r,
 		PrivilegedPool:  idpersistence.NewPersister(r, c),
 		DevicePersister: devices.NewPersister(r, c),
+		EventPersister:  outboxpersistence.NewPersister(r, c),
 		p:               networkx.NewManager(c, r.Logger()),
 	}, nil
 }
@@ -126,6 +130,11 @@ func (p Persister) WithNetworkID(nid uuid.UUID) persistence.Persister {
 	}); ok {
 		p.DevicePersister = dp.WithNetworkID(nid)
 	}
+	if op, ok := p.EventPersister.(interface {
+		WithNetworkID(uuid.UUID) outbox.EventPersister
+	}); ok {
+		p.EventPersister = op.WithNetworkID(nid)
+	}
 	return &p
 }
 
@@ -229,6 +238,18 @@ func (p *Persister) CleanupDatabase(ctx context.Context, wait time.Duration, old
 	}
 	time.Sleep(wait)
 
+	p.r.Logger().Println("Cleaning up expired rate limit buckets")
+	if err := p.DeleteExpiredRateLimitBuckets(ctx, currentTime, batchSize); err != nil {
+		return err
+	}
+	time.Sleep(wait)
+
+	p.r.Logger().Println("Cleaning up delivered outbox events")
+	if err := p.DeleteDeliveredOutboxEvents(ctx, currentTime, batchSize); err != nil {
+		return err
+	}
+	time.Sleep(wait)
+
 	p.r.Logger().Println("Successfully cleaned up the latest batch of the SQL database! " +
 		"This should be re-run periodically, to be sure that all expired data is purged.")
 	return nil
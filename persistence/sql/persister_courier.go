@@ -7,17 +7,22 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
 
 	"github.com/ory/herodot"
 	"github.com/ory/kratos/courier"
+	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/persistence/sql/update"
 	"github.com/ory/pop/v6"
 	"github.com/ory/x/otelx"
 	keysetpagination "github.com/ory/x/pagination/keysetpagination_v2"
 	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
 	"github.com/ory/x/uuidx"
 )
 
@@ -68,9 +73,10 @@ func (p *Persister) NextMessages(ctx context.Context, limit uint8) (messages []c
 	if err := p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
 		var m []courier.Message
 		if err := tx.
-			Where("nid = ? AND status = ?",
+			Where("nid = ? AND status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)",
 				p.NetworkID(ctx),
 				courier.MessageStatusQueued,
+				time.Now().UTC(),
 			).
 			Order("created_at ASC").
 			Limit(int(limit)).
@@ -210,3 +216,204 @@ func (p *Persister) RecordDispatch(ctx context.Context, msgID uuid.UUID, status
 
 	return nil
 }
+
+func (p *Persister) ScheduleMessageRetry(ctx context.Context, msgID uuid.UUID, nextAttemptAt time.Time) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ScheduleMessageRetry")
+	defer otelx.End(span, &err)
+
+	// Only messages which are still being processed are queued again, so that
+	// messages abandoned by the channel stay abandoned.
+	return sqlcon.HandleError(p.GetConnection(ctx).RawQuery(
+		"UPDATE courier_messages SET status = ?, next_attempt_at = ? WHERE id = ? AND nid = ? AND status = ?",
+		courier.MessageStatusQueued,
+		nextAttemptAt.UTC(),
+		msgID,
+		p.NetworkID(ctx),
+		courier.MessageStatusProcessing,
+	).Exec())
+}
+
+func (p *Persister) SetMessageDeliveryStatus(ctx context.Context, msgID uuid.UUID, status courier.MessageStatus) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.SetMessageDeliveryStatus")
+	defer otelx.End(span, &err)
+
+	// Messages may bounce after they were delivered, but never the other way
+	// around.
+	from := []courier.MessageStatus{courier.MessageStatusSent}
+	if status == courier.MessageStatusBounced {
+		from = append(from, courier.MessageStatusDelivered)
+	}
+
+	count, err := p.GetConnection(ctx).RawQuery(
+		"UPDATE courier_messages SET status = ? WHERE id = ? AND nid = ? AND status IN (?)",
+		status,
+		msgID,
+		p.NetworkID(ctx),
+		from,
+	).ExecWithCount()
+	if err != nil {
+		return sqlcon.HandleError(err)
+	}
+
+	if count == 0 {
+		return errors.WithStack(sqlcon.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (p *Persister) CancelMessage(ctx context.Context, msgID uuid.UUID) (_ *courier.Message, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CancelMessage")
+	defer otelx.End(span, &err)
+
+	return p.transitionMessage(ctx, msgID, []courier.MessageStatus{courier.MessageStatusQueued}, func(m *courier.Message) {
+		m.Status = courier.MessageStatusCancelled
+	})
+}
+
+func (p *Persister) RequeueMessage(ctx context.Context, msgID uuid.UUID, from ...courier.MessageStatus) (_ *courier.Message, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.RequeueMessage")
+	defer otelx.End(span, &err)
+
+	return p.transitionMessage(ctx, msgID, from, func(m *courier.Message) {
+		m.Status = courier.MessageStatusQueued
+		m.SendCount = 0
+		m.NextAttemptAt = sqlxx.NullTime{}
+	})
+}
+
+// transitionMessage applies the update to the message if it is in one of the
+// given states. The dispatches of the message are kept.
+func (p *Persister) transitionMessage(ctx context.Context, msgID uuid.UUID, from []courier.MessageStatus, update func(m *courier.Message)) (m *courier.Message, err error) {
+	if err := p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+		m, err = p.FetchMessage(ctx, msgID)
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(from, m.Status) {
+			return errors.WithStack(herodot.ErrConflict.
+				WithReasonf("The message can not be changed because its status is %q.", m.Status))
+		}
+
+		status := m.Status
+		update(m)
+		count, err := tx.RawQuery(
+			"UPDATE courier_messages SET status = ?, send_count = ?, next_attempt_at = ? WHERE id = ? AND nid = ? AND status = ?",
+			m.Status,
+			m.SendCount,
+			m.NextAttemptAt,
+			m.ID,
+			p.NetworkID(ctx),
+			status,
+		).ExecWithCount()
+		if err != nil {
+			return sqlcon.HandleError(err)
+		}
+		if count == 0 {
+			return errors.WithStack(herodot.ErrConflict.
+				WithReason("The message was changed concurrently, please try again."))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (p *Persister) AddSuppression(ctx context.Context, s *courier.Suppression) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.AddSuppression")
+	defer otelx.End(span, &err)
+
+	s.NID = p.NetworkID(ctx)
+	s.Recipient = courier.NormalizeRecipient(s.Recipient)
+
+	if err := p.GetConnection(ctx).Where("nid = ? AND recipient = ?", s.NID, s.Recipient).First(s); err == nil {
+		return nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return sqlcon.HandleError(err)
+	}
+
+	if err := sqlcon.HandleError(p.GetConnection(ctx).Create(s)); errors.Is(err, sqlcon.ErrUniqueViolation) {
+		// The recipient was suppressed concurrently.
+		if err := p.GetConnection(ctx).Where("nid = ? AND recipient = ?", s.NID, s.Recipient).First(s); err != nil {
+			return sqlcon.HandleError(err)
+		}
+	} else if err != nil {
+		return err
+	}
+
+	// #nosec G201 -- TableName is static
+	return sqlcon.HandleError(p.GetConnection(ctx).RawQuery(
+		fmt.Sprintf("UPDATE %s SET status = ? WHERE nid = ? AND value = ?", new(identity.VerifiableAddress).TableName(ctx)),
+		identity.VerifiableAddressStatusSuppressed,
+		s.NID,
+		s.Recipient,
+	).Exec())
+}
+
+func (p *Persister) IsSuppressed(ctx context.Context, recipient string) (_ bool, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.IsSuppressed")
+	defer otelx.End(span, &err)
+
+	exists, err := p.GetConnection(ctx).
+		Where("nid = ? AND recipient = ?", p.NetworkID(ctx), courier.NormalizeRecipient(recipient)).
+		Exists(new(courier.Suppression))
+	if err != nil {
+		return false, sqlcon.HandleError(err)
+	}
+
+	return exists, nil
+}
+
+func (p *Persister) ListSuppressions(ctx context.Context, filter courier.ListCourierSuppressionsParameters, opts []keysetpagination.Option) (_ []courier.Suppression, _ *keysetpagination.Paginator, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ListSuppressions")
+	defer otelx.End(span, &err)
+
+	q := p.GetConnection(ctx).Where("nid=?", p.NetworkID(ctx))
+
+	if filter.Recipient != "" {
+		q = q.Where("recipient=?", courier.NormalizeRecipient(filter.Recipient))
+	}
+
+	opts = append(opts, keysetpagination.WithDefaultToken(courier.Suppression{}.DefaultPageToken()))
+	opts = append(opts, keysetpagination.WithDefaultSize(10))
+	paginator := keysetpagination.NewPaginator(opts...)
+
+	suppressions := make([]courier.Suppression, paginator.Size())
+	if err := q.Scope(keysetpagination.Paginate[courier.Suppression](paginator)).
+		All(&suppressions); err != nil {
+		return nil, nil, sqlcon.HandleError(err)
+	}
+
+	suppressions, nextPage := keysetpagination.Result(suppressions, paginator)
+
+	return suppressions, nextPage, nil
+}
+
+func (p *Persister) DeleteSuppression(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteSuppression")
+	defer otelx.End(span, &err)
+
+	return p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+		var s courier.Suppression
+		if err := tx.Where("id = ? AND nid = ?", id, p.NetworkID(ctx)).First(&s); err != nil {
+			return sqlcon.HandleError(err)
+		}
+
+		if err := tx.Destroy(&s); err != nil {
+			return sqlcon.HandleError(err)
+		}
+
+		// #nosec G201 -- TableName is static
+		return sqlcon.HandleError(tx.RawQuery(
+			fmt.Sprintf("UPDATE %s SET status = CASE WHEN verified THEN ? ELSE ? END WHERE nid = ? AND value = ? AND status = ?", new(identity.VerifiableAddress).TableName(ctx)),
+			identity.VerifiableAddressStatusCompleted,
+			identity.VerifiableAddressStatusPending,
+			s.NID,
+			s.Recipient,
+			identity.VerifiableAddressStatusSuppressed,
+		).Exec())
+	})
+}
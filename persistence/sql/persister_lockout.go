@@ -0,0 +1,112 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/pop/v6"
+	"github.com/ory/x/otelx"
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/identity"
+)
+
+var _ identity.LockoutPersister = new(Persister)
+
+func (p *Persister) IncrementIdentityFailedAuthentications(ctx context.Context, identityID uuid.UUID) (l *identity.Lockout, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.IncrementIdentityFailedAuthentications",
+		trace.WithAttributes(attribute.Stringer("identity.id", identityID)))
+	defer otelx.End(span, &err)
+
+	// Two requests may record the first failed authentication at the same
+	// time, in which case one of them violates the unique constraint and is
+	// retried.
+	for range 2 {
+		l, err = p.incrementIdentityFailedAuthentications(ctx, identityID)
+		if !errors.Is(err, sqlcon.ErrUniqueViolation) {
+			return l, err
+		}
+	}
+
+	return nil, errors.WithStack(err)
+}
+
+func (p *Persister) incrementIdentityFailedAuthentications(ctx context.Context, identityID uuid.UUID) (l *identity.Lockout, err error) {
+	nid := p.NetworkID(ctx)
+	l = new(identity.Lockout)
+
+	return l, p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+		//#nosec G201 -- TableName is static
+		count, err := tx.RawQuery(fmt.Sprintf(
+			"UPDATE %s SET failed_attempts = failed_attempts + 1, updated_at = ? WHERE nid = ? AND identity_id = ?",
+			l.TableName(),
+		), time.Now().UTC(), nid, identityID).ExecWithCount()
+		if err != nil {
+			return sqlcon.HandleError(err)
+		}
+
+		if count > 0 {
+			return sqlcon.HandleError(tx.Where("nid = ? AND identity_id = ?", nid, identityID).First(l))
+		}
+
+		*l = identity.Lockout{
+			NID:            nid,
+			IdentityID:     identityID,
+			FailedAttempts: 1,
+		}
+		return sqlcon.HandleError(tx.Create(l))
+	})
+}
+
+func (p *Persister) LockIdentity(ctx context.Context, identityID uuid.UUID, maxFailedAttempts int, until time.Time) (_ bool, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.LockIdentity",
+		trace.WithAttributes(attribute.Stringer("identity.id", identityID)))
+	defer otelx.End(span, &err)
+
+	// The condition on failed_attempts makes sure that only one of several
+	// concurrent requests locks the identity.
+	//#nosec G201 -- TableName is static
+	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
+		"UPDATE %s SET failed_attempts = 0, lockouts = lockouts + 1, locked_until = ?, updated_at = ? WHERE nid = ? AND identity_id = ? AND failed_attempts >= ?",
+		identity.Lockout{}.TableName(),
+	), until.UTC(), time.Now().UTC(), p.NetworkID(ctx), identityID, maxFailedAttempts).ExecWithCount()
+	if err != nil {
+		return false, sqlcon.HandleError(err)
+	}
+
+	return count > 0, nil
+}
+
+func (p *Persister) GetIdentityLockout(ctx context.Context, identityID uuid.UUID) (_ *identity.Lockout, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetIdentityLockout",
+		trace.WithAttributes(attribute.Stringer("identity.id", identityID)))
+	defer otelx.End(span, &err)
+
+	var l identity.Lockout
+	if err := p.GetConnection(ctx).Where("nid = ? AND identity_id = ?", p.NetworkID(ctx), identityID).First(&l); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return &l, nil
+}
+
+func (p *Persister) DeleteIdentityLockout(ctx context.Context, identityID uuid.UUID) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteIdentityLockout",
+		trace.WithAttributes(attribute.Stringer("identity.id", identityID)))
+	defer otelx.End(span, &err)
+
+	//#nosec G201 -- TableName is static
+	return sqlcon.HandleError(p.GetConnection(ctx).RawQuery(
+		fmt.Sprintf("DELETE FROM %s WHERE nid = ? AND identity_id = ?", identity.Lockout{}.TableName()),
+		p.NetworkID(ctx), identityID,
+	).Exec())
+}
@@ -0,0 +1,116 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/pop/v6"
+	"github.com/ory/x/otelx"
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/selfservice/ratelimit"
+)
+
+var _ ratelimit.Persister = new(Persister)
+
+func (p *Persister) IncrementRateLimitBucket(ctx context.Context, key string, window time.Duration) (b *ratelimit.Bucket, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.IncrementRateLimitBucket")
+	defer otelx.End(span, &err)
+
+	hashed := p.hmacValue(ctx, key)
+
+	// Two requests may start a new bucket at the same time, in which case one
+	// of them violates the unique constraint and is retried.
+	for range 2 {
+		b, err = p.incrementRateLimitBucket(ctx, hashed, window)
+		if !errors.Is(err, sqlcon.ErrUniqueViolation) {
+			return b, err
+		}
+	}
+
+	return nil, errors.WithStack(err)
+}
+
+func (p *Persister) incrementRateLimitBucket(ctx context.Context, hashed string, window time.Duration) (b *ratelimit.Bucket, err error) {
+	nid := p.NetworkID(ctx)
+	b = new(ratelimit.Bucket)
+
+	return b, p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+		now := time.Now().UTC()
+
+		//#nosec G201 -- TableName is static
+		count, err := tx.RawQuery(fmt.Sprintf(
+			"UPDATE %s SET hits = hits + 1, updated_at = ? WHERE nid = ? AND bucket_key = ? AND expires_at > ?",
+			b.TableName(),
+		), now, nid, hashed, now).ExecWithCount()
+		if err != nil {
+			return sqlcon.HandleError(err)
+		}
+
+		if count > 0 {
+			return sqlcon.HandleError(tx.Where("nid = ? AND bucket_key = ?", nid, hashed).First(b))
+		}
+
+		// The bucket either does not exist or has expired.
+		//#nosec G201 -- TableName is static
+		if err := tx.RawQuery(fmt.Sprintf("DELETE FROM %s WHERE nid = ? AND bucket_key = ?", b.TableName()), nid, hashed).Exec(); err != nil {
+			return sqlcon.HandleError(err)
+		}
+
+		*b = ratelimit.Bucket{
+			NID:       nid,
+			Key:       hashed,
+			Hits:      1,
+			ExpiresAt: now.Add(window),
+		}
+		return sqlcon.HandleError(tx.Create(b))
+	})
+}
+
+func (p *Persister) GetRateLimitBucket(ctx context.Context, key string) (_ *ratelimit.Bucket, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetRateLimitBucket")
+	defer otelx.End(span, &err)
+
+	var b ratelimit.Bucket
+	if err := p.GetConnection(ctx).
+		Where("nid = ? AND bucket_key = ? AND expires_at > ?", p.NetworkID(ctx), p.hmacValue(ctx, key), time.Now().UTC()).
+		First(&b); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return &b, nil
+}
+
+func (p *Persister) DeleteRateLimitBucket(ctx context.Context, key string) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteRateLimitBucket")
+	defer otelx.End(span, &err)
+
+	//#nosec G201 -- TableName is static
+	return sqlcon.HandleError(p.GetConnection(ctx).RawQuery(
+		fmt.Sprintf("DELETE FROM %s WHERE nid = ? AND bucket_key = ?", ratelimit.Bucket{}.TableName()),
+		p.NetworkID(ctx), p.hmacValue(ctx, key),
+	).Exec())
+}
+
+func (p *Persister) DeleteExpiredRateLimitBuckets(ctx context.Context, at time.Time, limit int) (err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteExpiredRateLimitBuckets")
+	defer otelx.End(span, &err)
+
+	//#nosec G201 -- TableName is static
+	err = p.GetConnection(ctx).RawQuery(fmt.Sprintf(
+		"DELETE FROM %[1]s WHERE id in (SELECT id FROM (SELECT id FROM %[1]s c WHERE expires_at <= ? and nid = ? ORDER BY expires_at ASC LIMIT ?) AS s)",
+		ratelimit.Bucket{}.TableName(),
+	),
+		at.UTC(),
+		p.NetworkID(ctx),
+		limit,
+	).Exec()
+
+	return sqlcon.HandleError(err)
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/ory/herodot"
 	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/outbox"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/x"
 	"github.com/ory/kratos/x/events"
@@ -122,6 +123,21 @@ func (p *Persister) ListSessions(ctx context.Context, active *bool, paginatorOpt
 	return s, nextPage, nil
 }
 
+// ListActiveSessionsByProviderSessionID retrieves the active sessions which
+// were authenticated by the OpenID Connect provider session with the ID.
+func (p *Persister) ListActiveSessionsByProviderSessionID(ctx context.Context, providerSessionID string) (_ []session.Session, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ListActiveSessionsByProviderSessionID")
+	defer otelx.End(span, &err)
+
+	s := make([]session.Session, 0)
+	if err := p.GetConnection(ctx).
+		Where("nid = ? AND oidc_provider_session_id = ? AND active = ? AND expires_at >= ?", p.NetworkID(ctx), providerSessionID, true, time.Now().UTC()).
+		All(&s); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+	return s, nil
+}
+
 // ListSessionsByIdentity retrieves sessions for an identity from the store.
 func (p *Persister) ListSessionsByIdentity(
 	ctx context.Context,
@@ -300,10 +316,42 @@ func (p *Persister) UpsertSession(ctx context.Context, s *session.Session) (err
 			}
 		}
 
-		return nil
+		e, err := outbox.NewSessionIssuedEvent(s)
+		if err != nil {
+			return err
+		}
+		return p.AddOutboxEvents(ctx, e)
 	}))
 }
 
+// addSessionRevokedEvents records the revocation of the active sessions
+// matching the condition in the outbox. It must be called within the
+// transaction revoking the sessions.
+func (p *Persister) addSessionRevokedEvents(ctx context.Context, condition string, args ...any) error {
+	if sinks, err := p.r.Config().OutboxSinks(ctx); err != nil || len(sinks) == 0 {
+		return err
+	}
+
+	var sessions []session.Session
+	if err := p.GetConnection(ctx).
+		Select("id", "identity_id").
+		Where("active = ? AND nid = ?", true, p.NetworkID(ctx)).
+		Where(condition, args...).
+		All(&sessions); err != nil {
+		return sqlcon.HandleError(err)
+	}
+
+	events := make([]*outbox.Event, len(sessions))
+	for i, s := range sessions {
+		e, err := outbox.NewSessionRevokedEvent(s.ID, s.IdentityID)
+		if err != nil {
+			return err
+		}
+		events[i] = e
+	}
+	return p.AddOutboxEvents(ctx, events...)
+}
+
 func (p *Persister) DeleteSession(ctx context.Context, sid uuid.UUID) (err error) {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteSession")
 	defer otelx.End(span, &err)
@@ -414,21 +462,27 @@ func (p *Persister) RevokeSessionByToken(ctx context.Context, token string) (err
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.RevokeSessionByToken")
 	defer otelx.End(span, &err)
 
-	//#nosec G201 -- TableName is static
-	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
-		"UPDATE %s SET active = false WHERE token = ? AND nid = ?",
-		session.Session{}.TableName(),
-	),
-		token,
-		p.NetworkID(ctx),
-	).ExecWithCount()
-	if err != nil {
-		return sqlcon.HandleError(err)
-	}
-	if count == 0 {
-		return errors.WithStack(sqlcon.ErrNoRows)
-	}
-	return nil
+	return p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+		if err := p.addSessionRevokedEvents(ctx, "token = ?", token); err != nil {
+			return err
+		}
+
+		//#nosec G201 -- TableName is static
+		count, err := tx.RawQuery(fmt.Sprintf(
+			"UPDATE %s SET active = false WHERE token = ? AND nid = ?",
+			session.Session{}.TableName(),
+		),
+			token,
+			p.NetworkID(ctx),
+		).ExecWithCount()
+		if err != nil {
+			return sqlcon.HandleError(err)
+		}
+		if count == 0 {
+			return errors.WithStack(sqlcon.ErrNoRows)
+		}
+		return nil
+	})
 }
 
 // RevokeSessionById revokes a given session
@@ -436,21 +490,27 @@ func (p *Persister) RevokeSessionById(ctx context.Context, sID uuid.UUID) (err e
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.RevokeSessionById")
 	defer otelx.End(span, &err)
 
-	//#nosec G201 -- TableName is static
-	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
-		"UPDATE %s SET active = false WHERE id = ? AND nid = ?",
-		session.Session{}.TableName(),
-	),
-		sID,
-		p.NetworkID(ctx),
-	).ExecWithCount()
-	if err != nil {
-		return sqlcon.HandleError(err)
-	}
-	if count == 0 {
-		return errors.WithStack(sqlcon.ErrNoRows)
-	}
-	return nil
+	return p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+		if err := p.addSessionRevokedEvents(ctx, "id = ?", sID); err != nil {
+			return err
+		}
+
+		//#nosec G201 -- TableName is static
+		count, err := tx.RawQuery(fmt.Sprintf(
+			"UPDATE %s SET active = false WHERE id = ? AND nid = ?",
+			session.Session{}.TableName(),
+		),
+			sID,
+			p.NetworkID(ctx),
+		).ExecWithCount()
+		if err != nil {
+			return sqlcon.HandleError(err)
+		}
+		if count == 0 {
+			return errors.WithStack(sqlcon.ErrNoRows)
+		}
+		return nil
+	})
 }
 
 // RevokeSession revokes a given session. If the session does not exist or was not modified,
@@ -459,19 +519,21 @@ func (p *Persister) RevokeSession(ctx context.Context, iID, sID uuid.UUID) (err
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.RevokeSession")
 	defer otelx.End(span, &err)
 
-	//#nosec G201 -- TableName is static
-	err = p.GetConnection(ctx).RawQuery(fmt.Sprintf(
-		"UPDATE %s SET active = false WHERE id = ? AND identity_id = ? AND nid = ?",
-		session.Session{}.TableName(),
-	),
-		sID,
-		iID,
-		p.NetworkID(ctx),
-	).Exec()
-	if err != nil {
-		return sqlcon.HandleError(err)
-	}
-	return nil
+	return p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+		if err := p.addSessionRevokedEvents(ctx, "id = ? AND identity_id = ?", sID, iID); err != nil {
+			return err
+		}
+
+		//#nosec G201 -- TableName is static
+		return sqlcon.HandleError(tx.RawQuery(fmt.Sprintf(
+			"UPDATE %s SET active = false WHERE id = ? AND identity_id = ? AND nid = ?",
+			session.Session{}.TableName(),
+		),
+			sID,
+			iID,
+			p.NetworkID(ctx),
+		).Exec())
+	})
 }
 
 // RevokeSessionsIdentityExcept marks all except the given session of an identity inactive.
@@ -479,19 +541,23 @@ func (p *Persister) RevokeSessionsIdentityExcept(ctx context.Context, iID, sID u
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.RevokeSessionsIdentityExcept")
 	defer otelx.End(span, &err)
 
-	//#nosec G201 -- TableName is static
-	count, err := p.GetConnection(ctx).RawQuery(fmt.Sprintf(
-		"UPDATE %s SET active = false WHERE identity_id = ? AND id != ? AND nid = ?",
-		session.Session{}.TableName(),
-	),
-		iID,
-		sID,
-		p.NetworkID(ctx),
-	).ExecWithCount()
-	if err != nil {
-		return 0, sqlcon.HandleError(err)
-	}
-	return count, nil
+	err = p.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) (err error) {
+		if err := p.addSessionRevokedEvents(ctx, "identity_id = ? AND id != ?", iID, sID); err != nil {
+			return err
+		}
+
+		//#nosec G201 -- TableName is static
+		res, err = tx.RawQuery(fmt.Sprintf(
+			"UPDATE %s SET active = false WHERE identity_id = ? AND id != ? AND nid = ?",
+			session.Session{}.TableName(),
+		),
+			iID,
+			sID,
+			p.NetworkID(ctx),
+		).ExecWithCount()
+		return sqlcon.HandleError(err)
+	})
+	return res, err
 }
 
 func (p *Persister) DeleteExpiredSessions(ctx context.Context, expiresAt time.Time, limit int) (err error) {
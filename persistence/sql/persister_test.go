@@ -27,6 +27,7 @@ import (
 	identity "github.com/ory/kratos/identity/test"
 	"github.com/ory/kratos/internal"
 	"github.com/ory/kratos/internal/testhelpers"
+	outbox "github.com/ory/kratos/outbox/test"
 	"github.com/ory/kratos/persistence/sql"
 	"github.com/ory/kratos/persistence/sql/batch"
 	sqltesthelpers "github.com/ory/kratos/persistence/sql/testhelpers"
@@ -38,9 +39,11 @@ import (
 	registration "github.com/ory/kratos/selfservice/flow/registration/test"
 	settings "github.com/ory/kratos/selfservice/flow/settings/test"
 	verification "github.com/ory/kratos/selfservice/flow/verification/test"
+	ratelimit "github.com/ory/kratos/selfservice/ratelimit/test"
 	sessiontokenexchange "github.com/ory/kratos/selfservice/sessiontokenexchange/test"
 	code "github.com/ory/kratos/selfservice/strategy/code/test"
 	link "github.com/ory/kratos/selfservice/strategy/link/test"
+	oidc "github.com/ory/kratos/selfservice/strategy/oidc/test"
 	session "github.com/ory/kratos/session/test"
 	"github.com/ory/kratos/x"
 	"github.com/ory/pop/v6"
@@ -240,6 +243,14 @@ func TestPersister(t *testing.T) {
 				t.Parallel()
 				sessiontokenexchange.TestPersister(ctx, p)(t)
 			})
+			t.Run("contract=identity.TestLockoutPersister", func(t *testing.T) {
+				t.Parallel()
+				identity.TestLockoutPersister(ctx, p)(t)
+			})
+			t.Run("contract=ratelimit.TestPersister", func(t *testing.T) {
+				t.Parallel()
+				ratelimit.TestPersister(ctx, p)(t)
+			})
 			t.Run("contract=courier.TestPersister", func(t *testing.T) {
 				t.Parallel()
 				upsert, insert := sqltesthelpers.DefaultNetworkWrapper(p)
@@ -257,6 +268,10 @@ func TestPersister(t *testing.T) {
 				t.Parallel()
 				link.TestPersister(ctx, p)(t)
 			})
+			t.Run("contract=oidc.TestPersister", func(t *testing.T) {
+				t.Parallel()
+				oidc.TestPersister(ctx, p)(t)
+			})
 			t.Run("contract=code.TestPersister", func(t *testing.T) {
 				t.Parallel()
 				code.TestPersister(ctx, p)(t)
@@ -265,6 +280,10 @@ func TestPersister(t *testing.T) {
 				t.Parallel()
 				continuity.TestPersister(ctx, p)(t)
 			})
+			t.Run("contract=outbox.TestPersister", func(t *testing.T) {
+				t.Parallel()
+				outbox.TestPersister(ctx, p)(t)
+			})
 			t.Run("contract=batch.TestPersister", func(t *testing.T) {
 				t.Parallel()
 				batch.TestPersister(ctx, reg.Tracer(ctx), p)(t)
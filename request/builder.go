@@ -100,6 +100,11 @@ func NewBuilder(ctx context.Context, c *Config, deps Dependencies, o ...BuilderO
 		return nil, err
 	}
 
+	c.signer, err = newSigner(c.Signing.Secrets)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Builder{
 		r:            r,
 		Config:       c,
@@ -235,6 +240,10 @@ func (b *Builder) BuildRequest(ctx context.Context, body interface{}) (*retryabl
 		}
 	}
 
+	if err := b.Config.signer.apply(b.r, time.Now()); err != nil {
+		return nil, err
+	}
+
 	return b.r, nil
 }
 
@@ -276,6 +285,10 @@ func (b *Builder) BuildRawRequest(body any) (*retryablehttp.Request, error) {
 		}
 	}
 
+	if err := b.Config.signer.apply(b.r, time.Now()); err != nil {
+		return nil, err
+	}
+
 	return b.r, nil
 }
 
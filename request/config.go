@@ -16,6 +16,9 @@ type (
 		Parse  bool `json:"parse" koanf:"parse"`
 		Ignore bool `json:"ignore" koanf:"ignore"`
 	}
+	SigningConfig = struct {
+		Secrets []string `json:"secrets" koanf:"secrets"`
+	}
 	Config struct {
 		ID                 string            `json:"id" koanf:"id"`
 		Method             string            `json:"method" koanf:"method"`
@@ -26,8 +29,10 @@ type (
 		EmitAnalyticsEvent *bool             `json:"emit_analytics_event" koanf:"emit_analytics_event"`
 		CanInterrupt       bool              `json:"can_interrupt" koanf:"can_interrupt"`
 		Response           ResponseConfig    `json:"response" koanf:"response"`
+		Signing            SigningConfig     `json:"signing" koanf:"signing"`
 
 		auth   AuthStrategy
+		signer *signer
 		header http.Header
 	}
 )
@@ -0,0 +1,92 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package request
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+
+	"github.com/ory/kratos/x"
+)
+
+// The headers follow the Standard Webhooks specification, see
+// https://www.standardwebhooks.com.
+const (
+	HeaderWebhookID        = "webhook-id"
+	HeaderWebhookTimestamp = "webhook-timestamp"
+	HeaderWebhookSignature = "webhook-signature"
+
+	signingSecretPrefix = "whsec_"
+	signatureVersion    = "v1"
+)
+
+type signer struct {
+	secrets [][]byte
+}
+
+// newSigner decodes the signing secrets. Secrets with the `whsec_` prefix are
+// base64 encoded, all other secrets are used as is.
+func newSigner(secrets []string) (*signer, error) {
+	s := &signer{secrets: make([][]byte, 0, len(secrets))}
+	for i, secret := range secrets {
+		if encoded, ok := strings.CutPrefix(secret, signingSecretPrefix); ok {
+			key, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("signing secret %d is not valid base64: %w", i, err)
+			}
+			s.secrets = append(s.secrets, key)
+			continue
+		}
+
+		if secret == "" {
+			return nil, fmt.Errorf("signing secret %d must not be empty", i)
+		}
+		s.secrets = append(s.secrets, []byte(secret))
+	}
+	return s, nil
+}
+
+// apply signs the request body with every secret. Receivers accept the
+// request if any of the signatures matches, which allows rotating secrets
+// without downtime.
+func (s *signer) apply(req *retryablehttp.Request, now time.Time) error {
+	if len(s.secrets) == 0 {
+		return nil
+	}
+
+	body, err := req.BodyBytes()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	id := "msg_" + x.NewUUID().String()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	signatures := make([]string, len(s.secrets))
+	for i, secret := range s.secrets {
+		signatures[i] = signatureVersion + "," + sign(secret, id, timestamp, body)
+	}
+
+	// The header is shared with the configuration and must not be modified.
+	req.Header = req.Header.Clone()
+	req.Header.Set(HeaderWebhookID, id)
+	req.Header.Set(HeaderWebhookTimestamp, timestamp)
+	req.Header.Set(HeaderWebhookSignature, strings.Join(signatures, " "))
+	return nil
+}
+
+func sign(secret []byte, id, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(id + "." + timestamp + "."))
+	_, _ = mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,99 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package request
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigning(t *testing.T) {
+	t.Parallel()
+
+	expectedSignature := func(secret []byte, id, timestamp string, body []byte) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(id + "." + timestamp + "."))
+		mac.Write(body)
+		return "v1," + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	build := func(t *testing.T, c *Config) ([]byte, map[string]string) {
+		rb, err := NewBuilder(context.Background(), c, newTestDependencyProvider(t))
+		require.NoError(t, err)
+
+		req, err := rb.BuildRawRequest(json.RawMessage(`{"foo":"bar"}`))
+		require.NoError(t, err)
+
+		body, err := req.BodyBytes()
+		require.NoError(t, err)
+
+		return body, map[string]string{
+			HeaderWebhookID:        req.Header.Get(HeaderWebhookID),
+			HeaderWebhookTimestamp: req.Header.Get(HeaderWebhookTimestamp),
+			HeaderWebhookSignature: req.Header.Get(HeaderWebhookSignature),
+		}
+	}
+
+	t.Run("case=signs the body with every secret", func(t *testing.T) {
+		t.Parallel()
+
+		c := &Config{
+			URL:     "https://test.kratos.ory.sh/hook",
+			Method:  "POST",
+			Signing: SigningConfig{Secrets: []string{"whsec_" + base64.StdEncoding.EncodeToString([]byte("new-secret")), "old-secret"}},
+		}
+		body, h := build(t, c)
+
+		assert.True(t, strings.HasPrefix(h[HeaderWebhookID], "msg_"))
+		timestamp, err := strconv.ParseInt(h[HeaderWebhookTimestamp], 10, 64)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+
+		assert.Equal(t,
+			expectedSignature([]byte("new-secret"), h[HeaderWebhookID], h[HeaderWebhookTimestamp], body)+" "+
+				expectedSignature([]byte("old-secret"), h[HeaderWebhookID], h[HeaderWebhookTimestamp], body),
+			h[HeaderWebhookSignature])
+
+		t.Run("case=does not modify the configured headers", func(t *testing.T) {
+			assert.Empty(t, c.header.Get(HeaderWebhookSignature))
+		})
+
+		t.Run("case=every request has a new id", func(t *testing.T) {
+			_, other := build(t, c)
+			assert.NotEqual(t, h[HeaderWebhookID], other[HeaderWebhookID])
+		})
+	})
+
+	t.Run("case=does not sign without secrets", func(t *testing.T) {
+		t.Parallel()
+
+		_, h := build(t, &Config{URL: "https://test.kratos.ory.sh/hook", Method: "POST"})
+		assert.Empty(t, h[HeaderWebhookID])
+		assert.Empty(t, h[HeaderWebhookTimestamp])
+		assert.Empty(t, h[HeaderWebhookSignature])
+	})
+
+	t.Run("case=rejects invalid secrets", func(t *testing.T) {
+		t.Parallel()
+
+		for _, secret := range []string{"", "whsec_not base64"} {
+			_, err := NewBuilder(context.Background(), &Config{
+				URL:     "https://test.kratos.ory.sh/hook",
+				Method:  "POST",
+				Signing: SigningConfig{Secrets: []string{secret}},
+			}, newTestDependencyProvider(t))
+			assert.Error(t, err, "%q", secret)
+		}
+	})
+}
@@ -5,6 +5,7 @@ package schema
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -225,6 +226,26 @@ func NewNoVerificationStrategyResponsible() error {
 	})
 }
 
+func NewRecoveryAddressSuppressedError() error {
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     `the recovery address is suppressed`,
+			InstancePtr: "#/",
+		},
+		Messages: new(text.Messages).Add(text.NewErrorValidationRecoveryAddressSuppressed()),
+	})
+}
+
+func NewVerificationAddressSuppressedError() error {
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     `the verification address is suppressed`,
+			InstancePtr: "#/",
+		},
+		Messages: new(text.Messages).Add(text.NewErrorValidationVerificationAddressSuppressed()),
+	})
+}
+
 func NewAddressNotVerifiedError() error {
 	return errors.WithStack(&ValidationError{
 		ValidationError: &jsonschema.ValidationError{
@@ -359,6 +380,46 @@ func NewLoginCodeInvalid() error {
 	})
 }
 
+func NewLoginRateLimitedError(retryAt time.Time) error {
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     `too many failed attempts, please try again later`,
+			InstancePtr: "#/",
+		},
+		Messages: new(text.Messages).Add(text.NewErrorValidationLoginRateLimited(retryAt)),
+	})
+}
+
+func NewCaptchaFailedError() error {
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     `the captcha could not be verified`,
+			InstancePtr: "#/",
+		},
+		Messages: new(text.Messages).Add(text.NewErrorCaptchaFailed()),
+	})
+}
+
+func NewIdentityLockedError(lockedUntil time.Time) error {
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     `the account is temporarily locked`,
+			InstancePtr: "#/",
+		},
+		Messages: new(text.Messages).Add(text.NewErrorValidationLoginIdentityLocked(lockedUntil)),
+	})
+}
+
+func NewPasswordLoginForbiddenError(domain string) error {
+	return errors.WithStack(&ValidationError{
+		ValidationError: &jsonschema.ValidationError{
+			Message:     `password login is not allowed for this domain`,
+			InstancePtr: "#/identifier",
+		},
+		Messages: new(text.Messages).Add(text.NewErrorValidationLoginPasswordForbidden(domain)),
+	})
+}
+
 func NewLinkedCredentialsDoNotMatch() error {
 	return errors.WithStack(&ValidationError{
 		ValidationError: &jsonschema.ValidationError{
@@ -0,0 +1,554 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package scim
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Filter is a parsed SCIM filter expression as defined in RFC 7644,
+// section 3.4.2.2.
+type Filter interface {
+	// Matches returns true if the resource matches the filter.
+	Matches(resource map[string]any) bool
+}
+
+type (
+	logicalFilter struct {
+		and         bool
+		left, right Filter
+	}
+	notFilter struct {
+		filter Filter
+	}
+	attributeFilter struct {
+		path     []string
+		operator string
+		value    any
+	}
+	valuePathFilter struct {
+		attribute string
+		filter    Filter
+	}
+)
+
+var (
+	_ Filter = (*logicalFilter)(nil)
+	_ Filter = (*notFilter)(nil)
+	_ Filter = (*attributeFilter)(nil)
+	_ Filter = (*valuePathFilter)(nil)
+)
+
+// caseExactAttributes are compared case-sensitively. All other string
+// attributes are compared case-insensitively.
+var caseExactAttributes = map[string]bool{
+	"id":         true,
+	"externalid": true,
+}
+
+func (f *logicalFilter) Matches(resource map[string]any) bool {
+	if f.and {
+		return f.left.Matches(resource) && f.right.Matches(resource)
+	}
+	return f.left.Matches(resource) || f.right.Matches(resource)
+}
+
+func (f *notFilter) Matches(resource map[string]any) bool {
+	return !f.filter.Matches(resource)
+}
+
+func (f *valuePathFilter) Matches(resource map[string]any) bool {
+	for _, v := range lookup(resource, []string{f.attribute}) {
+		if m, ok := v.(map[string]any); ok && f.filter.Matches(m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *attributeFilter) Matches(resource map[string]any) bool {
+	values := lookup(resource, f.path)
+	if f.operator == "pr" {
+		for _, v := range values {
+			if !isEmpty(v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if f.operator == "ne" {
+		for _, v := range values {
+			if f.compare("eq", v) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, v := range values {
+		if f.compare(f.operator, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *attributeFilter) compare(operator string, actual any) bool {
+	switch expected := f.value.(type) {
+	case nil:
+		return operator == "eq" && actual == nil
+	case bool:
+		a, ok := actual.(bool)
+		return ok && operator == "eq" && a == expected
+	case float64:
+		a, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch operator {
+		case "eq":
+			return a == expected
+		case "gt":
+			return a > expected
+		case "ge":
+			return a >= expected
+		case "lt":
+			return a < expected
+		case "le":
+			return a <= expected
+		}
+		return false
+	case string:
+		a, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		if !caseExactAttributes[strings.ToLower(f.path[len(f.path)-1])] {
+			a, expected = strings.ToLower(a), strings.ToLower(expected)
+		}
+		switch operator {
+		case "eq":
+			return a == expected
+		case "co":
+			return strings.Contains(a, expected)
+		case "sw":
+			return strings.HasPrefix(a, expected)
+		case "ew":
+			return strings.HasSuffix(a, expected)
+		case "gt":
+			return a > expected
+		case "ge":
+			return a >= expected
+		case "lt":
+			return a < expected
+		case "le":
+			return a <= expected
+		}
+	}
+	return false
+}
+
+func isEmpty(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// lookup resolves the attribute path in the resource. Attribute names are
+// case-insensitive and multi-valued attributes are flattened.
+func lookup(resource map[string]any, path []string) []any {
+	values := []any{resource}
+	for _, name := range path {
+		var next []any
+		for _, v := range values {
+			m, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			_, value, ok := getAttribute(m, name)
+			if !ok {
+				continue
+			}
+			if list, ok := value.([]any); ok {
+				next = append(next, list...)
+			} else {
+				next = append(next, value)
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// getAttribute returns the key and value of the attribute with the given
+// case-insensitive name.
+func getAttribute(m map[string]any, name string) (string, any, bool) {
+	if v, ok := m[name]; ok {
+		return name, v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return k, v, true
+		}
+	}
+	return "", nil, false
+}
+
+// indexedValue is an `attribute eq "value"` expression on an attribute which
+// can be looked up using an index.
+type indexedValue struct {
+	attribute string
+	value     string
+}
+
+// indexedAttributes maps the lower-cased SCIM attribute paths which can be
+// looked up using an index to their canonical name.
+var indexedAttributes = map[string]string{
+	"id":           "id",
+	"externalid":   "externalId",
+	"username":     "userName",
+	"emails":       "emails",
+	"emails.value": "emails",
+}
+
+// indexedValues returns the indexed equality expressions whose matches are a
+// superset of the matches of the filter. Conjunctions need one indexed
+// operand, disjunctions need indexed operands on both sides. It returns false
+// if the filter cannot be resolved using indexes.
+func indexedValues(f Filter) ([]indexedValue, bool) {
+	switch f := f.(type) {
+	case *attributeFilter:
+		attribute, ok := indexedAttributes[strings.ToLower(strings.Join(f.path, "."))]
+		if !ok || f.operator != "eq" {
+			return nil, false
+		}
+		v, ok := f.value.(string)
+		if !ok {
+			return nil, false
+		}
+		return []indexedValue{{attribute: attribute, value: v}}, true
+	case *valuePathFilter:
+		inner, ok := f.filter.(*attributeFilter)
+		if !ok || !strings.EqualFold(f.attribute, "emails") || len(inner.path) != 1 || !strings.EqualFold(inner.path[0], "value") {
+			return nil, false
+		}
+		return indexedValues(&attributeFilter{path: []string{f.attribute}, operator: inner.operator, value: inner.value})
+	case *logicalFilter:
+		left, leftOK := indexedValues(f.left)
+		right, rightOK := indexedValues(f.right)
+		if f.and {
+			if leftOK {
+				return left, true
+			}
+			return right, rightOK
+		}
+		if !leftOK || !rightOK {
+			return nil, false
+		}
+		return append(left, right...), true
+	}
+	return nil, false
+}
+
+// ParseFilter parses a SCIM filter expression.
+func ParseFilter(filter string) (Filter, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, errors.Errorf("unexpected token %q", p.tokens[p.pos].value)
+	}
+	return f, nil
+}
+
+type (
+	tokenKind int
+	token     struct {
+		kind  tokenKind
+		value string
+	}
+	filterParser struct {
+		tokens []token
+		pos    int
+	}
+)
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOpenParen
+	tokenCloseParen
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+var comparisonOperators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true,
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpenParen, value: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenCloseParen, value: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenOpenBracket, value: "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenCloseBracket, value: "]"})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, errors.New("unterminated string")
+			}
+			value, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value})
+			i = end + 1
+		default:
+			end := i
+			for end < len(s) && !strings.ContainsRune(" \t()[]\"", rune(s[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: s[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func (p *filterParser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *filterParser) next() (token, error) {
+	t, ok := p.peek()
+	if !ok {
+		return t, errors.New("unexpected end of filter")
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) peekKeyword(keyword string) bool {
+	t, ok := p.peek()
+	return ok && t.kind == tokenWord && strings.EqualFold(t.value, keyword)
+}
+
+func (p *filterParser) expect(kind tokenKind, value string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != kind {
+		return errors.Errorf("expected %q but got %q", value, t.value)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseFactor() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		if err := p.expect(tokenOpenParen, "("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return &notFilter{filter: f}, nil
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.kind {
+	case tokenOpenParen:
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	case tokenWord:
+		return p.parseAttributeExpression(t.value)
+	}
+
+	return nil, errors.Errorf("unexpected token %q", t.value)
+}
+
+func (p *filterParser) parseAttributeExpression(attribute string) (Filter, error) {
+	path, err := parseAttributePath(attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	if t, ok := p.peek(); ok && t.kind == tokenOpenBracket {
+		if len(path) != 1 {
+			return nil, errors.Errorf("invalid value path %q", attribute)
+		}
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseBracket, "]"); err != nil {
+			return nil, err
+		}
+		return &valuePathFilter{attribute: path[0], filter: f}, nil
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	operator := strings.ToLower(op.value)
+	if op.kind != tokenWord {
+		return nil, errors.Errorf("expected an operator but got %q", op.value)
+	} else if operator == "pr" {
+		return &attributeFilter{path: path, operator: operator}, nil
+	} else if !comparisonOperators[operator] {
+		return nil, errors.Errorf("unknown operator %q", op.value)
+	}
+
+	v, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attributeFilter{path: path, operator: operator, value: value}, nil
+}
+
+func parseValue(t token) (any, error) {
+	if t.kind == tokenString {
+		return t.value, nil
+	} else if t.kind != tokenWord {
+		return nil, errors.Errorf("expected a value but got %q", t.value)
+	}
+
+	switch strings.ToLower(t.value) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	n, err := strconv.ParseFloat(t.value, 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid value %q", t.value)
+	}
+	return n, nil
+}
+
+// parseAttributePath splits an attribute path such as `name.familyName` into
+// its segments. Schema URN prefixes of the core user schema are removed.
+func parseAttributePath(attribute string) ([]string, error) {
+	attribute = strings.TrimPrefix(attribute, SchemaUser+":")
+	if attribute == "" {
+		return nil, errors.New("empty attribute path")
+	}
+
+	path := strings.Split(attribute, ".")
+	for _, segment := range path {
+		if segment == "" || !isAttributeName(segment) {
+			return nil, errors.Errorf("invalid attribute path %q", attribute)
+		}
+	}
+	return path, nil
+}
+
+func isAttributeName(s string) bool {
+	for i, r := range s {
+		if unicode.IsLetter(r) || r == '$' || (i > 0 && (unicode.IsDigit(r) || r == '_' || r == '-')) {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	var resource map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "id": "2819c223-7f76-453a-919d-413861904646",
+  "externalId": "Okta-42",
+  "userName": "Bjensen@example.com",
+  "active": true,
+  "name": {"givenName": "Barbara", "familyName": "Jensen"},
+  "emails": [
+    {"value": "bjensen@example.com", "type": "work", "primary": true},
+    {"value": "babs@jensen.org", "type": "home"}
+  ],
+  "meta": {"lastModified": "2025-01-23T04:56:22Z"},
+  "x509Certificates": []
+}`), &resource))
+
+	for _, tc := range []struct {
+		filter   string
+		expected bool
+	}{
+		{`userName eq "bjensen@example.com"`, true},
+		{`USERNAME Eq "BJENSEN@EXAMPLE.COM"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bjensen@example.com"`, true},
+		{`userName ne "bjensen@example.com"`, false},
+		{`userName co "jensen"`, true},
+		{`userName sw "bj"`, true},
+		{`userName ew "example.com"`, true},
+		{`userName ew "example.org"`, false},
+		{`externalId eq "Okta-42"`, true},
+		{`externalId eq "okta-42"`, false},
+		{`id eq "2819c223-7f76-453a-919d-413861904646"`, true},
+		{`active eq true`, true},
+		{`active eq false`, false},
+		{`name.familyName eq "Jensen"`, true},
+		{`name.middleName pr`, false},
+		{`title pr`, false},
+		{`x509Certificates pr`, false},
+		{`emails pr`, true},
+		{`emails.value eq "babs@jensen.org"`, true},
+		{`emails[type eq "work" and value co "example.com"]`, true},
+		{`emails[type eq "home" and value co "example.com"]`, false},
+		{`meta.lastModified gt "2025-01-01T00:00:00Z"`, true},
+		{`meta.lastModified lt "2025-01-01T00:00:00Z"`, false},
+		{`userName eq "nobody" or name.givenName eq "Barbara"`, true},
+		{`userName eq "nobody" and name.givenName eq "Barbara"`, false},
+		{`not (userName eq "nobody")`, true},
+		{`(userName eq "nobody" or active eq true) and not (emails[type eq "other"])`, true},
+	} {
+		t.Run("filter="+tc.filter, func(t *testing.T) {
+			f, err := ParseFilter(tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, f.Matches(resource))
+		})
+	}
+
+	for _, filter := range []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName foo "bar"`,
+		`userName eq "bar`,
+		`(userName eq "bar"`,
+		`userName eq "bar" and`,
+		`emails[type eq "work"`,
+		`name..givenName eq "bar"`,
+		`userName eq bar`,
+	} {
+		t.Run("invalid="+filter, func(t *testing.T) {
+			_, err := ParseFilter(filter)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestIndexedValues(t *testing.T) {
+	for _, tc := range []struct {
+		filter   string
+		expected []indexedValue
+		ok       bool
+	}{
+		{`externalId eq "a"`, []indexedValue{{"externalId", "a"}}, true},
+		{`USERNAME eq "a"`, []indexedValue{{"userName", "a"}}, true},
+		{`emails.value eq "a"`, []indexedValue{{"emails", "a"}}, true},
+		{`emails[value eq "a"]`, []indexedValue{{"emails", "a"}}, true},
+		{`active eq true and externalId eq "a"`, []indexedValue{{"externalId", "a"}}, true},
+		{`userName eq "a" or id eq "b"`, []indexedValue{{"userName", "a"}, {"id", "b"}}, true},
+		{`active eq true or externalId eq "a"`, nil, false},
+		{`externalId co "a"`, nil, false},
+		{`emails[type eq "work"]`, nil, false},
+		{`not (externalId eq "a")`, nil, false},
+	} {
+		t.Run("filter="+tc.filter, func(t *testing.T) {
+			f, err := ParseFilter(tc.filter)
+			require.NoError(t, err)
+			actual, ok := indexedValues(f)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
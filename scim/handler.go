@@ -0,0 +1,584 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dgraph-io/ristretto/v2"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/herodot"
+	"github.com/ory/jsonschema/v3"
+	"github.com/ory/x/fetcher"
+	"github.com/ory/x/jsonnetsecure"
+	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
+	"github.com/ory/x/urlx"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/x"
+	"github.com/ory/kratos/x/events"
+)
+
+const (
+	RouteBase             = "/scim/v2"
+	RouteOrganizationBase = RouteBase + "/organizations/{organization}"
+
+	RouteUsers                 = "/Users"
+	RouteUser                  = RouteUsers + "/{id}"
+	RouteServiceProviderConfig = "/ServiceProviderConfig"
+
+	defaultCount = 100
+	maxCount     = 1000
+)
+
+type (
+	handlerDependencies interface {
+		identity.PoolProvider
+		identity.PrivilegedPoolProvider
+		identity.ManagementProvider
+		config.Provider
+		x.LoggingProvider
+		x.HTTPClientProvider
+		jsonnetsecure.VMProvider
+	}
+	HandlerProvider interface {
+		SCIMHandler() *Handler
+	}
+	Handler struct {
+		r     handlerDependencies
+		cache *ristretto.Cache[[]byte, []byte]
+	}
+)
+
+func NewHandler(r handlerDependencies) *Handler {
+	cache, _ := ristretto.NewCache(&ristretto.Config[[]byte, []byte]{
+		MaxCost:     10 << 20, // 10MB,
+		NumCounters: 100_000,  // 1kB per snippet -> 10k snippets -> 100k counters
+		BufferItems: 64,
+	})
+	return &Handler{r: r, cache: cache}
+}
+
+// RegisterAdminRoutes registers the SCIM endpoints. Every endpoint is
+// available at `/scim/v2` and, scoped to an organization, at
+// `/scim/v2/organizations/{organization}`.
+func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
+	for _, base := range []string{RouteBase, RouteOrganizationBase} {
+		admin.GET(base+RouteServiceProviderConfig, h.enabled(h.getServiceProviderConfig))
+		admin.GET(base+RouteUsers, h.enabled(h.listUsers))
+		admin.POST(base+RouteUsers, h.enabled(h.createUser))
+		admin.GET(base+RouteUser, h.enabled(h.getUser))
+		admin.PUT(base+RouteUser, h.enabled(h.replaceUser))
+		admin.PATCH(base+RouteUser, h.enabled(h.patchUser))
+		admin.DELETE(base+RouteUser, h.enabled(h.deleteUser))
+	}
+}
+
+func (h *Handler) enabled(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.r.Config().IdentitySCIM(r.Context()).Enabled {
+			h.writeError(w, r, newError(http.StatusNotFound, "", "SCIM provisioning is not enabled."))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// organization returns the organization the request is scoped to, if any.
+func organization(r *http.Request) (uuid.NullUUID, error) {
+	raw := r.PathValue("organization")
+	if raw == "" {
+		return uuid.NullUUID{}, nil
+	}
+
+	id, err := uuid.FromString(raw)
+	if err != nil {
+		return uuid.NullUUID{}, newError(http.StatusNotFound, "", "The organization %q does not exist.", raw)
+	}
+	return uuid.NullUUID{UUID: id, Valid: true}, nil
+}
+
+func (h *Handler) location(ctx context.Context, org uuid.NullUUID, id uuid.UUID) string {
+	base := RouteBase
+	if org.Valid {
+		base += "/organizations/" + org.UUID.String()
+	}
+	return urlx.AppendPaths(h.r.Config().SelfAdminURL(ctx), x.AdminPrefix, base, RouteUsers, id.String()).String()
+}
+
+func (h *Handler) getServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	h.write(w, http.StatusOK, map[string]any{
+		"schemas":               []string{SchemaServiceProviderConfig},
+		"patch":                 map[string]any{"supported": true},
+		"bulk":                  map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":                map[string]any{"supported": true, "maxResults": maxCount},
+		"changePassword":        map[string]any{"supported": false},
+		"sort":                  map[string]any{"supported": false},
+		"etag":                  map[string]any{"supported": false},
+		"authenticationSchemes": []any{},
+	})
+}
+
+func (h *Handler) listUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	org, err := organization(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	query := r.URL.Query()
+	var filter Filter
+	if raw := query.Get("filter"); raw != "" {
+		if filter, err = ParseFilter(raw); err != nil {
+			h.writeError(w, r, newError(http.StatusBadRequest, ErrorTypeInvalidFilter, "The filter is invalid: %s", err))
+			return
+		}
+	}
+
+	startIndex, count := 1, defaultCount
+	if v, err := strconv.Atoi(query.Get("startIndex")); err == nil && v > 1 {
+		startIndex = v
+	}
+	if v, err := strconv.Atoi(query.Get("count")); err == nil {
+		count = min(max(v, 0), maxCount)
+	}
+
+	if filter == nil {
+		h.listAllUsers(w, r, org, startIndex, count)
+		return
+	}
+
+	candidates, err := h.candidates(ctx, filter, org)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	matches := make([]map[string]any, 0)
+	for k := range candidates {
+		i := &candidates[k]
+		if org.Valid && i.OrganizationID != org {
+			continue
+		}
+		resource, ok := resourceFromIdentity(i, h.location(ctx, org, i.ID))
+		if !ok || (filter != nil && !filter.Matches(resource)) {
+			continue
+		}
+		matches = append(matches, resource)
+	}
+
+	page := matches[min(startIndex-1, len(matches)):min(startIndex-1+count, len(matches))]
+	h.write(w, http.StatusOK, &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(matches),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	})
+}
+
+// listAllUsers lists the provisioned users without a filter. Only the
+// requested page is loaded from the database.
+func (h *Handler) listAllUsers(w http.ResponseWriter, r *http.Request, org uuid.NullUUID, startIndex, count int) {
+	ctx := r.Context()
+	is, total, err := h.r.PrivilegedIdentityPool().ListIdentitiesWithAdminMetadataKey(ctx, metadataKey, org.UUID, startIndex-1, count)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	page := make([]map[string]any, 0, len(is))
+	for k := range is {
+		if resource, ok := resourceFromIdentity(&is[k], h.location(ctx, org, is[k].ID)); ok {
+			page = append(page, resource)
+		}
+	}
+
+	h.write(w, http.StatusOK, &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: int(total),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	})
+}
+
+// candidates returns the identities which may match the filter. Filters are
+// resolved using the indexed credential identifiers and verifiable addresses,
+// filters which cannot be resolved using an index are rejected.
+func (h *Handler) candidates(ctx context.Context, filter Filter, org uuid.NullUUID) ([]identity.Identity, error) {
+	values, ok := indexedValues(filter)
+	if !ok {
+		return nil, newError(http.StatusBadRequest, ErrorTypeInvalidFilter, "The filter must contain an equality expression on one of the attributes id, externalId, userName, or emails.")
+	}
+
+	var (
+		candidates []identity.Identity
+		seen       = make(map[uuid.UUID]bool)
+	)
+	for _, v := range values {
+		ids, err := h.lookupIdentityIDs(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			i, err := h.r.IdentityPool().GetIdentity(ctx, id, identity.ExpandNothing)
+			if errors.Is(err, sqlcon.ErrNoRows) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, *i)
+		}
+	}
+	return candidates, nil
+}
+
+// lookupIdentityIDs returns the IDs of the identities which may have the
+// indexed attribute value.
+func (h *Handler) lookupIdentityIDs(ctx context.Context, v indexedValue) (ids []uuid.UUID, err error) {
+	add := func(id uuid.UUID, err error) error {
+		if errors.Is(err, sqlcon.ErrNoRows) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		return nil
+	}
+
+	pool := h.r.PrivilegedIdentityPool()
+	switch v.attribute {
+	case "id":
+		if id, err := uuid.FromString(v.value); err == nil {
+			ids = append(ids, id)
+		}
+	case "externalId":
+		err = add(identityID(pool.FindIdentityByExternalID(ctx, v.value, identity.ExpandNothing)))
+	case "userName":
+		err = add(identityID(pool.FindIdentityByCredentialIdentifier(ctx, v.value, false)))
+	case "emails":
+		err = add(addressIdentityID(pool.FindVerifiableAddressByValue(ctx, identity.AddressTypeEmail, v.value)))
+		if err == nil {
+			// Email addresses are usually also credential identifiers, which
+			// are indexed even if the address is not verifiable.
+			err = add(identityID(pool.FindIdentityByCredentialIdentifier(ctx, v.value, false)))
+		}
+	}
+	return ids, err
+}
+
+func identityID(i *identity.Identity, err error) (uuid.UUID, error) {
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return i.ID, nil
+}
+
+func addressIdentityID(a *identity.VerifiableAddress, err error) (uuid.UUID, error) {
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return a.IdentityID, nil
+}
+
+// getIdentity returns the identity if it was provisioned using SCIM and is
+// in the scope of the request.
+func (h *Handler) getIdentity(r *http.Request, org uuid.NullUUID) (*identity.Identity, error) {
+	id, err := uuid.FromString(r.PathValue("id"))
+	if err != nil {
+		return nil, newError(http.StatusNotFound, "", "The user does not exist.")
+	}
+
+	i, err := h.r.PrivilegedIdentityPool().GetIdentityConfidential(r.Context(), id)
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		return nil, newError(http.StatusNotFound, "", "The user does not exist.")
+	} else if err != nil {
+		return nil, err
+	}
+
+	if org.Valid && i.OrganizationID != org {
+		return nil, newError(http.StatusNotFound, "", "The user does not exist.")
+	}
+	if !gjson.GetBytes(i.MetadataAdmin, metadataKey).IsObject() {
+		return nil, newError(http.StatusNotFound, "", "The user was not provisioned using SCIM.")
+	}
+
+	return i, nil
+}
+
+func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) {
+	org, err := organization(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	i, err := h.getIdentity(r, org)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	resource, _ := resourceFromIdentity(i, h.location(r.Context(), org, i.ID))
+	h.write(w, http.StatusOK, resource)
+}
+
+func (h *Handler) createUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	org, err := organization(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	resource, err := decodeResource(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	i := identity.NewIdentity(h.r.Config().IdentitySCIM(ctx).SchemaID)
+	i.OrganizationID = org
+	if err := h.mapResource(ctx, i, resource); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	if err := h.r.IdentityManager().Create(ctx, i); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	location := h.location(ctx, org, i.ID)
+	resource, _ = resourceFromIdentity(i, location)
+	w.Header().Set("Location", location)
+	h.write(w, http.StatusCreated, resource)
+}
+
+func (h *Handler) replaceUser(w http.ResponseWriter, r *http.Request) {
+	org, err := organization(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	i, err := h.getIdentity(r, org)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	resource, err := decodeResource(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.updateUser(w, r, org, i, resource)
+}
+
+func (h *Handler) patchUser(w http.ResponseWriter, r *http.Request) {
+	org, err := organization(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	i, err := h.getIdentity(r, org)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	var patch PatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		h.writeError(w, r, newError(http.StatusBadRequest, ErrorTypeInvalidSyntax, "The request body is invalid: %s", err))
+		return
+	}
+
+	resource, _ := resourceFromIdentity(i, "")
+	if err := applyPatch(resource, patch.Operations); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.updateUser(w, r, org, i, resource)
+}
+
+func (h *Handler) updateUser(w http.ResponseWriter, r *http.Request, org uuid.NullUUID, i *identity.Identity, resource map[string]any) {
+	ctx := r.Context()
+	if err := h.mapResource(ctx, i, resource); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	if err := h.r.IdentityManager().Update(ctx, i, identity.ManagerAllowWriteProtectedTraits); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	resource, _ = resourceFromIdentity(i, h.location(ctx, org, i.ID))
+	h.write(w, http.StatusOK, resource)
+}
+
+func (h *Handler) deleteUser(w http.ResponseWriter, r *http.Request) {
+	org, err := organization(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	i, err := h.getIdentity(r, org)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	if err := h.r.PrivilegedIdentityPool().DeleteIdentity(r.Context(), i.ID); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeResource(r *http.Request) (map[string]any, error) {
+	var resource map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&resource); err != nil {
+		return nil, newError(http.StatusBadRequest, ErrorTypeInvalidSyntax, "The request body is invalid: %s", err)
+	}
+	return resource, nil
+}
+
+// mapResource maps the SCIM user resource to the identity using the
+// configured Jsonnet mapper.
+func (h *Handler) mapResource(ctx context.Context, i *identity.Identity, resource map[string]any) (err error) {
+	userName, err := stringAttribute(resource, "userName")
+	if err != nil {
+		return err
+	} else if userName == "" {
+		return newError(http.StatusBadRequest, ErrorTypeInvalidValue, "The attribute userName is required.")
+	}
+
+	externalID, err := stringAttribute(resource, "externalId")
+	if err != nil {
+		return err
+	}
+
+	active, err := isActive(resource)
+	if err != nil {
+		return err
+	}
+
+	input, err := json.Marshal(resource)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	mapper := h.r.Config().IdentitySCIM(ctx).MapperURL
+	snippet, err := fetcher.NewFetcher(fetcher.WithClient(h.r.HTTPClient(ctx)), fetcher.WithCache(h.cache, 60*time.Minute)).FetchContext(ctx, mapper)
+	if err != nil {
+		return err
+	}
+
+	vm, err := h.r.JsonnetVM(ctx)
+	if err != nil {
+		return err
+	}
+
+	vm.ExtCode("scim", string(input))
+	evaluated, err := vm.EvaluateAnonymousSnippet(mapper, snippet.String())
+	if err != nil {
+		trace.SpanFromContext(ctx).AddEvent(events.NewJsonnetMappingFailed(ctx, err, input, evaluated, "", "scim"))
+		return errors.WithStack(herodot.ErrBadRequest.WithWrap(err).WithDebug(err.Error()).WithReasonf("Unable to execute the SCIM Jsonnet mapper."))
+	}
+
+	traits := gjson.Get(evaluated, "identity.traits")
+	if !traits.IsObject() {
+		return errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("SCIM Jsonnet mapper did not return an object for key identity.traits. Please check your Jsonnet code!"))
+	}
+	i.Traits = identity.Traits(traits.Raw)
+
+	for key, target := range map[string]*sqlxx.NullJSONRawMessage{
+		"identity.metadata_public": &i.MetadataPublic,
+		"identity.metadata_admin":  &i.MetadataAdmin,
+	} {
+		metadata := gjson.Get(evaluated, key)
+		if !metadata.Exists() {
+			// Keep the metadata which was set through the identity API.
+			continue
+		} else if !metadata.IsObject() {
+			return errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("SCIM Jsonnet mapper did not return an object for key %s. Please check your Jsonnet code!", key))
+		}
+		*target = []byte(metadata.Raw)
+	}
+
+	if err := storeResource(i, resource); err != nil {
+		return err
+	}
+
+	i.ExternalID = sqlxx.NullString(externalID)
+
+	state := identity.StateActive
+	if !active {
+		state = identity.StateInactive
+	}
+	if i.State != state {
+		stateChangedAt := sqlxx.NullTime(time.Now())
+		i.State = state
+		i.StateChangedAt = &stateChangedAt
+	}
+
+	return nil
+}
+
+func (h *Handler) write(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the error as a SCIM error response.
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var scimErr *Error
+	if !errors.As(err, &scimErr) {
+		switch {
+		case errors.Is(err, sqlcon.ErrUniqueViolation) || errors.As(err, new(*identity.ErrDuplicateCredentials)):
+			scimErr = newError(http.StatusConflict, ErrorTypeUniqueness, "The user conflicts with an existing identity.")
+		case errors.As(err, new(*jsonschema.ValidationError)) || errors.As(err, new(*schema.ValidationError)):
+			scimErr = newError(http.StatusBadRequest, ErrorTypeInvalidValue, "The mapped identity is invalid: %s", err)
+		default:
+			de := herodot.ToDefaultError(err, "")
+			detail := de.Reason()
+			if detail == "" {
+				detail = de.Error()
+			}
+			scimErr = newError(de.StatusCode(), "", "%s", detail)
+		}
+	}
+
+	if scimErr.StatusCode() >= http.StatusInternalServerError {
+		h.r.Logger().WithRequest(r).WithError(err).Error("An error occurred while handling a SCIM request.")
+	}
+
+	h.write(w, scimErr.StatusCode(), scimErr)
+}
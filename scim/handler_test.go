@@ -0,0 +1,304 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package scim_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/scim"
+	"github.com/ory/x/ioutilx"
+)
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	_, adminTS := testhelpers.NewKratosServerWithCSRF(t, reg)
+
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/identity.schema.json")
+	conf.MustSet(ctx, config.ViperKeyAdminBaseURL, adminTS.URL)
+	conf.MustSet(ctx, config.ViperKeyIdentitySCIM+".enabled", true)
+	conf.MustSet(ctx, config.ViperKeyIdentitySCIM+".mapper_url", "file://./stub/scim.jsonnet")
+
+	do := func(t *testing.T, method, path, body string, expectCode int) gjson.Result {
+		t.Helper()
+		req, err := http.NewRequest(method, adminTS.URL+"/admin/scim/v2"+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", scim.ContentType)
+
+		res, err := adminTS.Client().Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		raw := ioutilx.MustReadAll(res.Body)
+		require.Equal(t, expectCode, res.StatusCode, "%s", raw)
+		if len(raw) > 0 {
+			assert.Equal(t, scim.ContentType, res.Header.Get("Content-Type"))
+		}
+		return gjson.ParseBytes(raw)
+	}
+
+	create := func(t *testing.T, path, userName, externalID string) gjson.Result {
+		t.Helper()
+		return do(t, "POST", path+"/Users", `{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "userName": "`+userName+`",
+  "externalId": "`+externalID+`",
+  "name": {"givenName": "Barbara", "familyName": "Jensen"},
+  "emails": [{"value": "`+userName+`", "type": "work", "primary": true}]
+}`, http.StatusCreated)
+	}
+
+	t.Run("case=is disabled by default", func(t *testing.T) {
+		_, reg := internal.NewFastRegistryWithMocks(t)
+		_, adminTS := testhelpers.NewKratosServerWithCSRF(t, reg)
+
+		res, err := adminTS.Client().Get(adminTS.URL + "/admin/scim/v2/Users")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("case=returns the service provider config", func(t *testing.T) {
+		res := do(t, "GET", "/ServiceProviderConfig", "", http.StatusOK)
+		assert.True(t, res.Get("patch.supported").Bool(), res.Raw)
+		assert.True(t, res.Get("filter.supported").Bool(), res.Raw)
+	})
+
+	t.Run("case=provisions a user", func(t *testing.T) {
+		user := create(t, "", "provision@example.com", "provision")
+		id := user.Get("id").String()
+
+		assert.Equal(t, "provision@example.com", user.Get("userName").String(), user.Raw)
+		assert.Equal(t, "provision", user.Get("externalId").String(), user.Raw)
+		assert.True(t, user.Get("active").Bool(), user.Raw)
+		assert.Equal(t, "User", user.Get("meta.resourceType").String(), user.Raw)
+		assert.Equal(t, adminTS.URL+"/admin/scim/v2/Users/"+id, user.Get("meta.location").String(), user.Raw)
+
+		i, err := reg.IdentityPool().GetIdentity(ctx, uuid.FromStringOrNil(id), identity.ExpandNothing)
+		require.NoError(t, err)
+		assert.Equal(t, "provision@example.com", gjson.GetBytes(i.Traits, "email").String())
+		assert.Equal(t, "Jensen", gjson.GetBytes(i.Traits, "name.last").String())
+		assert.EqualValues(t, "provision", i.ExternalID)
+		assert.Equal(t, identity.StateActive, i.State)
+
+		assert.Equal(t, user.Raw, do(t, "GET", "/Users/"+id, "", http.StatusOK).Raw)
+
+		t.Run("case=rejects duplicates", func(t *testing.T) {
+			res := do(t, "POST", "/Users", `{"userName": "provision@example.com"}`, http.StatusConflict)
+			assert.Equal(t, "uniqueness", res.Get("scimType").String(), res.Raw)
+			assert.Equal(t, "409", res.Get("status").String(), res.Raw)
+		})
+	})
+
+	t.Run("case=rejects invalid users", func(t *testing.T) {
+		res := do(t, "POST", "/Users", `{"name": {"givenName": "Barbara"}}`, http.StatusBadRequest)
+		assert.Equal(t, "invalidValue", res.Get("scimType").String(), res.Raw)
+
+		res = do(t, "POST", "/Users", `{"userName": "not-an-email"}`, http.StatusBadRequest)
+		assert.Equal(t, "invalidValue", res.Get("scimType").String(), res.Raw)
+	})
+
+	t.Run("case=lists users", func(t *testing.T) {
+		first := create(t, "", "list-1@example.com", "list-1")
+		second := create(t, "", "list-2@example.com", "list-2")
+
+		for filter, expected := range map[string][]string{
+			`userName eq "LIST-1@example.com"`:                                          {first.Get("id").String()},
+			`externalId eq "list-2"`:                                                    {second.Get("id").String()},
+			`id eq "` + first.Get("id").String() + `"`:                                  {first.Get("id").String()},
+			`emails[value eq "list-1@example.com"] or userName eq "list-2@example.com"`: {first.Get("id").String(), second.Get("id").String()},
+			`emails.value eq "LIST-2@example.com" and emails[type eq "work"]`:           {second.Get("id").String()},
+			`externalId eq "list-1" and userName eq "nobody"`:                           {},
+			`userName eq "nobody@example.com"`:                                          {},
+		} {
+			t.Run("filter="+filter, func(t *testing.T) {
+				res := do(t, "GET", "/Users?filter="+url.QueryEscape(filter), "", http.StatusOK)
+				assert.Equal(t, scim.SchemaListResponse, res.Get("schemas.0").String(), res.Raw)
+				assert.EqualValues(t, len(expected), res.Get("totalResults").Int(), res.Raw)
+
+				var actual []string
+				for _, r := range res.Get("Resources.#.id").Array() {
+					actual = append(actual, r.String())
+				}
+				assert.ElementsMatch(t, expected, actual, res.Raw)
+			})
+		}
+
+		t.Run("case=paginates", func(t *testing.T) {
+			res := do(t, "GET", "/Users?filter="+url.QueryEscape(`userName eq "list-1@example.com" or userName eq "list-2@example.com"`)+"&startIndex=2&count=1", "", http.StatusOK)
+			assert.EqualValues(t, 2, res.Get("totalResults").Int(), res.Raw)
+			assert.EqualValues(t, 2, res.Get("startIndex").Int(), res.Raw)
+			assert.EqualValues(t, 1, res.Get("itemsPerPage").Int(), res.Raw)
+			assert.Len(t, res.Get("Resources").Array(), 1, res.Raw)
+		})
+
+		t.Run("case=paginates without filter", func(t *testing.T) {
+			first := do(t, "GET", "/Users?count=1", "", http.StatusOK)
+			total := first.Get("totalResults").Int()
+			assert.GreaterOrEqual(t, total, int64(2), first.Raw)
+			assert.EqualValues(t, 1, first.Get("itemsPerPage").Int(), first.Raw)
+			require.Len(t, first.Get("Resources").Array(), 1, first.Raw)
+
+			second := do(t, "GET", "/Users?startIndex=2&count=1", "", http.StatusOK)
+			assert.Equal(t, total, second.Get("totalResults").Int(), second.Raw)
+			assert.EqualValues(t, 2, second.Get("startIndex").Int(), second.Raw)
+			require.Len(t, second.Get("Resources").Array(), 1, second.Raw)
+			assert.NotEqual(t, first.Get("Resources.0.id").String(), second.Get("Resources.0.id").String())
+
+			all := do(t, "GET", "/Users?count=100000", "", http.StatusOK)
+			assert.Equal(t, total, all.Get("itemsPerPage").Int(), all.Raw)
+
+			past := do(t, "GET", fmt.Sprintf("/Users?startIndex=%d", total+1), "", http.StatusOK)
+			assert.Equal(t, total, past.Get("totalResults").Int(), past.Raw)
+			assert.Empty(t, past.Get("Resources").Array(), past.Raw)
+		})
+
+		t.Run("case=rejects invalid filters", func(t *testing.T) {
+			res := do(t, "GET", "/Users?filter="+url.QueryEscape(`userName foo "bar"`), "", http.StatusBadRequest)
+			assert.Equal(t, "invalidFilter", res.Get("scimType").String(), res.Raw)
+		})
+
+		t.Run("case=rejects filters which cannot be resolved using an index", func(t *testing.T) {
+			for _, filter := range []string{
+				`userName sw "list-"`,
+				`emails[type eq "work"]`,
+				`userName eq "list-1@example.com" or active eq true`,
+			} {
+				res := do(t, "GET", "/Users?filter="+url.QueryEscape(filter), "", http.StatusBadRequest)
+				assert.Equal(t, "invalidFilter", res.Get("scimType").String(), res.Raw)
+			}
+		})
+	})
+
+	t.Run("case=patches a user", func(t *testing.T) {
+		id := create(t, "", "patch@example.com", "patch").Get("id").String()
+
+		res := do(t, "PATCH", "/Users/"+id, `{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+  "Operations": [
+    {"op": "Replace", "path": "name.familyName", "value": "Doe"},
+    {"op": "Add", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", "value": "Sales"}
+  ]
+}`, http.StatusOK)
+		assert.Equal(t, "Doe", res.Get("name.familyName").String(), res.Raw)
+
+		i, err := reg.IdentityPool().GetIdentity(ctx, uuid.FromStringOrNil(id), identity.ExpandNothing)
+		require.NoError(t, err)
+		assert.Equal(t, "Doe", gjson.GetBytes(i.Traits, "name.last").String())
+		assert.Equal(t, "Sales", gjson.GetBytes(i.Traits, "department").String())
+
+		t.Run("case=deactivates the user", func(t *testing.T) {
+			res := do(t, "PATCH", "/Users/"+id, `{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+  "Operations": [{"op": "Replace", "value": {"active": "False"}}]
+}`, http.StatusOK)
+			assert.False(t, res.Get("active").Bool(), res.Raw)
+
+			i, err := reg.IdentityPool().GetIdentity(ctx, uuid.FromStringOrNil(id), identity.ExpandNothing)
+			require.NoError(t, err)
+			assert.Equal(t, identity.StateInactive, i.State)
+			assert.Equal(t, "Doe", gjson.GetBytes(i.Traits, "name.last").String())
+		})
+	})
+
+	t.Run("case=replaces a user", func(t *testing.T) {
+		id := create(t, "", "replace@example.com", "replace").Get("id").String()
+
+		res := do(t, "PUT", "/Users/"+id, `{"userName": "replaced@example.com", "active": false}`, http.StatusOK)
+		assert.Equal(t, "replaced@example.com", res.Get("userName").String(), res.Raw)
+		assert.False(t, res.Get("externalId").Exists(), res.Raw)
+		assert.False(t, res.Get("name").Exists(), res.Raw)
+
+		i, err := reg.IdentityPool().GetIdentity(ctx, uuid.FromStringOrNil(id), identity.ExpandNothing)
+		require.NoError(t, err)
+		assert.Equal(t, "replaced@example.com", gjson.GetBytes(i.Traits, "email").String())
+		assert.Equal(t, identity.StateInactive, i.State)
+	})
+
+	t.Run("case=updates keep unrelated metadata", func(t *testing.T) {
+		id := create(t, "", "metadata@example.com", "metadata").Get("id").String()
+
+		i, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, uuid.FromStringOrNil(id))
+		require.NoError(t, err)
+		i.MetadataPublic = []byte(`{"plan":"pro"}`)
+		i.MetadataAdmin, err = sjson.SetBytes(i.MetadataAdmin, "billing_id", "42")
+		require.NoError(t, err)
+		require.NoError(t, reg.IdentityManager().Update(ctx, i, identity.ManagerAllowWriteProtectedTraits))
+
+		do(t, "PUT", "/Users/"+id, `{"userName": "metadata@example.com", "externalId": "metadata", "name": {"familyName": "Doe"}}`, http.StatusOK)
+		do(t, "PATCH", "/Users/"+id, `{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+  "Operations": [{"op": "Replace", "path": "name.givenName", "value": "Jane"}]
+}`, http.StatusOK)
+
+		i, err = reg.IdentityPool().GetIdentity(ctx, uuid.FromStringOrNil(id), identity.ExpandNothing)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"plan":"pro"}`, string(i.MetadataPublic))
+		assert.Equal(t, "42", gjson.GetBytes(i.MetadataAdmin, "billing_id").String(), string(i.MetadataAdmin))
+		assert.Equal(t, "Doe", gjson.GetBytes(i.Traits, "name.last").String())
+		assert.Equal(t, "Jane", gjson.GetBytes(i.Traits, "name.first").String())
+	})
+
+	t.Run("case=deletes a user", func(t *testing.T) {
+		id := create(t, "", "delete@example.com", "delete").Get("id").String()
+
+		do(t, "DELETE", "/Users/"+id, "", http.StatusNoContent)
+		do(t, "GET", "/Users/"+id, "", http.StatusNotFound)
+		do(t, "DELETE", "/Users/"+id, "", http.StatusNotFound)
+	})
+
+	t.Run("case=does not expose identities which were not provisioned", func(t *testing.T) {
+		i := identity.NewIdentity("")
+		i.Traits = identity.Traits(`{"email":"not-provisioned@example.com"}`)
+		require.NoError(t, reg.IdentityManager().Create(ctx, i))
+
+		do(t, "GET", "/Users/"+i.ID.String(), "", http.StatusNotFound)
+		do(t, "PUT", "/Users/"+i.ID.String(), `{"userName": "not-provisioned@example.com"}`, http.StatusNotFound)
+		res := do(t, "GET", "/Users?filter="+url.QueryEscape(`id eq "`+i.ID.String()+`"`), "", http.StatusOK)
+		assert.EqualValues(t, 0, res.Get("totalResults").Int(), res.Raw)
+
+		res = do(t, "GET", "/Users?count=1000", "", http.StatusOK)
+		assert.NotContains(t, res.Get("Resources.#.id").Value(), i.ID.String(), res.Raw)
+	})
+
+	t.Run("case=scopes users to organizations", func(t *testing.T) {
+		org := uuid.Must(uuid.NewV4())
+		base := "/organizations/" + org.String()
+
+		user := create(t, base, "org@example.com", "org")
+		id := user.Get("id").String()
+		assert.Equal(t, adminTS.URL+"/admin/scim/v2"+base+"/Users/"+id, user.Get("meta.location").String(), user.Raw)
+
+		i, err := reg.IdentityPool().GetIdentity(ctx, uuid.FromStringOrNil(id), identity.ExpandNothing)
+		require.NoError(t, err)
+		assert.Equal(t, org, i.OrganizationID.UUID)
+
+		do(t, "GET", base+"/Users/"+id, "", http.StatusOK)
+		do(t, "GET", "/organizations/"+uuid.Must(uuid.NewV4()).String()+"/Users/"+id, "", http.StatusNotFound)
+
+		res := do(t, "GET", base+"/Users", "", http.StatusOK)
+		assert.EqualValues(t, 1, res.Get("totalResults").Int(), res.Raw)
+		assert.Equal(t, id, res.Get("Resources.0.id").String(), res.Raw)
+
+		res = do(t, "GET", "/organizations/"+uuid.Must(uuid.NewV4()).String()+"/Users?filter="+url.QueryEscape(`externalId eq "org"`), "", http.StatusOK)
+		assert.EqualValues(t, 0, res.Get("totalResults").Int(), res.Raw)
+	})
+}
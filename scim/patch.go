@@ -0,0 +1,256 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type patchPath struct {
+	attribute    string
+	filter       Filter
+	subAttribute string
+}
+
+// parsePatchPath parses a PATCH path such as `name.givenName` or
+// `emails[type eq "work"].value` as defined in RFC 7644, section 3.5.2.
+func parsePatchPath(path string) (*patchPath, error) {
+	var p patchPath
+	if open := strings.Index(path, "["); open >= 0 {
+		end := strings.LastIndex(path, "]")
+		if end < open {
+			return nil, newError(http.StatusBadRequest, ErrorTypeInvalidPath, "The path %q is invalid.", path)
+		}
+
+		filter, err := ParseFilter(path[open+1 : end])
+		if err != nil {
+			return nil, newError(http.StatusBadRequest, ErrorTypeInvalidPath, "The path %q is invalid: %s", path, err)
+		}
+
+		p.attribute, p.filter = path[:open], filter
+		if rest := path[end+1:]; rest != "" {
+			if !strings.HasPrefix(rest, ".") {
+				return nil, newError(http.StatusBadRequest, ErrorTypeInvalidPath, "The path %q is invalid.", path)
+			}
+			p.subAttribute = rest[1:]
+		}
+	} else {
+		p.attribute, p.subAttribute, _ = strings.Cut(path, ".")
+	}
+
+	if !isAttributeName(p.attribute) || p.attribute == "" ||
+		(p.subAttribute != "" && !isAttributeName(p.subAttribute)) {
+		return nil, newError(http.StatusBadRequest, ErrorTypeInvalidPath, "The path %q is invalid.", path)
+	}
+
+	return &p, nil
+}
+
+// applyPatch applies the operations of a SCIM PATCH request to the resource.
+func applyPatch(resource map[string]any, operations []PatchOperation) error {
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return newError(http.StatusBadRequest, ErrorTypeInvalidSyntax, "The operation %q is not supported.", operation.Op)
+		}
+
+		var value any
+		if len(operation.Value) > 0 {
+			if err := json.Unmarshal(operation.Value, &value); err != nil {
+				return newError(http.StatusBadRequest, ErrorTypeInvalidSyntax, "The value of the %s operation is invalid: %s", operation.Op, err)
+			}
+		}
+
+		if operation.Path != "" {
+			if err := applyPatchPath(resource, op, operation.Path, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Without a path, the value contains the attributes to add or replace.
+		// Some identity providers use paths as keys, for example
+		// `name.givenName`, so each key is applied as a path.
+		attributes, ok := value.(map[string]any)
+		if op == "remove" || !ok {
+			return newError(http.StatusBadRequest, ErrorTypeNoTarget, "The %s operation requires a path.", operation.Op)
+		}
+		for path, v := range attributes {
+			// Schema extensions are objects keyed by the schema URN whose
+			// attributes are applied one by one.
+			if extension, ok := v.(map[string]any); ok && strings.HasPrefix(path, "urn:") && path != SchemaUser {
+				for name, vv := range extension {
+					if err := applyPatchPath(resource, op, path+":"+name, vv); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			if err := applyPatchPath(resource, op, path, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func applyPatchPath(resource map[string]any, op, path string, value any) error {
+	path = strings.TrimPrefix(path, SchemaUser+":")
+	if strings.HasPrefix(path, "urn:") {
+		// Attributes of schema extensions, such as
+		// `urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department`,
+		// are nested in an object keyed by the schema URN.
+		i := strings.LastIndex(path, ":")
+		key, current, ok := getAttribute(resource, path[:i])
+		if !ok {
+			key = path[:i]
+		}
+		extension, ok := current.(map[string]any)
+		if !ok {
+			if op == "remove" {
+				return nil
+			}
+			extension = map[string]any{}
+			resource[key] = extension
+		}
+		return applyPatchPath(extension, op, path[i+1:], value)
+	}
+
+	p, err := parsePatchPath(path)
+	if err != nil {
+		return err
+	}
+
+	key, current, exists := getAttribute(resource, p.attribute)
+	if !exists {
+		key = p.attribute
+	}
+
+	if p.filter != nil {
+		list, _ := current.([]any)
+		list, err := applyToFilteredValues(list, op, p, value)
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			delete(resource, key)
+		} else {
+			resource[key] = list
+		}
+		return nil
+	}
+
+	if p.subAttribute != "" {
+		switch current := current.(type) {
+		case []any:
+			for _, element := range current {
+				if m, ok := element.(map[string]any); ok {
+					setAttribute(m, op, p.subAttribute, value)
+				}
+			}
+		case map[string]any:
+			setAttribute(current, op, p.subAttribute, value)
+		default:
+			if op != "remove" {
+				resource[key] = map[string]any{p.subAttribute: value}
+			}
+		}
+		return nil
+	}
+
+	setAttribute(resource, op, key, value)
+	return nil
+}
+
+func applyToFilteredValues(list []any, op string, p *patchPath, value any) ([]any, error) {
+	var matched bool
+	result := make([]any, 0, len(list))
+	for _, element := range list {
+		m, ok := element.(map[string]any)
+		if !ok || !p.filter.Matches(m) {
+			result = append(result, element)
+			continue
+		}
+
+		matched = true
+		switch {
+		case op == "remove" && p.subAttribute == "":
+			continue
+		case p.subAttribute != "":
+			setAttribute(m, op, p.subAttribute, value)
+		case op == "replace":
+			if v, ok := value.(map[string]any); ok {
+				m = v
+			}
+		default:
+			setAttribute(m, "add", "", value)
+		}
+		result = append(result, m)
+	}
+
+	if matched || op == "remove" {
+		return result, nil
+	}
+
+	// Identity providers replace values such as `emails[type eq "work"].value`
+	// even if no such value exists yet, in which case it is added.
+	f, ok := p.filter.(*attributeFilter)
+	if !ok || f.operator != "eq" || len(f.path) != 1 {
+		return nil, newError(http.StatusBadRequest, ErrorTypeNoTarget, "No value matches the filter of the path.")
+	}
+
+	element := map[string]any{f.path[0]: f.value}
+	if p.subAttribute != "" {
+		element[p.subAttribute] = value
+	} else {
+		setAttribute(element, "add", "", value)
+	}
+	return append(result, element), nil
+}
+
+// setAttribute adds, replaces, or removes the attribute with the given
+// case-insensitive name. If name is empty, the attributes of value are merged
+// into m.
+func setAttribute(m map[string]any, op, name string, value any) {
+	if name == "" {
+		if v, ok := value.(map[string]any); ok {
+			for k, vv := range v {
+				setAttribute(m, op, k, vv)
+			}
+		}
+		return
+	}
+
+	key, current, exists := getAttribute(m, name)
+	if !exists {
+		key = name
+	}
+
+	switch op {
+	case "remove":
+		delete(m, key)
+	case "add":
+		switch current := current.(type) {
+		case []any:
+			if values, ok := value.([]any); ok {
+				m[key] = append(current, values...)
+			} else {
+				m[key] = append(current, value)
+			}
+			return
+		case map[string]any:
+			if _, ok := value.(map[string]any); ok {
+				setAttribute(current, op, "", value)
+				return
+			}
+		}
+		m[key] = value
+	default:
+		m[key] = value
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	const user = `{
+  "userName": "bjensen@example.com",
+  "name": {"givenName": "Barbara", "familyName": "Jensen"},
+  "emails": [{"value": "bjensen@example.com", "type": "work"}]
+}`
+
+	for _, tc := range []struct {
+		name       string
+		operations string
+		expected   string
+	}{
+		{
+			name:       "replace a simple attribute",
+			operations: `[{"op": "replace", "path": "userName", "value": "barbara@example.com"}]`,
+			expected:   `{"userName": "barbara@example.com", "name": {"givenName": "Barbara", "familyName": "Jensen"}, "emails": [{"value": "bjensen@example.com", "type": "work"}]}`,
+		},
+		{
+			name:       "replace a sub-attribute",
+			operations: `[{"op": "Replace", "path": "name.familyName", "value": "Doe"}]`,
+			expected:   `{"userName": "bjensen@example.com", "name": {"givenName": "Barbara", "familyName": "Doe"}, "emails": [{"value": "bjensen@example.com", "type": "work"}]}`,
+		},
+		{
+			name:       "replace without a path",
+			operations: `[{"op": "replace", "value": {"active": false, "name.givenName": "Babs"}}]`,
+			expected:   `{"userName": "bjensen@example.com", "active": false, "name": {"givenName": "Babs", "familyName": "Jensen"}, "emails": [{"value": "bjensen@example.com", "type": "work"}]}`,
+		},
+		{
+			name:       "add to a multi-valued attribute",
+			operations: `[{"op": "add", "path": "emails", "value": [{"value": "babs@jensen.org", "type": "home"}]}]`,
+			expected:   `{"userName": "bjensen@example.com", "name": {"givenName": "Barbara", "familyName": "Jensen"}, "emails": [{"value": "bjensen@example.com", "type": "work"}, {"value": "babs@jensen.org", "type": "home"}]}`,
+		},
+		{
+			name:       "replace a filtered value",
+			operations: `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "barbara@example.com"}]`,
+			expected:   `{"userName": "bjensen@example.com", "name": {"givenName": "Barbara", "familyName": "Jensen"}, "emails": [{"value": "barbara@example.com", "type": "work"}]}`,
+		},
+		{
+			name:       "replace a filtered value which does not exist yet",
+			operations: `[{"op": "replace", "path": "emails[type eq \"home\"].value", "value": "babs@jensen.org"}]`,
+			expected:   `{"userName": "bjensen@example.com", "name": {"givenName": "Barbara", "familyName": "Jensen"}, "emails": [{"value": "bjensen@example.com", "type": "work"}, {"value": "babs@jensen.org", "type": "home"}]}`,
+		},
+		{
+			name:       "remove a filtered value",
+			operations: `[{"op": "remove", "path": "emails[type eq \"work\"]"}]`,
+			expected:   `{"userName": "bjensen@example.com", "name": {"givenName": "Barbara", "familyName": "Jensen"}}`,
+		},
+		{
+			name:       "remove an attribute",
+			operations: `[{"op": "remove", "path": "name"}]`,
+			expected:   `{"userName": "bjensen@example.com", "emails": [{"value": "bjensen@example.com", "type": "work"}]}`,
+		},
+		{
+			name:       "replace an extension attribute",
+			operations: `[{"op": "replace", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", "value": "Sales"}]`,
+			expected:   `{"userName": "bjensen@example.com", "name": {"givenName": "Barbara", "familyName": "Jensen"}, "emails": [{"value": "bjensen@example.com", "type": "work"}], "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"department": "Sales"}}`,
+		},
+		{
+			name:       "add an extension without a path",
+			operations: `[{"op": "add", "value": {"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"department": "Sales"}}}]`,
+			expected:   `{"userName": "bjensen@example.com", "name": {"givenName": "Barbara", "familyName": "Jensen"}, "emails": [{"value": "bjensen@example.com", "type": "work"}], "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"department": "Sales"}}`,
+		},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			var resource map[string]any
+			require.NoError(t, json.Unmarshal([]byte(user), &resource))
+
+			var operations []PatchOperation
+			require.NoError(t, json.Unmarshal([]byte(tc.operations), &operations))
+			require.NoError(t, applyPatch(resource, operations))
+
+			actual, err := json.Marshal(resource)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(actual))
+		})
+	}
+
+	for _, tc := range []struct {
+		name       string
+		operations string
+		scimType   string
+	}{
+		{"unknown operation", `[{"op": "move", "path": "userName"}]`, ErrorTypeInvalidSyntax},
+		{"remove without a path", `[{"op": "remove"}]`, ErrorTypeNoTarget},
+		{"invalid path", `[{"op": "replace", "path": "emails[type eq]", "value": "x"}]`, ErrorTypeInvalidPath},
+		{"no matching value", `[{"op": "replace", "path": "emails[type co \"x\"].value", "value": "x"}]`, ErrorTypeNoTarget},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			var resource map[string]any
+			require.NoError(t, json.Unmarshal([]byte(user), &resource))
+
+			var operations []PatchOperation
+			require.NoError(t, json.Unmarshal([]byte(tc.operations), &operations))
+
+			err := applyPatch(resource, operations)
+			var scimErr *Error
+			require.ErrorAs(t, err, &scimErr)
+			assert.Equal(t, tc.scimType, scimErr.ScimType)
+			assert.Equal(t, 400, scimErr.StatusCode())
+		})
+	}
+}
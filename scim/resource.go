@@ -0,0 +1,198 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/ory/kratos/identity"
+)
+
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	// ContentType is the media type of SCIM requests and responses.
+	ContentType = "application/scim+json"
+
+	// metadataKey is the key in the identity's admin metadata under which the
+	// provisioned SCIM user resource is stored.
+	metadataKey = "scim"
+)
+
+// Error types as defined in RFC 7644, section 3.12.
+const (
+	ErrorTypeInvalidFilter = "invalidFilter"
+	ErrorTypeUniqueness    = "uniqueness"
+	ErrorTypeInvalidSyntax = "invalidSyntax"
+	ErrorTypeInvalidPath   = "invalidPath"
+	ErrorTypeNoTarget      = "noTarget"
+	ErrorTypeInvalidValue  = "invalidValue"
+)
+
+// derivedAttributes are not stored with the resource because they are
+// derived from the identity.
+var derivedAttributes = []string{"id", "externalId", "active", "meta"}
+
+type (
+	// Error is a SCIM error response.
+	Error struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail,omitempty"`
+
+		code int
+	}
+
+	// ListResponse is a SCIM list response.
+	ListResponse struct {
+		Schemas      []string         `json:"schemas"`
+		TotalResults int              `json:"totalResults"`
+		StartIndex   int              `json:"startIndex"`
+		ItemsPerPage int              `json:"itemsPerPage"`
+		Resources    []map[string]any `json:"Resources"`
+	}
+
+	// PatchRequest is a SCIM PATCH request.
+	PatchRequest struct {
+		Schemas    []string         `json:"schemas"`
+		Operations []PatchOperation `json:"Operations"`
+	}
+
+	// PatchOperation is a single operation of a SCIM PATCH request.
+	PatchOperation struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path,omitempty"`
+		Value json.RawMessage `json:"value,omitempty"`
+	}
+)
+
+func newError(code int, scimType string, format string, args ...any) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		Status:   fmt.Sprintf("%d", code),
+		ScimType: scimType,
+		Detail:   fmt.Sprintf(format, args...),
+		code:     code,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+func (e *Error) StatusCode() int {
+	return e.code
+}
+
+// resourceFromIdentity returns the SCIM user resource of an identity, or false
+// if the identity was not provisioned using SCIM.
+func resourceFromIdentity(i *identity.Identity, location string) (map[string]any, bool) {
+	stored := gjson.GetBytes(i.MetadataAdmin, metadataKey)
+	if !stored.IsObject() {
+		return nil, false
+	}
+
+	var resource map[string]any
+	if err := json.Unmarshal([]byte(stored.Raw), &resource); err != nil {
+		return nil, false
+	}
+
+	if _, _, ok := getAttribute(resource, "schemas"); !ok {
+		resource["schemas"] = []any{SchemaUser}
+	}
+	resource["id"] = i.ID.String()
+	if i.ExternalID != "" {
+		resource["externalId"] = string(i.ExternalID)
+	}
+	resource["active"] = i.State == identity.StateActive
+	resource["meta"] = map[string]any{
+		"resourceType": "User",
+		"created":      i.CreatedAt.UTC().Format(time.RFC3339),
+		"lastModified": i.UpdatedAt.UTC().Format(time.RFC3339),
+		"location":     location,
+	}
+
+	return resource, true
+}
+
+// storeResource stores the SCIM user resource in the identity's admin
+// metadata, without the attributes which are derived from the identity.
+func storeResource(i *identity.Identity, resource map[string]any) error {
+	stored := make(map[string]any, len(resource))
+	for k, v := range resource {
+		stored[k] = v
+	}
+	for _, name := range derivedAttributes {
+		if k, _, ok := getAttribute(stored, name); ok {
+			delete(stored, k)
+		}
+	}
+
+	raw, err := json.Marshal(stored)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	metadata := []byte(i.MetadataAdmin)
+	if len(metadata) == 0 || string(metadata) == "null" {
+		metadata = []byte("{}")
+	}
+	metadata, err = sjson.SetRawBytes(metadata, metadataKey, raw)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	i.MetadataAdmin = metadata
+	return nil
+}
+
+// isActive interprets the `active` attribute of a resource. Some identity
+// providers send booleans as strings, which is accepted as well.
+func isActive(resource map[string]any) (bool, error) {
+	_, v, ok := getAttribute(resource, "active")
+	if !ok || v == nil {
+		return true, nil
+	}
+
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(v) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+
+	return false, newError(http.StatusBadRequest, ErrorTypeInvalidValue, "The attribute active must be a boolean.")
+}
+
+// stringAttribute returns the value of a string attribute of a resource.
+func stringAttribute(resource map[string]any, name string) (string, error) {
+	_, v, ok := getAttribute(resource, name)
+	if !ok || v == nil {
+		return "", nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return "", newError(http.StatusBadRequest, ErrorTypeInvalidValue, "The attribute %s must be a string.", name)
+	}
+	return s, nil
+}
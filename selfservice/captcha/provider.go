@@ -0,0 +1,53 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package captcha
+
+import "github.com/ory/kratos/driver/config"
+
+// provider describes the siteverify endpoint and widget of a captcha service.
+type provider struct {
+	verifyURL string
+	scriptURL string
+
+	// class is the class name of the element the widget is rendered into.
+	class string
+
+	// responseField is the form field the widget stores the token in.
+	responseField string
+}
+
+var providers = map[string]provider{
+	"turnstile": {
+		verifyURL:     "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+		scriptURL:     "https://challenges.cloudflare.com/turnstile/v0/api.js",
+		class:         "cf-turnstile",
+		responseField: "cf-turnstile-response",
+	},
+	"hcaptcha": {
+		verifyURL:     "https://api.hcaptcha.com/siteverify",
+		scriptURL:     "https://js.hcaptcha.com/1/api.js",
+		class:         "h-captcha",
+		responseField: "h-captcha-response",
+	},
+	"recaptcha": {
+		verifyURL:     "https://www.google.com/recaptcha/api/siteverify",
+		scriptURL:     "https://www.google.com/recaptcha/api.js",
+		class:         "g-recaptcha",
+		responseField: "g-recaptcha-response",
+	},
+}
+
+func providerFor(c *config.Captcha) provider {
+	p, ok := providers[c.Provider]
+	if !ok {
+		p = providers["turnstile"]
+	}
+	if c.VerifyURL != "" {
+		p.verifyURL = c.VerifyURL
+	}
+	if c.ScriptURL != "" {
+		p.scriptURL = c.ScriptURL
+	}
+	return p
+}
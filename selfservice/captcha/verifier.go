@@ -0,0 +1,211 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package captcha
+
+import (
+	_ "embed"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/decoderx"
+	"github.com/ory/x/otelx"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/ratelimit"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+)
+
+const (
+	// FieldToken is the name of the field the captcha token is submitted in.
+	FieldToken = "captcha_token"
+
+	nodeScript = "captcha_script"
+	nodeWidget = "captcha_widget"
+)
+
+//go:embed .schema/captcha.schema.json
+var captchaSchema []byte
+
+type (
+	dependencies interface {
+		config.Provider
+		x.HTTPClientProvider
+		x.LoggingProvider
+		x.TracingProvider
+		ratelimit.LimiterProvider
+	}
+
+	Provider interface {
+		CaptchaVerifier() *Verifier
+	}
+
+	// Verifier requires and verifies captchas on self-service flows. Tokens
+	// are verified server-side using the siteverify API shared by Cloudflare
+	// Turnstile, hCaptcha, and reCAPTCHA.
+	Verifier struct {
+		d  dependencies
+		hd *decoderx.HTTP
+	}
+
+	submission struct {
+		Token     string `json:"captcha_token"`
+		Turnstile string `json:"cf-turnstile-response"`
+		HCaptcha  string `json:"h-captcha-response"`
+		ReCaptcha string `json:"g-recaptcha-response"`
+	}
+
+	verifyResponse struct {
+		Success    bool     `json:"success"`
+		Score      *float64 `json:"score"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+)
+
+func NewVerifier(d dependencies) *Verifier {
+	return &Verifier{d: d, hd: decoderx.NewHTTP()}
+}
+
+// Required returns true if the flow requires a captcha for this request. If
+// the flow only requires a captcha after failed attempts, the failed attempts
+// of the client IP address are counted by the rate limiter. Forwarding headers
+// are only considered for requests of trusted proxies, so clients cannot
+// evade the captcha by sending a different address with every request.
+func (v *Verifier) Required(r *http.Request, name flow.FlowName) (bool, error) {
+	ctx := r.Context()
+	if !v.d.Config().SecurityCaptcha(ctx).Enabled {
+		return false, nil
+	}
+
+	c := v.d.Config().SecurityCaptchaFlow(ctx, string(name))
+	if !c.Enabled {
+		return false, nil
+	} else if c.AfterFailedAttempts == 0 {
+		return true, nil
+	}
+
+	attempts, err := v.d.RateLimiter().Attempts(ctx, v.d.RateLimiter().IPKey(ctx, r))
+	if err != nil {
+		return false, err
+	}
+	return attempts >= c.AfterFailedAttempts, nil
+}
+
+// PopulateNodes adds the captcha widget to the flow's UI if the flow requires
+// a captcha.
+func (v *Verifier) PopulateNodes(r *http.Request, f flow.Flow) error {
+	if required, err := v.Required(r, f.GetFlowName()); err != nil || !required {
+		return err
+	}
+
+	c := v.d.Config().SecurityCaptcha(r.Context())
+	p := providerFor(c)
+
+	nodes := &f.GetUI().Nodes
+	nodes.Upsert(node.NewScriptField(nodeScript, p.scriptURL, node.CaptchaGroup, ""))
+	nodes.Upsert(node.NewDivisionField(nodeWidget, node.CaptchaGroup, func(a *node.DivisionAttributes) {
+		a.Classname = p.class
+		a.Data = map[string]string{"sitekey": c.SiteKey}
+	}).WithMetaLabel(text.NewCaptchaContainerMessage()))
+	nodes.Upsert(node.NewInputField(FieldToken, nil, node.CaptchaGroup, node.InputAttributeTypeHidden))
+	return nil
+}
+
+// Verify validates the captcha token submitted with the flow if the flow
+// requires a captcha. It must be called before the strategies handle the
+// submission, which can read the request body again afterwards.
+func (v *Verifier) Verify(r *http.Request, f flow.Flow) (err error) {
+	ctx, span := v.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.captcha.Verifier.Verify")
+	defer otelx.End(span, &err)
+
+	if required, err := v.Required(r, f.GetFlowName()); err != nil || !required {
+		return err
+	}
+
+	c := v.d.Config().SecurityCaptcha(ctx)
+	p := providerFor(c)
+
+	var s submission
+	if r.Method != http.MethodGet {
+		if err := v.hd.Decode(r, &s,
+			decoderx.HTTPDecoderSetValidatePayloads(true),
+			decoderx.MustHTTPRawJSONSchemaCompiler(captchaSchema),
+			decoderx.HTTPDecoderJSONFollowsFormFormat()); err != nil {
+			return err
+		}
+	}
+
+	token := s.token(p)
+	if token == "" {
+		return errors.WithStack(schema.NewCaptchaFailedError())
+	}
+
+	form := url.Values{
+		"secret":   {c.SecretKey},
+		"response": {token},
+		"remoteip": {ratelimit.ClientIP(r, v.d.Config().SecurityRateLimit(ctx))},
+	}
+	req, err := retryablehttp.NewRequestWithContext(ctx, "POST", p.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithWrap(err).WithReasonf("%s", err))
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.d.HTTPClient(ctx).Do(req)
+	if err != nil {
+		return errors.WithStack(herodot.ErrUpstreamError.WithWrap(err).WithReason("Unable to verify the captcha."))
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.WithStack(herodot.ErrUpstreamError.WithReasonf("The captcha provider responded with status code %d.", resp.StatusCode))
+	}
+
+	var res verifyResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&res); err != nil {
+		return errors.WithStack(herodot.ErrUpstreamError.WithWrap(err).WithReason("Unable to decode the response of the captcha provider."))
+	}
+
+	if !res.Success {
+		v.d.Logger().
+			WithField("error_codes", res.ErrorCodes).
+			Debug("The captcha provider rejected the captcha token.")
+		return errors.WithStack(schema.NewCaptchaFailedError())
+	}
+
+	if c.MinScore > 0 && res.Score != nil && *res.Score < c.MinScore {
+		v.d.Logger().
+			WithField("score", *res.Score).
+			Debug("The captcha score is below the minimum score.")
+		return errors.WithStack(schema.NewCaptchaFailedError())
+	}
+
+	return nil
+}
+
+func (s *submission) token(p provider) string {
+	if s.Token != "" {
+		return s.Token
+	}
+
+	switch p.responseField {
+	case "cf-turnstile-response":
+		return s.Turnstile
+	case "h-captcha-response":
+		return s.HCaptcha
+	case "g-recaptcha-response":
+		return s.ReCaptcha
+	}
+	return ""
+}
@@ -0,0 +1,204 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package captcha_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/selfservice/captcha"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/container"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/x/contextx"
+)
+
+func TestVerifier(t *testing.T) {
+	ctx := context.Background()
+
+	var received url.Values
+	siteverify := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		received = r.PostForm
+
+		res := map[string]any{"success": r.PostForm.Get("response") != "invalid"}
+		if r.PostForm.Get("response") == "low-score" {
+			res["score"] = 0.1
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	t.Cleanup(siteverify.Close)
+
+	conf, reg := internal.NewVeryFastRegistryWithoutDB(t)
+	conf.MustSet(ctx, config.ViperKeySecurityCaptcha, map[string]any{
+		"enabled":    true,
+		"provider":   "turnstile",
+		"site_key":   "site-key",
+		"secret_key": "secret-key",
+		"verify_url": siteverify.URL,
+		"min_score":  0.5,
+		"flows": map[string]any{
+			"login": map[string]any{"enabled": true},
+		},
+	})
+	v := captcha.NewVerifier(reg)
+
+	newFlow := func() *login.Flow {
+		return &login.Flow{UI: &container.Container{}}
+	}
+
+	newRequest := func(values url.Values) *http.Request {
+		r := httptest.NewRequest("POST", "/self-service/login", strings.NewReader(values.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	assertCaptchaFailed := func(t *testing.T, err error) {
+		e := new(schema.ValidationError)
+		require.ErrorAs(t, err, &e)
+		require.Len(t, e.Messages, 1)
+		assert.Equal(t, text.ErrorValidationCaptchaError, e.Messages[0].ID)
+	}
+
+	t.Run("case=adds the widget", func(t *testing.T) {
+		f := newFlow()
+		require.NoError(t, v.PopulateNodes(newRequest(nil), f))
+
+		require.Len(t, f.UI.Nodes, 3)
+		for _, n := range f.UI.Nodes {
+			assert.Equal(t, node.CaptchaGroup, n.Group)
+		}
+
+		widget := f.UI.Nodes.Find("captcha_widget")
+		require.NotNil(t, widget)
+		assert.Equal(t, "cf-turnstile", widget.Attributes.(*node.DivisionAttributes).Classname)
+		assert.Equal(t, "site-key", widget.Attributes.(*node.DivisionAttributes).Data["sitekey"])
+		assert.Equal(t, text.InfoNodeLabelCaptcha, widget.Meta.Label.ID)
+
+		token := f.UI.Nodes.Find(captcha.FieldToken)
+		require.NotNil(t, token)
+		assert.Equal(t, node.InputAttributeTypeHidden, token.Attributes.(*node.InputAttributes).Type)
+
+		t.Run("case=is idempotent", func(t *testing.T) {
+			require.NoError(t, v.PopulateNodes(newRequest(nil), f))
+			assert.Len(t, f.UI.Nodes, 3)
+		})
+	})
+
+	t.Run("case=accepts valid token", func(t *testing.T) {
+		r := newRequest(url.Values{captcha.FieldToken: {"valid"}, "identifier": {"foo"}})
+		r.Header.Set("True-Client-IP", "198.51.100.1")
+		r.Header.Set("X-Forwarded-For", "198.51.100.1")
+		require.NoError(t, v.Verify(r, newFlow()))
+
+		assert.Equal(t, "secret-key", received.Get("secret"))
+		assert.Equal(t, "valid", received.Get("response"))
+		assert.Equal(t, "192.0.2.1", received.Get("remoteip"), "forwarding headers of untrusted clients must be ignored")
+
+		t.Run("case=keeps the request body", func(t *testing.T) {
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "foo", r.PostForm.Get("identifier"))
+		})
+	})
+
+	t.Run("case=accepts the widget's response field", func(t *testing.T) {
+		require.NoError(t, v.Verify(newRequest(url.Values{"cf-turnstile-response": {"valid"}}), newFlow()))
+	})
+
+	t.Run("case=accepts JSON", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "/self-service/login", strings.NewReader(`{"captcha_token":"valid","method":"password"}`))
+		r.Header.Set("Content-Type", "application/json")
+		require.NoError(t, v.Verify(r, newFlow()))
+	})
+
+	t.Run("case=rejects missing token", func(t *testing.T) {
+		received = nil
+		assertCaptchaFailed(t, v.Verify(newRequest(url.Values{"method": {"password"}}), newFlow()))
+		assert.Nil(t, received)
+	})
+
+	t.Run("case=rejects invalid token", func(t *testing.T) {
+		assertCaptchaFailed(t, v.Verify(newRequest(url.Values{captcha.FieldToken: {"invalid"}}), newFlow()))
+	})
+
+	t.Run("case=rejects low score", func(t *testing.T) {
+		assertCaptchaFailed(t, v.Verify(newRequest(url.Values{captcha.FieldToken: {"low-score"}}), newFlow()))
+	})
+
+	t.Run("case=does nothing if the flow does not require a captcha", func(t *testing.T) {
+		f := newFlow()
+		r := newRequest(nil).WithContext(contextx.WithConfigValue(ctx, config.ViperKeySecurityCaptcha+".flows.login.enabled", false))
+
+		require.NoError(t, v.PopulateNodes(r, f))
+		assert.Empty(t, f.UI.Nodes)
+		require.NoError(t, v.Verify(r, f))
+	})
+
+	t.Run("case=does nothing if disabled", func(t *testing.T) {
+		f := newFlow()
+		r := newRequest(nil).WithContext(contextx.WithConfigValue(ctx, config.ViperKeySecurityCaptcha+".enabled", false))
+
+		require.NoError(t, v.PopulateNodes(r, f))
+		assert.Empty(t, f.UI.Nodes)
+		require.NoError(t, v.Verify(r, f))
+	})
+
+	t.Run("case=requires captcha after failed attempts", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySecurityRateLimit, map[string]any{
+			"enabled": true,
+			"store":   "memory",
+		})
+		conf.MustSet(ctx, config.ViperKeySecurityCaptcha+".flows.login.after_failed_attempts", 2)
+
+		r := newRequest(url.Values{"method": {"password"}})
+		r.RemoteAddr = "192.0.2.2:1234"
+
+		for range 2 {
+			required, err := v.Required(r, newFlow().GetFlowName())
+			require.NoError(t, err)
+			assert.False(t, required)
+
+			require.NoError(t, reg.RateLimiter().Hit(ctx, reg.RateLimiter().IPKey(ctx, r)))
+		}
+
+		required, err := v.Required(r, newFlow().GetFlowName())
+		require.NoError(t, err)
+		assert.True(t, required)
+		assertCaptchaFailed(t, v.Verify(r, newFlow()))
+
+		t.Run("case=spoofed forwarding headers do not evade the captcha", func(t *testing.T) {
+			r := newRequest(url.Values{"method": {"password"}})
+			r.RemoteAddr = "192.0.2.2:4321"
+			r.Header.Set("True-Client-IP", "198.51.100.2")
+			r.Header.Set("X-Forwarded-For", "198.51.100.2")
+
+			required, err := v.Required(r, newFlow().GetFlowName())
+			require.NoError(t, err)
+			assert.True(t, required)
+		})
+
+		t.Run("case=uses the client IP address of trusted proxies", func(t *testing.T) {
+			ctx := contextx.WithConfigValue(ctx, config.ViperKeySecurityRateLimit+".trusted_proxies", []string{"192.0.2.2"})
+			r := newRequest(url.Values{"method": {"password"}}).WithContext(ctx)
+			r.RemoteAddr = "192.0.2.2:4321"
+			r.Header.Set("X-Forwarded-For", "198.51.100.3")
+
+			required, err := v.Required(r, newFlow().GetFlowName())
+			require.NoError(t, err)
+			assert.False(t, required)
+		})
+	})
+}
@@ -17,7 +17,10 @@ import (
 	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x/events"
 
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/captcha"
 	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/ratelimit"
 	"github.com/ory/kratos/text"
 
 	"github.com/pkg/errors"
@@ -49,6 +52,7 @@ type (
 		x.TracingProvider
 		config.Provider
 		sessiontokenexchange.PersistenceProvider
+		captcha.Provider
 
 		FlowPersistenceProvider
 		HandlerProvider
@@ -98,6 +102,8 @@ func (s *ErrorHandler) WriteFlowError(w http.ResponseWriter, r *http.Request, f
 	logger.
 		Info("Encountered self-service login error.")
 
+	ratelimit.SetRetryAfterHeader(w, err)
+
 	if f == nil {
 		trace.SpanFromContext(r.Context()).AddEvent(events.NewLoginFailed(r.Context(), uuid.Nil, "", "", false, err))
 		s.forward(w, r, nil, err)
@@ -119,6 +125,15 @@ func (s *ErrorHandler) WriteFlowError(w http.ResponseWriter, r *http.Request, f
 		return
 	}
 
+	// The captcha may be required after this attempt even if it was not
+	// required when the flow was created.
+	if f.RequestedAAL == identity.AuthenticatorAssuranceLevel1 {
+		if err := s.d.CaptchaVerifier().PopulateNodes(r, f); err != nil {
+			s.forward(w, r, f, err)
+			return
+		}
+	}
+
 	f.UI.ResetMessages()
 	if err := f.UI.ParseError(group, err); err != nil {
 		s.forward(w, r, f, err)
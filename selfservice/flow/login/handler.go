@@ -21,8 +21,10 @@ import (
 	"github.com/ory/kratos/hydra"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/selfservice/captcha"
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/ratelimit"
 	"github.com/ory/kratos/selfservice/sessiontokenexchange"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/text"
@@ -66,6 +68,8 @@ type (
 		ErrorHandlerProvider
 		sessiontokenexchange.PersistenceProvider
 		x.LoggingProvider
+		ratelimit.LimiterProvider
+		captcha.Provider
 	}
 	HandlerProvider interface {
 		LoginHandler() *Handler
@@ -254,6 +258,12 @@ preLoginHook:
 		}
 	}
 
+	if f.RequestedAAL == identity.AuthenticatorAssuranceLevel1 {
+		if err := h.d.CaptchaVerifier().PopulateNodes(r, f); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if f.Refresh {
 		f.UI.Messages.Set(text.NewInfoLoginReAuth())
 	}
@@ -877,6 +887,21 @@ continueLogin:
 		return
 	}
 
+	// The strategies count the failed attempts per IP address, but as the IP
+	// address does not depend on the method used, it is checked here for all
+	// of them.
+	if err := h.d.RateLimiter().Check(ctx, h.d.RateLimiter().IPKey(ctx, r)); err != nil {
+		h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.DefaultGroup, err)
+		return
+	}
+
+	if f.RequestedAAL == identity.AuthenticatorAssuranceLevel1 {
+		if err := h.d.CaptchaVerifier().Verify(r, f); err != nil {
+			h.d.LoginFlowErrorHandler().WriteFlowError(w, r, f, node.CaptchaGroup, err)
+			return
+		}
+	}
+
 	var i *identity.Identity
 	var group node.UiNodeGroup
 	for _, ss := range h.d.AllLoginStrategies() {
@@ -18,6 +18,8 @@ func sortNodes(ctx context.Context, n node.Nodes) error {
 			node.PasskeyGroup,
 			node.CodeGroup,
 			node.PasswordGroup,
+			node.LDAPGroup,
+			node.CaptchaGroup,
 			node.TOTPGroup,
 			node.LookupGroup,
 		}),
@@ -26,6 +26,7 @@ import (
 	"github.com/ory/x/urlx"
 
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/selfservice/captcha"
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/text"
@@ -45,6 +46,7 @@ type (
 		nosurfx.CSRFTokenGeneratorProvider
 		config.Provider
 		StrategyProvider
+		captcha.Provider
 
 		FlowPersistenceProvider
 	}
@@ -138,6 +140,13 @@ func (s *ErrorHandler) WriteFlowError(
 		return
 	}
 
+	if f.awaitsAddress() {
+		if err := s.d.CaptchaVerifier().PopulateNodes(r, f); err != nil {
+			s.forward(w, r, f, err)
+			return
+		}
+	}
+
 	f.UI.ResetMessages()
 	if err := f.UI.ParseError(group, recoveryErr); err != nil {
 		s.forward(w, r, f, err)
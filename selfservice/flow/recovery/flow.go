@@ -195,6 +195,12 @@ func (f *Flow) Valid() error {
 	return nil
 }
 
+// awaitsAddress returns true if the flow waits for the address the recovery
+// message is sent to.
+func (f *Flow) awaitsAddress() bool {
+	return f.State == flow.StateChooseMethod || f.State == flow.StateRecoveryAwaitingAddress
+}
+
 func (f *Flow) AppendTo(src *url.URL) *url.URL {
 	return urlx.CopyWithQuery(src, url.Values{"flow": {f.ID.String()}})
 }
@@ -26,6 +26,7 @@ import (
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/captcha"
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/session"
@@ -57,6 +58,7 @@ type (
 		config.Provider
 		ErrorHandlerProvider
 		HookExecutorProvider
+		captcha.Provider
 	}
 	Handler struct {
 		d handlerDependencies
@@ -138,6 +140,11 @@ func (h *Handler) createNativeRecoveryFlow(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if err := h.d.CaptchaVerifier().PopulateNodes(r, f); err != nil {
+		h.d.Writer().WriteError(w, r, err)
+		return
+	}
+
 	if err := h.d.RecoveryExecutor().PreRecoveryHook(w, r, f); err != nil {
 		h.d.Writer().WriteError(w, r, err)
 		return
@@ -203,6 +210,11 @@ func (h *Handler) createBrowserRecoveryFlow(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if err := h.d.CaptchaVerifier().PopulateNodes(r, f); err != nil {
+		h.d.Writer().WriteError(w, r, err)
+		return
+	}
+
 	if err := h.d.RecoveryExecutor().PreRecoveryHook(w, r, f); err != nil {
 		h.d.Writer().WriteError(w, r, err)
 		return
@@ -422,6 +434,14 @@ func (h *Handler) updateRecoveryFlow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only sending the recovery message is protected by the captcha.
+	if f.awaitsAddress() {
+		if err := h.d.CaptchaVerifier().Verify(r, f); err != nil {
+			h.d.RecoveryFlowErrorHandler().WriteFlowError(w, r, f, node.UiNodeGroup(f.Active.String()), err)
+			return
+		}
+	}
+
 	var g node.UiNodeGroup
 	var found bool
 	for _, ss := range h.d.AllRecoveryStrategies() {
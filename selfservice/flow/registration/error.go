@@ -19,6 +19,7 @@ import (
 	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x/events"
 
+	"github.com/ory/kratos/selfservice/captcha"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/text"
 
@@ -45,6 +46,7 @@ type (
 		config.Provider
 
 		sessiontokenexchange.PersistenceProvider
+		captcha.Provider
 		FlowPersistenceProvider
 		HandlerProvider
 	}
@@ -125,6 +127,11 @@ func (s *ErrorHandler) WriteFlowError(
 		return
 	}
 
+	if err := s.d.CaptchaVerifier().PopulateNodes(r, f); err != nil {
+		s.forward(w, r, f, err)
+		return
+	}
+
 	f.UI.ResetMessages()
 	if err := f.UI.ParseError(group, err); err != nil {
 		s.forward(w, r, f, err)
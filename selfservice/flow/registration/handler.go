@@ -19,6 +19,7 @@ import (
 	"github.com/ory/kratos/hydra"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/selfservice/captcha"
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/sessiontokenexchange"
@@ -60,6 +61,7 @@ type (
 		ErrorHandlerProvider
 		sessiontokenexchange.PersistenceProvider
 		x.LoggingProvider
+		captcha.Provider
 	}
 	HandlerProvider interface {
 		RegistrationHandler() *Handler
@@ -160,6 +162,10 @@ func (h *Handler) NewRegistrationFlow(w http.ResponseWriter, r *http.Request, ft
 		}
 	}
 
+	if err := h.d.CaptchaVerifier().PopulateNodes(r, f); err != nil {
+		return nil, err
+	}
+
 	ds, err := f.IdentitySchema.URL(r.Context(), h.d.Config())
 	if err != nil {
 		return nil, err
@@ -682,6 +688,11 @@ func (h *Handler) updateRegistrationFlow(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := h.d.CaptchaVerifier().Verify(r, f); err != nil {
+		h.d.RegistrationFlowErrorHandler().WriteFlowError(w, r, f, node.CaptchaGroup, err)
+		return
+	}
+
 	i := identity.NewIdentity(f.IdentitySchema.ID(ctx, h.d.Config()))
 	var s Strategy
 	for _, ss := range h.d.AllRegistrationStrategies() {
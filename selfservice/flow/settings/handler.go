@@ -226,6 +226,11 @@ func (h *Handler) createNativeSettingsFlow(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if s.IsImpersonated() {
+		h.d.Writer().WriteError(w, r, errors.WithStack(session.ErrImpersonatedSession))
+		return
+	}
+
 	if err := h.d.SessionManager().DoesSessionSatisfy(ctx, s, h.d.Config().SelfServiceSettingsRequiredAAL(ctx)); err != nil {
 		h.d.Writer().WriteError(w, r, err)
 		return
@@ -310,6 +315,11 @@ func (h *Handler) createBrowserSettingsFlow(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if s.IsImpersonated() {
+		h.d.SelfServiceErrorManager().Forward(ctx, w, r, errors.WithStack(session.ErrImpersonatedSession))
+		return
+	}
+
 	var managerOptions []session.ManagerOptions
 	requestURL := x.RequestURL(r)
 	if requestURL.Query().Get("return_to") != "" {
@@ -593,6 +603,11 @@ func (h *Handler) updateSettingsFlow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if ss.IsImpersonated() {
+		h.d.SettingsFlowErrorHandler().WriteFlowError(ctx, w, r, node.DefaultGroup, f, nil, errors.WithStack(session.ErrImpersonatedSession))
+		return
+	}
+
 	requestURL := x.RequestURL(r).String()
 	if err := h.d.SessionManager().DoesSessionSatisfy(ctx, ss, h.d.Config().SelfServiceSettingsRequiredAAL(ctx), session.WithRequestURL(requestURL)); err != nil {
 		h.d.SettingsFlowErrorHandler().WriteFlowError(ctx, w, r, node.DefaultGroup, f, nil, err)
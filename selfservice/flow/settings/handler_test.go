@@ -167,6 +167,16 @@ func TestHandler(t *testing.T) {
 				))
 			})
 
+			t.Run("description=can not init with an impersonation session", func(t *testing.T) {
+				req := testhelpers.NewTestHTTPRequest(t, "GET", publicTS.URL+"/sessions/whoami", nil)
+				sess, err := reg.SessionManager().IssueImpersonationSession(req, primaryIdentity, session.Impersonator{ID: "support@example.com"})
+				require.NoError(t, err)
+
+				res, body := initFlow(t, testhelpers.NewHTTPClientWithSessionToken(t, ctx, reg, sess), true)
+				assert.Equalf(t, http.StatusForbidden, res.StatusCode, "%s", body)
+				assert.Equalf(t, text.ErrIDImpersonatedSession, gjson.GetBytes(body, "error.id").String(), "%s", body)
+			})
+
 			t.Run("description=can not init if identity has aal2 but session has aal1", func(t *testing.T) {
 				conf.MustSet(ctx, config.ViperKeySelfServiceSettingsRequiredAAL, config.HighestAvailableAAL)
 				res, body := initFlow(t, aal2Identity, true)
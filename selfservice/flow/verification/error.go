@@ -27,6 +27,7 @@ import (
 	"github.com/ory/x/urlx"
 
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/selfservice/captcha"
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/text"
@@ -46,6 +47,7 @@ type (
 		config.Provider
 		FlowPersistenceProvider
 		StrategyProvider
+		captcha.Provider
 	}
 
 	ErrorHandlerProvider interface {
@@ -128,6 +130,13 @@ func (s *ErrorHandler) WriteFlowError(
 		return
 	}
 
+	if f.State == flow.StateChooseMethod {
+		if err := s.d.CaptchaVerifier().PopulateNodes(r, f); err != nil {
+			s.forward(w, r, f, err)
+			return
+		}
+	}
+
 	if err := f.UI.ParseError(group, err); err != nil {
 		s.forward(w, r, f, err)
 		return
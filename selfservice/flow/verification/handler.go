@@ -4,6 +4,7 @@
 package verification
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/captcha"
 	"github.com/ory/kratos/selfservice/errorx"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/x"
@@ -61,6 +63,7 @@ type (
 		ErrorHandlerProvider
 		StrategyProvider
 		HookExecutorProvider
+		captcha.Provider
 	}
 	Handler struct {
 		d handlerDependencies
@@ -114,6 +117,10 @@ func (h *Handler) NewVerificationFlow(w http.ResponseWriter, r *http.Request, ft
 		o(f)
 	}
 
+	if err := h.d.CaptchaVerifier().PopulateNodes(r, f); err != nil {
+		return nil, err
+	}
+
 	if err := h.d.VerificationExecutor().PreVerificationHook(w, r, f); err != nil {
 		return nil, err
 	}
@@ -429,6 +436,14 @@ func (h *Handler) updateVerificationFlow(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Only sending the verification message is protected by the captcha.
+	if f.State == flow.StateChooseMethod {
+		if err := h.d.CaptchaVerifier().Verify(r, f); err != nil {
+			h.d.VerificationFlowErrorHandler().WriteFlowError(w, r, f, h.method(ctx, f), err)
+			return
+		}
+	}
+
 	var g node.UiNodeGroup
 	var found bool
 	for _, ss := range h.d.AllVerificationStrategies() {
@@ -531,3 +546,17 @@ func (h *Handler) updateVerificationFlow(w http.ResponseWriter, r *http.Request)
 
 	h.d.Writer().Write(w, r, updatedFlow)
 }
+
+// method returns the node group of the verification method the flow is
+// submitted with. The active method is not set on flows which were not
+// initialized by this handler, for example after registration, in which case
+// the configured verification method is used.
+func (h *Handler) method(ctx context.Context, f *Flow) node.UiNodeGroup {
+	if f.Active.String() != "" {
+		return node.UiNodeGroup(f.Active.String())
+	}
+	if s, err := h.d.GetActiveVerificationStrategy(ctx); err == nil {
+		return s.NodeGroup()
+	}
+	return node.DefaultGroup
+}
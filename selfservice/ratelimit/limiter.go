@@ -0,0 +1,311 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/otelx"
+	"github.com/ory/x/sqlcon"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/x"
+)
+
+type (
+	Kind string
+
+	// Key identifies the subject of a rate limit.
+	Key struct {
+		Kind  Kind
+		Value string
+	}
+
+	limiterDependencies interface {
+		config.Provider
+		x.LoggingProvider
+		x.TracingProvider
+		PersistenceProvider
+	}
+
+	LimiterProvider interface {
+		RateLimiter() *Limiter
+	}
+
+	// Limiter protects self-service flows against brute-force attacks by
+	// counting failed attempts per key within a fixed window.
+	Limiter struct {
+		d limiterDependencies
+
+		memoryOnce sync.Once
+		memory     *MemoryPersister
+	}
+
+	// Error is returned when a key has exhausted its budget of failed
+	// attempts.
+	Error struct {
+		error
+		RetryAt time.Time
+	}
+)
+
+const (
+	KindIdentifier Kind = "identifier"
+	KindIP         Kind = "ip"
+	KindIdentity   Kind = "identity"
+)
+
+func NewLimiter(d limiterDependencies) *Limiter {
+	return &Limiter{d: d}
+}
+
+// IdentifierKey returns the key for a login identifier such as an email
+// address or username.
+func IdentifierKey(identifier string) Key {
+	return Key{Kind: KindIdentifier, Value: strings.ToLower(strings.TrimSpace(identifier))}
+}
+
+// IPKey returns the key for the client IP address of the request. See
+// ClientIP for how the address is determined.
+func (l *Limiter) IPKey(ctx context.Context, r *http.Request) Key {
+	return Key{Kind: KindIP, Value: ClientIP(r, l.d.Config().SecurityRateLimit(ctx))}
+}
+
+// ClientIP returns the IP address of the client which sent the request.
+//
+// Headers such as X-Forwarded-For can be set by anyone, so they are only
+// considered if the request was received from one of the configured trusted
+// proxies. Otherwise, the remote address of the connection is used.
+func ClientIP(r *http.Request, c *config.RateLimit) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+
+	trusted := trustedProxies(c.TrustedProxies)
+	if !isTrusted(trusted, remote) {
+		return remote
+	}
+
+	header := r.Header.Get(c.ClientIPHeader)
+	if header == "" {
+		return remote
+	}
+
+	if !strings.EqualFold(c.ClientIPHeader, "X-Forwarded-For") {
+		if addr, err := netip.ParseAddr(strings.TrimSpace(header)); err == nil {
+			return addr.Unmap().String()
+		}
+		return remote
+	}
+
+	// Every proxy appends the address it received the request from, so the
+	// right-most address which is not a trusted proxy is the client.
+	hops := strings.Split(header, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return remote
+		}
+		if !isTrusted(trusted, addr.String()) {
+			return addr.Unmap().String()
+		}
+		remote = addr.Unmap().String()
+	}
+
+	return remote
+}
+
+func trustedProxies(proxies []string) []netip.Prefix {
+	result := make([]netip.Prefix, 0, len(proxies))
+	for _, p := range proxies {
+		if prefix, err := netip.ParsePrefix(p); err == nil {
+			result = append(result, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(p); err == nil {
+			result = append(result, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		}
+	}
+	return result
+}
+
+func isTrusted(trusted []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	for _, p := range trusted {
+		if p.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// IdentityKey returns the key for an identity.
+func IdentityKey(id uuid.UUID) Key {
+	if id == uuid.Nil {
+		return Key{Kind: KindIdentity}
+	}
+	return Key{Kind: KindIdentity, Value: id.String()}
+}
+
+func (k Key) String() string {
+	return string(k.Kind) + ":" + k.Value
+}
+
+func (l *Limiter) persister(ctx context.Context) Persister {
+	if l.d.Config().SecurityRateLimit(ctx).Store == "memory" {
+		l.memoryOnce.Do(func() { l.memory = NewMemoryPersister() })
+		return l.memory
+	}
+	return l.d.RateLimitPersister()
+}
+
+func maxAttempts(c *config.RateLimit, kind Kind) int {
+	switch kind {
+	case KindIdentifier:
+		return c.MaxAttemptsPerIdentifier
+	case KindIP:
+		return c.MaxAttemptsPerIP
+	case KindIdentity:
+		return c.MaxAttemptsPerIdentity
+	}
+	return 0
+}
+
+// keys returns the keys which are subject to a limit.
+func keys(c *config.RateLimit, keys []Key) []Key {
+	result := make([]Key, 0, len(keys))
+	for _, k := range keys {
+		if k.Value == "" || maxAttempts(c, k.Kind) <= 0 {
+			continue
+		}
+		result = append(result, k)
+	}
+	return result
+}
+
+// Check returns an *Error if any of the keys has exhausted its budget of
+// failed attempts.
+func (l *Limiter) Check(ctx context.Context, kk ...Key) (err error) {
+	ctx, span := l.d.Tracer(ctx).Tracer().Start(ctx, "selfservice.ratelimit.Limiter.Check")
+	defer otelx.End(span, &err)
+
+	c := l.d.Config().SecurityRateLimit(ctx)
+	if !c.Enabled {
+		return nil
+	}
+
+	for _, k := range keys(c, kk) {
+		b, err := l.persister(ctx).GetRateLimitBucket(ctx, k.String())
+		if errors.Is(err, sqlcon.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if b.Hits >= maxAttempts(c, k.Kind) {
+			l.d.Logger().
+				WithField("rate_limit_kind", k.Kind).
+				WithField("retry_at", b.ExpiresAt).
+				Info("Rejected self-service attempt because the rate limit was exceeded.")
+			return errors.WithStack(&Error{error: schema.NewLoginRateLimitedError(b.ExpiresAt), RetryAt: b.ExpiresAt})
+		}
+	}
+
+	return nil
+}
+
+// Attempts returns the number of failed attempts recorded for the key in the
+// current window.
+func (l *Limiter) Attempts(ctx context.Context, k Key) (_ int, err error) {
+	ctx, span := l.d.Tracer(ctx).Tracer().Start(ctx, "selfservice.ratelimit.Limiter.Attempts")
+	defer otelx.End(span, &err)
+
+	c := l.d.Config().SecurityRateLimit(ctx)
+	if !c.Enabled || len(keys(c, []Key{k})) == 0 {
+		return 0, nil
+	}
+
+	b, err := l.persister(ctx).GetRateLimitBucket(ctx, k.String())
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	return b.Hits, nil
+}
+
+// Hit records a failed attempt for all keys.
+func (l *Limiter) Hit(ctx context.Context, kk ...Key) (err error) {
+	ctx, span := l.d.Tracer(ctx).Tracer().Start(ctx, "selfservice.ratelimit.Limiter.Hit")
+	defer otelx.End(span, &err)
+
+	c := l.d.Config().SecurityRateLimit(ctx)
+	if !c.Enabled {
+		return nil
+	}
+
+	for _, k := range keys(c, kk) {
+		if _, err := l.persister(ctx).IncrementRateLimitBucket(ctx, k.String(), c.Window); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reset forgets the failed attempts of all keys. It is called after a
+// successful attempt.
+func (l *Limiter) Reset(ctx context.Context, kk ...Key) (err error) {
+	ctx, span := l.d.Tracer(ctx).Tracer().Start(ctx, "selfservice.ratelimit.Limiter.Reset")
+	defer otelx.End(span, &err)
+
+	c := l.d.Config().SecurityRateLimit(ctx)
+	if !c.Enabled {
+		return nil
+	}
+
+	for _, k := range keys(c, kk) {
+		if err := l.persister(ctx).DeleteRateLimitBucket(ctx, k.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Error) Unwrap() error {
+	return e.error
+}
+
+func (e *Error) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+// RetryAfter returns the value of the Retry-After header in seconds.
+func (e *Error) RetryAfter() string {
+	return strconv.Itoa(max(1, int(math.Ceil(time.Until(e.RetryAt).Seconds()))))
+}
+
+// SetRetryAfterHeader sets the Retry-After header if the error is caused by
+// an exceeded rate limit.
+func SetRetryAfterHeader(w http.ResponseWriter, err error) {
+	if e := new(Error); errors.As(err, &e) {
+		w.Header().Set("Retry-After", e.RetryAfter())
+	}
+}
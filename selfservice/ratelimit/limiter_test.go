@@ -0,0 +1,134 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/selfservice/ratelimit"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/contextx"
+)
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewVeryFastRegistryWithoutDB(t)
+	conf.MustSet(ctx, config.ViperKeySecurityRateLimit, map[string]any{
+		"enabled":                     true,
+		"store":                       "memory",
+		"max_attempts_per_identifier": 2,
+		"max_attempts_per_ip":         0,
+	})
+	l := ratelimit.NewLimiter(reg)
+
+	t.Run("case=blocks after too many failed attempts", func(t *testing.T) {
+		key := ratelimit.IdentifierKey(x.NewUUID().String())
+
+		for range 2 {
+			require.NoError(t, l.Check(ctx, key))
+			require.NoError(t, l.Hit(ctx, key))
+		}
+
+		err := l.Check(ctx, key)
+		e := new(ratelimit.Error)
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, http.StatusTooManyRequests, e.StatusCode())
+		assert.Equal(t, "900", e.RetryAfter())
+
+		w := httptest.NewRecorder()
+		ratelimit.SetRetryAfterHeader(w, err)
+		assert.Equal(t, "900", w.Header().Get("Retry-After"))
+
+		t.Run("case=identifiers are normalized", func(t *testing.T) {
+			require.Error(t, l.Check(ctx, ratelimit.IdentifierKey(" "+key.Value+" ")))
+		})
+
+		t.Run("case=reset allows new attempts", func(t *testing.T) {
+			require.NoError(t, l.Reset(ctx, key))
+			require.NoError(t, l.Check(ctx, key))
+		})
+	})
+
+	t.Run("case=counts attempts", func(t *testing.T) {
+		key := ratelimit.IdentifierKey(x.NewUUID().String())
+
+		n, err := l.Attempts(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+
+		require.NoError(t, l.Hit(ctx, key))
+		n, err = l.Attempts(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("case=keys without limit are ignored", func(t *testing.T) {
+		key := l.IPKey(ctx, &http.Request{RemoteAddr: "192.0.2.1:1234"})
+		assert.Equal(t, "ip:192.0.2.1", key.String())
+
+		for range 3 {
+			require.NoError(t, l.Hit(ctx, key))
+		}
+		require.NoError(t, l.Check(ctx, key))
+	})
+
+	t.Run("case=does nothing if disabled", func(t *testing.T) {
+		ctx := contextx.WithConfigValue(ctx, config.ViperKeySecurityRateLimit+".enabled", false)
+		key := ratelimit.IdentifierKey(x.NewUUID().String())
+
+		for range 3 {
+			require.NoError(t, l.Hit(ctx, key))
+		}
+		require.NoError(t, l.Check(ctx, key))
+	})
+}
+
+func TestClientIP(t *testing.T) {
+	c := &config.RateLimit{
+		TrustedProxies: []string{"10.0.0.0/8", "192.0.2.10", "not-an-ip"},
+		ClientIPHeader: "X-Forwarded-For",
+	}
+
+	for _, tc := range []struct {
+		name, remote, header, expected string
+	}{
+		{name: "remote address", remote: "198.51.100.1:1234", expected: "198.51.100.1"},
+		{name: "ignores header of untrusted remote", remote: "198.51.100.1:1234", header: "203.0.113.1", expected: "198.51.100.1"},
+		{name: "uses header of trusted proxy", remote: "10.0.0.1:1234", header: "203.0.113.1", expected: "203.0.113.1"},
+		{name: "uses header of trusted proxy address", remote: "192.0.2.10:1234", header: "203.0.113.1", expected: "203.0.113.1"},
+		{name: "skips trusted hops", remote: "10.0.0.1:1234", header: "203.0.113.1, 10.0.0.2", expected: "203.0.113.1"},
+		{name: "ignores spoofed left-most hops", remote: "10.0.0.1:1234", header: "192.0.2.99, 203.0.113.1", expected: "203.0.113.1"},
+		{name: "falls back to remote without header", remote: "10.0.0.1:1234", expected: "10.0.0.1"},
+		{name: "falls back to remote on invalid header", remote: "10.0.0.1:1234", header: "garbage", expected: "10.0.0.1"},
+		{name: "remote address without port", remote: "198.51.100.1", expected: "198.51.100.1"},
+	} {
+		t.Run("case="+tc.name, func(t *testing.T) {
+			r := &http.Request{RemoteAddr: tc.remote, Header: http.Header{}}
+			r.Header.Set("True-Client-IP", "192.0.2.77")
+			if tc.header != "" {
+				r.Header.Set("X-Forwarded-For", tc.header)
+			}
+			assert.Equal(t, tc.expected, ratelimit.ClientIP(r, c))
+		})
+	}
+
+	t.Run("case=uses configured header", func(t *testing.T) {
+		r := &http.Request{RemoteAddr: "10.0.0.1:1234", Header: http.Header{
+			"Cf-Connecting-Ip": {"203.0.113.1"},
+			"X-Forwarded-For":  {"192.0.2.77"},
+		}}
+		assert.Equal(t, "203.0.113.1", ratelimit.ClientIP(r, &config.RateLimit{
+			TrustedProxies: []string{"10.0.0.0/8"},
+			ClientIPHeader: "Cf-Connecting-IP",
+		}))
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/x/sqlcon"
+)
+
+var _ Persister = new(MemoryPersister)
+
+// MemoryPersister keeps the buckets in memory. The counters are not shared
+// between instances, which is why it should only be used if a single instance
+// of Ory Kratos is running.
+type MemoryPersister struct {
+	sync.Mutex
+	buckets map[string]*Bucket
+}
+
+func NewMemoryPersister() *MemoryPersister {
+	return &MemoryPersister{buckets: make(map[string]*Bucket)}
+}
+
+func (p *MemoryPersister) IncrementRateLimitBucket(_ context.Context, key string, window time.Duration) (*Bucket, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	now := time.Now().UTC()
+	b, ok := p.buckets[key]
+	if !ok || !b.ExpiresAt.After(now) {
+		b = &Bucket{Key: key, ExpiresAt: now.Add(window), CreatedAt: now}
+		p.buckets[key] = b
+	}
+
+	b.Hits++
+	b.UpdatedAt = now
+
+	bb := *b
+	return &bb, nil
+}
+
+func (p *MemoryPersister) GetRateLimitBucket(_ context.Context, key string) (*Bucket, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	b, ok := p.buckets[key]
+	if !ok || !b.ExpiresAt.After(time.Now()) {
+		return nil, errors.WithStack(sqlcon.ErrNoRows)
+	}
+
+	bb := *b
+	return &bb, nil
+}
+
+func (p *MemoryPersister) DeleteRateLimitBucket(_ context.Context, key string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	delete(p.buckets, key)
+	return nil
+}
+
+func (p *MemoryPersister) DeleteExpiredRateLimitBuckets(_ context.Context, at time.Time, limit int) error {
+	p.Lock()
+	defer p.Unlock()
+
+	for k, b := range p.buckets {
+		if limit <= 0 {
+			break
+		}
+		if b.ExpiresAt.Before(at) {
+			delete(p.buckets, k)
+			limit--
+		}
+	}
+	return nil
+}
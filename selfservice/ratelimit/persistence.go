@@ -0,0 +1,52 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Bucket counts the failed attempts of a key within a fixed window.
+type Bucket struct {
+	ID  uuid.UUID `db:"id"`
+	NID uuid.UUID `db:"nid"`
+
+	// Key is the HMAC of the rate limit key, so that identifiers and IP
+	// addresses are not stored in plain text.
+	Key string `db:"bucket_key"`
+
+	Hits      int       `db:"hits"`
+	ExpiresAt time.Time `db:"expires_at"`
+
+	// CreatedAt is a helper struct field for gobuffalo.pop.
+	CreatedAt time.Time `db:"created_at"`
+
+	// UpdatedAt is a helper struct field for gobuffalo.pop.
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (Bucket) TableName() string { return "selfservice_rate_limit_buckets" }
+
+type (
+	Persister interface {
+		// IncrementRateLimitBucket adds a hit to the bucket of the key. If the
+		// bucket does not exist or has expired, a new bucket is started which
+		// expires after the window.
+		IncrementRateLimitBucket(ctx context.Context, key string, window time.Duration) (*Bucket, error)
+
+		// GetRateLimitBucket returns the bucket of the key or sqlcon.ErrNoRows
+		// if there is no bucket which has not yet expired.
+		GetRateLimitBucket(ctx context.Context, key string) (*Bucket, error)
+		DeleteRateLimitBucket(ctx context.Context, key string) error
+
+		DeleteExpiredRateLimitBuckets(context.Context, time.Time, int) error
+	}
+
+	PersistenceProvider interface {
+		RateLimitPersister() Persister
+	}
+)
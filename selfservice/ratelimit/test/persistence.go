@@ -0,0 +1,94 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/persistence"
+	"github.com/ory/kratos/selfservice/ratelimit"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/sqlcon"
+)
+
+func TestPersister(ctx context.Context, p interface {
+	persistence.Persister
+}) func(t *testing.T) {
+	return func(t *testing.T) {
+		nid, p := testhelpers.NewNetworkUnlessExisting(t, ctx, p)
+
+		t.Run("case=returns no rows for unknown keys", func(t *testing.T) {
+			_, err := p.GetRateLimitBucket(ctx, x.NewUUID().String())
+			require.ErrorIs(t, err, sqlcon.ErrNoRows)
+		})
+
+		t.Run("case=increments the bucket", func(t *testing.T) {
+			key := x.NewUUID().String()
+
+			for i := 1; i <= 3; i++ {
+				b, err := p.IncrementRateLimitBucket(ctx, key, time.Hour)
+				require.NoError(t, err)
+				assert.Equal(t, i, b.Hits)
+				assert.Equal(t, nid, b.NID)
+				assert.NotEqual(t, key, b.Key, "the key must not be stored in plain text")
+			}
+
+			b, err := p.GetRateLimitBucket(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, 3, b.Hits)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), b.ExpiresAt, time.Minute)
+
+			t.Run("case=other networks are not affected", func(t *testing.T) {
+				_, other := testhelpers.NewNetwork(t, ctx, p)
+				_, err := other.GetRateLimitBucket(ctx, key)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+
+			t.Run("case=deletes the bucket", func(t *testing.T) {
+				require.NoError(t, p.DeleteRateLimitBucket(ctx, key))
+				_, err := p.GetRateLimitBucket(ctx, key)
+				require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			})
+		})
+
+		t.Run("case=starts a new bucket after expiry", func(t *testing.T) {
+			key := x.NewUUID().String()
+
+			_, err := p.IncrementRateLimitBucket(ctx, key, -time.Minute)
+			require.NoError(t, err)
+
+			_, err = p.GetRateLimitBucket(ctx, key)
+			require.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+			b, err := p.IncrementRateLimitBucket(ctx, key, time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, 1, b.Hits)
+		})
+
+		t.Run("case=deletes expired buckets", func(t *testing.T) {
+			expired, active := x.NewUUID().String(), x.NewUUID().String()
+
+			_, err := p.IncrementRateLimitBucket(ctx, expired, -time.Minute)
+			require.NoError(t, err)
+			_, err = p.IncrementRateLimitBucket(ctx, active, time.Hour)
+			require.NoError(t, err)
+
+			require.NoError(t, p.DeleteExpiredRateLimitBuckets(ctx, time.Now(), 100))
+
+			count, err := p.GetConnection(ctx).Where("nid = ? AND expires_at <= ?", nid, time.Now().UTC()).Count(new(ratelimit.Bucket))
+			require.NoError(t, err)
+			assert.Zero(t, count)
+
+			b, err := p.GetRateLimitBucket(ctx, active)
+			require.NoError(t, err)
+			assert.Equal(t, 1, b.Hits)
+		})
+	}
+}
@@ -5,6 +5,7 @@ package code
 
 import (
 	"context"
+	"encoding/json"
 	"net/url"
 
 	"github.com/gofrs/uuid"
@@ -13,12 +14,14 @@ import (
 	"github.com/ory/x/urlx"
 
 	"github.com/ory/herodot"
+	"github.com/ory/kratos/courier/template"
 	"github.com/ory/kratos/courier/template/email"
 	"github.com/ory/kratos/courier/template/sms"
 
 	"github.com/ory/kratos/courier"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/recovery"
 	"github.com/ory/kratos/selfservice/flow/verification"
@@ -31,6 +34,7 @@ type (
 	senderDependencies interface {
 		courier.Provider
 		courier.ConfigProvider
+		courier.PersistenceProvider
 
 		identity.PoolProvider
 		identity.ManagementProvider
@@ -100,6 +104,8 @@ func (s *Sender) SendCode(ctx context.Context, f flow.Flow, id *identity.Identit
 			if err != nil {
 				return err
 			}
+			locale := template.Locale(ctx, s.deps, json.RawMessage(id.Traits))
+			branding := template.ResolveBranding(ctx, s.deps, id.SchemaID, id.OrganizationID, locale)
 
 			s.deps.Audit().
 				WithField("registration_flow_id", code.FlowID).
@@ -117,6 +123,8 @@ func (s *Sender) SendCode(ctx context.Context, f flow.Flow, id *identity.Identit
 					RequestURL:       f.GetRequestURL(),
 					TransientPayload: transientPayload,
 					ExpiresInMinutes: int(s.deps.Config().SelfServiceCodeMethodLifespan(ctx).Minutes()),
+					Locale:           locale,
+					Branding:         branding,
 				})
 			case identity.ChannelTypeSMS:
 				t = sms.NewRegistrationCodeValid(s.deps, &sms.RegistrationCodeValidModel{
@@ -126,6 +134,8 @@ func (s *Sender) SendCode(ctx context.Context, f flow.Flow, id *identity.Identit
 					RequestURL:       f.GetRequestURL(),
 					TransientPayload: transientPayload,
 					ExpiresInMinutes: int(s.deps.Config().SelfServiceCodeMethodLifespan(ctx).Minutes()),
+					Locale:           locale,
+					Branding:         branding,
 				})
 			}
 
@@ -152,6 +162,8 @@ func (s *Sender) SendCode(ctx context.Context, f flow.Flow, id *identity.Identit
 			if err != nil {
 				return err
 			}
+			locale := template.Locale(ctx, s.deps, json.RawMessage(id.Traits))
+			branding := template.ResolveBranding(ctx, s.deps, id.SchemaID, id.OrganizationID, locale)
 			s.deps.Audit().
 				WithField("login_flow_id", code.FlowID).
 				WithField("login_code_id", code.ID).
@@ -168,6 +180,8 @@ func (s *Sender) SendCode(ctx context.Context, f flow.Flow, id *identity.Identit
 					RequestURL:       f.GetRequestURL(),
 					TransientPayload: transientPayload,
 					ExpiresInMinutes: int(s.deps.Config().SelfServiceCodeMethodLifespan(ctx).Minutes()),
+					Locale:           locale,
+					Branding:         branding,
 				})
 			case identity.ChannelTypeSMS:
 				t = sms.NewLoginCodeValid(s.deps, &sms.LoginCodeValidModel{
@@ -177,6 +191,8 @@ func (s *Sender) SendCode(ctx context.Context, f flow.Flow, id *identity.Identit
 					RequestURL:       f.GetRequestURL(),
 					TransientPayload: transientPayload,
 					ExpiresInMinutes: int(s.deps.Config().SelfServiceCodeMethodLifespan(ctx).Minutes()),
+					Locale:           locale,
+					Branding:         branding,
 				})
 			}
 
@@ -222,12 +238,15 @@ func (s *Sender) SendRecoveryCode(ctx context.Context, f *recovery.Flow, via ide
 		// That's because we pay per SMS sent (typically) so we want to avoid that, contrary to email.
 		shouldNotifyOfUnkownRecipient := notifyUnknownRecipients && via == identity.RecoveryAddressTypeEmail
 
+		locale := template.Locale(ctx, s.deps, nil)
 		if !shouldNotifyOfUnkownRecipient {
 			// do nothing
 		} else if err := s.send(ctx, string(via), email.NewRecoveryCodeInvalid(s.deps, &email.RecoveryCodeInvalidModel{
 			To:               to,
 			RequestURL:       f.RequestURL,
 			TransientPayload: transientPayload,
+			Locale:           locale,
+			Branding:         template.ResolveBranding(ctx, s.deps, "", uuid.NullUUID{}, locale),
 		})); err != nil {
 			return err
 		}
@@ -237,6 +256,23 @@ func (s *Sender) SendRecoveryCode(ctx context.Context, f *recovery.Flow, via ide
 		return err
 	}
 
+	if suppressed, err := s.deps.CourierPersister().IsSuppressed(ctx, address.Value); err != nil {
+		return err
+	} else if suppressed {
+		s.deps.Audit().
+			WithField("via", via).
+			WithField("identity_id", address.IdentityID).
+			WithSensitiveField("address", address.Value).
+			WithField("strategy", "code").
+			Info("Account recovery was requested for a suppressed address.")
+		if s.deps.Config().SecurityAccountEnumerationMitigate(ctx) {
+			// Responding as for an unknown address does not reveal that the
+			// address belongs to an identity.
+			return errors.WithStack(ErrUnknownAddress)
+		}
+		return schema.NewRecoveryAddressSuppressedError()
+	}
+
 	// Get the identity associated with the recovery address
 	i, err := s.deps.IdentityPool().GetIdentity(ctx, address.IdentityID, identity.ExpandDefault)
 	if err != nil {
@@ -275,6 +311,8 @@ func (s *Sender) SendRecoveryCodeTo(ctx context.Context, i *identity.Identity, c
 	if err != nil {
 		return err
 	}
+	locale := template.Locale(ctx, s.deps, json.RawMessage(i.Traits))
+	branding := template.ResolveBranding(ctx, s.deps, i.SchemaID, i.OrganizationID, locale)
 
 	transientPayload, err := x.ParseRawMessageOrEmpty(f.GetTransientPayload())
 	if err != nil {
@@ -292,6 +330,8 @@ func (s *Sender) SendRecoveryCodeTo(ctx context.Context, i *identity.Identity, c
 			RequestURL:       f.GetRequestURL(),
 			TransientPayload: transientPayload,
 			ExpiresInMinutes: int(s.deps.Config().SelfServiceCodeMethodLifespan(ctx).Minutes()),
+			Locale:           locale,
+			Branding:         branding,
 		})
 	case identity.RecoveryAddressTypeSMS:
 		u, err := url.Parse(f.GetRequestURL())
@@ -307,6 +347,8 @@ func (s *Sender) SendRecoveryCodeTo(ctx context.Context, i *identity.Identity, c
 			RequestURLDomain: u.Hostname(),
 			TransientPayload: transientPayload,
 			ExpiresInMinutes: int(s.deps.Config().SelfServiceCodeMethodLifespan(ctx).Minutes()),
+			Locale:           locale,
+			Branding:         branding,
 		})
 	default:
 		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Expected email or sms but got %s", code.RecoveryAddress.Via))
@@ -340,12 +382,15 @@ func (s *Sender) SendVerificationCode(ctx context.Context, f *verification.Flow,
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		locale := template.Locale(ctx, s.deps, nil)
 		if !notifyUnknownRecipients {
 			// do nothing
 		} else if err := s.send(ctx, via, email.NewVerificationCodeInvalid(s.deps, &email.VerificationCodeInvalidModel{
 			To:               to,
 			RequestURL:       f.GetRequestURL(),
 			TransientPayload: transientPayload,
+			Locale:           locale,
+			Branding:         template.ResolveBranding(ctx, s.deps, "", uuid.NullUUID{}, locale),
 		})); err != nil {
 			return err
 		}
@@ -355,6 +400,23 @@ func (s *Sender) SendVerificationCode(ctx context.Context, f *verification.Flow,
 		return err
 	}
 
+	if suppressed, err := s.deps.CourierPersister().IsSuppressed(ctx, address.Value); err != nil {
+		return err
+	} else if suppressed {
+		s.deps.Audit().
+			WithField("via", via).
+			WithField("identity_id", address.IdentityID).
+			WithSensitiveField("email_address", address.Value).
+			WithField("strategy", "code").
+			Info("Address verification was requested for a suppressed address.")
+		if s.deps.Config().SecurityAccountEnumerationMitigate(ctx) {
+			// Responding as for an unknown address does not reveal that the
+			// address belongs to an identity.
+			return errors.WithStack(ErrUnknownAddress)
+		}
+		return schema.NewVerificationAddressSuppressedError()
+	}
+
 	rawCode := GenerateCode()
 	var code *VerificationCode
 	if code, err = s.deps.VerificationCodePersister().CreateVerificationCode(ctx, &CreateVerificationCodeParams{
@@ -397,6 +459,8 @@ func (s *Sender) SendVerificationCodeTo(ctx context.Context, f *verification.Flo
 	if err != nil {
 		return err
 	}
+	locale := template.Locale(ctx, s.deps, json.RawMessage(i.Traits))
+	branding := template.ResolveBranding(ctx, s.deps, i.SchemaID, i.OrganizationID, locale)
 
 	transientPayload, err := x.ParseRawMessageOrEmpty(f.GetTransientPayload())
 	if err != nil {
@@ -416,6 +480,8 @@ func (s *Sender) SendVerificationCodeTo(ctx context.Context, f *verification.Flo
 			RequestURL:       f.GetRequestURL(),
 			TransientPayload: transientPayload,
 			ExpiresInMinutes: int(s.deps.Config().SelfServiceCodeMethodLifespan(ctx).Minutes()),
+			Locale:           locale,
+			Branding:         branding,
 		})
 	case identity.ChannelTypeSMS:
 		t = sms.NewVerificationCodeValid(s.deps, &sms.VerificationCodeValidModel{
@@ -425,6 +491,8 @@ func (s *Sender) SendVerificationCodeTo(ctx context.Context, f *verification.Flo
 			RequestURL:       f.GetRequestURL(),
 			TransientPayload: transientPayload,
 			ExpiresInMinutes: int(s.deps.Config().SelfServiceCodeMethodLifespan(ctx).Minutes()),
+			Locale:           locale,
+			Branding:         branding,
 		})
 	default:
 		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Expected email or sms but got %s", code.VerifiableAddress.Via))
@@ -13,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 
 	"github.com/ory/kratos/courier"
 	"github.com/ory/kratos/driver/config"
@@ -23,6 +24,7 @@ import (
 	"github.com/ory/kratos/selfservice/flow/recovery"
 	"github.com/ory/kratos/selfservice/flow/verification"
 	"github.com/ory/kratos/selfservice/strategy/code"
+	"github.com/ory/kratos/x"
 	"github.com/ory/x/urlx"
 )
 
@@ -105,6 +107,61 @@ func TestSender(t *testing.T) {
 			assert.Equal(t, messages[1].Subject, subject+" invalid")
 			assert.Equal(t, messages[1].Body, body)
 		})
+		t.Run("case=with localized templates", func(t *testing.T) {
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyCourierTemplatesRecoveryCodeInvalidEmail, nil)
+				conf.MustSet(ctx, config.ViperKeyCourierTemplatesRecoveryCodeValidEmail, nil)
+			})
+			conf.MustSet(ctx, config.ViperKeyCourierTemplatesRecoveryCodeInvalidEmail, fmt.Sprintf(`{ "locales": { "de": { "subject": "base64://%s" }}}`, b64("Kontozugriff versucht")))
+			conf.MustSet(ctx, config.ViperKeyCourierTemplatesRecoveryCodeValidEmail, fmt.Sprintf(`{ "locales": { "de": { "subject": "base64://%s" }}}`, b64("Verwenden Sie den Code {{ .RecoveryCode }}")))
+
+			f, err := recovery.NewFlow(conf, time.Hour, "", u, code.NewStrategy(reg), flow.TypeBrowser)
+			require.NoError(t, err)
+			require.NoError(t, reg.RecoveryFlowPersister().CreateRecoveryFlow(ctx, f))
+
+			ctx := x.WithAcceptLanguage(ctx, "de-CH, de;q=0.9, en;q=0.8")
+			require.NoError(t, reg.CodeSender().SendRecoveryCode(ctx, f, "email", "tracked@ory.sh"))
+			require.ErrorIs(t, reg.CodeSender().SendRecoveryCode(ctx, f, "email", "not-tracked@ory.sh"), code.ErrUnknownAddress)
+
+			messages, err := reg.CourierPersister().NextMessages(ctx, 12)
+			require.NoError(t, err)
+			require.Len(t, messages, 2)
+
+			assert.Contains(t, messages[0].Subject, "Verwenden Sie den Code")
+			assert.Regexp(t, testhelpers.CodeRegex, messages[0].Body, "the body falls back to the default template")
+			assert.Equal(t, "de-CH", gjson.GetBytes(messages[0].TemplateData, "locale").String())
+
+			assert.Equal(t, "Kontozugriff versucht", messages[1].Subject)
+		})
+		t.Run("case=with branding", func(t *testing.T) {
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeyCourierBranding, nil)
+				conf.MustSet(ctx, config.ViperKeyCourierTemplatesRecoveryCodeInvalidEmail, nil)
+				conf.MustSet(ctx, config.ViperKeyCourierTemplatesRecoveryCodeValidEmail, nil)
+			})
+			conf.MustSet(ctx, config.ViperKeyCourierBranding, map[string]any{
+				"product_name": "Ory",
+				"locales":      map[string]any{"de": map[string]any{"product_name": "Ory DE"}},
+			})
+			conf.MustSet(ctx, config.ViperKeyCourierTemplatesRecoveryCodeInvalidEmail, fmt.Sprintf(`{ "subject": "base64://%s" }`, b64("{{ .Branding.ProductName }} account access attempted")))
+			conf.MustSet(ctx, config.ViperKeyCourierTemplatesRecoveryCodeValidEmail, fmt.Sprintf(`{ "subject": "base64://%s" }`, b64("Recover your {{ .Branding.ProductName }} account")))
+
+			f, err := recovery.NewFlow(conf, time.Hour, "", u, code.NewStrategy(reg), flow.TypeBrowser)
+			require.NoError(t, err)
+			require.NoError(t, reg.RecoveryFlowPersister().CreateRecoveryFlow(ctx, f))
+
+			ctx := x.WithAcceptLanguage(ctx, "de")
+			require.NoError(t, reg.CodeSender().SendRecoveryCode(ctx, f, "email", "tracked@ory.sh"))
+			require.ErrorIs(t, reg.CodeSender().SendRecoveryCode(ctx, f, "email", "not-tracked@ory.sh"), code.ErrUnknownAddress)
+
+			messages, err := reg.CourierPersister().NextMessages(ctx, 12)
+			require.NoError(t, err)
+			require.Len(t, messages, 2)
+
+			assert.Equal(t, "Recover your Ory DE account", messages[0].Subject)
+			assert.Equal(t, "Ory DE", gjson.GetBytes(messages[0].TemplateData, "branding.product_name").String())
+			assert.Equal(t, "Ory DE account access attempted", messages[1].Subject)
+		})
 	})
 
 	t.Run("method=SendRecoveryCode sms", func(t *testing.T) {
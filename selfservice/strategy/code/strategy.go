@@ -30,6 +30,7 @@ import (
 	"github.com/ory/kratos/selfservice/flow/registration"
 	"github.com/ory/kratos/selfservice/flow/settings"
 	"github.com/ory/kratos/selfservice/flow/verification"
+	"github.com/ory/kratos/selfservice/ratelimit"
 	"github.com/ory/kratos/selfservice/sessiontokenexchange"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/text"
@@ -86,6 +87,7 @@ type (
 		identity.PrivilegedPoolProvider
 
 		courier.Provider
+		courier.PersistenceProvider
 
 		errorx.ManagementProvider
 
@@ -117,6 +119,8 @@ type (
 		sessiontokenexchange.PersistenceProvider
 
 		continuity.ManagementProvider
+
+		ratelimit.LimiterProvider
 	}
 
 	Strategy struct {
@@ -21,6 +21,7 @@ import (
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/ratelimit"
 	"github.com/ory/kratos/selfservice/strategy/idfirst"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/text"
@@ -233,7 +234,7 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 		}
 		return nil, nil
 	case flow.StateEmailSent:
-		i, err := s.loginVerifyCode(ctx, f, &p, sess)
+		i, err := s.loginVerifyCode(ctx, r, f, &p, sess)
 		if err != nil {
 			return nil, s.HandleLoginError(r, f, &p, err, true)
 		}
@@ -439,7 +440,7 @@ func maybeNormalizeEmail(input string) string {
 	return input
 }
 
-func (s *Strategy) loginVerifyCode(ctx context.Context, f *login.Flow, p *updateLoginFlowWithCodeMethod, sess *session.Session) (_ *identity.Identity, err error) {
+func (s *Strategy) loginVerifyCode(ctx context.Context, r *http.Request, f *login.Flow, p *updateLoginFlowWithCodeMethod, sess *session.Session) (_ *identity.Identity, err error) {
 	ctx, span := s.deps.Tracer(ctx).Tracer().Start(ctx, "selfservice.strategy.code.Strategy.loginVerifyCode")
 	defer otelx.End(span, &err)
 
@@ -466,14 +467,28 @@ func (s *Strategy) loginVerifyCode(ctx context.Context, f *login.Flow, p *update
 		return nil, err
 	}
 
+	// The number of submissions per flow is limited as well, but a new flow
+	// can be started at any time.
+	limited := []ratelimit.Key{ratelimit.IdentifierKey(p.Identifier), ratelimit.IdentityKey(i.ID)}
+	if err := s.deps.RateLimiter().Check(ctx, limited...); err != nil {
+		return nil, err
+	}
+
 	loginCode, err := s.deps.LoginCodePersister().UseLoginCode(ctx, f.ID, i.ID, p.Code)
 	if err != nil {
 		if errors.Is(err, ErrCodeNotFound) {
+			if err := s.deps.RateLimiter().Hit(ctx, append(limited, s.deps.RateLimiter().IPKey(ctx, r))...); err != nil {
+				return nil, err
+			}
 			return nil, schema.NewLoginCodeInvalid()
 		}
 		return nil, errors.WithStack(err)
 	}
 
+	if err := s.deps.RateLimiter().Reset(ctx, limited...); err != nil {
+		return nil, err
+	}
+
 	i, err = s.deps.PrivilegedIdentityPool().GetIdentity(ctx, loginCode.IdentityID, identity.ExpandDefault)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -4,6 +4,7 @@
 package code
 
 import (
+	"context"
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
@@ -475,6 +476,19 @@ func (s *Strategy) recoveryV2HandleStateAwaitingAddress(r *http.Request, f *reco
 		return err
 	}
 
+	// Suppressed addresses can not receive a recovery code, so they are not
+	// offered. If none is left, the identity can not be recovered this way. If
+	// account enumeration is mitigated, this is handled like an unknown address.
+	if len(recoveryAddresses) > 0 {
+		recoveryAddresses, err = s.withoutSuppressedAddresses(r.Context(), recoveryAddresses)
+		if err != nil {
+			return err
+		}
+		if len(recoveryAddresses) == 0 && !s.deps.Config().SecurityAccountEnumerationMitigate(r.Context()) {
+			return schema.NewRecoveryAddressSuppressedError()
+		}
+	}
+
 	// No rows returned.
 	if len(recoveryAddresses) == 0 {
 		// To avoid an attacker from using this case to probe for existing addresses, we pretend it exists.
@@ -537,6 +551,20 @@ func (s *Strategy) recoveryV2HandleStateAwaitingAddress(r *http.Request, f *reco
 	return nil
 }
 
+func (s *Strategy) withoutSuppressedAddresses(ctx context.Context, addresses []identity.RecoveryAddress) ([]identity.RecoveryAddress, error) {
+	available := make([]identity.RecoveryAddress, 0, len(addresses))
+	for _, a := range addresses {
+		suppressed, err := s.deps.CourierPersister().IsSuppressed(ctx, a.Value)
+		if err != nil {
+			return nil, err
+		}
+		if !suppressed {
+			available = append(available, a)
+		}
+	}
+	return available, nil
+}
+
 func (s *Strategy) recoveryV2HandleStateAwaitingAddressChoice(r *http.Request, f *recovery.Flow, body *recoverySubmitPayload) error {
 	if f.State != flow.StateRecoveryAwaitingAddressChoice {
 		return errors.Errorf("unreachable state: %s", f.State)
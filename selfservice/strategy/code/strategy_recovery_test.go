@@ -24,6 +24,7 @@ import (
 	"github.com/tidwall/gjson"
 
 	"github.com/ory/kratos/corpx"
+	"github.com/ory/kratos/courier"
 	"github.com/ory/kratos/driver"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
@@ -603,6 +604,42 @@ func TestRecovery(t *testing.T) {
 		})
 	})
 
+	t.Run("description=should not send a code to a suppressed address", func(t *testing.T) {
+		for _, flowType := range flowTypeCases {
+			t.Run("type="+string(flowType.ClientType), func(t *testing.T) {
+				email := "recoversuppressed_" + string(flowType.ClientType) + "@ory.sh"
+				createIdentityToRecover(t, reg, email)
+				require.NoError(t, reg.CourierPersister().AddSuppression(ctx, &courier.Suppression{ID: x.NewUUID(), Recipient: email, Reason: courier.SuppressionReasonBounced}))
+
+				body := expectValidationError(t, testhelpers.NewClientWithCookies(t), flowType.ClientType, func(v url.Values) {
+					v.Set("email", email)
+				})
+				assertx.EqualAsJSON(t, text.NewErrorValidationRecoveryAddressSuppressed(), json.RawMessage(gjson.Get(body, "ui.messages.0").Raw), "%s", body)
+				ExpectVerfiableAddressStatus(t, email, identity.VerifiableAddressStatusSuppressed)
+			})
+		}
+
+		t.Run("case=responds as for unknown addresses if account enumeration is mitigated", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySecurityAccountEnumerationMitigate, true)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeySecurityAccountEnumerationMitigate, false)
+			})
+
+			email := "recoversuppressed_mitigated@ory.sh"
+			createIdentityToRecover(t, reg, email)
+			require.NoError(t, reg.CourierPersister().AddSuppression(ctx, &courier.Suppression{ID: x.NewUUID(), Recipient: email, Reason: courier.SuppressionReasonBounced}))
+
+			body := submitRecovery(t, testhelpers.NewClientWithCookies(t), RecoveryClientTypeBrowser, func(v url.Values) {
+				v.Set("email", email)
+			}, http.StatusOK)
+			assertx.EqualAsJSON(t, text.NewRecoveryEmailWithCodeSent(), json.RawMessage(gjson.Get(body, "ui.messages.0").Raw), "%s", body)
+
+			messages, _, err := reg.CourierPersister().ListMessages(ctx, courier.ListCourierMessagesParameters{Recipient: email}, nil)
+			require.NoError(t, err)
+			assert.Empty(t, messages)
+		})
+	})
+
 	t.Run("description=should not be able to recover an inactive account", func(t *testing.T) {
 		for _, flowType := range flowTypeCases {
 			t.Run("type="+string(flowType.ClientType), func(t *testing.T) {
@@ -2295,6 +2332,22 @@ func TestRecovery_V2_WithContinueWith_OneAddress_Email(t *testing.T) {
 		}
 	})
 
+	t.Run("description=should not send a code if the only address is suppressed", func(t *testing.T) {
+		for _, flowType := range flowTypes {
+			t.Run("type="+flowType.String(), func(t *testing.T) {
+				email := "recoversuppressed_" + flowType.String() + "@ory.sh"
+				createIdentityToRecover(t, reg, email)
+				require.NoError(t, reg.CourierPersister().AddSuppression(ctx, &courier.Suppression{ID: x.NewUUID(), Recipient: email, Reason: courier.SuppressionReasonComplained}))
+
+				code := testhelpers.ExpectStatusCode(flowType == RecoveryClientTypeAPI || flowType == RecoveryClientTypeSPA, http.StatusBadRequest, http.StatusOK)
+				body := submitRecoveryFormInitial(t, nil, flowType, func(v url.Values) {
+					v.Set("recovery_address", email)
+				}, code)
+				assertx.EqualAsJSON(t, text.NewErrorValidationRecoveryAddressSuppressed(), json.RawMessage(gjson.Get(body, "ui.messages.0").Raw), "%s", body)
+			})
+		}
+	})
+
 	t.Run("description=should try to submit the form while authenticated", func(t *testing.T) {
 		for _, testCase := range flowTypeCases {
 			t.Run("type="+testCase.ClientType.String(), func(t *testing.T) {
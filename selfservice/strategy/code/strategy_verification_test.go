@@ -30,6 +30,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
 
+	"github.com/ory/kratos/courier"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/internal"
@@ -228,6 +229,61 @@ func TestVerification(t *testing.T) {
 		})
 	})
 
+	t.Run("description=should not send a code to a suppressed address", func(t *testing.T) {
+		email := x.NewUUID().String() + "@ory.sh"
+		id := &identity.Identity{
+			Traits:   identity.Traits(fmt.Sprintf(`{"email":%q}`, email)),
+			SchemaID: config.DefaultIdentityTraitsSchemaID,
+		}
+		require.NoError(t, reg.IdentityManager().Create(ctx, id, identity.ManagerAllowWriteProtectedTraits))
+
+		suppression := courier.Suppression{ID: x.NewUUID(), Recipient: email, Reason: courier.SuppressionReasonBounced}
+		require.NoError(t, reg.CourierPersister().AddSuppression(ctx, &suppression))
+
+		address, err := reg.IdentityPool().FindVerifiableAddressByValue(ctx, identity.VerifiableAddressTypeEmail, email)
+		require.NoError(t, err)
+		assert.Equal(t, identity.VerifiableAddressStatusSuppressed, address.Status)
+
+		check := func(t *testing.T, actual string) {
+			assertx.EqualAsJSON(t, text.NewErrorValidationVerificationAddressSuppressed(), json.RawMessage(gjson.Get(actual, "ui.messages.0").Raw), "%s", actual)
+		}
+
+		values := func(v url.Values) {
+			v.Set("email", email)
+		}
+
+		t.Run("type=browser", func(t *testing.T) {
+			check(t, expectValidationError(t, nil, false, false, values))
+		})
+
+		t.Run("type=spa", func(t *testing.T) {
+			check(t, expectValidationError(t, nil, false, true, values))
+		})
+
+		t.Run("type=api", func(t *testing.T) {
+			check(t, expectValidationError(t, nil, true, false, values))
+		})
+
+		t.Run("case=responds as for unknown addresses if account enumeration is mitigated", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySecurityAccountEnumerationMitigate, true)
+			t.Cleanup(func() {
+				conf.MustSet(ctx, config.ViperKeySecurityAccountEnumerationMitigate, false)
+			})
+
+			actual := expectSuccess(t, nil, false, false, values)
+			assertx.EqualAsJSON(t, text.NewVerificationEmailWithCodeSent(), json.RawMessage(gjson.Get(actual, "ui.messages.0").Raw), "%s", actual)
+
+			messages, _, err := reg.CourierPersister().ListMessages(ctx, courier.ListCourierMessagesParameters{Recipient: email}, nil)
+			require.NoError(t, err)
+			assert.Empty(t, messages)
+		})
+
+		require.NoError(t, reg.CourierPersister().DeleteSuppression(ctx, suppression.ID))
+		address, err = reg.IdentityPool().FindVerifiableAddressByValue(ctx, identity.VerifiableAddressTypeEmail, email)
+		require.NoError(t, err)
+		assert.Equal(t, identity.VerifiableAddressStatusPending, address.Status)
+	})
+
 	t.Run("description=clicking link should prefill code", func(t *testing.T) {
 		c := testhelpers.NewClientWithCookies(t)
 		f := testhelpers.SubmitVerificationForm(t, false, false, c, public, func(v url.Values) {
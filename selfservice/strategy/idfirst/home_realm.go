@@ -0,0 +1,66 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package idfirst
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+)
+
+// HomeRealmLoginer is implemented by login strategies which can sign in the
+// users of a home realm, such as the OpenID Connect strategy.
+type HomeRealmLoginer interface {
+	// LoginWithHomeRealm either redirects the browser to the identity provider
+	// of the realm and returns flow.ErrCompletedByStrategy, or populates the
+	// flow with the identity providers of the realm and returns nil. It returns
+	// flow.ErrStrategyNotResponsible if the strategy can not sign in users of
+	// the realm.
+	LoginWithHomeRealm(w http.ResponseWriter, r *http.Request, f *login.Flow, realm *config.HomeRealm, identifier string) error
+}
+
+// routesToHomeRealm reports whether the user is routed to the home realm. If
+// password logins of the realm are forbidden, all users are routed. Otherwise,
+// users who sign in with a password keep doing so, and only unknown users or
+// users without a password are routed. As that would disclose whether the
+// account exists, users are not routed in that case if account enumeration is
+// mitigated.
+func (s *Strategy) routesToHomeRealm(ctx context.Context, realm *config.HomeRealm, i *identity.Identity) bool {
+	if realm.ForbidPasswordLogin {
+		return true
+	} else if s.d.Config().SecurityAccountEnumerationMitigate(ctx) {
+		return false
+	} else if i == nil {
+		return true
+	}
+
+	_, ok := i.GetCredentials(identity.CredentialsTypePassword)
+	return !ok
+}
+
+// loginWithHomeRealm hands the login flow over to the strategy which signs in
+// the users of the realm.
+func (s *Strategy) loginWithHomeRealm(w http.ResponseWriter, r *http.Request, f *login.Flow, realm *config.HomeRealm, identifier string) error {
+	for _, ls := range s.d.LoginStrategies(r.Context()) {
+		loginer, ok := ls.(HomeRealmLoginer)
+		if !ok {
+			continue
+		}
+
+		if err := loginer.LoginWithHomeRealm(w, r, f, realm, identifier); errors.Is(err, flow.ErrStrategyNotResponsible) {
+			continue
+		} else {
+			return err
+		}
+	}
+
+	return errors.WithStack(herodot.ErrMisconfiguration.WithReason("Home realm discovery requires the OpenID Connect login method to be enabled."))
+}
@@ -69,6 +69,9 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 		return nil, s.handleLoginError(r, f, p, err)
 	}
 
+	f.UI.ResetMessages()
+	f.UI.Nodes.SetValueAttribute("identifier", p.Identifier)
+
 	var opts []login.FormHydratorModifier
 
 	// Look up the user by the identifier.
@@ -91,8 +94,14 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 		}
 	}
 
-	f.UI.ResetMessages()
-	f.UI.Nodes.SetValueAttribute("identifier", p.Identifier)
+	// Users of a home realm sign in with the identity provider of their
+	// organization, unless they can keep signing in with their own credentials.
+	if realm := s.d.Config().SelfServiceLoginHomeRealm(ctx, p.Identifier); realm != nil && s.routesToHomeRealm(ctx, realm, identityHint) {
+		if err := s.loginWithHomeRealm(w, r, f, realm, p.Identifier); err != nil {
+			return nil, s.handleLoginError(r, f, p, err)
+		}
+		return nil, s.showCredentials(w, r, f, p)
+	}
 
 	// Add identity hint
 	opts = append(opts, login.WithIdentityHint(identityHint))
@@ -123,6 +132,14 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 		return nil, s.handleLoginError(r, f, p, errors.WithStack(schema.NewAccountNotFoundError()))
 	}
 
+	return nil, s.showCredentials(w, r, f, p)
+}
+
+// showCredentials hides the identifier and persists the flow, so that the user
+// can continue with the credentials populated by the strategies.
+func (s *Strategy) showCredentials(w http.ResponseWriter, r *http.Request, f *login.Flow, p updateLoginFlowWithIdentifierFirstMethod) error {
+	ctx := r.Context()
+
 	// We found credentials - hide the identifier.
 	f.UI.GetNodes().RemoveMatching(node.NewInputField("method", s.ID(), s.NodeGroup(), node.InputAttributeTypeSubmit))
 
@@ -143,8 +160,8 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 	}
 
 	f.Active = s.ID()
-	if err = s.d.LoginFlowPersister().UpdateLoginFlow(ctx, f); err != nil {
-		return nil, s.handleLoginError(r, f, p, err)
+	if err := s.d.LoginFlowPersister().UpdateLoginFlow(ctx, f); err != nil {
+		return s.handleLoginError(r, f, p, err)
 	}
 
 	if x.IsJSONRequest(r) {
@@ -153,7 +170,7 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 		http.Redirect(w, r, f.AppendTo(s.d.Config().SelfServiceFlowLoginUI(ctx)).String(), http.StatusSeeOther)
 	}
 
-	return nil, flow.ErrCompletedByStrategy
+	return flow.ErrCompletedByStrategy
 }
 
 func (s *Strategy) PopulateLoginMethodFirstFactorRefresh(r *http.Request, sr *login.Flow, _ *session.Session) error {
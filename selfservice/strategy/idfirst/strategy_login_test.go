@@ -418,6 +418,150 @@ func TestCompleteLogin(t *testing.T) {
 		})
 	})
 
+	t.Run("case=home realm discovery", func(t *testing.T) {
+		var issuer *httptest.Server
+		issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/.well-known/openid-configuration":
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"issuer":                 issuer.URL,
+					"authorization_endpoint": issuer.URL + "/oauth2/auth",
+					"token_endpoint":         issuer.URL + "/oauth2/token",
+					"jwks_uri":               issuer.URL + "/.well-known/jwks.json",
+				})
+			case "/oauth2/auth":
+				_ = json.NewEncoder(w).Encode(r.URL.Query())
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(issuer.Close)
+
+		orgID := x.NewUUID().String()
+		newProvider := func(id, orgID string) oidc.Configuration {
+			return oidc.Configuration{ID: id, Provider: "generic", ClientID: "client", ClientSecret: "secret", IssuerURL: issuer.URL, Mapper: "file://", OrganizationID: orgID}
+		}
+
+		testhelpers.StrategyEnable(t, conf, identity.CredentialsTypePassword.String(), true)
+		testhelpers.StrategyEnable(t, conf, identity.CredentialsTypeOIDC.String(), true)
+		conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+string(identity.CredentialsTypeOIDC)+".config", &oidc.ConfigurationCollection{Providers: []oidc.Configuration{
+			newProvider("acme", ""),
+			newProvider("globex-1", orgID),
+			newProvider("globex-2", orgID),
+		}})
+		conf.MustSet(ctx, config.ViperKeySelfServiceLoginHomeRealms, []config.HomeRealm{
+			{Domains: []string{"acme.com"}, Provider: "acme", ForbidPasswordLogin: true},
+			{Domains: []string{"globex.com"}, Organization: orgID},
+			{Domains: []string{"initech.com"}, Provider: "acme"},
+		})
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.ViperKeySelfServiceLoginHomeRealms, nil)
+			conf.MustSet(ctx, "selfservice.methods.password", nil)
+			conf.MustSet(ctx, "selfservice.methods.oidc", nil)
+		})
+
+		identifier, pwd := "Alice@ACME.com", "password"
+		createIdentity(ctx, reg, t, identifier, pwd)
+
+		t.Run("case=redirects to the provider of the domain", func(t *testing.T) {
+			values := func(v url.Values) {
+				v.Set("identifier", identifier)
+				v.Set("method", "identifier_first")
+			}
+
+			t.Run("type=browser", func(t *testing.T) {
+				body := testhelpers.SubmitLoginForm(t, false, nil, publicTS, values,
+					false, false, http.StatusOK, issuer.URL+"/oauth2/auth")
+				assert.Equal(t, identifier, gjson.Get(body, "login_hint.0").String(), "%s", body)
+				assert.Equal(t, "client", gjson.Get(body, "client_id.0").String(), "%s", body)
+			})
+
+			t.Run("type=spa", func(t *testing.T) {
+				body := testhelpers.SubmitLoginForm(t, false, nil, publicTS, values,
+					true, false, http.StatusUnprocessableEntity, publicTS.URL+login.RouteSubmitFlow)
+				assert.Contains(t, gjson.Get(body, "redirect_browser_to").String(), issuer.URL+"/oauth2/auth", "%s", body)
+			})
+		})
+
+		t.Run("case=shows the providers of the organization", func(t *testing.T) {
+			body := testhelpers.SubmitLoginForm(t, false, nil, publicTS, func(v url.Values) {
+				v.Set("identifier", "bob@globex.com")
+				v.Set("method", "identifier_first")
+			}, true, false, http.StatusBadRequest, publicTS.URL+login.RouteSubmitFlow)
+
+			assert.Equal(t, []any{"globex-1", "globex-2"}, gjson.Get(body, "ui.nodes.#(attributes.name==provider)#.attributes.value").Value(), "%s", body)
+			assert.Equal(t, orgID, gjson.Get(body, "organization_id").String(), "%s", body)
+			assert.Equal(t, "hidden", gjson.Get(body, "ui.nodes.#(attributes.name==identifier).attributes.type").String(), "%s", body)
+			assert.NotContains(t, body, fmt.Sprintf("%d", text.InfoSelfServiceLoginPassword), "%s", body)
+		})
+
+		t.Run("case=keeps the password of users of a domain which allows password logins", func(t *testing.T) {
+			identifier := "carol@initech.com"
+			createIdentity(ctx, reg, t, identifier, pwd)
+
+			body := testhelpers.SubmitLoginForm(t, false, nil, publicTS, func(v url.Values) {
+				v.Set("identifier", identifier)
+				v.Set("method", "identifier_first")
+			}, true, false, http.StatusBadRequest, publicTS.URL+login.RouteSubmitFlow)
+			assert.Contains(t, body, "current-password", "%s", body)
+			assert.Empty(t, gjson.Get(body, "redirect_browser_to").String(), "%s", body)
+
+			body = testhelpers.SubmitLoginForm(t, true, nil, publicTS, func(v url.Values) {
+				v.Set("identifier", identifier)
+				v.Set("password", pwd)
+				v.Set("method", "password")
+			}, false, false, http.StatusOK, publicTS.URL+login.RouteSubmitFlow)
+			assert.NotEmpty(t, gjson.Get(body, "session_token").String(), "%s", body)
+		})
+
+		t.Run("case=redirects unknown users of a domain which allows password logins", func(t *testing.T) {
+			body := testhelpers.SubmitLoginForm(t, false, nil, publicTS, func(v url.Values) {
+				v.Set("identifier", "dave@initech.com")
+				v.Set("method", "identifier_first")
+			}, false, false, http.StatusOK, issuer.URL+"/oauth2/auth")
+			assert.Equal(t, "dave@initech.com", gjson.Get(body, "login_hint.0").String(), "%s", body)
+		})
+
+		t.Run("case=rejects password logins of the domain", func(t *testing.T) {
+			body := testhelpers.SubmitLoginForm(t, true, nil, publicTS, func(v url.Values) {
+				v.Set("identifier", identifier)
+				v.Set("password", pwd)
+				v.Set("method", "password")
+			}, false, false, http.StatusBadRequest, publicTS.URL+login.RouteSubmitFlow)
+
+			assert.Equal(t, text.NewErrorValidationLoginPasswordForbidden("acme.com").Text, gjson.Get(body, "ui.nodes.#(attributes.name==identifier).messages.0.text").String(), "%s", body)
+			assert.Empty(t, gjson.Get(body, "session_token").String(), "%s", body)
+		})
+
+		t.Run("case=rejects password logins of usernames of identities of the domain", func(t *testing.T) {
+			for name, emailAsAddress := range map[string]bool{"verifiable address": true, "identifier": false} {
+				t.Run("email="+name, func(t *testing.T) {
+					username, email := "user-"+x.NewUUID().String(), x.NewUUID().String()+"@acme.com"
+					i := createIdentity(ctx, reg, t, username, pwd)
+					if emailAsAddress {
+						i.VerifiableAddresses = append(i.VerifiableAddresses, identity.VerifiableAddress{
+							Value: email, Via: identity.AddressTypeEmail, Status: identity.VerifiableAddressStatusPending, IdentityID: i.ID,
+						})
+					} else {
+						creds := i.Credentials[identity.CredentialsTypePassword]
+						creds.Identifiers = append(creds.Identifiers, email)
+						i.SetCredentials(identity.CredentialsTypePassword, creds)
+					}
+					require.NoError(t, reg.PrivilegedIdentityPool().UpdateIdentity(ctx, i))
+
+					body := testhelpers.SubmitLoginForm(t, true, nil, publicTS, func(v url.Values) {
+						v.Set("identifier", username)
+						v.Set("password", pwd)
+						v.Set("method", "password")
+					}, false, false, http.StatusBadRequest, publicTS.URL+login.RouteSubmitFlow)
+
+					assert.Equal(t, text.NewErrorValidationLoginPasswordForbidden("acme.com").Text, gjson.Get(body, "ui.nodes.#(attributes.name==identifier).messages.0.text").String(), "%s", body)
+					assert.Empty(t, gjson.Get(body, "session_token").String(), "%s", body)
+				})
+			}
+		})
+	})
+
 	t.Run("should pass with real request", func(t *testing.T) {
 		identifier, pwd := x.NewUUID().String(), "password"
 		createIdentity(ctx, reg, t, identifier, pwd)
@@ -0,0 +1,192 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ldap
+
+import (
+	"crypto/tls"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+)
+
+const (
+	defaultUserFilter     = "(uid={identifier})"
+	identifierPlaceholder = "{identifier}"
+	timeout               = 10 * time.Second
+)
+
+var (
+	errEntryNotFound  = errors.New("no directory entry matches the identifier")
+	errEntryAmbiguous = errors.New("more than one directory entry matches the identifier")
+)
+
+// Configuration is the configuration of the LDAP strategy.
+type Configuration struct {
+	// URL is the URL of the directory server, for example
+	// `ldaps://ldap.example.org:636`.
+	URL string `json:"url"`
+
+	// StartTLS upgrades plain ldap connections to TLS before binding.
+	StartTLS bool `json:"start_tls"`
+
+	// BindDN and BindPassword are the credentials of the service account
+	// used to search for user entries. The search is performed
+	// anonymously if BindDN is empty.
+	BindDN       string `json:"bind_dn"`
+	BindPassword string `json:"bind_password"`
+
+	// BaseDN is the base of the user search.
+	BaseDN string `json:"base_dn"`
+
+	// UserFilter is the search filter of the user entry. The
+	// `{identifier}` placeholder is replaced with the escaped identifier.
+	UserFilter string `json:"user_filter"`
+
+	// SubjectAttribute is the attribute which uniquely identifies the user
+	// entry. Defaults to the distinguished name.
+	SubjectAttribute string `json:"subject_attribute"`
+
+	// Attributes are the attributes requested from the directory. All user
+	// attributes are requested if empty.
+	Attributes []string `json:"attributes"`
+
+	// MapperURL is the Jsonnet mapper which maps the entry to the identity.
+	MapperURL string `json:"mapper_url"`
+
+	// ImportPassword stores the password as password credentials after a
+	// successful bind.
+	ImportPassword bool `json:"import_password"`
+
+	// LinkExistingIdentities links the directory account to an existing
+	// identity with conflicting identifiers instead of failing.
+	LinkExistingIdentities bool `json:"link_existing_identities"`
+}
+
+func (c *Configuration) filter(identifier string) string {
+	filter := c.UserFilter
+	if filter == "" {
+		filter = defaultUserFilter
+	}
+	return strings.ReplaceAll(filter, identifierPlaceholder, ldap.EscapeFilter(identifier))
+}
+
+func (c *Configuration) subject(entry *ldap.Entry) (string, error) {
+	if c.SubjectAttribute == "" {
+		return entry.DN, nil
+	}
+
+	subject := entry.GetAttributeValue(c.SubjectAttribute)
+	if subject == "" {
+		return "", errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("The directory entry has no value for the subject attribute %q.", c.SubjectAttribute))
+	}
+	return subject, nil
+}
+
+type directory struct {
+	conn *ldap.Conn
+	c    *Configuration
+}
+
+// dial connects to the directory server and, if configured, upgrades the
+// connection using StartTLS.
+func (c *Configuration) dial() (*directory, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("The LDAP URL is invalid: %s", err))
+	}
+
+	tlsConfig := &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	conn, err := ldap.DialURL(c.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrUpstreamError.WithReason("Unable to connect to the LDAP directory.").WithDebug(err.Error()))
+	}
+	conn.SetTimeout(timeout)
+
+	if c.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, errors.WithStack(herodot.ErrUpstreamError.WithReason("Unable to establish a TLS connection to the LDAP directory.").WithDebug(err.Error()))
+		}
+	}
+
+	return &directory{conn: conn, c: c}, nil
+}
+
+func (d *directory) Close() error {
+	return d.conn.Close()
+}
+
+// find looks up the entry of the user with the given identifier.
+func (d *directory) find(identifier string) (*ldap.Entry, error) {
+	if d.c.BindDN != "" {
+		if err := d.conn.Bind(d.c.BindDN, d.c.BindPassword); err != nil {
+			return nil, errors.WithStack(herodot.ErrUpstreamError.WithReason("Unable to bind to the LDAP directory with the service account.").WithDebug(err.Error()))
+		}
+	}
+
+	attributes := d.c.Attributes
+	if len(attributes) > 0 && d.c.SubjectAttribute != "" {
+		attributes = append(attributes[:len(attributes):len(attributes)], d.c.SubjectAttribute)
+	}
+
+	result, err := d.conn.Search(ldap.NewSearchRequest(
+		d.c.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(timeout.Seconds()), false,
+		d.c.filter(identifier), attributes, nil,
+	))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, errors.WithStack(errEntryAmbiguous)
+	} else if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, errors.WithStack(errEntryNotFound)
+	} else if err != nil {
+		return nil, errors.WithStack(herodot.ErrUpstreamError.WithReason("Unable to search the LDAP directory.").WithDebug(err.Error()))
+	}
+
+	switch len(result.Entries) {
+	case 0:
+		return nil, errors.WithStack(errEntryNotFound)
+	case 1:
+		return result.Entries[0], nil
+	default:
+		return nil, errors.WithStack(errEntryAmbiguous)
+	}
+}
+
+// authenticate binds as the given entry. It returns false if the password is
+// wrong.
+func (d *directory) authenticate(entry *ldap.Entry, password string) (bool, error) {
+	if password == "" {
+		// An empty password results in an unauthenticated bind which
+		// succeeds on many servers.
+		return false, nil
+	}
+
+	if err := d.conn.Bind(entry.DN, password); ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.WithStack(herodot.ErrUpstreamError.WithReason("Unable to bind to the LDAP directory.").WithDebug(err.Error()))
+	}
+
+	return true, nil
+}
+
+// mapperInput is the directory entry as passed to the Jsonnet mapper.
+func mapperInput(entry *ldap.Entry) map[string]any {
+	attributes := make(map[string][]string, len(entry.Attributes))
+	for _, a := range entry.Attributes {
+		attributes[a.Name] = a.Values
+	}
+	return map[string]any{
+		"dn":         entry.DN,
+		"attributes": attributes,
+	}
+}
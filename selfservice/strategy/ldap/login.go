@@ -0,0 +1,433 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ldap
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/decoderx"
+	"github.com/ory/x/fetcher"
+	"github.com/ory/x/otelx"
+	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
+
+	"github.com/ory/kratos/hash"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/schema"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/ratelimit"
+	"github.com/ory/kratos/selfservice/strategy/idfirst"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+	"github.com/ory/kratos/x/events"
+)
+
+var _ login.FormHydrator = new(Strategy)
+
+func (s *Strategy) RegisterLoginRoutes(*x.RouterPublic) {}
+
+func (s *Strategy) handleLoginError(r *http.Request, f *login.Flow, payload updateLoginFlowWithLDAPMethod, err error) error {
+	if f != nil {
+		f.UI.Nodes.ResetNodes("password")
+		f.UI.Nodes.SetValueAttribute("identifier", payload.Identifier)
+		if f.Type == flow.TypeBrowser {
+			f.UI.SetCSRF(s.d.GenerateCSRFToken(r))
+		}
+	}
+
+	return err
+}
+
+func (s *Strategy) Login(_ http.ResponseWriter, r *http.Request, f *login.Flow, _ *session.Session) (i *identity.Identity, err error) {
+	ctx, span := s.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.strategy.ldap.Strategy.Login")
+	defer otelx.End(span, &err)
+
+	if err := login.CheckAAL(f, identity.AuthenticatorAssuranceLevel1); err != nil {
+		span.SetAttributes(attribute.String("not_responsible_reason", "requested AAL is not AAL1"))
+		return nil, err
+	}
+
+	if err := flow.MethodEnabledAndAllowedFromRequest(r, f.GetFlowName(), s.ID().String(), s.d); err != nil {
+		return nil, err
+	}
+
+	var p updateLoginFlowWithLDAPMethod
+	if err := s.hd.Decode(r, &p,
+		decoderx.HTTPDecoderSetValidatePayloads(true),
+		decoderx.MustHTTPRawJSONSchemaCompiler(loginSchema),
+		decoderx.HTTPDecoderJSONFollowsFormFormat()); err != nil {
+		return nil, s.handleLoginError(r, f, p, err)
+	}
+	f.TransientPayload = p.TransientPayload
+
+	if err := flow.EnsureCSRF(s.d, r, f.Type, s.d.Config().DisableAPIFlowEnforcement(ctx), s.d.GenerateCSRFToken, p.CSRFToken); err != nil {
+		return nil, s.handleLoginError(r, f, p, err)
+	}
+
+	conf, err := s.config(ctx)
+	if err != nil {
+		return nil, s.handleLoginError(r, f, p, err)
+	}
+
+	if err := s.d.RateLimiter().Check(ctx, ratelimit.IdentifierKey(p.Identifier)); err != nil {
+		return nil, s.handleLoginError(r, f, p, err)
+	}
+
+	dir, err := conf.dial()
+	if err != nil {
+		return nil, s.handleLoginError(r, f, p, err)
+	}
+	defer func() { _ = dir.Close() }()
+
+	entry, err := dir.find(p.Identifier)
+	if errors.Is(err, errEntryNotFound) || errors.Is(err, errEntryAmbiguous) {
+		if errors.Is(err, errEntryAmbiguous) {
+			s.d.Logger().WithField("identifier", p.Identifier).Warn("More than one LDAP entry matches the identifier, please check the user filter.")
+		}
+		if err := s.d.RateLimiter().Hit(ctx, ratelimit.IdentifierKey(p.Identifier), s.d.RateLimiter().IPKey(ctx, r)); err != nil {
+			return nil, s.handleLoginError(r, f, p, err)
+		}
+		return nil, s.handleLoginError(r, f, p, errors.WithStack(schema.NewInvalidCredentialsError()))
+	} else if err != nil {
+		return nil, s.handleLoginError(r, f, p, err)
+	}
+
+	subject, err := conf.subject(entry)
+	if err != nil {
+		return nil, s.handleLoginError(r, f, p, err)
+	}
+
+	// The identity is only known if the directory account signed in before.
+	i, _, err = s.d.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, s.ID(), subject)
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		i = nil
+	} else if err != nil {
+		return nil, s.handleLoginError(r, f, p, err)
+	} else {
+		if err := s.d.RateLimiter().Check(ctx, ratelimit.IdentityKey(i.ID)); err != nil {
+			return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+		}
+
+		if err := s.d.IdentityManager().CheckLockout(ctx, i.ID); err != nil {
+			return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+		}
+	}
+
+	if ok, err := dir.authenticate(entry, p.Password); err != nil {
+		return nil, s.handleLoginError(r, f, p, err)
+	} else if !ok {
+		keys := []ratelimit.Key{ratelimit.IdentifierKey(p.Identifier), s.d.RateLimiter().IPKey(ctx, r)}
+		if i != nil {
+			keys = append(keys, ratelimit.IdentityKey(i.ID))
+			if err := s.d.IdentityManager().RecordFailedAuthentication(ctx, i.ID); err != nil {
+				return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+			}
+		}
+		if err := s.d.RateLimiter().Hit(ctx, keys...); err != nil {
+			return nil, s.handleLoginError(r, f, p, err)
+		}
+		return nil, s.handleLoginError(r, f, p, errors.WithStack(schema.NewInvalidCredentialsError()))
+	}
+
+	credentials := identity.CredentialsLDAP{DN: entry.DN, Username: p.Identifier}
+	if i == nil {
+		i, err = s.createIdentity(ctx, f, conf, entry, subject, credentials, p.Password)
+	} else {
+		i, err = s.updateIdentity(ctx, i.ID, conf, credentials, p.Password)
+	}
+	if err != nil {
+		return nil, s.handleLoginError(r, f, p, err)
+	}
+
+	if err := s.d.RateLimiter().Reset(ctx, ratelimit.IdentifierKey(p.Identifier), ratelimit.IdentityKey(i.ID)); err != nil {
+		return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+	}
+
+	if err := s.d.IdentityManager().ResetFailedAuthentications(ctx, i.ID); err != nil {
+		return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+	}
+
+	f.Active = s.ID()
+	if err = s.d.LoginFlowPersister().UpdateLoginFlow(ctx, f); err != nil {
+		return nil, s.handleLoginError(r, f, p, errors.WithStack(x.WrapWithIdentityIDError(herodot.ErrInternalServerError.WithReason("Could not update flow").WithDebug(err.Error()), i.ID)))
+	}
+
+	return i, nil
+}
+
+// createIdentity creates the identity of a directory account which signs in
+// for the first time. If the mapped identity conflicts with an existing one
+// and linking is enabled, the directory account is linked to it instead.
+func (s *Strategy) createIdentity(ctx context.Context, f *login.Flow, conf *Configuration, entry *ldap.Entry, subject string, credentials identity.CredentialsLDAP, password string) (_ *identity.Identity, err error) {
+	ctx, span := s.d.Tracer(ctx).Tracer().Start(ctx, "selfservice.strategy.ldap.Strategy.createIdentity")
+	defer otelx.End(span, &err)
+
+	i := identity.NewIdentity(f.IdentitySchema.ID(ctx, s.d.Config()))
+	if err := s.mapEntry(ctx, conf, entry, i); err != nil {
+		return nil, err
+	}
+
+	if err := i.SetCredentialsWithConfig(s.ID(), identity.Credentials{Identifiers: []string{subject}}, credentials); err != nil {
+		return nil, err
+	}
+
+	if conf.ImportPassword {
+		if err := s.importPassword(ctx, i, password); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.d.IdentityManager().Create(ctx, i)
+	dup := new(identity.ErrDuplicateCredentials)
+	if !errors.As(err, &dup) {
+		return i, err
+	} else if !conf.LinkExistingIdentities {
+		return nil, schema.NewDuplicateCredentialsError(dup)
+	}
+
+	existing, _, _, err := s.d.IdentityManager().ConflictingIdentity(ctx, i)
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		return nil, schema.NewDuplicateCredentialsError(dup)
+	} else if err != nil {
+		return nil, err
+	}
+
+	s.d.Logger().WithField("identity_id", existing.ID).Info("Linking LDAP account to existing identity.")
+	return s.updateIdentity(ctx, existing.ID, conf, credentials, password, identity.WithAdditionalIdentifier(subject))
+}
+
+// updateIdentity updates the LDAP credentials and, if enabled, the imported
+// password hash of an identity which signed in using the directory.
+func (s *Strategy) updateIdentity(ctx context.Context, id uuid.UUID, conf *Configuration, credentials identity.CredentialsLDAP, password string, opts ...identity.CredentialsOptions) (_ *identity.Identity, err error) {
+	ctx, span := s.d.Tracer(ctx).Tracer().Start(ctx, "selfservice.strategy.ldap.Strategy.updateIdentity")
+	defer otelx.End(span, &err)
+
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed bool
+	var current identity.CredentialsLDAP
+	if c, ok := i.GetCredentials(s.ID()); ok {
+		_ = json.Unmarshal(c.Config, &current)
+	}
+	if current != credentials || len(opts) > 0 {
+		config, err := json.Marshal(credentials)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		i.UpsertCredentialsConfig(s.ID(), config, 0, opts...)
+		changed = true
+	}
+
+	if conf.ImportPassword {
+		var current identity.CredentialsPassword
+		if c, ok := i.GetCredentials(identity.CredentialsTypePassword); ok {
+			_ = json.Unmarshal(c.Config, &current)
+		}
+		if current.HashedPassword == "" || hash.Compare(ctx, []byte(password), []byte(current.HashedPassword)) != nil {
+			if err := s.importPassword(ctx, i, password); err != nil {
+				return nil, err
+			}
+			changed = true
+		}
+	}
+
+	if !changed {
+		return i, nil
+	}
+
+	if err := s.d.IdentityManager().Update(ctx, i, identity.ManagerAllowWriteProtectedTraits); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// importPassword stores the hash of the directory password as password
+// credentials. The identifiers are set from the identity schema.
+func (s *Strategy) importPassword(ctx context.Context, i *identity.Identity, password string) error {
+	hpw, err := s.d.Hasher(ctx).Generate(ctx, []byte(password))
+	if err != nil {
+		return err
+	}
+
+	c := i.GetCredentialsOr(identity.CredentialsTypePassword, &identity.Credentials{Identifiers: []string{}})
+	return i.SetCredentialsWithConfig(identity.CredentialsTypePassword, *c, &identity.CredentialsPassword{HashedPassword: string(hpw)})
+}
+
+// mapEntry maps the directory entry to the traits and metadata of the
+// identity using the configured Jsonnet mapper.
+func (s *Strategy) mapEntry(ctx context.Context, conf *Configuration, entry *ldap.Entry, i *identity.Identity) (err error) {
+	input, err := json.Marshal(mapperInput(entry))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	snippet, err := fetcher.NewFetcher(fetcher.WithClient(s.d.HTTPClient(ctx)), fetcher.WithCache(s.cache, 60*time.Minute)).FetchContext(ctx, conf.MapperURL)
+	if err != nil {
+		return err
+	}
+
+	vm, err := s.d.JsonnetVM(ctx)
+	if err != nil {
+		return err
+	}
+
+	vm.ExtCode("ldap", string(input))
+	evaluated, err := vm.EvaluateAnonymousSnippet(conf.MapperURL, snippet.String())
+	if err != nil {
+		trace.SpanFromContext(ctx).AddEvent(events.NewJsonnetMappingFailed(ctx, err, input, evaluated, "", s.ID().String()))
+		return errors.WithStack(herodot.ErrInternalServerError.WithWrap(err).WithDebug(err.Error()).WithReasonf("Unable to execute the LDAP Jsonnet mapper."))
+	}
+
+	traits := gjson.Get(evaluated, "identity.traits")
+	if !traits.IsObject() {
+		return errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("LDAP Jsonnet mapper did not return an object for key identity.traits. Please check your Jsonnet code!"))
+	}
+	i.Traits = identity.Traits(traits.Raw)
+
+	for key, target := range map[string]*sqlxx.NullJSONRawMessage{
+		"identity.metadata_public": &i.MetadataPublic,
+		"identity.metadata_admin":  &i.MetadataAdmin,
+	} {
+		metadata := gjson.Get(evaluated, key)
+		if metadata.Exists() && !metadata.IsObject() {
+			return errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("LDAP Jsonnet mapper did not return an object for key %s. Please check your Jsonnet code!", key))
+		}
+		*target = []byte(metadata.Raw)
+	}
+
+	return nil
+}
+
+func (s *Strategy) addIdentifierNode(r *http.Request, sr *login.Flow, value string, typ node.UiNodeInputAttributeType) error {
+	if typ == node.InputAttributeTypeHidden {
+		sr.UI.SetNode(node.NewInputField("identifier", value, node.DefaultGroup, typ))
+		return nil
+	}
+
+	ds, err := sr.IdentitySchema.URL(r.Context(), s.d.Config())
+	if err != nil {
+		return err
+	}
+
+	identifierLabel, err := login.GetIdentifierLabelFromSchema(r.Context(), ds.String())
+	if err != nil {
+		return err
+	}
+
+	sr.UI.SetNode(node.NewInputField("identifier", value, node.DefaultGroup, typ, node.WithRequiredInputAttribute).WithMetaLabel(identifierLabel))
+	return nil
+}
+
+// addPasswordNodes adds the password field, unless the password strategy
+// already added it, and the submit button of this strategy.
+func (s *Strategy) addPasswordNodes(r *http.Request, sr *login.Flow) {
+	sr.UI.SetCSRF(s.d.GenerateCSRFToken(r))
+	if sr.UI.Nodes.Find("password") == nil {
+		sr.UI.SetNode(node.NewInputField("password", nil, node.LDAPGroup,
+			node.InputAttributeTypePassword,
+			node.WithRequiredInputAttribute,
+			node.WithInputAttributes(func(a *node.InputAttributes) {
+				a.Autocomplete = node.InputAttributeAutocompleteCurrentPassword
+			})).
+			WithMetaLabel(text.NewInfoNodeInputPassword()))
+	}
+	sr.UI.GetNodes().Append(node.NewInputField("method", s.ID(), node.LDAPGroup, node.InputAttributeTypeSubmit).WithMetaLabel(text.NewInfoLoginLDAP()))
+}
+
+func (s *Strategy) PopulateLoginMethodFirstFactor(r *http.Request, sr *login.Flow) error {
+	if err := s.addIdentifierNode(r, sr, "", node.InputAttributeTypeText); err != nil {
+		return err
+	}
+
+	s.addPasswordNodes(r, sr)
+	return nil
+}
+
+func (s *Strategy) PopulateLoginMethodFirstFactorRefresh(r *http.Request, sr *login.Flow, sess *session.Session) (err error) {
+	ctx, span := s.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.strategy.ldap.Strategy.PopulateLoginMethodFirstFactorRefresh")
+	defer otelx.End(span, &err)
+
+	if sess == nil {
+		return nil
+	}
+
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, sess.IdentityID)
+	if err != nil {
+		return err
+	}
+
+	c, ok := i.GetCredentials(s.ID())
+	if !ok {
+		return nil
+	}
+
+	var conf identity.CredentialsLDAP
+	if err := json.Unmarshal(c.Config, &conf); err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReason("The LDAP credentials could not be decoded properly").WithDebug(err.Error()).WithWrap(err))
+	} else if conf.Username == "" {
+		return nil
+	}
+
+	if err := s.addIdentifierNode(r, sr, conf.Username, node.InputAttributeTypeHidden); err != nil {
+		return err
+	}
+
+	s.addPasswordNodes(r, sr)
+	return nil
+}
+
+func (s *Strategy) PopulateLoginMethodSecondFactor(*http.Request, *login.Flow) error {
+	return nil
+}
+
+func (s *Strategy) PopulateLoginMethodSecondFactorRefresh(*http.Request, *login.Flow) error {
+	return nil
+}
+
+func (s *Strategy) PopulateLoginMethodIdentifierFirstCredentials(r *http.Request, sr *login.Flow, opts ...login.FormHydratorModifier) (err error) {
+	ctx, span := s.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.strategy.ldap.Strategy.PopulateLoginMethodIdentifierFirstCredentials")
+	defer otelx.End(span, &err)
+
+	o := login.NewFormHydratorOptions(opts)
+
+	// Directory accounts which never signed in have no identity yet, which is
+	// why the form is shown unless the identity is known to not use LDAP.
+	count := 1
+	if o.IdentityHint != nil {
+		if count, err = s.CountActiveFirstFactorCredentials(ctx, o.IdentityHint.Credentials); err != nil {
+			return err
+		}
+	}
+
+	if count > 0 || s.d.Config().SecurityAccountEnumerationMitigate(ctx) {
+		s.addPasswordNodes(r, sr)
+	}
+
+	if count == 0 {
+		return errors.WithStack(idfirst.ErrNoCredentialsFound)
+	}
+
+	return nil
+}
+
+func (s *Strategy) PopulateLoginMethodIdentifierFirstIdentification(*http.Request, *login.Flow) error {
+	return nil
+}
@@ -0,0 +1,110 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ldap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/dgraph-io/ristretto/v2"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/decoderx"
+	"github.com/ory/x/jsonnetsecure"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/hash"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/ratelimit"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/kratos/x"
+	"github.com/ory/kratos/x/nosurfx"
+)
+
+var (
+	_ login.Strategy                    = new(Strategy)
+	_ identity.ActiveCredentialsCounter = new(Strategy)
+)
+
+type dependencies interface {
+	x.LoggingProvider
+	x.WriterProvider
+	x.HTTPClientProvider
+	x.TracingProvider
+	nosurfx.CSRFTokenGeneratorProvider
+	nosurfx.CSRFProvider
+	jsonnetsecure.VMProvider
+	config.Provider
+	hash.HashProvider
+
+	login.FlowPersistenceProvider
+
+	identity.PrivilegedPoolProvider
+	identity.ManagementProvider
+
+	ratelimit.LimiterProvider
+}
+
+// Strategy implements login by binding against an LDAP directory such as
+// OpenLDAP or Active Directory. The identity is created or linked on the
+// first successful login, with traits mapped from the directory entry using
+// Jsonnet.
+type Strategy struct {
+	d     dependencies
+	hd    *decoderx.HTTP
+	cache *ristretto.Cache[[]byte, []byte]
+}
+
+func NewStrategy(d dependencies) *Strategy {
+	cache, _ := ristretto.NewCache(&ristretto.Config[[]byte, []byte]{
+		MaxCost:     10 << 20, // 10MB,
+		NumCounters: 100_000,  // 1kB per snippet -> 10k snippets -> 100k counters
+		BufferItems: 64,
+	})
+	return &Strategy{d: d, hd: decoderx.NewHTTP(), cache: cache}
+}
+
+func (s *Strategy) config(ctx context.Context) (*Configuration, error) {
+	var c Configuration
+
+	conf := s.d.Config().SelfServiceStrategy(ctx, s.ID().String()).Config
+	if err := json.NewDecoder(bytes.NewBuffer(conf)).Decode(&c); err != nil {
+		s.d.Logger().WithError(err).WithField("config", conf)
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Unable to decode LDAP configuration: %s", err))
+	}
+
+	return &c, nil
+}
+
+func (s *Strategy) ID() identity.CredentialsType {
+	return identity.CredentialsTypeLDAP
+}
+
+func (s *Strategy) NodeGroup() node.UiNodeGroup {
+	return node.LDAPGroup
+}
+
+func (s *Strategy) CompletedAuthenticationMethod(_ context.Context) session.AuthenticationMethod {
+	return session.AuthenticationMethod{
+		Method: s.ID(),
+		AAL:    identity.AuthenticatorAssuranceLevel1,
+	}
+}
+
+func (s *Strategy) CountActiveFirstFactorCredentials(_ context.Context, cc map[identity.CredentialsType]identity.Credentials) (count int, err error) {
+	for _, c := range cc {
+		if c.Type == s.ID() && len(c.Identifiers) > 0 {
+			count++
+		}
+	}
+	return
+}
+
+func (s *Strategy) CountActiveMultiFactorCredentials(_ context.Context, _ map[identity.CredentialsType]identity.Credentials) (count int, err error) {
+	return 0, nil
+}
@@ -0,0 +1,291 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package ldap_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/hash"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/text"
+	"github.com/ory/x/sqlxx"
+)
+
+type entry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// newDirectory starts a minimal LDAP server which supports simple binds and
+// searches for `(uid=...)` filters, which is enough to mimic OpenLDAP or
+// Active Directory for the strategy.
+func newDirectory(t *testing.T, entries ...entry) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveDirectory(conn, entries)
+		}
+	}()
+
+	return "ldap://" + l.Addr().String()
+}
+
+func serveDirectory(conn net.Conn, entries []entry) {
+	defer conn.Close()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		id := packet.Children[0].Value
+		op := packet.Children[1]
+		switch op.Tag {
+		case goldap.ApplicationBindRequest:
+			dn, password := op.Children[1].Data.String(), op.Children[2].Data.String()
+			code := uint16(goldap.LDAPResultInvalidCredentials)
+			if dn == "cn=kratos,dc=example,dc=org" && password == "service-secret" {
+				code = goldap.LDAPResultSuccess
+			}
+			for _, e := range entries {
+				if strings.EqualFold(e.dn, dn) && e.password == password {
+					code = goldap.LDAPResultSuccess
+				}
+			}
+			writeResponse(conn, id, goldap.ApplicationBindResponse, code)
+		case goldap.ApplicationSearchRequest:
+			filter, _ := goldap.DecompileFilter(op.Children[6])
+			for _, e := range entries {
+				if strings.Contains(filter, "(uid="+e.attributes["uid"][0]+")") {
+					writeEntry(conn, id, e)
+				}
+			}
+			writeResponse(conn, id, goldap.ApplicationSearchResultDone, goldap.LDAPResultSuccess)
+		default:
+			return
+		}
+	}
+}
+
+func envelope(id any, op *ber.Packet) []byte {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+	packet.AppendChild(op)
+	return packet.Bytes()
+}
+
+func writeResponse(conn net.Conn, id any, tag ber.Tag, code uint16) {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, uint64(code), ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	_, _ = conn.Write(envelope(id, op))
+}
+
+func writeEntry(conn net.Conn, id any, e entry) {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, ""))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	for name, values := range e.attributes {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+		for _, v := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, ""))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	op.AppendChild(attributes)
+	_, _ = conn.Write(envelope(id, op))
+}
+
+func newEntry(uid, password string) entry {
+	return entry{
+		dn:       "uid=" + uid + ",ou=people,dc=example,dc=org",
+		password: password,
+		attributes: map[string][]string{
+			"uid":  {uid},
+			"cn":   {strings.ToUpper(uid[:1]) + uid[1:] + " Doe"},
+			"mail": {uid + "@example.org"},
+		},
+	}
+}
+
+func TestStrategy(t *testing.T) {
+	ctx := context.Background()
+	if testing.Short() {
+		t.Skip()
+	}
+
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/identity.schema.json")
+	conf.MustSet(ctx, config.ViperKeySecretsDefault, []string{"not-a-secure-session-key"})
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+".password.enabled", true)
+	publicTS, _ := testhelpers.NewKratosServer(t, reg)
+
+	directoryURL := newDirectory(t,
+		newEntry("jane", "directory-secret"),
+		newEntry("john", "directory-secret"),
+		newEntry("jack", "directory-secret"),
+		newEntry("jill", "directory-secret"),
+	)
+
+	setConfig := func(t *testing.T, values map[string]any) {
+		c := map[string]any{
+			"url":           directoryURL,
+			"bind_dn":       "cn=kratos,dc=example,dc=org",
+			"bind_password": "service-secret",
+			"base_dn":       "ou=people,dc=example,dc=org",
+			"mapper_url":    "file://./stub/ldap.jsonnet",
+		}
+		for k, v := range values {
+			c[k] = v
+		}
+		conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+".ldap", map[string]any{"enabled": true, "config": c})
+	}
+	setConfig(t, nil)
+
+	login := func(t *testing.T, method, identifier, password string) string {
+		client := testhelpers.NewDebugClient(t)
+		f := testhelpers.InitializeLoginFlowViaAPI(t, client, publicTS, false)
+		payload, err := json.Marshal(map[string]string{"method": method, "identifier": identifier, "password": password})
+		require.NoError(t, err)
+		body, _ := testhelpers.LoginMakeRequest(t, true, false, f, client, string(payload))
+		return body
+	}
+
+	assertInvalidCredentials := func(t *testing.T, body, identifier string) {
+		assert.EqualValues(t, text.ErrorValidationInvalidCredentials, gjson.Get(body, "ui.messages.0.id").Int(), "%s", body)
+		assert.Empty(t, gjson.Get(body, "ui.nodes.#(attributes.name==password).attributes.value").String(), "%s", body)
+		assert.Equal(t, identifier, gjson.Get(body, "ui.nodes.#(attributes.name==identifier).attributes.value").String(), "%s", body)
+	}
+
+	t.Run("case=shows the ldap method", func(t *testing.T) {
+		client := testhelpers.NewDebugClient(t)
+		f := testhelpers.InitializeLoginFlowViaAPI(t, client, publicTS, false)
+		nodes, err := json.Marshal(f.Ui.Nodes)
+		require.NoError(t, err)
+
+		assert.Equal(t, "ldap", gjson.GetBytes(nodes, "#(attributes.value==ldap).group").String(), "%s", nodes)
+		assert.EqualValues(t, text.InfoSelfServiceLoginLDAP, gjson.GetBytes(nodes, "#(attributes.value==ldap).meta.label.id").Int(), "%s", nodes)
+		assert.Len(t, gjson.GetBytes(nodes, "#(attributes.name==password)#").Array(), 1, "%s", nodes)
+	})
+
+	t.Run("case=creates the identity on the first login", func(t *testing.T) {
+		body := login(t, "ldap", "jane", "directory-secret")
+		require.NotEmpty(t, gjson.Get(body, "session_token").String(), "%s", body)
+		assert.Equal(t, "ldap", gjson.Get(body, "session.authentication_methods.0.method").String(), "%s", body)
+		assert.Equal(t, "jane@example.org", gjson.Get(body, "session.identity.traits.email").String(), "%s", body)
+		assert.Equal(t, "Jane Doe", gjson.Get(body, "session.identity.traits.name").String(), "%s", body)
+
+		i, c, err := reg.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, identity.CredentialsTypeLDAP, "uid=jane,ou=people,dc=example,dc=org")
+		require.NoError(t, err)
+		assert.Equal(t, gjson.Get(body, "session.identity.id").String(), i.ID.String())
+		assert.JSONEq(t, `{"dn":"uid=jane,ou=people,dc=example,dc=org","username":"jane"}`, string(c.Config))
+		assert.JSONEq(t, `{"dn":"uid=jane,ou=people,dc=example,dc=org"}`, string(i.MetadataAdmin))
+
+		t.Run("case=signs in to the same identity", func(t *testing.T) {
+			again := login(t, "ldap", "jane", "directory-secret")
+			assert.Equal(t, i.ID.String(), gjson.Get(again, "session.identity.id").String(), "%s", again)
+		})
+
+		t.Run("case=does not import the password by default", func(t *testing.T) {
+			assertInvalidCredentials(t, login(t, "password", "jane@example.org", "directory-secret"), "jane@example.org")
+		})
+	})
+
+	t.Run("case=fails with the wrong password", func(t *testing.T) {
+		assertInvalidCredentials(t, login(t, "ldap", "jane", "wrong-secret"), "jane")
+	})
+
+	t.Run("case=fails for unknown directory accounts", func(t *testing.T) {
+		body := login(t, "ldap", "jane*", "directory-secret")
+		assert.EqualValues(t, text.ErrorValidationInvalidCredentials, gjson.Get(body, "ui.messages.0.id").Int(), "%s", body)
+	})
+
+	t.Run("case=rejects an empty password", func(t *testing.T) {
+		body := login(t, "ldap", "jane", "")
+		assert.Empty(t, gjson.Get(body, "session_token").String(), "%s", body)
+		assert.NotEmpty(t, gjson.Get(body, "ui.nodes.#(attributes.name==password).messages.0.id").Int(), "%s", body)
+	})
+
+	t.Run("case=imports the password", func(t *testing.T) {
+		setConfig(t, map[string]any{"import_password": true})
+		t.Cleanup(func() { setConfig(t, nil) })
+
+		body := login(t, "ldap", "john", "directory-secret")
+		require.NotEmpty(t, gjson.Get(body, "session_token").String(), "%s", body)
+
+		body = login(t, "password", "john@example.org", "directory-secret")
+		require.NotEmpty(t, gjson.Get(body, "session_token").String(), "%s", body)
+		assert.Equal(t, "john@example.org", gjson.Get(body, "session.identity.traits.email").String(), "%s", body)
+	})
+
+	t.Run("case=links existing identities", func(t *testing.T) {
+		hpw, err := reg.Hasher(ctx).Generate(ctx, []byte("kratos-secret"))
+		require.NoError(t, err)
+
+		existing := func(t *testing.T, email string) *identity.Identity {
+			i := identity.NewIdentity(config.DefaultIdentityTraitsSchemaID)
+			i.Traits = identity.Traits(`{"email":"` + email + `"}`)
+			i.SetCredentials(identity.CredentialsTypePassword, identity.Credentials{
+				Identifiers: []string{email},
+				Config:      sqlxx.JSONRawMessage(`{"hashed_password":"` + string(hpw) + `"}`),
+			})
+			require.NoError(t, reg.IdentityManager().Create(ctx, i))
+			return i
+		}
+
+		t.Run("case=fails if linking is disabled", func(t *testing.T) {
+			existing(t, "jack@example.org")
+
+			body := login(t, "ldap", "jack", "directory-secret")
+			assert.Empty(t, gjson.Get(body, "session_token").String(), "%s", body)
+			assert.EqualValues(t, text.ErrorValidationDuplicateCredentials, gjson.Get(body, "ui.messages.0.id").Int(), "%s", body)
+		})
+
+		t.Run("case=links if enabled", func(t *testing.T) {
+			setConfig(t, map[string]any{"link_existing_identities": true, "import_password": true})
+			t.Cleanup(func() { setConfig(t, nil) })
+			i := existing(t, "jill@example.org")
+
+			body := login(t, "ldap", "jill", "directory-secret")
+			require.NotEmpty(t, gjson.Get(body, "session_token").String(), "%s", body)
+			assert.Equal(t, i.ID.String(), gjson.Get(body, "session.identity.id").String(), "%s", body)
+
+			actual, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+			require.NoError(t, err)
+			c, ok := actual.GetCredentials(identity.CredentialsTypeLDAP)
+			require.True(t, ok)
+			assert.Equal(t, []string{"uid=jill,ou=people,dc=example,dc=org"}, c.Identifiers)
+
+			c, ok = actual.GetCredentials(identity.CredentialsTypePassword)
+			require.True(t, ok)
+			assert.NoError(t, hash.Compare(ctx, []byte("directory-secret"), []byte(gjson.GetBytes(c.Config, "hashed_password").String())))
+		})
+	})
+}
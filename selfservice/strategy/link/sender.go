@@ -5,11 +5,14 @@ package link
 
 import (
 	"context"
+	"encoding/json"
 	"net/url"
 
+	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
 
 	"github.com/ory/kratos/courier"
+	"github.com/ory/kratos/courier/template"
 	"github.com/ory/kratos/courier/template/email"
 	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
@@ -76,12 +79,15 @@ func (s *Sender) SendRecoveryLink(ctx context.Context, f *recovery.Flow, via ide
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		locale := template.Locale(ctx, s.r, nil)
 		if !notifyUnknownRecipients {
 			// do nothing
 		} else if err := s.send(ctx, string(via), email.NewRecoveryInvalid(s.r, &email.RecoveryInvalidModel{
 			To:               to,
 			RequestURL:       f.GetRequestURL(),
 			TransientPayload: transientPayload,
+			Locale:           locale,
+			Branding:         template.ResolveBranding(ctx, s.r, "", uuid.NullUUID{}, locale),
 		})); err != nil {
 			return err
 		}
@@ -134,12 +140,15 @@ func (s *Sender) SendVerificationLink(ctx context.Context, f *verification.Flow,
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		locale := template.Locale(ctx, s.r, nil)
 		if !notifyUnknownRecipients {
 			// do nothing
 		} else if err := s.send(ctx, string(via), email.NewVerificationInvalid(s.r, &email.VerificationInvalidModel{
 			To:               to,
 			RequestURL:       f.GetRequestURL(),
 			TransientPayload: transientPayload,
+			Locale:           locale,
+			Branding:         template.ResolveBranding(ctx, s.r, "", uuid.NullUUID{}, locale),
 		})); err != nil {
 			return err
 		}
@@ -193,6 +202,7 @@ func (s *Sender) SendRecoveryTokenTo(ctx context.Context, f *recovery.Flow, i *i
 		}).
 		String()
 
+	locale := template.Locale(ctx, s.r, json.RawMessage(i.Traits))
 	return s.send(ctx, string(address.Via), email.NewRecoveryValid(s.r,
 		&email.RecoveryValidModel{
 			To:               address.Value,
@@ -201,6 +211,8 @@ func (s *Sender) SendRecoveryTokenTo(ctx context.Context, f *recovery.Flow, i *i
 			RequestURL:       f.GetRequestURL(),
 			TransientPayload: transientPayload,
 			ExpiresInMinutes: int(s.r.Config().SelfServiceLinkMethodLifespan(ctx).Minutes()),
+			Locale:           locale,
+			Branding:         template.ResolveBranding(ctx, s.r, i.SchemaID, i.OrganizationID, locale),
 		}))
 }
 
@@ -230,6 +242,7 @@ func (s *Sender) SendVerificationTokenTo(ctx context.Context, f *verification.Fl
 			"token": {token.Token},
 		}).String()
 
+	locale := template.Locale(ctx, s.r, json.RawMessage(i.Traits))
 	if err := s.send(ctx, address.Via, email.NewVerificationValid(s.r,
 		&email.VerificationValidModel{
 			To:               address.Value,
@@ -238,6 +251,8 @@ func (s *Sender) SendVerificationTokenTo(ctx context.Context, f *verification.Fl
 			RequestURL:       f.GetRequestURL(),
 			TransientPayload: transientPayload,
 			ExpiresInMinutes: int(s.r.Config().SelfServiceLinkMethodLifespan(ctx).Minutes()),
+			Locale:           locale,
+			Branding:         template.ResolveBranding(ctx, s.r, i.SchemaID, i.OrganizationID, locale),
 		})); err != nil {
 		return err
 	}
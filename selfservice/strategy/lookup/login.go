@@ -23,6 +23,7 @@ import (
 	"github.com/ory/kratos/schema"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/selfservice/ratelimit"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/ui/node"
@@ -117,6 +118,14 @@ func (s *Strategy) Login(_ http.ResponseWriter, r *http.Request, f *login.Flow,
 		return nil, s.handleLoginError(r, f, err)
 	}
 
+	if err := s.d.RateLimiter().Check(ctx, ratelimit.IdentityKey(sess.IdentityID)); err != nil {
+		return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(err, sess.IdentityID))
+	}
+
+	if err := s.d.IdentityManager().CheckLockout(ctx, sess.IdentityID); err != nil {
+		return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(err, sess.IdentityID))
+	}
+
 	i, c, err := s.d.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, s.ID(), sess.IdentityID.String())
 	if errors.Is(err, sqlcon.ErrNoRows) {
 		return nil, s.handleLoginError(r, f, errors.WithStack(schema.NewNoLookupDefined()))
@@ -142,9 +151,23 @@ func (s *Strategy) Login(_ http.ResponseWriter, r *http.Request, f *login.Flow,
 	}
 
 	if !found {
+		if err := s.d.RateLimiter().Hit(ctx, ratelimit.IdentityKey(i.ID), s.d.RateLimiter().IPKey(ctx, r)); err != nil {
+			return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(err, i.ID))
+		}
+		if err := s.d.IdentityManager().RecordFailedAuthentication(ctx, i.ID); err != nil {
+			return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(err, i.ID))
+		}
 		return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(errors.WithStack(schema.NewErrorValidationLookupInvalid()), i.ID))
 	}
 
+	if err := s.d.RateLimiter().Reset(ctx, ratelimit.IdentityKey(i.ID)); err != nil {
+		return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(err, i.ID))
+	}
+
+	if err := s.d.IdentityManager().ResetFailedAuthentications(ctx, i.ID); err != nil {
+		return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(err, i.ID))
+	}
+
 	// We can't use a transaction here because HydrateIdentityAssociations (used by update) does not support transactions.
 	toUpdate, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, sess.IdentityID)
 	if err != nil {
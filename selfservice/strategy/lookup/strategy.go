@@ -19,6 +19,7 @@ import (
 	"github.com/ory/kratos/selfservice/flow/login"
 	"github.com/ory/kratos/selfservice/flow/registration"
 	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/selfservice/ratelimit"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
@@ -69,6 +70,8 @@ type lookupStrategyDependencies interface {
 
 	session.HandlerProvider
 	session.ManagementProvider
+
+	ratelimit.LimiterProvider
 }
 
 type Strategy struct {
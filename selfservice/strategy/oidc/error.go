@@ -10,6 +10,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/ory/herodot"
+	"github.com/ory/kratos/text"
 	"github.com/ory/x/logrusx"
 )
 
@@ -21,6 +22,14 @@ var (
 	ErrIDTokenMissing = herodot.ErrBadRequest.
 				WithError("authentication failed because id_token is missing").
 				WithReasonf(`Authentication failed because no id_token was returned. Please accept the "openid" permission and try again.`)
+
+	ErrUpstreamGrantRevoked = herodot.ErrForbidden.WithID(text.ErrIDUpstreamGrantRevoked).
+				WithError("the identity provider rejected the refresh token").
+				WithReason("The grant of the identity provider was revoked or has expired. The identity must sign in with the provider again.")
+
+	ErrUpstreamTokenExpired = herodot.ErrForbidden.WithID(text.ErrIDUpstreamTokenExpired).
+				WithError("the access token expired and can not be refreshed").
+				WithReason("The access token of the identity provider has expired and no refresh token is available. The identity must sign in with the provider again.")
 )
 
 func logUpstreamError(l *logrusx.Logger, resp *http.Response) error {
@@ -9,9 +9,17 @@ import (
 )
 
 func NewLinkNode(providerID, providerLabel string) *node.Node {
-	return node.NewInputField("link", providerID, node.OpenIDConnectGroup, node.InputAttributeTypeSubmit).WithMetaLabel(text.NewInfoSelfServiceSettingsUpdateLinkOIDC(providerLabel))
+	return newLinkNode(node.OpenIDConnectGroup, providerID, providerLabel)
 }
 
 func NewUnlinkNode(providerID, providerLabel string) *node.Node {
-	return node.NewInputField("unlink", providerID, node.OpenIDConnectGroup, node.InputAttributeTypeSubmit).WithMetaLabel(text.NewInfoSelfServiceSettingsUpdateUnlinkOIDC(providerLabel))
+	return newUnlinkNode(node.OpenIDConnectGroup, providerID, providerLabel)
+}
+
+func newLinkNode(group node.UiNodeGroup, providerID, providerLabel string) *node.Node {
+	return node.NewInputField("link", providerID, group, node.InputAttributeTypeSubmit).WithMetaLabel(text.NewInfoSelfServiceSettingsUpdateLinkOIDC(providerLabel))
+}
+
+func newUnlinkNode(group node.UiNodeGroup, providerID, providerLabel string) *node.Node {
+	return node.NewInputField("unlink", providerID, group, node.InputAttributeTypeSubmit).WithMetaLabel(text.NewInfoSelfServiceSettingsUpdateUnlinkOIDC(providerLabel))
 }
@@ -37,6 +37,15 @@ type (
 		Claims(ctx context.Context, token *oauth1.Token) (*Claims, error)
 		ExchangeToken(ctx context.Context, req *http.Request) (*oauth1.Token, error)
 	}
+	// RedirectProvider is implemented by providers which speak a protocol other
+	// than OAuth, such as SAML 2.0. The provider handles the upstream response
+	// itself and redirects the browser to the callback URL with parameters from
+	// which the claims can be recovered.
+	RedirectProvider interface {
+		Provider
+		AuthURL(ctx context.Context, state string) (string, error)
+		CallbackClaims(ctx context.Context, query url.Values) (*Claims, error)
+	}
 )
 
 type OAuth2TokenExchanger interface {
@@ -47,6 +56,12 @@ type IDTokenVerifier interface {
 	Verify(ctx context.Context, rawIDToken string) (*Claims, error)
 }
 
+// LogoutTokenVerifier is implemented by providers which can verify OpenID
+// Connect Back-Channel Logout tokens.
+type LogoutTokenVerifier interface {
+	VerifyLogoutToken(ctx context.Context, rawLogoutToken string) (*Claims, error)
+}
+
 type NonceValidationSkipper interface {
 	CanSkipNonce(*Claims) bool
 }
@@ -116,6 +131,12 @@ func (c *Claims) Validate() error {
 	return nil
 }
 
+// sessionID returns the session ID (`sid`) of the provider, if any.
+func (c *Claims) sessionID() string {
+	sid, _ := c.RawClaims["sid"].(string)
+	return sid
+}
+
 // UpstreamParameters returns a list of oauth2.AuthCodeOption based on the upstream parameters.
 //
 // Only allowed parameters are returned and the rest is ignored.
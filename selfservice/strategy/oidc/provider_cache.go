@@ -0,0 +1,117 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gofrs/uuid"
+)
+
+// providerCacheTTL is how long the stored providers are cached. Writes using
+// the admin API invalidate the cache right away, the TTL only bounds how long
+// changes made on other instances take to be picked up.
+const providerCacheTTL = 30 * time.Second
+
+type (
+	// ProviderCache caches the decrypted configurations of the stored
+	// providers per network, so that they do not have to be loaded and
+	// decrypted on every request, as well as the key sets used to verify
+	// logout tokens. The zero value is ready to use.
+	ProviderCache struct {
+		mu         sync.Mutex
+		entries    map[uuid.UUID]providerCacheEntry
+		generation uint64
+		keySets    map[logoutKeySetKey]*logoutKeySet
+	}
+	providerCacheEntry struct {
+		providers []Configuration
+		expiresAt time.Time
+	}
+	ProviderCacheProvider interface {
+		OIDCProviderCache() *ProviderCache
+	}
+)
+
+// get returns the cached providers of the network. If there are none, it
+// returns the generation which has to be passed to set.
+func (c *ProviderCache) get(nid uuid.UUID) ([]Configuration, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[nid]; ok && time.Now().Before(e.expiresAt) {
+		return slices.Clone(e.providers), c.generation, true
+	}
+	return nil, c.generation, false
+}
+
+// set caches the providers of the network, unless the cache was invalidated
+// since the providers were loaded.
+func (c *ProviderCache) set(nid uuid.UUID, generation uint64, providers []Configuration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[uuid.UUID]providerCacheEntry)
+	}
+	c.entries[nid] = providerCacheEntry{providers: slices.Clone(providers), expiresAt: time.Now().Add(providerCacheTTL)}
+}
+
+// invalidate removes the cached providers of the network.
+func (c *ProviderCache) invalidate(nid uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	delete(c.entries, nid)
+}
+
+// logoutKeySetTTL is how long the discovered issuer and key set used to verify
+// logout tokens are cached. The key set itself fetches the keys again when it
+// encounters an unknown key ID, so rotated keys are picked up right away.
+const logoutKeySetTTL = time.Hour
+
+// maxLogoutKeySets bounds the number of cached key sets.
+const maxLogoutKeySets = 128
+
+type (
+	logoutKeySetKey struct {
+		provider, issuerURL string
+	}
+	logoutKeySet struct {
+		issuer    string
+		keySet    gooidc.KeySet
+		expiresAt time.Time
+	}
+)
+
+// logoutKeySet returns the cached key set of the provider.
+func (c *ProviderCache) logoutKeySet(key logoutKeySetKey) (*logoutKeySet, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ks, ok := c.keySets[key]; ok && time.Now().Before(ks.expiresAt) {
+		return ks, true
+	}
+	return nil, false
+}
+
+// setLogoutKeySet caches the key set of the provider.
+func (c *ProviderCache) setLogoutKeySet(key logoutKeySetKey, issuer string, keySet gooidc.KeySet) *logoutKeySet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.keySets == nil || len(c.keySets) >= maxLogoutKeySets {
+		c.keySets = make(map[logoutKeySetKey]*logoutKeySet)
+	}
+	ks := &logoutKeySet{issuer: issuer, keySet: keySet, expiresAt: time.Now().Add(logoutKeySetTTL)}
+	c.keySets[key] = ks
+	return ks
+}
@@ -0,0 +1,230 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/tls"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/httpx"
+	"github.com/ory/x/stringsx"
+)
+
+const (
+	TokenEndpointAuthMethodClientSecretBasic = "client_secret_basic"
+	TokenEndpointAuthMethodClientSecretPost  = "client_secret_post"
+	TokenEndpointAuthMethodPrivateKeyJWT     = "private_key_jwt"
+	TokenEndpointAuthMethodTLSClientAuth     = "tls_client_auth"
+
+	clientAssertionType     = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	clientAssertionLifespan = 5 * time.Minute
+)
+
+// clientAssertionSigner signs the client assertions of the private_key_jwt
+// token endpoint authentication method.
+type clientAssertionSigner struct {
+	key    any
+	keyID  string
+	method jwt.SigningMethod
+}
+
+func newClientAssertionSigner(c *Configuration) (*clientAssertionSigner, error) {
+	raw := strings.TrimSpace(c.ClientAssertionKey)
+	if raw == "" {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("OpenID Connect provider %q uses private_key_jwt, but no client assertion key is configured.", c.ID))
+	}
+
+	key, err := jwk.ParseKey([]byte(raw), jwk.WithPEM(!strings.HasPrefix(raw, "{")))
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Unable to parse the client assertion key of OpenID Connect provider %q: %s", c.ID, err))
+	}
+
+	var private any
+	if err := key.Raw(&private); err != nil {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Unable to parse the client assertion key of OpenID Connect provider %q: %s", c.ID, err))
+	}
+
+	var alg string
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		alg = "RS256"
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			alg = "ES256"
+		case elliptic.P384():
+			alg = "ES384"
+		case elliptic.P521():
+			alg = "ES512"
+		}
+	case ed25519.PrivateKey:
+		alg = "EdDSA"
+	default:
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("The client assertion key of OpenID Connect provider %q must be an RSA, ECDSA, or Ed25519 private key.", c.ID))
+	}
+
+	if key.Algorithm().String() != "" {
+		alg = key.Algorithm().String()
+	}
+	if c.ClientAssertionSigningAlgorithm != "" {
+		alg = c.ClientAssertionSigningAlgorithm
+	}
+
+	method := jwt.GetSigningMethod(alg)
+	if method == nil || alg == jwt.SigningMethodNone.Alg() || strings.HasPrefix(alg, "HS") {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("The client assertion signing algorithm %q of OpenID Connect provider %q is not supported.", alg, c.ID))
+	}
+
+	return &clientAssertionSigner{
+		key:    private,
+		keyID:  stringsx.Coalesce(c.ClientAssertionKeyID, key.KeyID()),
+		method: method,
+	}, nil
+}
+
+// sign returns a client assertion for the token endpoint.
+func (s *clientAssertionSigner) sign(clientID, audience string) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(s.method, jwt.RegisteredClaims{
+		Issuer:    clientID,
+		Subject:   clientID,
+		Audience:  jwt.ClaimStrings{audience},
+		ID:        uuid.Must(uuid.NewV4()).String(),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(clientAssertionLifespan)),
+	})
+	if s.keyID != "" {
+		token.Header["kid"] = s.keyID
+	}
+
+	assertion, err := token.SignedString(s.key)
+	if err != nil {
+		return "", errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to sign the client assertion: %s", err))
+	}
+	return assertion, nil
+}
+
+func clientCertificate(c *Configuration) (tls.Certificate, error) {
+	cert, err := tls.X509KeyPair([]byte(c.ClientCertificate), []byte(c.ClientCertificateKey))
+	if err != nil {
+		return cert, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Unable to load the client certificate of OpenID Connect provider %q: %s", c.ID, err))
+	}
+	return cert, nil
+}
+
+// tokenEndpointContext returns a context whose OAuth 2.0 HTTP client
+// authenticates the client at the token endpoint with the token endpoint
+// authentication method of the provider.
+func tokenEndpointContext(ctx context.Context, d x.HTTPClientProvider, c *Configuration) (context.Context, error) {
+	var (
+		opts   []httpx.ResilientOptions
+		signer *clientAssertionSigner
+	)
+	switch c.TokenEndpointAuthMethod {
+	case "", TokenEndpointAuthMethodClientSecretBasic, TokenEndpointAuthMethodClientSecretPost:
+	case TokenEndpointAuthMethodPrivateKeyJWT:
+		var err error
+		if signer, err = newClientAssertionSigner(c); err != nil {
+			return nil, err
+		}
+	case TokenEndpointAuthMethodTLSClientAuth:
+		cert, err := clientCertificate(c)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, httpx.ResilientClientWithClientCertificates(cert))
+	default:
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("The token endpoint authentication method %q of OpenID Connect provider %q is not supported.", c.TokenEndpointAuthMethod, c.ID))
+	}
+
+	client := *d.HTTPClient(ctx, opts...).HTTPClient
+	if c.TokenEndpointAuthMethod != "" {
+		base := client.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		client.Transport = &tokenEndpointAuthTransport{base: base, method: c.TokenEndpointAuthMethod, signer: signer}
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, &client), nil
+}
+
+// tokenEndpointAuthTransport rewrites the client authentication of token
+// requests, which the OAuth 2.0 library always sends with the client secret.
+type tokenEndpointAuthTransport struct {
+	base   http.RoundTripper
+	method string
+	signer *clientAssertionSigner
+}
+
+func (t *tokenEndpointAuthTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); r.Method != http.MethodPost || r.Body == nil || mediaType != "application/x-www-form-urlencoded" {
+		return t.base.RoundTrip(r)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	clientID, clientSecret := form.Get("client_id"), form.Get("client_secret")
+	if id, secret, ok := r.BasicAuth(); ok {
+		// The credentials of the Authorization header are form-encoded (RFC 6749, Section 2.3.1).
+		clientID, _ = url.QueryUnescape(id)
+		clientSecret, _ = url.QueryUnescape(secret)
+	}
+
+	r = r.Clone(r.Context())
+	r.Header.Del("Authorization")
+	form.Del("client_secret")
+	form.Set("client_id", clientID)
+
+	switch t.method {
+	case TokenEndpointAuthMethodClientSecretBasic:
+		form.Del("client_id")
+		r.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	case TokenEndpointAuthMethodClientSecretPost:
+		form.Set("client_secret", clientSecret)
+	case TokenEndpointAuthMethodPrivateKeyJWT:
+		audience := *r.URL
+		audience.RawQuery, audience.Fragment = "", ""
+		assertion, err := t.signer.sign(clientID, audience.String())
+		if err != nil {
+			return nil, err
+		}
+		form.Set("client_assertion_type", clientAssertionType)
+		form.Set("client_assertion", assertion)
+	}
+
+	encoded := []byte(form.Encode())
+	r.Body = io.NopCloser(bytes.NewReader(encoded))
+	r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(encoded)), nil }
+	r.ContentLength = int64(len(encoded))
+	r.Header.Set("Content-Length", strconv.Itoa(len(encoded)))
+	return t.base.RoundTrip(r)
+}
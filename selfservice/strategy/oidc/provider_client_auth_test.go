@@ -0,0 +1,186 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/ory/x/httpx"
+)
+
+type httpClientProvider struct{}
+
+func (httpClientProvider) HTTPClient(_ context.Context, opts ...httpx.ResilientOptions) *retryablehttp.Client {
+	return httpx.NewResilientClient(opts...)
+}
+
+func TestTokenEndpointAuth(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecJWK, err := jwk.FromRaw(ecKey)
+	require.NoError(t, err)
+	require.NoError(t, ecJWK.Set(jwk.KeyIDKey, "ec-key"))
+	ecJSON, err := json.Marshal(ecJWK)
+	require.NoError(t, err)
+
+	certTemplate := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "client"}, NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	certDER, err := x509.CreateCertificate(rand.Reader, certTemplate, certTemplate, &ecKey.PublicKey, ecKey)
+	require.NoError(t, err)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}))
+	certKeyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}))
+
+	var requests []*http.Request
+	var connections atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		requests = append(requests, r)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access-token","token_type":"bearer"}`))
+	}))
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	exchange := func(t *testing.T, c *Configuration) *http.Request {
+		requests = nil
+		ctx, err := tokenEndpointContext(context.Background(), httpClientProvider{}, c)
+		require.NoError(t, err)
+
+		conf := &oauth2.Config{ClientID: c.ClientID, ClientSecret: c.ClientSecret, Endpoint: oauth2.Endpoint{TokenURL: ts.URL + "/token"}}
+		token, err := conf.Exchange(ctx, "code")
+		require.NoError(t, err)
+		assert.Equal(t, "access-token", token.AccessToken)
+		require.Len(t, requests, 1)
+		assert.Equal(t, "code", requests[0].PostForm.Get("code"))
+		return requests[0]
+	}
+
+	verifyAssertion := func(t *testing.T, r *http.Request, key any, alg, kid string) {
+		assert.Equal(t, clientAssertionType, r.PostForm.Get("client_assertion_type"))
+		claims := new(jwt.RegisteredClaims)
+		token, err := jwt.ParseWithClaims(r.PostForm.Get("client_assertion"), claims, func(*jwt.Token) (any, error) { return key, nil },
+			jwt.WithValidMethods([]string{alg}), jwt.WithAudience(ts.URL+"/token"), jwt.WithIssuer("client"), jwt.WithSubject("client"))
+		require.NoError(t, err)
+		if kid == "" {
+			assert.NotContains(t, token.Header, "kid")
+		} else {
+			assert.Equal(t, kid, token.Header["kid"])
+		}
+		assert.NotEmpty(t, claims.ID)
+	}
+
+	t.Run("method=default", func(t *testing.T) {
+		r := exchange(t, &Configuration{ClientID: "client", ClientSecret: "secret"})
+		id, secret, ok := r.BasicAuth()
+		require.True(t, ok)
+		assert.Equal(t, "client", id)
+		assert.Equal(t, "secret", secret)
+	})
+
+	t.Run("method=client_secret_post", func(t *testing.T) {
+		r := exchange(t, &Configuration{ClientID: "client", ClientSecret: "s3cr=t", TokenEndpointAuthMethod: TokenEndpointAuthMethodClientSecretPost})
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.Equal(t, "client", r.PostForm.Get("client_id"))
+		assert.Equal(t, "s3cr=t", r.PostForm.Get("client_secret"))
+	})
+
+	t.Run("method=client_secret_basic", func(t *testing.T) {
+		r := exchange(t, &Configuration{ClientID: "client", ClientSecret: "s3cr=t", TokenEndpointAuthMethod: TokenEndpointAuthMethodClientSecretBasic})
+		id, secret, ok := r.BasicAuth()
+		require.True(t, ok)
+		assert.Equal(t, "client", id)
+		assert.Equal(t, url.QueryEscape("s3cr=t"), secret)
+		assert.Empty(t, r.PostForm.Get("client_secret"))
+	})
+
+	t.Run("method=private_key_jwt", func(t *testing.T) {
+		t.Run("key=rsa pem", func(t *testing.T) {
+			r := exchange(t, &Configuration{ClientID: "client", ClientSecret: "ignored", TokenEndpointAuthMethod: TokenEndpointAuthMethodPrivateKeyJWT, ClientAssertionKey: rsaPEM, ClientAssertionKeyID: "rsa-key"})
+			assert.Empty(t, r.Header.Get("Authorization"))
+			assert.Empty(t, r.PostForm.Get("client_secret"))
+			assert.Equal(t, "client", r.PostForm.Get("client_id"))
+			verifyAssertion(t, r, &rsaKey.PublicKey, "RS256", "rsa-key")
+		})
+
+		t.Run("key=rsa pem with algorithm", func(t *testing.T) {
+			r := exchange(t, &Configuration{ClientID: "client", TokenEndpointAuthMethod: TokenEndpointAuthMethodPrivateKeyJWT, ClientAssertionKey: rsaPEM, ClientAssertionSigningAlgorithm: "PS384"})
+			verifyAssertion(t, r, &rsaKey.PublicKey, "PS384", "")
+		})
+
+		t.Run("key=ecdsa jwk", func(t *testing.T) {
+			r := exchange(t, &Configuration{ClientID: "client", TokenEndpointAuthMethod: TokenEndpointAuthMethodPrivateKeyJWT, ClientAssertionKey: string(ecJSON)})
+			verifyAssertion(t, r, &ecKey.PublicKey, "ES256", "ec-key")
+		})
+	})
+
+	t.Run("method=tls_client_auth", func(t *testing.T) {
+		r := exchange(t, &Configuration{ClientID: "client", TokenEndpointAuthMethod: TokenEndpointAuthMethodTLSClientAuth, ClientCertificate: certPEM, ClientCertificateKey: certKeyPEM})
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.Empty(t, r.PostForm.Get("client_secret"))
+		assert.Equal(t, "client", r.PostForm.Get("client_id"))
+
+		t.Run("case=reuses the connections of the certificate", func(t *testing.T) {
+			connections.Store(0)
+			for range 3 {
+				exchange(t, &Configuration{ClientID: "client", TokenEndpointAuthMethod: TokenEndpointAuthMethodTLSClientAuth, ClientCertificate: certPEM, ClientCertificateKey: certKeyPEM})
+			}
+			assert.LessOrEqual(t, connections.Load(), int32(1), "the connection is reused")
+		})
+	})
+
+	t.Run("case=invalid configurations", func(t *testing.T) {
+		publicJWK, err := jwk.FromRaw(&rsaKey.PublicKey)
+		require.NoError(t, err)
+		publicJSON, err := json.Marshal(publicJWK)
+		require.NoError(t, err)
+
+		for name, c := range map[string]*Configuration{
+			"unknown method":      {TokenEndpointAuthMethod: "none"},
+			"missing key":         {TokenEndpointAuthMethod: TokenEndpointAuthMethodPrivateKeyJWT},
+			"invalid key":         {TokenEndpointAuthMethod: TokenEndpointAuthMethodPrivateKeyJWT, ClientAssertionKey: "not a key"},
+			"public key":          {TokenEndpointAuthMethod: TokenEndpointAuthMethodPrivateKeyJWT, ClientAssertionKey: string(publicJSON)},
+			"symmetric alg":       {TokenEndpointAuthMethod: TokenEndpointAuthMethodPrivateKeyJWT, ClientAssertionKey: rsaPEM, ClientAssertionSigningAlgorithm: "HS256"},
+			"missing cert":        {TokenEndpointAuthMethod: TokenEndpointAuthMethodTLSClientAuth},
+			"mismatched cert key": {TokenEndpointAuthMethod: TokenEndpointAuthMethodTLSClientAuth, ClientCertificate: certPEM, ClientCertificateKey: rsaPEM},
+		} {
+			t.Run("case="+name, func(t *testing.T) {
+				_, err := tokenEndpointContext(context.Background(), httpClientProvider{}, c)
+				require.Error(t, err)
+			})
+		}
+	})
+}
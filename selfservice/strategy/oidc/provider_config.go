@@ -51,6 +51,35 @@ type Configuration struct {
 	// ClientSecret is the application's secret.
 	ClientSecret string `json:"client_secret"`
 
+	// TokenEndpointAuthMethod is the method the client authenticates with at the token endpoint.
+	// Possible values are:
+	// - `client_secret_basic`: The client secret is sent using HTTP Basic authentication.
+	// - `client_secret_post`: The client secret is sent in the request body.
+	// - `private_key_jwt`: A JWT signed with `client_assertion_key` is sent (RFC 7523).
+	// - `tls_client_auth`: The client presents `client_certificate` during the TLS handshake (RFC 8705).
+	// If empty, the client secret is sent using HTTP Basic authentication, falling back to the request body.
+	TokenEndpointAuthMethod string `json:"token_endpoint_auth_method"`
+
+	// ClientAssertionKey is the private key which signs client assertions if
+	// `token_endpoint_auth_method` is `private_key_jwt`. It can be either a JSON Web Key
+	// or a PEM-encoded RSA, ECDSA, or Ed25519 private key.
+	ClientAssertionKey string `json:"client_assertion_key"`
+
+	// ClientAssertionKeyID is the key ID of client assertions. Defaults to the `kid` of the JSON Web Key.
+	ClientAssertionKeyID string `json:"client_assertion_key_id"`
+
+	// ClientAssertionSigningAlgorithm is the algorithm which signs client assertions, for example `RS256`,
+	// `PS256`, `ES256`, or `EdDSA`. Defaults to the `alg` of the JSON Web Key, or to `RS256`, `ES256`,
+	// `ES384`, `ES512`, or `EdDSA` depending on the key type.
+	ClientAssertionSigningAlgorithm string `json:"client_assertion_signing_alg"`
+
+	// ClientCertificate is the PEM-encoded certificate chain the client presents if
+	// `token_endpoint_auth_method` is `tls_client_auth`.
+	ClientCertificate string `json:"client_certificate"`
+
+	// ClientCertificateKey is the PEM-encoded private key of `client_certificate`.
+	ClientCertificateKey string `json:"client_certificate_key"`
+
 	// IssuerURL is the OpenID Connect Server URL. You can leave this empty if `provider` is not set to `generic`.
 	// If set, neither `auth_url` nor `token_url` are required.
 	IssuerURL string `json:"issuer_url"`
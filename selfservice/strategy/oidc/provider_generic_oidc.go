@@ -115,6 +115,49 @@ func (g *ProviderGenericOIDC) verifyAndDecodeClaimsWithProvider(ctx context.Cont
 	return &claims, nil
 }
 
+var _ LogoutTokenVerifier = (*ProviderGenericOIDC)(nil)
+
+// VerifyLogoutToken verifies the logout token with the keys and the issuer of
+// the discovery document.
+func (g *ProviderGenericOIDC) VerifyLogoutToken(ctx context.Context, rawLogoutToken string) (*Claims, error) {
+	ks, err := g.logoutKeySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := verifyToken(g.withHTTPClientContext(ctx), ks.keySet, g.config, rawLogoutToken, ks.issuer)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("The logout token is invalid: %s", err))
+	}
+	return claims, nil
+}
+
+// logoutKeySet returns the issuer and the key set of the provider, which are
+// cached so that discovery and the JWKS request do not run for every logout
+// token.
+func (g *ProviderGenericOIDC) logoutKeySet(ctx context.Context) (*logoutKeySet, error) {
+	key := logoutKeySetKey{provider: g.config.ID, issuerURL: g.config.IssuerURL}
+	if ks, ok := g.reg.OIDCProviderCache().logoutKeySet(key); ok {
+		return ks, nil
+	}
+
+	p, err := g.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURL string `json:"jwks_uri"`
+	}
+	if err := p.Claims(&discovery); err != nil {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Unable to decode the discovery document of the OpenID Connect provider: %s", err))
+	}
+
+	keySet := gooidc.NewRemoteKeySet(g.withHTTPClientContext(ctx), discovery.JWKSURL)
+	return g.reg.OIDCProviderCache().setLogoutKeySet(key, discovery.Issuer, keySet), nil
+}
+
 func (g *ProviderGenericOIDC) Claims(ctx context.Context, exchange *oauth2.Token, _ url.Values) (*Claims, error) {
 	switch g.config.ClaimsSource {
 	case ClaimsSourceIDToken, "":
@@ -0,0 +1,481 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow/login"
+	"github.com/ory/kratos/x"
+	"github.com/ory/kratos/x/nosurfx"
+	"github.com/ory/kratos/x/redir"
+	"github.com/ory/x/jsonx"
+)
+
+const (
+	AdminRouteProviders = "/oidc/providers"
+	AdminRouteProvider  = AdminRouteProviders + "/{id}"
+
+	AdminRouteIdentityToken = "/identities/{id}/credentials/oidc/{provider}/token"
+)
+
+type (
+	handlerDependencies interface {
+		storeDependencies
+		login.StrategyProvider
+		x.WriterProvider
+		x.LoggingProvider
+		nosurfx.CSRFProvider
+	}
+	// Handler manages the OpenID Connect providers which are stored in the
+	// database.
+	Handler struct {
+		r handlerDependencies
+	}
+	HandlerProvider interface {
+		OIDCProviderHandler() *Handler
+	}
+)
+
+func NewHandler(r handlerDependencies) *Handler {
+	return &Handler{r: r}
+}
+
+func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
+	h.r.CSRFHandler().IgnoreGlobs(
+		x.AdminPrefix+AdminRouteProviders, AdminRouteProviders,
+		x.AdminPrefix+AdminRouteProviders+"/*", AdminRouteProviders+"/*",
+	)
+	public.GET(x.AdminPrefix+AdminRouteProviders, redir.RedirectToAdminRoute(h.r))
+	public.POST(x.AdminPrefix+AdminRouteProviders, redir.RedirectToAdminRoute(h.r))
+	public.GET(x.AdminPrefix+AdminRouteProvider, redir.RedirectToAdminRoute(h.r))
+	public.PUT(x.AdminPrefix+AdminRouteProvider, redir.RedirectToAdminRoute(h.r))
+	public.DELETE(x.AdminPrefix+AdminRouteProvider, redir.RedirectToAdminRoute(h.r))
+	public.GET(x.AdminPrefix+AdminRouteIdentityToken, redir.RedirectToAdminRoute(h.r))
+}
+
+func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
+	admin.GET(AdminRouteProviders, h.listOIDCProviders)
+	admin.POST(AdminRouteProviders, h.createOIDCProvider)
+	admin.GET(AdminRouteProvider, h.getOIDCProvider)
+	admin.PUT(AdminRouteProvider, h.updateOIDCProvider)
+	admin.DELETE(AdminRouteProvider, h.deleteOIDCProvider)
+	admin.GET(AdminRouteIdentityToken, h.getIdentityOIDCToken)
+}
+
+// Stored OpenID Connect Provider
+//
+// An OpenID Connect provider which is managed using the admin API. Stored
+// providers are offered alongside the providers of the configuration file.
+// Secrets are encrypted at rest and never returned.
+//
+// swagger:model oidcProvider
+type storedProviderResponse struct {
+	Configuration
+
+	// required: true
+	CreatedAt time.Time `json:"created_at"`
+
+	// required: true
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (h *Handler) response(sp *StoredProvider) (*storedProviderResponse, error) {
+	c, err := sp.encryptedConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	return &storedProviderResponse{Configuration: c.redacted(), CreatedAt: sp.CreatedAt, UpdatedAt: sp.UpdatedAt}, nil
+}
+
+// List OpenID Connect Providers Response
+//
+// swagger:response listOIDCProviders
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type listOIDCProvidersResponse struct {
+	// in: body
+	Body []storedProviderResponse
+}
+
+// swagger:route GET /admin/oidc/providers oidc listOIDCProviders
+//
+// # List Stored OpenID Connect Providers
+//
+// Lists the OpenID Connect providers which are stored in the database. The
+// providers of the configuration file are not included.
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: listOIDCProviders
+//	  default: errorGeneric
+func (h *Handler) listOIDCProviders(w http.ResponseWriter, r *http.Request) {
+	stored, err := h.r.OIDCProviderPersister().ListOIDCProviders(r.Context())
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	providers := make([]storedProviderResponse, 0, len(stored))
+	for k := range stored {
+		p, err := h.response(&stored[k])
+		if err != nil {
+			h.r.Writer().WriteError(w, r, err)
+			return
+		}
+		providers = append(providers, *p)
+	}
+
+	h.r.Writer().Write(w, r, providers)
+}
+
+// OpenID Connect Provider Parameters
+//
+// swagger:parameters getOIDCProvider deleteOIDCProvider
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type oidcProviderParameters struct {
+	// ID is the ID of the provider.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:route GET /admin/oidc/providers/{id} oidc getOIDCProvider
+//
+// # Get a Stored OpenID Connect Provider
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: oidcProvider
+//	  404: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) getOIDCProvider(w http.ResponseWriter, r *http.Request) {
+	sp, err := h.r.OIDCProviderPersister().GetOIDCProvider(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	p, err := h.response(sp)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+	h.r.Writer().Write(w, r, p)
+}
+
+// Create OpenID Connect Provider Parameters
+//
+// swagger:parameters createOIDCProvider
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type createOIDCProvider struct {
+	// in: body
+	Body Configuration
+}
+
+// swagger:route POST /admin/oidc/providers oidc createOIDCProvider
+//
+// # Create a Stored OpenID Connect Provider
+//
+// Stores an OpenID Connect provider in the database. The issuer is discovered
+// and the mapper is compiled before the provider is stored. The ID must not be
+// used by a provider of the configuration file.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  201: oidcProvider
+//	  400: errorGeneric
+//	  409: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) createOIDCProvider(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var c Configuration
+	if err := jsonx.NewStrictDecoder(r.Body).Decode(&c); err != nil {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithError(err.Error()).WithReason("The request body is not a valid provider configuration.")))
+		return
+	}
+
+	if err := h.validate(r, &c); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	sp, err := newStoredProvider(ctx, h.r, c, nil)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if err := h.r.OIDCProviderPersister().CreateOIDCProvider(ctx, sp); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+	invalidateStoredConfigurations(ctx, h.r)
+
+	h.r.Logger().
+		WithField("provider", sp.ProviderID).
+		Info("An administrator created an OpenID Connect provider.")
+
+	p, err := h.response(sp)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+	h.r.Writer().WriteCode(w, r, http.StatusCreated, p)
+}
+
+// Update OpenID Connect Provider Parameters
+//
+// swagger:parameters updateOIDCProvider
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type updateOIDCProvider struct {
+	// ID is the ID of the provider.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+
+	// in: body
+	Body Configuration
+}
+
+// swagger:route PUT /admin/oidc/providers/{id} oidc updateOIDCProvider
+//
+// # Update a Stored OpenID Connect Provider
+//
+// Replaces the configuration of a stored OpenID Connect provider. Secrets which
+// are left empty keep their stored value.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: oidcProvider
+//	  400: errorGeneric
+//	  404: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) updateOIDCProvider(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var c Configuration
+	if err := jsonx.NewStrictDecoder(r.Body).Decode(&c); err != nil {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithError(err.Error()).WithReason("The request body is not a valid provider configuration.")))
+		return
+	}
+
+	id := r.PathValue("id")
+	if c.ID == "" {
+		c.ID = id
+	} else if c.ID != id {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("The provider ID %q of the body does not match the provider ID %q of the URL.", c.ID, id)))
+		return
+	}
+
+	existing, err := h.r.OIDCProviderPersister().GetOIDCProvider(ctx, id)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if err := keepSecrets(ctx, h.r, &c, existing); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if err := h.validate(r, &c); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	sp, err := newStoredProvider(ctx, h.r, c, existing)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if err := h.r.OIDCProviderPersister().UpdateOIDCProvider(ctx, sp); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+	invalidateStoredConfigurations(ctx, h.r)
+
+	h.r.Logger().
+		WithField("provider", sp.ProviderID).
+		Info("An administrator updated an OpenID Connect provider.")
+
+	p, err := h.response(sp)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+	h.r.Writer().Write(w, r, p)
+}
+
+// validate rejects invalid configurations and IDs of providers in the
+// configuration file.
+func (h *Handler) validate(r *http.Request, c *Configuration) error {
+	static, err := staticConfig(r.Context(), h.r)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(static.Providers, func(p Configuration) bool { return p.ID == c.ID }) {
+		return errors.WithStack(herodot.ErrConflict.WithReasonf("The provider ID %q is used by a provider of the configuration file.", c.ID))
+	}
+
+	return validateConfiguration(r.Context(), h.r, c)
+}
+
+// swagger:route DELETE /admin/oidc/providers/{id} oidc deleteOIDCProvider
+//
+// # Delete a Stored OpenID Connect Provider
+//
+// Deletes a stored OpenID Connect provider. Identities keep their credentials
+// of the provider, but can no longer sign in with it.
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  204: emptyResponse
+//	  404: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) deleteOIDCProvider(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.r.OIDCProviderPersister().DeleteOIDCProvider(r.Context(), id); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+	invalidateStoredConfigurations(r.Context(), h.r)
+
+	h.r.Logger().
+		WithField("provider", id).
+		Info("An administrator deleted an OpenID Connect provider.")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Get Upstream Access Token Parameters
+//
+// swagger:parameters getIdentityOidcToken
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type getIdentityOIDCToken struct {
+	// ID is the ID of the identity.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+
+	// Provider is the ID of the OpenID Connect provider.
+	//
+	// required: true
+	// in: path
+	Provider string `json:"provider"`
+}
+
+// swagger:route GET /admin/identities/{id}/credentials/oidc/{provider}/token identity getIdentityOidcToken
+//
+// # Get the Upstream Access Token of an Identity
+//
+// Returns a valid access token of the OpenID Connect provider which the
+// identity signed in with. Expired access tokens are refreshed using the stored
+// refresh token, and the refreshed tokens are stored.
+//
+// If the provider rejects the refresh token, because the grant was revoked or
+// has expired, the error ID is `upstream_grant_revoked`. If the access token
+// has expired and no refresh token is stored, the error ID is
+// `upstream_token_expired`. In both cases the identity must sign in with the
+// provider again.
+//
+//	Produces:
+//	- application/json
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: oidcUpstreamToken
+//	  403: errorGeneric
+//	  404: errorGeneric
+//	  502: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) getIdentityOIDCToken(w http.ResponseWriter, r *http.Request) {
+	identityID, err := uuid.FromString(r.PathValue("id"))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithError(err.Error()).WithReason("The identity ID is not a valid UUID.")))
+		return
+	}
+
+	strategy, err := h.r.AllLoginStrategies().Strategy(identity.CredentialsTypeOIDC)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+	s, ok := strategy.(*Strategy)
+	if !ok {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Expected the OpenID Connect strategy but got %T.", strategy)))
+		return
+	}
+
+	token, err := s.upstreamToken(r.Context(), identityID, r.PathValue("provider"))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.Writer().Write(w, r, token)
+}
@@ -0,0 +1,408 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/configx"
+)
+
+func TestProviderHandler(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t,
+		configx.WithValue(config.ViperKeyCipherAlgorithm, "xchacha20-poly1305"),
+		configx.WithValue(config.ViperKeySecretsCipher, []string{"secret-thirty-two-character-long"}),
+	)
+	viperSetProviderConfig(t, conf, oidc.Configuration{ID: "static", Provider: "generic", ClientID: "static", Mapper: "file://./stub/oidc.hydra.jsonnet"})
+	publicTS, adminTS := testhelpers.NewKratosServerWithCSRF(t, reg)
+	conf.MustSet(ctx, config.ViperKeyAdminBaseURL, adminTS.URL)
+
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/openid-configuration" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 issuer.URL,
+			"authorization_endpoint": issuer.URL + "/oauth2/auth",
+			"token_endpoint":         issuer.URL + "/oauth2/token",
+			"jwks_uri":               issuer.URL + "/.well-known/jwks.json",
+		})
+	}))
+	t.Cleanup(issuer.Close)
+
+	mapper := "base64://" + base64.StdEncoding.EncodeToString([]byte(`local claims = std.extVar('claims'); { identity: { traits: { email: claims.email } } }`))
+	newConfig := func(id string) oidc.Configuration {
+		return oidc.Configuration{
+			ID:           id,
+			Provider:     "generic",
+			Label:        "Acme",
+			ClientID:     "client",
+			ClientSecret: "client-secret",
+			IssuerURL:    issuer.URL,
+			Mapper:       mapper,
+			Scope:        []string{"email"},
+		}
+	}
+
+	do := func(t *testing.T, method, path string, body any, expectCode int) gjson.Result {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		req, err := http.NewRequest(method, adminTS.URL+path, &buf)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := adminTS.Client().Do(req)
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+		raw, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equalf(t, expectCode, res.StatusCode, "%s", raw)
+		return gjson.ParseBytes(raw)
+	}
+
+	providerIDs := func(t *testing.T) []string {
+		c, err := oidc.NewStrategy(reg).Config(ctx)
+		require.NoError(t, err)
+		ids := make([]string, len(c.Providers))
+		for k, p := range c.Providers {
+			ids[k] = p.ID
+		}
+		return ids
+	}
+
+	t.Run("case=creates a provider", func(t *testing.T) {
+		res := do(t, "POST", oidc.AdminRouteProviders, newConfig("acme"), http.StatusCreated)
+		assert.Equal(t, "acme", res.Get("id").String())
+		assert.Equal(t, "Acme", res.Get("label").String())
+		assert.Empty(t, res.Get("client_secret").String(), "secrets are never returned")
+		assert.NotEmpty(t, res.Get("created_at").String())
+
+		stored, err := reg.OIDCProviderPersister().GetOIDCProvider(ctx, "acme")
+		require.NoError(t, err)
+		assert.NotContains(t, string(stored.Config), "client-secret", "the secret is encrypted")
+
+		p, err := oidc.NewStrategy(reg).Provider(ctx, "acme")
+		require.NoError(t, err)
+		assert.Equal(t, "client-secret", p.Config().ClientSecret)
+		assert.Equal(t, []string{"static", "acme"}, providerIDs(t))
+	})
+
+	t.Run("case=gets and lists providers", func(t *testing.T) {
+		assert.Equal(t, "acme", do(t, "GET", oidc.AdminRouteProviders+"/acme", nil, http.StatusOK).Get("id").String())
+		do(t, "GET", oidc.AdminRouteProviders+"/unknown", nil, http.StatusNotFound)
+
+		res := do(t, "GET", oidc.AdminRouteProviders, nil, http.StatusOK)
+		assert.Equal(t, []any{"acme"}, res.Get("#.id").Value(), "providers of the configuration file are not listed")
+	})
+
+	t.Run("case=rejects invalid providers", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			modify   func(c *oidc.Configuration)
+			code     int
+			expected string
+		}{
+			{name: "existing provider", code: http.StatusConflict, modify: func(c *oidc.Configuration) {}},
+			{name: "static provider", code: http.StatusConflict, expected: "configuration file", modify: func(c *oidc.Configuration) { c.ID = "static" }},
+			{name: "invalid ID", code: http.StatusBadRequest, expected: "provider ID", modify: func(c *oidc.Configuration) { c.ID = "../acme" }},
+			{name: "unsupported provider", code: http.StatusBadRequest, expected: "not supported", modify: func(c *oidc.Configuration) { c.ID, c.Provider = "unsupported", "unknown" }},
+			{name: "undiscoverable issuer", code: http.StatusBadRequest, expected: "discover", modify: func(c *oidc.Configuration) { c.ID, c.IssuerURL = "undiscoverable", issuer.URL+"/unknown" }},
+			{name: "missing endpoints", code: http.StatusBadRequest, expected: "issuer URL", modify: func(c *oidc.Configuration) { c.ID, c.IssuerURL = "endpoints", "" }},
+			{name: "file mapper", code: http.StatusBadRequest, expected: "mapper URL", modify: func(c *oidc.Configuration) { c.ID, c.Mapper = "file-mapper", "file:///etc/passwd" }},
+			{name: "invalid client assertion key", code: http.StatusBadRequest, expected: "client assertion key", modify: func(c *oidc.Configuration) {
+				c.ID, c.TokenEndpointAuthMethod, c.ClientAssertionKey = "private-key-jwt", oidc.TokenEndpointAuthMethodPrivateKeyJWT, "not a key"
+			}},
+			{name: "invalid mapper", code: http.StatusBadRequest, expected: "compile the mapper", modify: func(c *oidc.Configuration) {
+				c.ID, c.Mapper = "invalid-mapper", "base64://"+base64.StdEncoding.EncodeToString([]byte(`{ identity: claims }`))
+			}},
+		} {
+			t.Run("case="+tc.name, func(t *testing.T) {
+				c := newConfig("acme")
+				tc.modify(&c)
+				res := do(t, "POST", oidc.AdminRouteProviders, c, tc.code)
+				assert.Contains(t, res.Get("error.reason").String(), tc.expected)
+			})
+		}
+		assert.Equal(t, []string{"static", "acme"}, providerIDs(t))
+	})
+
+	t.Run("case=updates a provider", func(t *testing.T) {
+		c := newConfig("")
+		c.Label, c.ClientSecret = "Acme Inc.", ""
+		res := do(t, "PUT", oidc.AdminRouteProviders+"/acme", c, http.StatusOK)
+		assert.Equal(t, "acme", res.Get("id").String())
+		assert.Equal(t, "Acme Inc.", res.Get("label").String())
+
+		p, err := oidc.NewStrategy(reg).Provider(ctx, "acme")
+		require.NoError(t, err)
+		assert.Equal(t, "Acme Inc.", p.Config().Label)
+		assert.Equal(t, "client-secret", p.Config().ClientSecret, "the secret is kept if it is left empty")
+
+		c.ClientSecret = "new-secret"
+		do(t, "PUT", oidc.AdminRouteProviders+"/acme", c, http.StatusOK)
+		p, err = oidc.NewStrategy(reg).Provider(ctx, "acme")
+		require.NoError(t, err)
+		assert.Equal(t, "new-secret", p.Config().ClientSecret)
+
+		do(t, "PUT", oidc.AdminRouteProviders+"/unknown", newConfig("unknown"), http.StatusNotFound)
+		do(t, "PUT", oidc.AdminRouteProviders+"/acme", newConfig("other"), http.StatusBadRequest)
+	})
+
+	t.Run("case=caches the stored providers until they are changed using the admin API", func(t *testing.T) {
+		label := func(t *testing.T) string {
+			p, err := oidc.NewStrategy(reg).Provider(ctx, "acme")
+			require.NoError(t, err)
+			return p.Config().Label
+		}
+		assert.Equal(t, "Acme Inc.", label(t))
+
+		stored, err := reg.OIDCProviderPersister().GetOIDCProvider(ctx, "acme")
+		require.NoError(t, err)
+		stored.Config, err = sjson.SetBytes(stored.Config, "label", "Changed Elsewhere")
+		require.NoError(t, err)
+		require.NoError(t, reg.OIDCProviderPersister().UpdateOIDCProvider(ctx, stored))
+		assert.Equal(t, "Acme Inc.", label(t), "the cached provider is used")
+
+		c := newConfig("")
+		c.Label, c.ClientSecret = "Acme Corp.", ""
+		do(t, "PUT", oidc.AdminRouteProviders+"/acme", c, http.StatusOK)
+		assert.Equal(t, "Acme Corp.", label(t), "writes using the admin API invalidate the cache")
+	})
+
+	t.Run("case=public routes redirect to the admin API", func(t *testing.T) {
+		res, err := publicTS.Client().Get(publicTS.URL + x.AdminPrefix + oidc.AdminRouteProviders + "/acme")
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, adminTS.URL+x.AdminPrefix+oidc.AdminRouteProviders+"/acme", res.Request.URL.String())
+	})
+
+	t.Run("case=deletes a provider", func(t *testing.T) {
+		do(t, "DELETE", oidc.AdminRouteProviders+"/acme", nil, http.StatusNoContent)
+		do(t, "DELETE", oidc.AdminRouteProviders+"/acme", nil, http.StatusNotFound)
+		do(t, "GET", oidc.AdminRouteProviders+"/acme", nil, http.StatusNotFound)
+		assert.Equal(t, []string{"static"}, providerIDs(t))
+	})
+}
+
+func TestIdentityOIDCToken(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t,
+		configx.WithValue(config.ViperKeyCipherAlgorithm, "xchacha20-poly1305"),
+		configx.WithValue(config.ViperKeySecretsCipher, []string{"secret-thirty-two-character-long"}),
+	)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/registration.schema.json")
+	_, adminTS := testhelpers.NewKratosServerWithCSRF(t, reg)
+
+	var (
+		refreshedMu sync.Mutex
+		refreshed   []string
+		onRefresh   func(refreshToken string)
+	)
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"issuer":                 issuer.URL,
+				"authorization_endpoint": issuer.URL + "/oauth2/auth",
+				"token_endpoint":         issuer.URL + "/oauth2/token",
+				"jwks_uri":               issuer.URL + "/.well-known/jwks.json",
+			})
+		case "/oauth2/token":
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+			refreshedMu.Lock()
+			refreshed = append(refreshed, r.PostForm.Get("refresh_token"))
+			refreshedMu.Unlock()
+			if onRefresh != nil {
+				onRefresh(r.PostForm.Get("refresh_token"))
+			}
+			if r.PostForm.Get("refresh_token") == "revoked-refresh-token" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"refreshed-access-token","refresh_token":"rotated-refresh-token","token_type":"bearer","expires_in":3600}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(issuer.Close)
+
+	viperSetProviderConfig(t, conf, oidc.Configuration{ID: "acme", Provider: "generic", ClientID: "client", ClientSecret: "secret", IssuerURL: issuer.URL, Mapper: "file://./stub/oidc.hydra.jsonnet"})
+
+	encrypt := func(t *testing.T, token string) string {
+		ciphertext, err := reg.Cipher(ctx).Encrypt(ctx, []byte(token))
+		require.NoError(t, err)
+		return ciphertext
+	}
+
+	createIdentity := func(t *testing.T, accessToken, refreshToken string, expiresAt time.Time) *identity.Identity {
+		creds, err := identity.NewCredentialsOIDC(&identity.CredentialsOIDCEncryptedTokens{
+			AccessToken:          encrypt(t, accessToken),
+			RefreshToken:         encrypt(t, refreshToken),
+			AccessTokenExpiresAt: expiresAt,
+		}, "acme", x.NewUUID().String(), "")
+		require.NoError(t, err)
+
+		i := &identity.Identity{Traits: []byte(`{"subject":"foo@bar.com"}`), SchemaID: config.DefaultIdentityTraitsSchemaID}
+		i.SetCredentials(identity.CredentialsTypeOIDC, *creds)
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+		return i
+	}
+
+	getToken := func(t *testing.T, id, provider string, expectCode int) gjson.Result {
+		t.Helper()
+		res, err := adminTS.Client().Get(adminTS.URL + "/identities/" + id + "/credentials/oidc/" + provider + "/token")
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+		raw, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equalf(t, expectCode, res.StatusCode, "%s", raw)
+		return gjson.ParseBytes(raw)
+	}
+
+	t.Run("case=returns a valid access token", func(t *testing.T) {
+		refreshed = nil
+		i := createIdentity(t, "access-token", "refresh-token", time.Now().Add(time.Hour))
+		res := getToken(t, i.ID.String(), "acme", http.StatusOK)
+		assert.Equal(t, "access-token", res.Get("access_token").String())
+		assert.Equal(t, "acme", res.Get("provider").String())
+		assert.True(t, res.Get("expires_at").Exists())
+		assert.Empty(t, refreshed)
+	})
+
+	t.Run("case=returns an access token without expiry and refresh token", func(t *testing.T) {
+		refreshed = nil
+		i := createIdentity(t, "access-token", "", time.Time{})
+		res := getToken(t, i.ID.String(), "acme", http.StatusOK)
+		assert.Equal(t, "access-token", res.Get("access_token").String())
+		assert.False(t, res.Get("expires_at").Exists())
+		assert.Empty(t, refreshed)
+	})
+
+	t.Run("case=refreshes an expired access token", func(t *testing.T) {
+		refreshed = nil
+		i := createIdentity(t, "expired-access-token", "refresh-token", time.Now().Add(-time.Minute))
+		res := getToken(t, i.ID.String(), "acme", http.StatusOK)
+		assert.Equal(t, "refreshed-access-token", res.Get("access_token").String())
+		assert.Equal(t, []string{"refresh-token"}, refreshed)
+
+		i, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+		require.NoError(t, err)
+		var stored identity.CredentialsOIDC
+		_, err = i.ParseCredentials(identity.CredentialsTypeOIDC, &stored)
+		require.NoError(t, err)
+		require.Len(t, stored.Providers, 1)
+		assert.NotContains(t, stored.Providers[0].InitialRefreshToken, "rotated-refresh-token", "the tokens are encrypted")
+		refreshToken, err := reg.Cipher(ctx).Decrypt(ctx, stored.Providers[0].InitialRefreshToken)
+		require.NoError(t, err)
+		assert.Equal(t, "rotated-refresh-token", string(refreshToken))
+		assert.WithinDuration(t, time.Now().Add(time.Hour), stored.Providers[0].AccessTokenExpiresAt, time.Minute)
+
+		res = getToken(t, i.ID.String(), "acme", http.StatusOK)
+		assert.Equal(t, "refreshed-access-token", res.Get("access_token").String())
+		assert.Len(t, refreshed, 1, "the refreshed access token is returned until it expires")
+	})
+
+	t.Run("case=refreshes an access token only once for concurrent requests", func(t *testing.T) {
+		refreshed = nil
+		release := make(chan struct{})
+		onRefresh = func(string) { <-release }
+		t.Cleanup(func() { onRefresh = nil })
+
+		i := createIdentity(t, "expired-access-token", "refresh-token", time.Now().Add(-time.Minute))
+
+		var wg sync.WaitGroup
+		tokens := make([]string, 5)
+		for k := range tokens {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				tokens[k] = getToken(t, i.ID.String(), "acme", http.StatusOK).Get("access_token").String()
+			}()
+		}
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, []string{"refreshed-access-token", "refreshed-access-token", "refreshed-access-token", "refreshed-access-token", "refreshed-access-token"}, tokens)
+		assert.Equal(t, []string{"refresh-token"}, refreshed)
+	})
+
+	t.Run("case=returns the access token of a concurrent refresh", func(t *testing.T) {
+		refreshed = nil
+		i := createIdentity(t, "expired-access-token", "refresh-token", time.Now().Add(-time.Minute))
+
+		// Another instance refreshes the credentials while this one does.
+		onRefresh = func(string) {
+			creds, err := identity.NewCredentialsOIDC(&identity.CredentialsOIDCEncryptedTokens{
+				AccessToken:          encrypt(t, "concurrent-access-token"),
+				RefreshToken:         encrypt(t, "concurrent-refresh-token"),
+				AccessTokenExpiresAt: time.Now().Add(time.Hour),
+			}, "acme", x.NewUUID().String(), "")
+			require.NoError(t, err)
+
+			loaded, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, i.ID)
+			require.NoError(t, err)
+			c, ok := loaded.GetCredentials(identity.CredentialsTypeOIDC)
+			require.True(t, ok)
+			c.Config = creds.Config
+			require.NoError(t, reg.PrivilegedIdentityPool().UpdateCredentialsConfig(ctx, c))
+		}
+		t.Cleanup(func() { onRefresh = nil })
+
+		res := getToken(t, i.ID.String(), "acme", http.StatusOK)
+		assert.Equal(t, "concurrent-access-token", res.Get("access_token").String())
+		assert.Equal(t, []string{"refresh-token"}, refreshed)
+	})
+
+	t.Run("case=rejects a revoked grant", func(t *testing.T) {
+		i := createIdentity(t, "expired-access-token", "revoked-refresh-token", time.Now().Add(-time.Minute))
+		res := getToken(t, i.ID.String(), "acme", http.StatusForbidden)
+		assert.Equal(t, text.ErrIDUpstreamGrantRevoked, res.Get("error.id").String())
+	})
+
+	t.Run("case=rejects an expired access token without refresh token", func(t *testing.T) {
+		i := createIdentity(t, "expired-access-token", "", time.Now().Add(-time.Minute))
+		res := getToken(t, i.ID.String(), "acme", http.StatusForbidden)
+		assert.Equal(t, text.ErrIDUpstreamTokenExpired, res.Get("error.id").String())
+	})
+
+	t.Run("case=returns not found", func(t *testing.T) {
+		i := createIdentity(t, "access-token", "refresh-token", time.Now().Add(time.Hour))
+		getToken(t, i.ID.String(), "unknown", http.StatusNotFound)
+		getToken(t, x.NewUUID().String(), "acme", http.StatusNotFound)
+		getToken(t, "not-a-uuid", "acme", http.StatusBadRequest)
+	})
+}
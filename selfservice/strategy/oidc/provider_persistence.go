@@ -0,0 +1,54 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/ory/x/sqlxx"
+)
+
+type (
+	// StoredProvider is an OpenID Connect provider which is managed using the
+	// admin API instead of the configuration file. Secrets in the stored
+	// configuration are encrypted with the cipher.
+	StoredProvider struct {
+		ID  uuid.UUID `json:"-" faker:"-" db:"id"`
+		NID uuid.UUID `json:"-" faker:"-" db:"nid"`
+
+		// ProviderID is the ID of the provider in the configuration.
+		ProviderID string `json:"-" db:"provider_id"`
+
+		// OrganizationID is the organization the provider belongs to, if any.
+		OrganizationID uuid.NullUUID `json:"-" faker:"-" db:"organization_id"`
+
+		// Config is the JSON-encoded Configuration of the provider.
+		Config sqlxx.JSONRawMessage `json:"-" faker:"-" db:"config"`
+
+		CreatedAt time.Time `json:"-" faker:"-" db:"created_at"`
+		UpdatedAt time.Time `json:"-" faker:"-" db:"updated_at"`
+	}
+
+	ProviderPersister interface {
+		CreateOIDCProvider(ctx context.Context, p *StoredProvider) error
+		GetOIDCProvider(ctx context.Context, providerID string) (*StoredProvider, error)
+
+		// ListOIDCProviders returns all stored providers of the network in the
+		// order they were created.
+		ListOIDCProviders(ctx context.Context) ([]StoredProvider, error)
+		UpdateOIDCProvider(ctx context.Context, p *StoredProvider) error
+		DeleteOIDCProvider(ctx context.Context, providerID string) error
+
+		// NetworkID returns the network the providers are stored in.
+		NetworkID(ctx context.Context) uuid.UUID
+	}
+	ProviderPersistenceProvider interface {
+		OIDCProviderPersister() ProviderPersister
+	}
+)
+
+func (StoredProvider) TableName() string { return "selfservice_oidc_providers" }
@@ -0,0 +1,306 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gofrs/uuid"
+	"github.com/google/go-jsonnet"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/cipher"
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/x"
+	"github.com/ory/x/fetcher"
+	"github.com/ory/x/uuidx"
+)
+
+// storedProviderID restricts the IDs of stored providers to characters which
+// can be used in the callback URL without escaping.
+var storedProviderID = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+type storeDependencies interface {
+	config.Provider
+	x.LoggingProvider
+	x.HTTPClientProvider
+	cipher.Provider
+	ProviderPersistenceProvider
+	ProviderCacheProvider
+}
+
+// secrets returns the fields of the configuration which are encrypted when the
+// provider is stored.
+func (p *Configuration) secrets() []*string {
+	return []*string{&p.ClientSecret, &p.PrivateKey, &p.ClientAssertionKey, &p.ClientCertificateKey}
+}
+
+// redacted returns a copy of the configuration without secrets.
+func (p Configuration) redacted() Configuration {
+	for _, s := range p.secrets() {
+		*s = ""
+	}
+	return p
+}
+
+func decodeConfig(raw []byte) (*ConfigurationCollection, error) {
+	var c ConfigurationCollection
+	if err := json.NewDecoder(bytes.NewBuffer(raw)).Decode(&c); err != nil {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Unable to decode OpenID Connect Provider configuration: %s", err))
+	}
+	return &c, nil
+}
+
+// staticConfig returns the providers of the configuration file.
+func staticConfig(ctx context.Context, d config.Provider) (*ConfigurationCollection, error) {
+	return decodeConfig(d.Config().SelfServiceStrategy(ctx, string(identity.CredentialsTypeOIDC)).Config)
+}
+
+// keepSecrets sets the empty secrets of the configuration to the secrets of the
+// existing provider, so that the secrets do not have to be repeated on every
+// update.
+func keepSecrets(ctx context.Context, d storeDependencies, c *Configuration, existing *StoredProvider) error {
+	previous, err := existing.Configuration(ctx, d.Cipher(ctx))
+	if err != nil {
+		return err
+	}
+
+	previousSecrets := previous.secrets()
+	for k, s := range c.secrets() {
+		if *s == "" {
+			*s = *previousSecrets[k]
+		}
+	}
+	return nil
+}
+
+// newStoredProvider encrypts the secrets of the configuration and returns the
+// stored provider. The existing provider, if any, keeps its ID.
+func newStoredProvider(ctx context.Context, d storeDependencies, c Configuration, existing *StoredProvider) (*StoredProvider, error) {
+	sp := &StoredProvider{ID: uuidx.NewV4(), ProviderID: c.ID}
+	if existing != nil {
+		sp.ID, sp.CreatedAt = existing.ID, existing.CreatedAt
+	}
+
+	for _, s := range c.secrets() {
+		if *s == "" {
+			continue
+		}
+
+		encrypted, err := d.Cipher(ctx).Encrypt(ctx, []byte(*s))
+		if err != nil {
+			return nil, err
+		}
+		*s = encrypted
+	}
+
+	if c.OrganizationID != "" {
+		sp.OrganizationID = uuid.NullUUID{UUID: uuid.FromStringOrNil(c.OrganizationID), Valid: true}
+	}
+
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sp.Config = raw
+	return sp, nil
+}
+
+// encryptedConfiguration returns the configuration of the stored provider with
+// encrypted secrets.
+func (sp *StoredProvider) encryptedConfiguration() (Configuration, error) {
+	var c Configuration
+	if err := json.Unmarshal(sp.Config, &c); err != nil {
+		return c, errors.WithStack(err)
+	}
+
+	// The columns take precedence over the encoded configuration.
+	c.ID = sp.ProviderID
+	c.OrganizationID = ""
+	if sp.OrganizationID.Valid {
+		c.OrganizationID = sp.OrganizationID.UUID.String()
+	}
+	return c, nil
+}
+
+// Configuration returns the configuration of the stored provider with
+// decrypted secrets.
+func (sp *StoredProvider) Configuration(ctx context.Context, c cipher.Cipher) (*Configuration, error) {
+	conf, err := sp.encryptedConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range conf.secrets() {
+		plaintext, err := c.Decrypt(ctx, *s)
+		if err != nil {
+			return nil, err
+		}
+		*s = string(plaintext)
+	}
+	return &conf, nil
+}
+
+// Reencrypt encrypts the secrets of the stored provider with the current key
+// of the cipher. It returns false if all secrets were encrypted with the
+// current key already.
+func (sp *StoredProvider) Reencrypt(ctx context.Context, c cipher.Cipher) (bool, error) {
+	var conf Configuration
+	if err := json.Unmarshal(sp.Config, &conf); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	var changed bool
+	for _, s := range conf.secrets() {
+		encrypted, ok, err := cipher.Reencrypt(ctx, c, *s)
+		if err != nil {
+			return false, err
+		}
+		*s = encrypted
+		changed = changed || ok
+	}
+	if !changed {
+		return false, nil
+	}
+
+	raw, err := json.Marshal(conf)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	sp.Config = raw
+	return true, nil
+}
+
+// storedConfigurations returns the providers stored in the database. Providers
+// whose secrets can not be decrypted are skipped, so that they do not break
+// sign-in with all other providers. The providers are cached per network.
+func storedConfigurations(ctx context.Context, d storeDependencies) ([]Configuration, error) {
+	nid := d.OIDCProviderPersister().NetworkID(ctx)
+	cached, generation, ok := d.OIDCProviderCache().get(nid)
+	if ok {
+		return cached, nil
+	}
+
+	stored, err := d.OIDCProviderPersister().ListOIDCProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	providers := make([]Configuration, 0, len(stored))
+	for k := range stored {
+		c, err := stored[k].Configuration(ctx, d.Cipher(ctx))
+		if err != nil {
+			d.Logger().WithError(err).WithField("provider", stored[k].ProviderID).
+				Error("Unable to load the stored OpenID Connect provider, ignoring it.")
+			continue
+		}
+		providers = append(providers, *c)
+	}
+
+	d.OIDCProviderCache().set(nid, generation, providers)
+	return providers, nil
+}
+
+// invalidateStoredConfigurations removes the cached providers of the network,
+// so that changes made using the admin API take effect right away.
+func invalidateStoredConfigurations(ctx context.Context, d storeDependencies) {
+	d.OIDCProviderCache().invalidate(d.OIDCProviderPersister().NetworkID(ctx))
+}
+
+// mergeProviders appends the stored providers to the providers of the
+// configuration file. Providers of the configuration file take precedence.
+func mergeProviders(static, stored []Configuration) []Configuration {
+	merged := slices.Clone(static)
+	for _, p := range stored {
+		if !slices.ContainsFunc(static, func(s Configuration) bool { return s.ID == p.ID }) {
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+// validateConfiguration checks a provider configuration before it is stored.
+// It discovers the issuer and compiles the mapper, so that misconfigured
+// providers are rejected instead of failing during sign-in.
+func validateConfiguration(ctx context.Context, d storeDependencies, c *Configuration) error {
+	if !storedProviderID.MatchString(c.ID) {
+		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("The provider ID %q is invalid. It must consist of 1 to 64 letters, digits, dashes, or underscores.", c.ID))
+	}
+
+	if _, ok := supportedProviders[c.Provider]; !ok {
+		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("The provider type %q is not supported.", c.Provider))
+	}
+
+	if c.ClientID == "" {
+		return errors.WithStack(herodot.ErrBadRequest.WithReason("The client ID must not be empty."))
+	}
+
+	if c.OrganizationID != "" {
+		if _, err := uuid.FromString(c.OrganizationID); err != nil {
+			return errors.WithStack(herodot.ErrBadRequest.WithReasonf("The organization ID %q is not a UUID.", c.OrganizationID))
+		}
+	}
+
+	switch c.PKCE {
+	case "", "auto", "never", "force":
+	default:
+		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("The PKCE mode %q is invalid. It must be one of auto, never, or force.", c.PKCE))
+	}
+
+	switch c.ClaimsSource {
+	case "", ClaimsSourceIDToken, ClaimsSourceUserInfo:
+	default:
+		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("The claims source %q is invalid. It must be one of %s or %s.", c.ClaimsSource, ClaimsSourceIDToken, ClaimsSourceUserInfo))
+	}
+
+	if len(c.RequestedClaims) > 0 && !json.Valid(c.RequestedClaims) {
+		return errors.WithStack(herodot.ErrBadRequest.WithReason("The requested claims must be a JSON object."))
+	}
+
+	if _, err := tokenEndpointContext(ctx, d, c); err != nil {
+		if e := new(herodot.DefaultError); errors.As(err, &e) {
+			return errors.WithStack(herodot.ErrBadRequest.WithReason(e.Reason()))
+		}
+		return err
+	}
+
+	if c.Provider == "generic" && c.IssuerURL == "" && (c.AuthURL == "" || c.TokenURL == "") {
+		return errors.WithStack(herodot.ErrBadRequest.WithReason("Generic providers require either an issuer URL, or an auth URL and a token URL."))
+	}
+
+	if c.IssuerURL != "" {
+		if _, err := gooidc.NewProvider(gooidc.ClientContext(ctx, d.HTTPClient(ctx).HTTPClient), c.IssuerURL); err != nil {
+			return errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to discover the OpenID Connect configuration of issuer %q: %s", c.IssuerURL, err))
+		}
+	}
+
+	return validateMapper(ctx, d, c.Mapper)
+}
+
+// validateMapper fetches and compiles the Jsonnet mapper. Stored providers can
+// not use file:// mappers, because the admin API must not read files of the
+// server.
+func validateMapper(ctx context.Context, d storeDependencies, mapper string) error {
+	if !strings.HasPrefix(mapper, "base64://") && !strings.HasPrefix(mapper, "https://") && !strings.HasPrefix(mapper, "http://") {
+		return errors.WithStack(herodot.ErrBadRequest.WithReason("The mapper URL must be a base64://, http://, or https:// URL."))
+	}
+
+	snippet, err := fetcher.NewFetcher(fetcher.WithClient(d.HTTPClient(ctx))).FetchContext(ctx, mapper)
+	if err != nil {
+		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to fetch the mapper: %s", err))
+	}
+
+	if _, err := jsonnet.SnippetToAST(mapper, snippet.String()); err != nil {
+		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to compile the mapper: %s", err))
+	}
+	return nil
+}
@@ -4,7 +4,6 @@
 package oidc
 
 import (
-	"bytes"
 	"cmp"
 	"context"
 	"encoding/json"
@@ -26,6 +25,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/ory/herodot"
 	"github.com/ory/kratos/cipher"
@@ -86,6 +86,7 @@ type Dependencies interface {
 
 	session.ManagementProvider
 	session.HandlerProvider
+	session.PersistenceProvider
 	sessiontokenexchange.PersistenceProvider
 
 	login.HookExecutorProvider
@@ -111,6 +112,9 @@ type Dependencies interface {
 	cipher.Provider
 
 	jsonnetsecure.VMProvider
+
+	ProviderPersistenceProvider
+	ProviderCacheProvider
 }
 
 func isForced(req interface{}) bool {
@@ -146,8 +150,11 @@ type Strategy struct {
 	credType                    identity.CredentialsType
 	handleUnknownProviderError  func(err error) error
 	handleMethodNotAllowedError func(err error) error
+	providerFactory             func(ctx context.Context, id string) (Provider, error)
 
 	conflictingIdentityPolicy ConflictingIdentityPolicy
+
+	upstreamTokens singleflight.Group
 }
 type ConflictingIdentityPolicy func(ctx context.Context, existingIdentity, newIdentity *identity.Identity, provider Provider, claims *Claims) ConflictingIdentityVerdict
 
@@ -207,6 +214,13 @@ func (s *Strategy) setRoutes(r *x.RouterPublic) {
 		r.GET(RouteCallbackGeneric, wrappedHandleCallback)
 	}
 
+	if !r.HasRoute("POST", RouteBackChannelLogout) {
+		// The provider calls the back-channel logout endpoint directly, without
+		// cookies, so CSRF protection does not apply.
+		s.d.CSRFHandler().IgnoreGlob(RouteBase + "/backchannel-logout/*")
+		r.POST(RouteBackChannelLogout, strategy.IsDisabled(s.d, s.ID().String(), s.handleBackChannelLogout))
+	}
+
 	// Apple can use the POST request method when calling the callback
 	if !r.HasRoute("POST", RouteCallback) {
 		// Apple is the only (known) provider that sometimes does a form POST to the callback URL.
@@ -261,6 +275,13 @@ func WithHandleMethodNotAllowedError(handler func(error) error) NewStrategyOpt {
 	return func(s *Strategy) { s.handleMethodNotAllowedError = handler }
 }
 
+// WithProviderFactory overrides how providers are constructed from their ID.
+// This is used by strategies that reuse this strategy but bring their own
+// provider configuration, such as SAML.
+func WithProviderFactory(factory func(ctx context.Context, id string) (Provider, error)) NewStrategyOpt {
+	return func(s *Strategy) { s.providerFactory = factory }
+}
+
 // WithOnConflictingIdentity sets a policy handler for deciding what to do when a
 // new identity conflicts with an existing one during login.
 func WithOnConflictingIdentity(handler ConflictingIdentityPolicy) NewStrategyOpt {
@@ -510,6 +531,12 @@ func (s *Strategy) HandleCallback(w http.ResponseWriter, r *http.Request) {
 			s.forwardError(ctx, w, r, req, s.HandleError(ctx, w, r, req, state.ProviderId, nil, err))
 			return
 		}
+	case RedirectProvider:
+		claims, err = p.CallbackClaims(ctx, r.URL.Query())
+		if err != nil {
+			s.forwardError(ctx, w, r, req, s.HandleError(ctx, w, r, req, state.ProviderId, nil, err))
+			return
+		}
 	}
 
 	if err = claims.Validate(); err != nil {
@@ -581,8 +608,10 @@ func (s *Strategy) exchangeCode(ctx context.Context, provider OAuth2Provider, co
 		}
 	}
 
-	client := s.d.HTTPClient(ctx)
-	ctx = context.WithValue(ctx, oauth2.HTTPClient, client.HTTPClient)
+	ctx, err = tokenEndpointContext(ctx, s.d, provider.Config())
+	if err != nil {
+		return nil, err
+	}
 	token, err = te.Exchange(ctx, code, opts...)
 	return token, err
 }
@@ -599,30 +628,46 @@ func (s *Strategy) populateMethod(r *http.Request, f flow.Flow, message func(pro
 	return nil
 }
 
+// Config returns the providers of the configuration file. The providers
+// stored using the admin API are appended for the OpenID Connect strategy.
 func (s *Strategy) Config(ctx context.Context) (*ConfigurationCollection, error) {
-	var c ConfigurationCollection
-
 	conf := s.d.Config().SelfServiceStrategy(ctx, string(s.ID())).Config
-	if err := json.
-		NewDecoder(bytes.NewBuffer(conf)).
-		Decode(&c); err != nil {
+	c, err := decodeConfig(conf)
+	if err != nil {
 		s.d.Logger().WithError(err).WithField("config", conf)
-		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Unable to decode OpenID Connect Provider configuration: %s", err))
+		return nil, err
 	}
 
-	return &c, nil
+	if s.ID() != identity.CredentialsTypeOIDC {
+		return c, nil
+	}
+
+	stored, err := storedConfigurations(ctx, s.d)
+	if err != nil {
+		return nil, err
+	}
+	c.Providers = mergeProviders(c.Providers, stored)
+
+	return c, nil
 }
 
 func (s *Strategy) Provider(ctx context.Context, id string) (Provider, error) {
 	if c, err := s.Config(ctx); err != nil {
 		return nil, err
-	} else if provider, err := c.Provider(id, s.d); err != nil {
+	} else if provider, err := s.providerFromConfig(ctx, c, id); err != nil {
 		return nil, s.handleUnknownProviderError(err)
 	} else {
 		return provider, nil
 	}
 }
 
+func (s *Strategy) providerFromConfig(ctx context.Context, c *ConfigurationCollection, id string) (Provider, error) {
+	if s.providerFactory != nil {
+		return s.providerFactory(ctx, id)
+	}
+	return c.Provider(id, s.d)
+}
+
 func (s *Strategy) forwardError(ctx context.Context, w http.ResponseWriter, r *http.Request, f flow.Flow, err error) {
 	switch ff := f.(type) {
 	case *login.Flow:
@@ -641,6 +686,11 @@ func (s *Strategy) forwardError(ctx context.Context, w http.ResponseWriter, r *h
 }
 
 func (s *Strategy) HandleError(ctx context.Context, w http.ResponseWriter, r *http.Request, f flow.Flow, usedProviderID string, traits []byte, err error) error {
+	if errors.Is(err, flow.ErrStrategyNotResponsible) {
+		// Leave the flow untouched so that the next strategy can handle it.
+		return err
+	}
+
 	switch rf := f.(type) {
 	case *login.Flow:
 		return err
@@ -796,6 +846,9 @@ func (s *Strategy) populateAccountLinkingUI(ctx context.Context, lf *login.Flow,
 }
 
 func (s *Strategy) NodeGroup() node.UiNodeGroup {
+	if s.credType == identity.CredentialsTypeSAML {
+		return node.SAMLGroup
+	}
 	return node.OpenIDConnectGroup
 }
 
@@ -867,12 +920,13 @@ func (s *Strategy) linkCredentials(ctx context.Context, i *identity.Identity, to
 	} else {
 		creds.Identifiers = append(creds.Identifiers, identity.OIDCUniqueID(provider, subject))
 		conf.Providers = append(conf.Providers, identity.CredentialsOIDCProvider{
-			Subject:             subject,
-			Provider:            provider,
-			InitialAccessToken:  tokens.GetAccessToken(),
-			InitialRefreshToken: tokens.GetRefreshToken(),
-			InitialIDToken:      tokens.GetIDToken(),
-			Organization:        organization,
+			Subject:              subject,
+			Provider:             provider,
+			InitialAccessToken:   tokens.GetAccessToken(),
+			InitialRefreshToken:  tokens.GetRefreshToken(),
+			InitialIDToken:       tokens.GetIDToken(),
+			Organization:         organization,
+			AccessTokenExpiresAt: tokens.GetAccessTokenExpiresAt(),
 		})
 
 		creds.Config, err = json.Marshal(conf)
@@ -902,6 +956,8 @@ func getAuthRedirectURL(ctx context.Context, provider Provider, req ider, state
 		return c.AuthCodeURL(state, opts...), nil
 	case OAuth1Provider:
 		return p.AuthURL(ctx, state)
+	case RedirectProvider:
+		return p.AuthURL(ctx, state)
 	default:
 		return "", errors.WithStack(herodot.ErrInternalServerError.WithReasonf("The provider %s does not support the OAuth 2.0 or OAuth 1.0 protocol", provider.Config().Provider))
 	}
@@ -930,5 +986,9 @@ func (s *Strategy) encryptOAuth2Tokens(ctx context.Context, token *oauth2.Token)
 		return nil, err
 	}
 
+	if !token.Expiry.IsZero() {
+		et.AccessTokenExpiresAt = token.Expiry.UTC()
+	}
+
 	return et, nil
 }
@@ -0,0 +1,226 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/session"
+	"github.com/ory/x/otelx"
+	"github.com/ory/x/sqlcon"
+)
+
+const (
+	RouteBackChannelLogout = RouteBase + "/backchannel-logout/{provider}"
+
+	backChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+)
+
+// OpenID Connect Back-Channel Logout Parameters
+//
+// swagger:parameters performOidcBackChannelLogout
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type performOIDCBackChannelLogout struct {
+	// Provider is the ID of the OpenID Connect provider.
+	//
+	// required: true
+	// in: path
+	Provider string `json:"provider"`
+
+	// The logout token issued by the provider.
+	//
+	// required: true
+	// in: formData
+	LogoutToken string `json:"logout_token"`
+}
+
+// swagger:route POST /self-service/methods/oidc/backchannel-logout/{provider} frontend performOidcBackChannelLogout
+//
+// # OpenID Connect Back-Channel Logout
+//
+// Receives OpenID Connect Back-Channel Logout tokens of an OpenID Connect
+// provider. The sessions of the identity which were created by signing in with
+// the provider are revoked. If the logout token contains a `sid` claim, only
+// the sessions of that provider session, and sessions for which no provider
+// session was recorded, are revoked.
+//
+// Logout tokens must contain a `sub` claim, a `sid` claim, or both. If the
+// logout token only contains a `sid` claim, the sessions of that provider
+// session are revoked.
+//
+//	Consumes:
+//	- application/x-www-form-urlencoded
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: emptyResponse
+//	  400: errorGeneric
+//	  default: errorGeneric
+func (s *Strategy) handleBackChannelLogout(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := r.ParseForm(); err != nil {
+		s.d.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithError(err.Error()).WithReason("Unable to parse the logout request.")))
+		return
+	}
+
+	if err := s.backChannelLogout(r.Context(), r.PathValue("provider"), r.PostForm.Get("logout_token")); err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// backChannelLogout validates the logout token as described in Section 2.6
+// of OpenID Connect Back-Channel Logout 1.0 and revokes the matching sessions.
+// Replayed logout tokens are harmless, as revoking a session is idempotent.
+func (s *Strategy) backChannelLogout(ctx context.Context, providerID, rawLogoutToken string) (err error) {
+	ctx, span := s.d.Tracer(ctx).Tracer().Start(ctx, "strategy.oidc.backChannelLogout", trace.WithAttributes(
+		attribute.String("provider_id", providerID)))
+	defer otelx.End(span, &err)
+
+	if rawLogoutToken == "" {
+		return errors.WithStack(herodot.ErrBadRequest.WithReason("The logout_token parameter is missing."))
+	}
+
+	provider, err := s.Provider(ctx, providerID)
+	if err != nil {
+		return err
+	}
+
+	verifier, ok := provider.(LogoutTokenVerifier)
+	if !ok {
+		return errors.WithStack(herodot.ErrBadRequest.WithReasonf("The OpenID Connect provider %q does not support back-channel logout.", providerID))
+	}
+
+	claims, err := verifier.VerifyLogoutToken(ctx, rawLogoutToken)
+	if err != nil {
+		return err
+	}
+
+	events, _ := claims.RawClaims["events"].(map[string]any)
+	if _, ok := events[backChannelLogoutEvent].(map[string]any); !ok {
+		return errors.WithStack(herodot.ErrBadRequest.WithReason("The logout token does not contain the back-channel logout event."))
+	}
+	if _, ok := claims.RawClaims["nonce"]; ok {
+		return errors.WithStack(herodot.ErrBadRequest.WithReason("The logout token must not contain a nonce."))
+	}
+	if claims.Subject == "" && claims.sessionID() == "" {
+		return errors.WithStack(herodot.ErrBadRequest.WithReason("The logout token contains neither a subject nor a session ID."))
+	} else if claims.Subject == "" {
+		return s.backChannelLogoutProviderSession(ctx, providerID, claims.sessionID())
+	}
+
+	i, _, err := s.d.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, s.ID(), identity.OIDCUniqueID(providerID, claims.Subject))
+	if errors.Is(err, sqlcon.ErrNoRows) {
+		// The subject never signed in, so there is nothing to revoke.
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	sessionIDs, err := s.backChannelLogoutSessions(ctx, i.ID, providerID, claims.sessionID())
+	if err != nil {
+		return err
+	}
+
+	for _, id := range sessionIDs {
+		if err := s.d.SessionPersister().RevokeSession(ctx, i.ID, id); err != nil {
+			return err
+		}
+	}
+
+	s.d.Audit().
+		WithField("identity_id", i.ID).
+		WithField("provider", providerID).
+		WithField("revoked_sessions", len(sessionIDs)).
+		Info("Revoked the sessions of an identity after an OpenID Connect back-channel logout.")
+
+	return nil
+}
+
+// backChannelLogoutProviderSession revokes the active sessions which were
+// authenticated with the provider session, for logout tokens which do not
+// identify the subject.
+func (s *Strategy) backChannelLogoutProviderSession(ctx context.Context, providerID, providerSessionID string) error {
+	sessions, err := s.d.SessionPersister().ListActiveSessionsByProviderSessionID(ctx, providerSessionID)
+	if err != nil {
+		return err
+	}
+
+	var revoked int
+	for _, sess := range sessions {
+		// Session IDs are only unique per provider.
+		if !slices.ContainsFunc(sess.AMR, func(m session.AuthenticationMethod) bool {
+			return m.Method == s.ID() && m.Provider == providerID && m.ProviderSessionID == providerSessionID
+		}) {
+			continue
+		}
+
+		if err := s.d.SessionPersister().RevokeSession(ctx, sess.IdentityID, sess.ID); err != nil {
+			return err
+		}
+		revoked++
+
+		s.d.Audit().
+			WithField("identity_id", sess.IdentityID).
+			WithField("provider", providerID).
+			WithField("session_id", sess.ID).
+			Info("Revoked a session after an OpenID Connect back-channel logout of the provider session.")
+	}
+
+	if revoked == 0 {
+		s.d.Audit().
+			WithField("provider", providerID).
+			Info("Received an OpenID Connect back-channel logout for a provider session without active sessions.")
+	}
+	return nil
+}
+
+// backChannelLogoutSessions returns the IDs of the active sessions of the
+// identity which were authenticated with the provider and, if the provider sent
+// a session ID, with that provider session. Sessions without a recorded
+// provider session, such as sessions issued after registration, are included.
+func (s *Strategy) backChannelLogoutSessions(ctx context.Context, identityID uuid.UUID, providerID, providerSessionID string) ([]uuid.UUID, error) {
+	const perPage = 500
+	active := true
+
+	var ids []uuid.UUID
+	for page := 1; ; page++ {
+		sessions, _, err := s.d.SessionPersister().ListSessionsByIdentity(ctx, identityID, &active, page, perPage, uuid.Nil, session.ExpandNothing)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sess := range sessions {
+			for _, m := range sess.AMR {
+				if m.Method != s.ID() || m.Provider != providerID {
+					continue
+				}
+				if providerSessionID != "" && m.ProviderSessionID != "" && m.ProviderSessionID != providerSessionID {
+					continue
+				}
+				ids = append(ids, sess.ID)
+				break
+			}
+		}
+
+		if len(sessions) < perPage {
+			return ids, nil
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rakutentech/jwk-go/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/x"
+)
+
+func TestBackChannelLogout(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/registration.schema.json")
+	publicTS, _ := testhelpers.NewKratosServerWithCSRF(t, reg)
+
+	var issuer *httptest.Server
+	var discoveryRequests, jwksRequests atomic.Int32
+	issuer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			discoveryRequests.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"issuer":                 issuer.URL,
+				"authorization_endpoint": issuer.URL + "/oauth2/auth",
+				"token_endpoint":         issuer.URL + "/oauth2/token",
+				"jwks_uri":               issuer.URL + "/.well-known/jwks.json",
+			})
+		case "/.well-known/jwks.json":
+			jwksRequests.Add(1)
+			_, _ = w.Write(publicJWKS)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(issuer.Close)
+
+	viperSetProviderConfig(t, conf,
+		oidc.Configuration{ID: "acme", Provider: "generic", ClientID: "client", IssuerURL: issuer.URL, Mapper: "file://./stub/oidc.hydra.jsonnet"},
+		oidc.Configuration{ID: "other", Provider: "generic", ClientID: "client", IssuerURL: issuer.URL, Mapper: "file://./stub/oidc.hydra.jsonnet"},
+	)
+
+	logoutToken := func(t *testing.T, modify func(c jwt.MapClaims)) string {
+		key := &jwk.KeySpec{}
+		require.NoError(t, json.Unmarshal(rawKey, key))
+		c := jwt.MapClaims{
+			"iss":    issuer.URL,
+			"aud":    "client",
+			"sub":    "subject",
+			"sid":    "upstream-session",
+			"iat":    time.Now().Unix(),
+			"exp":    time.Now().Add(time.Minute).Unix(),
+			"jti":    x.NewUUID().String(),
+			"events": map[string]any{"http://schemas.openid.net/event/backchannel-logout": map[string]any{}},
+		}
+		if modify != nil {
+			modify(c)
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+		token.Header["kid"] = key.KeyID
+		s, err := token.SignedString(key.Key)
+		require.NoError(t, err)
+		return s
+	}
+
+	logout := func(t *testing.T, provider, token string, expectCode int) gjson.Result {
+		t.Helper()
+		res, err := publicTS.Client().PostForm(publicTS.URL+"/self-service/methods/oidc/backchannel-logout/"+provider, url.Values{"logout_token": {token}})
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+		var body json.RawMessage
+		_ = json.NewDecoder(res.Body).Decode(&body)
+		require.Equalf(t, expectCode, res.StatusCode, "%s", body)
+		assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+		return gjson.ParseBytes(body)
+	}
+
+	creds, err := identity.NewCredentialsOIDC(new(identity.CredentialsOIDCEncryptedTokens), "acme", "subject", "")
+	require.NoError(t, err)
+	i := identity.NewIdentity("default")
+	i.Traits = identity.Traits(`{"subject":"foo@bar.com"}`)
+	i.SetCredentials(identity.CredentialsTypeOIDC, *creds)
+	require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, i))
+
+	newSession := func(t *testing.T, methods ...session.AuthenticationMethod) *session.Session {
+		s, err := testhelpers.NewActiveSession(httptest.NewRequest("GET", "/", nil), reg, i, time.Now(), identity.CredentialsTypePassword, identity.AuthenticatorAssuranceLevel1)
+		require.NoError(t, err)
+		s.AMR = session.AuthenticationMethods{}
+		for _, m := range methods {
+			s.CompletedLoginForMethod(m)
+		}
+		require.NoError(t, reg.SessionPersister().UpsertSession(ctx, s))
+		return s
+	}
+
+	isActive := func(t *testing.T, s *session.Session) bool {
+		actual, err := reg.SessionPersister().GetSession(ctx, s.ID, session.ExpandNothing)
+		require.NoError(t, err)
+		return actual.IsActive()
+	}
+
+	t.Run("case=revokes the sessions of the provider session", func(t *testing.T) {
+		sameSID := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "acme", ProviderSessionID: "upstream-session"})
+		noSID := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "acme"})
+		otherSID := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "acme", ProviderSessionID: "other-session"})
+		otherProvider := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "other", ProviderSessionID: "upstream-session"})
+		password := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypePassword})
+
+		logout(t, "acme", logoutToken(t, nil), http.StatusOK)
+
+		assert.False(t, isActive(t, sameSID))
+		assert.False(t, isActive(t, noSID), "sessions without a recorded provider session are revoked")
+		assert.True(t, isActive(t, otherSID))
+		assert.True(t, isActive(t, otherProvider))
+		assert.True(t, isActive(t, password))
+	})
+
+	t.Run("case=revokes all sessions of the provider without sid", func(t *testing.T) {
+		s1 := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "acme", ProviderSessionID: "one"})
+		s2 := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypePassword}, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "acme", ProviderSessionID: "two"})
+
+		logout(t, "acme", logoutToken(t, func(c jwt.MapClaims) { delete(c, "sid") }), http.StatusOK)
+
+		assert.False(t, isActive(t, s1))
+		assert.False(t, isActive(t, s2))
+	})
+
+	t.Run("case=revokes the sessions of the provider session without subject", func(t *testing.T) {
+		sameSID := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "acme", ProviderSessionID: "sid-only-session"})
+		noSID := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "acme"})
+		otherProvider := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "other", ProviderSessionID: "sid-only-session"})
+
+		logout(t, "acme", logoutToken(t, func(c jwt.MapClaims) {
+			delete(c, "sub")
+			c["sid"] = "sid-only-session"
+		}), http.StatusOK)
+
+		assert.False(t, isActive(t, sameSID))
+		assert.True(t, isActive(t, noSID), "sessions without a recorded provider session can not be matched without subject")
+		assert.True(t, isActive(t, otherProvider), "session IDs are only unique per provider")
+
+		logout(t, "acme", logoutToken(t, func(c jwt.MapClaims) {
+			delete(c, "sub")
+			c["sid"] = "unknown-session"
+		}), http.StatusOK)
+		assert.True(t, isActive(t, noSID))
+	})
+
+	t.Run("case=ignores unknown subjects", func(t *testing.T) {
+		s := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "acme"})
+		logout(t, "acme", logoutToken(t, func(c jwt.MapClaims) { c["sub"] = "unknown" }), http.StatusOK)
+		assert.True(t, isActive(t, s))
+	})
+
+	t.Run("case=does not fetch the keys again for every logout token", func(t *testing.T) {
+		logout(t, "acme", logoutToken(t, func(c jwt.MapClaims) { c["sub"] = "unknown" }), http.StatusOK)
+		discovered, fetched := discoveryRequests.Load(), jwksRequests.Load()
+		require.NotZero(t, discovered)
+		require.NotZero(t, fetched)
+
+		logout(t, "acme", logoutToken(t, func(c jwt.MapClaims) { c["sub"] = "unknown" }), http.StatusOK)
+		assert.Equal(t, discovered, discoveryRequests.Load())
+		assert.Equal(t, fetched, jwksRequests.Load())
+	})
+
+	t.Run("case=rejects invalid logout tokens", func(t *testing.T) {
+		s := newSession(t, session.AuthenticationMethod{Method: identity.CredentialsTypeOIDC, Provider: "acme"})
+
+		for name, token := range map[string]string{
+			"missing token":                  "",
+			"malformed token":                "not-a-jwt",
+			"missing event":                  logoutToken(t, func(c jwt.MapClaims) { delete(c, "events") }),
+			"nonce":                          logoutToken(t, func(c jwt.MapClaims) { c["nonce"] = "nonce" }),
+			"missing subject and session ID": logoutToken(t, func(c jwt.MapClaims) { delete(c, "sub"); delete(c, "sid") }),
+			"wrong audience":                 logoutToken(t, func(c jwt.MapClaims) { c["aud"] = "other-client" }),
+			"wrong issuer":                   logoutToken(t, func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }),
+			"expired":                        logoutToken(t, func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }),
+		} {
+			t.Run("case="+name, func(t *testing.T) {
+				logout(t, "acme", token, http.StatusBadRequest)
+			})
+		}
+
+		logout(t, "unknown", logoutToken(t, nil), http.StatusNotFound)
+		assert.True(t, isActive(t, s))
+	})
+}
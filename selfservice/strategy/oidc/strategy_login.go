@@ -11,11 +11,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/ory/herodot"
 	"github.com/ory/kratos/continuity"
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/selfservice/flow"
 	"github.com/ory/kratos/selfservice/flow/login"
@@ -33,8 +35,9 @@ import (
 )
 
 var (
-	_ login.FormHydrator = new(Strategy)
-	_ login.Strategy     = new(Strategy)
+	_ login.FormHydrator       = new(Strategy)
+	_ login.Strategy           = new(Strategy)
+	_ idfirst.HomeRealmLoginer = new(Strategy)
 )
 
 func (s *Strategy) RegisterLoginRoutes(r *x.RouterPublic) {
@@ -244,11 +247,17 @@ func (s *Strategy) ProcessLogin(ctx context.Context, w http.ResponseWriter, r *h
 	}
 
 	sess := session.NewInactiveSession()
-	sess.CompletedLoginForWithProvider(s.ID(), identity.AuthenticatorAssuranceLevel1, provider.Config().ID, provider.Config().OrganizationID)
+	sess.CompletedLoginForMethod(session.AuthenticationMethod{
+		Method:            s.ID(),
+		AAL:               identity.AuthenticatorAssuranceLevel1,
+		Provider:          provider.Config().ID,
+		Organization:      provider.Config().OrganizationID,
+		ProviderSessionID: claims.sessionID(),
+	})
 
 	for _, c := range oidcCredentials.Providers {
 		if c.Subject == claims.Subject && c.Provider == provider.Config().ID {
-			if err = s.d.LoginHookExecutor().PostLoginHook(w, r, node.OpenIDConnectGroup, loginFlow, i, sess, provider.Config().ID); err != nil {
+			if err = s.d.LoginHookExecutor().PostLoginHook(w, r, s.NodeGroup(), loginFlow, i, sess, provider.Config().ID); err != nil {
 				return nil, x.WrapWithIdentityIDError(s.HandleError(ctx, w, r, loginFlow, provider.Config().ID, nil, err), i.ID)
 			}
 			return nil, nil
@@ -328,35 +337,45 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 		return nil, errors.WithStack(flow.ErrCompletedByStrategy)
 	}
 
+	var up map[string]string
+	if err := json.NewDecoder(bytes.NewBuffer(p.UpstreamParameters)).Decode(&up); err != nil {
+		return nil, err
+	}
+
+	if err := s.redirectToProvider(ctx, w, r, f, provider, p.Traits, up); err != nil {
+		return nil, s.HandleError(ctx, w, r, f, pid, nil, err)
+	}
+
+	return nil, errors.WithStack(flow.ErrCompletedByStrategy)
+}
+
+// redirectToProvider pauses the login flow and redirects the browser to the
+// authorization URL of the provider.
+func (s *Strategy) redirectToProvider(ctx context.Context, w http.ResponseWriter, r *http.Request, f *login.Flow, provider Provider, traits json.RawMessage, upstreamParameters map[string]string) error {
 	state, pkce, err := s.GenerateState(ctx, provider, f.ID)
 	if err != nil {
-		return nil, s.HandleError(ctx, w, r, f, pid, nil, err)
+		return err
 	}
 	if err := s.d.ContinuityManager().Pause(ctx, w, r, sessionName,
 		continuity.WithPayload(&AuthCodeContainer{
 			State:            state,
 			FlowID:           f.ID.String(),
-			Traits:           p.Traits,
+			Traits:           traits,
 			TransientPayload: f.TransientPayload,
 			IdentitySchema:   f.IdentitySchema,
 		}),
 		continuity.WithLifespan(time.Minute*30)); err != nil {
-		return nil, s.HandleError(ctx, w, r, f, pid, nil, err)
+		return err
 	}
 
 	f.Active = s.ID()
-	if err = s.d.LoginFlowPersister().UpdateLoginFlow(ctx, f); err != nil {
-		return nil, s.HandleError(ctx, w, r, f, pid, nil, errors.WithStack(herodot.ErrInternalServerError.WithReason("Could not update flow").WithWrap(err)))
-	}
-
-	var up map[string]string
-	if err := json.NewDecoder(bytes.NewBuffer(p.UpstreamParameters)).Decode(&up); err != nil {
-		return nil, err
+	if err := s.d.LoginFlowPersister().UpdateLoginFlow(ctx, f); err != nil {
+		return errors.WithStack(herodot.ErrInternalServerError.WithReason("Could not update flow").WithWrap(err))
 	}
 
-	codeURL, err := getAuthRedirectURL(ctx, provider, f, state, up, pkce)
+	codeURL, err := getAuthRedirectURL(ctx, provider, f, state, upstreamParameters, pkce)
 	if err != nil {
-		return nil, s.HandleError(ctx, w, r, f, pid, nil, err)
+		return err
 	}
 
 	if x.IsJSONRequest(r) {
@@ -364,8 +383,7 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 	} else {
 		http.Redirect(w, r, codeURL, http.StatusSeeOther)
 	}
-
-	return nil, errors.WithStack(flow.ErrCompletedByStrategy)
+	return nil
 }
 
 func (s *Strategy) PopulateLoginMethodFirstFactorRefresh(r *http.Request, lf *login.Flow, _ *session.Session) error {
@@ -451,7 +469,7 @@ func (s *Strategy) PopulateLoginMethodIdentifierFirstCredentials(r *http.Request
 	if o.IdentityHint != nil {
 		var err error
 		// If we have an identity hint we check if the identity has any providers configured.
-		if linked, err = s.linkedProviders(conf, o.IdentityHint); err != nil {
+		if linked, err = s.linkedProviders(ctx, conf, o.IdentityHint); err != nil {
 			return err
 		}
 	}
@@ -495,3 +513,50 @@ func (s *Strategy) PopulateLoginMethodIdentifierFirstCredentials(r *http.Request
 func (s *Strategy) PopulateLoginMethodIdentifierFirstIdentification(r *http.Request, f *login.Flow) error {
 	return s.populateMethod(r, f, text.NewInfoLoginWith)
 }
+
+// LoginWithHomeRealm redirects the browser to the provider of the home realm.
+// If the realm is an organization with several providers, the flow shows the
+// providers of the organization instead.
+func (s *Strategy) LoginWithHomeRealm(w http.ResponseWriter, r *http.Request, f *login.Flow, realm *config.HomeRealm, identifier string) (err error) {
+	ctx, span := s.d.Tracer(r.Context()).Tracer().Start(r.Context(), "selfservice.strategy.oidc.Strategy.LoginWithHomeRealm")
+	defer otelx.End(span, &err)
+
+	if s.ID() != identity.CredentialsTypeOIDC {
+		return errors.WithStack(flow.ErrStrategyNotResponsible)
+	}
+
+	conf, err := s.Config(ctx)
+	if err != nil {
+		return err
+	}
+
+	var providers []Configuration
+	for _, p := range conf.Providers {
+		if (realm.Provider != "" && p.ID == realm.Provider) || (realm.Organization != "" && p.OrganizationID == realm.Organization) {
+			providers = append(providers, p)
+		}
+	}
+
+	switch len(providers) {
+	case 0:
+		return errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("No OpenID Connect provider is configured for the home realm of %s.", config.EmailDomain(identifier)))
+	case 1:
+		provider, err := s.providerFromConfig(ctx, conf, providers[0].ID)
+		if err != nil {
+			return s.handleUnknownProviderError(err)
+		}
+
+		span.SetAttributes(attribute.String("provider", providers[0].ID))
+		if err := s.redirectToProvider(ctx, w, r, f, provider, nil, map[string]string{"login_hint": identifier}); err != nil {
+			return err
+		}
+		return errors.WithStack(flow.ErrCompletedByStrategy)
+	}
+
+	f.OrganizationID = uuid.NullUUID{UUID: uuid.FromStringOrNil(realm.Organization), Valid: true}
+	s.removeProviders(conf, f)
+	for _, p := range providers {
+		AddProvider(f.UI, p.ID, text.NewInfoLoginWith(stringsx.Coalesce(p.Label, p.ID), p.ID), s.ID())
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ package oidc
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	_ "embed"
 	"encoding/json"
@@ -30,6 +31,7 @@ import (
 	"github.com/ory/kratos/selfservice/flow/settings"
 	"github.com/ory/kratos/selfservice/strategy"
 	"github.com/ory/kratos/session"
+	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
 )
 
@@ -86,7 +88,7 @@ func (s *Strategy) decoderSettings(ctx context.Context, p *updateSettingsFlowWit
 	return nil
 }
 
-func (s *Strategy) linkedProviders(conf *ConfigurationCollection, confidential *identity.Identity) ([]Provider, error) {
+func (s *Strategy) linkedProviders(ctx context.Context, conf *ConfigurationCollection, confidential *identity.Identity) ([]Provider, error) {
 	creds, ok := confidential.GetCredentials(s.ID())
 	if !ok {
 		return nil, nil
@@ -99,7 +101,7 @@ func (s *Strategy) linkedProviders(conf *ConfigurationCollection, confidential *
 
 	var result []Provider
 	for _, p := range available.Providers {
-		prov, err := conf.Provider(p.Provider, s.d)
+		prov, err := s.providerFromConfig(ctx, conf, p.Provider)
 		if errors.Is(err, herodot.ErrNotFound) {
 			continue
 		} else if err != nil {
@@ -111,7 +113,7 @@ func (s *Strategy) linkedProviders(conf *ConfigurationCollection, confidential *
 	return result, nil
 }
 
-func (s *Strategy) linkableProviders(conf *ConfigurationCollection, confidential *identity.Identity) ([]Provider, error) {
+func (s *Strategy) linkableProviders(ctx context.Context, conf *ConfigurationCollection, confidential *identity.Identity) ([]Provider, error) {
 	var available identity.CredentialsOIDC
 	creds, ok := confidential.GetCredentials(s.ID())
 	if ok {
@@ -131,7 +133,7 @@ func (s *Strategy) linkableProviders(conf *ConfigurationCollection, confidential
 		}
 
 		if !found {
-			prov, err := conf.Provider(p.ID, s.d)
+			prov, err := s.providerFromConfig(ctx, conf, p.ID)
 			if err != nil {
 				return nil, err
 			}
@@ -155,24 +157,26 @@ func (s *Strategy) PopulateSettingsMethod(ctx context.Context, r *http.Request,
 		return err
 	}
 
-	linkable, err := s.linkableProviders(conf, id)
+	linkable, err := s.linkableProviders(ctx, conf, id)
 	if err != nil {
 		return err
 	}
 
-	linked, err := s.linkedProviders(conf, id)
+	linked, err := s.linkedProviders(ctx, conf, id)
 	if err != nil {
 		return err
 	}
 
-	sr.UI.GetNodes().Remove("unlink", "link")
+	for _, name := range []string{"unlink", "link"} {
+		sr.UI.GetNodes().RemoveMatching(&node.Node{Group: s.NodeGroup(), Attributes: &node.InputAttributes{Name: name}})
+	}
 	sr.UI.SetCSRF(s.d.GenerateCSRFToken(r))
 	for _, l := range linkable {
 		// We do not want to offer to link SSO providers in the settings.
 		if l.Config().OrganizationID != "" {
 			continue
 		}
-		sr.UI.GetNodes().Append(NewLinkNode(l.Config().ID, stringsx.Coalesce(l.Config().Label, l.Config().ID)))
+		sr.UI.GetNodes().Append(newLinkNode(s.NodeGroup(), l.Config().ID, stringsx.Coalesce(l.Config().Label, l.Config().ID)))
 	}
 
 	count, err := s.d.IdentityManager().CountActiveFirstFactorCredentials(ctx, id)
@@ -184,7 +188,7 @@ func (s *Strategy) PopulateSettingsMethod(ctx context.Context, r *http.Request,
 		// This means that we're able to remove a connection because it is the last configured credential. If it is
 		// removed, the identity is no longer able to sign in.
 		for _, l := range linked {
-			sr.UI.GetNodes().Append(NewUnlinkNode(l.Config().ID, stringsx.Coalesce(l.Config().Label, l.Config().ID)))
+			sr.UI.GetNodes().Append(newUnlinkNode(s.NodeGroup(), l.Config().ID, stringsx.Coalesce(l.Config().Label, l.Config().ID)))
 		}
 	}
 
@@ -266,6 +270,14 @@ func (s *Strategy) Settings(ctx context.Context, w http.ResponseWriter, r *http.
 	}
 	f.TransientPayload = p.TransientPayload
 
+	if target := cmp.Or(p.Link, p.Unlink); len(target) > 0 {
+		// Strategies sharing this implementation only handle their own providers.
+		if _, err := s.Provider(ctx, target); errors.Is(err, flow.ErrStrategyNotResponsible) {
+			span.SetAttributes(attribute.String("not_responsible_reason", "provider is handled by another strategy"))
+			return nil, err
+		}
+	}
+
 	ctxUpdate, err := settings.PrepareUpdate(s.d, w, r, f, ss, settings.ContinuityKey(s.SettingsStrategyID()), &p)
 	if errors.Is(err, settings.ErrContinuePreviousAction) {
 		if !s.d.Config().SelfServiceStrategy(ctx, s.SettingsStrategyID()).Enabled {
@@ -332,7 +344,7 @@ func (s *Strategy) isLinkable(ctx context.Context, ctxUpdate *settings.UpdateCon
 		return nil, err
 	}
 
-	linkable, err := s.linkableProviders(providers, i)
+	linkable, err := s.linkableProviders(ctx, providers, i)
 	if err != nil {
 		return nil, err
 	}
@@ -460,7 +472,7 @@ func (s *Strategy) unlinkProvider(ctx context.Context, w http.ResponseWriter, r
 		return s.handleSettingsError(ctx, w, r, ctxUpdate, p, err)
 	}
 
-	availableProviders, err := s.linkedProviders(providers, i)
+	availableProviders, err := s.linkedProviders(ctx, providers, i)
 	if err != nil {
 		return s.handleSettingsError(ctx, w, r, ctxUpdate, p, err)
 	}
@@ -0,0 +1,218 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/x/otelx"
+)
+
+// upstreamTokenExpiryLeeway is the remaining lifetime below which a stored
+// access token is refreshed before it is returned.
+const upstreamTokenExpiryLeeway = time.Minute
+
+// Upstream Access Token
+//
+// A valid access token of an OpenID Connect provider, which can be used to
+// call the APIs of the provider on behalf of the identity.
+//
+// swagger:model oidcUpstreamToken
+type UpstreamToken struct {
+	// The ID of the OpenID Connect provider.
+	//
+	// required: true
+	Provider string `json:"provider"`
+
+	// The subject of the identity at the provider.
+	//
+	// required: true
+	Subject string `json:"subject"`
+
+	// The access token of the provider.
+	//
+	// required: true
+	AccessToken string `json:"access_token"`
+
+	// The expiry of the access token. It is omitted if the provider did not
+	// return one.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// upstreamToken returns a valid access token of the provider for the identity.
+// Expired access tokens, and access tokens without a known expiry, are
+// refreshed using the stored refresh token. The refreshed tokens are encrypted
+// and stored in the credentials of the identity.
+//
+// Providers which rotate refresh tokens reject a refresh token which was used
+// before, so the credentials are refreshed only once at a time: concurrent
+// requests of this instance share the refresh, and if another instance
+// refreshed the credentials concurrently, its access token is returned.
+func (s *Strategy) upstreamToken(ctx context.Context, identityID uuid.UUID, providerID string) (_ *UpstreamToken, err error) {
+	ctx, span := s.d.Tracer(ctx).Tracer().Start(ctx, "strategy.oidc.upstreamToken", trace.WithAttributes(
+		attribute.Stringer("identity_id", identityID),
+		attribute.String("provider_id", providerID)))
+	defer otelx.End(span, &err)
+
+	token, err, _ := s.upstreamTokens.Do(identityID.String()+"/"+providerID, func() (any, error) {
+		// The refresh must not be canceled if the request which started it
+		// is, because it is shared with other requests.
+		ctx := context.WithoutCancel(ctx)
+
+		token, err := s.refreshedUpstreamToken(ctx, identityID, providerID)
+		if errors.Is(err, herodot.ErrConflict) {
+			// The credentials were refreshed concurrently, so the reloaded
+			// access token is valid.
+			return s.refreshedUpstreamToken(ctx, identityID, providerID)
+		}
+		return token, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return token.(*UpstreamToken), nil
+}
+
+// refreshedUpstreamToken loads the access token of the provider for the
+// identity and refreshes it if necessary. It returns herodot.ErrConflict if
+// the credentials were updated while the access token was refreshed.
+func (s *Strategy) refreshedUpstreamToken(ctx context.Context, identityID uuid.UUID, providerID string) (*UpstreamToken, error) {
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, identityID)
+	if err != nil {
+		return nil, err
+	}
+
+	var conf identity.CredentialsOIDC
+	creds, err := i.ParseCredentials(s.ID(), &conf)
+	if errors.Is(err, herodot.ErrNotFound) {
+		return nil, errors.WithStack(herodot.ErrNotFound.WithReasonf("The identity has no credentials of OpenID Connect provider %q.", providerID))
+	} else if err != nil {
+		return nil, err
+	}
+
+	k := -1
+	for j, p := range conf.Providers {
+		if p.Provider == providerID {
+			k = j
+			break
+		}
+	}
+	if k < 0 {
+		return nil, errors.WithStack(herodot.ErrNotFound.WithReasonf("The identity has no credentials of OpenID Connect provider %q.", providerID))
+	}
+	stored := &conf.Providers[k]
+
+	accessToken, err := s.d.Cipher(ctx).Decrypt(ctx, stored.InitialAccessToken)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.d.Cipher(ctx).Decrypt(ctx, stored.InitialRefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := stored.AccessTokenExpiresAt
+	switch {
+	case len(accessToken) > 0 && !expiresAt.IsZero() && time.Until(expiresAt) > upstreamTokenExpiryLeeway:
+		return newUpstreamToken(stored, string(accessToken)), nil
+	case len(refreshToken) == 0 && len(accessToken) > 0 && expiresAt.IsZero():
+		// The provider issued a token without expiry and no refresh token, as
+		// for example GitHub does.
+		return newUpstreamToken(stored, string(accessToken)), nil
+	case len(refreshToken) == 0:
+		return nil, errors.WithStack(ErrUpstreamTokenExpired)
+	}
+
+	token, err := s.refreshUpstreamToken(ctx, providerID, string(refreshToken))
+	if err != nil {
+		if s.credentialsUpdated(ctx, identityID, creds) {
+			return nil, errors.WithStack(herodot.ErrConflict.WithWrap(err).WithReason("The credentials were refreshed concurrently."))
+		}
+		return nil, err
+	}
+
+	tokens, err := s.encryptOAuth2Tokens(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	stored.InitialAccessToken = tokens.AccessToken
+	stored.InitialRefreshToken = tokens.RefreshToken
+	stored.AccessTokenExpiresAt = tokens.AccessTokenExpiresAt
+	if tokens.IDToken != "" {
+		stored.InitialIDToken = tokens.IDToken
+	}
+
+	creds.Config, err = json.Marshal(conf)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := s.d.PrivilegedIdentityPool().UpdateCredentialsConfig(ctx, creds); err != nil {
+		return nil, err
+	}
+
+	return newUpstreamToken(stored, token.AccessToken), nil
+}
+
+// credentialsUpdated reports whether the credentials were updated since they
+// were loaded, for example because they were refreshed using the same refresh
+// token by another instance.
+func (s *Strategy) credentialsUpdated(ctx context.Context, identityID uuid.UUID, loaded *identity.Credentials) bool {
+	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, identityID)
+	if err != nil {
+		return false
+	}
+	current, ok := i.GetCredentials(s.ID())
+	return ok && !current.UpdatedAt.Equal(loaded.UpdatedAt)
+}
+
+func (s *Strategy) refreshUpstreamToken(ctx context.Context, providerID, refreshToken string) (*oauth2.Token, error) {
+	provider, err := s.Provider(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, ok := provider.(OAuth2Provider)
+	if !ok {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReasonf("The OpenID Connect provider %q does not support refreshing tokens.", providerID))
+	}
+
+	c, err := p.OAuth2(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err = tokenEndpointContext(ctx, s.d, provider.Config())
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+		return nil, errors.WithStack(ErrUpstreamGrantRevoked.WithWrap(err))
+	} else if err != nil {
+		return nil, errors.WithStack(herodot.ErrUpstreamError.WithWrap(err).WithReasonf("Unable to refresh the access token of OpenID Connect provider %q: %s", providerID, err))
+	}
+
+	return token, nil
+}
+
+func newUpstreamToken(p *identity.CredentialsOIDCProvider, accessToken string) *UpstreamToken {
+	t := &UpstreamToken{Provider: p.Provider, Subject: p.Subject, AccessToken: accessToken}
+	if !p.AccessTokenExpiresAt.IsZero() {
+		t.ExpiresAt = &p.AccessTokenExpiresAt
+	}
+	return t
+}
@@ -0,0 +1,100 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package oidc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/persistence"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/uuidx"
+)
+
+func TestPersister(ctx context.Context, p interface {
+	persistence.Persister
+},
+) func(t *testing.T) {
+	return func(t *testing.T) {
+		nid, p := testhelpers.NewNetworkUnlessExisting(t, ctx, p)
+
+		newProvider := func(id string) *oidc.StoredProvider {
+			return &oidc.StoredProvider{
+				ID:         uuidx.NewV4(),
+				ProviderID: id,
+				Config:     []byte(`{"id":"` + id + `","provider":"generic"}`),
+			}
+		}
+
+		t.Run("case=creates, gets, and lists providers", func(t *testing.T) {
+			a, b := newProvider("provider-a"), newProvider("provider-b")
+			a.CreatedAt = time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+			b.OrganizationID = uuid.NullUUID{UUID: uuidx.NewV4(), Valid: true}
+			require.NoError(t, p.CreateOIDCProvider(ctx, a))
+			require.NoError(t, p.CreateOIDCProvider(ctx, b))
+			assert.Equal(t, nid, a.NID)
+
+			actual, err := p.GetOIDCProvider(ctx, "provider-b")
+			require.NoError(t, err)
+			assert.Equal(t, b.ID, actual.ID)
+			assert.Equal(t, b.OrganizationID, actual.OrganizationID)
+			assert.JSONEq(t, string(b.Config), string(actual.Config))
+
+			providers, err := p.ListOIDCProviders(ctx)
+			require.NoError(t, err)
+			require.Len(t, providers, 2)
+			assert.Equal(t, "provider-a", providers[0].ProviderID)
+			assert.Equal(t, "provider-b", providers[1].ProviderID)
+		})
+
+		t.Run("case=rejects duplicate provider IDs", func(t *testing.T) {
+			require.NoError(t, p.CreateOIDCProvider(ctx, newProvider("duplicate")))
+			require.ErrorIs(t, p.CreateOIDCProvider(ctx, newProvider("duplicate")), sqlcon.ErrUniqueViolation)
+		})
+
+		t.Run("case=updates a provider", func(t *testing.T) {
+			sp := newProvider("updated")
+			require.NoError(t, p.CreateOIDCProvider(ctx, sp))
+
+			sp.Config = []byte(`{"id":"updated","provider":"google"}`)
+			require.NoError(t, p.UpdateOIDCProvider(ctx, sp))
+
+			actual, err := p.GetOIDCProvider(ctx, "updated")
+			require.NoError(t, err)
+			assert.JSONEq(t, string(sp.Config), string(actual.Config))
+
+			require.ErrorIs(t, p.UpdateOIDCProvider(ctx, newProvider("unknown")), sqlcon.ErrNoRows)
+		})
+
+		t.Run("case=deletes a provider", func(t *testing.T) {
+			require.NoError(t, p.CreateOIDCProvider(ctx, newProvider("deleted")))
+			require.NoError(t, p.DeleteOIDCProvider(ctx, "deleted"))
+
+			_, err := p.GetOIDCProvider(ctx, "deleted")
+			require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			require.ErrorIs(t, p.DeleteOIDCProvider(ctx, "deleted"), sqlcon.ErrNoRows)
+		})
+
+		t.Run("case=network isolation", func(t *testing.T) {
+			_, other := testhelpers.NewNetwork(t, ctx, p)
+
+			_, err := other.GetOIDCProvider(ctx, "provider-a")
+			require.ErrorIs(t, err, sqlcon.ErrNoRows)
+			require.ErrorIs(t, other.DeleteOIDCProvider(ctx, "provider-a"), sqlcon.ErrNoRows)
+
+			providers, err := other.ListOIDCProviders(ctx)
+			require.NoError(t, err)
+			assert.Empty(t, providers)
+
+			require.NoError(t, other.CreateOIDCProvider(ctx, newProvider("provider-a")), "provider IDs are unique per network")
+		})
+	}
+}
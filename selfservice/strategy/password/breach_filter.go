@@ -0,0 +1,177 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1" //#nosec G505 -- sha1 is used for k-anonymity
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// breachFilterMagic prefixes every breach filter file. The last byte is the
+// version of the format.
+var breachFilterMagic = []byte{'k', 'b', 'f', 1}
+
+// BreachFilter is a bloom filter of the SHA-1 hashes of breached passwords.
+// It answers whether a password was breached with a configurable false
+// positive rate, but never misses a breached password which was added to it.
+//
+// Breach filters are built with `kratos passwords build-breach-filter` from
+// the Pwned Passwords dataset, and contain only the passwords which were
+// breached at least MinCount times. The file format is:
+//
+//	magic | hash functions (4 bytes) | min count (8 bytes) | items (8 bytes) | bits (8 bytes) | bitset
+type BreachFilter struct {
+	// MinCount is the number of breaches from which on passwords were added
+	// to the filter.
+	MinCount uint64
+
+	// Items is the number of passwords added to the filter.
+	Items uint64
+
+	hashes uint32
+	bits   []byte
+}
+
+// NewBreachFilter returns an empty filter sized for expectedItems passwords
+// at the given false positive rate.
+func NewBreachFilter(expectedItems uint64, falsePositiveRate float64, minCount uint64) (*BreachFilter, error) {
+	if expectedItems == 0 {
+		return nil, errors.New("the number of expected items must be greater than zero")
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, errors.New("the false positive rate must be between zero and one")
+	}
+
+	m := math.Ceil(-float64(expectedItems) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Round(m/float64(expectedItems)*math.Ln2))
+	if m/8 > math.MaxInt32 {
+		return nil, errors.New("the filter would be too large, use a higher false positive rate")
+	}
+
+	return &BreachFilter{
+		MinCount: minCount,
+		hashes:   uint32(k),
+		bits:     make([]byte, int(math.Ceil(m/8))),
+	}, nil
+}
+
+// ReadBreachFilter reads a filter previously written with WriteTo.
+func ReadBreachFilter(r io.Reader) (*BreachFilter, error) {
+	header := make([]byte, len(breachFilterMagic)+4+8+8+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.Wrap(err, "unable to read the breach filter header")
+	}
+	if !bytes.HasPrefix(header, breachFilterMagic) {
+		return nil, errors.New("the file is not a breach filter")
+	}
+
+	rest := header[len(breachFilterMagic):]
+	f := &BreachFilter{
+		hashes:   binary.BigEndian.Uint32(rest[0:4]),
+		MinCount: binary.BigEndian.Uint64(rest[4:12]),
+		Items:    binary.BigEndian.Uint64(rest[12:20]),
+	}
+	size := binary.BigEndian.Uint64(rest[20:28])
+	if f.hashes == 0 || size == 0 || size > math.MaxInt32 {
+		return nil, errors.New("the breach filter header is invalid")
+	}
+
+	f.bits = make([]byte, size)
+	if _, err := io.ReadFull(r, f.bits); err != nil {
+		return nil, errors.Wrap(err, "unable to read the breach filter")
+	}
+	return f, nil
+}
+
+// WriteTo writes the filter in a format which can be read with
+// ReadBreachFilter.
+func (f *BreachFilter) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, 0, len(breachFilterMagic)+4+8+8+8)
+	header = append(header, breachFilterMagic...)
+	header = binary.BigEndian.AppendUint32(header, f.hashes)
+	header = binary.BigEndian.AppendUint64(header, f.MinCount)
+	header = binary.BigEndian.AppendUint64(header, f.Items)
+	header = binary.BigEndian.AppendUint64(header, uint64(len(f.bits)))
+
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), errors.WithStack(err)
+	}
+	m, err := w.Write(f.bits)
+	return int64(n + m), errors.WithStack(err)
+}
+
+// positions returns the bits of a SHA-1 hash. SHA-1 hashes are uniformly
+// distributed already, so the bits are derived from the hash by double
+// hashing instead of hashing it again.
+func (f *BreachFilter) positions(hash []byte, fn func(byteIndex int, mask byte) bool) bool {
+	size := uint64(len(f.bits)) * 8
+	h1 := binary.BigEndian.Uint64(hash[0:8])
+	h2 := binary.BigEndian.Uint64(hash[8:16]) | 1
+	for i := range uint64(f.hashes) {
+		bit := (h1 + i*h2) % size
+		if !fn(int(bit/8), 1<<(bit%8)) { //nolint:gosec // the bitset is smaller than math.MaxInt32 bytes
+			return false
+		}
+	}
+	return true
+}
+
+// Add adds the SHA-1 hash of a password to the filter.
+func (f *BreachFilter) Add(hash []byte) {
+	if len(hash) != sha1.Size {
+		return
+	}
+	f.positions(hash, func(byteIndex int, mask byte) bool {
+		f.bits[byteIndex] |= mask
+		return true
+	})
+	f.Items++
+}
+
+// Contains returns true if the SHA-1 hash of a password was probably added
+// to the filter.
+func (f *BreachFilter) Contains(hash []byte) bool {
+	if len(hash) != sha1.Size {
+		return false
+	}
+	return f.positions(hash, func(byteIndex int, mask byte) bool {
+		return f.bits[byteIndex]&mask != 0
+	})
+}
+
+// AddRange adds the hashes of a range of the Pwned Passwords dataset to the
+// filter, which were breached at least MinCount times. Every line of the
+// range has the form "<hash suffix>:<count>", where the hash suffix is
+// appended to prefix. If prefix is empty, every line contains the full hash.
+// It returns the number of added hashes.
+func (f *BreachFilter) AddRange(prefix string, r io.Reader) (int, error) {
+	var added int
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		suffix, count, err := parseRangeLine(sc.Text())
+		if err != nil {
+			return added, err
+		} else if suffix == "" {
+			continue
+		}
+
+		hash, err := decodeHashHex(prefix + suffix)
+		if err != nil {
+			return added, err
+		}
+
+		if count >= int64(f.MinCount) { //nolint:gosec // the minimum count is far below math.MaxInt64
+			f.Add(hash)
+			added++
+		}
+	}
+	return added, errors.WithStack(sc.Err())
+}
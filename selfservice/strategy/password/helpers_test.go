@@ -4,10 +4,14 @@
 package password
 
 import (
+	"context"
+	"fmt"
 	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestTidyForm(t *testing.T) {
@@ -18,3 +22,19 @@ func TestTidyForm(t *testing.T) {
 		"foobar":     {"foo"},
 	}))
 }
+
+func TestIsNewPasswordPreviouslyUsed(t *testing.T) {
+	ctx := context.Background()
+
+	var history []string
+	for k := range 5 {
+		h, err := bcrypt.GenerateFromPassword(fmt.Appendf(nil, "password-%d", k), bcrypt.MinCost)
+		require.NoError(t, err)
+		history = append(history, string(h))
+	}
+
+	assert.True(t, isNewPasswordPreviouslyUsed(ctx, history, "password-0"))
+	assert.True(t, isNewPasswordPreviouslyUsed(ctx, history, "password-4"))
+	assert.False(t, isNewPasswordPreviouslyUsed(ctx, history, "password-5"))
+	assert.False(t, isNewPasswordPreviouslyUsed(ctx, nil, "password-0"))
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -18,6 +19,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/ory/herodot"
+	"github.com/ory/kratos/driver/config"
 	"github.com/ory/kratos/hash"
 	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/schema"
@@ -25,11 +27,13 @@ import (
 	"github.com/ory/kratos/selfservice/flow/login"
 	"github.com/ory/kratos/selfservice/flowhelpers"
 	"github.com/ory/kratos/selfservice/hook"
+	"github.com/ory/kratos/selfservice/ratelimit"
 	"github.com/ory/kratos/selfservice/strategy/idfirst"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/ui/node"
 	"github.com/ory/kratos/x"
+	"github.com/ory/kratos/x/events"
 	"github.com/ory/x/decoderx"
 	"github.com/ory/x/stringsx"
 )
@@ -78,12 +82,44 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 	}
 
 	identifier := stringsx.Coalesce(p.Identifier, p.LegacyIdentifier)
+	if realm := s.d.Config().SelfServiceLoginHomeRealm(ctx, identifier); realm != nil && realm.ForbidPasswordLogin {
+		return nil, s.handleLoginError(r, f, p, errors.WithStack(schema.NewPasswordLoginForbiddenError(config.EmailDomain(identifier))))
+	}
+
+	if err := s.d.RateLimiter().Check(ctx, ratelimit.IdentifierKey(identifier)); err != nil {
+		return nil, s.handleLoginError(r, f, p, err)
+	}
+
 	i, c, err := s.d.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, s.ID(), identifier)
 	if err != nil {
 		time.Sleep(x.RandomDelay(s.d.Config().HasherArgon2(ctx).ExpectedDuration, s.d.Config().HasherArgon2(ctx).ExpectedDeviation))
+		if err := s.d.RateLimiter().Hit(ctx, ratelimit.IdentifierKey(identifier), s.d.RateLimiter().IPKey(ctx, r)); err != nil {
+			return nil, s.handleLoginError(r, f, p, err)
+		}
 		return nil, s.handleLoginError(r, f, p, errors.WithStack(schema.NewInvalidCredentialsError()))
 	}
 
+	if domain := s.passwordLoginForbiddenDomain(ctx, i, c); domain != "" {
+		if s.d.Config().SecurityAccountEnumerationMitigate(ctx) {
+			// Do not reveal that the identifier belongs to an identity of the domain.
+			time.Sleep(x.RandomDelay(s.d.Config().HasherArgon2(ctx).ExpectedDuration, s.d.Config().HasherArgon2(ctx).ExpectedDeviation))
+			return nil, s.handleLoginError(r, f, p, errors.WithStack(x.WrapWithIdentityIDError(schema.NewInvalidCredentialsError(), i.ID)))
+		}
+		return nil, s.handleLoginError(r, f, p, errors.WithStack(x.WrapWithIdentityIDError(schema.NewPasswordLoginForbiddenError(domain), i.ID)))
+	}
+
+	if err := s.d.RateLimiter().Check(ctx, ratelimit.IdentityKey(i.ID)); err != nil {
+		return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+	}
+
+	if err := s.d.IdentityManager().CheckLockout(ctx, i.ID); err != nil {
+		if s.d.Config().SecurityAccountEnumerationMitigate(ctx) {
+			// Take as long as for unknown identifiers and wrong passwords.
+			time.Sleep(x.RandomDelay(s.d.Config().HasherArgon2(ctx).ExpectedDuration, s.d.Config().HasherArgon2(ctx).ExpectedDeviation))
+		}
+		return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+	}
+
 	var o identity.CredentialsPassword
 	d := json.NewDecoder(bytes.NewBuffer(c.Config))
 	if err := d.Decode(&o); err != nil {
@@ -103,6 +139,14 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 			Identity:   i,
 		})
 		if err != nil {
+			if e := new(schema.ValidationError); errors.As(err, &e) {
+				if err := s.d.RateLimiter().Hit(ctx, ratelimit.IdentifierKey(identifier), s.d.RateLimiter().IPKey(ctx, r), ratelimit.IdentityKey(i.ID)); err != nil {
+					return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+				}
+				if err := s.d.IdentityManager().RecordFailedAuthentication(ctx, i.ID); err != nil {
+					return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+				}
+			}
 			return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
 		}
 
@@ -111,16 +155,32 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 		}
 	} else {
 		if err := hash.Compare(ctx, []byte(p.Password), []byte(o.HashedPassword)); err != nil {
+			if err := s.d.RateLimiter().Hit(ctx, ratelimit.IdentifierKey(identifier), s.d.RateLimiter().IPKey(ctx, r), ratelimit.IdentityKey(i.ID)); err != nil {
+				return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+			}
+			if err := s.d.IdentityManager().RecordFailedAuthentication(ctx, i.ID); err != nil {
+				return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+			}
 			return nil, s.handleLoginError(r, f, p, errors.WithStack(x.WrapWithIdentityIDError(schema.NewInvalidCredentialsError(), i.ID)))
 		}
 
-		if !s.d.Hasher(ctx).Understands([]byte(o.HashedPassword)) {
+		if s.d.Hasher(ctx).NeedsRehash(ctx, []byte(o.HashedPassword)) {
 			if err := s.migratePasswordHash(ctx, i.ID, []byte(p.Password)); err != nil {
 				s.d.Logger().Warnf("Unable to migrate password hash for identity %s: %s Keeping existing password hash and continuing.", i.ID, x.WrapWithIdentityIDError(err, i.ID))
+			} else {
+				span.AddEvent(events.NewPasswordHashUpgraded(ctx, i.ID, hash.AlgorithmName([]byte(o.HashedPassword)), s.d.Config().HasherPasswordHashingAlgorithm(ctx)))
 			}
 		}
 	}
 
+	if err := s.d.RateLimiter().Reset(ctx, ratelimit.IdentifierKey(identifier), ratelimit.IdentityKey(i.ID)); err != nil {
+		return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+	}
+
+	if err := s.d.IdentityManager().ResetFailedAuthentications(ctx, i.ID); err != nil {
+		return nil, s.handleLoginError(r, f, p, x.WrapWithIdentityIDError(err, i.ID))
+	}
+
 	f.Active = s.ID()
 	if err = s.d.LoginFlowPersister().UpdateLoginFlow(ctx, f); err != nil {
 		return nil, s.handleLoginError(r, f, p, errors.WithStack(x.WrapWithIdentityIDError(herodot.ErrInternalServerError.WithReason("Could not update flow").WithDebug(err.Error()), i.ID)))
@@ -129,6 +189,26 @@ func (s *Strategy) Login(w http.ResponseWriter, r *http.Request, f *login.Flow,
 	return i, nil
 }
 
+// passwordLoginForbiddenDomain returns the domain of an email identifier or
+// address of the identity whose home realm forbids password logins, so that
+// password logins can not bypass the home realm using another identifier such
+// as a username. It returns an empty string if password logins are allowed.
+func (s *Strategy) passwordLoginForbiddenDomain(ctx context.Context, i *identity.Identity, c *identity.Credentials) string {
+	values := slices.Clone(c.Identifiers)
+	for _, a := range i.VerifiableAddresses {
+		if a.Via == identity.AddressTypeEmail {
+			values = append(values, a.Value)
+		}
+	}
+
+	for _, v := range values {
+		if realm := s.d.Config().SelfServiceLoginHomeRealm(ctx, v); realm != nil && realm.ForbidPasswordLogin {
+			return config.EmailDomain(v)
+		}
+	}
+	return ""
+}
+
 func (s *Strategy) migratePasswordHash(ctx context.Context, identifier uuid.UUID, password []byte) (err error) {
 	ctx, span := s.d.Tracer(ctx).Tracer().Start(ctx, "selfservice.strategy.password.Strategy.migratePasswordHash")
 	defer otelx.End(span, &err)
@@ -137,10 +217,6 @@ func (s *Strategy) migratePasswordHash(ctx context.Context, identifier uuid.UUID
 	if err != nil {
 		return err
 	}
-	co, err := json.Marshal(&identity.CredentialsPassword{HashedPassword: string(hpw)})
-	if err != nil {
-		return errors.Wrap(err, "unable to encode password configuration to JSON")
-	}
 
 	i, err := s.d.PrivilegedIdentityPool().GetIdentityConfidential(ctx, identifier)
 	if err != nil {
@@ -152,6 +228,19 @@ func (s *Strategy) migratePasswordHash(ctx context.Context, identifier uuid.UUID
 		return errors.New("expected to find password credential but could not")
 	}
 
+	// Only the hash is replaced, so that the rest of the configuration such
+	// as the password history is kept.
+	var o identity.CredentialsPassword
+	if err := json.Unmarshal(c.Config, &o); err != nil {
+		return errors.Wrap(err, "unable to decode password configuration from JSON")
+	}
+	o.HashedPassword = string(hpw)
+
+	co, err := json.Marshal(&o)
+	if err != nil {
+		return errors.Wrap(err, "unable to encode password configuration to JSON")
+	}
+
 	c.Config = co
 	i.SetCredentials(s.ID(), *c)
 
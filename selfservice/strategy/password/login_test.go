@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -24,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
+	"golang.org/x/crypto/bcrypt"
 
 	"github.com/ory/kratos/driver"
 	"github.com/ory/kratos/driver/config"
@@ -906,6 +908,109 @@ func TestCompleteLogin(t *testing.T) {
 		assert.Equal(t, identifier, gjson.Get(body, "identity.traits.email").String(), "%s", body)
 	})
 
+	t.Run("should upgrade password hashed with weaker parameters", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyHasherBcryptCost, 5)
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeyHasherBcryptCost, 4) })
+
+		identifier, pwd := x.NewUUID().String()+"@google.com", "password"
+		p, err := bcrypt.GenerateFromPassword([]byte(pwd), 4)
+		require.NoError(t, err)
+
+		iId := x.NewUUID()
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, &identity.Identity{
+			ID:       iId,
+			SchemaID: "migration",
+			Traits:   identity.Traits(fmt.Sprintf(`{"email":"%s"}`, identifier)),
+			Credentials: map[identity.CredentialsType]identity.Credentials{
+				identity.CredentialsTypePassword: {
+					Type:        identity.CredentialsTypePassword,
+					Identifiers: []string{identifier},
+					Config:      sqlxx.JSONRawMessage(`{"hashed_password":"` + string(p) + `"}`),
+				},
+			},
+			VerifiableAddresses: []identity.VerifiableAddress{
+				{
+					ID:         x.NewUUID(),
+					Value:      identifier,
+					Verified:   true,
+					CreatedAt:  time.Now(),
+					IdentityID: iId,
+				},
+			},
+		}))
+
+		values := func(v url.Values) {
+			v.Set("identifier", identifier)
+			v.Set("method", identity.CredentialsTypePassword.String())
+			v.Set("password", pwd)
+		}
+
+		body := testhelpers.SubmitLoginForm(t, false, testhelpers.NewClientWithCookies(t), publicTS, values,
+			false, false, http.StatusOK, redirTS.URL)
+		assert.Equal(t, identifier, gjson.Get(body, "identity.traits.email").String(), "%s", body)
+
+		_, c, err := reg.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, identity.CredentialsTypePassword, identifier)
+		require.NoError(t, err)
+		var o identity.CredentialsPassword
+		require.NoError(t, json.Unmarshal(c.Config, &o))
+		cost, err := bcrypt.Cost([]byte(o.HashedPassword))
+		require.NoError(t, err)
+		assert.Equal(t, 5, cost)
+		assert.False(t, reg.Hasher(ctx).NeedsRehash(ctx, []byte(o.HashedPassword)))
+	})
+
+	t.Run("should keep the password history when upgrading the password hash", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyHasherBcryptCost, 5)
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeyHasherBcryptCost, 4) })
+
+		identifier, pwd := x.NewUUID().String()+"@google.com", "password"
+		p, err := bcrypt.GenerateFromPassword([]byte(pwd), 4)
+		require.NoError(t, err)
+		previous, err := bcrypt.GenerateFromPassword([]byte("previous-password"), 4)
+		require.NoError(t, err)
+
+		iId := x.NewUUID()
+		require.NoError(t, reg.PrivilegedIdentityPool().CreateIdentity(ctx, &identity.Identity{
+			ID:       iId,
+			SchemaID: "migration",
+			Traits:   identity.Traits(fmt.Sprintf(`{"email":"%s"}`, identifier)),
+			Credentials: map[identity.CredentialsType]identity.Credentials{
+				identity.CredentialsTypePassword: {
+					Type:        identity.CredentialsTypePassword,
+					Identifiers: []string{identifier},
+					Config:      sqlxx.JSONRawMessage(`{"hashed_password":"` + string(p) + `","previous_hashed_passwords":["` + string(previous) + `"]}`),
+				},
+			},
+			VerifiableAddresses: []identity.VerifiableAddress{
+				{
+					ID:         x.NewUUID(),
+					Value:      identifier,
+					Verified:   true,
+					CreatedAt:  time.Now(),
+					IdentityID: iId,
+				},
+			},
+		}))
+
+		values := func(v url.Values) {
+			v.Set("identifier", identifier)
+			v.Set("method", identity.CredentialsTypePassword.String())
+			v.Set("password", pwd)
+		}
+
+		body := testhelpers.SubmitLoginForm(t, false, testhelpers.NewClientWithCookies(t), publicTS, values,
+			false, false, http.StatusOK, redirTS.URL)
+		assert.Equal(t, identifier, gjson.Get(body, "identity.traits.email").String(), "%s", body)
+
+		_, c, err := reg.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, identity.CredentialsTypePassword, identifier)
+		require.NoError(t, err)
+		var o identity.CredentialsPassword
+		require.NoError(t, json.Unmarshal(c.Config, &o))
+		assert.NotEqual(t, string(p), o.HashedPassword)
+		assert.False(t, reg.Hasher(ctx).NeedsRehash(ctx, []byte(o.HashedPassword)))
+		assert.Equal(t, []string{string(previous)}, o.PreviousHashedPasswords)
+	})
+
 	t.Run("suite=password rehashing degrades gracefully during login", func(t *testing.T) {
 		identifier := x.NewUUID().String() + "@google.com"
 		// pwd := "Kd9hUV4Xkcq87VSca6A4fq1iBijrMScBFhkpIPEwBtvTDsBwfqJCqXPPr4TkhOhsd9wFGeB3MzS4bJuesLCAjJc5s1GKJ51zW7F"
@@ -1356,3 +1461,201 @@ func TestFormHydration(t *testing.T) {
 		toSnapshot(t, f)
 	})
 }
+
+func TestLoginRateLimit(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+string(identity.CredentialsTypePassword),
+		map[string]interface{}{"enabled": true})
+	conf.MustSet(ctx, config.ViperKeySecurityRateLimit, map[string]interface{}{
+		"enabled":                     true,
+		"max_attempts_per_identifier": 2,
+		"max_attempts_per_ip":         0,
+	})
+	testhelpers.SetIdentitySchemas(t, conf, map[string]string{"default": "file://./stub/login.schema.json"})
+	publicTS, _ := testhelpers.NewKratosServer(t, reg)
+
+	submit := func(t *testing.T, hc *http.Client, identifier, password string) (string, *http.Response) {
+		f := testhelpers.InitializeLoginFlowViaAPI(t, hc, publicTS, false)
+		return testhelpers.LoginMakeRequest(t, true, false, f, hc,
+			fmt.Sprintf(`{"method":"password","identifier":%q,"password":%q}`, identifier, password))
+	}
+
+	t.Run("case=blocks the identifier after too many failed attempts", func(t *testing.T) {
+		identifier, pwd := x.NewUUID().String(), x.NewUUID().String()
+		createIdentity(ctx, reg, t, identifier, pwd)
+		hc := testhelpers.NewDebugClient(t)
+
+		for range 2 {
+			body, res := submit(t, hc, identifier, "not-"+pwd)
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+			assert.Equal(t, int64(text.ErrorValidationInvalidCredentials), gjson.Get(body, "ui.messages.0.id").Int(), body)
+		}
+
+		body, res := submit(t, hc, identifier, pwd)
+		require.Equal(t, http.StatusTooManyRequests, res.StatusCode, body)
+		assert.Equal(t, int64(text.ErrorValidationLoginRateLimited), gjson.Get(body, "ui.messages.0.id").Int(), body)
+		assert.NotEmpty(t, res.Header.Get("Retry-After"))
+		assert.Equal(t, identifier, gjson.Get(body, "ui.nodes.#(attributes.name==identifier).attributes.value").String(), body)
+
+		t.Run("case=other identifiers are not affected", func(t *testing.T) {
+			identifier, pwd := x.NewUUID().String(), x.NewUUID().String()
+			createIdentity(ctx, reg, t, identifier, pwd)
+
+			body, res := submit(t, hc, identifier, pwd)
+			require.Equal(t, http.StatusOK, res.StatusCode, body)
+		})
+	})
+
+	t.Run("case=successful login resets the failed attempts", func(t *testing.T) {
+		identifier, pwd := x.NewUUID().String(), x.NewUUID().String()
+		createIdentity(ctx, reg, t, identifier, pwd)
+		hc := testhelpers.NewDebugClient(t)
+
+		for range 2 {
+			body, res := submit(t, hc, identifier, "not-"+pwd)
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+
+			body, res = submit(t, hc, identifier, pwd)
+			require.Equal(t, http.StatusOK, res.StatusCode, body)
+		}
+	})
+
+	t.Run("case=blocks the IP address after too many failed attempts", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySecurityRateLimit+".max_attempts_per_ip", 1)
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeySecurityRateLimit+".max_attempts_per_ip", 0) })
+
+		// Dialing from different loopback addresses changes the remote
+		// address the server sees.
+		clientFrom := func(ip string, h http.Header) *http.Client {
+			dialer := &net.Dialer{LocalAddr: &net.TCPAddr{IP: net.ParseIP(ip)}}
+			transport := testhelpers.NewTransportWithHeader(t, h)
+			transport.RoundTripper = &http.Transport{DialContext: dialer.DialContext}
+			return &http.Client{Transport: transport}
+		}
+
+		hc := clientFrom("127.0.0.2", nil)
+		body, res := submit(t, hc, x.NewUUID().String(), "password")
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+
+		body, res = submit(t, hc, x.NewUUID().String(), "password")
+		require.Equal(t, http.StatusTooManyRequests, res.StatusCode, body)
+		assert.Equal(t, int64(text.ErrorValidationLoginRateLimited), gjson.Get(body, "ui.messages.0.id").Int(), body)
+
+		t.Run("case=forwarding headers of untrusted clients are ignored", func(t *testing.T) {
+			hc := clientFrom("127.0.0.2", http.Header{
+				"True-Client-Ip":  {"192.0.2.1"},
+				"X-Forwarded-For": {"192.0.2.1"},
+			})
+			body, res := submit(t, hc, x.NewUUID().String(), "password")
+			require.Equal(t, http.StatusTooManyRequests, res.StatusCode, body)
+		})
+
+		t.Run("case=forwarding headers of trusted proxies are used", func(t *testing.T) {
+			conf.MustSet(ctx, config.ViperKeySecurityRateLimit+".trusted_proxies", []string{"127.0.0.2"})
+			t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeySecurityRateLimit+".trusted_proxies", []string{}) })
+
+			hc := clientFrom("127.0.0.2", http.Header{"X-Forwarded-For": {"192.0.2.1"}})
+			body, res := submit(t, hc, x.NewUUID().String(), "password")
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+		})
+
+		identifier, pwd := x.NewUUID().String(), x.NewUUID().String()
+		createIdentity(ctx, reg, t, identifier, pwd)
+		body, res = submit(t, clientFrom("127.0.0.3", nil), identifier, pwd)
+		require.Equal(t, http.StatusOK, res.StatusCode, body)
+	})
+}
+
+func TestLoginAccountLockout(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+"."+string(identity.CredentialsTypePassword),
+		map[string]interface{}{"enabled": true})
+	conf.MustSet(ctx, config.ViperKeySecurityAccountLockout, map[string]interface{}{
+		"enabled":             true,
+		"max_failed_attempts": 2,
+		"duration":            "1h",
+	})
+	testhelpers.SetIdentitySchemas(t, conf, map[string]string{"default": "file://./stub/login.schema.json"})
+	publicTS, _ := testhelpers.NewKratosServer(t, reg)
+
+	submit := func(t *testing.T, identifier, password string) (string, *http.Response) {
+		hc := testhelpers.NewDebugClient(t)
+		f := testhelpers.InitializeLoginFlowViaAPI(t, hc, publicTS, false)
+		return testhelpers.LoginMakeRequest(t, true, false, f, hc,
+			fmt.Sprintf(`{"method":"password","identifier":%q,"password":%q}`, identifier, password))
+	}
+
+	t.Run("case=locks the identity after too many consecutive failed attempts", func(t *testing.T) {
+		identifier, pwd := x.NewUUID().String(), x.NewUUID().String()
+		i := createIdentity(ctx, reg, t, identifier, pwd)
+
+		for range 2 {
+			body, res := submit(t, identifier, "not-"+pwd)
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+			assert.Equal(t, int64(text.ErrorValidationInvalidCredentials), gjson.Get(body, "ui.messages.0.id").Int(), body)
+		}
+
+		body, res := submit(t, identifier, pwd)
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+		assert.Equal(t, int64(text.ErrorValidationLoginIdentityLocked), gjson.Get(body, "ui.messages.0.id").Int(), body)
+
+		l, err := reg.IdentityManager().GetLockout(ctx, i.ID)
+		require.NoError(t, err)
+		assert.True(t, l.Locked)
+		assert.Equal(t, 1, l.Lockouts)
+
+		t.Run("case=unlocking allows to sign in again", func(t *testing.T) {
+			require.NoError(t, reg.IdentityManager().Unlock(ctx, i.ID))
+
+			body, res := submit(t, identifier, pwd)
+			require.Equal(t, http.StatusOK, res.StatusCode, body)
+		})
+	})
+
+	t.Run("case=does not reveal the lockout if account enumeration is mitigated", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySecurityAccountEnumerationMitigate, true)
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeySecurityAccountEnumerationMitigate, false) })
+
+		identifier, pwd := x.NewUUID().String(), x.NewUUID().String()
+		i := createIdentity(ctx, reg, t, identifier, pwd)
+
+		for range 2 {
+			body, res := submit(t, identifier, "not-"+pwd)
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+		}
+
+		l, err := reg.IdentityManager().GetLockout(ctx, i.ID)
+		require.NoError(t, err)
+		require.True(t, l.Locked)
+
+		for _, password := range []string{pwd, "not-" + pwd} {
+			body, res := submit(t, identifier, password)
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+			assert.Equal(t, int64(text.ErrorValidationInvalidCredentials), gjson.Get(body, "ui.messages.0.id").Int(), body)
+		}
+
+		body, res := submit(t, x.NewUUID().String(), pwd)
+		require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+		assert.Equal(t, int64(text.ErrorValidationInvalidCredentials), gjson.Get(body, "ui.messages.0.id").Int(), body)
+	})
+
+	t.Run("case=successful login resets the failed attempts", func(t *testing.T) {
+		identifier, pwd := x.NewUUID().String(), x.NewUUID().String()
+		i := createIdentity(ctx, reg, t, identifier, pwd)
+
+		for range 2 {
+			body, res := submit(t, identifier, "not-"+pwd)
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+
+			body, res = submit(t, identifier, pwd)
+			require.Equal(t, http.StatusOK, res.StatusCode, body)
+		}
+
+		l, err := reg.IdentityManager().GetLockout(ctx, i.ID)
+		require.NoError(t, err)
+		assert.False(t, l.Locked)
+		assert.Zero(t, l.FailedAttempts)
+	})
+}
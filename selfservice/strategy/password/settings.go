@@ -120,22 +120,22 @@ func (s *Strategy) decodeSettingsFlow(r *http.Request, dest interface{}) error {
 	)
 }
 
-// Try to find a password hash in the credentials. Returns it if found, otherwise return an empty string.
-func getPasswordHashFromCredential(creds map[identity.CredentialsType]identity.Credentials) string {
+// Try to find the password configuration in the credentials. Returns it if found, otherwise return an empty configuration.
+func getPasswordConfigFromCredential(creds map[identity.CredentialsType]identity.Credentials) identity.CredentialsPassword {
+	var hashedPassword identity.CredentialsPassword
 	if creds == nil {
-		return ""
+		return hashedPassword
 	}
 
 	cred, ok := creds[identity.CredentialsTypePassword]
 	if !ok {
-		return ""
+		return hashedPassword
 	}
 
-	var hashedPassword identity.CredentialsPassword
 	if err := json.Unmarshal(cred.Config, &hashedPassword); err != nil {
-		return ""
+		return identity.CredentialsPassword{}
 	}
-	return hashedPassword.HashedPassword
+	return hashedPassword
 }
 
 // Detect whether the new password is the same as the old password.
@@ -151,6 +151,55 @@ func isNewPasswordSameAsOld(ctx context.Context, oldHashedPassword string, newPa
 	return hash.Compare(ctx, []byte(newPassword), []byte(oldHashedPassword)) == nil
 }
 
+// maxPasswordHistorySize bounds the number of previous passwords compared with
+// a new password, as every comparison computes an expensive hash.
+const maxPasswordHistorySize = 12
+
+// passwordHistoryConcurrency is how many previous passwords are compared with
+// the new password at once.
+const passwordHistoryConcurrency = 2
+
+var errPasswordPreviouslyUsed = errors.New("the password was used before")
+
+// Detect whether the new password matches one of the previous passwords remembered in the password history. The
+// comparisons stop as soon as a match was found.
+func isNewPasswordPreviouslyUsed(ctx context.Context, previousHashedPasswords []string, newPassword string) bool {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(passwordHistoryConcurrency)
+	for _, previous := range previousHashedPasswords {
+		if ctx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if isNewPasswordSameAsOld(ctx, previous, newPassword) {
+				return errPasswordPreviouslyUsed
+			}
+			return nil
+		})
+	}
+	return g.Wait() != nil
+}
+
+// passwordHistory returns the previous password hashes to remember after the password was changed, with the most
+// recent first.
+func passwordHistory(old identity.CredentialsPassword, historySize int) []string {
+	if historySize <= 0 {
+		return nil
+	}
+
+	history := old.PreviousHashedPasswords
+	if old.HashedPassword != "" {
+		history = append([]string{old.HashedPassword}, history...)
+	}
+	if len(history) > historySize {
+		history = history[:historySize]
+	}
+	return history
+}
+
 func (s *Strategy) continueSettingsFlow(ctx context.Context, r *http.Request, ctxUpdate *settings.UpdateContext, p updateSettingsFlowWithPasswordMethod) error {
 	if err := flow.MethodEnabledAndAllowed(ctx, flow.SettingsFlow, s.SettingsStrategyID(), p.Method, s.d); err != nil {
 		return err
@@ -175,8 +224,10 @@ func (s *Strategy) continueSettingsFlow(ctx context.Context, r *http.Request, ct
 
 	g, ctx := errgroup.WithContext(ctx)
 	var newPasswordHash []byte
-	// Extract an immutable value to avoid data races between goroutines.
-	oldHashedPassword := getPasswordHashFromCredential(i.Credentials)
+	// Extract immutable values to avoid data races between goroutines.
+	oldPassword := getPasswordConfigFromCredential(i.Credentials)
+	historySize := int(min(s.d.Config().PasswordPolicyConfig(ctx).HistorySize, maxPasswordHistorySize)) // #nosec G115 -- the history size is bounded
+	previousHashedPasswords := oldPassword.PreviousHashedPasswords[:min(historySize, len(oldPassword.PreviousHashedPasswords))]
 
 	// Do in parallel due to limitations of the `bcrypt` library and for performance:
 	// - `hash(newPassword)` (expensive).
@@ -189,9 +240,12 @@ func (s *Strategy) continueSettingsFlow(ctx context.Context, r *http.Request, ct
 		return err
 	})
 	g.Go(func() error {
-		if isNewPasswordSameAsOld(ctx, oldHashedPassword, p.Password) {
+		if isNewPasswordSameAsOld(ctx, oldPassword.HashedPassword, p.Password) {
 			return schema.NewPasswordPolicyViolationError("#/password", text.NewErrorValidationPasswordNewSameAsOld())
 		}
+		if isNewPasswordPreviouslyUsed(ctx, previousHashedPasswords, p.Password) {
+			return schema.NewPasswordPolicyViolationError("#/password", text.NewErrorValidationPasswordPreviouslyUsed())
+		}
 		return nil
 	})
 	g.Go(func() error {
@@ -206,7 +260,7 @@ func (s *Strategy) continueSettingsFlow(ctx context.Context, r *http.Request, ct
 		return err
 	}
 
-	co, err := json.Marshal(&identity.CredentialsPassword{HashedPassword: string(newPasswordHash)})
+	co, err := json.Marshal(&identity.CredentialsPassword{HashedPassword: string(newPasswordHash), PreviousHashedPasswords: passwordHistory(oldPassword, historySize)})
 	if err != nil {
 		return errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to encode password options to JSON: %s", err))
 	}
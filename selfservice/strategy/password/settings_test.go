@@ -268,6 +268,72 @@ func TestSettings(t *testing.T) {
 		})
 	})
 
+	t.Run("case=should reject a new password if it is in the password history", func(t *testing.T) {
+		conf.MustSet(ctx, config.HookStrategyKey(config.ViperKeySelfServiceRegistrationAfter, identity.CredentialsTypePassword.String()), []config.SelfServiceHook{{Name: "session"}})
+		conf.MustSet(ctx, config.ViperKeyPasswordHistorySize, 1)
+		t.Cleanup(func() {
+			conf.MustSet(ctx, config.HookStrategyKey(config.ViperKeySelfServiceRegistrationAfter, identity.CredentialsTypePassword.String()), nil)
+			conf.MustSet(ctx, config.ViperKeyPasswordHistorySize, 0)
+		})
+
+		cfg := client.NewConfiguration()
+		u, err := url.Parse(publicTS.URL)
+		require.NoError(t, err)
+		cfg.Scheme = u.Scheme
+		cfg.Host = u.Host
+		api := client.NewAPIClient(cfg).FrontendAPI
+
+		first := uuid.Must(uuid.NewV4()).String()
+		registrationFlow, _, err := api.CreateNativeRegistrationFlow(t.Context()).Execute()
+		require.NoError(t, err)
+		registration, _, err := api.UpdateRegistrationFlow(t.Context()).Flow(registrationFlow.Id).UpdateRegistrationFlowBody(client.UpdateRegistrationFlowBody{
+			UpdateRegistrationFlowWithPasswordMethod: &client.UpdateRegistrationFlowWithPasswordMethod{
+				Method:   "password",
+				Password: first,
+				Traits: map[string]any{
+					"email": uuid.Must(uuid.NewV4()).String() + "@ory.dev",
+				},
+			},
+		}).Execute()
+		require.NoError(t, err)
+		require.NotNil(t, registration.SessionToken)
+		sessionToken := *registration.SessionToken
+
+		changePassword := func(t *testing.T, password string) (int, []byte) {
+			settingsFlow, _, err := api.CreateNativeSettingsFlow(t.Context()).XSessionToken(sessionToken).Execute()
+			require.NoError(t, err)
+
+			_, res, err := api.UpdateSettingsFlow(t.Context()).Flow(settingsFlow.Id).XSessionToken(sessionToken).UpdateSettingsFlowBody(client.UpdateSettingsFlowBody{
+				UpdateSettingsFlowWithPasswordMethod: &client.UpdateSettingsFlowWithPasswordMethod{
+					Method:   "password",
+					Password: password,
+				},
+			}).Execute()
+			require.NotNil(t, res)
+			if err != nil {
+				var apiErr *client.GenericOpenAPIError
+				require.ErrorAs(t, err, &apiErr)
+				return res.StatusCode, apiErr.Body()
+			}
+			return res.StatusCode, nil
+		}
+
+		second := uuid.Must(uuid.NewV4()).String()
+		status, _ := changePassword(t, second)
+		require.Equal(t, http.StatusOK, status)
+
+		status, body := changePassword(t, first)
+		require.Equal(t, http.StatusBadRequest, status)
+		assert.EqualValues(t, text.ErrorValidationPasswordPreviouslyUsed, gjson.GetBytes(body, "ui.nodes.#(attributes.name==password).messages.0.id").Int(), "%s", body)
+
+		status, _ = changePassword(t, uuid.Must(uuid.NewV4()).String())
+		require.Equal(t, http.StatusOK, status)
+
+		// The first password is no longer remembered because the history holds only one password.
+		status, body = changePassword(t, first)
+		require.Equal(t, http.StatusOK, status, "%s", body)
+	})
+
 	t.Run("description=not authorized to call endpoints without a session", func(t *testing.T) {
 		c := testhelpers.NewDebugClient(t)
 		t.Run("type=browser", func(t *testing.T) {
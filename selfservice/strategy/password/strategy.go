@@ -26,6 +26,7 @@ import (
 	"github.com/ory/kratos/selfservice/flow/login"
 	"github.com/ory/kratos/selfservice/flow/registration"
 	"github.com/ory/kratos/selfservice/flow/settings"
+	"github.com/ory/kratos/selfservice/ratelimit"
 	"github.com/ory/kratos/session"
 	"github.com/ory/kratos/x"
 )
@@ -74,6 +75,8 @@ type registrationStrategyDependencies interface {
 
 	session.HandlerProvider
 	session.ManagementProvider
+
+	ratelimit.LimiterProvider
 }
 
 type Strategy struct {
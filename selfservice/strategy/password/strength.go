@@ -0,0 +1,238 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package password
+
+import (
+	_ "embed"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// The strength estimation follows the approach of zxcvbn
+// (https://github.com/dropbox/zxcvbn): the password is split into the
+// sequence of patterns (common passwords, user inputs, repeats, sequences and
+// keyboard walks) which is the easiest to guess, and the number of guesses
+// needed for that sequence is mapped to a score between 0 and 4.
+
+//go:embed common_passwords.txt
+var commonPasswordsList string
+
+var commonPasswords = func() map[string]int {
+	ranked := make(map[string]int)
+	for _, word := range strings.Fields(commonPasswordsList) {
+		if _, ok := ranked[word]; !ok {
+			ranked[word] = len(ranked) + 1
+		}
+	}
+	return ranked
+}()
+
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"qwertzuiopü",
+	"asdfghjklöä",
+	"yxcvbnm",
+	"azertyuiop",
+	"qsdfghjklm",
+	"wxcvbn",
+}
+
+const (
+	// bruteforceCardinality is the number of guesses per character which is
+	// not part of any pattern.
+	bruteforceCardinality = 10
+	minPatternLength      = 3
+)
+
+type strengthMatch struct {
+	i, j    int
+	guesses float64
+}
+
+// passwordStrength returns a score between 0 (too guessable) and 4 (very
+// unguessable). The user inputs, for example the identifiers of the identity,
+// are treated like the most common passwords.
+func passwordStrength(password string, userInputs ...string) int {
+	guesses := estimateGuesses([]rune(password), userInputs)
+	switch {
+	case guesses < 1e3+5:
+		return 0
+	case guesses < 1e6+5:
+		return 1
+	case guesses < 1e8+5:
+		return 2
+	case guesses < 1e10+5:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func estimateGuesses(password []rune, userInputs []string) float64 {
+	if len(password) == 0 {
+		return 1
+	}
+
+	matches := findMatches(password, userInputs)
+
+	// best[k] holds the least number of guesses needed for the first k
+	// characters of the password.
+	best := make([]float64, len(password)+1)
+	best[0] = 1
+	for k := 1; k <= len(password); k++ {
+		best[k] = best[k-1] * bruteforceCardinality
+		for _, m := range matches {
+			if m.j == k-1 {
+				best[k] = math.Min(best[k], best[m.i]*m.guesses)
+			}
+		}
+	}
+	return best[len(password)]
+}
+
+func findMatches(password []rune, userInputs []string) []strengthMatch {
+	lower := make([]rune, len(password))
+	for k, r := range password {
+		lower[k] = unicode.ToLower(r)
+	}
+
+	dictionary := make(map[string]int, len(userInputs))
+	for _, input := range userInputs {
+		for _, word := range strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			dictionary[word] = 1
+		}
+		if input := strings.ToLower(input); len(input) > 0 {
+			dictionary[input] = 1
+		}
+	}
+
+	var matches []strengthMatch
+	for i := range lower {
+		for j := i + minPatternLength - 1; j < len(lower); j++ {
+			word := string(lower[i : j+1])
+			rank, ok := dictionary[word]
+			if !ok {
+				rank, ok = commonPasswords[word]
+			}
+			if ok {
+				matches = append(matches, strengthMatch{i: i, j: j, guesses: float64(rank) * uppercaseVariations(password[i:j+1])})
+			}
+		}
+	}
+
+	matches = append(matches, repeatMatches(lower)...)
+	matches = append(matches, sequenceMatches(lower)...)
+	matches = append(matches, keyboardMatches(lower)...)
+	return matches
+}
+
+// uppercaseVariations returns the factor by which the number of guesses
+// increases because of the capitalization of a word.
+func uppercaseVariations(word []rune) float64 {
+	var upper, lower int
+	for _, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+
+	switch {
+	case upper == 0:
+		return 1
+	case lower == 0, upper == 1 && unicode.IsUpper(word[0]), upper == 1 && unicode.IsUpper(word[len(word)-1]):
+		return 2
+	default:
+		return math.Pow(2, float64(min(upper, lower)))
+	}
+}
+
+func charCardinality(r rune) float64 {
+	switch {
+	case unicode.IsDigit(r):
+		return 10
+	case unicode.IsLower(r):
+		return 26
+	default:
+		return 33
+	}
+}
+
+// repeatMatches finds runs of the same character, e.g. "aaaa".
+func repeatMatches(password []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := 0; i < len(password); {
+		j := i
+		for j+1 < len(password) && password[j+1] == password[i] {
+			j++
+		}
+		if j-i+1 >= minPatternLength {
+			matches = append(matches, strengthMatch{i: i, j: j, guesses: charCardinality(password[i]) * float64(j-i+1)})
+		}
+		i = j + 1
+	}
+	return matches
+}
+
+// sequenceMatches finds runs of characters with a constant distance of
+// one, e.g. "abcd" or "9876".
+func sequenceMatches(password []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := 0; i+1 < len(password); {
+		delta := password[i+1] - password[i]
+		j := i + 1
+		for j+1 < len(password) && password[j+1]-password[j] == delta {
+			j++
+		}
+		if (delta == 1 || delta == -1) && j-i+1 >= minPatternLength {
+			base := charCardinality(password[i])
+			if strings.ContainsRune("aAzZ019", password[i]) {
+				base = 4
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			matches = append(matches, strengthMatch{i: i, j: j, guesses: base * float64(j-i+1)})
+		}
+		i = j
+	}
+	return matches
+}
+
+// keyboardMatches finds walks along a row of common keyboard layouts, e.g.
+// "qwerty" or "lkjh".
+func keyboardMatches(password []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := range password {
+		for j := len(password) - 1; j >= i+minPatternLength-1; j-- {
+			walk := string(password[i : j+1])
+			if onKeyboardRow(walk) {
+				matches = append(matches, strengthMatch{i: i, j: j, guesses: float64(len(keyboardRows)) * 2 * float64(j-i+1)})
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func onKeyboardRow(walk string) bool {
+	reversed := []rune(walk)
+	for a, b := 0, len(reversed)-1; a < b; a, b = a+1, b-1 {
+		reversed[a], reversed[b] = reversed[b], reversed[a]
+	}
+	for _, row := range keyboardRows {
+		if strings.Contains(row, walk) || strings.Contains(row, string(reversed)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package password
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordStrength(t *testing.T) {
+	for k, tc := range []struct {
+		password   string
+		userInputs []string
+		score      int
+	}{
+		{password: "", score: 0},
+		{password: "password", score: 0},
+		{password: "Password", score: 0},
+		{password: "123456789", score: 0},
+		{password: "aaaaaaaaaaaa", score: 0},
+		{password: "qwertyuiop", score: 0},
+		{password: "abcdefghijk", score: 0},
+		{password: "password123", score: 0},
+		{password: "Summer2024", score: 2},
+		{password: "foo.bar@example.org", userInputs: []string{"foo.bar@example.org"}, score: 0},
+		{password: "foobar2024!", userInputs: []string{"foo.bar@example.org"}, score: 1},
+		{password: "x7#kQ9!vL2", score: 3},
+		{password: "x7#kQ9!vL2m", score: 4},
+		{password: "correct horse battery staple", score: 4},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			assert.Equal(t, tc.score, passwordStrength(tc.password, tc.userInputs...), "%s", tc.password)
+		})
+	}
+}
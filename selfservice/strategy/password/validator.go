@@ -5,15 +5,23 @@ package password
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/sha1" //#nosec G505 -- sha1 is used for k-anonymity
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/x"
 
 	"github.com/arbovm/levenshtein"
 	"github.com/dgraph-io/ristretto/v2"
@@ -22,6 +30,7 @@ import (
 
 	"github.com/ory/herodot"
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/x/fetcher"
 	"github.com/ory/x/httpx"
 	"github.com/ory/x/otelx"
 )
@@ -55,11 +64,22 @@ var (
 //
 // Additionally passwords are being checked against Troy Hunt's
 // [haveibeenpwnd](https://haveibeenpwned.com/API/v2#SearchingPwnedPasswordsByRange) service to check if the
-// password has been breached in a previous data leak using k-anonymity.
+// password has been breached in a previous data leak using k-anonymity. Instead of the service, a locally
+// provisioned copy of the Pwned Passwords dataset or a BreachFilter built from it can be used.
+//
+// Depending on the password policy, passwords must also not exceed a maximum
+// length, contain characters of certain character classes, not contain words
+// from banned word lists, and reach a minimum strength score.
 type DefaultPasswordValidator struct {
-	reg    validatorDependencies
-	Client *retryablehttp.Client
-	hashes *ristretto.Cache[string, int64]
+	reg         validatorDependencies
+	Client      *retryablehttp.Client
+	hashes      *ristretto.Cache[string, int64]
+	bannedWords *ristretto.Cache[[]byte, []byte]
+
+	filterMu      sync.Mutex
+	filter        *BreachFilter
+	filterPath    string
+	filterModTime time.Time
 
 	minIdentifierPasswordDist            int
 	maxIdentifierPasswordSubstrThreshold float32
@@ -67,6 +87,7 @@ type DefaultPasswordValidator struct {
 
 type validatorDependencies interface {
 	config.Provider
+	x.HTTPClientProvider
 }
 
 func NewDefaultPasswordValidatorStrategy(reg validatorDependencies) (*DefaultPasswordValidator, error) {
@@ -80,12 +101,21 @@ func NewDefaultPasswordValidatorStrategy(reg validatorDependencies) (*DefaultPas
 	if err != nil {
 		return nil, errors.Wrap(err, "error while setting up validator cache")
 	}
+	bannedWords, err := ristretto.NewCache(&ristretto.Config[[]byte, []byte]{
+		MaxCost:     50 << 20, // 50MB
+		NumCounters: 1000,
+		BufferItems: 64,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error while setting up banned words cache")
+	}
 	return &DefaultPasswordValidator{
 		Client: httpx.NewResilientClient(
 			httpx.ResilientClientWithConnectionTimeout(time.Second),
 		),
 		reg:                       reg,
 		hashes:                    cache,
+		bannedWords:               bannedWords,
 		minIdentifierPasswordDist: 5, maxIdentifierPasswordSubstrThreshold: 0.5,
 	}, nil
 }
@@ -133,39 +163,73 @@ func (s *DefaultPasswordValidator) fetch(ctx context.Context, hpw []byte, apiDNS
 		return 0, errors.Wrapf(ErrUnexpectedStatusCode, "%d", res.StatusCode)
 	}
 
+	count, err := s.readRange(prefix, res.Body, hpw)
+	if err != nil {
+		return 0, errors.WithStack(herodot.ErrUpstreamError.WithWrap(err).WithReasonf("Unable to parse the response of the leaked password server: %s", err))
+	}
+	return count, nil
+}
+
+// readRange reads a range of the Pwned Passwords dataset, caches the counts of
+// all hashes in the range, and returns the count of hpw.
+func (s *DefaultPasswordValidator) readRange(prefix string, r io.Reader, hpw []byte) (int64, error) {
 	var thisCount int64
 
-	sc := bufio.NewScanner(res.Body)
+	sc := bufio.NewScanner(r)
 	for sc.Scan() {
-		row := sc.Text()
-		result := strings.Split(strings.TrimSpace(row), ":")
-
-		// We assume a count of 1. HIBP API sometimes responds without the
-		// colon, so we just assume that the leak count is one.
-		//
-		// See https://github.com/ory/kratos/issues/2145
-		count := int64(1)
-		if len(result) == 2 {
-			count, err = strconv.ParseInt(strings.ReplaceAll(result[1], ",", ""), 10, 64)
-			if err != nil {
-				return 0, errors.WithStack(herodot.ErrUpstreamError.WithReasonf("Expected password hash to contain a count formatted as int but got: %s", result[1]))
-			}
+		suffix, count, err := parseRangeLine(sc.Text())
+		if err != nil {
+			return 0, err
+		} else if suffix == "" {
+			continue
 		}
 
-		s.hashes.SetWithTTL(prefix+result[0], count, 1, hashCacheItemTTL)
-		if prefix+result[0] == b20(hpw) {
+		s.hashes.SetWithTTL(prefix+suffix, count, 1, hashCacheItemTTL)
+		if prefix+suffix == b20(hpw) {
 			thisCount = count
 		}
 	}
 
 	if err := sc.Err(); err != nil {
-		return 0, errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to initialize string scanner: %s", err))
+		return 0, errors.WithStack(err)
 	}
 
 	s.hashes.SetWithTTL(b20(hpw), thisCount, 1, hashCacheItemTTL)
 	return thisCount, nil
 }
 
+// parseRangeLine parses a line of a range of the Pwned Passwords dataset of
+// the form "<hash suffix>:<count>". It returns an empty suffix for empty
+// lines.
+func parseRangeLine(line string) (string, int64, error) {
+	result := strings.Split(strings.TrimSpace(line), ":")
+	if result[0] == "" {
+		return "", 0, nil
+	}
+
+	// We assume a count of 1. HIBP API sometimes responds without the
+	// colon, so we just assume that the leak count is one.
+	//
+	// See https://github.com/ory/kratos/issues/2145
+	count := int64(1)
+	if len(result) == 2 {
+		var err error
+		count, err = strconv.ParseInt(strings.ReplaceAll(result[1], ",", ""), 10, 64)
+		if err != nil {
+			return "", 0, errors.Errorf("expected password hash to contain a count formatted as int but got: %s", result[1])
+		}
+	}
+	return strings.ToUpper(result[0]), count, nil
+}
+
+func decodeHashHex(h string) ([]byte, error) {
+	hash, err := hex.DecodeString(h)
+	if err != nil || len(hash) != sha1.Size {
+		return nil, errors.Errorf("expected a hex-encoded SHA-1 hash but got: %s", h)
+	}
+	return hash, nil
+}
+
 func (s *DefaultPasswordValidator) Validate(ctx context.Context, identifier, password string) error {
 	return otelx.WithSpan(ctx, "password.DefaultPasswordValidator.Validate", func(ctx context.Context) error {
 		return s.validate(ctx, identifier, password)
@@ -181,6 +245,20 @@ func (s *DefaultPasswordValidator) validate(ctx context.Context, identifier, pas
 		return text.NewErrorValidationPasswordMinLength(int(passwordPolicyConfig.MinPasswordLength), len(password))
 	}
 
+	//nolint:gosec // disable G115
+	if passwordPolicyConfig.MaxPasswordLength > 0 && len(password) > int(passwordPolicyConfig.MaxPasswordLength) {
+		//nolint:gosec // disable G115
+		return text.NewErrorValidationPasswordMaxLength(int(passwordPolicyConfig.MaxPasswordLength), len(password))
+	}
+
+	if err := validateCharacterClasses(passwordPolicyConfig, password); err != nil {
+		return err
+	}
+
+	if err := s.validateBannedWords(ctx, passwordPolicyConfig, password); err != nil {
+		return err
+	}
+
 	if passwordPolicyConfig.IdentifierSimilarityCheckEnabled && len(identifier) > 0 {
 		compIdentifier, compPassword := strings.ToLower(identifier), strings.ToLower(password)
 		dist := levenshtein.Distance(compIdentifier, compPassword)
@@ -190,6 +268,14 @@ func (s *DefaultPasswordValidator) validate(ctx context.Context, identifier, pas
 		}
 	}
 
+	if passwordPolicyConfig.MinStrengthScore > 0 {
+		//nolint:gosec // disable G115
+		if score := passwordStrength(password, identifier); score < int(passwordPolicyConfig.MinStrengthScore) {
+			//nolint:gosec // disable G115
+			return text.NewErrorValidationPasswordTooWeak(int(passwordPolicyConfig.MinStrengthScore), score)
+		}
+	}
+
 	if !passwordPolicyConfig.HaveIBeenPwnedEnabled {
 		return nil
 	}
@@ -201,6 +287,10 @@ func (s *DefaultPasswordValidator) validate(ctx context.Context, identifier, pas
 	}
 	hpw := h.Sum(nil)
 
+	if passwordPolicyConfig.HaveIBeenPwnedDataset != "" {
+		return s.validateDataset(passwordPolicyConfig, hpw)
+	}
+
 	c, ok := s.hashes.Get(b20(hpw))
 	if !ok {
 		var err error
@@ -219,3 +309,149 @@ func (s *DefaultPasswordValidator) validate(ctx context.Context, identifier, pas
 
 	return nil
 }
+
+// validateDataset checks the password against a locally provisioned dataset
+// instead of the haveibeenpwned API. The dataset is either a directory with
+// one file per range of the Pwned Passwords dataset, named after the hash
+// prefix of the range (e.g. "21BD1.txt"), or a breach filter file. Errors
+// reading the dataset are never ignored.
+func (s *DefaultPasswordValidator) validateDataset(policy *config.PasswordPolicy, hpw []byte) error {
+	info, err := os.Stat(policy.HaveIBeenPwnedDataset)
+	if err != nil {
+		return errors.WithStack(herodot.ErrMisconfiguration.WithWrap(err).WithReasonf("Unable to open the leaked password dataset: %s", err))
+	}
+
+	if !info.IsDir() {
+		f, err := s.loadBreachFilter(policy.HaveIBeenPwnedDataset, info)
+		if err != nil {
+			return err
+		}
+		if f.Contains(hpw) {
+			//nolint:gosec // disable G115
+			return text.NewErrorValidationPasswordTooManyBreaches(int64(max(f.MinCount, 1)))
+		}
+		return nil
+	}
+
+	c, ok := s.hashes.Get(b20(hpw))
+	if !ok {
+		prefix := b20(hpw)[0:5]
+		file, err := os.Open(filepath.Join(policy.HaveIBeenPwnedDataset, prefix+".txt"))
+		if err != nil {
+			return errors.WithStack(herodot.ErrMisconfiguration.WithWrap(err).WithReasonf("Unable to open the range %s of the leaked password dataset: %s", prefix, err))
+		}
+		defer func() { _ = file.Close() }()
+
+		c, err = s.readRange(prefix, file, hpw)
+		if err != nil {
+			return errors.WithStack(herodot.ErrMisconfiguration.WithWrap(err).WithReasonf("Unable to read the range %s of the leaked password dataset: %s", prefix, err))
+		}
+	}
+
+	//nolint:gosec // disable G115
+	if c > int64(policy.MaxBreaches) {
+		return text.NewErrorValidationPasswordTooManyBreaches(c)
+	}
+	return nil
+}
+
+// loadBreachFilter returns the breach filter at path. The filter is kept in
+// memory and reloaded when the file changes.
+func (s *DefaultPasswordValidator) loadBreachFilter(path string, info os.FileInfo) (*BreachFilter, error) {
+	s.filterMu.Lock()
+	defer s.filterMu.Unlock()
+
+	if s.filter != nil && s.filterPath == path && s.filterModTime.Equal(info.ModTime()) {
+		return s.filter, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithWrap(err).WithReasonf("Unable to open the leaked password dataset: %s", err))
+	}
+	defer func() { _ = file.Close() }()
+
+	f, err := ReadBreachFilter(bufio.NewReader(file))
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithWrap(err).WithReasonf("Unable to read the leaked password dataset: %s", err))
+	}
+
+	s.filter, s.filterPath, s.filterModTime = f, path, info.ModTime()
+	return f, nil
+}
+
+const (
+	characterClassLowercase = "lowercase"
+	characterClassUppercase = "uppercase"
+	characterClassDigit     = "digit"
+	characterClassSymbol    = "symbol"
+)
+
+// characterClasses returns the character classes of the password. Letters
+// without case, for example CJK characters, do not belong to any class.
+func characterClasses(password string) map[string]bool {
+	classes := make(map[string]bool, 4)
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			classes[characterClassLowercase] = true
+		case unicode.IsUpper(r):
+			classes[characterClassUppercase] = true
+		case unicode.IsDigit(r):
+			classes[characterClassDigit] = true
+		case !unicode.IsLetter(r):
+			classes[characterClassSymbol] = true
+		}
+	}
+	return classes
+}
+
+func validateCharacterClasses(policy *config.PasswordPolicy, password string) error {
+	if len(policy.RequiredCharacterClasses) == 0 && policy.MinCharacterClasses == 0 {
+		return nil
+	}
+
+	classes := characterClasses(password)
+	for _, class := range policy.RequiredCharacterClasses {
+		if !classes[class] {
+			return text.NewErrorValidationPasswordMissingCharacterClass(class)
+		}
+	}
+
+	//nolint:gosec // disable G115
+	if len(classes) < int(policy.MinCharacterClasses) {
+		//nolint:gosec // disable G115
+		return text.NewErrorValidationPasswordMinCharacterClasses(int(policy.MinCharacterClasses), len(classes))
+	}
+	return nil
+}
+
+func (s *DefaultPasswordValidator) validateBannedWords(ctx context.Context, policy *config.PasswordPolicy, password string) error {
+	if len(policy.BannedWordsURLs) == 0 {
+		return nil
+	}
+
+	compPassword := strings.ToLower(password)
+	f := fetcher.NewFetcher(fetcher.WithClient(s.reg.HTTPClient(ctx)), fetcher.WithCache(s.bannedWords, hashCacheItemTTL))
+	for _, source := range policy.BannedWordsURLs {
+		words, err := f.FetchBytes(ctx, source)
+		if err != nil {
+			return errors.WithStack(herodot.ErrMisconfiguration.WithWrap(err).WithReasonf("Unable to fetch the banned words from %s: %s", source, err))
+		}
+
+		sc := bufio.NewScanner(bytes.NewReader(words))
+		for sc.Scan() {
+			word := strings.ToLower(strings.TrimSpace(sc.Text()))
+			if len(word) == 0 || strings.HasPrefix(word, "#") {
+				continue
+			}
+			if strings.Contains(compPassword, word) {
+				return text.NewErrorValidationPasswordBannedWord()
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return errors.WithStack(herodot.ErrMisconfiguration.WithWrap(err).WithReasonf("Unable to read the banned words from %s: %s", source, err))
+		}
+	}
+	return nil
+}
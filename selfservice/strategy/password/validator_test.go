@@ -8,12 +8,16 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/sha1" //#nosec G505 -- compatibility for imported passwords
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -326,6 +330,138 @@ func TestChangeIdentifierSimilarityCheckEnabled(t *testing.T) {
 	})
 }
 
+func TestHaveIBeenPwnedDataset(t *testing.T) {
+	ctx := context.Background()
+
+	hashOf := func(pw string) string {
+		return fmt.Sprintf("%X", sha1.Sum([]byte(pw))) //#nosec G401 -- sha1 is used for k-anonymity
+	}
+	breached := hashOf("damrumukuh")
+
+	newValidator := func(t *testing.T, dataset string) (*password.DefaultPasswordValidator, *fakeHttpClient) {
+		conf, reg := internal.NewFastRegistryWithMocks(t)
+		conf.MustSet(ctx, config.ViperKeyPasswordHaveIBeenPwnedDataset, dataset)
+		conf.MustSet(ctx, config.ViperKeyIgnoreNetworkErrors, true)
+		s, err := password.NewDefaultPasswordValidatorStrategy(reg)
+		require.NoError(t, err)
+
+		fakeClient := NewFakeHTTPClient()
+		s.Client = httpx.NewResilientClient(httpx.ResilientClientWithMaxRetry(1), httpx.ResilientClientWithConnectionTimeout(time.Millisecond))
+		s.Client.HTTPClient = &fakeClient.Client
+		return s, fakeClient
+	}
+
+	t.Run("case=range directory", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, breached[:5]+".txt"), []byte("0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n"+breached[5:]+":5\r\n"), 0o600))
+		s, fakeClient := newValidator(t, dir)
+
+		err := s.Validate(ctx, "", "damrumukuh")
+		var message *text.Message
+		require.ErrorAs(t, err, &message)
+		assert.Equal(t, text.ErrorValidationPasswordTooManyBreaches, message.ID)
+
+		err = s.Validate(ctx, "", "kuobahcaas")
+		require.ErrorIs(t, err, herodot.ErrMisconfiguration, "the range of the password is missing and must not be ignored")
+
+		require.Empty(t, fakeClient.RequestedURLs())
+	})
+
+	t.Run("case=breach filter", func(t *testing.T) {
+		f, err := password.NewBreachFilter(10, 0.001, 1)
+		require.NoError(t, err)
+		_, err = f.AddRange(breached[:5], strings.NewReader(breached[5:]+":5\n"))
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "breaches.bin")
+		var buf bytes.Buffer
+		_, err = f.WriteTo(&buf)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+		s, fakeClient := newValidator(t, path)
+
+		err = s.Validate(ctx, "", "damrumukuh")
+		var message *text.Message
+		require.ErrorAs(t, err, &message)
+		assert.Equal(t, text.ErrorValidationPasswordTooManyBreaches, message.ID)
+
+		require.NoError(t, s.Validate(ctx, "", "kuobahcaas"))
+		require.Empty(t, fakeClient.RequestedURLs())
+	})
+
+	t.Run("case=missing dataset", func(t *testing.T) {
+		s, _ := newValidator(t, filepath.Join(t.TempDir(), "does-not-exist"))
+		require.ErrorIs(t, s.Validate(ctx, "", "kuobahcaas"), herodot.ErrMisconfiguration)
+	})
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	s, err := password.NewDefaultPasswordValidatorStrategy(reg)
+	require.NoError(t, err)
+	conf.MustSet(ctx, config.ViperKeyPasswordHaveIBeenPwnedEnabled, false)
+
+	assertMessage := func(t *testing.T, err error, id text.ID) {
+		var message *text.Message
+		require.ErrorAs(t, err, &message)
+		assert.Equal(t, id, message.ID)
+	}
+
+	t.Run("case=max length", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyPasswordMaxLength, 12)
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeyPasswordMaxLength, 0) })
+
+		require.NoError(t, s.Validate(ctx, "", "kuobahcaasxy"))
+		assertMessage(t, s.Validate(ctx, "", "kuobahcaasxyz"), text.ErrorValidationPasswordMaxLength)
+	})
+
+	t.Run("case=required character classes", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyPasswordRequiredCharacterClasses, []string{"uppercase", "digit"})
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeyPasswordRequiredCharacterClasses, []string{}) })
+
+		require.NoError(t, s.Validate(ctx, "", "kuobAhcaas7"))
+		assertMessage(t, s.Validate(ctx, "", "kuobahcaas7"), text.ErrorValidationPasswordMissingCharacterClass)
+		assertMessage(t, s.Validate(ctx, "", "kuobAhcaasx"), text.ErrorValidationPasswordMissingCharacterClass)
+	})
+
+	t.Run("case=min character classes", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyPasswordMinCharacterClasses, 3)
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeyPasswordMinCharacterClasses, 0) })
+
+		require.NoError(t, s.Validate(ctx, "", "kuobahcaas7!"))
+		require.NoError(t, s.Validate(ctx, "", "kuobAhcaas7"))
+		assertMessage(t, s.Validate(ctx, "", "kuobahcaas7"), text.ErrorValidationPasswordMinCharacterClasses)
+	})
+
+	t.Run("case=banned words", func(t *testing.T) {
+		words := "# company specific words\n\nAcme\nroadrunner\n"
+		conf.MustSet(ctx, config.ViperKeyPasswordBannedWordsURLs, []string{"base64://" + base64.StdEncoding.EncodeToString([]byte(words))})
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeyPasswordBannedWordsURLs, []string{}) })
+
+		require.NoError(t, s.Validate(ctx, "", "kuobahcaas"))
+		assertMessage(t, s.Validate(ctx, "", "kuobACMEcaas"), text.ErrorValidationPasswordBannedWord)
+		assertMessage(t, s.Validate(ctx, "", "RoadRunner42"), text.ErrorValidationPasswordBannedWord)
+	})
+
+	t.Run("case=banned words list can not be fetched", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyPasswordBannedWordsURLs, []string{"file:///does/not/exist"})
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeyPasswordBannedWordsURLs, []string{}) })
+
+		err := s.Validate(ctx, "", "kuobahcaas")
+		require.ErrorIs(t, err, herodot.ErrMisconfiguration)
+	})
+
+	t.Run("case=min strength score", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeyPasswordMinStrengthScore, 3)
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeyPasswordMinStrengthScore, 0) })
+
+		require.NoError(t, s.Validate(ctx, "", "correct horse battery staple"))
+		assertMessage(t, s.Validate(ctx, "", "password123"), text.ErrorValidationPasswordTooWeak)
+		assertMessage(t, s.Validate(ctx, "", "qwertyuiop1234"), text.ErrorValidationPasswordTooWeak)
+	})
+}
+
 type fakeValidatorAPI struct{}
 
 func (api *fakeValidatorAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,371 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package saml
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+	"github.com/dgraph-io/ristretto/v2"
+	"github.com/pkg/errors"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/fetcher"
+	"github.com/ory/x/urlx"
+
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+)
+
+// callbackCodeLifespan is how long the claims handed from the assertion
+// consumer service to the callback remain valid.
+const callbackCodeLifespan = 5 * time.Minute
+
+var metadataCache, _ = ristretto.NewCache(&ristretto.Config[[]byte, []byte]{
+	MaxCost:     50 << 20, // 50MB,
+	NumCounters: 50_000,   // 1kB per snippet -> 50k snippets
+	BufferItems: 64,
+})
+
+var nameIDFormats = map[string]saml.NameIDFormat{
+	"":            saml.UnspecifiedNameIDFormat,
+	"unspecified": saml.UnspecifiedNameIDFormat,
+	"transient":   saml.TransientNameIDFormat,
+	"persistent":  saml.PersistentNameIDFormat,
+	"email":       saml.EmailAddressNameIDFormat,
+}
+
+// Well-known attribute names used by common identity providers. The first
+// attribute that is present in the assertion wins.
+var (
+	emailAttributes = []string{
+		"email", "mail", "emailAddress",
+		"urn:oid:0.9.2342.19200300.100.1.3",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+	}
+	nameAttributes = []string{
+		"name", "displayName", "cn",
+		"urn:oid:2.16.840.1.113730.3.1.241",
+		"urn:oid:2.5.4.3",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name",
+	}
+	givenNameAttributes = []string{
+		"given_name", "givenName", "firstName",
+		"urn:oid:2.5.4.42",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname",
+	}
+	familyNameAttributes = []string{
+		"family_name", "sn", "surname", "lastName",
+		"urn:oid:2.5.4.4",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/surname",
+	}
+)
+
+// Configuration is the configuration of a single SAML identity provider.
+type Configuration struct {
+	// ID is the provider's ID.
+	ID string `json:"id"`
+
+	// Label represents an optional label which can be used in the UI generation.
+	Label string `json:"label"`
+
+	// Mapper specifies the JSONNet code snippet which uses the SAML
+	// assertion's attributes to map them to the identity's traits.
+	Mapper string `json:"mapper_url"`
+
+	// OrganizationID is the ID of the organization this provider belongs to.
+	OrganizationID string `json:"organization_id,omitempty"`
+
+	// IDPMetadataURL points to the identity provider's SAML metadata. Supports
+	// http(s)://, file:// and base64:// URLs.
+	IDPMetadataURL string `json:"idp_metadata_url"`
+
+	// EntityID is the entity ID of this service provider. Defaults to the URL
+	// of the service provider metadata endpoint.
+	EntityID string `json:"entity_id"`
+
+	// SPCertificateURL points to the PEM encoded certificate of this service
+	// provider. Required when signing requests or receiving encrypted assertions.
+	SPCertificateURL string `json:"sp_certificate_url"`
+
+	// SPPrivateKeyURL points to the PEM encoded private key of this service
+	// provider.
+	SPPrivateKeyURL string `json:"sp_private_key_url"`
+
+	// SignAuthnRequests signs the authentication requests sent to the identity
+	// provider with the service provider's private key.
+	SignAuthnRequests bool `json:"sign_authn_requests"`
+
+	// NameIDFormat is the name ID format requested from the identity provider.
+	// One of `unspecified`, `transient`, `persistent`, or `email`.
+	NameIDFormat string `json:"name_id_format"`
+
+	// SubjectAttribute is the name of the assertion attribute used as the
+	// subject. Defaults to the assertion's name ID.
+	SubjectAttribute string `json:"subject_attribute"`
+}
+
+// ConfigurationCollection is the configuration of the SAML method.
+type ConfigurationCollection struct {
+	BaseRedirectURI string          `json:"base_redirect_uri"`
+	Providers       []Configuration `json:"providers"`
+}
+
+var _ oidc.RedirectProvider = (*Provider)(nil)
+
+// Provider is a SAML 2.0 identity provider as seen from Ory Kratos acting as
+// the service provider.
+type Provider struct {
+	config *Configuration
+	reg    oidc.Dependencies
+}
+
+func NewProvider(config *Configuration, reg oidc.Dependencies) *Provider {
+	return &Provider{config: config, reg: reg}
+}
+
+func (p *Provider) Config() *oidc.Configuration {
+	return &oidc.Configuration{
+		ID:             p.config.ID,
+		Provider:       "saml",
+		Label:          p.config.Label,
+		Mapper:         p.config.Mapper,
+		OrganizationID: p.config.OrganizationID,
+	}
+}
+
+func (p *Provider) routeURL(ctx context.Context, route string) *url.URL {
+	return urlx.AppendPaths(p.reg.Config().SAMLRedirectURIBase(ctx), route)
+}
+
+func (p *Provider) fetch(ctx context.Context, source string, cache bool) ([]byte, error) {
+	opts := []fetcher.Modifier{fetcher.WithClient(p.reg.HTTPClient(ctx))}
+	if cache {
+		opts = append(opts, fetcher.WithCache(metadataCache, 10*time.Minute))
+	}
+	return fetcher.NewFetcher(opts...).FetchBytes(ctx, source)
+}
+
+func (p *Provider) keyPair(ctx context.Context) (crypto.Signer, *x509.Certificate, error) {
+	if p.config.SPCertificateURL == "" && p.config.SPPrivateKeyURL == "" {
+		return nil, nil, nil
+	}
+
+	certPEM, err := p.fetch(ctx, p.config.SPCertificateURL, false)
+	if err != nil {
+		return nil, nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Unable to load the service provider certificate of SAML provider %q: %s", p.config.ID, err))
+	}
+	keyPEM, err := p.fetch(ctx, p.config.SPPrivateKeyURL, false)
+	if err != nil {
+		return nil, nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Unable to load the service provider private key of SAML provider %q: %s", p.config.ID, err))
+	}
+
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Unable to parse the service provider key pair of SAML provider %q: %s", p.config.ID, err))
+	}
+
+	signer, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("The service provider private key of SAML provider %q can not be used for signing.", p.config.ID))
+	}
+
+	return signer, pair.Leaf, nil
+}
+
+// ServiceProvider returns the SAML service provider for this identity
+// provider. Metadata and keys are loaded lazily so that a broken provider does
+// not affect the others.
+func (p *Provider) ServiceProvider(ctx context.Context, withIDPMetadata bool) (*saml.ServiceProvider, error) {
+	format, ok := nameIDFormats[p.config.NameIDFormat]
+	if !ok {
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Name ID format %q of SAML provider %q is not supported.", p.config.NameIDFormat, p.config.ID))
+	}
+
+	key, cert, err := p.keyPair(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sp := &saml.ServiceProvider{
+		EntityID:          p.config.EntityID,
+		Key:               key,
+		Certificate:       cert,
+		HTTPClient:        p.reg.HTTPClient(ctx).StandardClient(),
+		MetadataURL:       *p.routeURL(ctx, RouteMetadataBase+"/"+p.config.ID),
+		AcsURL:            *p.routeURL(ctx, RouteACSBase+"/"+p.config.ID),
+		AuthnNameIDFormat: format,
+	}
+
+	if p.config.SignAuthnRequests {
+		if key == nil {
+			return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("SAML provider %q is configured to sign authentication requests but has no service provider key pair.", p.config.ID))
+		}
+		sp.SignatureMethod = dsig.RSASHA256SignatureMethod
+		if _, ok := key.(*ecdsa.PrivateKey); ok {
+			sp.SignatureMethod = dsig.ECDSASHA256SignatureMethod
+		}
+	}
+
+	if !withIDPMetadata {
+		return sp, nil
+	}
+
+	raw, err := p.fetch(ctx, p.config.IDPMetadataURL, true)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrUpstreamError.WithReasonf("Unable to fetch the metadata of SAML provider %q: %s", p.config.ID, err))
+	}
+	sp.IDPMetadata, err = samlsp.ParseMetadata(raw)
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrUpstreamError.WithReasonf("Unable to parse the metadata of SAML provider %q: %s", p.config.ID, err))
+	}
+
+	return sp, nil
+}
+
+// requestID derives the ID of the authentication request from the state, so
+// that responses can be bound to the flow without storing the request.
+func requestID(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return "id-" + hex.EncodeToString(sum[:])
+}
+
+func (p *Provider) AuthURL(ctx context.Context, state string) (string, error) {
+	sp, err := p.ServiceProvider(ctx, true)
+	if err != nil {
+		return "", err
+	}
+
+	location := sp.GetSSOBindingLocation(saml.HTTPRedirectBinding)
+	if location == "" {
+		return "", errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("SAML provider %q does not support the HTTP-Redirect binding.", p.config.ID))
+	}
+
+	req, err := sp.MakeAuthenticationRequest(location, saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		return "", errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to create SAML authentication request: %s", err))
+	}
+	req.ID = requestID(state)
+
+	u, err := req.Redirect(url.QueryEscape(state), sp)
+	if err != nil {
+		return "", errors.WithStack(herodot.ErrInternalServerError.WithReasonf("Unable to create SAML authentication request: %s", err))
+	}
+
+	return u.String(), nil
+}
+
+// Claims maps a validated assertion to claims.
+func (p *Provider) Claims(assertion *saml.Assertion) *oidc.Claims {
+	raw := map[string]interface{}{}
+	attributes := map[string][]string{}
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			values := make([]string, 0, len(attr.Values))
+			for _, v := range attr.Values {
+				values = append(values, v.Value)
+			}
+			attributes[attr.Name] = append(attributes[attr.Name], values...)
+			if attr.FriendlyName != "" && attr.FriendlyName != attr.Name {
+				attributes[attr.FriendlyName] = append(attributes[attr.FriendlyName], values...)
+			}
+		}
+	}
+	for k, v := range attributes {
+		raw[k] = v
+	}
+
+	first := func(names []string) string {
+		for _, name := range names {
+			if v := attributes[name]; len(v) > 0 {
+				return v[0]
+			}
+		}
+		return ""
+	}
+
+	claims := &oidc.Claims{
+		Name:       first(nameAttributes),
+		GivenName:  first(givenNameAttributes),
+		FamilyName: first(familyNameAttributes),
+		Email:      first(emailAttributes),
+		RawClaims:  raw,
+	}
+
+	if assertion.Issuer.Value != "" {
+		claims.Issuer = assertion.Issuer.Value
+	}
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		claims.Subject = assertion.Subject.NameID.Value
+		raw["name_id"] = assertion.Subject.NameID.Value
+		raw["name_id_format"] = assertion.Subject.NameID.Format
+	}
+	if p.config.SubjectAttribute != "" {
+		claims.Subject = first([]string{p.config.SubjectAttribute})
+	}
+	for _, statement := range assertion.AuthnStatements {
+		if statement.SessionIndex != "" {
+			raw["session_index"] = statement.SessionIndex
+			break
+		}
+	}
+
+	return claims
+}
+
+type callbackCode struct {
+	StateSHA256 []byte       `json:"state_sha256"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	Claims      *oidc.Claims `json:"claims"`
+}
+
+// CallbackCode encrypts the claims into a short-lived code bound to the state.
+func (p *Provider) CallbackCode(ctx context.Context, state string, claims *oidc.Claims) (string, error) {
+	sum := sha256.Sum256([]byte(state))
+	raw, err := json.Marshal(&callbackCode{
+		StateSHA256: sum[:],
+		ExpiresAt:   time.Now().UTC().Add(callbackCodeLifespan),
+		Claims:      claims,
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return p.reg.Cipher(ctx).Encrypt(ctx, raw)
+}
+
+func (p *Provider) CallbackClaims(ctx context.Context, query url.Values) (*oidc.Claims, error) {
+	plaintext, err := p.reg.Cipher(ctx).Decrypt(ctx, query.Get("code"))
+	if err != nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReason("Unable to complete SAML flow because the code parameter is invalid."))
+	}
+
+	var code callbackCode
+	if err := json.Unmarshal(plaintext, &code); err != nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReason("Unable to complete SAML flow because the code parameter is invalid."))
+	}
+
+	sum := sha256.Sum256([]byte(query.Get("state")))
+	if subtle.ConstantTimeCompare(code.StateSHA256, sum[:]) != 1 {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReason("Unable to complete SAML flow because the code parameter does not belong to this flow."))
+	}
+	if time.Now().After(code.ExpiresAt) {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReason("Unable to complete SAML flow because the code parameter expired. Please try again."))
+	}
+	if code.Claims == nil {
+		return nil, errors.WithStack(herodot.ErrBadRequest.WithReason("Unable to complete SAML flow because the code parameter is invalid."))
+	}
+
+	return code.Claims, nil
+}
@@ -0,0 +1,86 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package saml_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	crewjam "github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/herodot"
+
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/kratos/selfservice/strategy/saml"
+)
+
+func TestProviderClaims(t *testing.T) {
+	_, reg := internal.NewVeryFastRegistryWithoutDB(t)
+
+	assertion := &crewjam.Assertion{
+		Issuer:  crewjam.Issuer{Value: "https://idp.example.org"},
+		Subject: &crewjam.Subject{NameID: &crewjam.NameID{Value: "jane", Format: string(crewjam.PersistentNameIDFormat)}},
+		AttributeStatements: []crewjam.AttributeStatement{{Attributes: []crewjam.Attribute{
+			{Name: "urn:oid:0.9.2342.19200300.100.1.3", FriendlyName: "mail", Values: []crewjam.AttributeValue{{Value: "jane@example.org"}}},
+			{Name: "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname", Values: []crewjam.AttributeValue{{Value: "Jane"}}},
+			{Name: "employeeNumber", Values: []crewjam.AttributeValue{{Value: "1234"}}},
+			{Name: "groups", Values: []crewjam.AttributeValue{{Value: "admins"}, {Value: "users"}}},
+		}}},
+		AuthnStatements: []crewjam.AuthnStatement{{SessionIndex: "session-index"}},
+	}
+
+	t.Run("case=maps well-known attributes", func(t *testing.T) {
+		claims := saml.NewProvider(&saml.Configuration{ID: "acme"}, reg).Claims(assertion)
+		require.NoError(t, claims.Validate())
+
+		assert.Equal(t, "https://idp.example.org", claims.Issuer)
+		assert.Equal(t, "jane", claims.Subject)
+		assert.Equal(t, "jane@example.org", claims.Email)
+		assert.Equal(t, "Jane", claims.GivenName)
+		assert.Equal(t, []string{"jane@example.org"}, claims.RawClaims["mail"])
+		assert.Equal(t, []string{"admins", "users"}, claims.RawClaims["groups"])
+		assert.Equal(t, "session-index", claims.RawClaims["session_index"])
+		assert.Equal(t, string(crewjam.PersistentNameIDFormat), claims.RawClaims["name_id_format"])
+	})
+
+	t.Run("case=uses the subject attribute", func(t *testing.T) {
+		claims := saml.NewProvider(&saml.Configuration{ID: "acme", SubjectAttribute: "employeeNumber"}, reg).Claims(assertion)
+		assert.Equal(t, "1234", claims.Subject)
+	})
+}
+
+func TestProviderCallbackCode(t *testing.T) {
+	ctx := context.Background()
+	_, reg := internal.NewVeryFastRegistryWithoutDB(t)
+	p := saml.NewProvider(&saml.Configuration{ID: "acme"}, reg)
+	expected := oidc.Claims{
+		Issuer:    "https://idp.example.org",
+		Subject:   "jane",
+		Email:     "jane@example.org",
+		RawClaims: map[string]interface{}{"groups": []interface{}{"admins"}},
+	}
+
+	code, err := p.CallbackCode(ctx, "state", &expected)
+	require.NoError(t, err)
+
+	t.Run("case=returns the claims", func(t *testing.T) {
+		claims, err := p.CallbackClaims(ctx, url.Values{"state": {"state"}, "code": {code}})
+		require.NoError(t, err)
+		assert.Equal(t, expected, *claims)
+	})
+
+	t.Run("case=rejects codes of other flows", func(t *testing.T) {
+		_, err := p.CallbackClaims(ctx, url.Values{"state": {"other-state"}, "code": {code}})
+		require.ErrorIs(t, err, herodot.ErrBadRequest)
+	})
+
+	t.Run("case=rejects invalid codes", func(t *testing.T) {
+		_, err := p.CallbackClaims(ctx, url.Values{"state": {"state"}, "code": {"not-a-code"}})
+		require.ErrorIs(t, err, herodot.ErrBadRequest)
+	})
+}
@@ -0,0 +1,220 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package saml
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+
+	"github.com/crewjam/saml"
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/urlx"
+
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/selfservice/flow"
+	"github.com/ory/kratos/selfservice/strategy"
+	"github.com/ory/kratos/selfservice/strategy/oidc"
+	"github.com/ory/kratos/x"
+)
+
+const (
+	RouteBase = "/self-service/methods/saml"
+
+	RouteMetadataBase = RouteBase + "/metadata"
+	RouteMetadata     = RouteMetadataBase + "/{provider}"
+	RouteACSBase      = RouteBase + "/acs"
+	RouteACS          = RouteACSBase + "/{provider}"
+	RouteCallbackBase = RouteBase + "/callback"
+	RouteCallback     = RouteCallbackBase + "/{provider}"
+)
+
+// Strategy implements login, registration and settings using SAML 2.0
+// identity providers. Everything after the assertion has been validated is
+// shared with the OpenID Connect strategy, including the Jsonnet claims
+// mapping and account linking.
+type Strategy struct {
+	*oidc.Strategy
+	d oidc.Dependencies
+}
+
+func NewStrategy(d oidc.Dependencies) *Strategy {
+	s := &Strategy{d: d}
+	notResponsible := func(err error) error {
+		if errors.Is(err, herodot.ErrNotFound) {
+			return errors.WithStack(flow.ErrStrategyNotResponsible)
+		}
+		return err
+	}
+	s.Strategy = oidc.NewStrategy(d,
+		oidc.ForCredentialType(identity.CredentialsTypeSAML),
+		oidc.WithProviderFactory(func(ctx context.Context, id string) (oidc.Provider, error) {
+			p, err := s.provider(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			return p, nil
+		}),
+		oidc.WithUnknownProviderHandler(notResponsible),
+		oidc.WithHandleMethodNotAllowedError(notResponsible),
+	)
+	return s
+}
+
+func (s *Strategy) config(ctx context.Context) (*ConfigurationCollection, error) {
+	var c ConfigurationCollection
+
+	conf := s.d.Config().SelfServiceStrategy(ctx, s.ID().String()).Config
+	if err := json.NewDecoder(bytes.NewBuffer(conf)).Decode(&c); err != nil {
+		s.d.Logger().WithError(err).WithField("config", conf)
+		return nil, errors.WithStack(herodot.ErrMisconfiguration.WithReasonf("Unable to decode SAML Provider configuration: %s", err))
+	}
+
+	return &c, nil
+}
+
+func (s *Strategy) provider(ctx context.Context, id string) (*Provider, error) {
+	c, err := s.config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for k := range c.Providers {
+		if c.Providers[k].ID == id {
+			return NewProvider(&c.Providers[k], s.d), nil
+		}
+	}
+
+	return nil, errors.WithStack(herodot.ErrNotFound.WithReasonf(`SAML Provider "%s" is unknown or has not been configured`, id))
+}
+
+func (s *Strategy) setRoutes(r *x.RouterPublic) {
+	if !r.HasRoute("GET", RouteMetadata) {
+		r.GET(RouteMetadata, strategy.IsDisabled(s.d, s.ID().String(), s.handleMetadata))
+	}
+
+	if !r.HasRoute("POST", RouteACS) {
+		// The identity provider posts the response from its own origin. The
+		// response is protected by its signature and bound to the flow through
+		// the request ID and relay state instead.
+		s.d.CSRFHandler().IgnoreGlob(RouteACSBase + "/*")
+		r.POST(RouteACS, strategy.IsDisabled(s.d, s.ID().String(), s.handleACS))
+	}
+
+	if !r.HasRoute("GET", RouteCallback) {
+		r.GET(RouteCallback, strategy.IsDisabled(s.d, s.ID().String(), s.HandleCallback))
+	}
+}
+
+func (s *Strategy) RegisterLoginRoutes(r *x.RouterPublic) {
+	s.setRoutes(r)
+}
+
+func (s *Strategy) RegisterRegistrationRoutes(r *x.RouterPublic) {
+	s.setRoutes(r)
+}
+
+func (s *Strategy) RegisterSettingsRoutes(r *x.RouterPublic) {
+	s.setRoutes(r)
+}
+
+func (s *Strategy) handleMetadata(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	provider, err := s.provider(ctx, r.PathValue("provider"))
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	sp, err := provider.ServiceProvider(ctx, false)
+	if err != nil {
+		s.d.Writer().WriteError(w, r, err)
+		return
+	}
+
+	metadata, err := xml.MarshalIndent(sp.Metadata(), "", "  ")
+	if err != nil {
+		s.d.Writer().WriteError(w, r, errors.WithStack(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	_, _ = w.Write(metadata)
+}
+
+// handleACS validates the response of the identity provider and hands the
+// resulting claims over to the callback. The response is posted cross-site,
+// which is why the session cookies are only available after redirecting the
+// browser to the callback.
+func (s *Strategy) handleACS(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, errors.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to parse SAML response: %s", err)))
+		return
+	}
+
+	stateParam := r.PostForm.Get("RelayState")
+	if stateParam == "" {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, errors.WithStack(herodot.ErrBadRequest.WithReason("Unable to complete SAML flow because the identity provider did not return the relay state. Identity provider initiated logins are not supported.")))
+		return
+	}
+
+	state, err := oidc.DecryptState(ctx, s.d.Cipher(ctx), stateParam)
+	if err != nil {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, errors.WithStack(herodot.ErrBadRequest.WithReason("Unable to complete SAML flow because the relay state is invalid.")))
+		return
+	}
+
+	providerID := r.PathValue("provider")
+	if state.ProviderId != providerID {
+		s.d.SelfServiceErrorManager().Forward(ctx, w, r, errors.WithStack(herodot.ErrBadRequest.WithReason("Unable to complete SAML flow: provider mismatch between internal state and URL.")))
+		return
+	}
+
+	callback := urlx.AppendPaths(s.d.Config().SAMLRedirectURIBase(ctx), RouteCallbackBase, providerID)
+	query := url.Values{"state": {stateParam}}
+
+	if code, err := s.callbackCode(ctx, r, providerID, stateParam); err != nil {
+		// The details are only logged as they may help an attacker to craft a
+		// response which passes validation.
+		s.d.Logger().WithError(err).WithField("provider", providerID).Warn("Unable to validate SAML response.")
+		query.Set("error", "invalid_response")
+		query.Set("error_description", "The SAML response could not be validated.")
+	} else {
+		query.Set("code", code)
+	}
+
+	callback.RawQuery = query.Encode()
+	http.Redirect(w, r, callback.String(), http.StatusSeeOther)
+}
+
+func (s *Strategy) callbackCode(ctx context.Context, r *http.Request, providerID, stateParam string) (string, error) {
+	provider, err := s.provider(ctx, providerID)
+	if err != nil {
+		return "", err
+	}
+
+	sp, err := provider.ServiceProvider(ctx, true)
+	if err != nil {
+		return "", err
+	}
+
+	assertion, err := sp.ParseResponse(r, []string{requestID(stateParam)})
+	if err != nil {
+		var invalid *saml.InvalidResponseError
+		if errors.As(err, &invalid) {
+			err = invalid.PrivateErr
+		}
+		return "", errors.WithStack(herodot.ErrBadRequest.WithReasonf("The SAML response is invalid: %s", err))
+	}
+
+	return provider.CallbackCode(ctx, stateParam, provider.Claims(assertion))
+}
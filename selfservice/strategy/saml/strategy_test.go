@@ -0,0 +1,307 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package saml_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	crewjam "github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/internal"
+	"github.com/ory/kratos/internal/testhelpers"
+	"github.com/ory/kratos/selfservice/strategy/saml"
+	"github.com/ory/kratos/x"
+)
+
+func newKeyPair(t *testing.T, cn string) (*rsa.PrivateKey, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return key, cert
+}
+
+func base64URL(b []byte) string {
+	return "base64://" + base64.StdEncoding.EncodeToString(b)
+}
+
+type serviceProviders struct{ t *testing.T }
+
+func (s serviceProviders) GetServiceProvider(_ *http.Request, id string) (*crewjam.EntityDescriptor, error) {
+	res, err := http.Get(id) //nolint:gosec // test code
+	require.NoError(s.t, err)
+	defer res.Body.Close()
+
+	var m crewjam.EntityDescriptor
+	require.NoError(s.t, xml.NewDecoder(res.Body).Decode(&m))
+	return &m, nil
+}
+
+// newIdentityProvider starts a SAML identity provider which authenticates
+// every request as the given user. Instead of auto-submitting the response
+// form, the SSO endpoint returns it as JSON so that the test can post it.
+func newIdentityProvider(t *testing.T, email *string) (*crewjam.IdentityProvider, *httptest.Server) {
+	key, cert := newKeyPair(t, "idp")
+	idp := &crewjam.IdentityProvider{
+		Key:                     key,
+		Certificate:             cert,
+		ServiceProviderProvider: serviceProviders{t: t},
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := crewjam.NewIdpAuthnRequest(idp, r)
+		require.NoError(t, err)
+		require.NoError(t, req.Validate())
+
+		require.NoError(t, crewjam.DefaultAssertionMaker{}.MakeAssertion(req, &crewjam.Session{
+			ID:             "session-id",
+			Index:          "session-index",
+			NameID:         *email,
+			UserEmail:      *email,
+			UserCommonName: "Jane Doe",
+		}))
+		form, err := req.PostBinding()
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(form))
+	}))
+	t.Cleanup(ts.Close)
+
+	idp.MetadataURL = *x.Must(url.Parse(ts.URL + "/metadata"))
+	idp.SSOURL = *x.Must(url.Parse(ts.URL + "/sso"))
+	return idp, ts
+}
+
+func TestStrategy(t *testing.T) {
+	ctx := context.Background()
+	if testing.Short() {
+		t.Skip()
+	}
+
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+
+	email := "jane@example.org"
+	idp, _ := newIdentityProvider(t, &email)
+	idpMetadata, err := xml.Marshal(idp.Metadata())
+	require.NoError(t, err)
+
+	returnTS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess, err := reg.SessionManager().FetchFromRequest(r.Context(), r)
+		require.NoError(t, err)
+		reg.Writer().Write(w, r, sess)
+	}))
+	t.Cleanup(returnTS.Close)
+	uiTS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e interface{}
+		var err error
+		switch r.URL.Path {
+		case "/login":
+			e, err = reg.LoginFlowPersister().GetLoginFlow(r.Context(), x.ParseUUID(r.URL.Query().Get("flow")))
+		case "/registration":
+			e, err = reg.RegistrationFlowPersister().GetRegistrationFlow(r.Context(), x.ParseUUID(r.URL.Query().Get("flow")))
+		}
+		require.NoError(t, err)
+		reg.Writer().Write(w, r, e)
+	}))
+	t.Cleanup(uiTS.Close)
+	errTS := testhelpers.NewErrorTestServer(t, reg)
+
+	conf.MustSet(ctx, config.ViperKeySelfServiceBrowserDefaultReturnTo, returnTS.URL)
+	conf.MustSet(ctx, config.ViperKeyURLsAllowedReturnToDomains, []string{returnTS.URL})
+	conf.MustSet(ctx, config.ViperKeySelfServiceLoginUI, uiTS.URL+"/login")
+	conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationUI, uiTS.URL+"/registration")
+	conf.MustSet(ctx, config.ViperKeySelfServiceRegistrationEnabled, true)
+	conf.MustSet(ctx, config.ViperKeyIdentitySchemas, config.Schemas{{ID: "default", URL: "file://./stub/registration.schema.json"}})
+	conf.MustSet(ctx, config.ViperKeyDefaultIdentitySchemaID, "default")
+	conf.MustSet(ctx, config.HookStrategyKey(config.ViperKeySelfServiceRegistrationAfter, identity.CredentialsTypeSAML.String()), []config.SelfServiceHook{{Name: "session"}})
+
+	spKey, spCert := newKeyPair(t, "sp")
+	conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+".saml", map[string]any{
+		"enabled": true,
+		"config": map[string]any{
+			"providers": []map[string]any{{
+				"id":                  "acme",
+				"label":               "ACME",
+				"mapper_url":          "file://./stub/saml.jsonnet",
+				"idp_metadata_url":    base64URL(idpMetadata),
+				"sp_certificate_url":  base64URL(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: spCert.Raw})),
+				"sp_private_key_url":  base64URL(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(spKey)})),
+				"sign_authn_requests": true,
+				"name_id_format":      "email",
+			}, {
+				"id":               "plain",
+				"mapper_url":       "file://./stub/saml.jsonnet",
+				"idp_metadata_url": base64URL(idpMetadata),
+			}},
+		},
+	})
+
+	ts, _ := testhelpers.NewKratosServerWithRouters(t, reg, x.NewRouterPublic(reg), x.NewRouterAdmin(reg))
+
+	// startFlow submits the SAML provider and runs the identity provider,
+	// returning the response form which the browser would post to the ACS.
+	startFlow := func(t *testing.T, client *http.Client, action, provider string) crewjam.IdpAuthnRequestForm {
+		res, err := client.PostForm(action, url.Values{"provider": {provider}})
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", body)
+
+		var form crewjam.IdpAuthnRequestForm
+		require.NoError(t, json.Unmarshal(body, &form), "%s", body)
+		assert.Equal(t, ts.URL+saml.RouteACSBase+"/"+provider, form.URL)
+		return form
+	}
+
+	postACS := func(t *testing.T, client *http.Client, form crewjam.IdpAuthnRequestForm) (*http.Response, []byte) {
+		res, err := client.PostForm(form.URL, url.Values{"SAMLResponse": {form.SAMLResponse}, "RelayState": {form.RelayState}})
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, body
+	}
+
+	t.Run("case=serves service provider metadata", func(t *testing.T) {
+		res, err := ts.Client().Get(ts.URL + saml.RouteMetadataBase + "/acme")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/samlmetadata+xml", res.Header.Get("Content-Type"))
+
+		var m crewjam.EntityDescriptor
+		require.NoError(t, xml.NewDecoder(res.Body).Decode(&m))
+		assert.Equal(t, ts.URL+saml.RouteMetadataBase+"/acme", m.EntityID)
+		require.Len(t, m.SPSSODescriptors, 1)
+		assert.True(t, *m.SPSSODescriptors[0].AuthnRequestsSigned)
+		assert.Equal(t, ts.URL+saml.RouteACSBase+"/acme", m.SPSSODescriptors[0].AssertionConsumerServices[0].Location)
+
+		res, err = ts.Client().Get(ts.URL + saml.RouteMetadataBase + "/unknown")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("case=renders the provider in the login flow", func(t *testing.T) {
+		client := testhelpers.NewClientWithCookieJar(t, nil, nil)
+		f := testhelpers.InitializeLoginFlowViaBrowser(t, client, ts, false, false, false, false)
+		nodes, err := json.Marshal(f.Ui.Nodes)
+		require.NoError(t, err)
+		assert.Equal(t, "saml", gjson.GetBytes(nodes, `#(attributes.value=="acme").group`).String(), "%s", nodes)
+	})
+
+	var identityID string
+	t.Run("case=registers and logs in with the identity provider", func(t *testing.T) {
+		client := testhelpers.NewClientWithCookieJar(t, nil, nil)
+		f := testhelpers.InitializeRegistrationFlowViaBrowser(t, client, ts, false, false, false)
+
+		res, body := postACS(t, client, startFlow(t, client, f.Ui.Action, "acme"))
+		require.Contains(t, res.Request.URL.String(), returnTS.URL, "%s", body)
+		assert.Equal(t, email, gjson.GetBytes(body, "identity.traits.email").String(), "%s", body)
+		assert.Equal(t, "Jane Doe", gjson.GetBytes(body, "identity.traits.name").String(), "%s", body)
+		assert.Equal(t, "saml", gjson.GetBytes(body, "authentication_methods.0.method").String(), "%s", body)
+		assert.Equal(t, "acme", gjson.GetBytes(body, "authentication_methods.0.provider").String(), "%s", body)
+		identityID = gjson.GetBytes(body, "identity.id").String()
+
+		i, err := reg.PrivilegedIdentityPool().GetIdentityConfidential(ctx, x.ParseUUID(identityID))
+		require.NoError(t, err)
+		creds, ok := i.GetCredentials(identity.CredentialsTypeSAML)
+		require.True(t, ok)
+		assert.Equal(t, []string{"acme:" + email}, creds.Identifiers)
+
+		client = testhelpers.NewClientWithCookieJar(t, nil, nil)
+		lf := testhelpers.InitializeLoginFlowViaBrowser(t, client, ts, false, false, false, false)
+		res, body = postACS(t, client, startFlow(t, client, lf.Ui.Action, "acme"))
+		require.Contains(t, res.Request.URL.String(), returnTS.URL, "%s", body)
+		assert.Equal(t, identityID, gjson.GetBytes(body, "identity.id").String(), "%s", body)
+	})
+
+	t.Run("case=rejects a tampered response", func(t *testing.T) {
+		client := testhelpers.NewClientWithCookieJar(t, nil, nil)
+		f := testhelpers.InitializeLoginFlowViaBrowser(t, client, ts, false, false, false, false)
+
+		// Assertions for providers with a key pair are encrypted, which is why
+		// this uses a provider without one.
+		form := startFlow(t, client, f.Ui.Action, "plain")
+		raw, err := base64.StdEncoding.DecodeString(form.SAMLResponse)
+		require.NoError(t, err)
+		require.Contains(t, string(raw), email)
+		form.SAMLResponse = base64.StdEncoding.EncodeToString([]byte(strings.ReplaceAll(string(raw), email, "mallory@example.org")))
+
+		res, body := postACS(t, client, form)
+		require.Contains(t, res.Request.URL.String(), uiTS.URL+"/login", "%s", body)
+		assert.Contains(t, gjson.GetBytes(body, "ui.messages.0.text").String(), "The SAML response could not be validated.", "%s", body)
+	})
+
+	t.Run("case=rejects a response for another flow", func(t *testing.T) {
+		client := testhelpers.NewClientWithCookieJar(t, nil, nil)
+		first := startFlow(t, client, testhelpers.InitializeLoginFlowViaBrowser(t, client, ts, false, false, false, false).Ui.Action, "acme")
+		second := startFlow(t, client, testhelpers.InitializeLoginFlowViaBrowser(t, client, ts, false, false, false, false).Ui.Action, "acme")
+
+		// The response was issued for the first flow's request.
+		second.SAMLResponse = first.SAMLResponse
+		res, body := postACS(t, client, second)
+		require.Contains(t, res.Request.URL.String(), uiTS.URL+"/login", "%s", body)
+		assert.Contains(t, gjson.GetBytes(body, "ui.messages.0.text").String(), "The SAML response could not be validated.", "%s", body)
+	})
+
+	t.Run("case=rejects identity provider initiated responses", func(t *testing.T) {
+		client := testhelpers.NewClientWithCookieJar(t, nil, nil)
+		res, body := postACS(t, client, crewjam.IdpAuthnRequestForm{URL: ts.URL + saml.RouteACSBase + "/acme", SAMLResponse: "foo"})
+		require.Contains(t, res.Request.URL.String(), errTS.URL, "%s", body)
+		assert.Contains(t, gjson.GetBytes(body, "reason").String(), "Identity provider initiated logins are not supported", "%s", body)
+	})
+
+	t.Run("case=is not responsible for unknown providers", func(t *testing.T) {
+		conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+".oidc.enabled", true)
+		t.Cleanup(func() { conf.MustSet(ctx, config.ViperKeySelfServiceStrategyConfig+".oidc.enabled", false) })
+
+		client := testhelpers.NewClientWithCookieJar(t, nil, nil)
+		f := testhelpers.InitializeLoginFlowViaBrowser(t, client, ts, false, false, false, false)
+
+		res, err := client.PostForm(f.Ui.Action, url.Values{"provider": {"unknown"}})
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		// The OpenID Connect strategy handled the request instead.
+		assert.Contains(t, string(body), `OpenID Connect Provider \"unknown\" is unknown or has not been configured`)
+
+		lf, err := reg.LoginFlowPersister().GetLoginFlow(ctx, x.ParseUUID(f.Id))
+		require.NoError(t, err)
+		// The flow's nodes are left untouched.
+		assert.NotNil(t, lf.UI.Nodes.Find("provider"))
+	})
+}
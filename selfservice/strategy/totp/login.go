@@ -120,6 +120,10 @@ func (s *Strategy) Login(_ http.ResponseWriter, r *http.Request, f *login.Flow,
 		return nil, s.handleLoginError(r, f, err)
 	}
 
+	if err := s.d.IdentityManager().CheckLockout(ctx, sess.IdentityID); err != nil {
+		return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(err, sess.IdentityID))
+	}
+
 	i, c, err := s.d.PrivilegedIdentityPool().FindByCredentialsIdentifier(ctx, s.ID(), sess.IdentityID.String())
 	if err != nil {
 		return nil, s.handleLoginError(r, f, errors.WithStack(schema.NewNoTOTPDeviceRegistered()))
@@ -136,9 +140,16 @@ func (s *Strategy) Login(_ http.ResponseWriter, r *http.Request, f *login.Flow,
 	}
 
 	if !totp.Validate(p.TOTPCode, key.Secret()) {
+		if err := s.d.IdentityManager().RecordFailedAuthentication(ctx, i.ID); err != nil {
+			return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(err, i.ID))
+		}
 		return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(errors.WithStack(schema.NewTOTPVerifierWrongError("#/")), i.ID))
 	}
 
+	if err := s.d.IdentityManager().ResetFailedAuthentications(ctx, i.ID); err != nil {
+		return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(err, i.ID))
+	}
+
 	f.Active = s.ID()
 	if err = s.d.LoginFlowPersister().UpdateLoginFlow(ctx, f); err != nil {
 		return nil, s.handleLoginError(r, f, x.WrapWithIdentityIDError(errors.WithStack(herodot.ErrInternalServerError.WithReason("Could not update flow").WithDebug(err.Error())), i.ID))
@@ -31,7 +31,10 @@ import (
 	"github.com/ory/herodot"
 
 	"github.com/ory/kratos/driver/config"
+	"github.com/ory/kratos/identity"
 	"github.com/ory/kratos/x"
+	"github.com/ory/x/jsonx"
+	"github.com/ory/x/urlx"
 )
 
 type (
@@ -45,6 +48,7 @@ type (
 		config.Provider
 		sessiontokenexchange.PersistenceProvider
 		TokenizerProvider
+		identity.PrivilegedPoolProvider
 	}
 	HandlerProvider interface {
 		SessionHandler() *Handler
@@ -72,9 +76,10 @@ const (
 )
 
 const (
-	AdminRouteIdentity           = "/identities"
-	AdminRouteIdentitiesSessions = AdminRouteIdentity + "/{id}/sessions"
-	AdminRouteSessionExtendId    = RouteSession + "/extend"
+	AdminRouteIdentity            = "/identities"
+	AdminRouteIdentitiesSessions  = AdminRouteIdentity + "/{id}/sessions"
+	AdminRouteSessionExtendId     = RouteSession + "/extend"
+	AdminRouteIdentityImpersonate = AdminRouteIdentity + "/{id}/impersonate"
 )
 
 func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
@@ -85,6 +90,7 @@ func (h *Handler) RegisterAdminRoutes(admin *x.RouterAdmin) {
 	admin.GET(AdminRouteIdentitiesSessions, h.listIdentitySessions)
 	admin.DELETE(AdminRouteIdentitiesSessions, h.deleteIdentitySessions)
 	admin.PATCH(AdminRouteSessionExtendId, h.adminSessionExtend)
+	admin.POST(AdminRouteIdentityImpersonate, h.impersonateIdentity)
 
 	admin.DELETE(RouteCollection, redir.RedirectToPublicRoute(h.r))
 }
@@ -97,6 +103,7 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	h.r.CSRFHandler().IgnoreGlob(RouteCollection + "/*")
 	h.r.CSRFHandler().IgnoreGlob(RouteCollection + "/*/extend")
 	h.r.CSRFHandler().IgnoreGlob(AdminRouteIdentity + "/*/sessions")
+	h.r.CSRFHandler().IgnoreGlob(AdminRouteIdentity + "/*/impersonate")
 
 	for _, m := range []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodConnect, http.MethodOptions, http.MethodTrace} {
 		public.Handler(m, RouteWhoami, http.HandlerFunc(h.whoami))
@@ -109,6 +116,7 @@ func (h *Handler) RegisterPublicRoutes(public *x.RouterPublic) {
 	public.GET(RouteExchangeCodeForSessionToken, h.exchangeCode)
 
 	public.DELETE(AdminRouteIdentitiesSessions, redir.RedirectToAdminRoute(h.r))
+	public.POST(AdminRouteIdentityImpersonate, redir.RedirectToAdminRoute(h.r))
 }
 
 // Check Session Request Parameters
@@ -323,6 +331,121 @@ func (h *Handler) deleteIdentitySessions(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Impersonate Identity Parameters
+//
+// swagger:parameters impersonateIdentity
+//
+//nolint:deadcode,unused
+//lint:ignore U1000 Used to generate Swagger and OpenAPI definitions
+type impersonateIdentity struct {
+	// ID is the identity's ID.
+	//
+	// required: true
+	// in: path
+	ID string `json:"id"`
+
+	// in: body
+	// required: true
+	Body ImpersonateIdentityBody
+}
+
+// Impersonate Identity Request Body
+//
+// swagger:model impersonateIdentityBody
+type ImpersonateIdentityBody struct {
+	// ImpersonatorID identifies the administrator who impersonates the identity, for example the email address
+	// of a support agent. It is stored in the session and visible to the identity.
+	//
+	// required: true
+	ImpersonatorID string `json:"impersonator_id"`
+
+	// Reason documents why the identity is impersonated, for example a support ticket.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Impersonation Session Response
+//
+// swagger:model impersonationSession
+type ImpersonationSessionResponse struct {
+	// The Session Token
+	//
+	// Send the session token in the HTTP Authorization header to act as the identity:
+	//
+	// 		Authorization: bearer ${session-token}
+	//
+	// required: true
+	Token string `json:"session_token"`
+
+	// The Session
+	//
+	// required: true
+	Session *Session `json:"session"`
+}
+
+// swagger:route POST /admin/identities/{id}/impersonate identity impersonateIdentity
+//
+// # Impersonate an Identity
+//
+// Issues a short-lived session which allows an administrator, for example a support agent, to act as the
+// identity. The session records the impersonator, uses the `impersonation` authentication method, and can
+// neither be extended nor be used to complete the settings flow.
+//
+// Impersonation sessions are issued with authenticator assurance level `aal1`. The lifespan of the session is
+// configured with `session.impersonation.lifespan`.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Security:
+//	  oryAccessToken:
+//
+//	Responses:
+//	  201: impersonationSession
+//	  400: errorGeneric
+//	  401: errorGeneric
+//	  404: errorGeneric
+//	  default: errorGeneric
+func (h *Handler) impersonateIdentity(w http.ResponseWriter, r *http.Request) {
+	iID, err := uuid.FromString(r.PathValue("id"))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithError(err.Error()).WithDebug("could not parse UUID")))
+		return
+	}
+
+	var body ImpersonateIdentityBody
+	if err := jsonx.NewStrictDecoder(r.Body).Decode(&body); err != nil {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithError(err.Error())))
+		return
+	}
+
+	if body.ImpersonatorID == "" {
+		h.r.Writer().WriteError(w, r, errors.WithStack(herodot.ErrBadRequest.WithReason("The impersonator_id must be set.")))
+		return
+	}
+
+	i, err := h.r.PrivilegedIdentityPool().GetIdentity(r.Context(), iID, identity.ExpandDefault)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	s, err := h.r.SessionManager().IssueImpersonationSession(r, i, Impersonator{ID: body.ImpersonatorID, Reason: body.Reason})
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.Writer().WriteCreated(w, r,
+		urlx.AppendPaths(h.r.Config().SelfAdminURL(r.Context()), RouteCollection, s.ID.String()).String(),
+		&ImpersonationSessionResponse{Token: s.Token, Session: s.Declassified()},
+	)
+}
+
 // Session List Request
 //
 // The request object for listing sessions in an administrative context.
@@ -1104,6 +1104,87 @@ func TestHandlerRefreshSessionBySessionID(t *testing.T) {
 	})
 }
 
+func TestHandlerImpersonateIdentity(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conf, reg := internal.NewFastRegistryWithMocks(t)
+	publicServer, adminServer, _, _ := testhelpers.NewKratosServerWithCSRFAndRouters(t, reg)
+
+	conf.MustSet(ctx, config.ViperKeyPublicBaseURL, "http://example.com")
+	testhelpers.SetDefaultIdentitySchema(conf, "file://./stub/identity.schema.json")
+	conf.MustSet(ctx, config.ViperKeySessionImpersonationLifespan, "10m")
+
+	i := identity.NewIdentity("")
+	require.NoError(t, reg.IdentityManager().Create(ctx, i))
+
+	impersonate := func(t *testing.T, id string, body string) (*http.Response, []byte) {
+		res, err := adminServer.Client().Post(adminServer.URL+"/admin/identities/"+id+"/impersonate", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		return res, ioutilx.MustReadAll(res.Body)
+	}
+
+	t.Run("case=issues an impersonation session", func(t *testing.T) {
+		res, body := impersonate(t, i.ID.String(), `{"impersonator_id":"support@example.com","reason":"ticket 123"}`)
+		require.Equal(t, http.StatusCreated, res.StatusCode, "%s", body)
+
+		token := gjson.GetBytes(body, "session_token").String()
+		require.NotEmpty(t, token, "%s", body)
+		assert.Equal(t, i.ID.String(), gjson.GetBytes(body, "session.identity.id").String(), "%s", body)
+		assert.Equal(t, "impersonation", gjson.GetBytes(body, "session.authentication_methods.0.method").String(), "%s", body)
+		assert.Equal(t, "aal1", gjson.GetBytes(body, "session.authenticator_assurance_level").String(), "%s", body)
+
+		expiresAt := gjson.GetBytes(body, "session.expires_at").Time()
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), expiresAt, time.Minute)
+
+		req := testhelpers.NewTestHTTPRequest(t, "GET", publicServer.URL+"/sessions/whoami", nil)
+		req.Header.Set("X-Session-Token", token)
+		res, err := publicServer.Client().Do(req)
+		require.NoError(t, err)
+		whoami := ioutilx.MustReadAll(res.Body)
+		require.Equal(t, http.StatusOK, res.StatusCode, "%s", whoami)
+		assert.Equal(t, "support@example.com", gjson.GetBytes(whoami, "impersonator.id").String(), "%s", whoami)
+		assert.Equal(t, "ticket 123", gjson.GetBytes(whoami, "impersonator.reason").String(), "%s", whoami)
+
+		t.Run("case=can not be extended", func(t *testing.T) {
+			sid := uuid.FromStringOrNil(gjson.GetBytes(body, "session.id").String())
+			require.NoError(t, reg.SessionPersister().ExtendSession(ctx, sid))
+
+			s, err := reg.SessionPersister().GetSession(ctx, sid, ExpandNothing)
+			require.NoError(t, err)
+			assert.True(t, s.IsImpersonated())
+			assert.WithinDuration(t, expiresAt, s.ExpiresAt, time.Second)
+		})
+	})
+
+	t.Run("case=requires an impersonator", func(t *testing.T) {
+		res, body := impersonate(t, i.ID.String(), `{}`)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, "%s", body)
+	})
+
+	t.Run("case=unknown identity", func(t *testing.T) {
+		res, body := impersonate(t, x.NewUUID().String(), `{"impersonator_id":"support@example.com"}`)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode, "%s", body)
+	})
+
+	t.Run("case=inactive identity", func(t *testing.T) {
+		inactive := identity.NewIdentity("")
+		inactive.State = identity.StateInactive
+		require.NoError(t, reg.IdentityManager().Create(ctx, inactive))
+
+		res, body := impersonate(t, inactive.ID.String(), `{"impersonator_id":"support@example.com"}`)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "%s", body)
+	})
+
+	t.Run("case=not available on the public API", func(t *testing.T) {
+		res, err := publicServer.Client().Post(publicServer.URL+"/admin/identities/"+i.ID.String()+"/impersonate", "application/json", strings.NewReader(`{"impersonator_id":"support@example.com"}`))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.NotEqual(t, http.StatusCreated, res.StatusCode)
+	})
+}
+
 type byCreatedAt []Session
 
 func (s byCreatedAt) Len() int      { return len(s) }
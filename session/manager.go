@@ -164,6 +164,10 @@ type Manager interface {
 	// all computed values (e.g. authenticator assurance level) and updates the session object but does not store
 	// the session in the database or on the client device.
 	ActivateSession(r *http.Request, session *Session, i *identity.Identity, authenticatedAt time.Time) error
+
+	// IssueImpersonationSession issues and stores a short-lived session which allows an administrator to act as
+	// the identity.
+	IssueImpersonationSession(r *http.Request, i *identity.Identity, impersonator Impersonator) (*Session, error)
 }
 
 type ManagementProvider interface {
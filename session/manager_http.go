@@ -466,6 +466,12 @@ func (s *ManagerHTTP) ActivateSession(r *http.Request, session *Session, i *iden
 		return errors.WithStack(ErrIdentityDisabled.WithDetail("identity_id", i.ID))
 	}
 
+	if l, err := s.r.IdentityManager().ActiveLockout(ctx, i.ID); err != nil {
+		return err
+	} else if l != nil {
+		return errors.WithStack(ErrIdentityLocked.WithDetail("identity_id", i.ID).WithDetail("locked_until", l.LockedUntil))
+	}
+
 	if err := s.r.IdentityManager().RefreshAvailableAAL(ctx, i); err != nil {
 		return err
 	}
@@ -487,3 +493,39 @@ func (s *ManagerHTTP) ActivateSession(r *http.Request, session *Session, i *iden
 
 	return nil
 }
+
+func (s *ManagerHTTP) IssueImpersonationSession(r *http.Request, i *identity.Identity, impersonator Impersonator) (_ *Session, err error) {
+	ctx, span := s.r.Tracer(r.Context()).Tracer().Start(r.Context(), "sessions.ManagerHTTP.IssueImpersonationSession", trace.WithAttributes(
+		attribute.String("identity.id", i.ID.String()),
+	))
+	defer otelx.End(span, &err)
+
+	now := time.Now().UTC()
+	sess := NewInactiveSession()
+
+	// Impersonation sessions never satisfy a second factor, which keeps
+	// AAL2-protected actions out of reach of the administrator.
+	sess.CompletedLoginFor(identity.CredentialsTypeImpersonation, identity.AuthenticatorAssuranceLevel1)
+	if err := s.ActivateSession(r.WithContext(ctx), sess, i, now); err != nil {
+		return nil, err
+	}
+
+	impersonator.IssuedAt = now
+	sess.Impersonator = &impersonator
+	sess.ExpiresAt = now.Add(s.r.Config().SessionImpersonationLifespan(ctx))
+
+	if err := s.r.SessionPersister().UpsertSession(ctx, sess); err != nil {
+		return nil, err
+	}
+
+	s.r.Audit().
+		WithRequest(r).
+		WithField("identity_id", i.ID).
+		WithField("session_id", sess.ID).
+		WithField("impersonator_id", impersonator.ID).
+		WithField("impersonation_reason", impersonator.Reason).
+		Info("An administrator issued an impersonation session.")
+	trace.SpanFromContext(ctx).AddEvent(events.NewSessionImpersonated(ctx, sess.ID, i.ID, impersonator.ID))
+
+	return sess, nil
+}
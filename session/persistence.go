@@ -32,6 +32,10 @@ type Persister interface {
 	// ListSessionsByIdentity retrieves sessions for an identity from the store.
 	ListSessionsByIdentity(ctx context.Context, iID uuid.UUID, active *bool, page, perPage int, except uuid.UUID, expandables Expandables) ([]Session, int64, error)
 
+	// ListActiveSessionsByProviderSessionID retrieves the active sessions which were last authenticated with the
+	// session of an OpenID Connect provider.
+	ListActiveSessionsByProviderSessionID(ctx context.Context, providerSessionID string) ([]Session, error)
+
 	// UpsertSession inserts or updates a session into / in the store.
 	UpsertSession(ctx context.Context, s *Session) error
 
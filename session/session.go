@@ -17,14 +17,21 @@ import (
 
 	"github.com/ory/herodot"
 	"github.com/ory/kratos/identity"
+	"github.com/ory/kratos/text"
 	"github.com/ory/kratos/x"
 	"github.com/ory/x/httpx"
 	"github.com/ory/x/pagination/keysetpagination"
 	"github.com/ory/x/pointerx"
 	"github.com/ory/x/randx"
+	"github.com/ory/x/sqlxx"
 )
 
-var ErrIdentityDisabled = herodot.ErrUnauthorized.WithError("identity is disabled").WithReason("This account was disabled.")
+var (
+	ErrIdentityDisabled = herodot.ErrUnauthorized.WithError("identity is disabled").WithReason("This account was disabled.")
+	ErrIdentityLocked   = herodot.ErrUnauthorized.WithError("identity is locked").WithReason("This account is temporarily locked because of too many failed attempts.")
+
+	ErrImpersonatedSession = herodot.ErrForbidden.WithID(text.ErrIDImpersonatedSession).WithError("session is impersonated").WithReason("This action is not available while the identity is being impersonated.")
+)
 
 type lifespanProvider interface {
 	SessionLifespan(ctx context.Context) time.Duration
@@ -106,6 +113,12 @@ type Session struct {
 	// A list of authentication methods (e.g. password, oidc, ...) used to issue this session.
 	AMR AuthenticationMethods `db:"authentication_methods" json:"authentication_methods"`
 
+	// ProviderSessionID is the session ID (`sid`) of the OpenID Connect
+	// provider the session was last authenticated with, if the provider
+	// returned one. It is indexed, so that back-channel logout tokens which
+	// only identify the provider session can revoke the session.
+	ProviderSessionID sqlxx.NullString `json:"-" faker:"-" db:"oidc_provider_session_id"`
+
 	// The Session Issuance Timestamp
 	//
 	// When this session was issued at. Usually equal or close to `authenticated_at`.
@@ -127,6 +140,11 @@ type Session struct {
 	// Devices has history of all endpoints where the session was used
 	Devices []Device `json:"devices" faker:"-" has_many:"session_devices" fk_id:"session_id"`
 
+	// The Session Impersonator
+	//
+	// Set if an administrator issued this session to act as the identity.
+	Impersonator *Impersonator `json:"impersonator,omitempty" faker:"-" db:"impersonator"`
+
 	// IdentityID is a helper struct field for gobuffalo.pop.
 	IdentityID uuid.UUID `json:"-" faker:"-" db:"identity_id"`
 
@@ -167,6 +185,9 @@ func (Session) TableName() string { return "sessions" }
 func (s *Session) CompletedLoginForMethod(method AuthenticationMethod) {
 	method.CompletedAt = time.Now().UTC()
 	s.AMR = append(s.AMR, method)
+	if method.ProviderSessionID != "" {
+		s.ProviderSessionID = sqlxx.NullString(method.ProviderSessionID)
+	}
 }
 
 func (s *Session) CompletedLoginFor(method identity.CredentialsType, aal identity.AuthenticatorAssuranceLevel) {
@@ -295,10 +316,61 @@ func (s *Session) MarshalJSON() ([]byte, error) {
 	return json.Marshal(out)
 }
 
+// IsImpersonated returns true if an administrator issued the session to act
+// as the identity.
+func (s *Session) IsImpersonated() bool {
+	return s.Impersonator != nil
+}
+
 func (s *Session) CanBeRefreshed(ctx context.Context, c refreshWindowProvider) bool {
+	if s.IsImpersonated() {
+		// Impersonation sessions are short-lived on purpose.
+		return false
+	}
 	return s.ExpiresAt.Add(-c.SessionRefreshMinTimeLeft(ctx)).Before(time.Now())
 }
 
+// Session Impersonator
+//
+// The administrator who issued an impersonation session.
+//
+// swagger:model sessionImpersonator
+type Impersonator struct {
+	// ID identifies the administrator, for example the email address of a support agent.
+	//
+	// required: true
+	ID string `json:"id"`
+
+	// The reason why the identity is impersonated.
+	Reason string `json:"reason,omitempty"`
+
+	// When the impersonation session was issued.
+	//
+	// required: true
+	IssuedAt time.Time `json:"issued_at"`
+}
+
+// Scan implements the Scanner interface.
+func (n *Impersonator) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	v := fmt.Sprintf("%s", value)
+	if len(v) == 0 {
+		return nil
+	}
+	return errors.WithStack(json.Unmarshal([]byte(v), n))
+}
+
+// Value implements the driver Valuer interface.
+func (n Impersonator) Value() (driver.Value, error) {
+	value, err := json.Marshal(n)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return string(value), nil
+}
+
 // List of (Used) AuthenticationMethods
 //
 // A list of authenticators which were used to authenticate the session.
@@ -326,6 +398,9 @@ type AuthenticationMethod struct {
 
 	// The Organization id used for authentication
 	Organization string `json:"organization,omitempty"`
+
+	// The session ID (`sid`) of the OIDC provider, if the provider returned one.
+	ProviderSessionID string `json:"provider_session_id,omitempty"`
 }
 
 // Scan implements the Scanner interface.
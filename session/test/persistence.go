@@ -28,6 +28,7 @@ import (
 	"github.com/ory/x/pointerx"
 	"github.com/ory/x/randx"
 	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
 )
 
 func TestPersister(ctx context.Context, conf *config.Config, p interface {
@@ -375,6 +376,38 @@ func TestPersister(ctx context.Context, conf *config.Config, p interface {
 			assert.ErrorIs(t, err, sqlcon.ErrNoRows)
 		})
 
+		t.Run("case=list active sessions by provider session ID", func(t *testing.T) {
+			providerSessionID := x.NewUUID().String()
+			newSession := func(t *testing.T, active bool, expiresAt time.Time, providerSessionID string) *session.Session {
+				var s session.Session
+				require.NoError(t, faker.FakeData(&s))
+				s.Active = active
+				s.ExpiresAt = expiresAt
+				s.ProviderSessionID = sqlxx.NullString(providerSessionID)
+				require.NoError(t, p.CreateIdentity(ctx, s.Identity))
+				require.NoError(t, p.UpsertSession(ctx, &s))
+				return &s
+			}
+
+			expected := newSession(t, true, time.Now().Add(time.Hour), providerSessionID)
+			newSession(t, false, time.Now().Add(time.Hour), providerSessionID)
+			newSession(t, true, time.Now().Add(-time.Hour), providerSessionID)
+			newSession(t, true, time.Now().Add(time.Hour), x.NewUUID().String())
+
+			actual, err := p.ListActiveSessionsByProviderSessionID(ctx, providerSessionID)
+			require.NoError(t, err)
+			require.Len(t, actual, 1)
+			assert.Equal(t, expected.ID, actual[0].ID)
+			assert.Equal(t, providerSessionID, string(actual[0].ProviderSessionID))
+
+			t.Run("on another network", func(t *testing.T) {
+				_, other := testhelpers.NewNetwork(t, ctx, p)
+				actual, err := other.ListActiveSessionsByProviderSessionID(ctx, providerSessionID)
+				require.NoError(t, err)
+				assert.Empty(t, actual)
+			})
+		})
+
 		t.Run("case=delete session by token", func(t *testing.T) {
 			var expected session.Session
 			require.NoError(t, faker.FakeData(&expected))
@@ -71,6 +71,17 @@ func SetSubjectClaim(claims jwt.MapClaims, session *Session, subjectSource strin
 	return nil
 }
 
+// SetActorClaim sets the `act` claim (RFC 8693) to the impersonator if the
+// session is impersonated, so that relying parties can tell the acting
+// administrator apart from the identity.
+func SetActorClaim(claims jwt.MapClaims, session *Session) {
+	if !session.IsImpersonated() {
+		delete(claims, "act")
+		return
+	}
+	claims["act"] = map[string]any{"sub": session.Impersonator.ID}
+}
+
 func (s *Tokenizer) TokenizeSession(ctx context.Context, template string, session *Session) (err error) {
 	ctx, span := s.r.Tracer(ctx).Tracer().Start(ctx, "sessions.ManagerHTTP.TokenizeSession")
 	defer otelx.End(span, &err)
@@ -162,6 +173,7 @@ func (s *Tokenizer) TokenizeSession(ctx context.Context, template string, sessio
 	if err = SetSubjectClaim(claims, session, tpl.SubjectSource); err != nil {
 		return err
 	}
+	SetActorClaim(claims, session)
 
 	var privateKey interface{}
 	if err := key.Raw(&privateKey); err != nil {
@@ -117,6 +117,21 @@ func TestTokenizer(t *testing.T) {
 		snapshotx.SnapshotT(t, token.Claims, snapshotx.ExceptPaths("jti"))
 	})
 
+	t.Run("case=impersonated-session-has-actor-claim", func(t *testing.T) {
+		tid := "rs512-template"
+		setTokenizeConfigWitSubjectSource(conf, tid, "jwk.es512.json", "file://stub/rs512-template.jsonnet", "id")
+
+		impersonated := *s
+		impersonated.Impersonator = &session.Impersonator{ID: "support@example.com", IssuedAt: now}
+
+		require.NoError(t, tkn.TokenizeSession(ctx, tid, &impersonated))
+		token := validateTokenized(t, impersonated.Tokenized, es512Key)
+
+		resultClaims := token.Claims.(jwt.MapClaims)
+		assert.Equal(t, i.ID.String(), resultClaims["sub"])
+		assert.Equal(t, map[string]any{"sub": "support@example.com"}, resultClaims["act"])
+	})
+
 	t.Run("case=es512-without-jsonnet", func(t *testing.T) {
 		tid := "es512-no-template"
 		setTokenizeConfig(conf, tid, "jwk.es512.json", "")
@@ -0,0 +1,219 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package text
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"golang.org/x/text/language"
+)
+
+// Catalog maps message IDs to translated texts. Translated texts reference the
+// message context using placeholders, e.g. `{min_length}`.
+type Catalog map[ID]string
+
+// Translations holds the catalogs of all languages. English is built in and is
+// used if no other language matches.
+type Translations struct {
+	matcher  language.Matcher
+	catalogs []Catalog
+}
+
+// NewTranslations returns the translations for the given catalogs. A catalog for
+// English overrides the built-in English texts.
+func NewTranslations(catalogs map[language.Tag]Catalog) *Translations {
+	tags := []language.Tag{language.English}
+	t := &Translations{catalogs: []Catalog{catalogs[language.English]}}
+	for tag, c := range catalogs {
+		if tag == language.English {
+			continue
+		}
+		tags = append(tags, tag)
+		t.catalogs = append(t.catalogs, c)
+	}
+	t.matcher = language.NewMatcher(tags)
+	return t
+}
+
+// Negotiate returns the catalog matching the explicitly requested language, or
+// the languages of the Accept-Language header. If no language matches, the
+// English catalog is returned, which is empty unless English texts are overridden.
+func (t *Translations) Negotiate(lang, acceptLanguage string) Catalog {
+	var preferred []language.Tag
+	if tag, err := language.Parse(lang); err == nil {
+		preferred = append(preferred, tag)
+	}
+	if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil {
+		preferred = append(preferred, tags...)
+	}
+	if len(preferred) == 0 {
+		return t.catalogs[0]
+	}
+
+	_, idx, conf := t.matcher.Match(preferred...)
+	if conf == language.No {
+		return t.catalogs[0]
+	}
+	return t.catalogs[idx]
+}
+
+// Translate returns the message with its text translated. The ID, type, and
+// context of the message are kept, and messages without translation are
+// returned as they are.
+func (c Catalog) Translate(m Message) Message {
+	translated, ok := c[m.ID]
+	if !ok {
+		return m
+	}
+
+	var replacements []string
+	gjson.ParseBytes(m.Context).ForEach(func(key, value gjson.Result) bool {
+		replacements = append(replacements, "{"+key.String()+"}", formatContextValue(value))
+		return true
+	})
+	m.Text = strings.NewReplacer(replacements...).Replace(translated)
+	return m
+}
+
+func formatContextValue(value gjson.Result) string {
+	if !value.IsArray() {
+		if value.Type == gjson.String {
+			return value.String()
+		}
+		return value.Raw
+	}
+
+	var values []string
+	for _, v := range value.Array() {
+		values = append(values, formatContextValue(v))
+	}
+	return strings.Join(values, ", ")
+}
+
+// ParseJSONCatalog parses a catalog from a JSON object mapping message IDs to
+// translated texts:
+//
+//	{ "1010001": "Anmelden" }
+func ParseJSONCatalog(raw []byte) (Catalog, error) {
+	var texts map[string]string
+	if err := json.Unmarshal(raw, &texts); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	c := make(Catalog, len(texts))
+	for key, text := range texts {
+		id, err := parseCatalogID(key)
+		if err != nil {
+			return nil, err
+		}
+		c[id] = text
+	}
+	return c, nil
+}
+
+// ParsePOCatalog parses a catalog from a gettext PO file. Entries are identified
+// by the message ID in `msgctxt`, or in `msgid` if the entry has no context.
+// Untranslated entries and the header are skipped.
+//
+//	msgctxt "1010001"
+//	msgid "Sign in"
+//	msgstr "Anmelden"
+func ParsePOCatalog(raw []byte) (Catalog, error) {
+	c := make(Catalog)
+
+	var ctxt, id, str *string
+	var current *string
+	flush := func() error {
+		defer func() { ctxt, id, str, current = nil, nil, nil, nil }()
+		if id == nil || str == nil || *id == "" || *str == "" {
+			return nil
+		}
+		key := *id
+		if ctxt != nil {
+			key = *ctxt
+		}
+		msgID, err := parseCatalogID(key)
+		if err != nil {
+			return err
+		}
+		c[msgID] = *str
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for line := 1; scanner.Scan(); line++ {
+		l := strings.TrimSpace(scanner.Text())
+
+		var keyword string
+		switch {
+		case l == "" || strings.HasPrefix(l, "#"):
+			continue
+		case strings.HasPrefix(l, `"`):
+			if current == nil {
+				return nil, errors.Errorf("unexpected string on line %d of PO catalog", line)
+			}
+			v, err := strconv.Unquote(l)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid string on line %d of PO catalog", line)
+			}
+			*current += v
+			continue
+		default:
+			keyword, l, _ = strings.Cut(l, " ")
+		}
+
+		v, err := strconv.Unquote(strings.TrimSpace(l))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid string on line %d of PO catalog", line)
+		}
+
+		switch keyword {
+		case "msgctxt":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			ctxt, current = &v, &v
+		case "msgid":
+			if id != nil {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			id, current = &v, &v
+		case "msgstr", "msgstr[0]":
+			str, current = &v, &v
+		case "msgid_plural":
+			// Plural forms are not used by messages, the singular form is used instead.
+			current = &v
+		default:
+			if strings.HasPrefix(keyword, "msgstr[") {
+				current = &v
+				continue
+			}
+			return nil, errors.Errorf("unsupported keyword %q on line %d of PO catalog", keyword, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func parseCatalogID(key string) (ID, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(key))
+	if err != nil {
+		return 0, errors.Errorf("catalog key %q is not a message ID", key)
+	}
+	return ID(id), nil
+}
@@ -0,0 +1,114 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package text
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestCatalog(t *testing.T) {
+	t.Run("case=translates messages with context", func(t *testing.T) {
+		c := Catalog{
+			ErrorValidationMinLength: "Die Länge muss mindestens {min_length} sein, ist aber {actual_length}",
+			InfoSelfServiceLogin:     "Anmelden",
+		}
+
+		m := c.Translate(*NewErrorValidationMinLength(8, 3))
+		assert.Equal(t, ErrorValidationMinLength, m.ID)
+		assert.Equal(t, Error, m.Type)
+		assert.Equal(t, "Die Länge muss mindestens 8 sein, ist aber 3", m.Text)
+		assert.JSONEq(t, `{"min_length":8,"actual_length":3}`, string(m.Context))
+
+		assert.Equal(t, "Anmelden", c.Translate(*NewInfoLogin()).Text)
+
+		untranslated := *NewInfoLoginReAuth()
+		assert.Equal(t, untranslated, c.Translate(untranslated))
+	})
+
+	t.Run("case=formats lists", func(t *testing.T) {
+		c := Catalog{ErrorValidationInvalidCredentials: "{items} und {missing}"}
+		m := c.Translate(Message{ID: ErrorValidationInvalidCredentials, Context: []byte(`{"items":["a","b"],"missing":null}`)})
+		assert.Equal(t, "a, b und null", m.Text)
+	})
+
+	t.Run("case=parses JSON catalogs", func(t *testing.T) {
+		c, err := ParseJSONCatalog([]byte(`{"1010001": "Anmelden", "4000002": "Feld {property} fehlt"}`))
+		require.NoError(t, err)
+		assert.Equal(t, Catalog{InfoSelfServiceLogin: "Anmelden", ErrorValidationRequired: "Feld {property} fehlt"}, c)
+
+		_, err = ParseJSONCatalog([]byte(`{"login": "Anmelden"}`))
+		require.ErrorContains(t, err, `catalog key "login" is not a message ID`)
+	})
+
+	t.Run("case=parses PO catalogs", func(t *testing.T) {
+		c, err := ParsePOCatalog([]byte(`# German translations
+msgid ""
+msgstr ""
+"Language: de\n"
+
+#: text/message_login.go
+msgctxt "1010001"
+msgid "Sign in"
+msgstr "Anmelden"
+
+msgid "4000002"
+msgstr ""
+"Feld {property} "
+"fehlt"
+
+msgctxt "1010003"
+msgid "Please confirm this action by verifying that it is you."
+msgstr ""
+
+msgctxt "1010004"
+msgid "Please complete the second authentication challenge."
+msgid_plural "Please complete the second authentication challenges."
+msgstr[0] "Bitte schließen Sie die zweite Authentifizierung ab."
+msgstr[1] "Bitte schließen Sie die zweiten Authentifizierungen ab."
+`))
+		require.NoError(t, err)
+		assert.Equal(t, Catalog{
+			InfoSelfServiceLogin:    "Anmelden",
+			ErrorValidationRequired: "Feld {property} fehlt",
+			InfoSelfServiceLoginMFA: "Bitte schließen Sie die zweite Authentifizierung ab.",
+		}, c)
+
+		_, err = ParsePOCatalog([]byte(`msgid "Sign in"
+msgstr "Anmelden"`))
+		require.ErrorContains(t, err, `catalog key "Sign in" is not a message ID`)
+
+		_, err = ParsePOCatalog([]byte(`msgid "1010001"
+msgstr "Anmelden`))
+		require.ErrorContains(t, err, "line 2")
+	})
+
+	t.Run("case=negotiates the language", func(t *testing.T) {
+		de, ptBR := Catalog{InfoSelfServiceLogin: "Anmelden"}, Catalog{InfoSelfServiceLogin: "Entrar"}
+		tr := NewTranslations(map[language.Tag]Catalog{
+			language.German:              de,
+			language.BrazilianPortuguese: ptBR,
+		})
+
+		for _, tc := range []struct {
+			lang, acceptLanguage string
+			expected             Catalog
+		}{
+			{acceptLanguage: "de-CH, en;q=0.5", expected: de},
+			{acceptLanguage: "pt-BR", expected: ptBR},
+			{lang: "de", acceptLanguage: "pt-BR", expected: de},
+			{lang: "invalid tag", acceptLanguage: "pt-BR", expected: ptBR},
+			{acceptLanguage: "en-US, de;q=0.5", expected: nil},
+			{acceptLanguage: "fr", expected: nil},
+			{expected: nil},
+		} {
+			t.Run("lang="+tc.lang+"&accept_language="+tc.acceptLanguage, func(t *testing.T) {
+				assert.Equal(t, tc.expected, tr.Negotiate(tc.lang, tc.acceptLanguage))
+			})
+		}
+	})
+}
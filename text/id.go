@@ -33,6 +33,7 @@ const (
 	InfoSelfServiceLoginPasskey                                  // 1010021
 	InfoSelfServiceLoginPassword                                 // 1010022
 	InfoSelfServiceLoginAAL2CodeAddress                          // 1010023
+	InfoSelfServiceLoginLDAP                                     // 1010024
 )
 
 const (
@@ -160,6 +161,11 @@ const (
 	ErrorValidationAccountNotFound
 	ErrorValidationCaptchaError
 	ErrorValidationPasswordNewSameAsOld
+	ErrorValidationPasswordMissingCharacterClass
+	ErrorValidationPasswordMinCharacterClasses
+	ErrorValidationPasswordBannedWord
+	ErrorValidationPasswordTooWeak
+	ErrorValidationPasswordPreviouslyUsed
 )
 
 const (
@@ -174,6 +180,9 @@ const (
 	ErrorValidationLoginCodeInvalidOrAlreadyUsed                        // 4010008
 	ErrorValidationLoginLinkedCredentialsDoNotMatch                     // 4010009
 	ErrorValidationLoginAddressUnknown                                  // 4010010
+	ErrorValidationLoginRateLimited                                     // 4010011
+	ErrorValidationLoginIdentityLocked                                  // 4010012
+	ErrorValidationLoginPasswordForbidden                               // 4010013
 )
 
 const (
@@ -196,6 +205,7 @@ const (
 	ErrorValidationRecoveryTokenInvalidOrAlreadyUsed                     // 4060004
 	ErrorValidationRecoveryFlowExpired                                   // 4060005
 	ErrorValidationRecoveryCodeInvalidOrAlreadyUsed                      // 4060006
+	ErrorValidationRecoveryAddressSuppressed                             // 4060007
 )
 
 const (
@@ -206,6 +216,7 @@ const (
 	ErrorValidationVerificationMissingVerificationToken                      // 4070004
 	ErrorValidationVerificationFlowExpired                                   // 4070005
 	ErrorValidationVerificationCodeInvalidOrAlreadyUsed                      // 4070006
+	ErrorValidationVerificationAddressSuppressed                             // 4070007
 )
 
 const (
@@ -11,6 +11,7 @@ import (
 
 func TestIDs(t *testing.T) {
 	assert.Equal(t, 1010000, int(InfoSelfServiceLoginRoot))
+	assert.Equal(t, 1010024, int(InfoSelfServiceLoginLDAP))
 
 	assert.Equal(t, 1020000, int(InfoSelfServiceLogout))
 
@@ -48,6 +49,9 @@ func TestIDs(t *testing.T) {
 
 	assert.Equal(t, 4010000, int(ErrorValidationLogin))
 	assert.Equal(t, 4010001, int(ErrorValidationLoginFlowExpired))
+	assert.Equal(t, 4010011, int(ErrorValidationLoginRateLimited))
+	assert.Equal(t, 4010012, int(ErrorValidationLoginIdentityLocked))
+	assert.Equal(t, 4010013, int(ErrorValidationLoginPasswordForbidden))
 
 	assert.Equal(t, 4040000, int(ErrorValidationRegistration))
 	assert.Equal(t, 4040001, int(ErrorValidationRegistrationFlowExpired))
@@ -66,6 +70,8 @@ func TestIDs(t *testing.T) {
 
 	assert.Equal(t, 4060006, int(ErrorValidationRecoveryCodeInvalidOrAlreadyUsed))
 	assert.Equal(t, 4070006, int(ErrorValidationVerificationCodeInvalidOrAlreadyUsed))
+	assert.Equal(t, 4060007, int(ErrorValidationRecoveryAddressSuppressed))
+	assert.Equal(t, 4070007, int(ErrorValidationVerificationAddressSuppressed))
 
 	assert.Equal(t, 1080000, int(InfoSelfServiceVerification))
 	assert.Equal(t, 1080001, int(InfoSelfServiceVerificationEmailSent))
@@ -74,4 +80,5 @@ func TestIDs(t *testing.T) {
 
 	assert.Equal(t, 1070015, int(InfoNodeLabelCaptcha))
 	assert.Equal(t, 4000038, int(ErrorValidationCaptchaError))
+	assert.Equal(t, 4000044, int(ErrorValidationPasswordPreviouslyUsed))
 }
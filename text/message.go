@@ -51,7 +51,8 @@ type Message struct {
 	// required: true
 	ID ID `json:"id"`
 
-	// The message text. Written in american english.
+	// The message text. Written in american english, unless translated into the language
+	// negotiated from the `lang` query parameter or the `Accept-Language` header.
 	//
 	// required: true
 	Text string `json:"text"`
@@ -20,6 +20,10 @@ const (
 	ErrNoActiveSession               = "session_inactive"
 	ErrIDRedirectURLNotAllowed       = "self_service_flow_return_to_forbidden"
 	ErrIDInitiatedBySomeoneElse      = "security_identity_mismatch"
+	ErrIDImpersonatedSession         = "session_impersonated"
 
 	ErrIDCSRF = "security_csrf_violation"
+
+	ErrIDUpstreamGrantRevoked = "upstream_grant_revoked"
+	ErrIDUpstreamTokenExpired = "upstream_token_expired"
 )
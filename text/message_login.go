@@ -101,6 +101,14 @@ func NewInfoLoginPassword() *Message {
 	}
 }
 
+func NewInfoLoginLDAP() *Message {
+	return &Message{
+		ID:   InfoSelfServiceLoginLDAP,
+		Text: "Sign in with directory account",
+		Type: Info,
+	}
+}
+
 func NewInfoLoginLookup() *Message {
 	return &Message{
 		ID:   InfoLoginLookup,
@@ -152,6 +160,41 @@ func NewErrorValidationLoginFlowExpired(expiredAt time.Time) *Message {
 	}
 }
 
+func NewErrorValidationLoginRateLimited(retryAt time.Time) *Message {
+	return &Message{
+		ID:   ErrorValidationLoginRateLimited,
+		Text: fmt.Sprintf("Too many failed attempts, please try again in %.2f minutes.", Until(retryAt).Minutes()),
+		Type: Error,
+		Context: context(map[string]any{
+			"retry_at":      retryAt,
+			"retry_at_unix": retryAt.Unix(),
+		}),
+	}
+}
+
+func NewErrorValidationLoginIdentityLocked(lockedUntil time.Time) *Message {
+	return &Message{
+		ID:   ErrorValidationLoginIdentityLocked,
+		Text: fmt.Sprintf("Your account has been locked because of too many failed attempts, please try again in %.2f minutes.", Until(lockedUntil).Minutes()),
+		Type: Error,
+		Context: context(map[string]any{
+			"locked_until":      lockedUntil,
+			"locked_until_unix": lockedUntil.Unix(),
+		}),
+	}
+}
+
+func NewErrorValidationLoginPasswordForbidden(domain string) *Message {
+	return &Message{
+		ID:   ErrorValidationLoginPasswordForbidden,
+		Text: fmt.Sprintf("Accounts of %s can not sign in with a password. Please sign in with the single sign-on provider of your organization.", domain),
+		Type: Error,
+		Context: context(map[string]any{
+			"domain": domain,
+		}),
+	}
+}
+
 func NewErrorValidationLoginNoStrategyFound() *Message {
 	return &Message{
 		ID:   ErrorValidationLoginNoStrategyFound,
@@ -109,6 +109,14 @@ func NewErrorValidationRecoveryCodeInvalidOrAlreadyUsed() *Message {
 	}
 }
 
+func NewErrorValidationRecoveryAddressSuppressed() *Message {
+	return &Message{
+		ID:   ErrorValidationRecoveryAddressSuppressed,
+		Text: "We are unable to send messages to this address because previous messages could not be delivered. Please contact support.",
+		Type: Error,
+	}
+}
+
 func NewErrorValidationRecoveryRetrySuccess() *Message {
 	return &Message{
 		ID:   ErrorValidationRecoveryRetrySuccess,
@@ -248,6 +248,57 @@ func NewErrorValidationPasswordNewSameAsOld() *Message {
 	}
 }
 
+func NewErrorValidationPasswordMissingCharacterClass(class string) *Message {
+	return &Message{
+		ID:   ErrorValidationPasswordMissingCharacterClass,
+		Text: fmt.Sprintf("The password must contain at least one %s character.", class),
+		Type: Error,
+		Context: context(map[string]any{
+			"class": class,
+		}),
+	}
+}
+
+func NewErrorValidationPasswordMinCharacterClasses(minClasses, actualClasses int) *Message {
+	return &Message{
+		ID:   ErrorValidationPasswordMinCharacterClasses,
+		Text: fmt.Sprintf("The password must contain characters of at least %d of the classes lowercase, uppercase, digit and symbol, but got %d.", minClasses, actualClasses),
+		Type: Error,
+		Context: context(map[string]any{
+			"min_classes":    minClasses,
+			"actual_classes": actualClasses,
+		}),
+	}
+}
+
+func NewErrorValidationPasswordBannedWord() *Message {
+	return &Message{
+		ID:   ErrorValidationPasswordBannedWord,
+		Text: "The password can not be used because it contains a banned word.",
+		Type: Error,
+	}
+}
+
+func NewErrorValidationPasswordTooWeak(minScore, actualScore int) *Message {
+	return &Message{
+		ID:   ErrorValidationPasswordTooWeak,
+		Text: "The password is too easy to guess. Use a longer password with uncommon words.",
+		Type: Error,
+		Context: context(map[string]any{
+			"min_score":    minScore,
+			"actual_score": actualScore,
+		}),
+	}
+}
+
+func NewErrorValidationPasswordPreviouslyUsed() *Message {
+	return &Message{
+		ID:   ErrorValidationPasswordPreviouslyUsed,
+		Text: "The password has been used before and can not be used again.",
+		Type: Error,
+	}
+}
+
 func NewErrorValidationPasswordTooManyBreaches(breaches int64) *Message {
 	return &Message{
 		ID:   ErrorValidationPasswordTooManyBreaches,
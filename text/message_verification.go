@@ -68,6 +68,14 @@ func NewErrorValidationVerificationCodeInvalidOrAlreadyUsed() *Message {
 	}
 }
 
+func NewErrorValidationVerificationAddressSuppressed() *Message {
+	return &Message{
+		ID:   ErrorValidationVerificationAddressSuppressed,
+		Text: "We are unable to send messages to this address because previous messages could not be delivered. Please contact support.",
+		Type: Error,
+	}
+}
+
 func NewVerificationEmailWithCodeSent() *Message {
 	return &Message{
 		ID:   InfoSelfServiceVerificationEmailWithCodeSent,
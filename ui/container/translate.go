@@ -0,0 +1,145 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/node"
+)
+
+// Translate returns a copy of the container with the texts of all messages and
+// node labels translated. The container itself is not modified, so that only
+// the English texts are persisted.
+func (c *Container) Translate(catalog text.Catalog) (*Container, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var translated Container
+	if err := json.Unmarshal(raw, &translated); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	translateMessages(catalog, translated.Messages)
+	for _, n := range translated.Nodes {
+		translateMessages(catalog, n.Messages)
+		if n.Meta != nil {
+			translateMessage(catalog, n.Meta.Label)
+		}
+		switch a := n.Attributes.(type) {
+		case *node.InputAttributes:
+			translateMessage(catalog, a.Label)
+		case *node.AnchorAttributes:
+			translateMessage(catalog, a.Title)
+		case *node.TextAttributes:
+			translateMessage(catalog, a.Text)
+		}
+	}
+
+	return &translated, nil
+}
+
+func translateMessages(catalog text.Catalog, messages text.Messages) {
+	for k := range messages {
+		messages[k] = catalog.Translate(messages[k])
+	}
+}
+
+func translateMessage(catalog text.Catalog, m *text.Message) {
+	if m != nil {
+		*m = catalog.Translate(*m)
+	}
+}
+
+type (
+	uiGetter interface {
+		GetUI() *Container
+	}
+
+	requestURLGetter interface {
+		GetRequestURL() string
+	}
+
+	translatingWriter struct {
+		herodot.Writer
+		translations func(ctx context.Context) *text.Translations
+	}
+)
+
+// NewTranslatingWriter returns a writer which translates the UI texts of written
+// flows into the language negotiated from the `lang` query parameter or the
+// Accept-Language header of the request. If the request has no `lang` query
+// parameter, the one the flow was initialized with is used.
+func NewTranslatingWriter(w herodot.Writer, translations func(ctx context.Context) *text.Translations) herodot.Writer {
+	return &translatingWriter{Writer: w, translations: translations}
+}
+
+func (w *translatingWriter) Write(rw http.ResponseWriter, r *http.Request, e interface{}, opts ...herodot.EncoderOptions) {
+	defer w.translate(r, e)()
+	w.Writer.Write(rw, r, e, opts...)
+}
+
+func (w *translatingWriter) WriteCode(rw http.ResponseWriter, r *http.Request, code int, e interface{}, opts ...herodot.EncoderOptions) {
+	defer w.translate(r, e)()
+	w.Writer.WriteCode(rw, r, code, e, opts...)
+}
+
+func (w *translatingWriter) WriteCreated(rw http.ResponseWriter, r *http.Request, location string, e interface{}) {
+	defer w.translate(r, e)()
+	w.Writer.WriteCreated(rw, r, location, e)
+}
+
+// translate replaces the UI of the flow with its translation while the flow is
+// written, and returns a function restoring the original UI.
+func (w *translatingWriter) translate(r *http.Request, e interface{}) (restore func()) {
+	restore = func() {}
+
+	f, ok := e.(uiGetter)
+	if !ok {
+		return
+	}
+	ui := f.GetUI()
+	if ui == nil {
+		return
+	}
+
+	catalog := w.translations(r.Context()).Negotiate(requestedLanguage(r, e), r.Header.Get("Accept-Language"))
+	if len(catalog) == 0 {
+		return
+	}
+
+	translated, err := ui.Translate(catalog)
+	if err != nil {
+		// Untranslated texts are still usable, so the flow is written as it is.
+		return
+	}
+
+	original := *ui
+	*ui = *translated
+	return func() { *ui = original }
+}
+
+// requestedLanguage returns the `lang` query parameter of the request or, if it
+// is not set, of the request which initialized the flow. The initial request URL
+// is stored with the flow, so that the language is kept when the flow is fetched
+// or submitted later.
+func requestedLanguage(r *http.Request, e interface{}) string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return lang
+	}
+	if f, ok := e.(requestURLGetter); ok {
+		if u, err := url.Parse(f.GetRequestURL()); err == nil {
+			return u.Query().Get("lang")
+		}
+	}
+	return ""
+}
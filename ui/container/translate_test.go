@@ -0,0 +1,131 @@
+// Copyright © 2025 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package container_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"golang.org/x/text/language"
+
+	"github.com/ory/herodot"
+	"github.com/ory/kratos/text"
+	"github.com/ory/kratos/ui/container"
+	"github.com/ory/kratos/ui/node"
+	"github.com/ory/x/logrusx"
+)
+
+type uiFlow struct {
+	UI         *container.Container `json:"ui"`
+	RequestURL string               `json:"request_url"`
+}
+
+func (f *uiFlow) GetUI() *container.Container { return f.UI }
+func (f *uiFlow) GetRequestURL() string       { return f.RequestURL }
+
+func newTranslatableContainer() *container.Container {
+	c := container.New("/action")
+	c.Messages.Add(text.NewErrorValidationMinLength(8, 3))
+	c.Nodes.Append(node.NewInputField("password", nil, node.PasswordGroup, node.InputAttributeTypePassword).
+		WithMetaLabel(text.NewInfoNodeLabelID()))
+	c.Nodes.Append(node.NewInputField("method", "password", node.PasswordGroup, node.InputAttributeTypeSubmit).
+		WithMetaLabel(text.NewInfoLogin()))
+	c.Nodes.Append(node.NewAnchorField("link", "https://www.ory.sh", node.DefaultGroup, text.NewInfoLogin()))
+	c.AddMessage(node.PasswordGroup, text.NewValidationErrorRequired("password"), "password")
+	return c
+}
+
+var german = text.Catalog{
+	text.ErrorValidationMinLength: "Mindestens {min_length} Zeichen",
+	text.ErrorValidationRequired:  "{property} fehlt",
+	text.InfoSelfServiceLogin:     "Anmelden",
+}
+
+func assertContainerEqual(t *testing.T, expected, actual *container.Container, msgAndArgs ...interface{}) {
+	e, err := json.Marshal(expected)
+	require.NoError(t, err)
+	a, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(e), string(a), msgAndArgs...)
+}
+
+func TestTranslate(t *testing.T) {
+	c := newTranslatableContainer()
+
+	translated, err := c.Translate(german)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Mindestens 8 Zeichen", translated.Messages[0].Text)
+	assert.Equal(t, text.ErrorValidationMinLength, translated.Messages[0].ID)
+	assert.Equal(t, "password fehlt", translated.Nodes[0].Messages[0].Text)
+	assert.Equal(t, c.Nodes[0].Meta.Label.Text, translated.Nodes[0].Meta.Label.Text, "labels without translation are kept")
+	assert.Equal(t, "Anmelden", translated.Nodes[1].Meta.Label.Text)
+	assert.Equal(t, "Anmelden", translated.Nodes[2].Attributes.(*node.AnchorAttributes).Title.Text)
+
+	assertContainerEqual(t, newTranslatableContainer(), c, "the original container is not modified")
+}
+
+func TestTranslatingWriter(t *testing.T) {
+	w := container.NewTranslatingWriter(herodot.NewJSONWriter(logrusx.New("", "")), func(context.Context) *text.Translations {
+		return text.NewTranslations(map[language.Tag]text.Catalog{language.German: german})
+	})
+
+	write := func(t *testing.T, target string, header http.Header, payload interface{}) string {
+		r := httptest.NewRequest("GET", target, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		w.Write(rec, r, payload)
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.String()
+	}
+
+	f := &uiFlow{UI: newTranslatableContainer()}
+
+	t.Run("case=translates with accept language", func(t *testing.T) {
+		body := write(t, "/flow", http.Header{"Accept-Language": {"de-DE, en;q=0.8"}}, f)
+		assert.Equal(t, "Mindestens 8 Zeichen", gjson.Get(body, "ui.messages.0.text").String(), body)
+		assert.EqualValues(t, text.ErrorValidationMinLength, gjson.Get(body, "ui.messages.0.id").Int(), body)
+		assert.Equal(t, "Anmelden", gjson.Get(body, "ui.nodes.1.meta.label.text").String(), body)
+	})
+
+	t.Run("case=lang parameter takes precedence", func(t *testing.T) {
+		body := write(t, "/flow?lang=de", http.Header{"Accept-Language": {"fr"}}, f)
+		assert.Equal(t, "Anmelden", gjson.Get(body, "ui.nodes.1.meta.label.text").String(), body)
+
+		body = write(t, "/flow?lang=en", http.Header{"Accept-Language": {"de"}}, f)
+		assert.Equal(t, "Sign in", gjson.Get(body, "ui.nodes.1.meta.label.text").String(), body)
+	})
+
+	t.Run("case=uses the lang parameter the flow was initialized with", func(t *testing.T) {
+		f := &uiFlow{UI: newTranslatableContainer(), RequestURL: "https://www.ory.sh/self-service/login/browser?lang=de"}
+		body := write(t, "/flow", http.Header{"Accept-Language": {"fr"}}, f)
+		assert.Equal(t, "Anmelden", gjson.Get(body, "ui.nodes.1.meta.label.text").String(), body)
+
+		body = write(t, "/flow?lang=en", nil, f)
+		assert.Equal(t, "Sign in", gjson.Get(body, "ui.nodes.1.meta.label.text").String(), body)
+	})
+
+	t.Run("case=keeps english without match", func(t *testing.T) {
+		body := write(t, "/flow", http.Header{"Accept-Language": {"fr"}}, f)
+		assert.Equal(t, "Sign in", gjson.Get(body, "ui.nodes.1.meta.label.text").String(), body)
+	})
+
+	t.Run("case=does not modify the flow", func(t *testing.T) {
+		write(t, "/flow", http.Header{"Accept-Language": {"de"}}, f)
+		assertContainerEqual(t, newTranslatableContainer(), f.UI)
+	})
+
+	t.Run("case=writes other payloads as they are", func(t *testing.T) {
+		body := write(t, "/flow", http.Header{"Accept-Language": {"de"}}, map[string]string{"foo": "bar"})
+		assert.JSONEq(t, `{"foo":"bar"}`, body)
+	})
+}
@@ -23,6 +23,7 @@ import (
 const (
 	IdentityCreated          semconv.Event = "IdentityCreated"
 	IdentityDeleted          semconv.Event = "IdentityDeleted"
+	IdentityLocked           semconv.Event = "IdentityLocked"
 	IdentityUpdated          semconv.Event = "IdentityUpdated"
 	JsonnetMappingFailed     semconv.Event = "JsonnetMappingFailed"
 	LoginFailed              semconv.Event = "LoginFailed"
@@ -36,6 +37,7 @@ const (
 	RegistrationSucceeded    semconv.Event = "RegistrationSucceeded"
 	SessionChanged           semconv.Event = "SessionChanged"
 	SessionChecked           semconv.Event = "SessionChecked"
+	SessionImpersonated      semconv.Event = "SessionImpersonated"
 	SessionIssued            semconv.Event = "SessionIssued"
 	SessionLifespanExtended  semconv.Event = "SessionLifespanExtended"
 	SessionRevoked           semconv.Event = "SessionRevoked"
@@ -49,6 +51,7 @@ const (
 	WebhookSucceeded         semconv.Event = "WebhookSucceeded"
 	CourierMessageAbandoned  semconv.Event = "CourierMessageAbandoned"
 	CourierMessageDispatched semconv.Event = "CourierMessageDispatched"
+	PasswordHashUpgraded     semconv.Event = "PasswordHashUpgraded"
 )
 
 const (
@@ -56,6 +59,7 @@ const (
 	AttributeKeyFlowID                          semconv.AttributeKey = "FlowID"
 	AttributeKeyFlowRefresh                     semconv.AttributeKey = "FlowRefresh"
 	AttributeKeyFlowRequestedAAL                semconv.AttributeKey = "FlowRequestedAAL"
+	AttributeKeyIdentityLockedUntil             semconv.AttributeKey = "IdentityLockedUntil"
 	AttributeKeyJsonnetInput                    semconv.AttributeKey = "JsonnetInput"
 	AttributeKeyJsonnetOutput                   semconv.AttributeKey = "JsonnetOutput"
 	AttributeKeyLoginRequestedAAL               semconv.AttributeKey = "LoginRequestedAAL"
@@ -72,6 +76,7 @@ const (
 	AttributeKeySessionAAL                 semconv.AttributeKey = "SessionAAL"
 	AttributeKeySessionExpiresAt           semconv.AttributeKey = "SessionExpiresAt"
 	AttributeKeySessionID                  semconv.AttributeKey = "SessionID"
+	AttributeKeySessionImpersonatorID      semconv.AttributeKey = "SessionImpersonatorID"
 	AttributeKeyTokenizedSessionTTL        semconv.AttributeKey = "TokenizedSessionTTL"
 	AttributeKeyWebhookAttemptNumber       semconv.AttributeKey = "WebhookAttemptNumber"
 	AttributeKeyWebhookID                  semconv.AttributeKey = "WebhookID"
@@ -84,6 +89,8 @@ const (
 	AttributeKeyCourierMessageID           semconv.AttributeKey = "CourierMessageID"
 	AttributeKeyCourierMessageChannel      semconv.AttributeKey = "CourierMessageChannel"
 	AttributeKeyCourierMessageTemplateType semconv.AttributeKey = "CourierMessageTemplateType"
+	AttributeKeyPasswordHashAlgorithmFrom  semconv.AttributeKey = "PasswordHashAlgorithmFrom"
+	AttributeKeyPasswordHashAlgorithmTo    semconv.AttributeKey = "PasswordHashAlgorithmTo"
 )
 
 func attrSessionID(val uuid.UUID) otelattr.KeyValue {
@@ -211,6 +218,18 @@ func NewSessionIssued(ctx context.Context, aal string, sessionID, identityID uui
 		)
 }
 
+func NewSessionImpersonated(ctx context.Context, sessionID, identityID uuid.UUID, impersonatorID string) (string, trace.EventOption) {
+	return SessionImpersonated.String(),
+		trace.WithAttributes(
+			append(
+				semconv.AttributesFromContext(ctx),
+				semconv.AttrIdentityID(identityID),
+				attrSessionID(sessionID),
+				otelattr.String(AttributeKeySessionImpersonatorID.String(), impersonatorID),
+			)...,
+		)
+}
+
 func NewSessionChanged(ctx context.Context, aal string, sessionID, identityID uuid.UUID) (string, trace.EventOption) {
 	return SessionChanged.String(),
 		trace.WithAttributes(
@@ -401,6 +420,17 @@ func NewIdentityDeleted(ctx context.Context, identityID uuid.UUID) (string, trac
 		)
 }
 
+func NewIdentityLocked(ctx context.Context, identityID uuid.UUID, lockedUntil time.Time) (string, trace.EventOption) {
+	return IdentityLocked.String(),
+		trace.WithAttributes(
+			append(
+				semconv.AttributesFromContext(ctx),
+				semconv.AttrIdentityID(identityID),
+				otelattr.String(AttributeKeyIdentityLockedUntil.String(), lockedUntil.UTC().Format(time.RFC3339)),
+			)...,
+		)
+}
+
 func NewIdentityUpdated(ctx context.Context, identityID uuid.UUID) (string, trace.EventOption) {
 	return IdentityUpdated.String(),
 		trace.WithAttributes(
@@ -589,3 +619,18 @@ func NewCourierMessageDispatched(ctx context.Context, messageID uuid.UUID, chann
 			)...,
 		)
 }
+
+// NewPasswordHashUpgraded is emitted when the password hash of an identity was
+// replaced after a successful login, because it was generated by a different
+// algorithm or with weaker parameters than the configured ones.
+func NewPasswordHashUpgraded(ctx context.Context, identityID uuid.UUID, from, to string) (string, trace.EventOption) {
+	return PasswordHashUpgraded.String(),
+		trace.WithAttributes(
+			append(
+				semconv.AttributesFromContext(ctx),
+				semconv.AttrIdentityID(identityID),
+				otelattr.String(AttributeKeyPasswordHashAlgorithmFrom.String(), from),
+				otelattr.String(AttributeKeyPasswordHashAlgorithmTo.String(), to),
+			)...,
+		)
+}